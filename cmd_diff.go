@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/danaketh/StellarisDataParser/internal/cli"
+	"github.com/danaketh/StellarisDataParser/lib/diff"
+)
+
+// diffCommand compares the technologies of two game versions
+func diffCommand() *cli.Command {
+	oldGame := &gameOptions{inputFlag: "old", noDetect: true}
+	newGame := &gameOptions{inputFlag: "new", noDetect: true}
+	var asJSON bool
+
+	return &cli.Command{
+		Name:    "diff",
+		Summary: "Show technologies added, removed or changed between two game versions",
+		Usage:   "-old <game_directory> -new <game_directory> [flags]",
+		Examples: []string{
+			"stellaris-data-parser diff -old ./stellaris-3.12 -new ./stellaris-3.13",
+			"stellaris-data-parser diff -old ./stellaris-3.12 -new ./stellaris-3.13 -json > changes.json",
+		},
+		SetFlags: func(fs *flag.FlagSet) {
+			fs.StringVar(&oldGame.gameDir, "old", "", "Path to the old Stellaris game directory (required)")
+			fs.StringVar(&newGame.gameDir, "new", "", "Path to the new Stellaris game directory (required)")
+			fs.StringVar(&newGame.language, "language", "english", "Localization language used for names and descriptions")
+			fs.BoolVar(&asJSON, "json", false, "Print the differences as JSON")
+			newGame.registerLogging(fs)
+		},
+		Validate: func(fs *flag.FlagSet, problems *cli.Problems) {
+			oldGame.language = newGame.language
+			oldGame.verbose, oldGame.quiet, oldGame.logFormat = newGame.verbose, newGame.quiet, newGame.logFormat
+			oldGame.validate(problems)
+			newGame.validate(problems)
+		},
+		Run: func(ctx context.Context, args []string) error {
+			oldData, err := oldGame.load(ctx, slog.LevelDebug)
+			if err != nil {
+				return fmt.Errorf("old version: %w", err)
+			}
+			newData, err := newGame.load(ctx, slog.LevelDebug)
+			if err != nil {
+				return fmt.Errorf("new version: %w", err)
+			}
+
+			result := diff.Compare(oldData.technologies, newData.technologies)
+
+			if asJSON {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(result)
+			}
+
+			if result.Empty() {
+				fmt.Println("✓ No differences found")
+				return nil
+			}
+
+			for _, key := range result.Added {
+				fmt.Printf("+ %s\n", key)
+			}
+			for _, key := range result.Removed {
+				fmt.Printf("- %s\n", key)
+			}
+			for _, change := range result.Changed {
+				fmt.Printf("~ %s\n", change.Key)
+				for _, field := range change.Fields {
+					fmt.Printf("    %s: %v → %v\n", field.Field, field.Old, field.New)
+				}
+			}
+
+			fmt.Printf("\n%d added, %d removed, %d changed\n", len(result.Added), len(result.Removed), len(result.Changed))
+			return nil
+		},
+	}
+}
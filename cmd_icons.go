@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+
+	"github.com/danaketh/StellarisDataParser/internal/cli"
+	"github.com/danaketh/StellarisDataParser/lib/generator"
+)
+
+// iconsCommand converts technology icons without writing the JSON data
+func iconsCommand() *cli.Command {
+	game := &gameOptions{}
+	var (
+		outputDir        string
+		repeatableBadges bool
+		iconPlaceholders bool
+		iconOverrides    string
+	)
+
+	return &cli.Command{
+		Name:    "icons",
+		Summary: "Convert technology icons from DDS and TGA to PNG",
+		Usage:   "[-input <game_directory>] [-output <directory>] [flags]",
+		Examples: []string{
+			"stellaris-data-parser icons -input \"C:\\Steam\\steamapps\\common\\Stellaris\" -repeatable-badges",
+		},
+		SetFlags: func(fs *flag.FlagSet) {
+			game.register(fs)
+			fs.StringVar(&outputDir, "output", "output", "Output directory for icons")
+			fs.BoolVar(&repeatableBadges, "repeatable-badges", false, "Render a level badge onto a copy of each repeatable technology's icon")
+			fs.BoolVar(&iconPlaceholders, "icon-placeholders", false, "Write a placeholder icon with the technology's initials on its area color for each icon that is missing or can't be decoded")
+			fs.StringVar(&iconOverrides, "icon-overrides", "", "Directory of PNG or SVG icons, named after a technology key or icon name, replacing the game icons")
+		},
+		Validate: func(fs *flag.FlagSet, problems *cli.Problems) {
+			// Icons don't depend on names and descriptions
+			game.skipLocalization = true
+			game.validate(problems)
+			validateOutputDir(outputDir, problems)
+			validateIconOverrides(iconOverrides, problems)
+		},
+		Run: func(ctx context.Context, args []string) error {
+			game.printBanner()
+
+			data, err := game.load(ctx, slog.LevelInfo)
+			if err != nil {
+				return err
+			}
+
+			jsonGenerator := generator.NewJSONGenerator(data.tree)
+			jsonGenerator.SetGameDir(game.gameDir)
+			jsonGenerator.SetMods(game.mods)
+			jsonGenerator.SetRepeatableBadges(repeatableBadges)
+			jsonGenerator.SetIconPlaceholders(iconPlaceholders)
+			jsonGenerator.SetIconOverrides(iconOverrides)
+			jsonGenerator.SetOutputConfig(game.config.Output)
+			jsonGenerator.SetIconsConfig(game.config.Icons)
+			jsonGenerator.SetProgress(game.reporter)
+			jsonGenerator.SetLogger(game.logger)
+
+			absOutputPath, err := prepareOutputDir(outputDir)
+			if err != nil {
+				return err
+			}
+
+			if err := jsonGenerator.ConvertIconsContext(ctx, absOutputPath); err != nil {
+				return fmt.Errorf("failed to convert icons: %w", err)
+			}
+
+			game.logger.Info("Icons written", "dir", absOutputPath)
+			game.finish(absOutputPath)
+			return nil
+		},
+	}
+}
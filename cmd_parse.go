@@ -0,0 +1,421 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/danaketh/StellarisDataParser/internal/cli"
+	"github.com/danaketh/StellarisDataParser/lib/content"
+	"github.com/danaketh/StellarisDataParser/lib/filter"
+	"github.com/danaketh/StellarisDataParser/lib/generator"
+	"github.com/danaketh/StellarisDataParser/lib/localization"
+	"github.com/danaketh/StellarisDataParser/lib/manifest"
+	"github.com/danaketh/StellarisDataParser/lib/parser"
+)
+
+// parseCommand generates the JSON data files and icons
+func parseCommand() *cli.Command {
+	game := &gameOptions{}
+	subset := &subsetOptions{}
+	var (
+		outputDir        string
+		where            string
+		repeatableLevels int
+		repeatableBadges bool
+		iconPlaceholders bool
+		colors           string
+		iconTokens       string
+		commands         string
+		full             bool
+		minify           bool
+		format           string
+		gzip             bool
+		iconOverrides    string
+		skipIcons        bool
+		iconSizeList     string
+		iconColors       int
+		iconCompression  string
+		iconSizes        []int
+		embedIcons       bool
+		embedIconSize    int
+		embedIconBytes   int
+		onlyJSON         bool
+		coverage         string
+		withMechanics    bool
+		subgraphs        bool
+		graph            bool
+		html             bool
+		searchIndex      bool
+		sidebars         bool
+		contentList      string
+		contentTypes     []string
+		coverageLangs    []string
+		since            string
+		summaryFile      string
+		templateList     string
+		templates        []*template.Template
+		whereExpr        *filter.Expression
+		sinceManifest    *manifest.Manifest
+		settings         string
+	)
+
+	return &cli.Command{
+		Name:    "parse",
+		Summary: "Generate JSON data files and icons from the game files",
+		Usage:   "[-input <game_directory>] [-output <directory>] [flags]",
+		Notes: []string{
+			"Point -input to the Stellaris game root directory",
+			"The tool will automatically find common/technology/ and localisation/ subdirectories",
+			"Generates JSON files for each research area and metadata.json with areas, tiers, and categories",
+			"Converts technology icons from DDS and TGA to PNG format",
+		},
+		Examples: []string{
+			"stellaris-data-parser parse -input \"C:\\Steam\\steamapps\\common\\Stellaris\"",
+			"stellaris-data-parser parse -input \"C:\\Steam\\steamapps\\common\\Stellaris\" -output data -where 'tier >= 3'",
+			"stellaris-data-parser parse -areas physics -tiers 0-2",
+			"stellaris-data-parser parse -tech tech_lasers_1 -output lasers",
+			"stellaris-data-parser parse -output changed -since previous/manifest.json",
+			"stellaris-data-parser parse -summary output/run-summary.json",
+			"stellaris-data-parser parse -template ./templates/wiki.txt.tmpl,./templates/techs.html.tmpl",
+			"stellaris-data-parser parse -html -output site",
+			"stellaris-data-parser parse -format yaml -output _data/stellaris",
+		},
+		SetFlags: func(fs *flag.FlagSet) {
+			game.register(fs)
+			fs.StringVar(&outputDir, "output", "output", "Output directory for JSON files and icons")
+			fs.StringVar(&where, "where", "", "Only export technologies matching an expression, e.g. 'tier >= 3 && isRare'")
+			subset.register(fs)
+			fs.IntVar(&repeatableLevels, "repeatable-levels", generator.DefaultRepeatableLevels, "Number of levels in the cost table of infinite repeatable technologies")
+			fs.StringVar(&colors, "colors", localization.ColorStrip, "How §Y...§! color markup in names and descriptions is written: strip, html or raw")
+			fs.StringVar(&commands, "commands", localization.CommandStrip, "How [Root.GetName] scripting commands in names and descriptions are written: strip, placeholder or raw")
+			fs.StringVar(&iconTokens, "icon-tokens", localization.IconTokenRaw, "How £energy£ icon references in names and descriptions are written: raw, strip, token or html")
+			fs.BoolVar(&repeatableBadges, "repeatable-badges", false, "Render a level badge onto a copy of each repeatable technology's icon")
+			fs.BoolVar(&iconPlaceholders, "icon-placeholders", false, "Write a placeholder icon with the technology's initials on its area color for each icon that is missing or can't be decoded")
+			fs.StringVar(&iconOverrides, "icon-overrides", "", "Directory of PNG or SVG icons, named after a technology key or icon name, replacing the game icons")
+			fs.BoolVar(&full, "full", false, "Export every parsed field, including every technology block key the parser does not model in extraFlags")
+			fs.BoolVar(&game.keepSource, "raw", false, "Include each technology's original script text and line span as raw")
+			fs.BoolVar(&minify, "minify", false, "Write JSON files without indentation")
+			fs.StringVar(&format, "format", generator.FormatJSON, "Format of the data files: json, or yaml for files of the same structure ending in .yaml")
+			fs.BoolVar(&gzip, "gzip", false, "Also write a gzip-compressed <name>.json.gz next to each JSON file")
+			fs.StringVar(&iconSizeList, "icon-sizes", "", "Comma-separated sizes in pixels of icon variants written to icons/<size>/, e.g. 24,32,52")
+			fs.IntVar(&iconColors, "icon-colors", 0, fmt.Sprintf("Reduce icons to a palette of at most this many colors, 2 to %d, 0 keeps full color", generator.MaxIconColors))
+			fs.StringVar(&iconCompression, "icon-compression", generator.IconCompressionDefault, "PNG compression of icons: "+strings.Join(generator.IconCompressionLevels, ", "))
+			fs.BoolVar(&embedIcons, "embed-icons", false, "Embed each technology's icon in the JSON as a data URI in iconData")
+			fs.IntVar(&embedIconSize, "embed-icon-size", 0, "Downscale embedded icons to fit in this many pixels, 0 keeps their size")
+			fs.IntVar(&embedIconBytes, "embed-icon-max-bytes", generator.DefaultEmbedIconMaxBytes, "Leave out embedded icons larger than this many bytes, 0 for no limit")
+			fs.BoolVar(&skipIcons, "skip-icons", false, "Don't convert icons, e.g. when only the JSON needs regenerating")
+			fs.BoolVar(&game.skipLocalization, "skip-localization", false, "Don't read localization files; names are formatted from technology keys")
+			fs.BoolVar(&onlyJSON, "only-json", false, "Only run the JSON stage, same as -skip-icons")
+			fs.BoolVar(&withMechanics, "mechanics", false, "Also write mechanics.json with the research defines, tier rules and static modifiers")
+			fs.BoolVar(&graph, "graph", false, "Also write graph.json with nodes and links arrays for d3-force and other graph libraries")
+			fs.BoolVar(&html, "html", false, "Also write index.html, a self-contained tech tree viewer with pan, zoom, search and tooltips")
+			fs.BoolVar(&searchIndex, "search-index", false, "Also write search-index.json with the plain-text name and description of each technology, for lunr or Algolia")
+			fs.BoolVar(&sidebars, "sidebars", false, "Also write sidebars.js, a Docusaurus sidebar of the technologies grouped by area and tier")
+			fs.BoolVar(&subgraphs, "subgraphs", false, "Also write the ancestors, descendants and edges of each technology to subgraphs/<key>.json")
+			fs.StringVar(&contentList, "content", content.Tech, "Comma-separated content types to parse, or all; technologies are always parsed: "+strings.Join(append([]string{content.Tech}, content.Names()...), ", "))
+			fs.StringVar(&coverage, "localization-report", "", "Comma-separated languages, or all, to report missing names and descriptions for")
+			fs.StringVar(&since, "since", "", "Only write files whose inputs changed since this manifest.json from a previous run")
+			fs.StringVar(&templateList, "template", "", "Comma-separated text/template files rendered into the output directory with the exported technologies, e.g. wiki.txt.tmpl renders wiki.txt")
+			fs.StringVar(&summaryFile, "summary", "", "Write counts, phase timings and input fingerprints of the run to this JSON file, e.g. output/run-summary.json")
+		},
+		Validate: func(fs *flag.FlagSet, problems *cli.Problems) {
+			game.validate(problems)
+			validateOutputDir(outputDir, problems)
+			validateIconOverrides(iconOverrides, problems)
+			subset.validate(problems)
+			whereExpr = filter.And(subset.expr, compileWhere(where, problems))
+			if repeatableLevels < 0 {
+				problems.Add("repeatable-levels", fmt.Sprintf("must not be negative, got %d", repeatableLevels))
+			}
+			iconSizes = nil
+			for _, value := range splitList(iconSizeList) {
+				size, err := strconv.Atoi(value)
+				if err != nil || size <= 0 {
+					problems.Add("icon-sizes", fmt.Sprintf("invalid size %q, expected a positive number of pixels", value))
+					continue
+				}
+				if !slices.Contains(iconSizes, size) {
+					iconSizes = append(iconSizes, size)
+				}
+			}
+			slices.Sort(iconSizes)
+			if iconColors != 0 && (iconColors < 2 || iconColors > generator.MaxIconColors) {
+				problems.Add("icon-colors", fmt.Sprintf("must be between 2 and %d, got %d", generator.MaxIconColors, iconColors))
+			}
+			cli.CheckChoice("icon-compression", iconCompression, generator.IconCompressionLevels, problems)
+			if embedIconSize < 0 {
+				problems.Add("embed-icon-size", fmt.Sprintf("must not be negative, got %d", embedIconSize))
+			}
+			if embedIconBytes < 0 {
+				problems.Add("embed-icon-max-bytes", fmt.Sprintf("must not be negative, got %d", embedIconBytes))
+			}
+			cli.CheckChoice("format", format, generator.Formats, problems)
+			if minify && format == generator.FormatYAML {
+				problems.Add("minify", "cannot be combined with -format yaml")
+			}
+			cli.CheckChoice("colors", colors, localization.ColorModes, problems)
+			cli.CheckChoice("icon-tokens", iconTokens, localization.IconTokenModes, problems)
+			cli.CheckChoice("commands", commands, localization.CommandModes, problems)
+			contentTypes = nil
+			if contentList == "all" {
+				contentTypes = content.Names()
+			} else {
+				for _, name := range splitList(contentList) {
+					cli.CheckChoice("content", name, append([]string{content.Tech}, content.Names()...), problems)
+					if name != content.Tech && !slices.Contains(contentTypes, name) {
+						contentTypes = append(contentTypes, name)
+					}
+				}
+			}
+			coverageLangs = splitList(coverage)
+			if coverage == "all" {
+				coverageLangs = localization.Languages
+			} else {
+				for _, language := range coverageLangs {
+					cli.CheckChoice("localization-report", language, localization.Languages, problems)
+				}
+			}
+			if len(coverageLangs) > 0 && game.skipLocalization {
+				problems.Add("localization-report", "cannot be combined with -skip-localization")
+			}
+			templates = nil
+			for _, path := range splitList(templateList) {
+				tmpl, err := generator.LoadTemplate(path)
+				if err != nil {
+					problems.Add("template", fmt.Sprintf("%s: %v", path, err))
+					continue
+				}
+				templates = append(templates, tmpl)
+			}
+			if since != "" {
+				loaded, err := manifest.Load(since)
+				if err != nil {
+					problems.Add("since", err.Error())
+				}
+				sinceManifest = loaded
+			}
+			settings = settingsFingerprint(fs)
+		},
+		Run: func(ctx context.Context, args []string) (err error) {
+			var summary *runSummary
+			if summaryFile != "" {
+				summary = newRunSummary("parse")
+				game.phases = &summary.timer
+				defer func() {
+					if summaryErr := summary.write(summaryFile, game, err); summaryErr != nil && err == nil {
+						err = summaryErr
+					}
+				}()
+			}
+
+			game.printBanner()
+			logger := game.logger
+			logger.Info("Stellaris game directory", "dir", game.gameDir)
+
+			// Hashing the inputs reads every game file, so it's only done
+			// when a -since manifest can use them
+			var inputs *manifest.Inputs
+			if sinceManifest != nil {
+				inputs, err = readInputs(game, settings, iconOverrides, splitList(templateList))
+				if err != nil {
+					return err
+				}
+			}
+			if sinceManifest.UnchangedInputs(inputs) {
+				return reuseManifest(sinceManifest, outputDir, game, summary)
+			}
+			if sinceManifest != nil && sinceManifest.Inputs != nil {
+				logger.Info("Inputs changed since the manifest", "files", len(inputs.Changed(sinceManifest.Inputs)), "since", since)
+			}
+
+			data, err := game.load(ctx, slog.LevelInfo)
+			if err != nil {
+				return err
+			}
+			if summary != nil {
+				summary.recordData(data, game)
+			}
+
+			techTree := data.tree
+			if err := subset.check(techTree); err != nil {
+				return err
+			}
+			logger.Info("Built technology tree", "levels", techTree.GetMaxLevel()+1, "roots", len(techTree.GetRootNodes()))
+
+			// Log statistics
+			if areas := techTree.GetAreas(); len(areas) > 0 {
+				logger.Info("Research areas", "areas", areas)
+			}
+			if tiers := techTree.GetTiers(); len(tiers) > 0 {
+				logger.Info("Technology tiers", "tiers", tiers)
+			}
+
+			// Generate JSON output
+			logger.Info("Generating JSON data files")
+			jsonGenerator := generator.NewJSONGenerator(techTree)
+			jsonGenerator.SetLogger(logger)
+			jsonGenerator.SetGameDir(game.gameDir) // Set game directory for icon extraction
+			jsonGenerator.SetMods(game.mods)
+			jsonGenerator.SetRepeatableLevels(repeatableLevels)
+			jsonGenerator.SetColorMode(colors)
+			jsonGenerator.SetIconTokenMode(iconTokens)
+			jsonGenerator.SetCommandMode(commands, game.config.Text.CommandPlaceholders)
+			jsonGenerator.SetRepeatableBadges(repeatableBadges)
+			jsonGenerator.SetIconPlaceholders(iconPlaceholders)
+			jsonGenerator.SetIconOverrides(iconOverrides)
+			jsonGenerator.SetSubgraphs(subgraphs)
+			jsonGenerator.SetGraph(graph)
+			jsonGenerator.SetHTML(html)
+			jsonGenerator.SetSearchIndex(searchIndex)
+			jsonGenerator.SetSidebars(sidebars)
+			jsonGenerator.SetSkipIcons(skipIcons || onlyJSON)
+			jsonGenerator.SetIconEncoding(generator.IconEncoding{Sizes: iconSizes, Colors: iconColors, Compression: iconCompression})
+			if embedIcons {
+				jsonGenerator.SetEmbedIcons(&generator.IconEmbedding{MaxSize: embedIconSize, MaxBytes: embedIconBytes})
+			}
+			jsonGenerator.SetFull(full)
+			jsonGenerator.SetRawSource(game.keepSource)
+			jsonGenerator.SetMinify(minify)
+			jsonGenerator.SetFormat(format)
+			jsonGenerator.SetGzip(gzip)
+			jsonGenerator.SetOutputConfig(game.config.Output)
+			jsonGenerator.SetIconsConfig(game.config.Icons)
+			jsonGenerator.SetTimeline(data.timeline(game.config.Timeline))
+			if alternatives, ok := data.defines.Number(parser.DefineResearchAlternatives); ok {
+				jsonGenerator.SetResearchAlternatives(int(alternatives))
+			}
+			jsonGenerator.SetOverrides(data.parser.GetOverrides())
+			jsonGenerator.SetCategories(data.parser.GetCategories())
+			jsonGenerator.SetGameVersion(data.gameVersion)
+			jsonGenerator.SetAreaNames(data.areaNames)
+			jsonGenerator.SetFilter(whereExpr)
+			jsonGenerator.SetSubtrees(subset.subtrees())
+			jsonGenerator.SetTemplates(templates)
+			jsonGenerator.SetSince(sinceManifest)
+			jsonGenerator.SetInputs(inputs)
+			if withMechanics {
+				m := data.mechanics
+				m.Localize(func(key string) string {
+					name, _ := data.localization.LookupName(key, game.chain)
+					return name
+				})
+				logger.Info("Read research mechanics", "defines", len(m.Defines), "tierRules", len(m.Tiers), "staticModifiers", len(m.StaticModifiers))
+				jsonGenerator.SetMechanics(m)
+			}
+
+			contentCtx := &content.Context{
+				GameDir:      game.gameDir,
+				ModDirs:      game.mods,
+				Localization: data.localization,
+				Languages:    game.chain,
+				Triggers:     data.triggers,
+			}
+			game.phases.begin("content")
+			for _, name := range contentTypes {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				contentParser, err := content.New(name)
+				if err != nil {
+					return err
+				}
+				if !content.Present(contentParser, game.gameDir, game.mods) {
+					logger.Warn("Content type not found in the game or mod directories", "content", name, "dirs", strings.Join(contentParser.Directories(), ","))
+				}
+				count, err := contentParser.Parse(contentCtx)
+				if err != nil {
+					return err
+				}
+				logger.Info("Parsed content", "content", name, "count", count)
+				if summary != nil {
+					summary.Counts.Content[name] = count
+				}
+				contentParser.Emit(jsonGenerator)
+			}
+
+			var report []localization.LanguageCoverage
+			if len(coverageLangs) > 0 {
+				keys := make([]string, 0, len(data.technologies))
+				for key := range data.technologies {
+					keys = append(keys, key)
+				}
+				report = data.localization.Coverage(keys, coverageLangs)
+				jsonGenerator.SetLocalizationCoverage(report)
+			}
+			jsonGenerator.SetProgress(game.reporter)
+
+			absOutputPath, err := prepareOutputDir(outputDir)
+			if err != nil {
+				return err
+			}
+
+			game.phases.end()
+			start := time.Now()
+			err = jsonGenerator.GenerateContext(ctx, absOutputPath)
+			if summary != nil {
+				summary.recordGenerator(jsonGenerator, time.Since(start))
+			}
+			if err != nil {
+				return fmt.Errorf("failed to generate JSON files: %w", err)
+			}
+
+			logger.Info("JSON data files created", "dir", absOutputPath, "files", len(jsonGenerator.GeneratedFiles()))
+			heavy := 0
+			for _, usage := range jsonGenerator.SharedIcons() {
+				if usage.HeavyReuse {
+					heavy++
+				}
+			}
+			if heavy > 0 {
+				logger.Warn(fmt.Sprintf("Icons shared by %d or more technologies", generator.HeavyIconReuse), "icons", heavy, "report", jsonGenerator.IconUsageFileName())
+			}
+			if len(report) > 0 {
+				for _, language := range report {
+					logger.Info("Localization coverage", "language", language.Language, "coverage", fmt.Sprintf("%.1f%%", language.Coverage),
+						"missing", language.Missing, "expected", language.Expected, "report", jsonGenerator.CoverageFileName())
+				}
+			}
+			if skipped := jsonGenerator.SkippedFiles(); len(skipped) > 0 {
+				logger.Info("Skipped unchanged JSON files", "count", len(skipped), "since", since)
+			}
+
+			logger.Info("JSON files ready for use with Docusaurus")
+			game.finish(absOutputPath)
+			return nil
+		},
+	}
+}
+
+// reuseManifest finishes a parse run whose inputs are unchanged since the
+// -since manifest: nothing is parsed or generated, and the manifest is
+// written to the output directory as it is, since every file is unchanged
+func reuseManifest(since *manifest.Manifest, outputDir string, game *gameOptions, summary *runSummary) error {
+	absOutputPath, err := prepareOutputDir(outputDir)
+	if err != nil {
+		return err
+	}
+	manifestPath := filepath.Join(absOutputPath, game.config.Output.ManifestFile)
+	if err := os.MkdirAll(filepath.Dir(manifestPath), 0755); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+	if err := since.Save(manifestPath); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if summary != nil {
+		summary.Counts.FilesWritten = 1
+		summary.Counts.FilesSkipped = len(since.Files)
+	}
+
+	game.logger.Info("Inputs unchanged since the manifest, skipped parsing and generating", "files", len(since.Files))
+	game.finish(absOutputPath)
+	return nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"strings"
+
+	"github.com/danaketh/StellarisDataParser/internal/cli"
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/savegame"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+// remainingCommand reports the technologies an empire of a save has not
+// researched yet
+func remainingCommand() *cli.Command {
+	game := &gameOptions{}
+	where := &whereOption{}
+	var (
+		savePath     string
+		empireName   string
+		outputFile   string
+		markdownFile string
+	)
+
+	return &cli.Command{
+		Name:    "remaining",
+		Summary: "Report the technologies an empire in a save game has left to research",
+		Usage:   "-save <file.sav> [-empire <id or name>] [flags]",
+		Notes: []string{
+			"Technologies are listed per area in a suggested research order, prerequisites first",
+			"Without -empire, the empire of the first player is used",
+			"With -where, only the matching technologies are listed and counted",
+		},
+		Examples: []string{
+			"stellaris-data-parser remaining -save ./autosave_2250.01.01.sav",
+			"stellaris-data-parser remaining -save ./autosave.sav -empire 3 -markdown ./remaining.md",
+			"stellaris-data-parser remaining -save ./autosave.sav -where '!isRare && acquisition == \"research\"'",
+		},
+		SetFlags: func(fs *flag.FlagSet) {
+			game.register(fs)
+			fs.StringVar(&savePath, "save", "", "Path to a Stellaris .sav file (required)")
+			fs.StringVar(&empireName, "empire", "", "ID or name of the empire, the first player's empire if empty")
+			fs.StringVar(&outputFile, "output", "remaining-research.json", "Path of the JSON report, empty to skip it")
+			fs.StringVar(&markdownFile, "markdown", "remaining-research.md", "Path of the Markdown report, empty to skip it")
+			where.register(fs, "Only list technologies matching an expression, e.g. 'tier >= 3 && isRare'")
+		},
+		Validate: func(fs *flag.FlagSet, problems *cli.Problems) {
+			game.validate(problems)
+			where.validate(problems)
+			if savePath == "" {
+				problems.AddWithSuggestion("save", "save file is required",
+					"saves are in Documents/Paradox Interactive/Stellaris/save games/")
+			} else if _, err := os.Stat(savePath); os.IsNotExist(err) {
+				problems.Add("save", fmt.Sprintf("save file does not exist: %s", savePath))
+			}
+			if outputFile == "" && markdownFile == "" {
+				problems.Add("output", "nothing to write, -output and -markdown are both empty")
+			}
+		},
+		Run: func(ctx context.Context, args []string) error {
+			data, err := game.load(ctx, slog.LevelDebug)
+			if err != nil {
+				return err
+			}
+
+			empire, err := loadEmpire(savePath, empireName)
+			if err != nil {
+				return err
+			}
+
+			matched, err := where.matching(data, game.config.Timeline)
+			if err != nil {
+				return err
+			}
+
+			researched := make(map[string]bool, len(empire.Technologies))
+			for key := range empire.Technologies {
+				researched[key] = true
+			}
+			// Technologies not matching -where are left out like researched ones
+			listed := researched
+			if where.expr != nil {
+				listed = maps.Clone(researched)
+				for key := range data.technologies {
+					if _, ok := matched[key]; !ok {
+						listed[key] = true
+					}
+				}
+			}
+			remaining := data.tree.Remaining(listed)
+			status := data.tree.ResearchStatus(researched)
+
+			if outputFile != "" {
+				content, err := json.MarshalIndent(remainingReport(empire, remaining, status), "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to encode remaining research: %w", err)
+				}
+				if err := os.WriteFile(outputFile, append(content, '\n'), 0644); err != nil {
+					return fmt.Errorf("failed to write remaining research: %w", err)
+				}
+				fmt.Printf("✓ Wrote %s\n", outputFile)
+			}
+			if markdownFile != "" {
+				markdown := remainingMarkdown(empire, data.tree.GetAreas(), data.areaNames, remaining, status)
+				if err := os.WriteFile(markdownFile, []byte(markdown), 0644); err != nil {
+					return fmt.Errorf("failed to write remaining research: %w", err)
+				}
+				fmt.Printf("✓ Wrote %s\n", markdownFile)
+			}
+
+			count, cost := remainingTotals(remaining)
+			fmt.Printf("✓ %s: %d technologies remaining, total cost %d\n", empire.Name, count, cost)
+			game.finish(empire.Name)
+			return nil
+		},
+	}
+}
+
+// remainingTotals returns the number and total cost of the remaining
+// technologies of all areas
+func remainingTotals(remaining map[string]tree.ResearchPath) (count, cost int) {
+	for _, path := range remaining {
+		count += len(path.Steps)
+		cost += path.TotalCost
+	}
+	return count, cost
+}
+
+// remainingReport returns the JSON report of the remaining technologies
+func remainingReport(empire *savegame.Empire, remaining map[string]tree.ResearchPath, status map[string]string) map[string]interface{} {
+	areas := make(map[string]interface{}, len(remaining))
+	for area, path := range remaining {
+		techs := make([]map[string]interface{}, len(path.Steps))
+		for i, step := range path.Steps {
+			techs[i] = map[string]interface{}{
+				"key":            step.Node.Tech.Key,
+				"name":           step.Node.Tech.Name,
+				"tier":           step.Node.Tech.Tier,
+				"cost":           step.Node.Tech.Cost,
+				"cumulativeCost": step.CumulativeCost,
+				"status":         status[step.Node.Tech.Key],
+				"acquisition":    step.Node.Tech.Acquisition(),
+			}
+		}
+		areas[area] = map[string]interface{}{
+			"remaining":    len(path.Steps),
+			"totalCost":    path.TotalCost,
+			"technologies": techs,
+		}
+	}
+
+	count, cost := remainingTotals(remaining)
+	return map[string]interface{}{
+		"empire": map[string]interface{}{
+			"id":     empire.ID,
+			"name":   empire.Name,
+			"player": empire.Player,
+		},
+		"remaining": count,
+		"totalCost": cost,
+		"areas":     areas,
+	}
+}
+
+// remainingMarkdown returns the Markdown report of the remaining
+// technologies, one table per area headed by its localized name
+func remainingMarkdown(empire *savegame.Empire, areas []string, areaNames map[string]string, remaining map[string]tree.ResearchPath, status map[string]string) string {
+	var b strings.Builder
+	count, cost := remainingTotals(remaining)
+	fmt.Fprintf(&b, "# Remaining research: %s\n\n", empire.Name)
+	fmt.Fprintf(&b, "%d technologies remaining, total cost %d.\n", count, cost)
+
+	for _, area := range areas {
+		path, exists := remaining[area]
+		if !exists {
+			continue
+		}
+		title := areaNames[area]
+		if title == "" {
+			title = area
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", title)
+		fmt.Fprintf(&b, "%d technologies, total cost %d.\n\n", len(path.Steps), path.TotalCost)
+		b.WriteString("| # | Technology | Tier | Cost | Cumulative | Status |\n")
+		b.WriteString("|---|------------|------|------|------------|--------|\n")
+		for i, step := range path.Steps {
+			tech := step.Node.Tech
+			name := "`" + tech.Key + "`"
+			if tech.Name != "" {
+				name = fmt.Sprintf("%s (`%s`)", strings.ReplaceAll(tech.Name, "|", "\\|"), tech.Key)
+			}
+			state := status[tech.Key]
+			if acquisition := tech.Acquisition(); acquisition != models.AcquisitionResearch {
+				state += ", " + acquisition
+			}
+			fmt.Fprintf(&b, "| %d | %s | %d | %d | %d | %s |\n", i+1, name, tech.Tier, tech.Cost, step.CumulativeCost, state)
+		}
+	}
+	return b.String()
+}
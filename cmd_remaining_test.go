@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testGamestate is a save whose player empire researched tech_lasers_1
+const testGamestate = `player={ { name="Player" country=0 } }
+country={
+	0={
+		name="United Nations of Earth"
+		tech_status={ technology="tech_lasers_1" level=1 }
+	}
+}
+`
+
+// writeTestSave writes testGamestate as a plain save file
+func writeTestSave(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "autosave.sav")
+	if err := os.WriteFile(path, []byte(testGamestate), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRemainingWhere(t *testing.T) {
+	gameDir := writeTechGame(t)
+	outputFile := filepath.Join(t.TempDir(), "remaining.json")
+
+	_, code := runCommand(t, remainingCommand(), "-input", gameDir, "-save", writeTestSave(t), "-quiet",
+		"-output", outputFile, "-markdown", "", "-where", "!isRare")
+	if code != 0 {
+		t.Fatalf("remaining exited with %d", code)
+	}
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		Remaining int `json:"remaining"`
+		TotalCost int `json:"totalCost"`
+	}
+	if err := json.Unmarshal(content, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Remaining != 2 || report.TotalCost != 350 {
+		t.Errorf("Expected tech_lasers_2 and tech_society_1 to remain, got %+v", report)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+
+	"github.com/danaketh/StellarisDataParser/internal/cli"
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/savegame"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+// saveCommand marks each technology as researched, available or locked for
+// an empire of a save game
+func saveCommand() *cli.Command {
+	game := &gameOptions{}
+	where := &whereOption{}
+	var (
+		savePath   string
+		empireName string
+		outputFile string
+	)
+
+	return &cli.Command{
+		Name:    "save",
+		Summary: "Write the research status of an empire in a save game",
+		Usage:   "-save <file.sav> [-empire <id or name>] [flags]",
+		Notes: []string{
+			"Without -empire, the empire of the first player is used",
+			"Ironman saves use a binary format and can't be read",
+			"With -where, only the status of the matching technologies is written and counted",
+		},
+		Examples: []string{
+			"stellaris-data-parser save -save ~/Documents/Paradox\\ Interactive/Stellaris/save\\ games/unitednations/autosave_2250.01.01.sav",
+			"stellaris-data-parser save -save ./autosave.sav -empire 3 -output ./status.json",
+		},
+		SetFlags: func(fs *flag.FlagSet) {
+			game.register(fs)
+			fs.StringVar(&savePath, "save", "", "Path to a Stellaris .sav file (required)")
+			fs.StringVar(&empireName, "empire", "", "ID or name of the empire, the first player's empire if empty")
+			fs.StringVar(&outputFile, "output", "research-status.json", "Path of the JSON file to write")
+			where.register(fs, "Only write the status of technologies matching an expression, e.g. 'area == \"physics\"'")
+		},
+		Validate: func(fs *flag.FlagSet, problems *cli.Problems) {
+			game.validate(problems)
+			where.validate(problems)
+			if savePath == "" {
+				problems.AddWithSuggestion("save", "save file is required",
+					"saves are in Documents/Paradox Interactive/Stellaris/save games/")
+			} else if _, err := os.Stat(savePath); os.IsNotExist(err) {
+				problems.Add("save", fmt.Sprintf("save file does not exist: %s", savePath))
+			}
+			if outputFile == "" {
+				problems.Add("output", "must not be empty")
+			}
+		},
+		Run: func(ctx context.Context, args []string) error {
+			data, err := game.load(ctx, slog.LevelDebug)
+			if err != nil {
+				return err
+			}
+
+			empire, err := loadEmpire(savePath, empireName)
+			if err != nil {
+				return err
+			}
+
+			matched, err := where.matching(data, game.config.Timeline)
+			if err != nil {
+				return err
+			}
+
+			overlay := researchOverlay(data.tree, empire, matched)
+			content, err := json.MarshalIndent(overlay, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode research status: %w", err)
+			}
+			if err := os.WriteFile(outputFile, append(content, '\n'), 0644); err != nil {
+				return fmt.Errorf("failed to write research status: %w", err)
+			}
+
+			counts := overlay["counts"].(map[string]int)
+			fmt.Printf("✓ %s: %d researched, %d available, %d locked\n", empire.Name,
+				counts[tree.StatusResearched], counts[tree.StatusAvailable], counts[tree.StatusLocked])
+			if unknown := overlay["unknown"].([]string); len(unknown) > 0 {
+				fmt.Printf("⚠ %d researched technologies are not in the game data (missing -mods?)\n", len(unknown))
+			}
+			fmt.Printf("✓ Wrote %s\n", outputFile)
+
+			game.finish(empire.Name)
+			return nil
+		},
+	}
+}
+
+// loadEmpire reads a save and selects an empire, listing the empires of the
+// save if the selection fails
+func loadEmpire(savePath, selector string) (*savegame.Empire, error) {
+	fmt.Printf("💾 Reading save %s\n", savePath)
+	save, err := savegame.Open(savePath)
+	if err != nil {
+		return nil, err
+	}
+
+	empire, err := save.Find(selector)
+	if err != nil {
+		fmt.Println("Empires in the save:")
+		for _, e := range save.Empires {
+			player := ""
+			if e.Player != "" {
+				player = fmt.Sprintf(" (player %s)", e.Player)
+			}
+			fmt.Printf("  %d: %s%s\n", e.ID, e.Name, player)
+		}
+		return nil, err
+	}
+	return empire, nil
+}
+
+// researchOverlay returns the research status of the listed technologies for
+// an empire, the researched level of each technology, the number of listed
+// technologies in each status and the researched technologies missing from
+// the tree
+func researchOverlay(techTree *tree.TechTree, empire *savegame.Empire, listed map[string]*models.Technology) map[string]interface{} {
+	researched := make(map[string]bool, len(empire.Technologies))
+	unknown := []string{}
+	for key := range empire.Technologies {
+		researched[key] = true
+		if _, exists := techTree.GetNode(key); !exists {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+
+	status := techTree.ResearchStatus(researched)
+	for key := range status {
+		if _, ok := listed[key]; !ok {
+			delete(status, key)
+		}
+	}
+	counts := map[string]int{tree.StatusResearched: 0, tree.StatusAvailable: 0, tree.StatusLocked: 0}
+	for _, s := range status {
+		counts[s]++
+	}
+
+	return map[string]interface{}{
+		"empire": map[string]interface{}{
+			"id":     empire.ID,
+			"name":   empire.Name,
+			"player": empire.Player,
+		},
+		"levels":       empire.Technologies,
+		"counts":       counts,
+		"technologies": status,
+		"unknown":      unknown,
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveWhere(t *testing.T) {
+	gameDir := writeTechGame(t)
+	outputFile := filepath.Join(t.TempDir(), "status.json")
+
+	_, code := runCommand(t, saveCommand(), "-input", gameDir, "-save", writeTestSave(t), "-quiet",
+		"-output", outputFile, "-where", `area == "physics"`)
+	if code != 0 {
+		t.Fatalf("save exited with %d", code)
+	}
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var overlay struct {
+		Counts       map[string]int    `json:"counts"`
+		Technologies map[string]string `json:"technologies"`
+	}
+	if err := json.Unmarshal(content, &overlay); err != nil {
+		t.Fatal(err)
+	}
+	if len(overlay.Technologies) != 3 || overlay.Technologies["tech_society_1"] != "" {
+		t.Errorf("Expected the status of the physics technologies only, got %v", overlay.Technologies)
+	}
+	if overlay.Counts["researched"] != 1 || overlay.Counts["available"] != 2 {
+		t.Errorf("Expected 1 researched and 2 available technologies, got %v", overlay.Counts)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/danaketh/StellarisDataParser/internal/cli"
+	"github.com/danaketh/StellarisDataParser/lib/generator"
+	"github.com/danaketh/StellarisDataParser/lib/localization"
+	"github.com/danaketh/StellarisDataParser/lib/server"
+)
+
+// serveCommand parses the game data once and serves it over a REST API
+func serveCommand() *cli.Command {
+	game := &gameOptions{}
+	var (
+		addr             string
+		repeatableLevels int
+		colors           string
+		iconTokens       string
+		commands         string
+	)
+
+	return &cli.Command{
+		Name:    "serve",
+		Summary: "Serve technology data over a REST API",
+		Usage:   "[-input <game_directory>] [-addr <host:port>] [flags]",
+		Notes: []string{
+			"GET /api/technologies lists technologies; filter with area, tier, category, mod, icon, search and where, page with offset and limit",
+			"GET /api/technologies/{key} returns one technology and the technologies it unlocks",
+			"GET /api/areas lists research areas with their technology counts",
+			"GET /api/tree?area=physics returns the prerequisite graph as nodes and edges",
+		},
+		Examples: []string{
+			"stellaris-data-parser serve -input \"C:\\Steam\\steamapps\\common\\Stellaris\" -addr :8080",
+			"curl 'http://localhost:8080/api/technologies?area=physics&where=isRare&limit=10'",
+		},
+		SetFlags: func(fs *flag.FlagSet) {
+			game.register(fs)
+			fs.StringVar(&addr, "addr", "localhost:8080", "Address to listen on")
+			fs.IntVar(&repeatableLevels, "repeatable-levels", generator.DefaultRepeatableLevels, "Number of levels in the cost table of infinite repeatable technologies")
+			fs.StringVar(&colors, "colors", localization.ColorStrip, "How §Y...§! color markup in names and descriptions is written: strip, html or raw")
+			fs.StringVar(&commands, "commands", localization.CommandStrip, "How [Root.GetName] scripting commands in names and descriptions are written: strip, placeholder or raw")
+			fs.StringVar(&iconTokens, "icon-tokens", localization.IconTokenRaw, "How £energy£ icon references in names and descriptions are written: raw, strip, token or html")
+		},
+		Validate: func(fs *flag.FlagSet, problems *cli.Problems) {
+			game.validate(problems)
+			if repeatableLevels < 0 {
+				problems.Add("repeatable-levels", fmt.Sprintf("must not be negative, got %d", repeatableLevels))
+			}
+			cli.CheckChoice("colors", colors, localization.ColorModes, problems)
+			cli.CheckChoice("icon-tokens", iconTokens, localization.IconTokenModes, problems)
+			cli.CheckChoice("commands", commands, localization.CommandModes, problems)
+		},
+		Run: func(ctx context.Context, args []string) error {
+			game.printBanner()
+
+			data, err := game.load(ctx, slog.LevelInfo)
+			if err != nil {
+				return err
+			}
+
+			jsonGenerator := generator.NewJSONGenerator(data.tree)
+			jsonGenerator.SetRepeatableLevels(repeatableLevels)
+			jsonGenerator.SetColorMode(colors)
+			jsonGenerator.SetIconTokenMode(iconTokens)
+			jsonGenerator.SetCommandMode(commands, game.config.Text.CommandPlaceholders)
+			jsonGenerator.SetTimeline(data.timeline(game.config.Timeline))
+
+			httpServer := &http.Server{
+				Addr:              addr,
+				Handler:           server.NewServer(data.tree, jsonGenerator).Handler(),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			// Shut down cleanly on Ctrl+C
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				httpServer.Shutdown(shutdownCtx)
+			}()
+
+			game.finish("serving on " + addr)
+
+			fmt.Printf("\n🚀 Serving %d technologies on http://%s/api/technologies\n", len(data.technologies), addr)
+			fmt.Println("   Press Ctrl+C to stop")
+
+			if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("server failed: %w", err)
+			}
+			return nil
+		},
+	}
+}
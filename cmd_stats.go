@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/danaketh/StellarisDataParser/internal/cli"
+	"github.com/danaketh/StellarisDataParser/lib/stats"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+// statsCommand prints counts and distributions of the technology tree
+func statsCommand() *cli.Command {
+	game := &gameOptions{}
+	where := &whereOption{}
+	var outputFile string
+
+	return &cli.Command{
+		Name:    "stats",
+		Summary: "Print statistics of the technology tree and write them to stats.json",
+		Usage:   "[-input <game_directory>] [flags]",
+		Notes: []string{
+			"Counts technologies per area, tier and category, and finds the longest prerequisite chain and the technologies unlocking the most others",
+			"With -where, only the matching technologies are counted, and chains and dependents only run through matching technologies",
+		},
+		Examples: []string{
+			"stellaris-data-parser stats -input \"C:\\Steam\\steamapps\\common\\Stellaris\"",
+			"stellaris-data-parser stats -mods ./my_mod -output ./output/stats.json",
+			"stellaris-data-parser stats -where 'area == \"physics\" && !isRare'",
+		},
+		SetFlags: func(fs *flag.FlagSet) {
+			game.register(fs)
+			fs.StringVar(&outputFile, "output", "stats.json", "Path of the JSON file to write, empty to only print the statistics")
+			where.register(fs, "Only count technologies matching an expression, e.g. 'tier >= 3 && isRare'")
+		},
+		Validate: func(fs *flag.FlagSet, problems *cli.Problems) {
+			game.validate(problems)
+			where.validate(problems)
+		},
+		Run: func(ctx context.Context, args []string) error {
+			data, err := game.load(ctx, slog.LevelDebug)
+			if err != nil {
+				return err
+			}
+
+			techTree := data.tree
+			if where.expr != nil {
+				technologies, err := where.matching(data, game.config.Timeline)
+				if err != nil {
+					return err
+				}
+				techTree = tree.NewTechTree(technologies)
+			}
+
+			summary := stats.Compute(techTree)
+			printStats(summary)
+
+			if outputFile != "" {
+				content, err := json.MarshalIndent(summary, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to encode statistics: %w", err)
+				}
+				if err := os.WriteFile(outputFile, append(content, '\n'), 0644); err != nil {
+					return fmt.Errorf("failed to write statistics: %w", err)
+				}
+				fmt.Printf("\n✓ Wrote %s\n", outputFile)
+			}
+
+			game.finish(fmt.Sprintf("%d technologies", summary.Technologies))
+			return nil
+		},
+	}
+}
+
+// printStats prints the statistics as tables
+func printStats(s stats.Stats) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Technologies\t%d\n", s.Technologies)
+	fmt.Fprintf(w, "Rare\t%d\n", s.Rare)
+	fmt.Fprintf(w, "Dangerous\t%d\n", s.Dangerous)
+
+	fmt.Fprintf(w, "\nArea\tTechnologies\n")
+	for _, area := range sortedKeys(s.ByArea) {
+		fmt.Fprintf(w, "%s\t%d\n", area, s.ByArea[area])
+	}
+
+	tiers := make([]int, 0, len(s.ByTier))
+	for tier := range s.ByTier {
+		tiers = append(tiers, tier)
+	}
+	sort.Ints(tiers)
+	fmt.Fprintf(w, "\nTier\tTechnologies\tAverage cost\n")
+	for _, tier := range tiers {
+		fmt.Fprintf(w, "%d\t%d\t%.1f\n", tier, s.ByTier[tier], s.AverageCostByTier[tier])
+	}
+
+	fmt.Fprintf(w, "\nCategory\tTechnologies\n")
+	for _, category := range sortedKeys(s.ByCategory) {
+		fmt.Fprintf(w, "%s\t%d\n", category, s.ByCategory[category])
+	}
+
+	fmt.Fprintf(w, "\nMost dependents\tDependents\n")
+	for _, fanOut := range s.LargestFanOut {
+		fmt.Fprintf(w, "%s\t%d\n", fanOut.Tech, fanOut.Dependents)
+	}
+	w.Flush()
+
+	fmt.Printf("\nLongest chain (%d technologies):\n  %s\n", len(s.LongestChain), strings.Join(s.LongestChain, " → "))
+}
+
+// sortedKeys returns the keys of a count map in lexical order
+func sortedKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/internal/cli"
+	"github.com/danaketh/StellarisDataParser/lib/stats"
+)
+
+// testTechnologies are the technologies of the game written by writeTechGame
+const testTechnologies = `tech_lasers_1 = { area = physics tier = 1 cost = 100 }
+tech_lasers_2 = { area = physics tier = 2 cost = 200 prerequisites = { "tech_lasers_1" } }
+tech_rare = { area = physics tier = 2 cost = 300 is_rare = yes prerequisites = { "tech_lasers_1" } }
+tech_society_1 = { area = society tier = 1 cost = 150 }
+`
+
+// writeTechGame writes a game directory with testTechnologies
+func writeTechGame(t *testing.T) string {
+	t.Helper()
+	return writeGame(t, map[string]string{"common/technology/00_tech.txt": testTechnologies})
+}
+
+// runCommand runs a command with args and returns what it printed to stdout
+// and its exit code
+func runCommand(t *testing.T, command *cli.Command, args ...string) (string, int) {
+	t.Helper()
+	app := &cli.App{Name: "stellaris-data-parser", Commands: []*cli.Command{command}, Output: io.Discard}
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		content, _ := io.ReadAll(reader)
+		output <- string(content)
+	}()
+	code := app.Run(append([]string{command.Name}, args...))
+	writer.Close()
+	return <-output, code
+}
+
+func TestStatsWhere(t *testing.T) {
+	gameDir := writeTechGame(t)
+	outputFile := filepath.Join(t.TempDir(), "stats.json")
+
+	if _, code := runCommand(t, statsCommand(), "-input", gameDir, "-output", outputFile, "-quiet", "-where", `area == "physics" && !isRare`); code != 0 {
+		t.Fatalf("stats exited with %d", code)
+	}
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary stats.Stats
+	if err := json.Unmarshal(content, &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Technologies != 2 || summary.ByArea["physics"] != 2 || summary.ByArea["society"] != 0 || summary.Rare != 0 {
+		t.Errorf("Expected only the 2 common physics technologies to be counted, got %+v", summary)
+	}
+	if len(summary.LongestChain) != 2 {
+		t.Errorf("Expected the chain through the matching technologies, got %v", summary.LongestChain)
+	}
+
+	if _, code := runCommand(t, statsCommand(), "-input", gameDir, "-output", "", "-where", "bogus > 1"); code == 0 {
+		t.Error("Expected an unknown field in -where to be rejected")
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/danaketh/StellarisDataParser/internal/cli"
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+// treeCommand prints the technology tree or the prerequisites of one technology
+func treeCommand() *cli.Command {
+	game := &gameOptions{}
+	where := &whereOption{}
+	var area string
+
+	return &cli.Command{
+		Name:    "tree",
+		Summary: "Print the prerequisites of a technology or a summary of the tree",
+		Usage:   "[-input <game_directory>] [flags] [technology | path <technology>]",
+		Notes: []string{
+			"tree path <technology> prints the cheapest set of technologies to research to reach it, in research order",
+			"-where limits the summary and the technologies a technology unlocks; prerequisites and research paths are always listed in full",
+		},
+		Examples: []string{
+			"stellaris-data-parser tree -input \"C:\\Steam\\steamapps\\common\\Stellaris\"",
+			"stellaris-data-parser tree -input \"C:\\Steam\\steamapps\\common\\Stellaris\" tech_battleships",
+			"stellaris-data-parser tree path tech_mega_engineering",
+			"stellaris-data-parser tree -where 'isRare || isDangerous'",
+		},
+		SetFlags: func(fs *flag.FlagSet) {
+			game.register(fs)
+			fs.StringVar(&area, "area", "", "Only summarize one research area")
+			where.register(fs, "Only summarize and list technologies matching an expression, e.g. 'tier >= 3 && isRare'")
+		},
+		Validate: func(fs *flag.FlagSet, problems *cli.Problems) {
+			game.validate(problems)
+			where.validate(problems)
+			if fs.Arg(0) == "path" {
+				if fs.NArg() != 2 {
+					problems.AddWithSuggestion("", "path expects exactly one technology",
+						"example: tree path tech_mega_engineering")
+				}
+			} else if fs.NArg() > 1 {
+				problems.Add("", fmt.Sprintf("expected at most one technology, got %d", fs.NArg()))
+			}
+		},
+		Run: func(ctx context.Context, args []string) error {
+			data, err := game.load(ctx, slog.LevelDebug)
+			if err != nil {
+				return err
+			}
+
+			game.finish("")
+
+			matched, err := where.matching(data, game.config.Timeline)
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 0 {
+				techTree := data.tree
+				if where.expr != nil {
+					techTree = tree.NewTechTree(matched)
+				}
+				printTreeSummary(techTree, area)
+				return nil
+			}
+
+			if args[0] == "path" {
+				path, exists := data.tree.PathTo(args[1])
+				if !exists {
+					return unknownTechnology(args[1], data)
+				}
+				printResearchPath(args[1], path)
+				return nil
+			}
+
+			node, exists := data.tree.GetNode(args[0])
+			if !exists {
+				return unknownTechnology(args[0], data)
+			}
+
+			fmt.Println("Prerequisites:")
+			printPrerequisites(node, 0, make(map[string]bool))
+
+			var dependents []*tree.TechNode
+			for _, dependent := range node.Dependents {
+				if _, ok := matched[dependent.Tech.Key]; ok {
+					dependents = append(dependents, dependent)
+				}
+			}
+			if len(dependents) > 0 {
+				fmt.Println("\nUnlocks:")
+				sort.Slice(dependents, func(i, j int) bool { return dependents[i].Tech.Key < dependents[j].Tech.Key })
+				for _, dependent := range dependents {
+					fmt.Printf("  %s\n", describeNode(dependent))
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// unknownTechnology returns the error for a technology key that doesn't
+// exist, suggesting the closest known key
+func unknownTechnology(key string, data *gameData) error {
+	keys := make([]string, 0, len(data.technologies))
+	for k := range data.technologies {
+		keys = append(keys, k)
+	}
+	if suggestion := cli.Suggest(key, keys); suggestion != "" {
+		return fmt.Errorf("unknown technology %q (did you mean %q?)", key, suggestion)
+	}
+	return fmt.Errorf("unknown technology %q", key)
+}
+
+// printResearchPath prints the technologies on a research path with their
+// cumulative cost
+func printResearchPath(target string, path tree.ResearchPath) {
+	fmt.Printf("Research path to %s: %d technologies, total cost %d\n", target, len(path.Steps), path.TotalCost)
+	for i, step := range path.Steps {
+		fmt.Printf("  %3d. %s (cumulative %d)\n", i+1, describeNode(step.Node), step.CumulativeCost)
+	}
+}
+
+// printPrerequisites prints a node and its prerequisites as an indented tree.
+// Technologies already printed are not expanded again.
+func printPrerequisites(node *tree.TechNode, depth int, seen map[string]bool) {
+	indent := strings.Repeat("  ", depth+1)
+	if seen[node.Tech.Key] {
+		fmt.Printf("%s%s (see above)\n", indent, node.Tech.Key)
+		return
+	}
+	seen[node.Tech.Key] = true
+
+	fmt.Printf("%s%s\n", indent, describeNode(node))
+
+	dependencies := append([]*tree.TechNode(nil), node.Dependencies...)
+	sort.Slice(dependencies, func(i, j int) bool { return dependencies[i].Tech.Key < dependencies[j].Tech.Key })
+	for _, dependency := range dependencies {
+		printPrerequisites(dependency, depth+1, seen)
+	}
+}
+
+// describeNode formats a technology for the tree output
+func describeNode(node *tree.TechNode) string {
+	name := node.Tech.Key
+	if node.Tech.Name != "" {
+		name = fmt.Sprintf("%s (%s)", node.Tech.Key, node.Tech.Name)
+	}
+	details := fmt.Sprintf("%s, tier %d, cost %d", node.Tech.Area, node.Tech.Tier, node.Tech.Cost)
+	if acquisition := node.Tech.Acquisition(); acquisition != models.AcquisitionResearch {
+		details += ", " + acquisition
+	}
+	return fmt.Sprintf("%s [%s]", name, details)
+}
+
+// printTreeSummary prints the number of technologies per tier for each area
+func printTreeSummary(techTree *tree.TechTree, area string) {
+	for _, a := range techTree.GetAreas() {
+		if area != "" && a != area {
+			continue
+		}
+
+		nodes := techTree.GetNodesByArea(a)
+		perTier := make(map[int]int)
+		for _, node := range nodes {
+			perTier[node.Tech.Tier]++
+		}
+
+		fmt.Printf("%s: %d technologies\n", a, len(nodes))
+		for _, tier := range techTree.GetTiers() {
+			if perTier[tier] > 0 {
+				fmt.Printf("  tier %d: %d\n", tier, perTier[tier])
+			}
+		}
+	}
+	fmt.Printf("\n%d levels, %d root technologies\n", techTree.GetMaxLevel()+1, len(techTree.GetRootNodes()))
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTreeWhere(t *testing.T) {
+	gameDir := writeTechGame(t)
+
+	output, code := runCommand(t, treeCommand(), "-input", gameDir, "-quiet", "-where", "isRare")
+	if code != 0 {
+		t.Fatalf("tree exited with %d", code)
+	}
+	if !strings.Contains(output, "physics: 1 technologies") || strings.Contains(output, "society") {
+		t.Errorf("Expected the summary of the rare technology only, got:\n%s", output)
+	}
+
+	output, code = runCommand(t, treeCommand(), "-input", gameDir, "-quiet", "-where", "!isRare", "tech_lasers_1")
+	if code != 0 {
+		t.Fatalf("tree exited with %d", code)
+	}
+	if !strings.Contains(output, "Unlocks:") || !strings.Contains(output, "tech_lasers_2") || strings.Contains(output, "tech_rare") {
+		t.Errorf("Expected only the matching technologies to be listed as unlocked, got:\n%s", output)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/danaketh/StellarisDataParser/internal/cli"
+	"github.com/danaketh/StellarisDataParser/lib/config"
+	"github.com/danaketh/StellarisDataParser/lib/generator"
+	"github.com/danaketh/StellarisDataParser/lib/manifest"
+)
+
+// upgradeCommand converts the JSON files of an older run to the current
+// schema version
+func upgradeCommand() *cli.Command {
+	var (
+		inputDir  string
+		outputDir string
+		check     bool
+	)
+
+	return &cli.Command{
+		Name:    "upgrade",
+		Summary: "Upgrade generated JSON files to the current schema version",
+		Usage:   "[-input <output_directory>] [-output <directory>] [-check]",
+		Notes: []string{
+			"Fields that need the game data can't be derived; the files are upgraded without them and the fields are listed, run parse again to write them",
+			"With -check, nothing is written and the exit status is 1 if any file has an older schema version",
+		},
+		Examples: []string{
+			"stellaris-data-parser upgrade -input ./output",
+			"stellaris-data-parser upgrade -input ./static/data -check",
+		},
+		SetFlags: func(fs *flag.FlagSet) {
+			fs.StringVar(&inputDir, "input", "output", "Directory of the generated files to upgrade")
+			fs.StringVar(&outputDir, "output", "", "Directory to write the upgraded files to, the input directory if empty")
+			fs.BoolVar(&check, "check", false, "Only report files with an older schema version")
+		},
+		Validate: func(fs *flag.FlagSet, problems *cli.Problems) {
+			if info, err := os.Stat(inputDir); err != nil || !info.IsDir() {
+				problems.Add("input", fmt.Sprintf("directory not found: %s", inputDir))
+			}
+			validateOutputDir(outputDir, problems)
+		},
+		Run: func(ctx context.Context, args []string) error {
+			if outputDir == "" {
+				outputDir = inputDir
+			}
+			files, err := generatedJSONFiles(inputDir)
+			if err != nil {
+				return err
+			}
+
+			outdated := 0
+			for _, name := range files {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				content, err := os.ReadFile(filepath.Join(inputDir, filepath.FromSlash(name)))
+				if err != nil {
+					return fmt.Errorf("failed to read %s: %w", name, err)
+				}
+				upgraded, result, err := generator.Upgrade(content)
+				if err != nil {
+					return fmt.Errorf("failed to upgrade %s: %w", name, err)
+				}
+				if !result.Upgraded() {
+					if !check && outputDir != inputDir {
+						if err := writeUpgraded(outputDir, name, content); err != nil {
+							return err
+						}
+					}
+					continue
+				}
+
+				outdated++
+				fmt.Printf("%s: schema version %d → %d\n", name, result.From, generator.SchemaVersion)
+				if len(result.Missing) > 0 {
+					fmt.Printf("    missing: %s\n", strings.Join(result.Missing, ", "))
+				}
+				if !check {
+					if err := writeUpgraded(outputDir, name, upgraded); err != nil {
+						return err
+					}
+					if err := recompress(inputDir, outputDir, name, upgraded); err != nil {
+						return err
+					}
+				}
+			}
+
+			switch {
+			case outdated == 0:
+				fmt.Printf("✓ %d files at schema version %d\n", len(files), generator.SchemaVersion)
+			case check:
+				fmt.Printf("\n%d of %d files have an older schema version\n", outdated, len(files))
+				return &cli.ExitError{Code: 1}
+			default:
+				fmt.Printf("\n✓ Upgraded %d of %d files to schema version %d in %s\n", outdated, len(files), generator.SchemaVersion, outputDir)
+			}
+			return nil
+		},
+	}
+}
+
+// generatedJSONFiles returns the slash-separated paths of the JSON files
+// generated into dir, sorted. The files recorded in the manifest are used
+// when there is one; output of releases without a manifest is searched for
+// JSON files. The search index has no schema version and is left out.
+func generatedJSONFiles(dir string) ([]string, error) {
+	output := config.Default().Output
+	unversioned := map[string]bool{output.ManifestFile: true, output.SearchIndexFile: true}
+	var files []string
+	if m, err := manifest.Load(filepath.Join(dir, output.ManifestFile)); err == nil {
+		for name := range m.Files {
+			if strings.HasSuffix(name, ".json") && !unversioned[name] {
+				files = append(files, name)
+			}
+		}
+	} else {
+		err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() || !strings.HasSuffix(path, ".json") {
+				return err
+			}
+			name, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			if name = filepath.ToSlash(name); !unversioned[name] {
+				files = append(files, name)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// recompress replaces the <name>.gz copy written with -gzip, if the input
+// has one, with a compressed copy of the upgraded file
+func recompress(inputDir, outputDir, name string, content []byte) error {
+	if _, err := os.Stat(filepath.Join(inputDir, filepath.FromSlash(name+".gz"))); err != nil {
+		return nil
+	}
+	var compressed bytes.Buffer
+	writer, err := gzip.NewWriterLevel(&compressed, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(content); err != nil {
+		return fmt.Errorf("failed to compress %s: %w", name, err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to compress %s: %w", name, err)
+	}
+	return writeUpgraded(outputDir, name+".gz", compressed.Bytes())
+}
+
+// writeUpgraded writes a file of the upgraded output
+func writeUpgraded(dir, name string, content []byte) error {
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", name, err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
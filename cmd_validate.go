@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+
+	"github.com/danaketh/StellarisDataParser/internal/cli"
+)
+
+// validateCommand checks the game or mod files without generating output
+func validateCommand() *cli.Command {
+	game := &gameOptions{}
+
+	return &cli.Command{
+		Name:    "validate",
+		Summary: "Check technology files for syntax errors and broken references",
+		Usage:   "[-input <game_directory>] [flags]",
+		Notes: []string{
+			"Reports malformed files, prerequisites that do not exist and overridden definitions",
+			"Exits with status 1 if any errors are found, which makes it suitable for mod CI",
+			"Known acceptable warnings can be listed in a -suppress file",
+		},
+		Examples: []string{
+			"stellaris-data-parser validate -input \"C:\\Steam\\steamapps\\common\\Stellaris\" -mods ./my_mod -strict",
+		},
+		SetFlags: func(fs *flag.FlagSet) {
+			game.register(fs)
+		},
+		Validate: func(fs *flag.FlagSet, problems *cli.Problems) {
+			game.validate(problems)
+		},
+		Run: func(ctx context.Context, args []string) error {
+			data, err := game.load(ctx, slog.LevelDebug)
+			if err != nil {
+				return err
+			}
+
+			errorCount := 0
+			for _, warning := range data.warnings {
+				if warning.isError {
+					fmt.Printf("❌ %s\n", warning.message)
+					errorCount++
+				} else {
+					fmt.Printf("⚠ %s\n", warning.message)
+				}
+			}
+
+			if suppressed := game.rules.SuppressedCount(); suppressed > 0 {
+				fmt.Printf("✓ %d warnings suppressed\n", suppressed)
+			}
+			for _, rule := range game.rules.Unused() {
+				fmt.Printf("⚠ suppression rule never matched, it can be removed: %s\n", rule)
+			}
+
+			game.finish(fmt.Sprintf("%d problems", errorCount))
+
+			if errorCount > 0 {
+				fmt.Printf("\n%d problems found in %d technologies\n", errorCount, len(data.technologies))
+				return &cli.ExitError{Code: 1}
+			}
+
+			fmt.Printf("✓ %d technologies are valid\n", len(data.technologies))
+			return nil
+		},
+	}
+}
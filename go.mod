@@ -1,5 +1,3 @@
-module stellaris-data-parser
+module github.com/danaketh/StellarisDataParser
 
 go 1.25.3
-
-require github.com/lukegb/dds v0.0.0-20190402175749-8b7170e64003
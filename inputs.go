@@ -0,0 +1,146 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/danaketh/StellarisDataParser/lib/gamefs"
+	"github.com/danaketh/StellarisDataParser/lib/generator"
+	"github.com/danaketh/StellarisDataParser/lib/manifest"
+)
+
+// regenerationInputs are the files and directories of the game and of each
+// mod that parse may read, hashed to tell whether a run can be skipped. The
+// DLC archives are fingerprinted by size and modification time instead, see
+// readInputs.
+var regenerationInputs = []string{
+	"common",
+	"events",
+	"localisation_synced",
+	"localisation",
+	"interface", // .gfx sprite definitions, whose textures are hashed as well
+	"gfx/interface/icons",
+	"launcher-settings.json",
+	"launcher/launcher-settings.json",
+}
+
+// runOnlyFlags are the flags of parse that don't change what is generated:
+// where files are written and read from, and what is logged
+var runOnlyFlags = map[string]bool{
+	"input":      true, // The content is hashed instead
+	"mods":       true,
+	"output":     true,
+	"since":      true,
+	"summary":    true,
+	"progress":   true,
+	"verbose":    true,
+	"quiet":      true,
+	"log-format": true,
+}
+
+// settingsFingerprint returns a hash of the version and of the flags of fs
+// the generated files depend on
+func settingsFingerprint(fs *flag.FlagSet) string {
+	parts := [][]byte{[]byte(version)}
+	fs.VisitAll(func(f *flag.Flag) {
+		if !runOnlyFlags[f.Name] {
+			parts = append(parts, []byte(f.Name), []byte(f.Value.String()))
+		}
+	})
+	return manifest.Fingerprint(parts...)
+}
+
+// readInputs hashes every input file of a parse run: the files of the game
+// below game/, those of each mod below mods/<position>-<name>/, and the
+// config, suppression, icon override and template files. The textures of
+// icon sprites are hashed in every source, as a sprite of a mod may refer to
+// a texture of the game and the other way around. The DLC archives, which
+// run to gigabytes, are only fingerprinted by size and modification time.
+// Templates are recorded by their path, as given, below templates/.
+func readInputs(game *gameOptions, settings, iconOverrides string, templates []string) (*manifest.Inputs, error) {
+	inputs := &manifest.Inputs{Settings: settings, Files: make(map[string]string)}
+
+	paths := append([]string{game.gameDir}, game.mods...)
+	sources := make([]gamefs.Source, 0, len(paths))
+	defer func() {
+		for _, source := range sources {
+			source.Close()
+		}
+	}()
+	var textures []string
+	for _, path := range paths {
+		source, err := gamefs.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+		for _, texture := range generator.SpriteTextures(source) {
+			if texture := spriteTexturePath(texture); texture != "." {
+				textures = append(textures, texture)
+			}
+		}
+	}
+	slices.Sort(textures)
+	dirs := slices.Concat(regenerationInputs, game.config.Icons.SearchDirs, slices.Compact(textures))
+
+	for i, source := range sources {
+		prefix := "game"
+		if i > 0 {
+			prefix = "mods/" + strconv.Itoa(i) + "-" + gamefs.Name(paths[i])
+		}
+		files, err := manifest.FingerprintFiles(source, prefix, dirs...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %s: %w", paths[i], err)
+		}
+		maps.Copy(inputs.Files, files)
+		archives, err := manifest.StatFiles(source, prefix, gamefs.DLCDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fingerprint the DLC of %s: %w", paths[i], err)
+		}
+		maps.Copy(inputs.Files, archives)
+	}
+
+	if iconOverrides != "" {
+		files, err := manifest.FingerprintFiles(os.DirFS(iconOverrides), "icon-overrides", ".")
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %s: %w", iconOverrides, err)
+		}
+		maps.Copy(inputs.Files, files)
+	}
+
+	named := map[string]string{"config": game.configFile, "suppress": game.suppressFile}
+	for _, path := range templates {
+		named[templatePath(path)] = path
+	}
+	for name, path := range named {
+		if path == "" {
+			continue
+		}
+		fingerprint, err := manifest.FingerprintFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %s: %w", path, err)
+		}
+		inputs.Files[name] = fingerprint
+	}
+	return inputs, nil
+}
+
+// templatePath returns the name a template file is recorded under in the
+// inputs: its slash-separated path below templates/, so templates of the
+// same name in different directories don't collide
+func templatePath(file string) string {
+	return path.Join("templates", filepath.ToSlash(filepath.Clean(file)))
+}
+
+// spriteTexturePath returns the texture file of a sprite as a path of the
+// game or mod file system, the way the icon converter looks it up
+func spriteTexturePath(texture string) string {
+	return path.Clean(strings.TrimPrefix(texture, "/"))
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/internal/cli"
+	"github.com/danaketh/StellarisDataParser/lib/config"
+)
+
+// writeGame writes a game directory with the given files, by slash-separated
+// path
+func writeGame(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestReadInputs(t *testing.T) {
+	gameDir := writeGame(t, map[string]string{
+		"common/technology/00_tech.txt":        "tech_a = { area = physics }\n",
+		"interface/technologies.gfx":           `spriteTypes = { spriteType = { name = "GFX_tech_a" texturefile = "gfx/custom/tech_a.dds" } }`,
+		"gfx/custom/tech_a.dds":                "texture",
+		"gfx/custom/unused.dds":                "unused",
+		"gfx/models/ship.mesh":                 "mesh",
+		"launcher-settings.json":               `{"rawVersion": "3.12.4"}`,
+		"dlc/dlc001_a/dlc001.zip":              "archive",
+		"localisation/english/t_l_english.yml": "l_english:\n",
+	})
+	modDir := writeGame(t, map[string]string{
+		"common/technology/01_mod.txt": "tech_b = { area = society }\n",
+		"gfx/custom/tech_a.dds":        "mod texture",
+	})
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(configFile, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	templates := []string{filepath.Join(t.TempDir(), "a", "page.tmpl"), filepath.Join(t.TempDir(), "b", "page.tmpl")}
+	for i, path := range templates {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte{byte('a' + i)}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	game := &gameOptions{gameDir: gameDir, mods: []string{modDir}, configFile: configFile, config: config.Default()}
+	inputs, err := readInputs(game, "settings", "", templates)
+	if err != nil {
+		t.Fatalf("readInputs failed: %v", err)
+	}
+	if inputs.Settings != "settings" {
+		t.Errorf("Expected the settings to be recorded, got %q", inputs.Settings)
+	}
+
+	modPrefix := "mods/1-" + filepath.Base(modDir) + "/"
+	for _, name := range []string{
+		"game/common/technology/00_tech.txt",
+		"game/interface/technologies.gfx",
+		"game/gfx/custom/tech_a.dds",
+		"game/launcher-settings.json",
+		"game/localisation/english/t_l_english.yml",
+		modPrefix + "common/technology/01_mod.txt",
+		modPrefix + "gfx/custom/tech_a.dds",
+		"game/dlc/dlc001_a/dlc001.zip",
+		"config",
+		templatePath(templates[0]),
+		templatePath(templates[1]),
+	} {
+		if _, exists := inputs.Files[name]; !exists {
+			t.Errorf("Expected %s to be hashed, got %v", name, inputs.Files)
+		}
+	}
+	for _, name := range []string{"game/gfx/custom/unused.dds", "game/gfx/models/ship.mesh"} {
+		if _, exists := inputs.Files[name]; exists {
+			t.Errorf("Expected %s not to be hashed", name)
+		}
+	}
+
+	if inputs.Files[templatePath(templates[0])] == inputs.Files[templatePath(templates[1])] {
+		t.Error("Expected templates of the same name to be hashed separately")
+	}
+
+	// A changed sprite texture changes the inputs
+	if err := os.WriteFile(filepath.Join(gameDir, "gfx", "custom", "tech_a.dds"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := readInputs(game, "settings", "", templates)
+	if err != nil {
+		t.Fatalf("readInputs failed: %v", err)
+	}
+	if names := changed.Changed(inputs); len(names) != 1 || names[0] != "game/gfx/custom/tech_a.dds" {
+		t.Errorf("Expected only the sprite texture to change, got %v", names)
+	}
+}
+
+func TestSettingsFingerprint(t *testing.T) {
+	newFlags := func(args ...string) *flag.FlagSet {
+		fs := flag.NewFlagSet("parse", flag.ContinueOnError)
+		fs.String("output", "output", "")
+		fs.String("summary", "", "")
+		fs.Bool("verbose", false, "")
+		fs.Bool("minify", false, "")
+		fs.String("language", "english", "")
+		if err := fs.Parse(args); err != nil {
+			t.Fatal(err)
+		}
+		return fs
+	}
+
+	base := settingsFingerprint(newFlags())
+	if got := settingsFingerprint(newFlags("-output", "other", "-summary", "summary.json", "-verbose")); got != base {
+		t.Error("Expected flags that don't change the output not to change the fingerprint")
+	}
+	if got := settingsFingerprint(newFlags("-minify")); got == base {
+		t.Error("Expected -minify to change the fingerprint")
+	}
+	if got := settingsFingerprint(newFlags("-language", "german")); got == base {
+		t.Error("Expected -language to change the fingerprint")
+	}
+
+	for _, name := range []string{"output", "since", "summary", "progress", "verbose", "quiet", "log-format"} {
+		if !runOnlyFlags[name] {
+			t.Errorf("Expected -%s not to count as a change", name)
+		}
+	}
+	for _, name := range []string{"language", "where", "full", "format", "config"} {
+		if runOnlyFlags[name] {
+			t.Errorf("Expected -%s to count as a change", name)
+		}
+	}
+}
+
+func TestParseSkipsUnchangedInputs(t *testing.T) {
+	gameDir := writeGame(t, map[string]string{
+		"common/technology/00_tech.txt": "tech_a = { area = physics tier = 0 cost = 100 }\n",
+		"interface/technologies.gfx":    `spriteTypes = { spriteType = { name = "GFX_tech_a" texturefile = "gfx/custom/tech_a.dds" } }`,
+	})
+	app := &cli.App{Name: "stellaris-data-parser", Commands: []*cli.Command{parseCommand()}, Output: io.Discard}
+	parse := func(outputDir string, args ...string) {
+		t.Helper()
+		args = append([]string{"parse", "-input", gameDir, "-output", outputDir, "-skip-icons", "-quiet",
+			"-summary", filepath.Join(outputDir, "run-summary.json")}, args...)
+		if code := app.Run(args); code != 0 {
+			t.Fatalf("parse %v exited with %d", args, code)
+		}
+	}
+	exists := func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+	// Runs that skip parsing report no technologies in the summary
+	parsed := func(outputDir string) bool {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(outputDir, "run-summary.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var summary runSummary
+		if err := json.Unmarshal(content, &summary); err != nil {
+			t.Fatal(err)
+		}
+		return summary.Counts.Technologies > 0
+	}
+
+	// The inputs are only hashed with -since, so the first run with it can't
+	// skip anything
+	first := filepath.Join(t.TempDir(), "first")
+	parse(first)
+	recorded := filepath.Join(t.TempDir(), "recorded")
+	parse(recorded, "-since", filepath.Join(first, "manifest.json"))
+	if !parsed(recorded) {
+		t.Error("Expected a manifest without inputs to regenerate the files")
+	}
+
+	second := filepath.Join(t.TempDir(), "second")
+	parse(second, "-since", filepath.Join(recorded, "manifest.json"))
+	if !exists(filepath.Join(second, "manifest.json")) {
+		t.Error("Expected the manifest to be written when the inputs are unchanged")
+	}
+	if exists(filepath.Join(second, "metadata.json")) || parsed(second) {
+		t.Error("Expected nothing to be generated when the inputs are unchanged")
+	}
+
+	// A changed sprite definition runs again
+	spriteFile := filepath.Join(gameDir, "interface", "technologies.gfx")
+	if err := os.WriteFile(spriteFile, []byte(`spriteTypes = { spriteType = { name = "GFX_tech_a" texturefile = "gfx/other/tech_a.dds" } }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	third := filepath.Join(t.TempDir(), "third")
+	parse(third, "-since", filepath.Join(second, "manifest.json"))
+	if !parsed(third) {
+		t.Error("Expected a changed sprite definition to regenerate the files")
+	}
+
+	// So does a flag the output depends on
+	fourth := filepath.Join(t.TempDir(), "fourth")
+	parse(fourth, "-since", filepath.Join(third, "manifest.json"), "-minify")
+	if !exists(filepath.Join(fourth, "metadata.json")) || !parsed(fourth) {
+		t.Error("Expected a changed flag to regenerate the files")
+	}
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"english", "german", "french", "braz_por"}
+
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"englsh", "english"},
+		{"German", "german"},
+		{"frnch", "french"},
+		{"klingon", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := Suggest(tt.value, candidates); got != tt.expected {
+				t.Errorf("Expected suggestion %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"input", "inptu", 2},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.expected {
+			t.Errorf("levenshtein(%q, %q) = %d, expected %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestCheckUnknownFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("input", "", "")
+	fs.String("output", "", "")
+
+	var problems Problems
+	filtered := CheckUnknownFlags(fs, []string{"-inptu", "dir", "--output=x", "-zzzzzz", "-5", "--", "-ignored"}, &problems)
+
+	expected := []string{"--output=x", "-5", "--", "-ignored"}
+	if strings.Join(filtered, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected filtered args %v, got %v", expected, filtered)
+	}
+
+	list := problems.List()
+	if len(list) != 2 {
+		t.Fatalf("Expected 2 problems, got %d: %v", len(list), list)
+	}
+	if list[0].Flag != "inptu" || !strings.Contains(list[0].Suggestion, "-input") {
+		t.Errorf("Expected suggestion for -inptu, got %+v", list[0])
+	}
+	if list[1].Flag != "zzzzzz" || !strings.Contains(list[1].Suggestion, "-help") {
+		t.Errorf("Expected generic hint for -zzzzzz, got %+v", list[1])
+	}
+}
+
+func TestProblemsError(t *testing.T) {
+	var problems Problems
+	if problems.Err() != nil {
+		t.Error("Expected nil error without problems")
+	}
+
+	problems.Add("input", "game directory is required")
+	CheckChoice("language", "englsh", []string{"english", "german"}, &problems)
+	CheckChoice("language", "english", []string{"english", "german"}, &problems)
+
+	err := problems.Err()
+	if err == nil {
+		t.Fatal("Expected error with problems")
+	}
+
+	msg := err.Error()
+	for _, want := range []string{"found 2 problems", "-input: game directory is required", `did you mean "english"?`} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Expected message to contain %q, got:\n%s", want, msg)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Command is a single subcommand with its own flags and help text
+type Command struct {
+	Name     string
+	Summary  string   // One line description shown in the command list
+	Usage    string   // Arguments shown after the command name, e.g. "-input <dir> [flags]"
+	Notes    []string // Extra lines shown in the command help
+	Examples []string // Example invocations shown in the command help
+
+	// SetFlags registers the command's flags
+	SetFlags func(fs *flag.FlagSet)
+	// Validate checks the parsed flags and records every problem found
+	Validate func(fs *flag.FlagSet, problems *Problems)
+	// Run executes the command with the remaining positional arguments. ctx
+	// is cancelled when the run should stop, e.g. on Ctrl+C.
+	Run func(ctx context.Context, args []string) error
+}
+
+// App dispatches command-line arguments to subcommands
+type App struct {
+	Name           string
+	Description    string
+	Version        string
+	Commands       []*Command
+	DefaultCommand string // Command used when the first argument is a flag
+	Output         io.Writer
+}
+
+// ExitError is returned by a command to exit with a specific status code
+// without printing an error message
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// CancelledCode is the exit code of a command stopped by cancelling its
+// context, the code shells use for Ctrl+C
+const CancelledCode = 130
+
+// Run parses args (without the program name), runs the selected command and
+// returns the process exit code
+func (a *App) Run(args []string) int {
+	return a.RunContext(context.Background(), args)
+}
+
+// RunContext is Run with the context passed to the command
+func (a *App) RunContext(ctx context.Context, args []string) int {
+	out := a.output()
+
+	if len(args) == 0 {
+		a.PrintHelp()
+		return 1
+	}
+
+	name := args[0]
+	switch name {
+	case "help", "-help", "--help", "-h":
+		if len(args) > 1 {
+			if cmd := a.Find(args[1]); cmd != nil {
+				a.PrintCommandHelp(cmd)
+				return 0
+			}
+		}
+		a.PrintHelp()
+		return 0
+	case "version", "-version", "--version":
+		fmt.Fprintf(out, "%s v%s\n", a.Description, a.Version)
+		return 0
+	}
+
+	// Allow flags without a command for backwards compatibility
+	if strings.HasPrefix(name, "-") && a.DefaultCommand != "" {
+		name = a.DefaultCommand
+	} else {
+		args = args[1:]
+	}
+
+	cmd := a.Find(name)
+	if cmd == nil {
+		fmt.Fprintf(out, "Error: unknown command %q\n", name)
+		if suggestion := Suggest(name, a.commandNames()); suggestion != "" {
+			fmt.Fprintf(out, "       did you mean %q?\n", suggestion)
+		}
+		fmt.Fprintf(out, "\nRun '%s help' for a list of commands.\n", a.Name)
+		return 1
+	}
+
+	return a.runCommand(ctx, cmd, args)
+}
+
+// runCommand parses the command's flags, validates them and runs it
+func (a *App) runCommand(ctx context.Context, cmd *Command, args []string) int {
+	out := a.output()
+
+	fs := a.newFlagSet(cmd)
+	showHelp := fs.Bool("help", false, "Show help for this command")
+
+	// Collect every problem with the command line before giving up, so
+	// users can fix them all in one go
+	problems := &Problems{}
+	args = CheckUnknownFlags(fs, args, problems)
+	if err := fs.Parse(args); err != nil {
+		problems.Add("", err.Error())
+	}
+
+	if *showHelp {
+		a.PrintCommandHelp(cmd)
+		return 0
+	}
+
+	if cmd.Validate != nil {
+		cmd.Validate(fs, problems)
+	}
+
+	if err := problems.Err(); err != nil {
+		fmt.Fprintf(out, "Error: %v\n", err)
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "Run '%s %s -help' for usage information.\n", a.Name, cmd.Name)
+		return 1
+	}
+
+	if err := cmd.Run(ctx, fs.Args()); err != nil {
+		if exitErr, ok := err.(*ExitError); ok {
+			return exitErr.Code
+		}
+		if errors.Is(err, context.Canceled) {
+			fmt.Fprintln(out, "⏹ Cancelled")
+			return CancelledCode
+		}
+		fmt.Fprintf(out, "❌ Error: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// newFlagSet creates the flag set of a command with its flags registered
+func (a *App) newFlagSet(cmd *Command) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if cmd.SetFlags != nil {
+		cmd.SetFlags(fs)
+	}
+	return fs
+}
+
+// Find returns the command with the given name, or nil
+func (a *App) Find(name string) *Command {
+	for _, cmd := range a.Commands {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// commandNames returns the names of all commands
+func (a *App) commandNames() []string {
+	names := make([]string, 0, len(a.Commands))
+	for _, cmd := range a.Commands {
+		names = append(names, cmd.Name)
+	}
+	return names
+}
+
+// PrintHelp prints the list of commands
+func (a *App) PrintHelp() {
+	out := a.output()
+
+	fmt.Fprintf(out, "%s v%s\n", a.Description, a.Version)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Usage:")
+	fmt.Fprintf(out, "  %s <command> [flags]\n", a.Name)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Commands:")
+
+	width := 0
+	for _, cmd := range a.Commands {
+		if len(cmd.Name) > width {
+			width = len(cmd.Name)
+		}
+	}
+	for _, cmd := range a.Commands {
+		fmt.Fprintf(out, "  %-*s  %s\n", width, cmd.Name, cmd.Summary)
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "Run '%s <command> -help' for the flags of a command.\n", a.Name)
+	if a.DefaultCommand != "" {
+		fmt.Fprintf(out, "Flags given without a command run '%s'.\n", a.DefaultCommand)
+	}
+}
+
+// PrintCommandHelp prints the usage and flags of a single command
+func (a *App) PrintCommandHelp(cmd *Command) {
+	out := a.output()
+
+	fmt.Fprintf(out, "%s %s - %s\n", a.Name, cmd.Name, cmd.Summary)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Usage:")
+	fmt.Fprintf(out, "  %s %s %s\n", a.Name, cmd.Name, cmd.Usage)
+
+	fs := a.newFlagSet(cmd)
+	var flags []*flag.Flag
+	fs.VisitAll(func(f *flag.Flag) {
+		flags = append(flags, f)
+	})
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+
+	if len(flags) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Flags:")
+		for _, f := range flags {
+			typeName, usage := flag.UnquoteUsage(f)
+			if typeName != "" {
+				fmt.Fprintf(out, "  -%s %s\n", f.Name, typeName)
+			} else {
+				fmt.Fprintf(out, "  -%s\n", f.Name)
+			}
+			fmt.Fprintf(out, "        %s", usage)
+			if f.DefValue != "" && f.DefValue != "false" && f.DefValue != "0" {
+				fmt.Fprintf(out, " (default: %s)", f.DefValue)
+			}
+			fmt.Fprintln(out)
+		}
+	}
+
+	if len(cmd.Notes) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Notes:")
+		for _, note := range cmd.Notes {
+			fmt.Fprintf(out, "  - %s\n", note)
+		}
+	}
+
+	if len(cmd.Examples) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Examples:")
+		for _, example := range cmd.Examples {
+			fmt.Fprintf(out, "  %s\n", example)
+		}
+	}
+}
+
+// output returns the writer for help and error output
+func (a *App) output() io.Writer {
+	if a.Output != nil {
+		return a.Output
+	}
+	return os.Stdout
+}
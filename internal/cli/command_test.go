@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func newTestApp(out *bytes.Buffer, ran *[]string) *App {
+	var name string
+	return &App{
+		Name:           "tool",
+		Description:    "Test Tool",
+		Version:        "1.2.3",
+		DefaultCommand: "greet",
+		Output:         out,
+		Commands: []*Command{
+			{
+				Name:    "greet",
+				Summary: "Say hello",
+				Usage:   "-name <name>",
+				SetFlags: func(fs *flag.FlagSet) {
+					fs.StringVar(&name, "name", "", "Who to greet")
+				},
+				Validate: func(fs *flag.FlagSet, problems *Problems) {
+					if name == "" {
+						problems.Add("name", "is required")
+					}
+				},
+				Run: func(ctx context.Context, args []string) error {
+					*ran = append(*ran, "greet:"+name+":"+strings.Join(args, ","))
+					return nil
+				},
+			},
+			{
+				Name:    "fail",
+				Summary: "Always fails",
+				Run: func(ctx context.Context, args []string) error {
+					*ran = append(*ran, "fail")
+					if len(args) > 0 {
+						return &ExitError{Code: 3}
+					}
+					return errors.New("boom")
+				},
+			},
+			{
+				Name:    "wait",
+				Summary: "Runs until cancelled",
+				Run: func(ctx context.Context, args []string) error {
+					*ran = append(*ran, "wait")
+					<-ctx.Done()
+					return fmt.Errorf("stopped waiting: %w", ctx.Err())
+				},
+			},
+		},
+	}
+}
+
+func TestAppRunCommand(t *testing.T) {
+	var out bytes.Buffer
+	var ran []string
+	app := newTestApp(&out, &ran)
+
+	if code := app.Run([]string{"greet", "-name", "world", "extra"}); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, out.String())
+	}
+	if len(ran) != 1 || ran[0] != "greet:world:extra" {
+		t.Errorf("Expected greet to run with flags and args, got %v", ran)
+	}
+}
+
+func TestAppRunDefaultCommand(t *testing.T) {
+	var out bytes.Buffer
+	var ran []string
+	app := newTestApp(&out, &ran)
+
+	if code := app.Run([]string{"-name", "world"}); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, out.String())
+	}
+	if len(ran) != 1 || ran[0] != "greet:world:" {
+		t.Errorf("Expected default command to run, got %v", ran)
+	}
+}
+
+func TestAppRunValidationProblems(t *testing.T) {
+	var out bytes.Buffer
+	var ran []string
+	app := newTestApp(&out, &ran)
+
+	if code := app.Run([]string{"greet", "-nmae", "x"}); code != 1 {
+		t.Errorf("Expected exit code 1, got %d", code)
+	}
+	if len(ran) != 0 {
+		t.Errorf("Expected command not to run, got %v", ran)
+	}
+	for _, want := range []string{"-nmae: unknown flag", "-name: is required", "tool greet -help"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestAppRunErrors(t *testing.T) {
+	var out bytes.Buffer
+	var ran []string
+	app := newTestApp(&out, &ran)
+
+	if code := app.Run([]string{"fail"}); code != 1 {
+		t.Errorf("Expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(out.String(), "boom") {
+		t.Errorf("Expected error to be printed, got:\n%s", out.String())
+	}
+
+	out.Reset()
+	if code := app.Run([]string{"fail", "quietly"}); code != 3 {
+		t.Errorf("Expected exit code 3 from ExitError, got %d", code)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected no output for ExitError, got:\n%s", out.String())
+	}
+}
+
+func TestAppRunCancelled(t *testing.T) {
+	var out bytes.Buffer
+	var ran []string
+	app := newTestApp(&out, &ran)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if code := app.RunContext(ctx, []string{"wait"}); code != CancelledCode {
+		t.Errorf("Expected exit code %d, got %d", CancelledCode, code)
+	}
+	if len(ran) != 1 || !strings.Contains(out.String(), "Cancelled") || strings.Contains(out.String(), "Error") {
+		t.Errorf("Expected the command to run and report the cancellation, got %v:\n%s", ran, out.String())
+	}
+}
+
+func TestAppRunUnknownCommand(t *testing.T) {
+	var out bytes.Buffer
+	var ran []string
+	app := newTestApp(&out, &ran)
+
+	if code := app.Run([]string{"gret"}); code != 1 {
+		t.Errorf("Expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(out.String(), `did you mean "greet"?`) {
+		t.Errorf("Expected command suggestion, got:\n%s", out.String())
+	}
+}
+
+func TestAppHelpAndVersion(t *testing.T) {
+	var out bytes.Buffer
+	var ran []string
+	app := newTestApp(&out, &ran)
+
+	if code := app.Run([]string{"help"}); code != 0 {
+		t.Errorf("Expected exit code 0, got %d", code)
+	}
+	if !strings.Contains(out.String(), "greet  Say hello") {
+		t.Errorf("Expected command list, got:\n%s", out.String())
+	}
+
+	out.Reset()
+	app.Run([]string{"greet", "-help"})
+	if !strings.Contains(out.String(), "-name string") || !strings.Contains(out.String(), "Who to greet") {
+		t.Errorf("Expected command flags in help, got:\n%s", out.String())
+	}
+
+	out.Reset()
+	app.Run([]string{"version"})
+	if strings.TrimSpace(out.String()) != "Test Tool v1.2.3" {
+		t.Errorf("Expected version output, got %q", out.String())
+	}
+
+	if len(ran) != 0 {
+		t.Errorf("Expected no command to run, got %v", ran)
+	}
+}
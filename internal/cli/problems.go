@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Problem is a single configuration problem found while validating flags
+type Problem struct {
+	Flag       string // Flag the problem relates to, without leading dash (may be empty)
+	Message    string
+	Suggestion string // Optional hint on how to fix the problem
+}
+
+// String formats the problem for console output
+func (p Problem) String() string {
+	var b strings.Builder
+	if p.Flag != "" {
+		fmt.Fprintf(&b, "-%s: ", p.Flag)
+	}
+	b.WriteString(p.Message)
+	if p.Suggestion != "" {
+		fmt.Fprintf(&b, "\n      %s", p.Suggestion)
+	}
+	return b.String()
+}
+
+// Problems collects configuration problems so that all of them can be
+// reported at once instead of stopping at the first one
+type Problems struct {
+	list []Problem
+}
+
+// Add records a problem for the given flag
+func (p *Problems) Add(flagName, message string) {
+	p.list = append(p.list, Problem{Flag: flagName, Message: message})
+}
+
+// AddWithSuggestion records a problem together with a hint on how to fix it
+func (p *Problems) AddWithSuggestion(flagName, message, suggestion string) {
+	p.list = append(p.list, Problem{Flag: flagName, Message: message, Suggestion: suggestion})
+}
+
+// List returns all recorded problems
+func (p *Problems) List() []Problem {
+	return p.list
+}
+
+// Empty reports whether no problems were recorded
+func (p *Problems) Empty() bool {
+	return len(p.list) == 0
+}
+
+// Error formats all problems as a single message
+func (p *Problems) Error() string {
+	var b strings.Builder
+	if len(p.list) == 1 {
+		b.WriteString("found 1 problem:")
+	} else {
+		fmt.Fprintf(&b, "found %d problems:", len(p.list))
+	}
+	for _, problem := range p.list {
+		b.WriteString("\n  ")
+		b.WriteString(problem.String())
+	}
+	return b.String()
+}
+
+// Err returns the problems as an error, or nil if there are none
+func (p *Problems) Err() error {
+	if p.Empty() {
+		return nil
+	}
+	return p
+}
+
+// CheckUnknownFlags scans args for flags that are not defined in fs and
+// records each of them with a suggestion for the closest known flag. This
+// runs before fs.Parse, which would otherwise stop at the first unknown flag.
+// The returned args have the unknown flags (and a value directly following
+// them) removed, so the remaining flags can still be parsed and validated.
+func CheckUnknownFlags(fs *flag.FlagSet, args []string, problems *Problems) []string {
+	var known []string
+	fs.VisitAll(func(f *flag.Flag) {
+		known = append(known, f.Name)
+	})
+
+	filtered := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			filtered = append(filtered, args[i:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" || isNumber(arg) {
+			filtered = append(filtered, arg)
+			continue
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		hasValue := strings.Contains(name, "=")
+		if idx := strings.Index(name, "="); idx != -1 {
+			name = name[:idx]
+		}
+		if name == "" || fs.Lookup(name) != nil {
+			filtered = append(filtered, arg)
+			continue
+		}
+
+		// Skip the value of the unknown flag as well
+		if !hasValue && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			i++
+		}
+
+		if suggestion := Suggest(name, known); suggestion != "" {
+			problems.AddWithSuggestion(name, "unknown flag", fmt.Sprintf("did you mean -%s?", suggestion))
+		} else {
+			problems.AddWithSuggestion(name, "unknown flag", "run with -help to see all flags")
+		}
+	}
+
+	return filtered
+}
+
+// isNumber reports whether arg is a (possibly negative) number, which is a
+// flag value rather than a flag
+func isNumber(arg string) bool {
+	_, err := strconv.ParseFloat(arg, 64)
+	return err == nil
+}
+
+// CheckChoice records a problem if value is not one of choices, suggesting
+// the closest valid choice
+func CheckChoice(flagName, value string, choices []string, problems *Problems) {
+	for _, choice := range choices {
+		if value == choice {
+			return
+		}
+	}
+
+	message := fmt.Sprintf("unknown value %q", value)
+	if suggestion := Suggest(value, choices); suggestion != "" {
+		problems.AddWithSuggestion(flagName, message, fmt.Sprintf("did you mean %q?", suggestion))
+	} else {
+		problems.AddWithSuggestion(flagName, message, fmt.Sprintf("valid values: %s", strings.Join(choices, ", ")))
+	}
+}
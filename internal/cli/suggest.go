@@ -0,0 +1,51 @@
+package cli
+
+import "strings"
+
+// Suggest returns the candidate closest to value by edit distance, or an
+// empty string if no candidate is close enough to be a likely typo
+func Suggest(value string, candidates []string) string {
+	value = strings.ToLower(value)
+
+	best := ""
+	bestDistance := -1
+	for _, candidate := range candidates {
+		distance := levenshtein(value, strings.ToLower(candidate))
+		if bestDistance == -1 || distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+
+	// Allow roughly one typo per three characters
+	maxDistance := len(value)/3 + 1
+	if bestDistance == -1 || bestDistance > maxDistance {
+		return ""
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
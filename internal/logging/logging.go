@@ -0,0 +1,116 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Formats are the values accepted by -log-format
+var Formats = []string{"text", "json"}
+
+// Level returns the lowest level logged: debug with -verbose, warnings and
+// errors only with -quiet, info otherwise
+func Level(verbose, quiet bool) slog.Level {
+	switch {
+	case verbose:
+		return slog.LevelDebug
+	case quiet:
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
+
+// New returns a logger writing records of at least level to w, as JSON lines
+// in the json format and as console lines otherwise
+func New(w io.Writer, format string, level slog.Level) *slog.Logger {
+	if format == "json" {
+		return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}))
+	}
+	return slog.New(&consoleHandler{mu: &sync.Mutex{}, w: w, level: level})
+}
+
+// consoleHandler writes a record as its message followed by its attributes
+// as key=value pairs, without time or level. Warnings and errors are marked
+// the way the commands mark them.
+type consoleHandler struct {
+	mu     *sync.Mutex // Shared by the handlers derived with WithAttrs and WithGroup
+	w      io.Writer
+	level  slog.Level
+	attrs  string // Attributes added with WithAttrs, formatted
+	prefix string // Group names added with WithGroup, each followed by a dot
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *consoleHandler) Handle(_ context.Context, record slog.Record) error {
+	var b strings.Builder
+	switch {
+	case record.Level >= slog.LevelError:
+		b.WriteString("❌ ")
+	case record.Level >= slog.LevelWarn:
+		b.WriteString("⚠ ")
+	}
+	b.WriteString(record.Message)
+	b.WriteString(h.attrs)
+	record.Attrs(func(attr slog.Attr) bool {
+		writeAttr(&b, h.prefix, attr)
+		return true
+	})
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	for _, attr := range attrs {
+		writeAttr(&b, h.prefix, attr)
+	}
+	derived := *h
+	derived.attrs += b.String()
+	return &derived
+}
+
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	derived := *h
+	derived.prefix += name + "."
+	return &derived
+}
+
+// writeAttr writes an attribute as " key=value", groups as one pair per
+// member. Values with spaces or quotes are quoted; empty attributes are
+// skipped.
+func writeAttr(b *strings.Builder, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, member := range attr.Value.Group() {
+			writeAttr(b, prefix, member)
+		}
+		return
+	}
+
+	value := attr.Value.String()
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+	fmt.Fprintf(b, " %s%s=%s", prefix, attr.Key, value)
+}
@@ -0,0 +1,57 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestLevel(t *testing.T) {
+	tests := []struct {
+		verbose, quiet bool
+		expected       slog.Level
+	}{
+		{false, false, slog.LevelInfo},
+		{true, false, slog.LevelDebug},
+		{false, true, slog.LevelWarn},
+	}
+	for _, tt := range tests {
+		if level := Level(tt.verbose, tt.quiet); level != tt.expected {
+			t.Errorf("Level(%v, %v) = %v, expected %v", tt.verbose, tt.quiet, level, tt.expected)
+		}
+	}
+}
+
+func TestConsoleFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "text", slog.LevelInfo)
+
+	logger.Debug("hidden")
+	logger.Info("Parsed technologies", "count", 3)
+	logger.With("file", "my techs.txt").Warn("Failed to parse technology file", "error", "unexpected }")
+	logger.WithGroup("icon").Error("Failed", slog.Group("source", "mod", "my_mod"))
+
+	expected := "Parsed technologies count=3\n" +
+		"⚠ Failed to parse technology file file=\"my techs.txt\" error=\"unexpected }\"\n" +
+		"❌ Failed icon.source.mod=my_mod\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "json", slog.LevelWarn)
+
+	logger.Info("hidden")
+	logger.Warn("Unknown prerequisite", "tech", "tech_a", "prerequisite", "tech_b")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected one JSON record, got %q: %v", buf.String(), err)
+	}
+	if record["level"] != "WARN" || record["msg"] != "Unknown prerequisite" || record["tech"] != "tech_a" {
+		t.Errorf("Unexpected record: %v", record)
+	}
+}
@@ -0,0 +1,184 @@
+package suppress
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+)
+
+// Kinds of warnings that can be suppressed
+const (
+	KindParse               = "parse"                // Malformed technology file
+	KindMissingPrerequisite = "missing-prerequisite" // Prerequisite that matches no technology
+	KindOverride            = "override"             // Technology replaced by a later definition
+	KindUnreachable         = "unreachable"          // Technology that can never be researched
+	KindOrphan              = "orphan"               // Technology not connected to the tree
+	KindPrerequisiteTier    = "prerequisite-tier"    // Prerequisite of a higher tier
+	KindTierRequirement     = "tier-requirement"     // Tier that can't be offered under the tier rules
+)
+
+// kinds lists every valid kind
+var kinds = []string{
+	KindParse, KindMissingPrerequisite, KindOverride, KindUnreachable, KindOrphan,
+	KindPrerequisiteTier, KindTierRequirement,
+}
+
+// Warning identifies a single warning for matching against rules
+type Warning struct {
+	Kind         string
+	Tech         string // Technology key, if any
+	Prerequisite string // Missing prerequisite key, for missing-prerequisite warnings
+	File         string // Source file the warning refers to
+	Mod          string // Mod the source file belongs to, empty for the base game
+}
+
+// Rule suppresses warnings matching all of its non-empty fields. Tech,
+// Prerequisite, File and Mod accept glob patterns such as "tech_lasers_*".
+type Rule struct {
+	Kind         string `json:"kind"`
+	Tech         string `json:"tech,omitempty"`
+	Prerequisite string `json:"prerequisite,omitempty"`
+	File         string `json:"file,omitempty"`
+	Mod          string `json:"mod,omitempty"`
+	Reason       string `json:"reason,omitempty"` // Why the warning is acceptable, for humans
+}
+
+// Rules is a set of suppression rules. It counts how often each rule matched,
+// so it is not safe for concurrent use. A nil *Rules suppresses nothing.
+type Rules struct {
+	rules      []Rule
+	hits       []int
+	suppressed int
+}
+
+// file is the format of a suppression file
+type file struct {
+	Suppress []Rule `json:"suppress"`
+}
+
+// New creates a rule set
+func New(rules ...Rule) (*Rules, error) {
+	for i, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return &Rules{
+		rules: rules,
+		hits:  make([]int, len(rules)),
+	}, nil
+}
+
+// Load reads a JSON suppression file
+func Load(filePath string) (*Rules, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suppression file: %w", err)
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse suppression file %s: %w", filePath, err)
+	}
+
+	rules, err := New(f.Suppress...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid suppression file %s: %w", filePath, err)
+	}
+	return rules, nil
+}
+
+// validate checks the kind and the glob patterns of a rule
+func (r Rule) validate() error {
+	valid := false
+	for _, kind := range kinds {
+		if r.Kind == kind {
+			valid = true
+		}
+	}
+	if !valid {
+		return fmt.Errorf("unknown kind %q (expected one of %v)", r.Kind, kinds)
+	}
+
+	for _, pattern := range []string{r.Tech, r.Prerequisite, r.File, r.Mod} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matches reports whether the rule applies to a warning
+func (r Rule) matches(w Warning) bool {
+	if r.Kind != w.Kind {
+		return false
+	}
+	return matchField(r.Tech, w.Tech) &&
+		matchField(r.Prerequisite, w.Prerequisite) &&
+		matchField(r.File, w.File) &&
+		matchField(r.Mod, w.Mod)
+}
+
+// matchField matches a value against a pattern; an empty pattern matches anything
+func matchField(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	matched, _ := path.Match(pattern, value)
+	return matched
+}
+
+// Suppressed reports whether a warning matches any rule
+func (r *Rules) Suppressed(w Warning) bool {
+	if r == nil {
+		return false
+	}
+	for i, rule := range r.rules {
+		if rule.matches(w) {
+			r.hits[i]++
+			r.suppressed++
+			return true
+		}
+	}
+	return false
+}
+
+// SuppressedCount returns the number of warnings suppressed so far
+func (r *Rules) SuppressedCount() int {
+	if r == nil {
+		return 0
+	}
+	return r.suppressed
+}
+
+// Unused returns the rules that have not matched any warning, which usually
+// means the problem they covered was fixed and the rule can be removed
+func (r *Rules) Unused() []Rule {
+	if r == nil {
+		return nil
+	}
+	var unused []Rule
+	for i, rule := range r.rules {
+		if r.hits[i] == 0 {
+			unused = append(unused, rule)
+		}
+	}
+	return unused
+}
+
+// String describes a rule for console output
+func (r Rule) String() string {
+	s := r.Kind
+	for _, field := range []struct{ name, value string }{
+		{"tech", r.Tech},
+		{"prerequisite", r.Prerequisite},
+		{"file", r.File},
+		{"mod", r.Mod},
+	} {
+		if field.value != "" {
+			s += fmt.Sprintf(" %s=%s", field.name, field.value)
+		}
+	}
+	return s
+}
@@ -0,0 +1,112 @@
+package suppress
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSuppressed(t *testing.T) {
+	rules, err := New(
+		Rule{Kind: KindMissingPrerequisite, Prerequisite: "tech_optional_*", Reason: "Optional mod"},
+		Rule{Kind: KindOverride, Tech: "tech_lasers_1", Mod: "my_mod"},
+		Rule{Kind: KindParse, File: "99_broken.txt"},
+	)
+	if err != nil {
+		t.Fatalf("Failed to create rules: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		warning  Warning
+		expected bool
+	}{
+		{"glob prerequisite", Warning{Kind: KindMissingPrerequisite, Tech: "tech_a", Prerequisite: "tech_optional_shields"}, true},
+		{"other prerequisite", Warning{Kind: KindMissingPrerequisite, Tech: "tech_a", Prerequisite: "tech_typo"}, false},
+		{"override by mod", Warning{Kind: KindOverride, Tech: "tech_lasers_1", File: "lasers.txt", Mod: "my_mod"}, true},
+		{"override by other mod", Warning{Kind: KindOverride, Tech: "tech_lasers_1", File: "lasers.txt", Mod: "other_mod"}, false},
+		{"parse file", Warning{Kind: KindParse, File: "99_broken.txt"}, true},
+		{"kind must match", Warning{Kind: KindOverride, File: "99_broken.txt"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules.Suppressed(tt.warning); got != tt.expected {
+				t.Errorf("Expected suppressed=%v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if rules.SuppressedCount() != 3 {
+		t.Errorf("Expected 3 suppressed warnings, got %d", rules.SuppressedCount())
+	}
+	if unused := rules.Unused(); len(unused) != 0 {
+		t.Errorf("Expected all rules to be used, got %v", unused)
+	}
+}
+
+func TestUnused(t *testing.T) {
+	rules, err := New(
+		Rule{Kind: KindParse, File: "used.txt"},
+		Rule{Kind: KindParse, File: "stale.txt"},
+	)
+	if err != nil {
+		t.Fatalf("Failed to create rules: %v", err)
+	}
+
+	rules.Suppressed(Warning{Kind: KindParse, File: "used.txt"})
+
+	unused := rules.Unused()
+	if len(unused) != 1 || unused[0].File != "stale.txt" {
+		t.Errorf("Expected stale.txt rule to be unused, got %v", unused)
+	}
+	if unused[0].String() != "parse file=stale.txt" {
+		t.Errorf("Unexpected rule description %q", unused[0].String())
+	}
+}
+
+func TestNilRules(t *testing.T) {
+	var rules *Rules
+	if rules.Suppressed(Warning{Kind: KindParse}) {
+		t.Error("Expected nil rules to suppress nothing")
+	}
+	if rules.SuppressedCount() != 0 || rules.Unused() != nil {
+		t.Error("Expected nil rules to be empty")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "suppress.json")
+	content := `{"suppress": [{"kind": "missing-prerequisite", "prerequisite": "tech_optional_*", "reason": "Optional mod"}]}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load rules: %v", err)
+	}
+	if !rules.Suppressed(Warning{Kind: KindMissingPrerequisite, Prerequisite: "tech_optional_x"}) {
+		t.Error("Expected loaded rule to apply")
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := map[string]string{
+		"malformed json": `{"suppress": `,
+		"unknown kind":   `{"suppress": [{"kind": "everything"}]}`,
+		"bad pattern":    `{"suppress": [{"kind": "parse", "file": "[unclosed"}]}`,
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "suppress.json")
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(path); err == nil {
+				t.Error("Expected error for invalid suppression file")
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Package charset converts script and localization files to UTF-8 text.
+// Localization files are UTF-8 with a byte order mark, while some mods save
+// their files as UTF-16 or Windows-1252. StripComment removes the # comments
+// both kinds of files share.
+package charset
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Byte order marks
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// windows1252 maps the bytes 0x80 to 0x9F of Windows-1252 to runes. The
+// other bytes above 0x7F are the same as in Latin-1, and the five unused
+// ones are kept as their C1 control characters.
+var windows1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// Decode returns the content of a file as UTF-8 without a byte order mark.
+// UTF-16 is recognized by its byte order mark; other content that isn't
+// valid UTF-8 is read as Windows-1252.
+func Decode(data []byte) []byte {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return data[len(bomUTF8):]
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return decodeUTF16(data[len(bomUTF16LE):], false)
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return decodeUTF16(data[len(bomUTF16BE):], true)
+	case utf8.Valid(data):
+		return data
+	}
+	return decodeWindows1252(data)
+}
+
+// NewReader reads all of r and returns a reader of its content as UTF-8,
+// see Decode
+func NewReader(r io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(Decode(data)), nil
+}
+
+// decodeUTF16 converts UTF-16 to UTF-8. A trailing odd byte is dropped.
+func decodeUTF16(data []byte, bigEndian bool) []byte {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		} else {
+			units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+		}
+	}
+	return []byte(string(utf16.Decode(units)))
+}
+
+// decodeWindows1252 converts Windows-1252 to UTF-8
+func decodeWindows1252(data []byte) []byte {
+	result := make([]byte, 0, len(data)+len(data)/4)
+	for _, b := range data {
+		switch {
+		case b < 0x80:
+			result = append(result, b)
+		case b < 0xA0:
+			result = utf8.AppendRune(result, windows1252[b-0x80])
+		default:
+			result = utf8.AppendRune(result, rune(b))
+		}
+	}
+	return result
+}
+
+// StripComment removes the # comment at the end of a line of a script or
+// localization file. A # inside a quoted string, such as the color tag of
+// "#weak text", doesn't start a comment; escaped quotes don't end the string.
+func StripComment(line string) string {
+	inQuotes := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			if inQuotes {
+				i++
+			}
+		case '"':
+			inQuotes = !inQuotes
+		case '#':
+			if !inQuotes {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
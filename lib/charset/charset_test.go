@@ -0,0 +1,64 @@
+package charset
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		expected string
+	}{
+		{"plain UTF-8", []byte("tech_a = { cost = 1 }"), "tech_a = { cost = 1 }"},
+		{"UTF-8 with BOM", []byte("\xEF\xBB\xBFl_english:"), "l_english:"},
+		{"UTF-8 without BOM", []byte("name: \"Café\""), "name: \"Café\""},
+		{"Windows-1252", []byte("name: \"Caf\xE9 \x80 \x96 \x9F\""), "name: \"Café € – Ÿ\""},
+		{"UTF-16LE", []byte("\xFF\xFEa\x00=\x00\xE9\x00"), "a=é"},
+		{"UTF-16BE", []byte("\xFE\xFF\x00a\x00=\x20\xAC"), "a=€"},
+		{"empty", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(Decode(tt.input)); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	reader, err := NewReader(strings.NewReader("\xEF\xBB\xBFtech_a = yes"))
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	content, _ := io.ReadAll(reader)
+	if string(content) != "tech_a = yes" {
+		t.Errorf("Expected the BOM to be stripped, got %q", content)
+	}
+}
+
+func TestStripComment(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"no comment", `cost = 100`, `cost = 100`},
+		{"trailing comment", `cost = 100 # was 50`, `cost = 100 `},
+		{"whole line", `# tech_a = { }`, ``},
+		{"hash in quotes", `text = "#weak Careful#! text" # note`, `text = "#weak Careful#! text" `},
+		{"escaped quote", `text = "say \"#hi\"" # note`, `text = "say \"#hi\"" `},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripComment(tt.input); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// Package config loads the JSON configuration of the commands: the
+// names of the output files, the timeline assumptions, where icons are
+// looked up and how localized text is written.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/danaketh/StellarisDataParser/lib/localization"
+	"github.com/danaketh/StellarisDataParser/lib/timeline"
+)
+
+// AreaPlaceholder is replaced with the lower-cased research area name in
+// research file name templates
+const AreaPlaceholder = "%area%"
+
+// KeyPlaceholder is replaced with the technology key in per-technology file
+// names
+const KeyPlaceholder = "%key%"
+
+// DomainPlaceholder is replaced with the domain name, such as edicts, in
+// domain file names
+const DomainPlaceholder = "%domain%"
+
+// Config holds user configuration loaded from a JSON config file
+type Config struct {
+	Output   OutputConfig         `json:"output"`
+	Timeline timeline.Assumptions `json:"timeline"` // Research speed used for estimatedYear
+	Icons    IconsConfig          `json:"icons"`
+	Text     TextConfig           `json:"text"`
+}
+
+// OutputConfig controls the names of generated files and directories
+type OutputConfig struct {
+	ResearchFile     string `json:"researchFile"` // Template for per-area files, must contain %area%
+	MetadataFile     string `json:"metadataFile"`
+	OverridesFile    string `json:"overridesFile"`    // Report of technologies replaced by mods
+	ManifestFile     string `json:"manifestFile"`     // Fingerprints of generated files, used by -since
+	TypesFile        string `json:"typesFile"`        // TypeScript declarations of the JSON files
+	IconUsageFile    string `json:"iconUsageFile"`    // Report of icons shared by several technologies
+	CoverageFile     string `json:"coverageFile"`     // Report of missing localization keys
+	MechanicsFile    string `json:"mechanicsFile"`    // Research mechanics written with -mechanics
+	SubgraphFile     string `json:"subgraphFile"`     // Template for per-technology subgraph files, must contain %key%
+	GraphFile        string `json:"graphFile"`        // Nodes and links graph written with -graph
+	HTMLFile         string `json:"htmlFile"`         // Tech tree viewer written with -html
+	SearchIndexFile  string `json:"searchIndexFile"`  // Search records written with -search-index
+	SidebarsFile     string `json:"sidebarsFile"`     // Docusaurus sidebars written with -sidebars
+	SidebarDocID     string `json:"sidebarDocId"`     // Template for the doc id of each technology in the sidebars, must contain %key%
+	MissingIconsFile string `json:"missingIconsFile"` // Report of technology icons that couldn't be converted
+	DomainFile       string `json:"domainFile"`       // Template for files written with -content, must contain %domain%
+	IconsDir         string `json:"iconsDir"`         // Relative to the output directory
+}
+
+// IconsConfig controls where technology icons are looked up in the game
+// files. Each directory is searched for each extension in turn and the first
+// existing file is used.
+type IconsConfig struct {
+	SearchDirs []string `json:"searchDirs"` // Relative to the game directory
+	Extensions []string `json:"extensions"`
+}
+
+// TextConfig controls how localized names and descriptions are written
+type TextConfig struct {
+	// Replacements for [Scope.Command] scripting commands with -commands
+	// placeholder, keyed by command without brackets. Entries are added to
+	// the defaults.
+	CommandPlaceholders map[string]string `json:"commandPlaceholders"`
+}
+
+// IconExtensions lists the icon file extensions that can be converted
+var IconExtensions = []string{".dds", ".png", ".jpg", ".tga"}
+
+// Default returns the configuration used when no config file is given
+func Default() *Config {
+	return &Config{
+		Output: OutputConfig{
+			ResearchFile:     "research-" + AreaPlaceholder + ".json",
+			MetadataFile:     "metadata.json",
+			OverridesFile:    "overrides.json",
+			ManifestFile:     "manifest.json",
+			TypesFile:        "technologies.d.ts",
+			IconUsageFile:    "icon-usage.json",
+			CoverageFile:     "localization-coverage.json",
+			MechanicsFile:    "mechanics.json",
+			SubgraphFile:     "subgraphs/" + KeyPlaceholder + ".json",
+			GraphFile:        "graph.json",
+			HTMLFile:         "index.html",
+			SearchIndexFile:  "search-index.json",
+			SidebarsFile:     "sidebars.js",
+			SidebarDocID:     "technologies/" + KeyPlaceholder,
+			MissingIconsFile: "missing-icons.json",
+			DomainFile:       DomainPlaceholder + ".json",
+			IconsDir:         "icons",
+		},
+		Timeline: timeline.DefaultAssumptions(),
+		Icons: IconsConfig{
+			SearchDirs: []string{"gfx/interface/icons/technologies"},
+			Extensions: []string{".dds", ".png", ".jpg", ".tga"},
+		},
+		Text: TextConfig{
+			CommandPlaceholders: localization.DefaultCommandPlaceholders(),
+		},
+	}
+}
+
+// Load reads a JSON config file. Values missing from the file keep their
+// defaults.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	cfg := Default()
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Validate checks the configuration for values that would produce broken output
+func (c *Config) Validate() error {
+	if !strings.Contains(c.Output.ResearchFile, AreaPlaceholder) {
+		return fmt.Errorf("output.researchFile must contain %s so each area gets its own file", AreaPlaceholder)
+	}
+	if c.Output.MetadataFile == "" {
+		return fmt.Errorf("output.metadataFile must not be empty")
+	}
+	if c.Output.OverridesFile == "" {
+		return fmt.Errorf("output.overridesFile must not be empty")
+	}
+	if c.Output.ManifestFile == "" {
+		return fmt.Errorf("output.manifestFile must not be empty")
+	}
+	if c.Output.TypesFile == "" {
+		return fmt.Errorf("output.typesFile must not be empty")
+	}
+	if c.Output.IconUsageFile == "" {
+		return fmt.Errorf("output.iconUsageFile must not be empty")
+	}
+	if c.Output.CoverageFile == "" {
+		return fmt.Errorf("output.coverageFile must not be empty")
+	}
+	if c.Output.MechanicsFile == "" {
+		return fmt.Errorf("output.mechanicsFile must not be empty")
+	}
+	if c.Output.GraphFile == "" {
+		return fmt.Errorf("output.graphFile must not be empty")
+	}
+	if c.Output.HTMLFile == "" {
+		return fmt.Errorf("output.htmlFile must not be empty")
+	}
+	if c.Output.SearchIndexFile == "" {
+		return fmt.Errorf("output.searchIndexFile must not be empty")
+	}
+	if c.Output.SidebarsFile == "" {
+		return fmt.Errorf("output.sidebarsFile must not be empty")
+	}
+	if !strings.Contains(c.Output.SidebarDocID, KeyPlaceholder) {
+		return fmt.Errorf("output.sidebarDocId must contain %s so each technology gets its own doc", KeyPlaceholder)
+	}
+	if c.Output.MissingIconsFile == "" {
+		return fmt.Errorf("output.missingIconsFile must not be empty")
+	}
+	if !strings.Contains(c.Output.SubgraphFile, KeyPlaceholder) {
+		return fmt.Errorf("output.subgraphFile must contain %s so each technology gets its own file", KeyPlaceholder)
+	}
+	if !strings.Contains(c.Output.DomainFile, DomainPlaceholder) {
+		return fmt.Errorf("output.domainFile must contain %s so each domain gets its own file", DomainPlaceholder)
+	}
+	if c.Output.IconsDir == "" {
+		return fmt.Errorf("output.iconsDir must not be empty")
+	}
+	if len(c.Icons.SearchDirs) == 0 {
+		return fmt.Errorf("icons.searchDirs must not be empty")
+	}
+	if len(c.Icons.Extensions) == 0 {
+		return fmt.Errorf("icons.extensions must not be empty")
+	}
+	for _, ext := range c.Icons.Extensions {
+		if !slices.Contains(IconExtensions, ext) {
+			return fmt.Errorf("icons.extensions: unsupported extension %q, must be one of %s", ext, strings.Join(IconExtensions, ", "))
+		}
+	}
+	if err := c.Timeline.Validate(); err != nil {
+		return fmt.Errorf("timeline.%w", err)
+	}
+	return nil
+}
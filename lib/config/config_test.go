@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefault(t *testing.T) {
+	cfg := Default()
+
+	if cfg.Output.ResearchFile != "research-%area%.json" {
+		t.Errorf("Unexpected default research file: %s", cfg.Output.ResearchFile)
+	}
+	if cfg.Output.MetadataFile != "metadata.json" {
+		t.Errorf("Unexpected default metadata file: %s", cfg.Output.MetadataFile)
+	}
+	if cfg.Output.IconsDir != "icons" {
+		t.Errorf("Unexpected default icons dir: %s", cfg.Output.IconsDir)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected default config to be valid: %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := `{"output": {"researchFile": "tech-%area%.json", "iconsDir": "img/tech"}}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.Output.ResearchFile != "tech-%area%.json" {
+		t.Errorf("Expected research file to be overridden, got %s", cfg.Output.ResearchFile)
+	}
+	if cfg.Output.IconsDir != "img/tech" {
+		t.Errorf("Expected icons dir to be overridden, got %s", cfg.Output.IconsDir)
+	}
+	if cfg.Output.MetadataFile != "metadata.json" {
+		t.Errorf("Expected metadata file to keep default, got %s", cfg.Output.MetadataFile)
+	}
+	if cfg.Timeline.StartYear != 2200 {
+		t.Errorf("Expected timeline to keep defaults, got start year %d", cfg.Timeline.StartYear)
+	}
+	if cfg.Text.CommandPlaceholders["Root.GetName"] == "" {
+		t.Errorf("Expected default command placeholders, got %v", cfg.Text.CommandPlaceholders)
+	}
+	if len(cfg.Icons.Extensions) != 4 || cfg.Icons.Extensions[0] != ".dds" {
+		t.Errorf("Expected icon extensions to keep defaults, got %v", cfg.Icons.Extensions)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := map[string]string{
+		"missing placeholder": `{"output": {"researchFile": "research.json"}}`,
+		"empty metadata":      `{"output": {"metadataFile": ""}}`,
+		"empty types":         `{"output": {"typesFile": ""}}`,
+		"empty graph":         `{"output": {"graphFile": ""}}`,
+		"empty html":          `{"output": {"htmlFile": ""}}`,
+		"empty search index":  `{"output": {"searchIndexFile": ""}}`,
+		"empty sidebars":      `{"output": {"sidebarsFile": ""}}`,
+		"no sidebar doc key":  `{"output": {"sidebarDocId": "technologies/tech"}}`,
+		"malformed json":      `{"output": `,
+		"zero research":       `{"timeline": {"baseResearch": 0}}`,
+		"no icon dirs":        `{"icons": {"searchDirs": []}}`,
+		"unknown extension":   `{"icons": {"extensions": [".bmp"]}}`,
+		"no subgraph key":     `{"output": {"subgraphFile": "subgraph.json"}}`,
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+			if _, err := Load(path); err == nil {
+				t.Error("Expected error for invalid config")
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	if _, err := Load("/nonexistent/config.json"); err == nil {
+		t.Error("Expected error for missing config file")
+	}
+}
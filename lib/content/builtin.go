@@ -0,0 +1,320 @@
+package content
+
+import (
+	"github.com/danaketh/StellarisDataParser/lib/generator"
+	"github.com/danaketh/StellarisDataParser/lib/parser"
+)
+
+// The built-in content types, in the order they are listed in -content
+func init() {
+	register(generator.DomainEdicts, parseEdicts, parser.EdictsDir)
+	register(generator.DomainPolicies, parsePolicies, parser.PoliciesDir)
+	register(generator.DomainShips, parseShips, parser.ShipSizesDir, parser.SectionTemplatesDir)
+	register(generator.DomainDistricts, parseDistricts, parser.DistrictsDir)
+	register(generator.DomainPlanets, parsePlanets, parser.PlanetClassesDir)
+	register(generator.DomainRelics, parseRelics, parser.RelicsDir)
+	register(generator.DomainArchaeology, parseArchaeology, parser.ArchaeologicalSiteTypesDir)
+	register(generator.DomainEvents, parseEvents, parser.EventsDir)
+	register(generator.DomainAnomalies, parseAnomalies, parser.AnomaliesDir, parser.SpecialProjectsDir)
+	register(generator.DomainLeaders, parseLeaders, parser.TraitsDir, parser.LeaderClassesDir, parser.CouncilPositionsDir)
+	register(generator.DomainEspionage, parseEspionage, parser.EspionageOperationTypesDir)
+	register(generator.DomainSituations, parseSituations, parser.SituationsDir)
+	register(generator.DomainDiplomacy, parseDiplomacy, parser.DiplomaticActionsDir, parser.AgreementPresetsDir, parser.SubjectTermsDir)
+	register(generator.DomainDefines, parseDefines, parser.DefinesDir)
+}
+
+// parseEdicts reads and localizes the edicts and campaigns
+func parseEdicts(ctx *Context) (interface{}, int, error) {
+	edicts, err := parser.ParseEdicts(ctx.GameDir, ctx.ModDirs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, edict := range edicts {
+		edict.Name = ctx.Name(edict.Key)
+		edict.Description = ctx.Description(edict.Key)
+		edict.Potential = ctx.Conditions(edict.Potential)
+		edict.Allow = ctx.Conditions(edict.Allow)
+	}
+	return edicts, len(edicts), nil
+}
+
+// parsePolicies reads and localizes the policies and their options
+func parsePolicies(ctx *Context) (interface{}, int, error) {
+	policies, err := parser.ParsePolicies(ctx.GameDir, ctx.ModDirs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, policy := range policies {
+		// Policies are localized with a policy_ prefix
+		policy.Name = ctx.Name("policy_"+policy.Key, policy.Key)
+		policy.Description = ctx.Description("policy_"+policy.Key, policy.Key)
+		policy.Potential = ctx.Conditions(policy.Potential)
+		policy.Allow = ctx.Conditions(policy.Allow)
+		for i := range policy.Options {
+			option := &policy.Options[i]
+			option.Name = ctx.Name(option.Key)
+			option.Description = ctx.Description(option.Key)
+			option.Potential = ctx.Conditions(option.Potential)
+			option.Valid = ctx.Conditions(option.Valid)
+		}
+	}
+	return policies, len(policies), nil
+}
+
+// parseShips reads and localizes the ship sizes with their section templates
+func parseShips(ctx *Context) (interface{}, int, error) {
+	sizes, err := parser.ParseShips(ctx.GameDir, ctx.ModDirs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, size := range sizes {
+		size.Name = ctx.Name(size.Key)
+		size.Description = ctx.Description(size.Key)
+		size.Potential = ctx.Conditions(size.Potential)
+		for _, section := range size.Sections {
+			section.Name = ctx.Name(section.Key)
+		}
+	}
+	return sizes, len(sizes), nil
+}
+
+// parseDistricts reads and localizes the districts
+func parseDistricts(ctx *Context) (interface{}, int, error) {
+	districts, err := parser.ParseDistricts(ctx.GameDir, ctx.ModDirs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, district := range districts {
+		district.Name = ctx.Name(district.Key)
+		district.Description = ctx.Description(district.Key)
+		district.Potential = ctx.Conditions(district.Potential)
+		district.Allow = ctx.Conditions(district.Allow)
+		for _, triggered := range district.Triggered {
+			triggered["potential"] = ctx.Weight(triggered["potential"])
+		}
+	}
+	return districts, len(districts), nil
+}
+
+// parsePlanets reads and localizes the planet classes
+func parsePlanets(ctx *Context) (interface{}, int, error) {
+	classes, err := parser.ParsePlanetClasses(ctx.GameDir, ctx.ModDirs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, class := range classes {
+		class.Name = ctx.Name(class.Key)
+		class.Description = ctx.Description(class.Key)
+	}
+	return classes, len(classes), nil
+}
+
+// parseRelics reads and localizes the relics
+func parseRelics(ctx *Context) (interface{}, int, error) {
+	relics, err := parser.ParseRelics(ctx.GameDir, ctx.ModDirs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, relic := range relics {
+		relic.Name = ctx.Name(relic.Key)
+		relic.Description = ctx.Description(relic.Key)
+		relic.Possible = ctx.Conditions(relic.Possible)
+		for _, effect := range relic.PassiveEffects {
+			effect["potential"] = ctx.Weight(effect["potential"])
+		}
+		if relic.Portrait != "" {
+			relic.IconFile = generator.RelicIconFile(relic.Key)
+		}
+	}
+	return relics, len(relics), nil
+}
+
+// parseArchaeology reads and localizes the archaeological site types
+func parseArchaeology(ctx *Context) (interface{}, int, error) {
+	sites, err := parser.ParseArchaeologySites(ctx.GameDir, ctx.ModDirs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, site := range sites {
+		site.Name = ctx.Name(site.Key)
+		site.Description = ctx.Description(site.Key)
+		site.Potential = ctx.Conditions(site.Potential)
+		site.Allow = ctx.Conditions(site.Allow)
+		site.Weight = ctx.Weight(site.Weight)
+	}
+	return sites, len(sites), nil
+}
+
+// parseEvents reads and localizes the events
+func parseEvents(ctx *Context) (interface{}, int, error) {
+	events, err := parser.ParseEvents(ctx.GameDir, ctx.ModDirs)
+	if err != nil {
+		return nil, 0, err
+	}
+	// Events name their localization keys instead of following the
+	// <key>_desc convention
+	for _, event := range events {
+		event.Title = ctx.Name(event.TitleKey)
+		if len(event.DescriptionKeys) > 0 {
+			event.Description = ctx.Name(event.DescriptionKeys[0])
+		}
+		event.Trigger = ctx.Conditions(event.Trigger)
+		for i := range event.Options {
+			event.Options[i].Name = ctx.Name(event.Options[i].NameKey)
+			event.Options[i].Trigger = ctx.Conditions(event.Options[i].Trigger)
+		}
+	}
+	return events, len(events), nil
+}
+
+// parseAnomalies reads and localizes the anomalies and special projects
+func parseAnomalies(ctx *Context) (interface{}, int, error) {
+	anomalies, err := parser.ParseAnomalies(ctx.GameDir, ctx.ModDirs)
+	if err != nil {
+		return nil, 0, err
+	}
+	projects, err := parser.ParseSpecialProjects(ctx.GameDir, ctx.ModDirs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, anomaly := range anomalies {
+		anomaly.Name = ctx.Name(anomaly.Key)
+		anomaly.Description = ctx.TextOrDescription(anomaly.DescriptionKey, anomaly.Key)
+		anomaly.SpawnChance = ctx.Weight(anomaly.SpawnChance)
+	}
+	for _, project := range projects {
+		project.Name = ctx.Name(project.Key)
+		project.Description = ctx.TextOrDescription(project.DescriptionKey, project.Key)
+	}
+	return map[string]interface{}{
+		"anomalies":       anomalies,
+		"specialProjects": projects,
+	}, len(anomalies) + len(projects), nil
+}
+
+// parseLeaders reads and localizes the leader traits, leader classes and council positions
+func parseLeaders(ctx *Context) (interface{}, int, error) {
+	traits, err := parser.ParseLeaderTraits(ctx.GameDir, ctx.ModDirs)
+	if err != nil {
+		return nil, 0, err
+	}
+	classes, err := parser.ParseLeaderClasses(ctx.GameDir, ctx.ModDirs)
+	if err != nil {
+		return nil, 0, err
+	}
+	positions, err := parser.ParseCouncilPositions(ctx.GameDir, ctx.ModDirs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, trait := range traits {
+		trait.Name = ctx.Name(trait.Key)
+		trait.Description = ctx.Description(trait.Key)
+		trait.Potential = ctx.Conditions(trait.Potential)
+	}
+	for _, class := range classes {
+		class.Name = ctx.Name(class.Key)
+		class.Description = ctx.Description(class.Key)
+	}
+	for _, position := range positions {
+		position.Name = ctx.Name(position.Key)
+		position.Description = ctx.Description(position.Key)
+		position.Potential = ctx.Conditions(position.Potential)
+	}
+	return map[string]interface{}{
+		"traits":             traits,
+		"classes":            classes,
+		"councilPositions":   positions,
+		"traitsByTechnology": parser.TraitsByTechnology(traits),
+	}, len(traits) + len(classes) + len(positions), nil
+}
+
+// parseEspionage reads and localizes the espionage operations
+func parseEspionage(ctx *Context) (interface{}, int, error) {
+	operations, err := parser.ParseEspionageOperations(ctx.GameDir, ctx.ModDirs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, operation := range operations {
+		operation.Name = ctx.Name(operation.Key)
+		operation.Description = ctx.Description(operation.Key)
+		operation.Difficulty = ctx.Weight(operation.Difficulty)
+		operation.Potential = ctx.Conditions(operation.Potential)
+		operation.Allow = ctx.Conditions(operation.Allow)
+	}
+	return operations, len(operations), nil
+}
+
+// parseSituations reads and localizes the situations
+func parseSituations(ctx *Context) (interface{}, int, error) {
+	situations, err := parser.ParseSituations(ctx.GameDir, ctx.ModDirs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, situation := range situations {
+		situation.Name = ctx.Name(situation.Key)
+		situation.Description = ctx.Description(situation.Key)
+		situation.MonthlyProgress = ctx.Weight(situation.MonthlyProgress)
+		// Stage keys such as stage_1 repeat across situations, so the
+		// situation's own key is tried first
+		for i := range situation.Stages {
+			stage := &situation.Stages[i]
+			stage.Name = ctx.Name(situation.Key+"_"+stage.Key, stage.Key)
+		}
+		for i := range situation.Approaches {
+			approach := &situation.Approaches[i]
+			approach.Name = ctx.Name(approach.NameKey, approach.Key)
+			approach.Description = ctx.Description(approach.Key)
+			approach.Potential = ctx.Conditions(approach.Potential)
+			approach.Allow = ctx.Conditions(approach.Allow)
+		}
+	}
+	return situations, len(situations), nil
+}
+
+// parseDiplomacy reads and localizes the diplomatic actions, agreement presets and subject terms
+func parseDiplomacy(ctx *Context) (interface{}, int, error) {
+	actions, err := parser.ParseDiplomaticActions(ctx.GameDir, ctx.ModDirs)
+	if err != nil {
+		return nil, 0, err
+	}
+	presets, err := parser.ParseAgreementPresets(ctx.GameDir, ctx.ModDirs)
+	if err != nil {
+		return nil, 0, err
+	}
+	terms, err := parser.ParseSubjectTerms(ctx.GameDir, ctx.ModDirs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, action := range actions {
+		action.Name = ctx.Name(action.Key)
+		action.Description = ctx.Description(action.Key)
+		action.Potential = ctx.Conditions(action.Potential)
+		action.Possible = ctx.Conditions(action.Possible)
+		action.Proposable = ctx.Conditions(action.Proposable)
+	}
+	for _, preset := range presets {
+		preset.Name = ctx.Name(preset.Key)
+		preset.Description = ctx.Description(preset.Key)
+		preset.Potential = ctx.Conditions(preset.Potential)
+	}
+	for _, term := range terms {
+		term.Name = ctx.Name(term.Key)
+		term.Description = ctx.Description(term.Key)
+		term.Potential = ctx.Conditions(term.Potential)
+	}
+	return map[string]interface{}{
+		"actions":          actions,
+		"agreementPresets": presets,
+		"subjectTerms":     terms,
+	}, len(actions) + len(presets) + len(terms), nil
+}
+
+// parseDefines reads the defines, which have no localization
+func parseDefines(ctx *Context) (interface{}, int, error) {
+	defines, err := parser.ParseDefines(ctx.GameDir, ctx.ModDirs)
+	if err != nil {
+		return nil, 0, err
+	}
+	list := defines.List()
+	return list, len(list), nil
+}
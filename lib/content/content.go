@@ -0,0 +1,185 @@
+// Package content runs the parsers of the game content besides technologies,
+// such as edicts or ship sizes, through a common pipeline. Each content type
+// registers a Parser; the parse command selects them with -content.
+package content
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/danaketh/StellarisDataParser/lib/gamefs"
+	"github.com/danaketh/StellarisDataParser/lib/generator"
+	"github.com/danaketh/StellarisDataParser/lib/localization"
+	"github.com/danaketh/StellarisDataParser/lib/parser"
+)
+
+// Tech is the content type of technologies. Technologies are always parsed,
+// since every command and output file builds on the technology tree, so it
+// has no Parser.
+const Tech = "tech"
+
+// Parser reads one content type from the game and mod directories and hands
+// it to the generator
+type Parser interface {
+	// Name identifies the content type in -content and names its output
+	// file, e.g. edicts for edicts.json
+	Name() string
+	// Directories returns the directories the content is read from,
+	// relative to the game or mod directory
+	Directories() []string
+	// Parse reads and localizes the content and returns the number of
+	// definitions read
+	Parse(ctx *Context) (int, error)
+	// Emit sets the parsed content as the data of the generator's output
+	// file
+	Emit(g *generator.JSONGenerator)
+}
+
+// Context holds what parsers need besides the game files
+type Context struct {
+	GameDir      string
+	ModDirs      []string                         // In load order
+	Localization *localization.LocalizationParser // Nil or empty without localization
+	Languages    []string                         // Language followed by its fallbacks
+	Triggers     parser.ScriptedTriggers          // Expanded in conditions
+}
+
+// Name returns the localized name of the first key that has one
+func (c *Context) Name(keys ...string) string {
+	if c.Localization == nil {
+		return ""
+	}
+	for _, key := range keys {
+		if text, _ := c.Localization.LookupName(key, c.Languages); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// Description returns the localized description of the first key that has
+// one
+func (c *Context) Description(keys ...string) string {
+	if c.Localization == nil {
+		return ""
+	}
+	for _, key := range keys {
+		if text, _ := c.Localization.LookupDescription(key, c.Languages); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// TextOrDescription returns the text of a definition's own description key,
+// or the description of key by the <key>_desc convention when it has none
+func (c *Context) TextOrDescription(descriptionKey, key string) string {
+	if descriptionKey != "" {
+		return c.Name(descriptionKey)
+	}
+	return c.Description(key)
+}
+
+// Conditions expands the scripted triggers in a condition block
+func (c *Context) Conditions(block map[string]interface{}) map[string]interface{} {
+	return c.Triggers.Expand(block)
+}
+
+// Weight expands the scripted triggers in a weight, which is a number or a
+// block of modifiers with conditions
+func (c *Context) Weight(value interface{}) interface{} {
+	if block, ok := value.(map[string]interface{}); ok {
+		return c.Conditions(block)
+	}
+	return value
+}
+
+// registry holds a constructor of each content type's parser, in
+// registration order
+var registry []func() Parser
+
+// Register adds a content type. Parsers keep their parsed content until
+// Emit, so the registry creates a new one for every run.
+func Register(newParser func() Parser) {
+	registry = append(registry, newParser)
+}
+
+// Names returns the names of the registered content types, in registration
+// order, without Tech
+func Names() []string {
+	names := make([]string, len(registry))
+	for i, newParser := range registry {
+		names[i] = newParser().Name()
+	}
+	return names
+}
+
+// New returns a new parser of a content type
+func New(name string) (Parser, error) {
+	for _, newParser := range registry {
+		if p := newParser(); p.Name() == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown content type %s", name)
+}
+
+// Present reports whether any directory of a parser exists in the game
+// directory or a mod, see gamefs.Open
+func Present(p Parser, gameDir string, modDirs []string) bool {
+	for _, source := range append([]string{gameDir}, modDirs...) {
+		fsys, err := gamefs.Open(source)
+		if err != nil {
+			continue
+		}
+		found := false
+		for _, dir := range p.Directories() {
+			if _, err := fs.Stat(fsys, dir); err == nil {
+				found = true
+				break
+			}
+		}
+		fsys.Close()
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// definitionParser is a Parser for content written as a whole to the
+// generator's file of the content type
+type definitionParser struct {
+	name        string
+	directories []string
+	parse       func(ctx *Context) (interface{}, int, error)
+	data        interface{}
+}
+
+// register adds a built-in content type parsed by a function
+func register(name string, parse func(ctx *Context) (interface{}, int, error), directories ...string) {
+	Register(func() Parser {
+		return &definitionParser{name: name, directories: directories, parse: parse}
+	})
+}
+
+func (p *definitionParser) Name() string {
+	return p.name
+}
+
+func (p *definitionParser) Directories() []string {
+	return p.directories
+}
+
+func (p *definitionParser) Parse(ctx *Context) (int, error) {
+	data, count, err := p.parse(ctx)
+	if err != nil {
+		return 0, err
+	}
+	p.data = data
+	return count, nil
+}
+
+func (p *definitionParser) Emit(g *generator.JSONGenerator) {
+	g.SetDomain(p.name, p.data)
+}
@@ -0,0 +1,131 @@
+package content
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/generator"
+	"github.com/danaketh/StellarisDataParser/lib/localization"
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/parser"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	names := Names()
+	if len(names) == 0 || names[0] != generator.DomainEdicts {
+		t.Fatalf("Expected the built-in content types starting with edicts, got %v", names)
+	}
+	if slices.Contains(names, Tech) {
+		t.Errorf("Expected technologies not to be a registered parser, got %v", names)
+	}
+
+	first, err := New(generator.DomainShips)
+	if err != nil {
+		t.Fatalf("Failed to create the ships parser: %v", err)
+	}
+	second, _ := New(generator.DomainShips)
+	if first == second {
+		t.Errorf("Expected a new parser for every call")
+	}
+	if first.Name() != generator.DomainShips || !slices.Equal(first.Directories(), []string{parser.ShipSizesDir, parser.SectionTemplatesDir}) {
+		t.Errorf("Unexpected parser %s reading %v", first.Name(), first.Directories())
+	}
+
+	if _, err := New("buildings"); err == nil {
+		t.Errorf("Expected an error for an unknown content type")
+	}
+}
+
+func TestContext(t *testing.T) {
+	locDir := t.TempDir()
+	writeFile(t, filepath.Join(locDir, "english", "edicts_l_english.yml"), "\ufeffl_english:\n research_subsidies:0 \"Research Subsidies\"\n research_subsidies_desc:0 \"More research.\"\n anomaly_desc_text:0 \"Custom text\"\n")
+	loc := localization.NewLocalizationParser()
+	if err := loc.ParseDirectory(locDir); err != nil {
+		t.Fatalf("Failed to parse localization: %v", err)
+	}
+
+	ctx := &Context{
+		Localization: loc,
+		Languages:    []string{"english"},
+		Triggers:     parser.ScriptedTriggers{"is_gestalt": {"has_ethic": "ethic_gestalt_consciousness"}},
+	}
+	if name := ctx.Name("missing", "research_subsidies"); name != "Research Subsidies" {
+		t.Errorf("Expected the name of the first localized key, got %q", name)
+	}
+	if description := ctx.Description("research_subsidies"); description != "More research." {
+		t.Errorf("Expected the description, got %q", description)
+	}
+	if text := ctx.TextOrDescription("anomaly_desc_text", "research_subsidies"); text != "Custom text" {
+		t.Errorf("Expected the text of the description key, got %q", text)
+	}
+	if text := ctx.TextOrDescription("", "research_subsidies"); text != "More research." {
+		t.Errorf("Expected the <key>_desc description, got %q", text)
+	}
+
+	expanded := ctx.Weight(map[string]interface{}{"is_gestalt": true})
+	if block, ok := expanded.(map[string]interface{}); !ok || block["AND"] == nil {
+		t.Errorf("Expected scripted triggers in weights to be expanded, got %v", expanded)
+	}
+	if weight := ctx.Weight(5); weight != 5 {
+		t.Errorf("Expected a numeric weight as is, got %v", weight)
+	}
+
+	// Without localization nothing is found
+	if name := (&Context{}).Name("research_subsidies"); name != "" {
+		t.Errorf("Expected no name without localization, got %q", name)
+	}
+}
+
+func TestParseAndEmit(t *testing.T) {
+	gameDir := t.TempDir()
+	writeFile(t, filepath.Join(gameDir, parser.EdictsDir, "00_edicts.txt"), "research_subsidies = {\n\tlength = 3600\n}\n")
+
+	edicts, _ := New(generator.DomainEdicts)
+	policies, _ := New(generator.DomainPolicies)
+	if !Present(edicts, gameDir, nil) || Present(policies, gameDir, nil) {
+		t.Errorf("Expected only the edicts directory to be present")
+	}
+
+	count, err := edicts.Parse(&Context{GameDir: gameDir})
+	if err != nil {
+		t.Fatalf("Failed to parse edicts: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 edict, got %d", count)
+	}
+
+	g := generator.NewJSONGenerator(tree.NewTechTree(map[string]*models.Technology{}))
+	edicts.Emit(g)
+	outputDir := t.TempDir()
+	if err := g.GenerateJSONFiles(outputDir); err != nil {
+		t.Fatalf("Failed to generate JSON files: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "edicts.json"))
+	if err != nil {
+		t.Fatalf("Failed to read edicts file: %v", err)
+	}
+	var file struct {
+		Edicts []models.Edict `json:"edicts"`
+	}
+	if err := json.Unmarshal(content, &file); err != nil {
+		t.Fatalf("Failed to parse edicts file: %v", err)
+	}
+	if len(file.Edicts) != 1 || file.Edicts[0].Key != "research_subsidies" || file.Edicts[0].Length != 3600 {
+		t.Errorf("Expected the edict, got %+v", file.Edicts)
+	}
+}
@@ -0,0 +1,76 @@
+package dds
+
+import "image/color"
+
+// decodeBC1 decodes a BC1 color block. In BC1 itself, blocks whose first
+// color is not greater than the second have three colors and transparent
+// black; the color blocks of BC2 and BC3 always have four colors.
+func decodeBC1(block []byte, pixels *[16]color.NRGBA, punchThrough bool) {
+	c0 := uint16(block[0]) | uint16(block[1])<<8
+	c1 := uint16(block[2]) | uint16(block[3])<<8
+	var palette [4]color.NRGBA
+	palette[0], palette[1] = rgb565(c0), rgb565(c1)
+	if c0 > c1 || !punchThrough {
+		palette[2] = mix(palette[0], palette[1], 2, 1, 3)
+		palette[3] = mix(palette[0], palette[1], 1, 2, 3)
+	} else {
+		palette[2] = mix(palette[0], palette[1], 1, 1, 2)
+		palette[3] = color.NRGBA{}
+	}
+
+	indices := uint32(block[4]) | uint32(block[5])<<8 | uint32(block[6])<<16 | uint32(block[7])<<24
+	for i := range pixels {
+		pixels[i] = palette[indices>>(2*i)&3]
+	}
+}
+
+// rgb565 expands a 5:6:5 color to 8 bits per channel
+func rgb565(c uint16) color.NRGBA {
+	r, g, b := uint8(c>>11&0x1f), uint8(c>>5&0x3f), uint8(c&0x1f)
+	return color.NRGBA{R: r<<3 | r>>2, G: g<<2 | g>>4, B: b<<3 | b>>2, A: 255}
+}
+
+// mix returns (w0·a + w1·b) / total for each color channel
+func mix(a, b color.NRGBA, w0, w1, total int) color.NRGBA {
+	channel := func(x, y uint8) uint8 {
+		return uint8((w0*int(x) + w1*int(y)) / total)
+	}
+	return color.NRGBA{R: channel(a.R, b.R), G: channel(a.G, b.G), B: channel(a.B, b.B), A: 255}
+}
+
+// decodeBC4 decodes a BC4 single channel block, also used for the alpha of
+// BC3 and both channels of BC5. Signed values are mapped from -1..1 to
+// 0..255.
+func decodeBC4(block []byte, values *[16]uint8, signed bool) {
+	var palette [8]int
+	if signed {
+		palette[0], palette[1] = max(int(int8(block[0])), -127), max(int(int8(block[1])), -127)
+	} else {
+		palette[0], palette[1] = int(block[0]), int(block[1])
+	}
+	if palette[0] > palette[1] {
+		for i := 1; i < 7; i++ {
+			palette[i+1] = ((7-i)*palette[0] + i*palette[1]) / 7
+		}
+	} else {
+		for i := 1; i < 5; i++ {
+			palette[i+1] = ((5-i)*palette[0] + i*palette[1]) / 5
+		}
+		palette[6], palette[7] = 0, 255
+		if signed {
+			palette[6], palette[7] = -127, 127
+		}
+	}
+
+	var indices uint64
+	for i := 7; i >= 2; i-- {
+		indices = indices<<8 | uint64(block[i])
+	}
+	for i := range values {
+		value := palette[indices>>(3*i)&7]
+		if signed {
+			value = ((value+127)*255 + 127) / 254
+		}
+		values[i] = uint8(value)
+	}
+}
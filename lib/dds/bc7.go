@@ -0,0 +1,192 @@
+package dds
+
+import "image/color"
+
+// bc7Mode describes the layout of a BC7 block mode
+type bc7Mode struct {
+	subsets       int // Number of subsets, each with two endpoints
+	partitionBits int
+	rotationBits  int
+	selectionBits int // Index selection bit, swapping the index sets of color and alpha
+	colorBits     int // Bits per color channel of an endpoint
+	alphaBits     int // Bits of the alpha of an endpoint, 0 for opaque blocks
+	endpointPBits bool
+	sharedPBits   bool // One p-bit per subset rather than per endpoint
+	indexBits     int
+	indexBits2    int // Bits of the second index set, 0 without one
+}
+
+var bc7Modes = [8]bc7Mode{
+	{subsets: 3, partitionBits: 4, colorBits: 4, endpointPBits: true, indexBits: 3},
+	{subsets: 2, partitionBits: 6, colorBits: 6, sharedPBits: true, indexBits: 3},
+	{subsets: 3, partitionBits: 6, colorBits: 5, indexBits: 2},
+	{subsets: 2, partitionBits: 6, colorBits: 7, endpointPBits: true, indexBits: 2},
+	{subsets: 1, rotationBits: 2, selectionBits: 1, colorBits: 5, alphaBits: 6, indexBits: 2, indexBits2: 3},
+	{subsets: 1, rotationBits: 2, colorBits: 7, alphaBits: 8, indexBits: 2, indexBits2: 2},
+	{subsets: 1, colorBits: 7, alphaBits: 7, endpointPBits: true, indexBits: 4},
+	{subsets: 2, partitionBits: 6, colorBits: 5, alphaBits: 5, endpointPBits: true, indexBits: 2},
+}
+
+// Interpolation weights out of 64 by index bits
+var bc7Weights = map[int][]int{
+	2: {0, 21, 43, 64},
+	3: {0, 9, 18, 27, 37, 46, 55, 64},
+	4: {0, 4, 9, 13, 17, 21, 26, 30, 34, 38, 43, 47, 51, 55, 60, 64},
+}
+
+// bitReader reads a block as a little-endian bit stream
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bitReader) read(n int) int {
+	value := 0
+	for i := 0; i < n; i++ {
+		bit := int(r.data[r.pos/8]>>(r.pos%8)) & 1
+		value |= bit << i
+		r.pos++
+	}
+	return value
+}
+
+// decodeBC7 decodes a BC7 block. Blocks with an invalid mode decode to
+// transparent black.
+func decodeBC7(block []byte, pixels *[16]color.NRGBA) {
+	r := &bitReader{data: block}
+	modeIndex := 0
+	for modeIndex < 8 && r.read(1) == 0 {
+		modeIndex++
+	}
+	if modeIndex == 8 {
+		*pixels = [16]color.NRGBA{}
+		return
+	}
+	mode := bc7Modes[modeIndex]
+
+	partition := r.read(mode.partitionBits)
+	rotation := r.read(mode.rotationBits)
+	selection := r.read(mode.selectionBits)
+
+	// Endpoints are stored channel by channel
+	endpoints := make([][4]int, 2*mode.subsets)
+	for c := 0; c < 3; c++ {
+		for i := range endpoints {
+			endpoints[i][c] = r.read(mode.colorBits)
+		}
+	}
+	for i := range endpoints {
+		endpoints[i][3] = r.read(mode.alphaBits)
+	}
+
+	colorBits, alphaBits := mode.colorBits, mode.alphaBits
+	if mode.endpointPBits || mode.sharedPBits {
+		pBits := make([]int, len(endpoints))
+		if mode.endpointPBits {
+			for i := range pBits {
+				pBits[i] = r.read(1)
+			}
+		} else {
+			for s := 0; s < mode.subsets; s++ {
+				pBits[2*s] = r.read(1)
+				pBits[2*s+1] = pBits[2*s]
+			}
+		}
+		for i := range endpoints {
+			for c := 0; c < 4; c++ {
+				endpoints[i][c] = endpoints[i][c]<<1 | pBits[i]
+			}
+		}
+		colorBits++
+		if alphaBits > 0 {
+			alphaBits++
+		}
+	}
+	for i := range endpoints {
+		for c := 0; c < 3; c++ {
+			endpoints[i][c] = expandBits(endpoints[i][c], colorBits)
+		}
+		if alphaBits > 0 {
+			endpoints[i][3] = expandBits(endpoints[i][3], alphaBits)
+		} else {
+			endpoints[i][3] = 255
+		}
+	}
+
+	subsets := bc7Subsets(mode.subsets, partition)
+	anchors := bc7Anchors(mode.subsets, partition)
+	readIndices := func(bits int) [16]int {
+		var indices [16]int
+		for i := range indices {
+			n := bits
+			for _, anchor := range anchors {
+				if i == anchor {
+					n--
+				}
+			}
+			indices[i] = r.read(n)
+		}
+		return indices
+	}
+	colorIndices := readIndices(mode.indexBits)
+	alphaIndices, colorIndexBits, alphaIndexBits := colorIndices, mode.indexBits, mode.indexBits
+	if mode.indexBits2 > 0 {
+		alphaIndices, alphaIndexBits = readIndices(mode.indexBits2), mode.indexBits2
+		if selection == 1 {
+			colorIndices, alphaIndices = alphaIndices, colorIndices
+			colorIndexBits, alphaIndexBits = alphaIndexBits, colorIndexBits
+		}
+	}
+
+	for i := range pixels {
+		e0, e1 := endpoints[2*subsets[i]], endpoints[2*subsets[i]+1]
+		var c [4]int
+		for ch := 0; ch < 3; ch++ {
+			c[ch] = interpolate(e0[ch], e1[ch], bc7Weights[colorIndexBits][colorIndices[i]])
+		}
+		c[3] = interpolate(e0[3], e1[3], bc7Weights[alphaIndexBits][alphaIndices[i]])
+		if rotation > 0 {
+			c[rotation-1], c[3] = c[3], c[rotation-1]
+		}
+		pixels[i] = color.NRGBA{R: uint8(c[0]), G: uint8(c[1]), B: uint8(c[2]), A: uint8(c[3])}
+	}
+}
+
+// expandBits scales an n-bit value to 8 bits by repeating its high bits
+func expandBits(value, n int) int {
+	value <<= 8 - n
+	return value | value>>n
+}
+
+// interpolate blends two endpoints with a weight out of 64
+func interpolate(e0, e1, weight int) int {
+	return ((64-weight)*e0 + weight*e1 + 32) >> 6
+}
+
+// bc7Subsets returns the subset of each pixel of a partition
+func bc7Subsets(subsets, partition int) [16]int {
+	var result [16]int
+	switch subsets {
+	case 2:
+		for i := range result {
+			result[i] = int(bc7Partitions2[partition] >> i & 1)
+		}
+	case 3:
+		for i := range result {
+			result[i] = int(bc7Partitions3[partition] >> (2 * i) & 3)
+		}
+	}
+	return result
+}
+
+// bc7Anchors returns the anchor pixel of each subset of a partition, whose
+// index has one bit less as its high bit is always 0
+func bc7Anchors(subsets, partition int) []int {
+	switch subsets {
+	case 2:
+		return []int{0, int(bc7Anchors2[partition])}
+	case 3:
+		return []int{0, int(bc7Anchors3a[partition]), int(bc7Anchors3b[partition])}
+	}
+	return []int{0}
+}
@@ -0,0 +1,330 @@
+// Package dds decodes DirectDraw Surface textures as used by the game and
+// mods: uncompressed RGB, luminance and alpha surfaces, the block compressed
+// BC1 to BC5 formats (DXT1 to DXT5, ATI1 and ATI2) and BC7, with the legacy
+// header or the DX10 header extension. Only the top-level mipmap of the
+// first surface is decoded.
+//
+// Importing the package registers the format with the image package:
+//
+//	import _ "github.com/danaketh/StellarisDataParser/lib/dds"
+package dds
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math/bits"
+)
+
+func init() {
+	image.RegisterFormat("dds", Magic, Decode, DecodeConfig)
+}
+
+// Magic starts every DDS file
+const Magic = "DDS "
+
+const (
+	headerSize      = 124 // DDS_HEADER without the magic
+	pixelFormatSize = 32  // DDS_PIXELFORMAT
+	dx10HeaderSize  = 20  // DDS_HEADER_DXT10
+
+	pfAlphaPixels = 0x1
+	pfAlpha       = 0x2
+	pfFourCC      = 0x4
+	pfRGB         = 0x40
+	pfLuminance   = 0x20000
+)
+
+// Format is the pixel format of a surface
+type Format int
+
+// Formats the decoder supports
+const (
+	FormatRGB  Format = iota // Uncompressed, with bit masks per channel
+	FormatBC1                // DXT1
+	FormatBC2                // DXT2 and DXT3
+	FormatBC3                // DXT4 and DXT5
+	FormatBC4                // ATI1, unsigned
+	FormatBC4S               // BC4, signed
+	FormatBC5                // ATI2, unsigned
+	FormatBC5S               // BC5, signed
+	FormatBC7
+)
+
+var formatNames = map[Format]string{
+	FormatRGB:  "RGB",
+	FormatBC1:  "BC1",
+	FormatBC2:  "BC2",
+	FormatBC3:  "BC3",
+	FormatBC4:  "BC4",
+	FormatBC4S: "BC4 signed",
+	FormatBC5:  "BC5",
+	FormatBC5S: "BC5 signed",
+	FormatBC7:  "BC7",
+}
+
+func (f Format) String() string {
+	return formatNames[f]
+}
+
+// blockSize returns the number of bytes of a 4×4 block of a compressed
+// format
+func (f Format) blockSize() int {
+	if f == FormatBC1 || f == FormatBC4 || f == FormatBC4S {
+		return 8
+	}
+	return 16
+}
+
+// Legacy FourCC codes of the compressed formats
+var fourCCFormats = map[string]Format{
+	"DXT1": FormatBC1,
+	"DXT2": FormatBC2,
+	"DXT3": FormatBC2,
+	"DXT4": FormatBC3,
+	"DXT5": FormatBC3,
+	"ATI1": FormatBC4,
+	"BC4U": FormatBC4,
+	"BC4S": FormatBC4S,
+	"ATI2": FormatBC5,
+	"BC5U": FormatBC5,
+	"BC5S": FormatBC5S,
+}
+
+// DXGI formats of the DX10 header. Uncompressed formats are described with
+// the bit masks of the legacy header.
+var dxgiFormats = map[uint32]struct {
+	format Format
+	masks  pixelFormat
+}{
+	28: {format: FormatRGB, masks: rgba8}, // R8G8B8A8_UNORM
+	29: {format: FormatRGB, masks: rgba8}, // R8G8B8A8_UNORM_SRGB
+	71: {format: FormatBC1},               // BC1_UNORM
+	72: {format: FormatBC1},               // BC1_UNORM_SRGB
+	74: {format: FormatBC2},               // BC2_UNORM
+	75: {format: FormatBC2},               // BC2_UNORM_SRGB
+	77: {format: FormatBC3},               // BC3_UNORM
+	78: {format: FormatBC3},               // BC3_UNORM_SRGB
+	80: {format: FormatBC4},               // BC4_UNORM
+	81: {format: FormatBC4S},              // BC4_SNORM
+	83: {format: FormatBC5},               // BC5_UNORM
+	84: {format: FormatBC5S},              // BC5_SNORM
+	87: {format: FormatRGB, masks: bgra8}, // B8G8R8A8_UNORM
+	88: {format: FormatRGB, masks: bgrx8}, // B8G8R8X8_UNORM
+	91: {format: FormatRGB, masks: bgra8}, // B8G8R8A8_UNORM_SRGB
+	93: {format: FormatRGB, masks: bgrx8}, // B8G8R8X8_UNORM_SRGB
+	98: {format: FormatBC7},               // BC7_UNORM
+	99: {format: FormatBC7},               // BC7_UNORM_SRGB
+}
+
+// pixelFormat is the layout of an uncompressed pixel
+type pixelFormat struct {
+	flags                      uint32
+	bitCount                   uint32
+	rMask, gMask, bMask, aMask uint32
+}
+
+var (
+	rgba8 = pixelFormat{pfRGB | pfAlphaPixels, 32, 0xff, 0xff00, 0xff0000, 0xff000000}
+	bgra8 = pixelFormat{pfRGB | pfAlphaPixels, 32, 0xff0000, 0xff00, 0xff, 0xff000000}
+	bgrx8 = pixelFormat{pfRGB, 32, 0xff0000, 0xff00, 0xff, 0}
+)
+
+// Header describes the surface of a DDS file
+type Header struct {
+	Width, Height int
+	Format        Format
+	pixels        pixelFormat // Layout of uncompressed pixels
+}
+
+// ReadHeader reads the header of a DDS file, including the DX10 extension
+func ReadHeader(r io.Reader) (Header, error) {
+	var buf [4 + headerSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return Header{}, fmt.Errorf("failed to read header: %w", err)
+	}
+	if string(buf[:4]) != Magic {
+		return Header{}, fmt.Errorf("not a DDS file")
+	}
+	field := func(offset int) uint32 {
+		return binary.LittleEndian.Uint32(buf[4+offset:])
+	}
+	if size := field(0); size != headerSize {
+		return Header{}, fmt.Errorf("invalid header size %d", size)
+	}
+	if size := field(72); size != pixelFormatSize {
+		return Header{}, fmt.Errorf("invalid pixel format size %d", size)
+	}
+
+	h := Header{Height: int(field(8)), Width: int(field(12))}
+	if h.Width <= 0 || h.Height <= 0 || h.Width > 1<<14 || h.Height > 1<<14 {
+		return Header{}, fmt.Errorf("invalid size %dx%d", h.Width, h.Height)
+	}
+	h.pixels = pixelFormat{
+		flags:    field(76),
+		bitCount: field(84),
+		rMask:    field(88),
+		gMask:    field(92),
+		bMask:    field(96),
+		aMask:    field(100),
+	}
+	if h.pixels.flags&pfFourCC == 0 {
+		return h, h.pixels.check()
+	}
+
+	fourCC := string(buf[4+80 : 4+84])
+	if fourCC != "DX10" {
+		format, ok := fourCCFormats[fourCC]
+		if !ok {
+			return Header{}, fmt.Errorf("unsupported compression %q", fourCC)
+		}
+		h.Format = format
+		return h, nil
+	}
+
+	var dx10 [dx10HeaderSize]byte
+	if _, err := io.ReadFull(r, dx10[:]); err != nil {
+		return Header{}, fmt.Errorf("failed to read DX10 header: %w", err)
+	}
+	dxgi := binary.LittleEndian.Uint32(dx10[:])
+	format, ok := dxgiFormats[dxgi]
+	if !ok {
+		return Header{}, fmt.Errorf("unsupported DXGI format %d", dxgi)
+	}
+	h.Format, h.pixels = format.format, format.masks
+	return h, nil
+}
+
+// check returns an error for uncompressed pixel layouts that can't be
+// decoded
+func (pf pixelFormat) check() error {
+	if pf.flags&(pfRGB|pfLuminance|pfAlpha) == 0 {
+		return fmt.Errorf("unsupported pixel format flags %#x", pf.flags)
+	}
+	if pf.bitCount == 0 || pf.bitCount > 32 || pf.bitCount%8 != 0 {
+		return fmt.Errorf("unsupported bit count %d", pf.bitCount)
+	}
+	return nil
+}
+
+// DecodeConfig returns the size and color model of a DDS image
+func DecodeConfig(r io.Reader) (image.Config, error) {
+	h, err := ReadHeader(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{ColorModel: color.NRGBAModel, Width: h.Width, Height: h.Height}, nil
+}
+
+// Decode reads a DDS image
+func Decode(r io.Reader) (image.Image, error) {
+	h, err := ReadHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, h.Width, h.Height))
+
+	if h.Format == FormatRGB {
+		bytesPerPixel := int(h.pixels.bitCount / 8)
+		row := make([]byte, h.Width*bytesPerPixel)
+		for y := 0; y < h.Height; y++ {
+			if _, err := io.ReadFull(r, row); err != nil {
+				return nil, fmt.Errorf("failed to read pixels: %w", err)
+			}
+			for x := 0; x < h.Width; x++ {
+				var value uint32
+				for i := bytesPerPixel - 1; i >= 0; i-- {
+					value = value<<8 | uint32(row[x*bytesPerPixel+i])
+				}
+				img.SetNRGBA(x, y, h.pixels.color(value))
+			}
+		}
+		return img, nil
+	}
+
+	block := make([]byte, h.Format.blockSize())
+	var pixels [16]color.NRGBA
+	for by := 0; by < (h.Height+3)/4; by++ {
+		for bx := 0; bx < (h.Width+3)/4; bx++ {
+			if _, err := io.ReadFull(r, block); err != nil {
+				return nil, fmt.Errorf("failed to read %s block: %w", h.Format, err)
+			}
+			decodeBlock(h.Format, block, &pixels)
+			for i, c := range pixels {
+				x, y := bx*4+i%4, by*4+i/4
+				if x < h.Width && y < h.Height {
+					img.SetNRGBA(x, y, c)
+				}
+			}
+		}
+	}
+	return img, nil
+}
+
+// color returns the color of an uncompressed pixel. Luminance surfaces are
+// gray, alpha-only surfaces are black with the alpha, and channels without a
+// mask are 0, or opaque for alpha.
+func (pf pixelFormat) color(value uint32) color.NRGBA {
+	c := color.NRGBA{A: 255}
+	switch {
+	case pf.flags&pfRGB != 0:
+		c.R, c.G, c.B = channel(value, pf.rMask), channel(value, pf.gMask), channel(value, pf.bMask)
+	case pf.flags&pfLuminance != 0:
+		c.R = channel(value, pf.rMask)
+		c.G, c.B = c.R, c.R
+	}
+	if pf.flags&(pfAlphaPixels|pfAlpha) != 0 && pf.aMask != 0 {
+		c.A = channel(value, pf.aMask)
+	}
+	return c
+}
+
+// channel extracts the bits of a mask from a pixel and scales them to 8 bits
+func channel(value, mask uint32) uint8 {
+	if mask == 0 {
+		return 0
+	}
+	width := bits.OnesCount32(mask)
+	max := uint32(1)<<width - 1
+	v := (value & mask) >> bits.TrailingZeros32(mask)
+	return uint8((v*255 + max/2) / max)
+}
+
+// decodeBlock decodes a 4×4 block of a compressed format into pixels, in
+// rows from the top left
+func decodeBlock(format Format, block []byte, pixels *[16]color.NRGBA) {
+	switch format {
+	case FormatBC1:
+		decodeBC1(block, pixels, true)
+	case FormatBC2:
+		decodeBC1(block[8:], pixels, false)
+		for i := range pixels {
+			alpha := block[i/2] >> (4 * (i % 2)) & 0xf
+			pixels[i].A = alpha<<4 | alpha
+		}
+	case FormatBC3:
+		decodeBC1(block[8:], pixels, false)
+		var alpha [16]uint8
+		decodeBC4(block, &alpha, false)
+		for i := range pixels {
+			pixels[i].A = alpha[i]
+		}
+	case FormatBC4, FormatBC4S:
+		var red [16]uint8
+		decodeBC4(block, &red, format == FormatBC4S)
+		for i := range pixels {
+			pixels[i] = color.NRGBA{R: red[i], G: red[i], B: red[i], A: 255}
+		}
+	case FormatBC5, FormatBC5S:
+		var red, green [16]uint8
+		decodeBC4(block, &red, format == FormatBC5S)
+		decodeBC4(block[8:], &green, format == FormatBC5S)
+		for i := range pixels {
+			pixels[i] = color.NRGBA{R: red[i], G: green[i], A: 255}
+		}
+	case FormatBC7:
+		decodeBC7(block, pixels)
+	}
+}
@@ -0,0 +1,309 @@
+package dds
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// decodeFixture decodes a texture of testdata/textures
+func decodeFixture(t *testing.T, name string) image.Image {
+	t.Helper()
+	file, err := os.Open(filepath.Join("../../testdata/textures", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	img, format, err := image.Decode(file)
+	if err != nil {
+		t.Fatalf("Failed to decode %s: %v", name, err)
+	}
+	if format != "dds" {
+		t.Errorf("Expected the dds format to be registered, got %q", format)
+	}
+	return img
+}
+
+func expectPixels(t *testing.T, img image.Image, expected map[image.Point]color.NRGBA) {
+	t.Helper()
+	for point, want := range expected {
+		if got := color.NRGBAModel.Convert(img.At(point.X, point.Y)); got != want {
+			t.Errorf("Expected %v at %v, got %v", want, point, got)
+		}
+	}
+}
+
+func TestDecodeUncompressed(t *testing.T) {
+	img := decodeFixture(t, "bgra.dds")
+	expectPixels(t, img, map[image.Point]color.NRGBA{
+		{0, 0}: {255, 0, 0, 255},
+		{1, 0}: {0, 255, 0, 255},
+		{0, 1}: {0, 0, 255, 255},
+		{1, 1}: {255, 255, 255, 128},
+	})
+}
+
+func TestDecodeBC1(t *testing.T) {
+	img := decodeFixture(t, "dxt1.dds")
+	if size := img.Bounds().Size(); size != image.Pt(6, 6) {
+		t.Fatalf("Expected 6x6, got %v", size)
+	}
+	red, blue := color.NRGBA{255, 0, 0, 255}, color.NRGBA{0, 0, 255, 255}
+	expectPixels(t, img, map[image.Point]color.NRGBA{
+		// Four colors
+		{0, 0}: red,
+		{1, 0}: blue,
+		{2, 0}: {170, 0, 85, 255},
+		{3, 3}: {85, 0, 170, 255},
+		// Three colors and transparent black, clipped to the image
+		{4, 0}: blue,
+		{5, 1}: red,
+		// Solid blocks
+		{0, 4}: {0, 255, 0, 255},
+		{5, 5}: {0, 255, 0, 255},
+	})
+}
+
+func TestDecodeBC1Transparent(t *testing.T) {
+	block := []byte{0x1f, 0x00, 0x00, 0xf8, 0xe4, 0xe4, 0xe4, 0xe4}
+	var pixels [16]color.NRGBA
+	decodeBlock(FormatBC1, block, &pixels)
+	if pixels[2] != (color.NRGBA{127, 0, 127, 255}) {
+		t.Errorf("Expected the average of both colors, got %v", pixels[2])
+	}
+	if pixels[3] != (color.NRGBA{}) {
+		t.Errorf("Expected transparent black, got %v", pixels[3])
+	}
+
+	// The color block of BC3 always has four colors
+	bc3 := append([]byte{255, 0, 0, 0, 0, 0, 0, 0}, block...)
+	decodeBlock(FormatBC3, bc3, &pixels)
+	if pixels[3] != (color.NRGBA{170, 0, 85, 255}) {
+		t.Errorf("Expected a blend in BC3, got %v", pixels[3])
+	}
+}
+
+func TestDecodeBC3Alpha(t *testing.T) {
+	// Eight alpha values: pixel i uses index i%8
+	var indices uint64
+	for i := 0; i < 16; i++ {
+		indices |= uint64(i%8) << (3 * i)
+	}
+	block := []byte{255, 0}
+	for i := 0; i < 6; i++ {
+		block = append(block, byte(indices>>(8*i)))
+	}
+	block = append(block, 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0)
+
+	var pixels [16]color.NRGBA
+	decodeBlock(FormatBC3, block, &pixels)
+	expected := []uint8{255, 0, 218, 182, 145, 109, 72, 36}
+	for i, alpha := range expected {
+		if pixels[i].A != alpha {
+			t.Errorf("Expected alpha %d for index %d, got %d", alpha, i, pixels[i].A)
+		}
+		if pixels[i].R != 255 || pixels[i].G != 255 || pixels[i].B != 255 {
+			t.Errorf("Expected white, got %v", pixels[i])
+		}
+	}
+
+	// Six values with 0 and 255
+	block[0], block[1] = 0, 255
+	decodeBlock(FormatBC3, block, &pixels)
+	expected = []uint8{0, 255, 51, 102, 153, 204, 0, 255}
+	for i, alpha := range expected {
+		if pixels[i].A != alpha {
+			t.Errorf("Expected alpha %d for index %d with six values, got %d", alpha, i, pixels[i].A)
+		}
+	}
+}
+
+func TestDecodeBC4Signed(t *testing.T) {
+	// -128 is clamped to -1 like -127
+	block := []byte{127, 0x80, 0b001000, 0, 0, 0, 0, 0}
+	var pixels [16]color.NRGBA
+	decodeBlock(FormatBC4S, block, &pixels)
+	if pixels[0] != (color.NRGBA{255, 255, 255, 255}) {
+		t.Errorf("Expected 1 to map to white, got %v", pixels[0])
+	}
+	if pixels[1] != (color.NRGBA{0, 0, 0, 255}) {
+		t.Errorf("Expected -1 to map to black, got %v", pixels[1])
+	}
+}
+
+func TestDecodeBC5(t *testing.T) {
+	img := decodeFixture(t, "bc5.dds")
+	expectPixels(t, img, map[image.Point]color.NRGBA{
+		{0, 0}: {255, 0, 0, 255},
+		{1, 0}: {255, 255, 0, 255},
+		// Green has six values with 0 and 255
+		{2, 0}: {255, 51, 0, 255},
+		{2, 1}: {255, 0, 0, 255},
+		{0, 2}: {0, 0, 0, 255},
+		{3, 3}: {0, 255, 0, 255},
+	})
+}
+
+func TestDecodeBC7(t *testing.T) {
+	img := decodeFixture(t, "bc7.dds")
+	// Mode 6: red from 255 to 1, pixel i with index i. The p-bits of 1 make
+	// green and blue 1 as well.
+	weights := []int{0, 4, 9, 13, 17, 21, 26, 30, 34, 38, 43, 47, 51, 55, 60, 64}
+	expected := map[image.Point]color.NRGBA{}
+	for i, w := range weights {
+		red := ((64-w)*255 + w*1 + 32) >> 6
+		expected[image.Pt(i%4, i/4)] = color.NRGBA{uint8(red), 1, 1, 255}
+	}
+	// An invalid block is transparent black
+	expected[image.Pt(4, 0)] = color.NRGBA{}
+	expected[image.Pt(7, 3)] = color.NRGBA{}
+	expectPixels(t, img, expected)
+}
+
+// bitWriter builds a BC7 block
+type bitWriter struct {
+	block [16]byte
+	pos   int
+}
+
+func (w *bitWriter) write(value, n int) {
+	for i := 0; i < n; i++ {
+		w.block[w.pos/8] |= byte(value>>i&1) << (w.pos % 8)
+		w.pos++
+	}
+}
+
+func TestDecodeBC7Modes(t *testing.T) {
+	t.Run("mode 1 with two subsets", func(t *testing.T) {
+		w := &bitWriter{}
+		w.write(0b10, 2) // Mode 1
+		w.write(0, 6)    // Partition 0: columns 2 and 3 in the second subset
+		// Red, green and blue of both endpoints of each subset
+		for _, endpoints := range [][]int{{63, 63, 0, 0}, {0, 0, 63, 63}, {0, 0, 0, 0}} {
+			for _, value := range endpoints {
+				w.write(value, 6)
+			}
+		}
+		w.write(1, 1) // Shared p-bits
+		w.write(0, 1)
+		w.write(0, 46) // Index 0 everywhere
+		if w.pos != 128 {
+			t.Fatalf("Expected 128 bits, got %d", w.pos)
+		}
+
+		var pixels [16]color.NRGBA
+		decodeBC7(w.block[:], &pixels)
+		for i, c := range pixels {
+			want := color.NRGBA{255, 2, 2, 255}
+			if i%4 >= 2 {
+				want = color.NRGBA{0, 253, 0, 255}
+			}
+			if c != want {
+				t.Errorf("Expected %v for pixel %d, got %v", want, i, c)
+			}
+		}
+	})
+
+	t.Run("mode 5 with rotation", func(t *testing.T) {
+		w := &bitWriter{}
+		w.write(0b100000, 6) // Mode 5
+		w.write(1, 2)        // Swap red and alpha
+		for _, value := range []int{127, 127, 64, 64, 0, 0} {
+			w.write(value, 7)
+		}
+		w.write(100, 8)
+		w.write(100, 8)
+		w.write(0, 31)
+		w.write(0, 31)
+		if w.pos != 128 {
+			t.Fatalf("Expected 128 bits, got %d", w.pos)
+		}
+
+		var pixels [16]color.NRGBA
+		decodeBC7(w.block[:], &pixels)
+		if want := (color.NRGBA{100, 129, 0, 255}); pixels[0] != want {
+			t.Errorf("Expected %v, got %v", want, pixels[0])
+		}
+	})
+
+	t.Run("mode 4 with index selection", func(t *testing.T) {
+		w := &bitWriter{}
+		w.write(0b10000, 5) // Mode 4
+		w.write(0, 2)
+		w.write(1, 1) // Colors use the 3-bit indices
+		for _, value := range []int{0, 31, 0, 0, 0, 0} {
+			w.write(value, 5)
+		}
+		w.write(0, 6)
+		w.write(63, 6)
+		w.write(0, 1)  // Pixel 0, 2-bit alpha index
+		w.write(1, 2)  // Pixel 1
+		w.write(0, 28) // Remaining 2-bit indices
+		w.write(0, 2)  // Pixel 0, 3-bit color index
+		w.write(7, 3)  // Pixel 1
+		w.write(0, 42)
+		if w.pos != 128 {
+			t.Fatalf("Expected 128 bits, got %d", w.pos)
+		}
+
+		var pixels [16]color.NRGBA
+		decodeBC7(w.block[:], &pixels)
+		if want := (color.NRGBA{255, 0, 0, 84}); pixels[1] != want {
+			t.Errorf("Expected %v, got %v", want, pixels[1])
+		}
+		if want := (color.NRGBA{0, 0, 0, 0}); pixels[0] != want {
+			t.Errorf("Expected %v, got %v", want, pixels[0])
+		}
+	})
+}
+
+func TestBC7Anchors(t *testing.T) {
+	for partition := 0; partition < 64; partition++ {
+		for _, subsets := range []int{2, 3} {
+			pixels := bc7Subsets(subsets, partition)
+			for subset, anchor := range bc7Anchors(subsets, partition) {
+				if pixels[anchor] != subset {
+					t.Errorf("Partition %d of %d subsets: anchor %d is in subset %d, expected %d", partition, subsets, anchor, pixels[anchor], subset)
+				}
+			}
+		}
+	}
+}
+
+func TestReadHeader(t *testing.T) {
+	file, err := os.ReadFile("../../testdata/textures/bc7.dds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := ReadHeader(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Width != 8 || h.Height != 4 || h.Format != FormatBC7 {
+		t.Errorf("Expected an 8x4 BC7 surface, got %dx%d %s", h.Width, h.Height, h.Format)
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(file))
+	if err != nil || format != "dds" || config.Width != 8 || config.Height != 4 {
+		t.Errorf("Expected the config of an 8x4 image, got %+v, %q, %v", config, format, err)
+	}
+
+	unsupported := bytes.Clone(file)
+	unsupported[128] = 2 // R32G32B32A32_FLOAT
+	if _, err := ReadHeader(bytes.NewReader(unsupported)); err == nil || !strings.Contains(err.Error(), "unsupported DXGI format 2") {
+		t.Errorf("Expected an unsupported format error, got %v", err)
+	}
+
+	truncated := file[:len(file)-8]
+	if _, err := Decode(bytes.NewReader(truncated)); err == nil {
+		t.Error("Expected an error for a truncated file")
+	}
+
+	if _, err := ReadHeader(strings.NewReader("PNG not a texture")); err == nil {
+		t.Error("Expected an error for a file that isn't DDS")
+	}
+}
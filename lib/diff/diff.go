@@ -0,0 +1,102 @@
+// Package diff compares the technologies of two game versions and reports
+// the technologies added, removed and changed, with the fields that
+// changed.
+package diff
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/danaketh/StellarisDataParser/lib/filter"
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// FieldChange describes a single field whose value differs between versions
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// Change lists the changed fields of a technology present in both versions
+type Change struct {
+	Key    string        `json:"key"`
+	Fields []FieldChange `json:"fields"`
+}
+
+// Result is the difference between two sets of technologies
+type Result struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changed []Change `json:"changed"`
+}
+
+// Empty reports whether both sets are identical
+func (r *Result) Empty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0
+}
+
+// ignoredFields are not compared because they change without affecting the
+// technology itself
+var ignoredFields = map[string]bool{
+	"sourceFile": true,
+}
+
+// Compare returns the technologies added, removed and changed between
+// oldTechs and newTechs. Fields are compared using the same names as the
+// generated JSON. All lists in the result are sorted.
+func Compare(oldTechs, newTechs map[string]*models.Technology) *Result {
+	result := &Result{
+		Added:   []string{},
+		Removed: []string{},
+		Changed: []Change{},
+	}
+
+	for key := range newTechs {
+		if _, exists := oldTechs[key]; !exists {
+			result.Added = append(result.Added, key)
+		}
+	}
+
+	for key, oldTech := range oldTechs {
+		newTech, exists := newTechs[key]
+		if !exists {
+			result.Removed = append(result.Removed, key)
+			continue
+		}
+
+		if fields := compareFields(oldTech, newTech); len(fields) > 0 {
+			result.Changed = append(result.Changed, Change{Key: key, Fields: fields})
+		}
+	}
+
+	sort.Strings(result.Added)
+	sort.Strings(result.Removed)
+	sort.Slice(result.Changed, func(i, j int) bool {
+		return result.Changed[i].Key < result.Changed[j].Key
+	})
+
+	return result
+}
+
+// compareFields returns the changed fields of a technology, sorted by name
+func compareFields(oldTech, newTech *models.Technology) []FieldChange {
+	oldFields := filter.TechnologyFields(oldTech)
+	newFields := filter.TechnologyFields(newTech)
+
+	var changes []FieldChange
+	for field, oldValue := range oldFields {
+		if ignoredFields[field] {
+			continue
+		}
+		newValue := newFields[field]
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, FieldChange{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
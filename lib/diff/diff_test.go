@@ -0,0 +1,55 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+func TestCompare(t *testing.T) {
+	oldTechs := map[string]*models.Technology{
+		"tech_kept":    {Key: "tech_kept", Cost: 100, Area: "physics", Category: []string{"particles"}},
+		"tech_changed": {Key: "tech_changed", Cost: 100, Tier: 1, Category: []string{"particles"}},
+		"tech_removed": {Key: "tech_removed"},
+		"tech_moved":   {Key: "tech_moved", SourceFile: "00_old.txt"},
+	}
+	newTechs := map[string]*models.Technology{
+		"tech_kept":    {Key: "tech_kept", Cost: 100, Area: "physics", Category: []string{"particles"}},
+		"tech_changed": {Key: "tech_changed", Cost: 250, Tier: 1, Category: []string{"particles", "computing"}},
+		"tech_added":   {Key: "tech_added"},
+		"tech_moved":   {Key: "tech_moved", SourceFile: "00_new.txt"},
+	}
+
+	result := Compare(oldTechs, newTechs)
+
+	if len(result.Added) != 1 || result.Added[0] != "tech_added" {
+		t.Errorf("Unexpected added technologies: %v", result.Added)
+	}
+	if len(result.Removed) != 1 || result.Removed[0] != "tech_removed" {
+		t.Errorf("Unexpected removed technologies: %v", result.Removed)
+	}
+	if len(result.Changed) != 1 {
+		t.Fatalf("Expected 1 changed technology, got %v", result.Changed)
+	}
+
+	change := result.Changed[0]
+	if change.Key != "tech_changed" {
+		t.Errorf("Expected tech_changed, got %s", change.Key)
+	}
+	if len(change.Fields) != 2 || change.Fields[0].Field != "category" || change.Fields[1].Field != "cost" {
+		t.Fatalf("Unexpected field changes: %+v", change.Fields)
+	}
+	if change.Fields[1].Old != 100 || change.Fields[1].New != 250 {
+		t.Errorf("Unexpected cost change: %+v", change.Fields[1])
+	}
+}
+
+func TestCompareIdentical(t *testing.T) {
+	techs := map[string]*models.Technology{
+		"tech_a": {Key: "tech_a", Cost: 100},
+	}
+
+	if result := Compare(techs, techs); !result.Empty() {
+		t.Errorf("Expected no differences, got %+v", result)
+	}
+}
@@ -0,0 +1,55 @@
+package filter
+
+import (
+	"sort"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// TechnologyFields returns the filterable fields of a technology, named
+// like the keys in the generated JSON
+func TechnologyFields(tech *models.Technology) Fields {
+	return Fields{
+		"key":           tech.Key,
+		"name":          tech.Name,
+		"description":   tech.Description,
+		"cost":          tech.Cost,
+		"costPerLevel":  tech.CostPerLevel,
+		"area":          tech.Area,
+		"tier":          tech.Tier,
+		"category":      tech.Category,
+		"prerequisites": tech.Prerequisites,
+		"weight":        tech.Weight,
+		"sourceFile":    tech.SourceFile,
+		"mod":           tech.Mod,
+		"icon":          tech.Icon,
+		"isStartTech":   tech.IsStartTech,
+		"isDangerous":   tech.IsDangerous,
+		"isRare":        tech.IsRare,
+		"isEvent":       tech.IsEvent,
+		"isInsight":     tech.IsInsight,
+		"acquisition":   tech.Acquisition(),
+		"isReverse":     tech.IsReverse,
+		"isRepeatable":  tech.IsRepeatable,
+		"isInfinite":    tech.IsInfinite(),
+		"levels":        tech.Levels,
+		"isGestalt":     tech.IsGestalt,
+		"isMegacorp":    tech.IsMegacorp,
+		"isMachine":     tech.IsMachineEmpire,
+		"isHive":        tech.IsHiveEmpire,
+	}
+}
+
+// TechnologyFieldNames returns the sorted names of all fields available in
+// technology filters. "level" (the depth in the tree) and "estimatedYear"
+// are included as well.
+func TechnologyFieldNames() []string {
+	fields := TechnologyFields(&models.Technology{})
+	names := make([]string, 0, len(fields)+2)
+	for name := range fields {
+		names = append(names, name)
+	}
+	names = append(names, "level", "estimatedYear")
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,395 @@
+// Package filter compiles -where expressions, such as
+// area == "physics" && tier >= 2, and matches them against the fields of a
+// technology.
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Fields maps field names to their values for a single record.
+// Supported value types are bool, string, []string and any integer or
+// float type.
+type Fields map[string]interface{}
+
+// Expression is a compiled filter expression such as
+// `tier >= 3 && area == "physics" && isRare`
+type Expression struct {
+	source string
+	root   node
+}
+
+// node is a single element of the expression syntax tree
+type node interface {
+	eval(fields Fields) (interface{}, error)
+}
+
+// Compile parses a filter expression. If knownFields is not nil, every
+// identifier in the expression must be one of them.
+//
+// Supported syntax:
+//   - literals: "string", 'string', numbers, true, false
+//   - comparison: ==, !=, <, <=, >, >= (== and != on a list test membership)
+//   - logic: &&, ||, ! and parentheses
+//   - a bare field name is true when its value is true, non-zero or non-empty
+func Compile(expression string, knownFields []string) (*Expression, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{tokens: tokens, knownFields: knownFields}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at column %d", tok.text, tok.pos)
+	}
+
+	return &Expression{source: expression, root: root}, nil
+}
+
+// String returns the source text of the expression
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Match evaluates the expression against a record
+func (e *Expression) Match(fields Fields) (bool, error) {
+	value, err := e.root.eval(fields)
+	if err != nil {
+		return false, err
+	}
+	return truthy(value), nil
+}
+
+// And returns an expression matching the records that match every non-nil
+// expression, or nil if all are nil
+func And(exprs ...*Expression) *Expression {
+	var combined *Expression
+	for _, expr := range exprs {
+		switch {
+		case expr == nil:
+		case combined == nil:
+			combined = expr
+		default:
+			combined = &Expression{
+				source: "(" + combined.source + ") && (" + expr.source + ")",
+				root:   &logicalNode{op: "&&", left: combined.root, right: expr.root},
+			}
+		}
+	}
+	return combined
+}
+
+// exprParser is a recursive descent parser over a token list
+type exprParser struct {
+	tokens      []token
+	pos         int
+	knownFields []string
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseOr parses: and ('||' and)*
+func (p *exprParser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOperator && p.peek().text == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses: unary ('&&' unary)*
+func (p *exprParser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOperator && p.peek().text == "&&" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseUnary parses: '!' unary | comparison
+func (p *exprParser) parseUnary() (node, error) {
+	if p.peek().kind == tokenOperator && p.peek().text == "!" {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison parses: primary (op primary)?
+func (p *exprParser) parseComparison() (node, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	if tok.kind == tokenOperator {
+		switch tok.text {
+		case "==", "!=", "<", "<=", ">", ">=":
+			p.next()
+			right, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			return &compareNode{op: tok.text, left: left, right: right, pos: tok.pos}, nil
+		}
+	}
+	return left, nil
+}
+
+// parsePrimary parses a literal, field reference or parenthesized expression
+func (p *exprParser) parsePrimary() (node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenString:
+		return &literalNode{value: tok.text}, nil
+	case tokenNumber:
+		return &literalNode{value: tok.number}, nil
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		}
+		if p.knownFields != nil && !contains(p.knownFields, tok.text) {
+			return nil, &UnknownFieldError{Field: tok.text, Column: tok.pos}
+		}
+		return &fieldNode{name: tok.text}, nil
+	case tokenLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("expected ')' at column %d", closing.pos)
+		}
+		return inner, nil
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	default:
+		return nil, fmt.Errorf("unexpected %q at column %d", tok.text, tok.pos)
+	}
+}
+
+// UnknownFieldError is returned by Compile for identifiers that are not
+// valid field names
+type UnknownFieldError struct {
+	Field  string
+	Column int
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field %q at column %d", e.Field, e.Column)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(Fields) (interface{}, error) {
+	return n.value, nil
+}
+
+type fieldNode struct {
+	name string
+}
+
+func (n *fieldNode) eval(fields Fields) (interface{}, error) {
+	value, ok := fields[n.name]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q", n.name)
+	}
+	return normalize(value), nil
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n *notNode) eval(fields Fields) (interface{}, error) {
+	value, err := n.operand.eval(fields)
+	if err != nil {
+		return nil, err
+	}
+	return !truthy(value), nil
+}
+
+type logicalNode struct {
+	op          string
+	left, right node
+}
+
+func (n *logicalNode) eval(fields Fields) (interface{}, error) {
+	left, err := n.left.eval(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	// Short-circuit evaluation
+	if n.op == "&&" && !truthy(left) {
+		return false, nil
+	}
+	if n.op == "||" && truthy(left) {
+		return true, nil
+	}
+
+	right, err := n.right.eval(fields)
+	if err != nil {
+		return nil, err
+	}
+	return truthy(right), nil
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+	pos         int
+}
+
+func (n *compareNode) eval(fields Fields) (interface{}, error) {
+	left, err := n.left.eval(fields)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	}
+
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("operator %s at column %d needs numbers, got %v and %v", n.op, n.pos, left, right)
+	}
+
+	switch n.op {
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	default:
+		return l >= r, nil
+	}
+}
+
+// equal compares two values. A list equals a value if it contains it, and
+// strings are compared case-insensitively.
+func equal(left, right interface{}) bool {
+	if list, ok := left.([]string); ok {
+		return listContains(list, right)
+	}
+	if list, ok := right.([]string); ok {
+		return listContains(list, left)
+	}
+
+	ls, lok := left.(string)
+	rs, rok := right.(string)
+	if lok && rok {
+		return strings.EqualFold(ls, rs)
+	}
+
+	return left == right
+}
+
+// listContains reports whether a list of strings contains value
+func listContains(list []string, value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// truthy converts a value to a boolean
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	case []string:
+		return len(v) > 0
+	default:
+		return false
+	}
+}
+
+// normalize converts numeric types to float64 so they can be compared
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case float32:
+		return float64(v)
+	default:
+		return value
+	}
+}
+
+// contains reports whether list contains value
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+func testFields() Fields {
+	return TechnologyFields(&models.Technology{
+		Key:      "tech_plasma_1",
+		Name:     "Plasma Cannons",
+		Area:     "physics",
+		Tier:     3,
+		Cost:     4000,
+		Category: []string{"particles", "weapons"},
+		IsRare:   true,
+	})
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   bool
+	}{
+		{`tier >= 3 && area == "physics" && isRare`, true},
+		{`tier > 3`, false},
+		{`tier == 3`, true},
+		{`cost < 5000 && cost <= 4000`, true},
+		{`area != "physics"`, false},
+		{`area == 'PHYSICS'`, true},
+		{`!isDangerous`, true},
+		{`isDangerous || isRare`, true},
+		{`isDangerous || (tier == 1 && isRare)`, false},
+		{`category == "weapons"`, true},
+		{`category != "biology"`, true},
+		{`prerequisites`, false},
+		{`name == "plasma cannons"`, true},
+		{`!(tier < 2) && true`, true},
+		{`cost > -1`, true},
+	}
+
+	fields := testFields()
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			expr, err := Compile(tt.expression, TechnologyFieldNames())
+			if err != nil {
+				t.Fatalf("Failed to compile: %v", err)
+			}
+			got, err := expr.Match(fields)
+			if err != nil {
+				t.Fatalf("Failed to evaluate: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []string{
+		`tier >=`,
+		`(tier == 1`,
+		`area == "physics`,
+		`tier == 1 tier`,
+		`tier @ 1`,
+		`teir == 1`,
+	}
+
+	for _, expression := range tests {
+		t.Run(expression, func(t *testing.T) {
+			if _, err := Compile(expression, TechnologyFieldNames()); err == nil {
+				t.Error("Expected compile error")
+			}
+		})
+	}
+}
+
+func TestUnknownFieldError(t *testing.T) {
+	_, err := Compile(`tier == 1 && teir == 2`, TechnologyFieldNames())
+
+	var fieldErr *UnknownFieldError
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("Expected *UnknownFieldError, got %v", err)
+	}
+	if fieldErr.Field != "teir" || fieldErr.Column != 14 {
+		t.Errorf("Unexpected error details: %+v", fieldErr)
+	}
+}
+
+func TestMatchTypeError(t *testing.T) {
+	expr, err := Compile(`area > 3`, nil)
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+	if _, err := expr.Match(testFields()); err == nil {
+		t.Error("Expected error comparing string with number")
+	}
+}
+
+func TestTechnologyFieldNames(t *testing.T) {
+	names := TechnologyFieldNames()
+	for _, want := range []string{"tier", "area", "isRare", "level", "category"} {
+		if !contains(names, want) {
+			t.Errorf("Expected field %q to be available", want)
+		}
+	}
+}
+
+func TestAnd(t *testing.T) {
+	if And(nil, nil) != nil {
+		t.Error("Expected nil when combining no expressions")
+	}
+
+	tier, err := Compile(`tier >= 3`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if And(nil, tier) != tier {
+		t.Error("Expected a single expression to be returned as is")
+	}
+
+	for _, tt := range []struct {
+		area     string
+		expected bool
+	}{
+		{`area == "physics"`, true},
+		{`area == "society"`, false},
+	} {
+		area, err := Compile(tt.area, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		combined := And(tier, nil, area)
+		if matched, err := combined.Match(testFields()); err != nil || matched != tt.expected {
+			t.Errorf("%s: expected %v, got %v (%v)", combined, tt.expected, matched, err)
+		}
+	}
+	if source := And(tier, tier).String(); source != "(tier >= 3) && (tier >= 3)" {
+		t.Errorf("Unexpected source %q", source)
+	}
+}
@@ -0,0 +1,193 @@
+// Package gamefs opens the files of the game or of a mod as an fs.FS for the
+// FS variants of the parsers, from a directory or from a .zip archive.
+package gamefs
+
+import (
+	"archive/zip"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Extensions of the mod files Open accepts besides directories
+const (
+	ZipExt        = ".zip" // Mod archive
+	DescriptorExt = ".mod" // Launcher mod descriptor pointing to a directory or archive
+)
+
+// DescriptorFile is the descriptor at the root of a mod
+const DescriptorFile = "descriptor.mod"
+
+// DLCDir holds a directory for each DLC below the game directory, with the
+// DLC's files in .zip archives
+const DLCDir = "dlc"
+
+// descriptorPattern matches the path and archive entries of a descriptor
+var descriptorPattern = regexp.MustCompile(`(?m)^\s*(path|archive)\s*=\s*"([^"]*)"`)
+
+// Source is the file system of the game or a mod. Close releases the
+// archive of a .zip source.
+type Source interface {
+	fs.FS
+	Close() error
+}
+
+// dirSource is a Source reading a directory
+type dirSource struct {
+	fs.FS
+}
+
+// Close does nothing, directories hold no resources
+func (dirSource) Close() error {
+	return nil
+}
+
+// zipSource is a Source reading a .zip archive
+type zipSource struct {
+	fs.FS
+	archive *zip.ReadCloser
+}
+
+// Close closes the archive
+func (z *zipSource) Close() error {
+	return z.archive.Close()
+}
+
+// Open opens path as a Source: a .zip archive with OpenZip, a .mod
+// descriptor with OpenDescriptor and anything else with Dir
+func Open(path string) (Source, error) {
+	switch ext := filepath.Ext(path); {
+	case strings.EqualFold(ext, ZipExt):
+		return OpenZip(path)
+	case strings.EqualFold(ext, DescriptorExt):
+		return OpenDescriptor(path)
+	}
+	return Dir(path)
+}
+
+// Name returns the mod name of a path given to Open: the base name without
+// the .zip or .mod extension
+func Name(path string) string {
+	name := filepath.Base(path)
+	if ext := filepath.Ext(name); strings.EqualFold(ext, ZipExt) || strings.EqualFold(ext, DescriptorExt) {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
+
+// Dir returns a Source reading the directory dir
+func Dir(dir string) (Source, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	return dirSource{os.DirFS(dir)}, nil
+}
+
+// DLCArchives returns the paths of the .zip archives of the installed DLCs,
+// dlc/<dlc>/*.zip below the game directory, sorted. A game without a dlc
+// directory has none.
+func DLCArchives(gameDir string) ([]string, error) {
+	dirs, err := os.ReadDir(filepath.Join(gameDir, DLCDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to list DLCs: %w", err)
+	}
+
+	var archives []string
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		dlcDir := filepath.Join(gameDir, DLCDir, dir.Name())
+		entries, err := os.ReadDir(dlcDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list DLC %s: %w", dir.Name(), err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.EqualFold(filepath.Ext(entry.Name()), ZipExt) {
+				archives = append(archives, filepath.Join(dlcDir, entry.Name()))
+			}
+		}
+	}
+	return archives, nil
+}
+
+// OpenZip returns a Source reading a .zip archive, such as a mod downloaded
+// outside of the launcher. An archive holding a single directory with the
+// descriptor.mod, as created when a mod directory is zipped, is read from
+// that directory.
+func OpenZip(path string) (Source, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive %s: %w", path, err)
+	}
+
+	root, err := archiveRoot(archive)
+	if err != nil {
+		archive.Close()
+		return nil, fmt.Errorf("failed to read archive %s: %w", path, err)
+	}
+	sub, err := fs.Sub(archive, root)
+	if err != nil {
+		archive.Close()
+		return nil, fmt.Errorf("failed to read archive %s: %w", path, err)
+	}
+	return &zipSource{FS: sub, archive: archive}, nil
+}
+
+// archiveRoot returns the only top-level directory of an archive if it holds
+// the mod's descriptor.mod, or else "."
+func archiveRoot(archive fs.FS) (string, error) {
+	entries, err := fs.ReadDir(archive, ".")
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		if _, err := fs.Stat(archive, path.Join(entries[0].Name(), DescriptorFile)); err == nil {
+			return entries[0].Name(), nil
+		}
+	}
+	return ".", nil
+}
+
+// OpenDescriptor opens the mod a launcher descriptor such as
+// mod/ugc_123.mod points to. Workshop mods shipped as archives name it with
+// archive, others name their directory with path. Relative paths are
+// relative to the user directory holding the mod directory of the
+// descriptor.
+func OpenDescriptor(path string) (Source, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mod descriptor: %w", err)
+	}
+
+	entries := make(map[string]string)
+	for _, match := range descriptorPattern.FindAllStringSubmatch(string(content), -1) {
+		entries[match[1]] = match[2]
+	}
+	target := entries["archive"]
+	if target == "" {
+		target = entries["path"]
+	}
+	if target == "" {
+		return nil, fmt.Errorf("mod descriptor %s has neither path nor archive", path)
+	}
+
+	target = filepath.FromSlash(target)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(filepath.Dir(path)), target)
+	}
+	if strings.EqualFold(filepath.Ext(target), ZipExt) {
+		return OpenZip(target)
+	}
+	return Dir(target)
+}
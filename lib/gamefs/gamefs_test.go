@@ -0,0 +1,187 @@
+package gamefs
+
+import (
+	"archive/zip"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeZip writes an archive holding the given files
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	defer file.Close()
+
+	w := zip.NewWriter(file)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close archive: %v", err)
+	}
+}
+
+func TestOpenDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "common", "technology"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "common", "technology", "a.txt"), []byte("tech_a = {}"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	source, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Failed to open directory: %v", err)
+	}
+	defer source.Close()
+
+	content, err := fs.ReadFile(source, "common/technology/a.txt")
+	if err != nil || string(content) != "tech_a = {}" {
+		t.Errorf("Expected the file content, got %q (%v)", content, err)
+	}
+
+	if _, err := Open(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+	if _, err := Dir(filepath.Join(dir, "common", "technology", "a.txt")); err == nil {
+		t.Error("Expected an error for a file")
+	}
+}
+
+func TestOpenZip(t *testing.T) {
+	tests := map[string]map[string]string{
+		"flat.zip": {
+			"descriptor.mod":          "name=\"Flat\"",
+			"common/technology/a.txt": "tech_a = {}",
+		},
+		"Nested.ZIP": {
+			"my_mod/descriptor.mod":          "name=\"Nested\"",
+			"my_mod/common/technology/a.txt": "tech_a = {}",
+		},
+	}
+
+	dir := t.TempDir()
+	for name, files := range tests {
+		path := filepath.Join(dir, name)
+		writeZip(t, path, files)
+
+		source, err := Open(path)
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", name, err)
+		}
+		content, err := fs.ReadFile(source, "common/technology/a.txt")
+		if err != nil || string(content) != "tech_a = {}" {
+			t.Errorf("%s: expected the file content, got %q (%v)", name, content, err)
+		}
+		if err := source.Close(); err != nil {
+			t.Errorf("%s: failed to close: %v", name, err)
+		}
+	}
+
+	broken := filepath.Join(dir, "broken.zip")
+	if err := os.WriteFile(broken, []byte("not an archive"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := OpenZip(broken); err == nil {
+		t.Error("Expected an error for a file that is not an archive")
+	}
+}
+
+func TestDLCArchives(t *testing.T) {
+	gameDir := t.TempDir()
+	if archives, err := DLCArchives(gameDir); err != nil || len(archives) != 0 {
+		t.Errorf("Expected no archives without a dlc directory, got %v (%v)", archives, err)
+	}
+
+	for _, name := range []string{"dlc002_b/dlc002.zip", "dlc001_a/dlc001.ZIP", "dlc001_a/readme.txt"} {
+		path := filepath.Join(gameDir, DLCDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeZip(t, path, map[string]string{"gfx/icon.dds": "icon"})
+	}
+	if err := os.WriteFile(filepath.Join(gameDir, DLCDir, "loose.zip"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	archives, err := DLCArchives(gameDir)
+	if err != nil {
+		t.Fatalf("Failed to list archives: %v", err)
+	}
+	expected := []string{
+		filepath.Join(gameDir, DLCDir, "dlc001_a", "dlc001.ZIP"),
+		filepath.Join(gameDir, DLCDir, "dlc002_b", "dlc002.zip"),
+	}
+	if len(archives) != len(expected) || archives[0] != expected[0] || archives[1] != expected[1] {
+		t.Errorf("Expected %v, got %v", expected, archives)
+	}
+}
+
+func TestOpenDescriptor(t *testing.T) {
+	userDir := t.TempDir()
+	writeZip(t, filepath.Join(userDir, "workshop.zip"), map[string]string{
+		"common/technology/a.txt": "tech_a = {}",
+	})
+	if err := os.MkdirAll(filepath.Join(userDir, "mod", "local", "common"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	descriptors := map[string]string{
+		"ugc_1.mod": "name=\"Workshop\"\narchive=\"" + filepath.ToSlash(filepath.Join(userDir, "workshop.zip")) + "\"\n",
+		"local.mod": "name=\"Local\"\npath=\"mod/local\"\n",
+		"empty.mod": "name=\"Empty\"\n",
+	}
+	for name, content := range descriptors {
+		if err := os.WriteFile(filepath.Join(userDir, "mod", name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	workshop, err := Open(filepath.Join(userDir, "mod", "ugc_1.mod"))
+	if err != nil {
+		t.Fatalf("Failed to open the archive descriptor: %v", err)
+	}
+	defer workshop.Close()
+	if _, err := fs.Stat(workshop, "common/technology/a.txt"); err != nil {
+		t.Errorf("Expected the archive's files: %v", err)
+	}
+
+	local, err := Open(filepath.Join(userDir, "mod", "local.mod"))
+	if err != nil {
+		t.Fatalf("Failed to open the directory descriptor: %v", err)
+	}
+	defer local.Close()
+	if info, err := fs.Stat(local, "common"); err != nil || !info.IsDir() {
+		t.Errorf("Expected the directory relative to the user directory: %v", err)
+	}
+
+	if _, err := Open(filepath.Join(userDir, "mod", "empty.mod")); err == nil {
+		t.Error("Expected an error for a descriptor without path or archive")
+	}
+}
+
+func TestName(t *testing.T) {
+	tests := map[string]string{
+		filepath.Join("mods", "my_mod"):      "my_mod",
+		filepath.Join("mods", "my_mod.zip"):  "my_mod",
+		filepath.Join("mods", "ugc_123.MOD"): "ugc_123",
+		filepath.Join("mods", "v1.2"):        "v1.2",
+	}
+	for path, expected := range tests {
+		if name := Name(path); name != expected {
+			t.Errorf("Name(%q) = %q, want %q", path, name, expected)
+		}
+	}
+}
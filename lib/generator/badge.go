@@ -0,0 +1,190 @@
+package generator
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"strconv"
+
+	"github.com/danaketh/StellarisDataParser/lib/manifest"
+)
+
+// badgeSuffix is appended to the icon name of repeatable icon variants,
+// followed by the level count or "inf"
+const badgeSuffix = "_repeatable_"
+
+// badgeGlyphs is a minimal bitmap font for badge labels. Labels only use
+// digits and the infinity sign, so they read the same in every language.
+var badgeGlyphs = map[rune][]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'∞': {".....", ".#.#.", "#.#.#", ".#.#.", "....."},
+}
+
+var (
+	badgeBackground = color.RGBA{R: 20, G: 20, B: 20, A: 220}
+	badgeForeground = color.RGBA{R: 255, G: 204, B: 0, A: 255}
+)
+
+// BadgeLabel returns the badge text for a repeatable technology: the
+// infinity sign for unlimited repeatables, including those that don't set
+// levels, otherwise the number of levels
+func BadgeLabel(levels int) string {
+	if levels <= 0 {
+		return "∞"
+	}
+	return strconv.Itoa(levels)
+}
+
+// BadgeIconName returns the icon name of the badge variant of an icon for a
+// repeatable technology with the given number of levels
+func BadgeIconName(iconName string, levels int) string {
+	if levels <= 0 {
+		return iconName + badgeSuffix + "inf"
+	}
+	return iconName + badgeSuffix + strconv.Itoa(levels)
+}
+
+// RenderBadgeVariant reads an already converted icon and writes a copy with
+// the level badge of a repeatable technology drawn in the bottom right corner
+func (ic *IconConverter) RenderBadgeVariant(iconName string, levels int) error {
+	outputPath := ic.iconOutputPath(BadgeIconName(iconName, levels))
+	if ic.manifest != nil {
+		// A badge only changes when its base icon or its label does
+		if base, ok := ic.manifest.Files[manifestName(ic.outputDir, ic.iconOutputPath(iconName))]; ok {
+			if ic.unchangedVariants(outputPath, manifest.Fingerprint([]byte(base), []byte(BadgeLabel(levels)))) {
+				ic.skipped++
+				return nil
+			}
+		}
+	}
+
+	img, err := ic.loadIcon(iconName)
+	if err != nil {
+		return err
+	}
+
+	badged := drawBadge(img, BadgeLabel(levels))
+	if err := ic.encodeFile(badged, outputPath); err != nil {
+		return fmt.Errorf("failed to write badge icon: %w", err)
+	}
+	for i, path := range ic.variantPaths(outputPath) {
+		if err := ic.encodeFile(downscale(badged, ic.encoding.Sizes[i]), path); err != nil {
+			return fmt.Errorf("failed to write badge icon: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// loadIcon decodes the converted icon, falling back to the game files when
+// the conversion was skipped because the icon was unchanged
+func (ic *IconConverter) loadIcon(iconName string) (image.Image, error) {
+	source := osFile(ic.iconOutputPath(iconName))
+	if _, err := os.Stat(source.path); err != nil {
+		var found bool
+		if source, found = ic.sourceFile(iconName); !found {
+			return nil, fmt.Errorf("failed to open icon: %w", err)
+		}
+	}
+
+	file, err := source.open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open icon: %w", err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode icon: %w", err)
+	}
+	return img, nil
+}
+
+// drawBadge returns a copy of img with label drawn in a filled box in the
+// bottom right corner. The glyph size scales with the icon size.
+func drawBadge(img image.Image, label string) *image.RGBA {
+	bounds := img.Bounds()
+	result := image.NewRGBA(bounds)
+	draw.Draw(result, bounds, img, bounds.Min, draw.Src)
+
+	scale := bounds.Dy() / 26
+	if scale < 1 {
+		scale = 1
+	}
+	padding := scale
+
+	textWidth, glyphHeight := labelSize(label, scale)
+	if textWidth == 0 {
+		return result
+	}
+
+	box := image.Rect(
+		bounds.Max.X-textWidth-2*padding,
+		bounds.Max.Y-glyphHeight-2*padding,
+		bounds.Max.X,
+		bounds.Max.Y,
+	)
+	draw.Draw(result, box, &image.Uniform{C: badgeBackground}, image.Point{}, draw.Over)
+	drawLabel(result, label, box.Min.X+padding, box.Min.Y+padding, scale, badgeForeground)
+
+	return result
+}
+
+// glyph returns the bitmap of a label character: the badge glyphs and the
+// capital letters of placeholders
+func glyph(r rune) ([]string, bool) {
+	if g, ok := badgeGlyphs[r]; ok {
+		return g, true
+	}
+	g, ok := letterGlyphs[r]
+	return g, ok
+}
+
+// labelSize returns the size of a label drawn with glyph pixels of
+// scale×scale. Characters without a glyph are left out.
+func labelSize(label string, scale int) (width, height int) {
+	runes := []rune(label)
+	for i, r := range runes {
+		g, ok := glyph(r)
+		if !ok {
+			continue
+		}
+		width += len(g[0]) * scale
+		if i < len(runes)-1 {
+			width += scale // Spacing between glyphs
+		}
+		height = max(height, len(g)*scale)
+	}
+	return width, height
+}
+
+// drawLabel draws a label with its top left corner at x, y
+func drawLabel(dst draw.Image, label string, x, y, scale int, c color.Color) {
+	for _, r := range label {
+		g, ok := glyph(r)
+		if !ok {
+			continue
+		}
+		for row, line := range g {
+			for col, pixel := range line {
+				if pixel != '#' {
+					continue
+				}
+				cell := image.Rect(x+col*scale, y+row*scale, x+(col+1)*scale, y+(row+1)*scale)
+				draw.Draw(dst, cell, &image.Uniform{C: c}, image.Point{}, draw.Src)
+			}
+		}
+		x += (len(g[0]) + 1) * scale
+	}
+}
@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBadgeLabel(t *testing.T) {
+	if label := BadgeLabel(-1); label != "∞" {
+		t.Errorf("Expected infinity label, got %q", label)
+	}
+	if label := BadgeLabel(0); label != "∞" {
+		t.Errorf("Expected infinity label without levels, got %q", label)
+	}
+	if label := BadgeLabel(5); label != "5" {
+		t.Errorf("Expected label 5, got %q", label)
+	}
+}
+
+func TestBadgeIconName(t *testing.T) {
+	if name := BadgeIconName("tech_lasers", -1); name != "tech_lasers_repeatable_inf" {
+		t.Errorf("Unexpected infinite badge name: %s", name)
+	}
+	if name := BadgeIconName("tech_lasers", 0); name != "tech_lasers_repeatable_inf" {
+		t.Errorf("Unexpected badge name without levels: %s", name)
+	}
+	if name := BadgeIconName("tech_lasers", 3); name != "tech_lasers_repeatable_3" {
+		t.Errorf("Unexpected finite badge name: %s", name)
+	}
+}
+
+func TestDrawBadge(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 52, 52))
+
+	result := drawBadge(src, "∞")
+	if result.Bounds() != src.Bounds() {
+		t.Fatalf("Expected bounds %v, got %v", src.Bounds(), result.Bounds())
+	}
+
+	// The bottom right corner is covered by the badge background
+	if c := result.RGBAAt(51, 51); c.A == 0 {
+		t.Error("Expected badge background in the bottom right corner")
+	}
+
+	// The top left corner is untouched
+	if c := result.RGBAAt(0, 0); c != (color.RGBA{}) {
+		t.Errorf("Expected top left corner untouched, got %v", c)
+	}
+
+	// Unknown glyphs produce no badge
+	plain := drawBadge(src, "?")
+	if c := plain.RGBAAt(51, 51); c.A != 0 {
+		t.Error("Expected no badge for label without known glyphs")
+	}
+}
+
+func TestRenderBadgeVariant(t *testing.T) {
+	outputDir := t.TempDir()
+	converter := NewIconConverter(t.TempDir(), outputDir)
+
+	iconsDir := filepath.Join(outputDir, "icons")
+	if err := os.MkdirAll(iconsDir, 0755); err != nil {
+		t.Fatalf("Failed to create icons dir: %v", err)
+	}
+	file, err := os.Create(filepath.Join(iconsDir, "tech_test.png"))
+	if err != nil {
+		t.Fatalf("Failed to create icon: %v", err)
+	}
+	if err := png.Encode(file, image.NewRGBA(image.Rect(0, 0, 52, 52))); err != nil {
+		t.Fatalf("Failed to encode icon: %v", err)
+	}
+	file.Close()
+
+	if err := converter.RenderBadgeVariant("tech_test", -1); err != nil {
+		t.Fatalf("Failed to render badge variant: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(iconsDir, "tech_test_repeatable_inf.png")); err != nil {
+		t.Errorf("Expected badge variant to be written: %v", err)
+	}
+
+	if err := converter.RenderBadgeVariant("tech_missing", 2); err == nil {
+		t.Error("Expected error for missing base icon")
+	}
+}
@@ -0,0 +1,95 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/danaketh/StellarisDataParser/lib/config"
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// Game data domains besides technologies, written to their own files by the
+// content types selected with -content
+const (
+	DomainEdicts      = "edicts"
+	DomainPolicies    = "policies"
+	DomainShips       = "ships"
+	DomainDistricts   = "districts"
+	DomainPlanets     = "planets"
+	DomainRelics      = "relics"
+	DomainArchaeology = "archaeology"
+	DomainEvents      = "events"
+	DomainAnomalies   = "anomalies"
+	DomainDefines     = "defines"
+	DomainLeaders     = "leaders"
+	DomainEspionage   = "espionage"
+	DomainSituations  = "situations"
+	DomainDiplomacy   = "diplomacy"
+)
+
+// SetDomain sets the definitions written to a domain's file. A list of
+// definitions is written under the domain name; a map, for domains made of
+// several kinds of definitions, is written as the file itself.
+func (g *JSONGenerator) SetDomain(domain string, definitions interface{}) {
+	if g.domains == nil {
+		g.domains = make(map[string]interface{})
+	}
+	g.domains[domain] = definitions
+}
+
+// DomainFileName returns the file name of a domain's file
+func (g *JSONGenerator) DomainFileName(domain string) string {
+	return g.dataFileName(strings.ReplaceAll(g.output.DomainFile, config.DomainPlaceholder, domain))
+}
+
+// RelicIconFile returns the path of a relic's extracted art, relative to the
+// icons directory
+func RelicIconFile(key string) string {
+	return path.Join(RelicIconsOutputDir, key+".png")
+}
+
+// convertRelicIcons extracts the art of the relics set with SetDomain
+func (g *JSONGenerator) convertRelicIcons(converter *IconConverter) {
+	relics, _ := g.domains[DomainRelics].([]*models.Relic)
+	icons := make(map[string]string)
+	for _, relic := range relics {
+		if relic.Portrait != "" {
+			icons[relic.Key] = relic.Portrait
+		}
+	}
+	if len(icons) == 0 {
+		return
+	}
+
+	converted, err := converter.ConvertRelicIcons(icons)
+	if err != nil {
+		g.logger.Warn("Some relic icons could not be converted", "error", err)
+	}
+	g.logger.Info("Extracted relic icons", "count", converted, "relics", len(icons))
+}
+
+// writeDomains writes the file of each domain set with SetDomain
+func (g *JSONGenerator) writeDomains(outputDir string) error {
+	domains := make([]string, 0, len(g.domains))
+	for domain := range g.domains {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	for _, domain := range domains {
+		path, err := prepareOutputPath(outputDir, g.DomainFileName(domain))
+		if err != nil {
+			return fmt.Errorf("failed to create %s directory: %w", domain, err)
+		}
+		content, isMap := g.domains[domain].(map[string]interface{})
+		if !isMap {
+			content = map[string]interface{}{domain: g.domains[domain]}
+		}
+		if err := g.writeJSONFile(path, content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", domain, err)
+		}
+	}
+	return nil
+}
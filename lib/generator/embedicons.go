@@ -0,0 +1,159 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"path"
+	"strings"
+)
+
+// DefaultEmbedIconMaxBytes is the size limit of embedded icons unless
+// configured otherwise, enough for the game's technology icons
+const DefaultEmbedIconMaxBytes = 32 * 1024
+
+// IconEmbedding controls embedding technology icons in the technology data
+// as data URIs
+type IconEmbedding struct {
+	MaxSize  int // Icons wider or taller than this many pixels are downscaled, 0 keeps their size
+	MaxBytes int // Icons larger than this many bytes after downscaling are not embedded, 0 for no limit
+}
+
+// SetEmbedIcons enables embedding each technology's icon in the technology
+// data as iconData. Icons are looked up like ConvertIcons does, so the game
+// directory, mods and icon overrides must be set first. nil disables
+// embedding.
+func (g *JSONGenerator) SetEmbedIcons(embedding *IconEmbedding) {
+	g.embedIcons = embedding
+	g.iconData = nil
+}
+
+// embeddedIcons returns the data URI of the icon of each exported
+// technology, by icon name, computing them on first use. Icons that are
+// missing, can't be decoded or are too large have none.
+func (g *JSONGenerator) embeddedIcons() map[string]string {
+	if g.iconData != nil || g.embedIcons == nil || g.gameDir == "" {
+		return g.iconData
+	}
+	g.iconData = make(map[string]string)
+
+	converter, closeMods, err := g.newIconConverter(g.outputDir)
+	if err != nil {
+		g.logger.Warn("Icons not embedded", "error", err)
+		return g.iconData
+	}
+	defer closeMods()
+
+	// A filter error is reported when the files are generated
+	nodes, err := g.exportedNodes(context.Background())
+	if err != nil {
+		g.logger.Warn("Icons not embedded", "error", err)
+		return g.iconData
+	}
+	errors := []string{}
+	for _, node := range nodes {
+		icon, override := g.resolveIcon(node.Tech)
+		if _, done := g.iconData[icon]; done {
+			continue
+		}
+		source, found := osFile(override), true
+		if override == "" {
+			source, found = converter.sourceFile(icon)
+		}
+		if !found {
+			g.iconData[icon] = ""
+			continue
+		}
+		uri, err := g.iconDataURI(source)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", icon, err))
+		}
+		g.iconData[icon] = uri
+	}
+	if len(errors) > 0 {
+		g.logger.Warn("Some icons were not embedded", "count", len(errors), "error", strings.Join(errors, "\n"))
+	}
+	return g.iconData
+}
+
+// iconDataURI reads, downscales and encodes an icon as a data URI
+func (g *JSONGenerator) iconDataURI(source iconFile) (string, error) {
+	file, err := source.open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	var content []byte
+	mediaType := "image/png"
+	if strings.EqualFold(path.Ext(source.name), ".svg") {
+		// SVG icons are embedded as they are
+		mediaType = "image/svg+xml"
+		if content, err = io.ReadAll(file); err != nil {
+			return "", err
+		}
+	} else {
+		img, format, err := image.Decode(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode image (format: %s): %w", format, err)
+		}
+		if g.embedIcons.MaxSize > 0 {
+			img = downscale(img, g.embedIcons.MaxSize)
+		}
+		var encoded bytes.Buffer
+		if err := png.Encode(&encoded, img); err != nil {
+			return "", fmt.Errorf("failed to encode PNG: %w", err)
+		}
+		content = encoded.Bytes()
+	}
+
+	if g.embedIcons.MaxBytes > 0 && len(content) > g.embedIcons.MaxBytes {
+		return "", fmt.Errorf("%d bytes exceed the limit of %d", len(content), g.embedIcons.MaxBytes)
+	}
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(content), nil
+}
+
+// downscale shrinks an image to fit in maxSize×maxSize pixels, keeping its
+// aspect ratio, by averaging the source pixels each pixel covers. Smaller
+// images are returned unchanged.
+func downscale(img image.Image, maxSize int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSize && height <= maxSize {
+		return img
+	}
+	scaledWidth, scaledHeight := maxSize, maxSize
+	if width > height {
+		scaledHeight = max(1, height*maxSize/width)
+	} else {
+		scaledWidth = max(1, width*maxSize/height)
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, scaledWidth, scaledHeight))
+	for y := 0; y < scaledHeight; y++ {
+		top, bottom := bounds.Min.Y+y*height/scaledHeight, bounds.Min.Y+(y+1)*height/scaledHeight
+		for x := 0; x < scaledWidth; x++ {
+			left, right := bounds.Min.X+x*width/scaledWidth, bounds.Min.X+(x+1)*width/scaledWidth
+			var r, g, b, a, count uint64
+			for sy := top; sy < bottom; sy++ {
+				for sx := left; sx < right; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					count++
+				}
+			}
+			scaled.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(r / count),
+				G: uint16(g / count),
+				B: uint16(b / count),
+				A: uint16(a / count),
+			})
+		}
+	}
+	return scaled
+}
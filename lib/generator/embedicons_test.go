@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+func TestDownscale(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 4; y++ {
+			if x%2 == 0 {
+				src.Set(x, y, color.White)
+			} else {
+				src.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	scaled := downscale(src, 4)
+	if size := scaled.Bounds().Size(); size != image.Pt(4, 2) {
+		t.Fatalf("Expected 4x2 keeping the aspect ratio, got %v", size)
+	}
+	if r, _, _, a := scaled.At(0, 0).RGBA(); r < 0x7000 || r > 0x9000 || a != 0xffff {
+		t.Errorf("Expected each pixel to average the pixels it covers, got %v", scaled.At(0, 0))
+	}
+	if downscale(src, 8) != image.Image(src) {
+		t.Error("Expected images that fit to be returned unchanged")
+	}
+}
+
+func TestEmbedIcons(t *testing.T) {
+	gameDir := t.TempDir()
+	iconPath := filepath.Join(gameDir, "gfx", "interface", "icons", "technologies", "tech_icon.png")
+	if err := os.MkdirAll(filepath.Dir(iconPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	iconFile, err := os.Create(iconPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(iconFile, image.NewRGBA(image.Rect(0, 0, 52, 52))); err != nil {
+		t.Fatal(err)
+	}
+	iconFile.Close()
+
+	overridesDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(overridesDir, "tech_svg.svg"), []byte("<svg/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	techTree := tree.NewTechTree(map[string]*models.Technology{
+		"tech_png":     {Key: "tech_png", Area: "physics", Icon: "tech_icon"},
+		"tech_svg":     {Key: "tech_svg", Area: "physics", Icon: "tech_icon"},
+		"tech_missing": {Key: "tech_missing", Area: "physics", Icon: "tech_missing"},
+	})
+	technology := func(g *JSONGenerator, key string) TechnologyJSON {
+		node, _ := techTree.GetNode(key)
+		return g.Technology(node)
+	}
+
+	g := NewJSONGenerator(techTree)
+	g.SetGameDir(gameDir)
+	g.SetIconOverrides(overridesDir)
+	if data := technology(g, "tech_png").IconData; data != "" {
+		t.Fatalf("Expected no icon data without embedding, got %q", data)
+	}
+
+	g.SetEmbedIcons(&IconEmbedding{MaxSize: 16})
+	data := technology(g, "tech_png").IconData
+	encoded, found := strings.CutPrefix(data, "data:image/png;base64,")
+	if !found {
+		t.Fatalf("Expected a PNG data URI, got %q", data)
+	}
+	content, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size != image.Pt(16, 16) {
+		t.Errorf("Expected the icon downscaled to 16x16, got %v", size)
+	}
+
+	expected := "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte("<svg/>"))
+	if data := technology(g, "tech_svg").IconData; data != expected {
+		t.Errorf("Expected the SVG override as it is, got %q", data)
+	}
+	if data := technology(g, "tech_missing").IconData; data != "" {
+		t.Errorf("Expected no icon data for a missing icon, got %q", data)
+	}
+
+	g.SetEmbedIcons(&IconEmbedding{MaxBytes: 10})
+	if data := technology(g, "tech_png").IconData; data != "" {
+		t.Errorf("Expected icons over the limit to be left out, got %q", data)
+	}
+}
@@ -1,27 +1,420 @@
+// Package generator writes the JSON data files, TypeScript declarations and
+// PNG icons of a technology tree, and the files of other content types handed
+// to it with SetDomain.
 package generator
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
+	"text/template"
+	"time"
 
-	"stellaris-data-parser/lib/tree"
+	"github.com/danaketh/StellarisDataParser/lib/config"
+	"github.com/danaketh/StellarisDataParser/lib/filter"
+	"github.com/danaketh/StellarisDataParser/lib/gamefs"
+	"github.com/danaketh/StellarisDataParser/lib/layout"
+	"github.com/danaketh/StellarisDataParser/lib/localization"
+	"github.com/danaketh/StellarisDataParser/lib/manifest"
+	"github.com/danaketh/StellarisDataParser/lib/mechanics"
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/progress"
+	"github.com/danaketh/StellarisDataParser/lib/timeline"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
 )
 
+// ErrNoGameDir is returned by ConvertIcons when no game directory was set
+// with SetGameDir
+var ErrNoGameDir = errors.New("game directory not set")
+
 // JSONGenerator generates JSON data files and icons for Docusaurus
 type JSONGenerator struct {
-	tree    *tree.TechTree
-	gameDir string // Game directory for finding icons
+	tree             *tree.TechTree
+	gameDir          string   // Game directory for finding icons
+	mods             []string // Mods whose icons replace those of the game, in load order
+	repeatableLevels int      // Number of levels exported in repeatable cost tables
+	output           config.OutputConfig
+	overrides        []models.Override  // Technologies replaced by later definitions
+	filter           *filter.Expression // Only technologies matching the filter are exported
+	subtrees         map[string]bool    // Only these technologies are exported, if set
+	files            []string           // Paths of the files written by the last run
+	repeatableBadges bool               // Render level badges onto repeatable technology icons
+	timeline         timeline.Assumptions
+	estimatedYears   map[string]int     // Estimated year each technology is reachable
+	since            *manifest.Manifest // Files unchanged since this manifest are not written
+	manifest         *manifest.Manifest // Fingerprints of the files of the last run
+	generatedAt      time.Time          // Time written files are recorded at in the manifest, now if zero
+	inputs           *manifest.Inputs   // Recorded in the manifest, if set
+	outputDir        string
+	skipped          []string // Files skipped by the last run because they were unchanged
+	progress         *progress.Reporter
+	logger           *slog.Logger
+	totalFiles       int    // Number of JSON files the current run writes, for progress events
+	full             bool   // Export every parsed field, including extraFlags
+	rawSource        bool   // Export the script text of each technology as raw
+	minify           bool   // Write JSON files without indentation
+	format           string // Format of the data files: FormatJSON, or FormatYAML
+	gzip             bool   // Write a gzip-compressed copy of each JSON file
+	colorMode        string // How §X...§! color markup in names and descriptions is written
+	iconTokenMode    string // How £name£ icon references in names and descriptions are written
+	commandMode      string // How [Scope.Command] scripting commands in names and descriptions are written
+	placeholders     map[string]string
+	icons            config.IconsConfig
+	sharedIcons      []IconUsage // Icons shared by several technologies in the last run
+	iconStats        IconStats   // Technology icons of the last run
+	iconOverrides    string      // Directory of PNG/SVG icons replacing the game icons
+	skipIcons        bool        // Generate leaves icons untouched
+	iconEncoding     IconEncoding
+	coverage         []localization.LanguageCoverage
+	categories       map[string]*models.Category // Research category definitions, by key
+	gameVersion      *models.GameVersion         // Version of the game the data was read from, if known
+	areaNames        map[string]string           // Localized research area names, by key
+	mechanics        *mechanics.Mechanics
+	order            map[string]int // Index of each technology in the research order
+	areaOrder        map[string]int // Index of each technology in its area's research order
+	subgraphs        bool           // Write a subgraph file for each exported technology
+	cumulativeCosts  map[string]tree.CumulativeCost
+	positions        map[string]layout.Position
+	graph            bool                   // Write the nodes and links graph file
+	domains          map[string]interface{} // Definitions of other game data domains, by domain
+	alternatives     int                    // Research alternatives offered per area
+	offerChances     map[string]float64     // Chance of each technology to be offered
+	templates        []*template.Template   // User templates rendered with the exported technologies
+	embedIcons       *IconEmbedding         // Embed icons as data URIs, if set
+	iconData         map[string]string      // Data URI of each embedded icon, by icon name
+	html             bool                   // Write the tech tree viewer
+	searchIndex      bool                   // Write the search index
+	sidebars         bool                   // Write the Docusaurus sidebars file
+	iconPlaceholders bool                   // Write placeholders for icons that can't be converted
+	missingIcons     []MissingIcon          // Technology icons that couldn't be converted in the last run
 }
 
+// DefaultRepeatableLevels is the number of levels included in the cost table
+// of infinite repeatable technologies unless configured otherwise
+const DefaultRepeatableLevels = 10
+
+// DefaultResearchAlternatives is the number of research alternatives offered
+// per area unless the game's defines set another
+const DefaultResearchAlternatives = 3
+
 // NewJSONGenerator creates a new JSON generator
 func NewJSONGenerator(techTree *tree.TechTree) *JSONGenerator {
 	return &JSONGenerator{
-		tree: techTree,
+		tree:             techTree,
+		repeatableLevels: DefaultRepeatableLevels,
+		output:           config.Default().Output,
+		timeline:         timeline.DefaultAssumptions(),
+		alternatives:     DefaultResearchAlternatives,
+		colorMode:        localization.ColorStrip,
+		iconTokenMode:    localization.IconTokenRaw,
+		commandMode:      localization.CommandStrip,
+		placeholders:     localization.DefaultCommandPlaceholders(),
+		icons:            config.Default().Icons,
+		logger:           slog.New(slog.DiscardHandler),
+	}
+}
+
+// SetOutputConfig sets the file name templates used for generated files
+func (g *JSONGenerator) SetOutputConfig(output config.OutputConfig) {
+	g.output = output
+}
+
+// SetIconsConfig sets the directories and extensions icons are looked up in
+func (g *JSONGenerator) SetIconsConfig(icons config.IconsConfig) {
+	g.icons = icons
+}
+
+// SetIconOverrides sets the directory of PNG or SVG icons, named after a
+// technology key or icon name, that replace the icons from the game files
+func (g *JSONGenerator) SetIconOverrides(dir string) {
+	g.iconOverrides = dir
+}
+
+// SetOverrides sets the override records written to the overrides report
+func (g *JSONGenerator) SetOverrides(overrides []models.Override) {
+	g.overrides = overrides
+}
+
+// SetLocalizationCoverage sets the missing localization keys written to the
+// coverage report. The report is only written when coverage is set.
+func (g *JSONGenerator) SetLocalizationCoverage(coverage []localization.LanguageCoverage) {
+	g.coverage = coverage
+}
+
+// SetMechanics sets the research mechanics written to the mechanics file.
+// The file is only written when mechanics are set.
+func (g *JSONGenerator) SetMechanics(m *mechanics.Mechanics) {
+	g.mechanics = m
+}
+
+// SetRepeatableBadges enables rendering a level badge onto a copy of each
+// repeatable technology's icon
+func (g *JSONGenerator) SetRepeatableBadges(enabled bool) {
+	g.repeatableBadges = enabled
+}
+
+// SetTimeline sets the research speed assumptions used to estimate the year
+// each technology is reachable
+func (g *JSONGenerator) SetTimeline(assumptions timeline.Assumptions) {
+	g.timeline = assumptions
+	g.estimatedYears = nil
+}
+
+// SetResearchAlternatives sets the number of research alternatives offered
+// per area, used for the chance of each technology to be offered
+func (g *JSONGenerator) SetResearchAlternatives(alternatives int) {
+	g.alternatives = alternatives
+	g.offerChances = nil
+}
+
+// SetSince makes the generator skip files whose inputs have not changed since
+// the given manifest was written. A nil manifest writes every file.
+func (g *JSONGenerator) SetSince(since *manifest.Manifest) {
+	g.since = since
+}
+
+// SetGeneratedAt sets the time the files written by Generate are recorded
+// at in the manifest, for reproducible output. The zero time uses the time
+// of the run.
+func (g *JSONGenerator) SetGeneratedAt(generatedAt time.Time) {
+	g.generatedAt = generatedAt
+}
+
+// SetInputs sets the inputs the data was read from, recorded in the manifest
+// written by Generate, so a later run can tell whether anything changed
+func (g *JSONGenerator) SetInputs(inputs *manifest.Inputs) {
+	g.inputs = inputs
+}
+
+// SetProgress sets the reporter that receives an event for every written
+// file and converted icon
+func (g *JSONGenerator) SetProgress(reporter *progress.Reporter) {
+	g.progress = reporter
+}
+
+// SetLogger sets the logger that receives the icon conversion summaries,
+// warnings for icons that can't be converted and a debug record for every
+// written file. Nothing is logged by default; nil restores the default.
+func (g *JSONGenerator) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+	g.logger = logger
+}
+
+// SetIconEncoding sets how converted icons are encoded and the size
+// variants written along with them
+func (g *JSONGenerator) SetIconEncoding(encoding IconEncoding) {
+	g.iconEncoding = encoding
+}
+
+// SetFull enables exporting every parsed field of each technology, including
+// keys the parser does not model in extraFlags
+func (g *JSONGenerator) SetFull(enabled bool) {
+	g.full = enabled
+}
+
+// SetRawSource enables exporting the original script text and line span of
+// each technology as raw. The parser must keep them, see
+// parser.TechParser.SetKeepSource; technologies without a Source have no
+// raw field.
+func (g *JSONGenerator) SetRawSource(enabled bool) {
+	g.rawSource = enabled
+}
+
+// SetMinify enables writing JSON files without indentation or line breaks
+func (g *JSONGenerator) SetMinify(enabled bool) {
+	g.minify = enabled
+}
+
+// SetFormat sets the format of the data files, FormatJSON (the default) or
+// FormatYAML. YAML files have the same structure as the JSON files and end in
+// .yaml instead of .json. The manifest and the TypeScript declarations are
+// written as usual.
+func (g *JSONGenerator) SetFormat(format string) {
+	g.format = format
+}
+
+// SetGzip enables writing a gzip-compressed <name>.json.gz next to each JSON
+// file, for servers that serve precompressed files
+func (g *JSONGenerator) SetGzip(enabled bool) {
+	g.gzip = enabled
+}
+
+// SetColorMode sets how color markup in names and descriptions is written,
+// one of localization.ColorModes
+func (g *JSONGenerator) SetColorMode(mode string) {
+	g.colorMode = mode
+}
+
+// SetIconTokenMode sets how icon references in names and descriptions are
+// written, one of localization.IconTokenModes. In token and html mode the
+// referenced resource icons are extracted along with the technology icons.
+func (g *JSONGenerator) SetIconTokenMode(mode string) {
+	g.iconTokenMode = mode
+}
+
+// SetCommandMode sets how scripting commands in names and descriptions are
+// written, one of localization.CommandModes, and the placeholders used in
+// placeholder mode
+func (g *JSONGenerator) SetCommandMode(mode string, placeholders map[string]string) {
+	g.commandMode = mode
+	g.placeholders = placeholders
+}
+
+// formatText applies the color, command and icon token modes to a localized
+// string. Colors go first so commands they wrap are stripped cleanly and the
+// <img> elements of html mode are not escaped.
+func (g *JSONGenerator) formatText(text string) string {
+	text = localization.FormatColors(text, g.colorMode)
+	text = localization.FormatCommands(text, g.commandMode, g.placeholders)
+	return localization.FormatIconTokens(text, g.iconTokenMode, path.Join(filepath.ToSlash(g.output.IconsDir), ResourceIconsOutputDir))
+}
+
+// extractsResourceIcons reports whether resource icons referenced from
+// localized strings are converted
+func (g *JSONGenerator) extractsResourceIcons() bool {
+	return g.iconTokenMode == localization.IconTokenToken || g.iconTokenMode == localization.IconTokenHTML
+}
+
+// SetFilter restricts the exported technologies to those matching expr.
+// A nil expression exports every technology.
+func (g *JSONGenerator) SetFilter(expr *filter.Expression) {
+	g.filter = expr
+	g.iconData = nil
+}
+
+// SetSubtrees restricts the exported technologies to the given technologies
+// and every technology depending on them, directly or through other
+// prerequisites. Unknown keys are ignored; no keys export every technology.
+// The filter set with SetFilter applies as well.
+func (g *JSONGenerator) SetSubtrees(keys []string) {
+	g.iconData = nil
+	if len(keys) == 0 {
+		g.subtrees = nil
+		return
+	}
+	g.subtrees = make(map[string]bool)
+	for _, key := range keys {
+		descendants, exists := g.tree.Descendants(key)
+		if !exists {
+			continue
+		}
+		g.subtrees[key] = true
+		for _, node := range descendants {
+			g.subtrees[node.Tech.Key] = true
+		}
+	}
+}
+
+// exportedNodes returns the nodes of the technologies that are in the
+// subtrees and pass the configured filter, in key order, so the first filter
+// error is the same on every run
+func (g *JSONGenerator) exportedNodes(ctx context.Context) ([]*tree.TechNode, error) {
+	allNodes := g.tree.GetSortedNodes()
+	nodes := make([]*tree.TechNode, 0, len(allNodes))
+	for _, node := range allNodes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if ok, err := g.matches(node); err != nil {
+			return nil, fmt.Errorf("failed to evaluate filter for %s: %w", node.Tech.Key, err)
+		} else if ok {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+// matches reports whether a node is in the subtrees and passes the
+// configured filter
+func (g *JSONGenerator) matches(node *tree.TechNode) (bool, error) {
+	if g.subtrees != nil && !g.subtrees[node.Tech.Key] {
+		return false, nil
+	}
+	if g.filter == nil {
+		return true, nil
+	}
+	return g.filter.Match(g.FilterFields(node))
+}
+
+// ResearchFileName returns the file name of the technology file for an area
+func (g *JSONGenerator) ResearchFileName(area string) string {
+	return g.dataFileName(strings.ReplaceAll(g.output.ResearchFile, config.AreaPlaceholder, strings.ToLower(area)))
+}
+
+// MetadataFileName returns the file name of the metadata file
+func (g *JSONGenerator) MetadataFileName() string {
+	return g.dataFileName(g.output.MetadataFile)
+}
+
+// CoverageFileName returns the file name of the localization coverage report
+func (g *JSONGenerator) CoverageFileName() string {
+	return g.dataFileName(g.output.CoverageFile)
+}
+
+// MechanicsFileName returns the file name of the research mechanics file
+func (g *JSONGenerator) MechanicsFileName() string {
+	return g.dataFileName(g.output.MechanicsFile)
+}
+
+// OverridesFileName returns the file name of the overrides report
+func (g *JSONGenerator) OverridesFileName() string {
+	return g.dataFileName(g.output.OverridesFile)
+}
+
+// ManifestFileName returns the file name of the manifest
+func (g *JSONGenerator) ManifestFileName() string {
+	return g.output.ManifestFile
+}
+
+// TypesFileName returns the file name of the TypeScript declarations
+func (g *JSONGenerator) TypesFileName() string {
+	return g.output.TypesFile
+}
+
+// IconUsageFileName returns the file name of the icon usage report
+func (g *JSONGenerator) IconUsageFileName() string {
+	return g.dataFileName(g.output.IconUsageFile)
+}
+
+// dataFileName returns the name of a data file in the format set with
+// SetFormat, replacing the .json extension of name for YAML
+func (g *JSONGenerator) dataFileName(name string) string {
+	if g.format == FormatYAML && strings.HasSuffix(name, ".json") {
+		return strings.TrimSuffix(name, ".json") + ".yaml"
 	}
+	return name
+}
+
+// SharedIcons returns the icons shared by several exported technologies in
+// the last call to Generate or GenerateJSONFiles
+func (g *JSONGenerator) SharedIcons() []IconUsage {
+	return g.sharedIcons
+}
+
+// IconStats counts the technology icons of a run and how long converting
+// them took
+type IconStats struct {
+	Converted int           // Converted from the game files or copied from the overrides
+	Skipped   int           // Unchanged since the manifest set with SetSince
+	Failed    int           // Found but not converted
+	Duration  time.Duration // Time spent on all icons, including badges and resource icons
+}
+
+// IconStats returns the technology icons of the last call to Generate or
+// ConvertIcons
+func (g *JSONGenerator) IconStats() IconStats {
+	return g.iconStats
 }
 
 // SetGameDir sets the game directory path for icon extraction
@@ -29,126 +422,664 @@ func (g *JSONGenerator) SetGameDir(gameDir string) {
 	g.gameDir = gameDir
 }
 
+// SetMods sets the mods icons are also looked up in, in load order: mod
+// directories, .zip archives or .mod descriptors, see gamefs.Open
+func (g *JSONGenerator) SetMods(mods []string) {
+	g.mods = mods
+}
+
+// SetSkipIcons makes Generate skip the icon stage. The fingerprints of the
+// icons in the since manifest are carried over, so a later incremental run
+// still knows which icons are up to date.
+func (g *JSONGenerator) SetSkipIcons(skip bool) {
+	g.skipIcons = skip
+}
+
+// SetRepeatableLevels sets how many levels are exported in the cost table of
+// repeatable technologies
+func (g *JSONGenerator) SetRepeatableLevels(levels int) {
+	g.repeatableLevels = levels
+}
+
 // Generate creates JSON data files and converts icons
 func (g *JSONGenerator) Generate(outputPath string) error {
+	return g.GenerateContext(context.Background(), outputPath)
+}
+
+// GenerateContext is Generate with a context. When the context is
+// cancelled, generation stops after the file or icon being written and the
+// context's error is returned; the manifest is not written then, so the next
+// incremental run regenerates everything.
+func (g *JSONGenerator) GenerateContext(ctx context.Context, outputPath string) error {
 	// outputPath is now the output directory
 	outputDir := outputPath
 
 	// Generate separate JSON files
-	if err := g.GenerateJSONFiles(outputDir); err != nil {
+	if err := g.GenerateJSONFilesContext(ctx, outputDir); err != nil {
 		return fmt.Errorf("failed to generate JSON files: %w", err)
 	}
 
 	// Convert and copy icon files if game directory is set
-	if g.gameDir != "" {
-		if err := g.ConvertIcons(outputDir); err != nil {
+	if g.skipIcons {
+		g.keepIconFingerprints()
+	} else if g.gameDir != "" {
+		if err := g.ConvertIconsContext(ctx, outputDir); ctx.Err() != nil {
+			return ctx.Err()
+		} else if err != nil {
 			// Don't fail generation if icons can't be converted
 			// Just log a warning
-			fmt.Printf("⚠ Warning: Failed to convert some icons: %v\n", err)
+			g.logger.Warn("Failed to convert some icons", "error", err)
 		}
 	}
 
+	// Write the manifest last so it covers icons as well
+	generatedAt := g.generatedAt
+	if generatedAt.IsZero() {
+		generatedAt = time.Now()
+	}
+	if err := g.manifest.RecordOutputs(outputDir, g.since, generatedAt.UTC()); err != nil {
+		return fmt.Errorf("failed to checksum generated files: %w", err)
+	}
+	g.manifest.Inputs = g.inputs
+	manifestPath, err := prepareOutputPath(outputDir, g.ManifestFileName())
+	if err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+	if err := g.manifest.Save(manifestPath); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	g.files = append(g.files, manifestPath)
+
 	return nil
 }
 
+// keepIconFingerprints copies the fingerprints of the icons recorded in the
+// since manifest into the current one
+func (g *JSONGenerator) keepIconFingerprints() {
+	if g.since == nil {
+		return
+	}
+	prefix := filepath.ToSlash(filepath.Clean(g.output.IconsDir)) + "/"
+	for name, fingerprint := range g.since.Files {
+		if strings.HasPrefix(name, prefix) {
+			g.manifest.Set(name, fingerprint)
+		}
+	}
+}
+
 // GenerateJSONFiles creates separate JSON files for technologies by area
 func (g *JSONGenerator) GenerateJSONFiles(outputDir string) error {
+	return g.GenerateJSONFilesContext(context.Background(), outputDir)
+}
+
+// GenerateJSONFilesContext is GenerateJSONFiles with a context that stops
+// generation when it is cancelled
+func (g *JSONGenerator) GenerateJSONFilesContext(ctx context.Context, outputDir string) error {
+	g.files = nil
+	g.skipped = nil
+	g.outputDir = outputDir
+	g.manifest = manifest.New()
+	g.estimatedYears = nil
+
 	// Prepare all data
-	allNodes := g.tree.GetAllNodes()
-	techsByArea := make(map[string][]map[string]interface{})
-
-	// Process all technologies
-	for key, node := range allNodes {
-		// Prepare tech data with English localization
-		deps := make([]string, len(node.Dependencies))
-		for i, dep := range node.Dependencies {
-			deps[i] = dep.Tech.Key
-		}
-
-		// Use localized name if available, otherwise format from key
-		name := node.Tech.Name
-		if name == "" {
-			name = formatTechName(key)
-		}
-
-		techData := map[string]interface{}{
-			"key":           key,
-			"name":          name,
-			"description":   node.Tech.Description,
-			"cost":          node.Tech.Cost,
-			"area":          node.Tech.Area,
-			"tier":          node.Tech.Tier,
-			"level":         node.Level,
-			"category":      strings.Join(node.Tech.Category, ", "),
-			"prerequisites": deps,
-			"weight":        node.Tech.Weight,
-			"sourceFile":    node.Tech.SourceFile,
-			"icon":          node.Tech.Icon,
-			"isStartTech":   node.Tech.IsStartTech,
-			"isDangerous":   node.Tech.IsDangerous,
-			"isRare":        node.Tech.IsRare,
-			"isEvent":       node.Tech.IsEvent,
-			"isReverse":     node.Tech.IsReverse,
-			"isRepeatable":  node.Tech.IsRepeatable,
-			"levels":        node.Tech.Levels,
-			"isGestalt":     node.Tech.IsGestalt,
-			"isMegacorp":    node.Tech.IsMegacorp,
+	nodes, err := g.exportedNodes(ctx)
+	if err != nil {
+		return err
+	}
+	techsByArea := make(map[string][]TechnologyJSON)
+	exported := make([]*models.Technology, 0, len(nodes))
+
+	// Process the technologies in key order, so the progress events are the
+	// same on every run
+	for _, node := range nodes {
+		if err := ctx.Err(); err != nil {
+			return err
 		}
+		exported = append(exported, node.Tech)
 
 		// Group by area
 		area := node.Tech.Area
 		if area == "" {
 			area = "unknown"
 		}
-		techsByArea[area] = append(techsByArea[area], techData)
+		techsByArea[area] = append(techsByArea[area], g.Technology(node))
 	}
 
 	// Sort technologies within each area
 	for area := range techsByArea {
-		sort.Slice(techsByArea[area], func(i, j int) bool {
-			if techsByArea[area][i]["level"].(int) == techsByArea[area][j]["level"].(int) {
-				return techsByArea[area][i]["key"].(string) < techsByArea[area][j]["key"].(string)
+		techs := techsByArea[area]
+		sort.Slice(techs, func(i, j int) bool {
+			if techs[i].Level == techs[j].Level {
+				return techs[i].Key < techs[j].Key
 			}
-			return techsByArea[area][i]["level"].(int) < techsByArea[area][j]["level"].(int)
+			return techs[i].Level < techs[j].Level
 		})
 	}
 
-	// Write separate technology files for each area
-	for area, techs := range techsByArea {
-		techPath := filepath.Join(outputDir, fmt.Sprintf("research-%s.json", strings.ToLower(area)))
-		if err := g.writeJSONFile(techPath, map[string]interface{}{
-			"area":         area,
-			"technologies": techs,
-		}); err != nil {
+	// Per-area files, metadata, type declarations, icon usage and the optional
+	// overrides and coverage reports, mechanics, graph, subgraphs, domains,
+	// templates, the tech tree viewer and the search index
+	g.totalFiles = len(techsByArea) + 3 + len(g.domains)
+	if len(g.overrides) > 0 {
+		g.totalFiles++
+	}
+	if len(g.coverage) > 0 {
+		g.totalFiles++
+	}
+	if g.mechanics != nil {
+		g.totalFiles++
+	}
+	if g.graph {
+		g.totalFiles++
+	}
+	if g.subgraphs {
+		g.totalFiles += len(exported)
+	}
+	if g.gzip {
+		// Every file but the type declarations gets a compressed copy
+		g.totalFiles += g.totalFiles - 1
+	}
+	g.totalFiles += len(g.templates)
+	if g.html {
+		g.totalFiles++
+	}
+	if g.searchIndex {
+		g.totalFiles++
+		if g.gzip {
+			g.totalFiles++
+		}
+	}
+	if g.sidebars {
+		g.totalFiles++
+	}
+
+	// Write separate technology files for each area, in the order of the
+	// area keys so files are written and reported the same way on every run
+	areas := make([]string, 0, len(techsByArea))
+	for area := range techsByArea {
+		areas = append(areas, area)
+	}
+	sort.Strings(areas)
+	for _, area := range areas {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		techs := techsByArea[area]
+		techPath, err := prepareOutputPath(outputDir, g.ResearchFileName(area))
+		if err != nil {
+			return fmt.Errorf("failed to create directory for area %s: %w", area, err)
+		}
+		if err := g.writeJSONFile(techPath, ResearchFileJSON{SchemaVersion: SchemaVersion, Area: area, Technologies: techs}); err != nil {
 			return fmt.Errorf("failed to write technologies for area %s: %w", area, err)
 		}
 	}
 
-	// Write metadata file with areas, tiers, categories, and max level
-	metaPath := filepath.Join(outputDir, "metadata.json")
-	if err := g.writeJSONFile(metaPath, map[string]interface{}{
-		"areas":      g.tree.GetAreas(),
-		"tiers":      g.tree.GetTiers(),
-		"categories": g.tree.GetCategories(),
-		"maxLevel":   g.tree.GetMaxLevel(),
-	}); err != nil {
+	// Write metadata file with areas, tiers, categories, max level, colors,
+	// the display names and icons of areas and categories and tree issues
+	metaPath, err := prepareOutputPath(outputDir, g.MetadataFileName())
+	if err != nil {
+		return fmt.Errorf("failed to create metadata directory: %w", err)
+	}
+	if err := g.writeJSONFile(metaPath, g.Metadata()); err != nil {
 		return fmt.Errorf("failed to write metadata: %w", err)
 	}
 
+	// Write the report of icons shared by several technologies
+	g.sharedIcons = SharedIcons(exported)
+	iconUsagePath, err := prepareOutputPath(outputDir, g.IconUsageFileName())
+	if err != nil {
+		return fmt.Errorf("failed to create icon usage directory: %w", err)
+	}
+	if err := g.writeJSONFile(iconUsagePath, map[string]interface{}{
+		"heavyReuseThreshold": HeavyIconReuse,
+		"sharedIcons":         g.sharedIcons,
+	}); err != nil {
+		return fmt.Errorf("failed to write icon usage report: %w", err)
+	}
+
+	// Write TypeScript declarations describing the JSON files
+	typesPath, err := prepareOutputPath(outputDir, g.TypesFileName())
+	if err != nil {
+		return fmt.Errorf("failed to create type declarations directory: %w", err)
+	}
+	if err := g.writeFile(typesPath, []byte(TypeDefinitions())); err != nil {
+		return fmt.Errorf("failed to write type declarations: %w", err)
+	}
+
+	// Write overrides report when definitions were replaced
+	if len(g.overrides) > 0 {
+		overridesPath, err := prepareOutputPath(outputDir, g.OverridesFileName())
+		if err != nil {
+			return fmt.Errorf("failed to create overrides directory: %w", err)
+		}
+		if err := g.writeJSONFile(overridesPath, map[string]interface{}{
+			"overrides": g.overrides,
+		}); err != nil {
+			return fmt.Errorf("failed to write overrides report: %w", err)
+		}
+	}
+
+	// Write the localization coverage report when requested
+	if len(g.coverage) > 0 {
+		coveragePath, err := prepareOutputPath(outputDir, g.CoverageFileName())
+		if err != nil {
+			return fmt.Errorf("failed to create coverage directory: %w", err)
+		}
+		if err := g.writeJSONFile(coveragePath, map[string]interface{}{
+			"languages": g.coverage,
+		}); err != nil {
+			return fmt.Errorf("failed to write localization coverage report: %w", err)
+		}
+	}
+
+	// Write the research mechanics when requested
+	if g.mechanics != nil {
+		mechanicsPath, err := prepareOutputPath(outputDir, g.MechanicsFileName())
+		if err != nil {
+			return fmt.Errorf("failed to create mechanics directory: %w", err)
+		}
+		if err := g.writeJSONFile(mechanicsPath, g.mechanics); err != nil {
+			return fmt.Errorf("failed to write research mechanics: %w", err)
+		}
+	}
+
+	if g.graph {
+		graphPath, err := prepareOutputPath(outputDir, g.GraphFileName())
+		if err != nil {
+			return fmt.Errorf("failed to create graph directory: %w", err)
+		}
+		if err := g.writeJSONFile(graphPath, g.graphData(techsByArea)); err != nil {
+			return fmt.Errorf("failed to write graph: %w", err)
+		}
+	}
+
+	if g.searchIndex {
+		searchPath, err := prepareOutputPath(outputDir, g.SearchIndexFileName())
+		if err != nil {
+			return fmt.Errorf("failed to create search index directory: %w", err)
+		}
+		if err := g.writeJSONFile(searchPath, g.searchRecords(techsByArea)); err != nil {
+			return fmt.Errorf("failed to write search index: %w", err)
+		}
+	}
+
+	if g.sidebars {
+		sidebarsPath, err := prepareOutputPath(outputDir, g.SidebarsFileName())
+		if err != nil {
+			return fmt.Errorf("failed to create sidebars directory: %w", err)
+		}
+		content, err := g.sidebarsFile(techsByArea)
+		if err != nil {
+			return fmt.Errorf("failed to encode sidebars: %w", err)
+		}
+		if err := g.writeFile(sidebarsPath, content); err != nil {
+			return fmt.Errorf("failed to write sidebars: %w", err)
+		}
+	}
+
+	if g.subgraphs {
+		if err := g.writeSubgraphs(ctx, outputDir, exported); err != nil {
+			return err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := g.writeDomains(outputDir); err != nil {
+		return err
+	}
+	if err := g.writeTemplates(ctx, outputDir, techsByArea); err != nil {
+		return err
+	}
+	if err := g.writeHTML(outputDir, techsByArea); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// Technology returns the JSON representation of a technology, as written to
+// the research files
+func (g *JSONGenerator) Technology(node *tree.TechNode) TechnologyJSON {
+	deps := make([]string, len(node.Dependencies))
+	for i, dep := range node.Dependencies {
+		deps[i] = dep.Tech.Key
+	}
+
+	icon, iconOverride := g.resolveIcon(node.Tech)
+
+	// Use localized name if available, otherwise format from key
+	name := node.Tech.Name
+	if name == "" {
+		name = formatTechName(node.Tech.Key)
+	}
+
+	// Position in the research order, so consumers can lay out the tree
+	// without sorting the prerequisites themselves
+	order, areaOrder := g.ResearchOrder()
+
+	tech := TechnologyJSON{
+		Key:                node.Tech.Key,
+		Name:               g.formatText(name),
+		Description:        g.formatText(node.Tech.Description),
+		Cost:               node.Tech.Cost,
+		Area:               node.Tech.Area,
+		Tier:               node.Tech.Tier,
+		Level:              node.Level,
+		EstimatedYear:      g.EstimatedYears()[node.Tech.Key],
+		Category:           strings.Join(node.Tech.Category, ", "),
+		Prerequisites:      deps,
+		Order:              order[node.Tech.Key],
+		AreaOrder:          areaOrder[node.Tech.Key],
+		CumulativeCost:     g.CumulativeCosts()[node.Tech.Key],
+		Position:           g.Positions()[node.Tech.Key],
+		PrerequisiteGroups: node.Tech.PrerequisiteGroups,
+		Weight:             node.Tech.Weight,
+		SourceFile:         node.Tech.SourceFile,
+		Icon:               icon,
+		IconFile:           iconFileName(icon, iconOverride),
+		IconData:           g.embeddedIcons()[icon],
+		IsStartTech:        node.Tech.IsStartTech,
+		IsDangerous:        node.Tech.IsDangerous,
+		IsRare:             node.Tech.IsRare,
+		IsEvent:            node.Tech.IsEvent,
+		IsInsight:          node.Tech.IsInsight,
+		Acquisition:        node.Tech.Acquisition(),
+		IsReverse:          node.Tech.IsReverse,
+		IsRepeatable:       node.Tech.IsRepeatable,
+		Levels:             node.Tech.Levels,
+		CostPerLevel:       node.Tech.CostPerLevel,
+		IsInfinite:         node.Tech.IsInfinite(),
+		IsGestalt:          node.Tech.IsGestalt,
+		IsMegacorp:         node.Tech.IsMegacorp,
+		Mod:                node.Tech.Mod,
+		// Repeatable technologies get a precomputed level -> cost table
+		CostTable: node.Tech.CostTable(g.repeatableLevels),
+	}
+
+	// Each group lists alternatives to the others; one group means all of
+	// its prerequisites are required
+	if tech.PrerequisiteGroups == nil {
+		tech.PrerequisiteGroups = [][]string{}
+	}
+
+	if g.repeatableBadges && node.Tech.IsRepeatable {
+		tech.BadgeIcon = BadgeIconName(icon, node.Tech.Levels)
+	}
+
+	if g.rawSource {
+		tech.Raw = node.Tech.Source
+	}
+
+	if g.full {
+		tech.FullTechnologyJSON = g.fullData(node.Tech)
+	}
+
+	return tech
+}
+
+// fullData returns the fields only exported in full mode
+func (g *JSONGenerator) fullData(tech *models.Technology) *FullTechnologyJSON {
+	featureUnlocks := tech.FeatureUnlocks
+	if featureUnlocks == nil {
+		featureUnlocks = []string{}
+	}
+	extraFlags := tech.ExtraFlags
+	if extraFlags == nil {
+		extraFlags = map[string]interface{}{}
+	}
+	weightGroups := tech.WeightGroups
+	if weightGroups == nil {
+		weightGroups = []string{}
+	}
+	groupFactors := tech.ModWeightIfGroupPicked
+	if groupFactors == nil {
+		groupFactors = map[string]float64{}
+	}
+
+	return &FullTechnologyJSON{
+		BaseWeight:         tech.BaseWeight,
+		OfferChance:        g.OfferChances()[tech.Key],
+		FeatureUnlocks:     featureUnlocks,
+		AIUpdateType:       tech.AIUpdateType,
+		Gateway:            tech.Gateway,
+		IsMachineEmpire:    tech.IsMachineEmpire,
+		IsHiveEmpire:       tech.IsHiveEmpire,
+		IsDriveAssimilator: tech.IsDriveAssimilator,
+		IsRogueServitor:    tech.IsRogueServitor,
+		ExtraFlags:         extraFlags,
+
+		WeightGroups:           weightGroups,
+		ModWeightIfGroupPicked: groupFactors,
+	}
+}
+
+// FilterFields returns the fields filter expressions are evaluated against
+// for a technology
+func (g *JSONGenerator) FilterFields(node *tree.TechNode) filter.Fields {
+	fields := filter.TechnologyFields(node.Tech)
+	fields["level"] = node.Level
+	fields["estimatedYear"] = g.EstimatedYears()[node.Tech.Key]
+	return fields
+}
+
+// EstimatedYears returns the estimated year each technology is reachable,
+// computing it on first use
+func (g *JSONGenerator) EstimatedYears() map[string]int {
+	if g.estimatedYears == nil {
+		g.estimatedYears = timeline.Estimate(g.tree, g.timeline)
+	}
+	return g.estimatedYears
+}
+
+// OfferChances returns the chance of each technology to be among the research
+// alternatives of its area, computing it on first use
+func (g *JSONGenerator) OfferChances() map[string]float64 {
+	if g.offerChances == nil {
+		g.offerChances = g.tree.OfferChances(g.alternatives)
+	}
+	return g.offerChances
+}
+
+// CumulativeCosts returns the cost of each technology including its
+// prerequisites, computing it on first use
+func (g *JSONGenerator) CumulativeCosts() map[string]tree.CumulativeCost {
+	if g.cumulativeCosts == nil {
+		g.cumulativeCosts = g.tree.CumulativeCosts()
+	}
+	return g.cumulativeCosts
+}
+
+// Positions returns the layered layout position of each technology within
+// its area, computing it on first use
+func (g *JSONGenerator) Positions() map[string]layout.Position {
+	if g.positions == nil {
+		g.positions = layout.Compute(g.tree)
+	}
+	return g.positions
+}
+
+// ResearchOrder returns the index of each technology in the topological
+// research order of the whole tree and of its area, computing both on first
+// use
+func (g *JSONGenerator) ResearchOrder() (order, areaOrder map[string]int) {
+	if g.order == nil {
+		g.order = make(map[string]int)
+		g.areaOrder = make(map[string]int)
+		areaCounts := make(map[string]int)
+		for i, node := range g.tree.TopologicalOrder() {
+			g.order[node.Tech.Key] = i
+			g.areaOrder[node.Tech.Key] = areaCounts[node.Tech.Area]
+			areaCounts[node.Tech.Area]++
+		}
+	}
+	return g.order, g.areaOrder
+}
+
+// Precompute computes the values Technology and FilterFields otherwise
+// compute on first use, so concurrent calls only read the generator until
+// it is configured again
+func (g *JSONGenerator) Precompute() {
+	g.EstimatedYears()
+	g.OfferChances()
+	g.CumulativeCosts()
+	g.Positions()
+	g.ResearchOrder()
+	g.embeddedIcons()
+}
+
+// prepareOutputPath joins a configured file name to the output directory and
+// creates any subdirectories the file name contains. The output directory
+// itself is expected to exist.
+func prepareOutputPath(outputDir, name string) (string, error) {
+	path := filepath.Join(outputDir, name)
+	if filepath.Dir(filepath.Clean(name)) != "." {
+		if _, err := os.Stat(outputDir); err != nil {
+			return "", err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", err
+		}
+	}
+	return path, nil
+}
+
+// GeneratedFiles returns the paths of all JSON files written by the last
+// call to Generate or GenerateJSONFiles, sorted
+func (g *JSONGenerator) GeneratedFiles() []string {
+	files := make([]string, len(g.files))
+	copy(files, g.files)
+	sort.Strings(files)
+	return files
+}
+
+// SkippedFiles returns the paths of the files not written by the last call
+// to Generate or GenerateJSONFiles because their inputs were unchanged, sorted
+func (g *JSONGenerator) SkippedFiles() []string {
+	files := make([]string, len(g.skipped))
+	copy(files, g.skipped)
+	sort.Strings(files)
+	return files
+}
+
+// Manifest returns the fingerprints of the files of the last call to
+// Generate or GenerateJSONFiles
+func (g *JSONGenerator) Manifest() *manifest.Manifest {
+	return g.manifest
+}
+
 // writeJSONFile is a helper function to write JSON data to a file
 func (g *JSONGenerator) writeJSONFile(path string, data interface{}) error {
-	file, err := os.Create(path)
+	content, err := g.encodeFile(data)
+	if err != nil {
+		return err
+	}
+	if err := g.writeFile(path, content); err != nil {
+		return err
+	}
+	if !g.gzip {
+		return nil
+	}
+
+	compressed, err := gzipContent(content)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	return g.writeFile(path+".gz", compressed)
+}
+
+// encodeJSONFile encodes the contents of a generated file with schemaVersion
+// as its first field, indented or on a single line
+func encodeJSONFile(data interface{}, indent bool) ([]byte, error) {
+	content, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	content = withSchemaVersion(content)
+	if !indent {
+		return append(content, '\n'), nil
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, content, "", "  "); err != nil {
+		return nil, err
+	}
+	indented.WriteByte('\n')
+	return indented.Bytes(), nil
+}
+
+// encodeFile encodes the contents of a generated data file in the format set
+// with SetFormat
+func (g *JSONGenerator) encodeFile(data interface{}) ([]byte, error) {
+	if g.format != FormatYAML {
+		return encodeJSONFile(data, !g.minify)
+	}
+	content, err := encodeJSONFile(data, false)
+	if err != nil {
+		return nil, err
+	}
+	return encodeYAML(content)
+}
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(data)
+// gzipContent compresses the content of a file. The gzip header has no name
+// or time, so the same content always compresses to the same bytes.
+func gzipContent(content []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	writer, err := gzip.NewWriterLevel(&compressed, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(content); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return compressed.Bytes(), nil
+}
+
+// withSchemaVersion adds schemaVersion as the first field of an encoded
+// object that doesn't start with it
+func withSchemaVersion(content []byte) []byte {
+	if len(content) < 2 || content[0] != '{' || bytes.HasPrefix(content, []byte(`{"schemaVersion":`)) {
+		return content
+	}
+	field := fmt.Sprintf(`{"schemaVersion":%d`, SchemaVersion)
+	if content[1] != '}' {
+		field += ","
+	}
+	return append([]byte(field), content[1:]...)
+}
+
+// writeFile writes a generated file and records it in the manifest. The file
+// is skipped if its content is unchanged since the manifest set with SetSince.
+func (g *JSONGenerator) writeFile(path string, content []byte) error {
+	name := manifestName(g.outputDir, path)
+	fingerprint := manifest.Fingerprint(content)
+	g.manifest.Set(name, fingerprint)
+	if g.since.Unchanged(name, fingerprint) {
+		g.skipped = append(g.skipped, path)
+		g.progress.Report(progress.StageGenerate, len(g.files)+len(g.skipped), g.totalFiles, name+" (unchanged)")
+		g.logger.Debug("Skipped unchanged file", "file", name)
+		return nil
+	}
+
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return err
+	}
+	g.files = append(g.files, path)
+	g.logger.Debug("Wrote file", "file", name)
+	g.progress.Report(progress.StageGenerate, len(g.files)+len(g.skipped), g.totalFiles, name)
+	return nil
+}
+
+// manifestName returns the name a file is recorded under in the manifest
+func manifestName(outputDir, path string) string {
+	if rel, err := filepath.Rel(outputDir, path); err == nil {
+		path = rel
+	}
+	return filepath.ToSlash(path)
 }
 
 // formatTechName converts tech key to readable name
@@ -170,34 +1101,185 @@ func formatTechName(key string) string {
 	return strings.Join(words, " ")
 }
 
-// ConvertIcons converts all technology icons from DDS to PNG
+// ConvertIcons converts all technology icons from DDS or TGA to PNG
 func (g *JSONGenerator) ConvertIcons(outputDir string) error {
+	return g.ConvertIconsContext(context.Background(), outputDir)
+}
+
+// ConvertIconsContext is ConvertIcons with a context. When it is cancelled,
+// conversion stops after the icon being converted and the context's error is
+// returned.
+func (g *JSONGenerator) ConvertIconsContext(ctx context.Context, outputDir string) error {
 	if g.gameDir == "" {
-		return fmt.Errorf("game directory not set")
+		return ErrNoGameDir
 	}
+	start := time.Now()
+	g.iconStats = IconStats{}
 
-	// Create icon converter
-	converter := NewIconConverter(g.gameDir, outputDir)
+	converter, closeMods, err := g.newIconConverter(outputDir)
+	if err != nil {
+		return err
+	}
+	defer closeMods()
+	converter.SetEncoding(g.iconEncoding)
+	converter.SetProgress(g.progress)
+	if g.manifest != nil {
+		converter.SetManifests(g.since, g.manifest)
+	}
 
-	// Collect all unique icon names
-	allNodes := g.tree.GetAllNodes()
-	iconNames := make([]string, 0, len(allNodes))
-	for _, node := range allNodes {
-		iconNames = append(iconNames, node.Tech.Icon)
+	// Collect the icon names of the exported technologies, setting aside
+	// those replaced by an override
+	nodes, err := g.exportedNodes(ctx)
+	if err != nil {
+		return err
+	}
+	iconNames := make([]string, 0, len(nodes))
+	overrides := make(map[string]string)
+	for _, node := range nodes {
+		icon, override := g.resolveIcon(node.Tech)
+		if override != "" {
+			overrides[icon] = override
+			continue
+		}
+		iconNames = append(iconNames, icon)
 	}
 
 	// Convert icons
-	fmt.Printf("🎨 Converting technology icons...\n")
-	converted, err := converter.ConvertIcons(iconNames)
-	if err != nil {
-		fmt.Printf("⚠ Some icons could not be converted: %v\n", err)
+	g.logger.Info("Converting technology icons", "icons", len(iconNames))
+	converted, err := converter.ConvertIconsContext(ctx, iconNames)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	} else if err != nil {
+		g.logger.Warn("Some icons could not be converted", "error", err)
+	}
+
+	if len(overrides) > 0 {
+		copied, err := converter.CopyOverrides(overrides)
+		if err != nil {
+			g.logger.Warn("Some icon overrides could not be copied", "error", err)
+		}
+		g.logger.Info("Used icon overrides", "count", copied)
+		converted += copied
 	}
 
+	g.iconStats = IconStats{Converted: converted, Skipped: converter.skipped, Failed: converter.failed}
+	if err := g.reportMissingIcons(converter, nodes, outputDir); err != nil {
+		return err
+	}
 	if converted > 0 {
-		fmt.Printf("✓ Converted %d technology icons\n", converted)
-	} else {
-		fmt.Printf("⚠ No icons were converted (icon files may not exist in game directory)\n")
+		g.logger.Info("Converted technology icons", "count", converted)
+	}
+	if converter.skipped > 0 {
+		g.logger.Info("Skipped icons unchanged since the previous manifest", "count", converter.skipped)
+	} else if converted == 0 {
+		g.logger.Warn("No icons were converted, icon files may not exist in the game directory", "dir", g.gameDir)
 	}
 
-	return nil
+	if g.extractsResourceIcons() {
+		g.convertResourceIcons(converter, nodes)
+	}
+	g.convertMetadataIcons(converter)
+	g.convertRelicIcons(converter)
+
+	if g.repeatableBadges && converted+converter.skipped > 0 {
+		g.renderBadges(ctx, converter, nodes)
+	}
+
+	g.iconStats.Duration = time.Since(start)
+	return ctx.Err()
+}
+
+// newIconConverter returns an icon converter looking up icons in the game
+// directory, the DLC archives and the mods, and a function closing the
+// archives and mods
+func (g *JSONGenerator) newIconConverter(outputDir string) (*IconConverter, func(), error) {
+	converter := NewIconConverter(g.gameDir, outputDir)
+	converter.SetIconsDir(g.output.IconsDir)
+	converter.SetSearchOrder(g.icons.SearchDirs, g.icons.Extensions)
+
+	var sources []gamefs.Source
+	closeMods := func() {
+		for _, source := range sources {
+			source.Close()
+		}
+	}
+	// Icons of DLC technologies may only exist in the DLC archives
+	archives, err := gamefs.DLCArchives(g.gameDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, archive := range archives {
+		source, err := gamefs.OpenZip(archive)
+		if err != nil {
+			closeMods()
+			return nil, nil, fmt.Errorf("failed to open DLC: %w", err)
+		}
+		sources = append(sources, source)
+		converter.AddDLC(source, archive)
+	}
+	for _, mod := range g.mods {
+		source, err := gamefs.Open(mod)
+		if err != nil {
+			closeMods()
+			return nil, nil, fmt.Errorf("failed to open mod: %w", err)
+		}
+		sources = append(sources, source)
+		converter.AddMod(source, mod)
+	}
+	return converter, closeMods, nil
+}
+
+// convertResourceIcons extracts the resource icons referenced from the names
+// and descriptions of the given technologies
+func (g *JSONGenerator) convertResourceIcons(converter *IconConverter, nodes []*tree.TechNode) {
+	var texts []string
+	for _, node := range nodes {
+		texts = append(texts, node.Tech.Name, node.Tech.Description)
+	}
+	names := localization.IconTokenNames(texts...)
+	if len(names) == 0 {
+		return
+	}
+
+	converted, err := converter.ConvertResourceIcons(names)
+	if err != nil {
+		g.logger.Warn("Some resource icons could not be converted", "error", err)
+	}
+	g.logger.Info("Extracted referenced resource icons", "count", converted, "referenced", len(names))
+}
+
+// renderBadges writes badge variants of the icons of the given repeatable
+// technologies until ctx is cancelled
+func (g *JSONGenerator) renderBadges(ctx context.Context, converter *IconConverter, nodes []*tree.TechNode) {
+	rendered := 0
+	skipped := converter.skipped
+	done := make(map[string]bool)
+	for _, node := range nodes {
+		if ctx.Err() != nil {
+			return
+		}
+		if !node.Tech.IsRepeatable {
+			continue
+		}
+		icon, _ := g.resolveIcon(node.Tech)
+		name := BadgeIconName(icon, node.Tech.Levels)
+		if done[name] {
+			continue
+		}
+		done[name] = true
+
+		if err := converter.RenderBadgeVariant(icon, node.Tech.Levels); err != nil {
+			// The base icon may simply not exist in the game files, or be
+			// an SVG override
+			continue
+		}
+		rendered++
+	}
+
+	// Badges skipped because they were unchanged are not rendered
+	rendered -= converter.skipped - skipped
+
+	if rendered > 0 {
+		g.logger.Info("Rendered repeatable icon badges", "count", rendered)
+	}
 }
@@ -1,13 +1,32 @@
 package generator
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
-	"stellaris-data-parser/lib/models"
-	"stellaris-data-parser/lib/tree"
+	"github.com/danaketh/StellarisDataParser/lib/config"
+	"github.com/danaketh/StellarisDataParser/lib/filter"
+	"github.com/danaketh/StellarisDataParser/lib/localization"
+	"github.com/danaketh/StellarisDataParser/lib/manifest"
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/progress"
+	"github.com/danaketh/StellarisDataParser/lib/timeline"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
 )
 
 func createTestTree() *tree.TechTree {
@@ -300,7 +319,7 @@ func TestTechnologyFieldsInJSON(t *testing.T) {
 
 		requiredFields := []string{
 			"key", "name", "cost", "area", "tier", "level",
-			"category", "prerequisites", "weight", "sourceFile",
+			"category", "prerequisites", "prerequisiteGroups", "weight", "sourceFile",
 			"isStartTech", "isDangerous", "isRare",
 			"isEvent", "isReverse", "isRepeatable", "levels",
 			"isGestalt", "isMegacorp",
@@ -331,3 +350,970 @@ func TestEmptyTreeGeneration(t *testing.T) {
 		t.Error("Expected metadata.json file to be created")
 	}
 }
+
+func TestRepeatableCostTable(t *testing.T) {
+	technologies := map[string]*models.Technology{
+		"tech_repeatable": {
+			Key:          "tech_repeatable",
+			Cost:         50000,
+			CostPerLevel: 10000,
+			Area:         "physics",
+			IsRepeatable: true,
+			Levels:       -1,
+		},
+		"tech_regular": {
+			Key:  "tech_regular",
+			Cost: 1000,
+			Area: "physics",
+		},
+	}
+
+	generator := NewJSONGenerator(tree.NewTechTree(technologies))
+	generator.SetRepeatableLevels(4)
+
+	tmpDir := t.TempDir()
+	if err := generator.GenerateJSONFiles(tmpDir); err != nil {
+		t.Fatalf("Failed to generate JSON files: %v", err)
+	}
+
+	content, err := os.ReadFile(tmpDir + "/research-physics.json")
+	if err != nil {
+		t.Fatalf("Failed to read technologies file: %v", err)
+	}
+
+	var data struct {
+		Technologies []struct {
+			Key        string             `json:"key"`
+			IsInfinite bool               `json:"isInfinite"`
+			CostTable  []models.LevelCost `json:"costTable"`
+		} `json:"technologies"`
+	}
+	if err := json.Unmarshal(content, &data); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+
+	for _, tech := range data.Technologies {
+		switch tech.Key {
+		case "tech_repeatable":
+			if !tech.IsInfinite {
+				t.Error("Expected tech_repeatable to be infinite")
+			}
+			if len(tech.CostTable) != 4 {
+				t.Fatalf("Expected 4 cost table entries, got %d", len(tech.CostTable))
+			}
+			if tech.CostTable[3].Cost != 80000 {
+				t.Errorf("Expected level 4 to cost 80000, got %d", tech.CostTable[3].Cost)
+			}
+		case "tech_regular":
+			if tech.CostTable != nil {
+				t.Error("Expected no cost table for non-repeatable technology")
+			}
+		}
+	}
+}
+
+func TestCustomOutputFileNames(t *testing.T) {
+	generator := NewJSONGenerator(createTestTree())
+	generator.SetOutputConfig(config.OutputConfig{
+		ResearchFile:  "data/tech-%area%.json",
+		MetadataFile:  "data/meta.json",
+		TypesFile:     "data/types.d.ts",
+		IconUsageFile: "data/icons.json",
+		IconsDir:      "img",
+	})
+
+	if name := generator.ResearchFileName("Physics"); name != "data/tech-physics.json" {
+		t.Errorf("Expected data/tech-physics.json, got %s", name)
+	}
+
+	tmpDir := t.TempDir()
+	if err := generator.GenerateJSONFiles(tmpDir); err != nil {
+		t.Fatalf("Failed to generate JSON files: %v", err)
+	}
+
+	for _, file := range []string{"data/tech-physics.json", "data/tech-engineering.json", "data/meta.json", "data/types.d.ts", "data/icons.json"} {
+		if _, err := os.Stat(tmpDir + "/" + file); err != nil {
+			t.Errorf("Expected %s to be created: %v", file, err)
+		}
+	}
+
+	if _, err := os.Stat(tmpDir + "/metadata.json"); !os.IsNotExist(err) {
+		t.Error("Expected default metadata.json not to be created")
+	}
+}
+
+func TestOverridesReport(t *testing.T) {
+	generator := NewJSONGenerator(createTestTree())
+	tmpDir := t.TempDir()
+
+	// No report without overrides
+	if err := generator.GenerateJSONFiles(tmpDir); err != nil {
+		t.Fatalf("Failed to generate JSON files: %v", err)
+	}
+	if _, err := os.Stat(tmpDir + "/overrides.json"); !os.IsNotExist(err) {
+		t.Error("Expected no overrides.json without overrides")
+	}
+
+	generator.SetOverrides([]models.Override{{
+		Key:          "tech_test_1",
+		Definition:   models.Definition{SourceFile: "00_base.txt"},
+		OverriddenBy: models.Definition{SourceFile: "mod.txt", Mod: "my_mod"},
+	}})
+	if err := generator.GenerateJSONFiles(tmpDir); err != nil {
+		t.Fatalf("Failed to generate JSON files: %v", err)
+	}
+
+	content, err := os.ReadFile(tmpDir + "/overrides.json")
+	if err != nil {
+		t.Fatalf("Failed to read overrides report: %v", err)
+	}
+
+	var report struct {
+		Overrides []models.Override `json:"overrides"`
+	}
+	if err := json.Unmarshal(content, &report); err != nil {
+		t.Fatalf("Failed to parse overrides report: %v", err)
+	}
+	if len(report.Overrides) != 1 || report.Overrides[0].OverriddenBy.Mod != "my_mod" {
+		t.Errorf("Unexpected overrides report: %+v", report.Overrides)
+	}
+}
+
+func TestGenerateWithFilter(t *testing.T) {
+	generator := NewJSONGenerator(createTestTree())
+
+	expr, err := filter.Compile(`area == "physics" && level >= 1`, filter.TechnologyFieldNames())
+	if err != nil {
+		t.Fatalf("Failed to compile filter: %v", err)
+	}
+	generator.SetFilter(expr)
+
+	tmpDir := t.TempDir()
+	if err := generator.GenerateJSONFiles(tmpDir); err != nil {
+		t.Fatalf("Failed to generate JSON files: %v", err)
+	}
+
+	if _, err := os.Stat(tmpDir + "/research-engineering.json"); !os.IsNotExist(err) {
+		t.Error("Expected no engineering file when filtered out")
+	}
+
+	content, err := os.ReadFile(tmpDir + "/research-physics.json")
+	if err != nil {
+		t.Fatalf("Failed to read physics file: %v", err)
+	}
+	if strings.Contains(string(content), `"key": "tech_test_1"`) {
+		t.Error("Expected level 0 technology to be filtered out")
+	}
+	if !strings.Contains(string(content), `"key": "tech_test_2"`) {
+		t.Error("Expected tech_test_2 to match the filter")
+	}
+
+	files := generator.GeneratedFiles()
+	if len(files) != 4 {
+		t.Errorf("Expected 4 generated files, got %v", files)
+	}
+}
+
+func TestEstimatedYear(t *testing.T) {
+	technologies := map[string]*models.Technology{
+		"tech_start": {
+			Key:         "tech_start",
+			Area:        "physics",
+			IsStartTech: true,
+		},
+		"tech_next": {
+			Key:           "tech_next",
+			Cost:          480,
+			Area:          "physics",
+			Tier:          1,
+			Prerequisites: []string{"tech_start"},
+		},
+	}
+
+	generator := NewJSONGenerator(tree.NewTechTree(technologies))
+	generator.SetTimeline(timeline.Assumptions{StartYear: 2200, BaseResearch: 20})
+
+	tmpDir := t.TempDir()
+	if err := generator.GenerateJSONFiles(tmpDir); err != nil {
+		t.Fatalf("Failed to generate JSON files: %v", err)
+	}
+
+	content, err := os.ReadFile(tmpDir + "/research-physics.json")
+	if err != nil {
+		t.Fatalf("Failed to read technologies file: %v", err)
+	}
+
+	var data struct {
+		Technologies []struct {
+			Key           string `json:"key"`
+			EstimatedYear int    `json:"estimatedYear"`
+		} `json:"technologies"`
+	}
+	if err := json.Unmarshal(content, &data); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+
+	expected := map[string]int{"tech_start": 2200, "tech_next": 2202}
+	for _, tech := range data.Technologies {
+		if tech.EstimatedYear != expected[tech.Key] {
+			t.Errorf("Expected %s to have estimated year %d, got %d", tech.Key, expected[tech.Key], tech.EstimatedYear)
+		}
+	}
+}
+
+func TestGenerateSince(t *testing.T) {
+	gameDir := t.TempDir()
+	iconDir := filepath.Join(gameDir, "gfx", "interface", "icons", "technologies")
+	if err := os.MkdirAll(iconDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	iconFile, err := os.Create(filepath.Join(iconDir, "tech_icon.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(iconFile, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	iconFile.Close()
+
+	newTechnologies := func(cost int) map[string]*models.Technology {
+		return map[string]*models.Technology{
+			"tech_physics": {Key: "tech_physics", Area: "physics", Cost: cost, Icon: "tech_icon"},
+			"tech_society": {Key: "tech_society", Area: "society", Cost: 1000},
+		}
+	}
+
+	first := NewJSONGenerator(tree.NewTechTree(newTechnologies(1000)))
+	first.SetGameDir(gameDir)
+	firstDir := t.TempDir()
+	if err := first.Generate(firstDir); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(firstDir, "manifest.json")); err != nil {
+		t.Fatalf("Expected manifest to be written: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(firstDir, "research-physics.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(content)
+	output := first.Manifest().Outputs["research-physics.json"]
+	if output.SHA256 != hex.EncodeToString(sum[:]) || output.Size != int64(len(content)) || output.GeneratedAt.IsZero() {
+		t.Errorf("Expected the checksum, size and time of research-physics.json, got %+v", output)
+	}
+	if first.Manifest().Inputs != nil {
+		t.Error("Expected no inputs in the manifest without SetInputs")
+	}
+	if len(first.Manifest().Outputs) != len(first.Manifest().Files) {
+		t.Errorf("Expected an output for every file, got %d for %d", len(first.Manifest().Outputs), len(first.Manifest().Files))
+	}
+
+	// Nothing changed: only the manifest is written
+	second := NewJSONGenerator(tree.NewTechTree(newTechnologies(1000)))
+	second.SetGameDir(gameDir)
+	second.SetSince(first.Manifest())
+	inputs := &manifest.Inputs{Settings: "flags", Files: map[string]string{"game/common/technology/00_tech.txt": "1"}}
+	second.SetInputs(inputs)
+	secondDir := t.TempDir()
+	if err := second.Generate(secondDir); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+	if files := second.GeneratedFiles(); len(files) != 1 || filepath.Base(files[0]) != "manifest.json" {
+		t.Errorf("Expected only the manifest to be written, got %v", files)
+	}
+	if len(second.SkippedFiles()) != 5 {
+		t.Errorf("Expected 5 skipped files, got %v", second.SkippedFiles())
+	}
+	if _, err := os.Stat(filepath.Join(secondDir, "icons", "tech_icon.png")); err == nil {
+		t.Error("Expected unchanged icon not to be written")
+	}
+	if len(second.Manifest().Files) != len(first.Manifest().Files) {
+		t.Errorf("Expected the manifest to cover skipped files, got %v", second.Manifest().Names())
+	}
+	if second.Manifest().Inputs != inputs {
+		t.Error("Expected the inputs to be recorded in the manifest")
+	}
+	if second.Manifest().Outputs["research-physics.json"] != output {
+		t.Errorf("Expected the output of a skipped file to be carried over, got %+v", second.Manifest().Outputs["research-physics.json"])
+	}
+
+	// A changed technology only rewrites its area
+	third := NewJSONGenerator(tree.NewTechTree(newTechnologies(2000)))
+	third.SetSince(first.Manifest())
+	thirdDir := t.TempDir()
+	if err := third.GenerateJSONFiles(thirdDir); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+	if files := third.GeneratedFiles(); len(files) != 1 || filepath.Base(files[0]) != "research-physics.json" {
+		t.Errorf("Expected only research-physics.json to be written, got %v", files)
+	}
+}
+
+func TestGenerateProgress(t *testing.T) {
+	var buf strings.Builder
+	generator := NewJSONGenerator(createTestTree())
+	generator.SetProgress(progress.NewReporter(&buf))
+
+	if err := generator.GenerateJSONFiles(t.TempDir()); err != nil {
+		t.Fatalf("Failed to generate JSON files: %v", err)
+	}
+
+	var events []progress.Event
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var event progress.Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Invalid event %q: %v", line, err)
+		}
+		events = append(events, event)
+	}
+
+	if len(events) != len(generator.GeneratedFiles()) {
+		t.Fatalf("Expected one event per file, got %d events for %d files", len(events), len(generator.GeneratedFiles()))
+	}
+	last := events[len(events)-1]
+	if last.Stage != progress.StageGenerate || last.Current != last.Total {
+		t.Errorf("Expected last event to complete the generate stage, got %+v", last)
+	}
+}
+
+func TestGenerateProgressOrder(t *testing.T) {
+	var first []string
+	for run := 0; run < 5; run++ {
+		var buf strings.Builder
+		generator := NewJSONGenerator(createTestTree())
+		generator.SetProgress(progress.NewReporter(&buf))
+		if err := generator.GenerateJSONFiles(t.TempDir()); err != nil {
+			t.Fatalf("Failed to generate JSON files: %v", err)
+		}
+
+		var items []string
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var event progress.Event
+			if err := json.Unmarshal([]byte(line), &event); err != nil {
+				t.Fatalf("Invalid event %q: %v", line, err)
+			}
+			items = append(items, event.Message)
+		}
+		if run == 0 {
+			first = items
+			if len(items) < 2 || items[0] != "research-engineering.json" || items[1] != "research-physics.json" {
+				t.Fatalf("Expected the research files first, by area, got %v", items)
+			}
+		} else if strings.Join(items, ",") != strings.Join(first, ",") {
+			t.Fatalf("Expected the same progress events on every run, got %v and %v", first, items)
+		}
+	}
+}
+
+func TestGenerateFull(t *testing.T) {
+	testTree := createTestTree()
+	node, _ := testTree.GetNode("tech_test_1")
+	node.Tech.ExtraFlags = map[string]interface{}{"is_insight": true}
+
+	generator := NewJSONGenerator(testTree)
+
+	if tech := generator.Technology(node); tech.FullTechnologyJSON != nil {
+		t.Error("Expected the full fields to be omitted without full mode")
+	}
+
+	generator.SetFull(true)
+	tech := generator.Technology(node)
+	if tech.FullTechnologyJSON == nil || tech.ExtraFlags["is_insight"] != true {
+		t.Fatalf("Expected extraFlags with is_insight, got %+v", tech.FullTechnologyJSON)
+	}
+
+	// The full fields are written even when empty
+	content, err := json.Marshal(tech)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var techData map[string]interface{}
+	if err := json.Unmarshal(content, &techData); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"baseWeight", "featureUnlocks", "weightGroups", "modWeightIfGroupPicked", "aiUpdateType", "gateway", "isMachineEmpire"} {
+		if _, exists := techData[field]; !exists {
+			t.Errorf("Expected field '%s' in full mode", field)
+		}
+	}
+
+	other, _ := testTree.GetNode("tech_test_2")
+	if flags := generator.Technology(other).ExtraFlags; flags == nil || len(flags) != 0 {
+		t.Errorf("Expected empty extraFlags object, got %v", flags)
+	}
+}
+
+func TestGenerateExtraFlagsBlocks(t *testing.T) {
+	testTree := createTestTree()
+	node, _ := testTree.GetNode("tech_test_1")
+	node.Tech.ExtraFlags = map[string]interface{}{
+		"my_mod_unlocks": map[string]interface{}{"rank": models.Comparison{Operator: "!=", Value: 2}},
+	}
+
+	generator := NewJSONGenerator(testTree)
+	generator.SetFull(true)
+	content, err := json.Marshal(generator.Technology(node))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `"extraFlags":{"my_mod_unlocks":{"rank":{"operator":"!=","value":2}}}`) {
+		t.Errorf("Expected the nested block in extraFlags, got %s", content)
+	}
+}
+
+func TestColorMode(t *testing.T) {
+	testTree := createTestTree()
+	node, _ := testTree.GetNode("tech_test_1")
+	node.Tech.Name = "§YLasers§!"
+	node.Tech.Description = "Grants §G+10%§! damage"
+
+	generator := NewJSONGenerator(testTree)
+	techData := generator.Technology(node)
+	if techData.Name != "Lasers" || techData.Description != "Grants +10% damage" {
+		t.Errorf("Expected color markup to be stripped by default, got %q and %q", techData.Name, techData.Description)
+	}
+
+	generator.SetColorMode(localization.ColorHTML)
+	techData = generator.Technology(node)
+	if techData.Description != `Grants <span class="stellaris-color-G">+10%</span> damage` {
+		t.Errorf("Expected HTML spans, got %q", techData.Description)
+	}
+
+	generator.SetColorMode(localization.ColorRaw)
+	if name := generator.Technology(node).Name; name != "§YLasers§!" {
+		t.Errorf("Expected raw markup to be kept, got %q", name)
+	}
+}
+
+func TestIconTokenMode(t *testing.T) {
+	gameDir := t.TempDir()
+	resourceDir := filepath.Join(gameDir, filepath.FromSlash(ResourceIconsDir))
+	if err := os.MkdirAll(resourceDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	iconFile, err := os.Create(filepath.Join(resourceDir, "energy.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(iconFile, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	iconFile.Close()
+
+	testTree := tree.NewTechTree(map[string]*models.Technology{
+		"tech_power": {Key: "tech_power", Area: "physics", Description: "Produces £energy£ and £minerals£"},
+	})
+	node, _ := testTree.GetNode("tech_power")
+
+	generator := NewJSONGenerator(testTree)
+	generator.SetGameDir(gameDir)
+	if description := generator.Technology(node).Description; description != "Produces £energy£ and £minerals£" {
+		t.Errorf("Expected icon references to be kept by default, got %q", description)
+	}
+
+	generator.SetIconTokenMode(localization.IconTokenHTML)
+	expected := `Produces <img class="stellaris-icon" src="icons/resources/energy.png" alt="energy"> and <img class="stellaris-icon" src="icons/resources/minerals.png" alt="minerals">`
+	if description := generator.Technology(node).Description; description != expected {
+		t.Errorf("Expected <img> elements, got %q", description)
+	}
+
+	outputDir := t.TempDir()
+	if err := generator.Generate(outputDir); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "icons", "resources", "energy.png")); err != nil {
+		t.Errorf("Expected the energy icon to be extracted: %v", err)
+	}
+}
+
+func TestCommandMode(t *testing.T) {
+	testTree := createTestTree()
+	node, _ := testTree.GetNode("tech_test_1")
+	node.Tech.Description = "The [Root.GetName] studies §Y[Root.GetSpeciesName]§! history."
+
+	generator := NewJSONGenerator(testTree)
+	if description := generator.Technology(node).Description; description != "The studies history." {
+		t.Errorf("Expected commands to be stripped by default, got %q", description)
+	}
+
+	generator.SetCommandMode(localization.CommandPlaceholder, map[string]string{"Root.GetName": "Empire"})
+	if description := generator.Technology(node).Description; description != "The Empire studies history." {
+		t.Errorf("Expected the configured placeholder, got %q", description)
+	}
+}
+
+func TestGenerateSkipIcons(t *testing.T) {
+	gameDir := t.TempDir()
+	iconDir := filepath.Join(gameDir, "gfx", "interface", "icons", "technologies")
+	if err := os.MkdirAll(iconDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	iconFile, err := os.Create(filepath.Join(iconDir, "tech_icon.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(iconFile, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	iconFile.Close()
+
+	technologies := map[string]*models.Technology{
+		"tech_physics": {Key: "tech_physics", Area: "physics", Cost: 1000, Icon: "tech_icon"},
+	}
+
+	first := NewJSONGenerator(tree.NewTechTree(technologies))
+	first.SetGameDir(gameDir)
+	if err := first.Generate(t.TempDir()); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	second := NewJSONGenerator(tree.NewTechTree(technologies))
+	second.SetGameDir(gameDir)
+	second.SetSkipIcons(true)
+	second.SetSince(first.Manifest())
+	secondDir := t.TempDir()
+	if err := second.Generate(secondDir); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(secondDir, "icons")); err == nil {
+		t.Error("Expected no icons to be written")
+	}
+	if fingerprint := second.Manifest().Files["icons/tech_icon.png"]; fingerprint != first.Manifest().Files["icons/tech_icon.png"] {
+		t.Errorf("Expected the icon fingerprint to be carried over, got %q", fingerprint)
+	}
+}
+
+func TestLocalizationCoverageReport(t *testing.T) {
+	generator := NewJSONGenerator(createTestTree())
+	tmpDir := t.TempDir()
+
+	// No report unless requested
+	if err := generator.GenerateJSONFiles(tmpDir); err != nil {
+		t.Fatalf("Failed to generate JSON files: %v", err)
+	}
+	if _, err := os.Stat(tmpDir + "/localization-coverage.json"); !os.IsNotExist(err) {
+		t.Error("Expected no localization-coverage.json without coverage")
+	}
+
+	generator.SetLocalizationCoverage([]localization.LanguageCoverage{{
+		Language:     "german",
+		Expected:     2,
+		Missing:      1,
+		Coverage:     50,
+		MissingNames: []string{"tech_test_1"},
+	}})
+	if err := generator.GenerateJSONFiles(tmpDir); err != nil {
+		t.Fatalf("Failed to generate JSON files: %v", err)
+	}
+
+	content, err := os.ReadFile(tmpDir + "/localization-coverage.json")
+	if err != nil {
+		t.Fatalf("Failed to read coverage report: %v", err)
+	}
+	var report struct {
+		Languages []localization.LanguageCoverage `json:"languages"`
+	}
+	if err := json.Unmarshal(content, &report); err != nil {
+		t.Fatalf("Failed to parse coverage report: %v", err)
+	}
+	if len(report.Languages) != 1 || report.Languages[0].MissingNames[0] != "tech_test_1" {
+		t.Errorf("Unexpected coverage report: %+v", report.Languages)
+	}
+}
+
+func TestSubgraphFiles(t *testing.T) {
+	generator := NewJSONGenerator(createTestTree())
+	generator.SetSubgraphs(true)
+	tmpDir := t.TempDir()
+
+	if err := generator.GenerateJSONFiles(tmpDir); err != nil {
+		t.Fatalf("Failed to generate JSON files: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "subgraphs", "tech_test_2.json"))
+	if err != nil {
+		t.Fatalf("Failed to read subgraph file: %v", err)
+	}
+	var subgraph struct {
+		Key         string      `json:"key"`
+		Ancestors   []string    `json:"ancestors"`
+		Descendants []string    `json:"descendants"`
+		Edges       [][2]string `json:"edges"`
+	}
+	if err := json.Unmarshal(content, &subgraph); err != nil {
+		t.Fatalf("Failed to parse subgraph file: %v", err)
+	}
+
+	if subgraph.Key != "tech_test_2" {
+		t.Errorf("Expected key tech_test_2, got %q", subgraph.Key)
+	}
+	if len(subgraph.Ancestors) != 1 || subgraph.Ancestors[0] != "tech_test_1" {
+		t.Errorf("Expected ancestors [tech_test_1], got %v", subgraph.Ancestors)
+	}
+	if len(subgraph.Descendants) != 1 || subgraph.Descendants[0] != "tech_test_3" {
+		t.Errorf("Expected descendants [tech_test_3], got %v", subgraph.Descendants)
+	}
+	expected := [][2]string{{"tech_test_1", "tech_test_2"}, {"tech_test_2", "tech_test_3"}}
+	if len(subgraph.Edges) != len(expected) || subgraph.Edges[0] != expected[0] || subgraph.Edges[1] != expected[1] {
+		t.Errorf("Expected edges %v, got %v", expected, subgraph.Edges)
+	}
+
+	if _, exists := generator.SubgraphData("tech_unknown"); exists {
+		t.Error("Expected no subgraph for an unknown technology")
+	}
+}
+
+func TestGraphFile(t *testing.T) {
+	generator := NewJSONGenerator(createTestTree())
+	generator.SetGraph(true)
+	tmpDir := t.TempDir()
+
+	if err := generator.GenerateJSONFiles(tmpDir); err != nil {
+		t.Fatalf("Failed to generate JSON files: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "graph.json"))
+	if err != nil {
+		t.Fatalf("Failed to read graph file: %v", err)
+	}
+	var graph struct {
+		Nodes []map[string]interface{} `json:"nodes"`
+		Links []GraphLink              `json:"links"`
+	}
+	if err := json.Unmarshal(content, &graph); err != nil {
+		t.Fatalf("Failed to parse graph file: %v", err)
+	}
+
+	expectedNodes := []string{"tech_test_1", "tech_test_2", "tech_test_3"}
+	if len(graph.Nodes) != len(expectedNodes) {
+		t.Fatalf("Expected %d nodes, got %d", len(expectedNodes), len(graph.Nodes))
+	}
+	for i, key := range expectedNodes {
+		if graph.Nodes[i]["id"] != key || graph.Nodes[i]["key"] != key {
+			t.Errorf("Expected node %d to be %s, got %v", i, key, graph.Nodes[i]["id"])
+		}
+	}
+	if graph.Nodes[2]["area"] != "engineering" {
+		t.Errorf("Expected node attributes, got %v", graph.Nodes[2])
+	}
+
+	expectedLinks := []GraphLink{{"tech_test_1", "tech_test_2"}, {"tech_test_2", "tech_test_3"}}
+	if len(graph.Links) != len(expectedLinks) || graph.Links[0] != expectedLinks[0] || graph.Links[1] != expectedLinks[1] {
+		t.Errorf("Expected links %v, got %v", expectedLinks, graph.Links)
+	}
+}
+
+func TestDomainFiles(t *testing.T) {
+	generator := NewJSONGenerator(createTestTree())
+	generator.SetDomain(DomainEdicts, []*models.Edict{{Key: "research_subsidies", Cost: map[string]float64{"influence": 50}}})
+	tmpDir := t.TempDir()
+
+	if err := generator.GenerateJSONFiles(tmpDir); err != nil {
+		t.Fatalf("Failed to generate JSON files: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "edicts.json"))
+	if err != nil {
+		t.Fatalf("Failed to read edicts file: %v", err)
+	}
+	var file struct {
+		Edicts []models.Edict `json:"edicts"`
+	}
+	if err := json.Unmarshal(content, &file); err != nil {
+		t.Fatalf("Failed to parse edicts file: %v", err)
+	}
+	if len(file.Edicts) != 1 || file.Edicts[0].Key != "research_subsidies" || file.Edicts[0].Cost["influence"] != 50 {
+		t.Errorf("Expected the edict, got %+v", file.Edicts)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "policies.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected no file for a domain that wasn't set")
+	}
+}
+
+func TestDomainFileWithSeveralLists(t *testing.T) {
+	generator := NewJSONGenerator(createTestTree())
+	generator.SetDomain(DomainAnomalies, map[string]interface{}{
+		"anomalies":       []*models.Anomaly{{Key: "ORB_CAT"}},
+		"specialProjects": []*models.SpecialProject{{Key: "SURVEY_DERELICT"}},
+	})
+	tmpDir := t.TempDir()
+
+	if err := generator.GenerateJSONFiles(tmpDir); err != nil {
+		t.Fatalf("Failed to generate JSON files: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "anomalies.json"))
+	if err != nil {
+		t.Fatalf("Failed to read anomalies file: %v", err)
+	}
+	var file struct {
+		Anomalies       []models.Anomaly        `json:"anomalies"`
+		SpecialProjects []models.SpecialProject `json:"specialProjects"`
+	}
+	if err := json.Unmarshal(content, &file); err != nil {
+		t.Fatalf("Failed to parse anomalies file: %v", err)
+	}
+	if len(file.Anomalies) != 1 || len(file.SpecialProjects) != 1 || file.SpecialProjects[0].Key != "SURVEY_DERELICT" {
+		t.Errorf("Expected both lists at the top level, got %s", content)
+	}
+}
+
+func TestOfferChanceUsesResearchAlternatives(t *testing.T) {
+	techTree := tree.NewTechTree(map[string]*models.Technology{
+		"tech_a": {Key: "tech_a", Area: "physics", Tier: 1, Weight: 50},
+		"tech_b": {Key: "tech_b", Area: "physics", Tier: 1, Weight: 25},
+		"tech_c": {Key: "tech_c", Area: "physics", Tier: 1, Weight: 25},
+		"tech_d": {Key: "tech_d", Area: "physics", Tier: 1, Weight: 0},
+	})
+	node, _ := techTree.GetNode("tech_a")
+
+	generator := NewJSONGenerator(techTree)
+	generator.SetFull(true)
+	if chance := generator.Technology(node).OfferChance; chance != 1.0 {
+		t.Errorf("Expected tech_a to always be offered with %d alternatives, got %v", DefaultResearchAlternatives, chance)
+	}
+	if unweighted, _ := techTree.GetNode("tech_d"); generator.Technology(unweighted).OfferChance != 0.0 {
+		t.Errorf("Expected technologies without weight never to be offered")
+	}
+
+	generator.SetResearchAlternatives(1)
+	if chance := generator.Technology(node).OfferChance; chance != 0.5 {
+		t.Errorf("Expected tech_a to be offered with 0.5 with one alternative, got %v", chance)
+	}
+}
+
+func TestGenerateContextCancelled(t *testing.T) {
+	generator := NewJSONGenerator(createTestTree())
+	tmpDir := t.TempDir()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := generator.GenerateContext(ctx, tmpDir); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, generator.ManifestFileName())); err == nil {
+		t.Error("Expected no manifest after cancellation")
+	}
+}
+
+func TestGenerateLogger(t *testing.T) {
+	var buf bytes.Buffer
+	generator := NewJSONGenerator(createTestTree())
+	generator.SetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	if err := generator.Generate(t.TempDir()); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+	if !strings.Contains(buf.String(), "msg=\"Wrote file\" file=research-physics.json") {
+		t.Errorf("Expected a debug record for every written file, got %q", buf.String())
+	}
+}
+
+func TestIconStats(t *testing.T) {
+	gameDir := t.TempDir()
+	iconDir := filepath.Join(gameDir, "gfx", "interface", "icons", "technologies")
+	if err := os.MkdirAll(iconDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	iconFile, err := os.Create(filepath.Join(iconDir, "tech_icon.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(iconFile, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	iconFile.Close()
+	// An empty DDS file can't be decoded
+	if err := os.WriteFile(filepath.Join(iconDir, "tech_broken.dds"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	technologies := map[string]*models.Technology{
+		"tech_physics": {Key: "tech_physics", Area: "physics", Cost: 1000, Icon: "tech_icon"},
+		"tech_society": {Key: "tech_society", Area: "society", Cost: 1000, Icon: "tech_broken"},
+	}
+	generator := NewJSONGenerator(tree.NewTechTree(technologies))
+	generator.SetGameDir(gameDir)
+	if err := generator.Generate(t.TempDir()); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	stats := generator.IconStats()
+	if stats.Converted != 1 || stats.Failed != 1 || stats.Skipped != 0 {
+		t.Errorf("Expected 1 converted and 1 failed icon, got %+v", stats)
+	}
+	if stats.Duration <= 0 {
+		t.Error("Expected the conversion time to be recorded")
+	}
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	technologies := map[string]*models.Technology{
+		"tech_base": {Key: "tech_base", Area: "physics", Cost: 100, Category: []string{"computing"}, Icon: "tech_shared"},
+	}
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("tech_%02d", i)
+		technologies[key] = &models.Technology{
+			Key:           key,
+			Area:          []string{"physics", "society", "engineering"}[i%3],
+			Tier:          i % 4,
+			Cost:          1000,
+			Category:      []string{"computing", "biology"},
+			Icon:          "tech_shared",
+			Prerequisites: []string{"tech_base"},
+		}
+	}
+
+	generate := func() string {
+		generator := NewJSONGenerator(tree.NewTechTree(technologies))
+		generator.SetGraph(true)
+		generator.SetSubgraphs(true)
+		generator.SetFull(true)
+		generator.SetGeneratedAt(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+		outputDir := t.TempDir()
+		if err := generator.Generate(outputDir); err != nil {
+			t.Fatalf("Failed to generate: %v", err)
+		}
+		return outputDir
+	}
+
+	first := generate()
+	for i := 0; i < 3; i++ {
+		next := generate()
+		err := filepath.WalkDir(first, func(path string, entry os.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+			rel, _ := filepath.Rel(first, path)
+			expected, _ := os.ReadFile(path)
+			actual, err := os.ReadFile(filepath.Join(next, rel))
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(expected, actual) {
+				t.Errorf("Expected %s to be the same on every run", rel)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGenerateMinifiedAndGzip(t *testing.T) {
+	generator := NewJSONGenerator(createTestTree())
+	generator.SetMinify(true)
+	generator.SetGzip(true)
+
+	outputDir := t.TempDir()
+	if err := generator.GenerateJSONFiles(outputDir); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "metadata.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count(content, []byte("\n")); lines != 1 {
+		t.Errorf("Expected minified metadata on one line, got %d lines", lines)
+	}
+
+	file, err := os.Open(filepath.Join(outputDir, "metadata.json.gz"))
+	if err != nil {
+		t.Fatalf("Expected a compressed copy: %v", err)
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decompressed, content) {
+		t.Error("Expected the compressed copy to hold the JSON file")
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "technologies.d.ts.gz")); !os.IsNotExist(err) {
+		t.Error("Expected no compressed copy of the type declarations")
+	}
+	for _, path := range generator.GeneratedFiles() {
+		if strings.HasSuffix(path, ".json") {
+			if _, err := os.Stat(path + ".gz"); err != nil {
+				t.Errorf("Expected a compressed copy of %s", filepath.Base(path))
+			}
+		}
+	}
+}
+
+func TestGenerateSubtrees(t *testing.T) {
+	generator := NewJSONGenerator(createTestTree())
+	generator.SetSubtrees([]string{"tech_test_2", "tech_unknown"})
+
+	tmpDir := t.TempDir()
+	if err := generator.GenerateJSONFiles(tmpDir); err != nil {
+		t.Fatalf("Failed to generate JSON files: %v", err)
+	}
+
+	keys := func(area string) []string {
+		var file ResearchFileJSON
+		content, err := os.ReadFile(filepath.Join(tmpDir, "research-"+area+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(content, &file); err != nil {
+			t.Fatal(err)
+		}
+		var keys []string
+		for _, tech := range file.Technologies {
+			keys = append(keys, tech.Key)
+		}
+		return keys
+	}
+	if physics := keys("physics"); len(physics) != 1 || physics[0] != "tech_test_2" {
+		t.Errorf("Expected only tech_test_2 in physics, got %v", physics)
+	}
+	if engineering := keys("engineering"); len(engineering) != 1 || engineering[0] != "tech_test_3" {
+		t.Errorf("Expected the dependent tech_test_3 in engineering, got %v", engineering)
+	}
+
+	// The filter narrows the subtree further
+	expr, err := filter.Compile(`area == "physics"`, filter.TechnologyFieldNames())
+	if err != nil {
+		t.Fatal(err)
+	}
+	generator.SetFilter(expr)
+	tmpDir = t.TempDir()
+	if err := generator.GenerateJSONFiles(tmpDir); err != nil {
+		t.Fatalf("Failed to generate JSON files: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "research-engineering.json")); !os.IsNotExist(err) {
+		t.Error("Expected no engineering file with the area filter")
+	}
+}
+
+func TestGenerateRawSource(t *testing.T) {
+	testTree := createTestTree()
+	node, _ := testTree.GetNode("tech_test_1")
+	node.Tech.Source = &models.Source{File: "00_test.txt", StartLine: 1, EndLine: 3, Text: "tech_test_1 = {\n\tcost = 100\n}"}
+
+	generator := NewJSONGenerator(testTree)
+	if tech := generator.Technology(node); tech.Raw != nil {
+		t.Error("Expected raw to be omitted by default")
+	}
+
+	generator.SetRawSource(true)
+	if tech := generator.Technology(node); tech.Raw != node.Tech.Source {
+		t.Errorf("Expected the technology's source as raw, got %+v", tech.Raw)
+	}
+	other, _ := testTree.GetNode("tech_test_2")
+	content, err := json.Marshal(generator.Technology(other))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), `"raw"`) {
+		t.Error("Expected no raw field for a technology without a source")
+	}
+}
@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"sort"
+)
+
+// GraphLink is a prerequisite edge of the graph file, from the prerequisite
+// to the technology it unlocks
+type GraphLink struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// SetGraph enables writing the graph file
+func (g *JSONGenerator) SetGraph(enabled bool) {
+	g.graph = enabled
+}
+
+// GraphFileName returns the file name of the nodes and links graph file
+func (g *JSONGenerator) GraphFileName() string {
+	return g.dataFileName(g.output.GraphFile)
+}
+
+// graphData returns the exported technologies as a nodes and links graph, as
+// used by d3-force and most graph libraries. Each node is the technology data
+// with its key as id, in research order. Links only connect exported
+// technologies.
+func (g *JSONGenerator) graphData(techsByArea map[string][]TechnologyJSON) GraphJSON {
+	nodes := []GraphNode{}
+	exported := make(map[string]bool)
+	for _, techs := range techsByArea {
+		for _, tech := range techs {
+			nodes = append(nodes, GraphNode{ID: tech.Key, TechnologyJSON: tech})
+			exported[tech.Key] = true
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Order < nodes[j].Order })
+
+	links := []GraphLink{}
+	for _, node := range nodes {
+		for _, prerequisite := range node.Prerequisites {
+			if exported[prerequisite] {
+				links = append(links, GraphLink{Source: prerequisite, Target: node.ID})
+			}
+		}
+	}
+
+	return GraphJSON{SchemaVersion: SchemaVersion, Nodes: nodes, Links: links}
+}
@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/danaketh/StellarisDataParser/lib/layout"
+	"github.com/danaketh/StellarisDataParser/lib/localization"
+)
+
+// viewerPage is the tech tree viewer; the technologies are embedded in place
+// of viewerPlaceholder
+//
+//go:embed viewer.html
+var viewerPage []byte
+
+const viewerPlaceholder = "/*TECH_TREE_DATA*/null"
+
+// viewerTechnology is a technology as embedded in the viewer, with the
+// fields it displays
+type viewerTechnology struct {
+	Key           string          `json:"key"`
+	Name          string          `json:"name"`
+	Description   string          `json:"description"`
+	Cost          int             `json:"cost"`
+	Area          string          `json:"area"`
+	Tier          int             `json:"tier"`
+	Category      string          `json:"category"`
+	Prerequisites []string        `json:"prerequisites"`
+	Position      layout.Position `json:"position"`
+	Icon          string          `json:"icon"` // Data URI of an embedded icon, or a path relative to the viewer
+	IsDangerous   bool            `json:"isDangerous"`
+	IsRare        bool            `json:"isRare"`
+	IsRepeatable  bool            `json:"isRepeatable"`
+}
+
+// viewerData is the data embedded in the viewer
+type viewerData struct {
+	Metadata     MetadataJSON       `json:"metadata"`
+	Technologies []viewerTechnology `json:"technologies"` // Sorted by key
+	HTMLText     bool               `json:"htmlText"`     // Names and descriptions contain HTML markup
+}
+
+// SetHTML enables writing the tech tree viewer, a self-contained HTML page
+// rendering the exported technologies
+func (g *JSONGenerator) SetHTML(enabled bool) {
+	g.html = enabled
+}
+
+// HTMLFileName returns the file name of the tech tree viewer
+func (g *JSONGenerator) HTMLFileName() string {
+	return g.output.HTMLFile
+}
+
+// viewerData returns the exported technologies of each area as embedded in
+// the viewer, with icon paths relative to the viewer's directory
+func (g *JSONGenerator) viewerData(techsByArea map[string][]TechnologyJSON) viewerData {
+	iconsDir := filepath.ToSlash(g.output.IconsDir)
+	if rel, err := filepath.Rel(filepath.Dir(g.HTMLFileName()), g.output.IconsDir); err == nil {
+		iconsDir = filepath.ToSlash(rel)
+	}
+
+	technologies := []viewerTechnology{}
+	for _, techs := range techsByArea {
+		for _, tech := range techs {
+			icon := tech.IconData
+			if icon == "" && tech.Icon != "" {
+				icon = path.Join(iconsDir, tech.IconFile)
+			}
+			technologies = append(technologies, viewerTechnology{
+				Key:           tech.Key,
+				Name:          tech.Name,
+				Description:   tech.Description,
+				Cost:          tech.Cost,
+				Area:          tech.Area,
+				Tier:          tech.Tier,
+				Category:      tech.Category,
+				Prerequisites: tech.Prerequisites,
+				Position:      tech.Position,
+				Icon:          icon,
+				IsDangerous:   tech.IsDangerous,
+				IsRare:        tech.IsRare,
+				IsRepeatable:  tech.IsRepeatable,
+			})
+		}
+	}
+	sort.Slice(technologies, func(i, j int) bool { return technologies[i].Key < technologies[j].Key })
+
+	return viewerData{
+		Metadata:     g.Metadata(),
+		Technologies: technologies,
+		HTMLText:     g.colorMode == localization.ColorHTML || g.iconTokenMode == localization.IconTokenHTML,
+	}
+}
+
+// writeHTML writes the tech tree viewer. The data is embedded as JSON, which
+// escapes <, > and &, so it can't close the script element.
+func (g *JSONGenerator) writeHTML(outputDir string, techsByArea map[string][]TechnologyJSON) error {
+	if !g.html {
+		return nil
+	}
+	data, err := json.Marshal(g.viewerData(techsByArea))
+	if err != nil {
+		return fmt.Errorf("failed to encode tech tree viewer data: %w", err)
+	}
+	htmlPath, err := prepareOutputPath(outputDir, g.HTMLFileName())
+	if err != nil {
+		return fmt.Errorf("failed to create tech tree viewer directory: %w", err)
+	}
+	content := bytes.Replace(viewerPage, []byte(viewerPlaceholder), data, 1)
+	if err := g.writeFile(htmlPath, content); err != nil {
+		return fmt.Errorf("failed to write tech tree viewer: %w", err)
+	}
+	return nil
+}
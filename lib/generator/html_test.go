@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/config"
+)
+
+func TestGenerateHTML(t *testing.T) {
+	techTree := createTestTree()
+	node, _ := techTree.GetNode("tech_test_1")
+	node.Tech.Description = "Breaks </script> out"
+	node.Tech.Icon = "tech_test_1"
+
+	output := config.Default().Output
+	output.HTMLFile = "viewer/tree.html"
+	generator := NewJSONGenerator(techTree)
+	generator.SetOutputConfig(output)
+	generator.SetHTML(true)
+	outputDir := t.TempDir()
+	if err := generator.GenerateJSONFiles(outputDir); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "viewer", "tree.html"))
+	if err != nil {
+		t.Fatalf("Failed to read viewer: %v", err)
+	}
+	page := string(content)
+	if strings.Contains(page, viewerPlaceholder) {
+		t.Fatal("Expected the data placeholder to be replaced")
+	}
+	if strings.Count(page, "</script>") != 2 {
+		t.Errorf("Expected descriptions to be escaped inside the script element")
+	}
+
+	start := strings.Index(page, `type="application/json">`) + len(`type="application/json">`)
+	end := strings.Index(page[start:], "</script>")
+	var data viewerData
+	if err := json.Unmarshal([]byte(page[start:start+end]), &data); err != nil {
+		t.Fatalf("Failed to parse embedded data: %v", err)
+	}
+	if len(data.Technologies) != 3 || data.Technologies[0].Key != "tech_test_1" {
+		t.Fatalf("Expected the 3 technologies sorted by key, got %+v", data.Technologies)
+	}
+	if icon := data.Technologies[0].Icon; icon != "../icons/tech_test_1.png" {
+		t.Errorf("Expected the icon relative to the viewer, got %q", icon)
+	}
+	if data.Technologies[0].Description != "Breaks </script> out" {
+		t.Errorf("Expected the description to round-trip, got %q", data.Technologies[0].Description)
+	}
+	if len(data.Metadata.Areas) == 0 || data.HTMLText {
+		t.Errorf("Expected metadata and plain text, got %+v", data)
+	}
+}
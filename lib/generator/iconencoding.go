@@ -0,0 +1,202 @@
+package generator
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Compression levels of converted icons
+const (
+	IconCompressionDefault = "default"
+	IconCompressionNone    = "none"
+	IconCompressionSpeed   = "speed"
+	IconCompressionBest    = "best"
+)
+
+// IconCompressionLevels lists the valid compression levels
+var IconCompressionLevels = []string{IconCompressionDefault, IconCompressionNone, IconCompressionSpeed, IconCompressionBest}
+
+// MaxIconColors is the largest palette icons can be reduced to
+const MaxIconColors = 256
+
+// IconEncoding controls how converted icons are encoded. The zero value
+// writes full color icons with default compression and no size variants, and
+// copies PNG icons of the game files as they are.
+type IconEncoding struct {
+	Sizes       []int  // Sizes in pixels of the variants written to <iconsDir>/<size>/
+	Colors      int    // Icons are reduced to a palette of at most this many colors, 0 keeps full color
+	Compression string // One of IconCompressionLevels, the default if empty
+}
+
+// reencodes reports whether icons are decoded and encoded again rather than
+// copied
+func (e IconEncoding) reencodes() bool {
+	return len(e.Sizes) > 0 || e.Colors > 0 || (e.Compression != "" && e.Compression != IconCompressionDefault)
+}
+
+// fingerprint returns the options as part of the fingerprint of converted
+// icons, so changing them converts the icons again. The default options have
+// none, which keeps the fingerprints of earlier runs.
+func (e IconEncoding) fingerprint() string {
+	if !e.reencodes() {
+		return ""
+	}
+	sizes := make([]string, len(e.Sizes))
+	for i, size := range e.Sizes {
+		sizes[i] = strconv.Itoa(size)
+	}
+	return fmt.Sprintf("sizes=%s colors=%d compression=%s", strings.Join(sizes, ","), e.Colors, e.Compression)
+}
+
+// encoder returns the PNG encoder of the compression level
+func (e IconEncoding) encoder() *png.Encoder {
+	levels := map[string]png.CompressionLevel{
+		IconCompressionNone:  png.NoCompression,
+		IconCompressionSpeed: png.BestSpeed,
+		IconCompressionBest:  png.BestCompression,
+	}
+	return &png.Encoder{CompressionLevel: levels[e.Compression]}
+}
+
+// SetEncoding sets how converted icons are encoded and the size variants
+// written along with them
+func (ic *IconConverter) SetEncoding(encoding IconEncoding) {
+	ic.encoding = encoding
+}
+
+// variantPaths returns the paths of the size variants of an icon written to
+// outputPath: the same path below <iconsDir>/<size>/
+func (ic *IconConverter) variantPaths(outputPath string) []string {
+	iconsDir := filepath.Join(ic.outputDir, ic.iconsDir)
+	rel, err := filepath.Rel(iconsDir, outputPath)
+	if err != nil || len(ic.encoding.Sizes) == 0 {
+		return nil
+	}
+	paths := make([]string, len(ic.encoding.Sizes))
+	for i, size := range ic.encoding.Sizes {
+		paths[i] = filepath.Join(iconsDir, strconv.Itoa(size), rel)
+	}
+	return paths
+}
+
+// writeVariants writes the icon at outputPath and its size variants. SVG icons
+// are copied to each path, other icons are decoded and encoded with the
+// configured palette and compression.
+func (ic *IconConverter) writeVariants(source iconFile, outputPath string) error {
+	paths := append([]string{outputPath}, ic.variantPaths(outputPath)...)
+	if strings.EqualFold(filepath.Ext(source.name), ".svg") {
+		for _, path := range paths {
+			if err := ic.copyFile(source, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	file, err := source.open()
+	if err != nil {
+		return fmt.Errorf("failed to open source file: %w", err)
+	}
+	img, format, err := image.Decode(file)
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to decode image (format: %s): %w", format, err)
+	}
+
+	if err := ic.encodeFile(img, outputPath); err != nil {
+		return err
+	}
+	for i, size := range ic.encoding.Sizes {
+		if err := ic.encodeFile(downscale(img, size), paths[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeFile writes an image as PNG with the configured palette and
+// compression
+func (ic *IconConverter) encodeFile(img image.Image, outputPath string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	outputFile, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer outputFile.Close()
+	return ic.encode(outputFile, img)
+}
+
+// encode writes an image as PNG with the configured palette and compression
+func (ic *IconConverter) encode(w io.Writer, img image.Image) error {
+	if ic.encoding.Colors > 0 {
+		img = reducePalette(img, ic.encoding.Colors)
+	}
+	if err := ic.encoding.encoder().Encode(w, img); err != nil {
+		return fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return nil
+}
+
+// reducePalette returns an image with at most colors colors. The palette
+// holds the most frequent colors, with similar colors averaged, and each
+// pixel gets the nearest palette color.
+func reducePalette(img image.Image, colors int) *image.Paletted {
+	type bucket struct {
+		r, g, b, a, count uint64
+	}
+	// Group colors by the top 5 bits of each channel
+	buckets := make(map[uint32]*bucket)
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBA64Model.Convert(img.At(x, y)).(color.NRGBA64)
+			key := uint32(c.R>>11)<<15 | uint32(c.G>>11)<<10 | uint32(c.B>>11)<<5 | uint32(c.A>>11)
+			if buckets[key] == nil {
+				buckets[key] = &bucket{}
+			}
+			b := buckets[key]
+			b.r, b.g, b.b, b.a = b.r+uint64(c.R), b.g+uint64(c.G), b.b+uint64(c.B), b.a+uint64(c.A)
+			b.count++
+		}
+	}
+
+	keys := make([]uint32, 0, len(buckets))
+	for key := range buckets {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if buckets[keys[i]].count != buckets[keys[j]].count {
+			return buckets[keys[i]].count > buckets[keys[j]].count
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > colors {
+		keys = keys[:colors]
+	}
+
+	palette := make(color.Palette, len(keys))
+	for i, key := range keys {
+		b := buckets[key]
+		palette[i] = color.NRGBA64{
+			R: uint16(b.r / b.count),
+			G: uint16(b.g / b.count),
+			B: uint16(b.b / b.count),
+			A: uint16(b.a / b.count),
+		}
+	}
+
+	reduced := image.NewPaletted(image.Rect(0, 0, bounds.Dx(), bounds.Dy()), palette)
+	draw.Draw(reduced, reduced.Bounds(), img, bounds.Min, draw.Src)
+	return reduced
+}
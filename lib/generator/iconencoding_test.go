@@ -0,0 +1,91 @@
+package generator
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/manifest"
+)
+
+func TestReducePalette(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	for x := 0; x < 16; x++ {
+		for y := 0; y < 16; y++ {
+			src.Set(x, y, color.NRGBA{uint8(x * 16), uint8(y * 16), 0, 255})
+		}
+	}
+
+	reduced := reducePalette(src, 8)
+	if len(reduced.Palette) != 8 {
+		t.Fatalf("Expected 8 colors, got %d", len(reduced.Palette))
+	}
+	if reduced.Bounds().Size() != src.Bounds().Size() {
+		t.Errorf("Expected the size to be kept, got %v", reduced.Bounds().Size())
+	}
+
+	few := reducePalette(image.NewNRGBA(image.Rect(0, 0, 4, 4)), 8)
+	if len(few.Palette) != 1 {
+		t.Errorf("Expected a palette of the colors in use, got %d colors", len(few.Palette))
+	}
+}
+
+func TestConvertIconVariants(t *testing.T) {
+	gameDir := t.TempDir()
+	iconPath := filepath.Join(gameDir, "gfx", "interface", "icons", "technologies", "tech_icon.png")
+	if err := os.MkdirAll(filepath.Dir(iconPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	iconFile, err := os.Create(iconPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(iconFile, image.NewRGBA(image.Rect(0, 0, 52, 52))); err != nil {
+		t.Fatal(err)
+	}
+	iconFile.Close()
+
+	outputDir := t.TempDir()
+	current := manifest.New()
+	converter := NewIconConverter(gameDir, outputDir)
+	converter.SetManifests(nil, current)
+	converter.SetEncoding(IconEncoding{Sizes: []int{24, 32}, Colors: 16, Compression: IconCompressionBest})
+	if err := converter.ConvertIcon("tech_icon"); err != nil {
+		t.Fatalf("Failed to convert icon: %v", err)
+	}
+
+	for path, size := range map[string]int{"tech_icon.png": 52, "24/tech_icon.png": 24, "32/tech_icon.png": 32} {
+		file, err := os.Open(filepath.Join(outputDir, "icons", filepath.FromSlash(path)))
+		if err != nil {
+			t.Fatalf("Expected %s: %v", path, err)
+		}
+		img, err := png.Decode(file)
+		file.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if img.Bounds().Dx() != size {
+			t.Errorf("Expected %s to be %d pixels wide, got %d", path, size, img.Bounds().Dx())
+		}
+		if _, paletted := img.(*image.Paletted); !paletted {
+			t.Errorf("Expected %s to be paletted, got %T", path, img)
+		}
+		if current.Files["icons/"+path] == "" {
+			t.Errorf("Expected %s in the manifest", path)
+		}
+	}
+
+	// Changing the options converts the icons again
+	plain := NewIconConverter(gameDir, t.TempDir())
+	plainManifest := manifest.New()
+	plain.SetManifests(nil, plainManifest)
+	if err := plain.ConvertIcon("tech_icon"); err != nil {
+		t.Fatal(err)
+	}
+	if plainManifest.Files["icons/tech_icon.png"] == current.Files["icons/tech_icon.png"] {
+		t.Error("Expected the encoding options to change the fingerprint")
+	}
+}
@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// IconOverrideExtensions lists the file types accepted in the icon override
+// directory, in order of preference
+var IconOverrideExtensions = []string{".png", ".svg"}
+
+// findIconOverride returns the override file for name in dir, or an empty
+// string if there is none
+func findIconOverride(dir, name string) string {
+	if dir == "" || name == "" {
+		return ""
+	}
+	for _, ext := range IconOverrideExtensions {
+		path := filepath.Join(dir, name+ext)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// resolveIcon returns the name a technology's icon is exported under and the
+// override file replacing the game icon, if any. An override named after the
+// technology key takes precedence over one named after the icon and gives the
+// technology an icon of its own.
+func (g *JSONGenerator) resolveIcon(tech *models.Technology) (name, override string) {
+	if override := findIconOverride(g.iconOverrides, tech.Key); override != "" {
+		return tech.Key, override
+	}
+	return tech.Icon, findIconOverride(g.iconOverrides, tech.Icon)
+}
+
+// iconFileName returns the file name of an exported icon in the icon
+// directory: PNG unless an SVG override replaces it
+func iconFileName(name, override string) string {
+	if override != "" {
+		return name + filepath.Ext(override)
+	}
+	return name + ".png"
+}
@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+func TestResolveIcon(t *testing.T) {
+	overridesDir := t.TempDir()
+	keyOverride := touch(t, overridesDir, "tech_keyed.svg")
+	touch(t, overridesDir, "icon_shared.svg")
+	iconOverride := touch(t, overridesDir, "icon_shared.png")
+
+	g := NewJSONGenerator(nil)
+	tests := []struct {
+		tech     *models.Technology
+		name     string
+		override string
+		file     string
+	}{
+		{&models.Technology{Key: "tech_keyed", Icon: "icon_shared"}, "tech_keyed", keyOverride, "tech_keyed.svg"},
+		{&models.Technology{Key: "tech_other", Icon: "icon_shared"}, "icon_shared", iconOverride, "icon_shared.png"},
+		{&models.Technology{Key: "tech_plain", Icon: "icon_plain"}, "icon_plain", "", "icon_plain.png"},
+	}
+
+	for _, tt := range tests {
+		g.SetIconOverrides("")
+		if name, override := g.resolveIcon(tt.tech); name != tt.tech.Icon || override != "" {
+			t.Errorf("%s: expected the game icon without overrides, got %s %q", tt.tech.Key, name, override)
+		}
+
+		g.SetIconOverrides(overridesDir)
+		name, override := g.resolveIcon(tt.tech)
+		if name != tt.name || override != tt.override {
+			t.Errorf("%s: expected %s %q, got %s %q", tt.tech.Key, tt.name, tt.override, name, override)
+		}
+		if file := iconFileName(name, override); file != tt.file {
+			t.Errorf("%s: expected file %s, got %s", tt.tech.Key, tt.file, file)
+		}
+	}
+}
+
+func TestCopyOverrides(t *testing.T) {
+	overridesDir := t.TempDir()
+	svg := filepath.Join(overridesDir, "tech_keyed.svg")
+	if err := os.WriteFile(svg, []byte("<svg/>"), 0644); err != nil {
+		t.Fatalf("Failed to write override: %v", err)
+	}
+
+	outputDir := t.TempDir()
+	converter := NewIconConverter(t.TempDir(), outputDir)
+	copied, err := converter.CopyOverrides(map[string]string{"tech_keyed": svg})
+	if err != nil || copied != 1 {
+		t.Fatalf("Expected 1 copied override, got %d (%v)", copied, err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "icons", "tech_keyed.svg"))
+	if err != nil || string(content) != "<svg/>" {
+		t.Errorf("Expected the SVG to be copied as is, got %q (%v)", content, err)
+	}
+}
@@ -1,78 +1,487 @@
 package generator
 
 import (
+	"context"
 	"fmt"
 	"image"
 	_ "image/jpeg" // Register JPEG format
 	"image/png"
 	"io"
+	"io/fs"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 
-	_ "github.com/lukegb/dds" // Register DDS format
+	"github.com/danaketh/StellarisDataParser/lib/charset"
+	"github.com/danaketh/StellarisDataParser/lib/config"
+	_ "github.com/danaketh/StellarisDataParser/lib/dds" // Register DDS format
+	"github.com/danaketh/StellarisDataParser/lib/manifest"
+	"github.com/danaketh/StellarisDataParser/lib/progress"
+	_ "github.com/danaketh/StellarisDataParser/lib/tga" // Register TGA format
 )
 
-// IconConverter handles conversion of DDS icons to PNG format
+// IconConverter handles conversion of DDS and TGA icons to PNG format
 type IconConverter struct {
-	gameDir   string
+	sources   []iconSource // Game directory followed by the DLCs and the mods, in load order
+	dlcs      int          // Number of DLC sources after the game directory
 	outputDir string
+	iconsDir  string             // Icon directory relative to outputDir
+	since     *manifest.Manifest // Icons unchanged since this manifest are skipped
+	manifest  *manifest.Manifest // Records the fingerprint of each icon when set
+	skipped   int                // Number of icons skipped because they were unchanged
+	failed    int                // Number of icons ConvertIcons couldn't convert
+	missing   []MissingIcon      // Icons ConvertIcons couldn't find or convert, in order
+	progress  *progress.Reporter
+	// Icon lookup order: each directory (relative to the game directory) is
+	// searched for each extension in turn
+	searchDirs []string
+	extensions []string
+	sprites    map[string]string // GFX_ sprite name -> texture file, loaded on first use
+	encoding   IconEncoding
 }
 
-// NewIconConverter creates a new icon converter
+// iconSource is the game directory or a mod that icons are looked up in
+type iconSource struct {
+	fsys fs.FS
+	path string // Directory or archive, to show the paths of its files
+}
+
+// iconFile is an icon in the game files, a mod or an override directory
+type iconFile struct {
+	fsys fs.FS
+	name string // Slash-separated path in fsys
+	path string // Path shown in errors
+}
+
+// osFile returns the iconFile of an operating system path
+func osFile(path string) iconFile {
+	return iconFile{fsys: os.DirFS(filepath.Dir(path)), name: filepath.Base(path), path: path}
+}
+
+// open opens the icon
+func (f iconFile) open() (fs.File, error) {
+	return f.fsys.Open(f.name)
+}
+
+// SpritePrefix marks icons referring to a sprite defined in the interface
+// .gfx files rather than to a file name
+const SpritePrefix = "GFX_"
+
+// NewIconConverter creates a new icon converter using the default lookup
+// order
 func NewIconConverter(gameDir, outputDir string) *IconConverter {
+	defaults := config.Default().Icons
 	return &IconConverter{
-		gameDir:   gameDir,
-		outputDir: outputDir,
+		sources:    []iconSource{{fsys: os.DirFS(gameDir), path: gameDir}},
+		outputDir:  outputDir,
+		iconsDir:   "icons",
+		searchDirs: defaults.SearchDirs,
+		extensions: defaults.Extensions,
 	}
 }
 
-// ConvertIcon converts a single icon from DDS to PNG
-// iconName is the base name without extension (e.g., "tech_lasers")
-func (ic *IconConverter) ConvertIcon(iconName string) error {
-	// Look for the icon in multiple locations
-	possiblePaths := []string{
-		filepath.Join(ic.gameDir, "gfx", "interface", "icons", "technologies", iconName+".dds"),
-		filepath.Join(ic.gameDir, "gfx", "interface", "icons", "technologies", iconName+".png"),
-		filepath.Join(ic.gameDir, "gfx", "interface", "icons", "technologies", iconName+".jpg"),
+// AddMod adds the files of a mod, such as a directory or .zip archive opened
+// with gamefs.Open. Icons and sprites of mods added later replace those of
+// earlier mods and of the game. path is the mod's location, shown in errors.
+func (ic *IconConverter) AddMod(fsys fs.FS, path string) {
+	ic.sources = append(ic.sources, iconSource{fsys: fsys, path: path})
+	ic.sprites = nil
+}
+
+// AddDLC adds the files of a DLC archive opened with gamefs.OpenZip, such as
+// one of gamefs.DLCArchives. Icons and sprites of DLCs replace those of the
+// game, and mods replace those of DLCs whether they were added before or
+// after. path is the archive's location, shown in errors.
+func (ic *IconConverter) AddDLC(fsys fs.FS, path string) {
+	index := 1 + ic.dlcs
+	ic.sources = slices.Insert(ic.sources, index, iconSource{fsys: fsys, path: path})
+	ic.dlcs++
+	ic.sprites = nil
+}
+
+// SetSearchOrder sets the directories (relative to the game directory) and
+// extensions icons are looked up in. The first existing file wins.
+func (ic *IconConverter) SetSearchOrder(searchDirs, extensions []string) {
+	ic.searchDirs = searchDirs
+	ic.extensions = extensions
+}
+
+// SetIconsDir sets the icon directory, relative to the output directory
+func (ic *IconConverter) SetIconsDir(iconsDir string) {
+	ic.iconsDir = iconsDir
+}
+
+// SetProgress sets the reporter that receives an event for every icon
+func (ic *IconConverter) SetProgress(reporter *progress.Reporter) {
+	ic.progress = reporter
+}
+
+// SetManifests enables incremental conversion: the fingerprint of each icon's
+// source is recorded in current, and icons whose fingerprint matches since
+// are not written
+func (ic *IconConverter) SetManifests(since, current *manifest.Manifest) {
+	ic.since = since
+	ic.manifest = current
+}
+
+// unchanged records the fingerprint of an output file and reports whether it
+// is unchanged since the previous manifest
+func (ic *IconConverter) unchanged(outputPath, fingerprint string) bool {
+	if ic.manifest == nil {
+		return false
 	}
+	name := manifestName(ic.outputDir, outputPath)
+	ic.manifest.Set(name, fingerprint)
+	return ic.since.Unchanged(name, fingerprint)
+}
 
-	var sourcePath string
-	var sourceExt string
-	for _, path := range possiblePaths {
-		if _, err := os.Stat(path); err == nil {
-			sourcePath = path
-			sourceExt = filepath.Ext(path)
-			break
-		}
+// unchangedVariants is unchanged for an output file and its size variants,
+// which are unchanged only if all of them are
+func (ic *IconConverter) unchangedVariants(outputPath, fingerprint string) bool {
+	unchanged := ic.unchanged(outputPath, fingerprint)
+	for _, path := range ic.variantPaths(outputPath) {
+		unchanged = ic.unchanged(path, fingerprint) && unchanged
 	}
+	return unchanged
+}
+
+// iconOutputPath returns the path a converted icon is written to
+func (ic *IconConverter) iconOutputPath(iconName string) string {
+	return filepath.Join(ic.outputDir, ic.iconsDir, iconName+".png")
+}
 
-	if sourcePath == "" {
+// ConvertIcon converts a single icon from DDS or TGA to PNG
+// iconName is the base name without extension (e.g., "tech_lasers")
+func (ic *IconConverter) ConvertIcon(iconName string) error {
+	source, found := ic.sourceFile(iconName)
+	if !found {
 		// Icon file not found - this is not necessarily an error
 		// as some mods or DLCs might be missing
 		return nil
 	}
 
-	// If already PNG or JPG, just copy it
-	outputPath := filepath.Join(ic.outputDir, "icons", iconName+".png")
-	if sourceExt == ".png" || sourceExt == ".jpg" {
-		return ic.copyFile(sourcePath, outputPath)
+	return ic.convertFile(source, ic.iconOutputPath(iconName))
+}
+
+// Locations of the resource icons referenced as £energy£ in localized strings
+const (
+	ResourceIconsDir       = "gfx/interface/icons/resources" // Relative to the game directory
+	ResourceIconsOutputDir = "resources"                     // Relative to the icon output directory
+)
+
+// ConvertResourceIcons converts the resource icons referenced from localized
+// strings into the resources subdirectory of the icon directory and returns
+// the number of icons written or unchanged. Missing icons are skipped.
+func (ic *IconConverter) ConvertResourceIcons(names []string) (int, error) {
+	converted := 0
+	errors := []string{}
+
+	for _, name := range names {
+		source, found := ic.findFile([]string{ResourceIconsDir}, name)
+		if !found {
+			continue
+		}
+		outputPath := filepath.Join(ic.outputDir, ic.iconsDir, ResourceIconsOutputDir, name+".png")
+		if err := ic.convertFile(source, outputPath); err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		converted++
+	}
+
+	if len(errors) > 0 {
+		return converted, fmt.Errorf("failed to convert some resource icons:\n%s", strings.Join(errors, "\n"))
+	}
+	return converted, nil
+}
+
+// AreaSpriteName returns the name of the sprite the game's .gfx files define
+// for the icon of a research area, e.g. GFX_research_physics
+func AreaSpriteName(area string) string {
+	return SpritePrefix + "research_" + area
+}
+
+// ConvertAreaIcons converts the icons of research areas into the areas
+// subdirectory of the icon directory as <area>.png and returns the number of
+// icons written or unchanged. An area's icon is the texture of its
+// GFX_research_<area> sprite, or else its <area>_research resource icon;
+// missing icons are skipped.
+func (ic *IconConverter) ConvertAreaIcons(areas []string) (int, error) {
+	converted := 0
+	errors := []string{}
+
+	for _, area := range areas {
+		source, found := ic.areaFile(area)
+		if !found {
+			continue
+		}
+		outputPath := filepath.Join(ic.outputDir, ic.iconsDir, AreaIconsOutputDir, area+".png")
+		if err := ic.convertFile(source, outputPath); err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", area, err))
+			continue
+		}
+		converted++
+	}
+
+	if len(errors) > 0 {
+		return converted, fmt.Errorf("failed to convert some area icons:\n%s", strings.Join(errors, "\n"))
 	}
+	return converted, nil
+}
+
+// areaFile returns the icon file of a research area and whether one exists
+func (ic *IconConverter) areaFile(area string) (iconFile, bool) {
+	if texture, ok := ic.spriteTextures()[AreaSpriteName(area)]; ok {
+		if file, found := ic.gameFile(texture); found {
+			return file, true
+		}
+	}
+	return ic.findFile([]string{ResourceIconsDir}, AreaIconName(area))
+}
+
+// Directories of research area, category and relic icons, relative to the
+// icon output directory
+const (
+	AreaIconsOutputDir     = "areas"
+	CategoryIconsOutputDir = "categories"
+	RelicIconsOutputDir    = "relics"
+)
 
-	// Convert DDS to PNG
-	return ic.convertDDSToPNG(sourcePath, outputPath)
+// ConvertCategoryIcons converts the icons of research categories, given by
+// category key, into the categories subdirectory of the icon directory and
+// returns the number of icons written or unchanged. An icon is a texture path
+// relative to the game directory or a GFX_ sprite name; missing icons are
+// skipped.
+func (ic *IconConverter) ConvertCategoryIcons(icons map[string]string) (int, error) {
+	return ic.convertKeyedIcons(icons, CategoryIconsOutputDir, "category")
 }
 
-// convertDDSToPNG converts a DDS file to PNG format
-func (ic *IconConverter) convertDDSToPNG(sourcePath, outputPath string) error {
+// ConvertRelicIcons converts the art of relics, given by relic key, into the
+// relics subdirectory of the icon directory like ConvertCategoryIcons
+func (ic *IconConverter) ConvertRelicIcons(icons map[string]string) (int, error) {
+	return ic.convertKeyedIcons(icons, RelicIconsOutputDir, "relic")
+}
+
+// convertKeyedIcons converts icons given by key as <dir>/<key>.png. kind
+// names the icons in errors.
+func (ic *IconConverter) convertKeyedIcons(icons map[string]string, dir, kind string) (int, error) {
+	keys := make([]string, 0, len(icons))
+	for key := range icons {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	converted := 0
+	errors := []string{}
+	for _, key := range keys {
+		var source iconFile
+		var found bool
+		if strings.HasPrefix(icons[key], SpritePrefix) {
+			source, found = ic.sourceFile(icons[key])
+		} else {
+			source, found = ic.gameFile(icons[key])
+		}
+		if !found {
+			continue
+		}
+
+		outputPath := filepath.Join(ic.outputDir, ic.iconsDir, dir, key+".png")
+		if err := ic.convertFile(source, outputPath); err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		converted++
+	}
+
+	if len(errors) > 0 {
+		return converted, fmt.Errorf("failed to convert some %s icons:\n%s", kind, strings.Join(errors, "\n"))
+	}
+	return converted, nil
+}
+
+// convertFile writes a source icon as PNG to outputPath, unless it is
+// unchanged since the previous manifest
+func (ic *IconConverter) convertFile(source iconFile, outputPath string) error {
+	if ic.manifest != nil {
+		fingerprint, err := fingerprintFile(source)
+		if err != nil {
+			return fmt.Errorf("failed to read source file: %w", err)
+		}
+		if encoding := ic.encoding.fingerprint(); encoding != "" {
+			fingerprint = manifest.Fingerprint([]byte(fingerprint), []byte(encoding))
+		}
+		if ic.unchangedVariants(outputPath, fingerprint) {
+			ic.skipped++
+			return nil
+		}
+	}
+
+	// Icons are decoded to resize, reduce or recompress them
+	if ic.encoding.reencodes() {
+		return ic.writeVariants(source, outputPath)
+	}
+
+	// If already PNG, JPG or SVG, just copy it
+	sourceExt := strings.ToLower(path.Ext(source.name))
+	if sourceExt == ".png" || sourceExt == ".jpg" || sourceExt == ".svg" {
+		return ic.copyFile(source, outputPath)
+	}
+
+	// Convert DDS and TGA to PNG
+	return ic.convertToPNG(source, outputPath)
+}
+
+// fingerprintFile returns the fingerprint of a source icon's content
+func fingerprintFile(source iconFile) (string, error) {
+	file, err := source.open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	return manifest.FingerprintReader(file)
+}
+
+// CopyOverrides copies override files into the icon directory, keyed by the
+// icon name they are exported under, and returns the number of icons written
+// or unchanged. SVG overrides keep their extension.
+func (ic *IconConverter) CopyOverrides(overrides map[string]string) (int, error) {
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	copied := 0
+	errors := []string{}
+	for _, name := range names {
+		outputPath := filepath.Join(ic.outputDir, ic.iconsDir, iconFileName(name, overrides[name]))
+		if err := ic.convertFile(osFile(overrides[name]), outputPath); err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		copied++
+	}
+
+	if len(errors) > 0 {
+		return copied, fmt.Errorf("failed to copy some icon overrides:\n%s", strings.Join(errors, "\n"))
+	}
+	return copied, nil
+}
+
+// sourceFile returns an icon in the game files or the mods, and whether it
+// exists. Icons named GFX_... are looked up in the sprite definitions first
+// and otherwise by the name without the prefix.
+func (ic *IconConverter) sourceFile(iconName string) (iconFile, bool) {
+	if strings.HasPrefix(iconName, SpritePrefix) {
+		if texture, ok := ic.spriteTextures()[iconName]; ok {
+			if file, found := ic.gameFile(texture); found {
+				return file, true
+			}
+		}
+		iconName = strings.TrimPrefix(iconName, SpritePrefix)
+	}
+
+	return ic.findFile(ic.searchDirs, iconName)
+}
+
+// findFile returns the first existing file named name plus one of the
+// configured extensions in dirs (relative to the game directory), and
+// whether one exists. Sources are searched from the last mod back to the
+// game, so a mod's icon replaces the game's whatever its extension.
+func (ic *IconConverter) findFile(dirs []string, name string) (iconFile, bool) {
+	for i := len(ic.sources) - 1; i >= 0; i-- {
+		for _, dir := range dirs {
+			for _, ext := range ic.extensions {
+				if file, found := ic.sources[i].file(path.Join(dir, name+ext)); found {
+					return file, true
+				}
+			}
+		}
+	}
+	return iconFile{}, false
+}
+
+// gameFile returns the file at a slash-separated path relative to the game
+// directory from the last mod that has it, or else from the game
+func (ic *IconConverter) gameFile(name string) (iconFile, bool) {
+	for i := len(ic.sources) - 1; i >= 0; i-- {
+		if file, found := ic.sources[i].file(name); found {
+			return file, true
+		}
+	}
+	return iconFile{}, false
+}
+
+// file returns the file at a slash-separated path relative to the root of
+// the source, and whether it exists
+func (source iconSource) file(name string) (iconFile, bool) {
+	name = path.Clean(strings.TrimPrefix(name, "/"))
+	if info, err := fs.Stat(source.fsys, name); err == nil && !info.IsDir() {
+		return iconFile{fsys: source.fsys, name: name, path: filepath.Join(source.path, filepath.FromSlash(name))}, true
+	}
+	return iconFile{}, false
+}
+
+var (
+	spriteTypePattern    = regexp.MustCompile(`(?s)spriteType\s*=\s*\{(.*?)\}`)
+	spriteNamePattern    = regexp.MustCompile(`name\s*=\s*"?(` + SpritePrefix + `\w+)"?`)
+	spriteTexturePattern = regexp.MustCompile(`texturefile\s*=\s*"([^"]+)"`)
+)
+
+// spriteTextures returns the texture file of each sprite defined in the
+// .gfx files below the interface directory of the game and the mods. Sprites
+// of later mods replace earlier definitions.
+func (ic *IconConverter) spriteTextures() map[string]string {
+	if ic.sprites != nil {
+		return ic.sprites
+	}
+
+	ic.sprites = make(map[string]string)
+	for _, source := range ic.sources {
+		maps.Copy(ic.sprites, SpriteTextures(source.fsys))
+	}
+	return ic.sprites
+}
+
+// SpriteTextures returns the texture file of each icon sprite defined in the
+// .gfx files below the interface directory of fsys, by sprite name
+func SpriteTextures(fsys fs.FS) map[string]string {
+	sprites := make(map[string]string)
+	fs.WalkDir(fsys, "interface", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.EqualFold(path.Ext(name), ".gfx") {
+			return nil
+		}
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil
+		}
+		for _, block := range spriteTypePattern.FindAllStringSubmatch(string(charset.Decode(content)), -1) {
+			name := spriteNamePattern.FindStringSubmatch(block[1])
+			texture := spriteTexturePattern.FindStringSubmatch(block[1])
+			if name != nil && texture != nil {
+				sprites[name[1]] = texture[1]
+			}
+		}
+		return nil
+	})
+	return sprites
+}
+
+// convertToPNG converts a DDS or TGA file to PNG format
+func (ic *IconConverter) convertToPNG(source iconFile, outputPath string) error {
 	// Open source file
-	sourceFile, err := os.Open(sourcePath)
+	sourceFile, err := source.open()
 	if err != nil {
 		return fmt.Errorf("failed to open source file: %w", err)
 	}
 	defer sourceFile.Close()
 
-	// Decode image (DDS decoder is registered)
+	// Decode image (DDS and TGA decoders are registered)
 	img, format, err := image.Decode(sourceFile)
 	if err != nil {
 		return fmt.Errorf("failed to decode image (format: %s): %w", format, err)
@@ -100,14 +509,14 @@ func (ic *IconConverter) convertDDSToPNG(sourcePath, outputPath string) error {
 }
 
 // copyFile copies a file from src to dst
-func (ic *IconConverter) copyFile(src, dst string) error {
+func (ic *IconConverter) copyFile(src iconFile, dst string) error {
 	// Create output directory if needed
 	outputDir := filepath.Dir(dst)
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	sourceFile, err := os.Open(src)
+	sourceFile, err := src.open()
 	if err != nil {
 		return err
 	}
@@ -125,19 +534,33 @@ func (ic *IconConverter) copyFile(src, dst string) error {
 
 // ConvertIcons converts all icons for the given technology keys
 func (ic *IconConverter) ConvertIcons(iconNames []string) (int, error) {
+	return ic.ConvertIconsContext(context.Background(), iconNames)
+}
+
+// ConvertIconsContext is ConvertIcons with a context. When it is cancelled,
+// the remaining icons are skipped and the context's error is returned.
+func (ic *IconConverter) ConvertIconsContext(ctx context.Context, iconNames []string) (int, error) {
 	converted := 0
 	errors := []string{}
 
-	for _, iconName := range iconNames {
-		if err := ic.ConvertIcon(iconName); err != nil {
+	for i, iconName := range iconNames {
+		if err := ctx.Err(); err != nil {
+			return converted, err
+		}
+		ic.progress.Report(progress.StageIcons, i+1, len(iconNames), iconName)
+		source, found := ic.sourceFile(iconName)
+		if !found {
+			// Not necessarily an error, as some mods or DLCs might be missing
+			ic.missing = append(ic.missing, MissingIcon{Icon: iconName, Reason: MissingIconNotFound})
+			continue
+		}
+		if err := ic.convertFile(source, ic.iconOutputPath(iconName)); err != nil {
 			errors = append(errors, fmt.Sprintf("%s: %v", iconName, err))
-		} else {
-			// Check if file was actually created
-			outputPath := filepath.Join(ic.outputDir, "icons", iconName+".png")
-			if _, err := os.Stat(outputPath); err == nil {
-				converted++
-			}
+			ic.failed++
+			ic.missing = append(ic.missing, MissingIcon{Icon: iconName, Reason: MissingIconUnreadable, Error: err.Error()})
+			continue
 		}
+		converted++
 	}
 
 	if len(errors) > 0 {
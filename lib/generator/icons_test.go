@@ -0,0 +1,307 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+// touch creates an empty file below dir, including its directories
+func touch(t *testing.T, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestSourcePathSearchOrder(t *testing.T) {
+	gameDir := t.TempDir()
+	dds := touch(t, gameDir, "gfx/interface/icons/technologies/tech_a.dds")
+	png := touch(t, gameDir, "gfx/interface/icons/technologies/tech_a.png")
+	custom := touch(t, gameDir, "gfx/custom/tech_a.png")
+
+	converter := NewIconConverter(gameDir, t.TempDir())
+	if file, _ := converter.sourceFile("tech_a"); file.path != dds {
+		t.Errorf("Expected the DDS file by default, got %s", file.path)
+	}
+
+	converter.SetSearchOrder([]string{"gfx/interface/icons/technologies"}, []string{".png", ".dds"})
+	if file, _ := converter.sourceFile("tech_a"); file.path != png {
+		t.Errorf("Expected the PNG file first, got %s", file.path)
+	}
+
+	converter.SetSearchOrder([]string{"gfx/custom", "gfx/interface/icons/technologies"}, []string{".dds", ".png"})
+	if file, _ := converter.sourceFile("tech_a"); file.path != custom {
+		t.Errorf("Expected the first directory to win, got %s", file.path)
+	}
+
+	if file, found := converter.sourceFile("tech_missing"); found {
+		t.Errorf("Expected no file for a missing icon, got %s", file.path)
+	}
+}
+
+func TestSourcePathSprite(t *testing.T) {
+	gameDir := t.TempDir()
+	texture := touch(t, gameDir, "gfx/interface/icons/technologies/sprite_texture.dds")
+	stripped := touch(t, gameDir, "gfx/interface/icons/technologies/tech_plain.dds")
+
+	gfx := `spriteTypes = {
+	spriteType = {
+		name = "GFX_tech_sprite"
+		texturefile = "gfx/interface/icons/technologies/sprite_texture.dds"
+	}
+}
+`
+	if err := os.MkdirAll(filepath.Join(gameDir, "interface"), 0755); err != nil {
+		t.Fatalf("Failed to create interface directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(gameDir, "interface", "technologies.gfx"), []byte(gfx), 0644); err != nil {
+		t.Fatalf("Failed to write gfx file: %v", err)
+	}
+
+	converter := NewIconConverter(gameDir, t.TempDir())
+	if file, _ := converter.sourceFile("GFX_tech_sprite"); file.path != texture {
+		t.Errorf("Expected the sprite's texture file, got %s", file.path)
+	}
+	if file, _ := converter.sourceFile("GFX_tech_plain"); file.path != stripped {
+		t.Errorf("Expected the icon without the GFX_ prefix, got %s", file.path)
+	}
+}
+
+func TestConvertCategoryIcons(t *testing.T) {
+	gameDir := t.TempDir()
+	touch(t, gameDir, "gfx/interface/icons/research/particles.png")
+
+	outputDir := t.TempDir()
+	converter := NewIconConverter(gameDir, outputDir)
+	converted, err := converter.ConvertCategoryIcons(map[string]string{
+		"particles": "gfx/interface/icons/research/particles.png",
+		"computing": "gfx/interface/icons/research/missing.dds",
+	})
+	if err != nil || converted != 1 {
+		t.Fatalf("Expected 1 converted icon, got %d (%v)", converted, err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "icons", "categories", "particles.png")); err != nil {
+		t.Errorf("Expected the category icon to be written: %v", err)
+	}
+}
+
+func TestConvertRelicIcons(t *testing.T) {
+	gameDir := t.TempDir()
+	touch(t, gameDir, "gfx/interface/relics/relic_galaxy.png")
+	touch(t, gameDir, "interface/relics.gfx")
+	gfx := "spriteTypes = {\n\tspriteType = {\n\t\tname = \"GFX_relic_galaxy\"\n\t\ttexturefile = \"gfx/interface/relics/relic_galaxy.png\"\n\t}\n}\n"
+	if err := os.WriteFile(filepath.Join(gameDir, "interface", "relics.gfx"), []byte(gfx), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outputDir := t.TempDir()
+	converter := NewIconConverter(gameDir, outputDir)
+	converted, err := converter.ConvertRelicIcons(map[string]string{
+		"r_galaxy":  "GFX_relic_galaxy",
+		"r_missing": "GFX_relic_missing",
+	})
+	if err != nil || converted != 1 {
+		t.Fatalf("Expected 1 converted icon, got %d (%v)", converted, err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "icons", RelicIconFile("r_galaxy"))); err != nil {
+		t.Errorf("Expected the relic icon to be written: %v", err)
+	}
+}
+
+func TestConvertAreaIcons(t *testing.T) {
+	gameDir := t.TempDir()
+	sprite := touch(t, gameDir, "gfx/interface/icons/research/physics.png")
+	touch(t, gameDir, "gfx/interface/icons/resources/society_research.png")
+	gfx := "spriteTypes = {\n\tspriteType = {\n\t\tname = \"GFX_research_physics\"\n\t\ttexturefile = \"gfx/interface/icons/research/physics.png\"\n\t}\n}\n"
+	if err := os.WriteFile(touch(t, gameDir, "interface/research.gfx"), []byte(gfx), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outputDir := t.TempDir()
+	converter := NewIconConverter(gameDir, outputDir)
+	if file, _ := converter.areaFile("physics"); file.path != sprite {
+		t.Errorf("Expected the area sprite's texture, got %s", file.path)
+	}
+	converted, err := converter.ConvertAreaIcons([]string{"physics", "society", "engineering"})
+	if err != nil || converted != 2 {
+		t.Fatalf("Expected 2 converted icons, got %d (%v)", converted, err)
+	}
+	for _, area := range []string{"physics", "society"} {
+		if _, err := os.Stat(filepath.Join(outputDir, "icons", "areas", area+".png")); err != nil {
+			t.Errorf("Expected the %s icon to be written: %v", area, err)
+		}
+	}
+}
+
+func TestConvertTGAIcon(t *testing.T) {
+	gameDir := t.TempDir()
+	// A 1x1 uncompressed true-color TGA with a red pixel
+	tga := []byte{0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 1, 0, 24, 0, 0, 0, 255}
+	iconPath := touch(t, gameDir, "gfx/interface/icons/technologies/tech_mod.tga")
+	if err := os.WriteFile(iconPath, tga, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outputDir := t.TempDir()
+	converter := NewIconConverter(gameDir, outputDir)
+	if err := converter.ConvertIcon("tech_mod"); err != nil {
+		t.Fatalf("Failed to convert icon: %v", err)
+	}
+	file, err := os.Open(filepath.Join(outputDir, "icons", "tech_mod.png"))
+	if err != nil {
+		t.Fatalf("Expected the icon to be written: %v", err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("Expected a PNG icon: %v", err)
+	}
+	if r, g, b, _ := img.At(0, 0).RGBA(); r != 0xffff || g != 0 || b != 0 {
+		t.Errorf("Expected a red pixel, got %v", img.At(0, 0))
+	}
+}
+
+func TestSourceFileMods(t *testing.T) {
+	gameDir := t.TempDir()
+	touch(t, gameDir, "gfx/interface/icons/technologies/tech_a.dds")
+	touch(t, gameDir, "gfx/interface/icons/technologies/tech_b.dds")
+	touch(t, gameDir, "gfx/interface/icons/technologies/tech_d.dds")
+
+	first := fstest.MapFS{
+		"gfx/interface/icons/technologies/tech_a.dds": {Data: []byte("first")},
+		"gfx/interface/icons/technologies/tech_c.dds": {Data: []byte("first")},
+		"gfx/interface/icons/technologies/tech_d.png": {Data: []byte("first")},
+	}
+	second := fstest.MapFS{
+		"gfx/interface/icons/technologies/tech_c.dds": {Data: []byte("second")},
+		"gfx/interface/mod/sprite.png":                {Data: []byte("sprite")},
+		"interface/mod.gfx":                           {Data: []byte("spriteTypes = {\n\tspriteType = {\n\t\tname = \"GFX_tech_sprite\"\n\t\ttexturefile = \"gfx/interface/mod/sprite.png\"\n\t}\n}\n")},
+	}
+
+	converter := NewIconConverter(gameDir, t.TempDir())
+	converter.AddMod(first, "first.zip")
+	converter.AddMod(second, "second")
+
+	tests := map[string]string{
+		"tech_a":          filepath.Join("first.zip", "gfx", "interface", "icons", "technologies", "tech_a.dds"),
+		"tech_b":          filepath.Join(gameDir, "gfx", "interface", "icons", "technologies", "tech_b.dds"),
+		"tech_c":          filepath.Join("second", "gfx", "interface", "icons", "technologies", "tech_c.dds"),
+		"tech_d":          filepath.Join("first.zip", "gfx", "interface", "icons", "technologies", "tech_d.png"),
+		"GFX_tech_sprite": filepath.Join("second", "gfx", "interface", "mod", "sprite.png"),
+	}
+	for icon, expected := range tests {
+		if file, found := converter.sourceFile(icon); !found || file.path != expected {
+			t.Errorf("Expected %s from %s, got %q", icon, expected, file.path)
+		}
+	}
+}
+
+func TestConvertIconsContextCancelled(t *testing.T) {
+	gameDir := t.TempDir()
+	touch(t, gameDir, "gfx/interface/icons/technologies/tech_a.png")
+	outputDir := t.TempDir()
+
+	converter := NewIconConverter(gameDir, outputDir)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	converted, err := converter.ConvertIconsContext(ctx, []string{"tech_a"})
+	if err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if converted != 0 {
+		t.Errorf("Expected no icons to be converted, got %d", converted)
+	}
+}
+
+func TestConvertIconsNoGameDir(t *testing.T) {
+	g := NewJSONGenerator(tree.NewTechTree(map[string]*models.Technology{}))
+	if err := g.ConvertIcons(t.TempDir()); !errors.Is(err, ErrNoGameDir) {
+		t.Errorf("Expected ErrNoGameDir, got %v", err)
+	}
+}
+
+func TestSourceFileDLC(t *testing.T) {
+	gameDir := t.TempDir()
+	touch(t, gameDir, "gfx/interface/icons/technologies/tech_a.dds")
+	dlc := fstest.MapFS{
+		"gfx/interface/icons/technologies/tech_a.dds":   {Data: []byte("dlc")},
+		"gfx/interface/icons/technologies/tech_dlc.dds": {Data: []byte("dlc")},
+		"gfx/interface/icons/technologies/tech_mod.dds": {Data: []byte("dlc")},
+	}
+	mod := fstest.MapFS{
+		"gfx/interface/icons/technologies/tech_mod.dds": {Data: []byte("mod")},
+	}
+
+	// Mods replace DLC icons even when added first
+	converter := NewIconConverter(gameDir, t.TempDir())
+	converter.AddMod(mod, "mod")
+	converter.AddDLC(dlc, "dlc001.zip")
+
+	tests := map[string]string{
+		"tech_a":   filepath.Join("dlc001.zip", "gfx", "interface", "icons", "technologies", "tech_a.dds"),
+		"tech_dlc": filepath.Join("dlc001.zip", "gfx", "interface", "icons", "technologies", "tech_dlc.dds"),
+		"tech_mod": filepath.Join("mod", "gfx", "interface", "icons", "technologies", "tech_mod.dds"),
+	}
+	for icon, expected := range tests {
+		if file, found := converter.sourceFile(icon); !found || file.path != expected {
+			t.Errorf("Expected %s from %s, got %q", icon, expected, file.path)
+		}
+	}
+}
+
+func TestConvertIconsSubset(t *testing.T) {
+	gameDir := t.TempDir()
+	for _, icon := range []string{"tech_a", "tech_b", "tech_c"} {
+		file, err := os.Create(touch(t, gameDir, "gfx/interface/icons/technologies/"+icon+".png"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := png.Encode(file, image.NewRGBA(image.Rect(0, 0, 52, 52))); err != nil {
+			t.Fatal(err)
+		}
+		file.Close()
+	}
+
+	techTree := tree.NewTechTree(map[string]*models.Technology{
+		"tech_a": {Key: "tech_a", Area: "physics", Icon: "tech_a"},
+		"tech_b": {Key: "tech_b", Area: "physics", Icon: "tech_b", Prerequisites: []string{"tech_a"}, IsRepeatable: true, Levels: -1},
+		"tech_c": {Key: "tech_c", Area: "society", Icon: "tech_c", IsRepeatable: true, Levels: -1},
+	})
+	g := NewJSONGenerator(techTree)
+	g.SetGameDir(gameDir)
+	g.SetRepeatableBadges(true)
+	g.SetSubtrees([]string{"tech_a"})
+
+	outputDir := t.TempDir()
+	if err := g.ConvertIcons(outputDir); err != nil {
+		t.Fatalf("Failed to convert icons: %v", err)
+	}
+	if stats := g.IconStats(); stats.Converted != 2 {
+		t.Errorf("Expected only the icons of the subtree to be converted, got %+v", stats)
+	}
+	for name, expected := range map[string]bool{
+		"tech_a.png":                true,
+		"tech_b.png":                true,
+		"tech_b_repeatable_inf.png": true,
+		"tech_c.png":                false,
+		"tech_c_repeatable_inf.png": false,
+	} {
+		if _, err := os.Stat(filepath.Join(outputDir, "icons", name)); (err == nil) != expected {
+			t.Errorf("Expected %s to be written: %v, got %v", name, expected, err)
+		}
+	}
+}
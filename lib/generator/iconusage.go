@@ -0,0 +1,48 @@
+package generator
+
+import (
+	"sort"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// HeavyIconReuse is the number of technologies sharing an icon from which the
+// icon usage report flags the icon as heavily reused
+const HeavyIconReuse = 3
+
+// IconUsage lists the technologies that share one icon
+type IconUsage struct {
+	Icon         string   `json:"icon"`
+	Technologies []string `json:"technologies"` // Sorted by key
+	HeavyReuse   bool     `json:"heavyReuse"`
+}
+
+// SharedIcons returns the icons used by more than one of the given
+// technologies, most used first and then by icon name
+func SharedIcons(technologies []*models.Technology) []IconUsage {
+	byIcon := make(map[string][]string)
+	for _, tech := range technologies {
+		byIcon[tech.Icon] = append(byIcon[tech.Icon], tech.Key)
+	}
+
+	shared := []IconUsage{}
+	for icon, keys := range byIcon {
+		if len(keys) < 2 {
+			continue
+		}
+		sort.Strings(keys)
+		shared = append(shared, IconUsage{
+			Icon:         icon,
+			Technologies: keys,
+			HeavyReuse:   len(keys) >= HeavyIconReuse,
+		})
+	}
+
+	sort.Slice(shared, func(i, j int) bool {
+		if len(shared[i].Technologies) != len(shared[j].Technologies) {
+			return len(shared[i].Technologies) > len(shared[j].Technologies)
+		}
+		return shared[i].Icon < shared[j].Icon
+	})
+	return shared
+}
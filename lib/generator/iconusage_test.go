@@ -0,0 +1,42 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+func TestSharedIcons(t *testing.T) {
+	technologies := []*models.Technology{
+		{Key: "tech_c", Icon: "icon_heavy"},
+		{Key: "tech_a", Icon: "icon_heavy"},
+		{Key: "tech_b", Icon: "icon_heavy"},
+		{Key: "tech_d", Icon: "icon_pair"},
+		{Key: "tech_e", Icon: "icon_pair"},
+		{Key: "tech_f", Icon: "icon_unique"},
+	}
+
+	shared := SharedIcons(technologies)
+	if len(shared) != 2 {
+		t.Fatalf("Expected 2 shared icons, got %v", shared)
+	}
+
+	heavy := shared[0]
+	if heavy.Icon != "icon_heavy" || !heavy.HeavyReuse {
+		t.Errorf("Expected icon_heavy first and flagged as heavy reuse, got %+v", heavy)
+	}
+	if heavy.Technologies[0] != "tech_a" || len(heavy.Technologies) != 3 {
+		t.Errorf("Expected sorted technologies, got %v", heavy.Technologies)
+	}
+
+	if shared[1].Icon != "icon_pair" || shared[1].HeavyReuse {
+		t.Errorf("Expected icon_pair not to be flagged as heavy reuse, got %+v", shared[1])
+	}
+}
+
+func TestSharedIconsNone(t *testing.T) {
+	shared := SharedIcons([]*models.Technology{{Key: "tech_a", Icon: "icon_a"}})
+	if shared == nil || len(shared) != 0 {
+		t.Errorf("Expected an empty, non-nil list, got %v", shared)
+	}
+}
@@ -0,0 +1,118 @@
+package generator
+
+import (
+	"path"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// MetadataEntry describes a research area or category in metadata.json
+type MetadataEntry struct {
+	Name string `json:"name"`           // Localized, or formatted from the key
+	Icon string `json:"icon,omitempty"` // Path relative to the icon directory
+}
+
+// Metadata returns the contents of metadata.json: the areas, tiers,
+// categories and max level of the tree, colors, the display names and icons
+// of areas and categories, tree issues and the game version
+func (g *JSONGenerator) Metadata() MetadataJSON {
+	return MetadataJSON{
+		SchemaVersion:   SchemaVersion,
+		Areas:           g.tree.GetAreas(),
+		Tiers:           g.tree.GetTiers(),
+		Categories:      g.tree.GetCategories(),
+		MaxLevel:        g.tree.GetMaxLevel(),
+		AreaDetails:     g.areaDetails(),
+		CategoryDetails: g.categoryDetails(),
+		Colors:          ColorPalettes(g.tree.GetTiers()),
+		Issues:          g.tree.Issues(),
+		IconSizes:       g.iconEncoding.Sizes,
+		GameVersion:     g.gameVersion,
+	}
+}
+
+// AreaIconName returns the name of the resource icon of a research area,
+// e.g. physics_research, used when the game defines no area sprite
+func AreaIconName(area string) string {
+	return area + "_research"
+}
+
+// areaDetails returns the display name and icon of each research area
+func (g *JSONGenerator) areaDetails() map[string]MetadataEntry {
+	details := make(map[string]MetadataEntry)
+	for _, area := range g.tree.GetAreas() {
+		name := g.areaNames[area]
+		if name == "" {
+			name = formatTechName(area)
+		}
+		details[area] = MetadataEntry{
+			Name: g.formatText(name),
+			Icon: path.Join(AreaIconsOutputDir, area+".png"),
+		}
+	}
+	return details
+}
+
+// categoryDetails returns the display name and icon of each research
+// category used by a technology
+func (g *JSONGenerator) categoryDetails() map[string]MetadataEntry {
+	details := make(map[string]MetadataEntry)
+	for _, key := range g.tree.GetCategories() {
+		entry := MetadataEntry{Name: formatTechName(key)}
+		if category, ok := g.categories[key]; ok {
+			if category.Name != "" {
+				entry.Name = category.Name
+			}
+			if category.Icon != "" {
+				entry.Icon = path.Join(CategoryIconsOutputDir, key+".png")
+			}
+		}
+		entry.Name = g.formatText(entry.Name)
+		details[key] = entry
+	}
+	return details
+}
+
+// convertMetadataIcons extracts the icons of the research areas and of the
+// categories used by a technology
+func (g *JSONGenerator) convertMetadataIcons(converter *IconConverter) {
+	areaIcons, err := converter.ConvertAreaIcons(g.tree.GetAreas())
+	if err != nil {
+		g.logger.Warn("Some area icons could not be converted", "error", err)
+	}
+
+	icons := make(map[string]string)
+	for _, key := range g.tree.GetCategories() {
+		if category, ok := g.categories[key]; ok && category.Icon != "" {
+			icons[key] = category.Icon
+		}
+	}
+	categoryIcons, err := converter.ConvertCategoryIcons(icons)
+	if err != nil {
+		g.logger.Warn("Some category icons could not be converted", "error", err)
+	}
+
+	if areaIcons+categoryIcons > 0 {
+		g.logger.Info("Extracted area and category icons", "areas", areaIcons, "categories", categoryIcons)
+	}
+}
+
+// SetCategories sets the research category definitions used for the names
+// and icons of categories in the metadata
+func (g *JSONGenerator) SetCategories(categories map[string]*models.Category) {
+	g.categories = categories
+}
+
+// SetGameVersion sets the game version the data was read from, written to
+// metadata.json as gameVersion. An empty version is not written.
+func (g *JSONGenerator) SetGameVersion(version models.GameVersion) {
+	g.gameVersion = nil
+	if version.Version != "" {
+		g.gameVersion = &version
+	}
+}
+
+// SetAreaNames sets the localized names of the research areas, by area key
+func (g *JSONGenerator) SetAreaNames(names map[string]string) {
+	g.areaNames = names
+}
@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+func TestMetadataDetails(t *testing.T) {
+	generator := NewJSONGenerator(createTestTree())
+	generator.SetAreaNames(map[string]string{"physics": "§YPhysics Research§!"})
+	generator.SetCategories(map[string]*models.Category{
+		"computing": {Key: "computing", Name: "Computing", Icon: "GFX_research_computing"},
+		"materials": {Key: "materials"},
+	})
+
+	tmpDir := t.TempDir()
+	if err := generator.GenerateJSONFiles(tmpDir); err != nil {
+		t.Fatalf("Failed to generate JSON files: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "metadata.json"))
+	if err != nil {
+		t.Fatalf("Failed to read metadata: %v", err)
+	}
+	var metadata struct {
+		AreaDetails     map[string]MetadataEntry `json:"areaDetails"`
+		CategoryDetails map[string]MetadataEntry `json:"categoryDetails"`
+	}
+	if err := json.Unmarshal(content, &metadata); err != nil {
+		t.Fatalf("Failed to parse metadata: %v", err)
+	}
+
+	expectedAreas := map[string]MetadataEntry{
+		"physics":     {Name: "Physics Research", Icon: "areas/physics.png"},
+		"engineering": {Name: "Engineering", Icon: "areas/engineering.png"},
+	}
+	for area, expected := range expectedAreas {
+		if metadata.AreaDetails[area] != expected {
+			t.Errorf("Expected %s to be %+v, got %+v", area, expected, metadata.AreaDetails[area])
+		}
+	}
+
+	expectedCategories := map[string]MetadataEntry{
+		"computing": {Name: "Computing", Icon: "categories/computing.png"},
+		"materials": {Name: "Materials"},
+		"voidcraft": {Name: "Voidcraft"},
+	}
+	for category, expected := range expectedCategories {
+		if metadata.CategoryDetails[category] != expected {
+			t.Errorf("Expected %s to be %+v, got %+v", category, expected, metadata.CategoryDetails[category])
+		}
+	}
+}
+
+func TestMetadataGameVersion(t *testing.T) {
+	generator := NewJSONGenerator(createTestTree())
+	if version := generator.Metadata().GameVersion; version != nil {
+		t.Errorf("Expected no game version by default, got %+v", version)
+	}
+
+	generator.SetGameVersion(models.GameVersion{Version: "3.12.4", Name: "Andromeda"})
+	version := generator.Metadata().GameVersion
+	if version == nil || version.Version != "3.12.4" || version.Name != "Andromeda" {
+		t.Errorf("Expected game version 3.12.4 (Andromeda), got %+v", version)
+	}
+
+	generator.SetGameVersion(models.GameVersion{})
+	if version := generator.Metadata().GameVersion; version != nil {
+		t.Errorf("Expected an empty version not to be written, got %+v", version)
+	}
+}
@@ -0,0 +1,133 @@
+package generator
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/danaketh/StellarisDataParser/lib/manifest"
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+// Reasons a technology icon is missing
+const (
+	MissingIconNotFound   = "notFound"   // No file in the game, the mods or the overrides
+	MissingIconUnreadable = "unreadable" // Found but couldn't be decoded or written
+)
+
+// MissingIcon is a technology icon that couldn't be converted
+type MissingIcon struct {
+	Icon         string   `json:"icon"`
+	Reason       string   `json:"reason"`          // MissingIconNotFound or MissingIconUnreadable
+	Error        string   `json:"error,omitempty"` // Why an unreadable icon failed
+	Technologies []string `json:"technologies"`    // Sorted by key
+	Placeholder  bool     `json:"placeholder"`     // A placeholder was written in its place
+}
+
+// SetIconPlaceholders makes ConvertIcons write a placeholder PNG for each
+// technology icon that can't be found or converted: the initials of the
+// technology on the color of its research area
+func (g *JSONGenerator) SetIconPlaceholders(enabled bool) {
+	g.iconPlaceholders = enabled
+}
+
+// MissingIconsFileName returns the file name of the missing icon report
+func (g *JSONGenerator) MissingIconsFileName() string {
+	return g.dataFileName(g.output.MissingIconsFile)
+}
+
+// MissingIcons returns the technology icons that couldn't be converted in the
+// last call to Generate or ConvertIcons, by icon name
+func (g *JSONGenerator) MissingIcons() []MissingIcon {
+	return g.missingIcons
+}
+
+// reportMissingIcons lists the icons the converter couldn't convert with the
+// technologies using them, writes their placeholders if enabled and writes
+// the missing icon report
+func (g *JSONGenerator) reportMissingIcons(converter *IconConverter, nodes []*tree.TechNode, outputDir string) error {
+	users := make(map[string][]*models.Technology)
+	for _, node := range nodes {
+		if icon, override := g.resolveIcon(node.Tech); override == "" {
+			users[icon] = append(users[icon], node.Tech)
+		}
+	}
+
+	g.missingIcons = []MissingIcon{}
+	placeholders := 0
+	reported := make(map[string]bool)
+	for _, missing := range converter.missing {
+		// Icons shared by several technologies are converted once per technology
+		if reported[missing.Icon] {
+			continue
+		}
+		reported[missing.Icon] = true
+		techs := users[missing.Icon]
+		sort.Slice(techs, func(i, j int) bool { return techs[i].Key < techs[j].Key })
+		missing.Technologies = make([]string, len(techs))
+		for i, tech := range techs {
+			missing.Technologies[i] = tech.Key
+		}
+
+		if g.iconPlaceholders && len(techs) > 0 {
+			if err := converter.WritePlaceholder(missing.Icon, g.techInitials(techs[0]), areaColor(techs[0].Area)); err != nil {
+				g.logger.Warn("Placeholder icon not written", "icon", missing.Icon, "error", err)
+			} else {
+				missing.Placeholder = true
+				placeholders++
+			}
+		}
+		g.missingIcons = append(g.missingIcons, missing)
+	}
+	sort.Slice(g.missingIcons, func(i, j int) bool { return g.missingIcons[i].Icon < g.missingIcons[j].Icon })
+
+	if len(g.missingIcons) > 0 {
+		g.logger.Warn("Some technology icons are missing", "count", len(g.missingIcons), "placeholders", placeholders, "report", g.MissingIconsFileName())
+	}
+
+	path, err := prepareOutputPath(outputDir, g.MissingIconsFileName())
+	if err != nil {
+		return fmt.Errorf("failed to create missing icon report directory: %w", err)
+	}
+	content, err := g.encodeFile(map[string]interface{}{"missingIcons": g.missingIcons})
+	if err != nil {
+		return fmt.Errorf("failed to encode missing icon report: %w", err)
+	}
+	if g.manifest != nil {
+		// Recorded like the icons, as ConvertIcons runs after the JSON files
+		name, fingerprint := manifestName(outputDir, path), manifest.Fingerprint(content)
+		g.manifest.Set(name, fingerprint)
+		if g.since.Unchanged(name, fingerprint) {
+			return nil
+		}
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write missing icon report: %w", err)
+	}
+	return nil
+}
+
+// techInitials returns the initials a placeholder shows for a technology
+func (g *JSONGenerator) techInitials(tech *models.Technology) string {
+	if initials := iconInitials(plainText(tech.Name)); initials != "" {
+		return initials
+	}
+	return iconInitials(formatTechName(tech.Key))
+}
+
+// areaColor returns the game palette color of a research area, or the
+// lowest tier color for unknown areas
+func areaColor(area string) color.RGBA {
+	hex, ok := gameColors.areas[area]
+	if !ok {
+		hex = gameColors.tiers[0]
+	}
+	value, err := strconv.ParseUint(hex[1:], 16, 32)
+	if err != nil {
+		return color.RGBA{A: 255}
+	}
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 255}
+}
@@ -0,0 +1,105 @@
+package generator
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+func TestIconInitials(t *testing.T) {
+	tests := map[string]string{
+		"Red Lasers":               "RL",
+		"Cloaking 1":               "C1",
+		"Zero-Point Power":         "ZP",
+		"éther":                    "",
+		"  Planetary Unification ": "PU",
+		"Three Word Name":          "TW",
+	}
+	for name, expected := range tests {
+		if initials := iconInitials(name); initials != expected {
+			t.Errorf("Expected initials %q for %q, got %q", expected, name, initials)
+		}
+	}
+}
+
+func TestMissingIcons(t *testing.T) {
+	gameDir := t.TempDir()
+	iconsDir := filepath.Join(gameDir, "gfx", "interface", "icons", "technologies")
+	if err := os.MkdirAll(iconsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	iconFile, err := os.Create(filepath.Join(iconsDir, "tech_found.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(iconFile, image.NewRGBA(image.Rect(0, 0, 52, 52))); err != nil {
+		t.Fatal(err)
+	}
+	iconFile.Close()
+	if err := os.WriteFile(filepath.Join(iconsDir, "tech_corrupt.dds"), []byte("DDS broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	techTree := tree.NewTechTree(map[string]*models.Technology{
+		"tech_found":    {Key: "tech_found", Area: "physics", Icon: "tech_found"},
+		"tech_corrupt":  {Key: "tech_corrupt", Area: "society", Icon: "tech_corrupt"},
+		"tech_lasers_1": {Key: "tech_lasers_1", Name: "Red Lasers", Area: "physics", Icon: "tech_lasers"},
+		"tech_lasers_2": {Key: "tech_lasers_2", Area: "physics", Icon: "tech_lasers"},
+	})
+	g := NewJSONGenerator(techTree)
+	g.SetGameDir(gameDir)
+	g.SetIconPlaceholders(true)
+
+	outputDir := t.TempDir()
+	if err := g.ConvertIcons(outputDir); err != nil {
+		t.Fatalf("Failed to convert icons: %v", err)
+	}
+
+	missing := g.MissingIcons()
+	if len(missing) != 2 {
+		t.Fatalf("Expected 2 missing icons, got %+v", missing)
+	}
+	if missing[0].Icon != "tech_corrupt" || missing[0].Reason != MissingIconUnreadable || missing[0].Error == "" {
+		t.Errorf("Expected the corrupt icon to be unreadable, got %+v", missing[0])
+	}
+	if missing[1].Icon != "tech_lasers" || missing[1].Reason != MissingIconNotFound || len(missing[1].Technologies) != 2 || !missing[1].Placeholder {
+		t.Errorf("Expected the shared icon to be reported once with both technologies, got %+v", missing[1])
+	}
+
+	var report struct {
+		MissingIcons []MissingIcon `json:"missingIcons"`
+	}
+	content, err := os.ReadFile(filepath.Join(outputDir, "missing-icons.json"))
+	if err != nil {
+		t.Fatalf("Expected the report to be written: %v", err)
+	}
+	if err := json.Unmarshal(content, &report); err != nil || len(report.MissingIcons) != 2 {
+		t.Errorf("Expected 2 icons in the report, got %s (%v)", content, err)
+	}
+
+	file, err := os.Open(filepath.Join(outputDir, "icons", "tech_lasers.png"))
+	if err != nil {
+		t.Fatalf("Expected a placeholder icon: %v", err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size != image.Pt(PlaceholderIconSize, PlaceholderIconSize) {
+		t.Errorf("Expected a %dx%d placeholder, got %v", PlaceholderIconSize, PlaceholderIconSize, size)
+	}
+	if c := color.RGBAModel.Convert(img.At(0, 0)); c != areaColor("physics") {
+		t.Errorf("Expected the physics color as background, got %v", c)
+	}
+	if c := color.RGBAModel.Convert(img.At(PlaceholderIconSize/2+4, PlaceholderIconSize/2)); c != placeholderForeground {
+		t.Errorf("Expected the stem of the L of RL right of the center, got %v", c)
+	}
+}
@@ -0,0 +1,73 @@
+package generator
+
+import "strconv"
+
+// Palette maps research areas, technology tiers and rarities to CSS hex
+// colors
+type Palette struct {
+	Areas  map[string]string `json:"areas"`
+	Tiers  map[string]string `json:"tiers"`  // Keyed by tier number
+	Rarity map[string]string `json:"rarity"` // common, rare and dangerous
+}
+
+// paletteColors holds the colors a Palette is built from
+type paletteColors struct {
+	areas  map[string]string
+	tiers  []string // Lowest tier first; later tiers reuse the last color
+	rarity map[string]string
+}
+
+// gameColors follow the in-game research screen: blue physics, green society
+// and orange engineering cards, purple rare and red dangerous technologies
+var gameColors = paletteColors{
+	areas: map[string]string{
+		"physics":     "#3D9BE9",
+		"society":     "#3DBD58",
+		"engineering": "#E8A33D",
+	},
+	tiers: []string{"#8C8C8C", "#A8B8C8", "#6FA8DC", "#3D9BE9", "#9B6FDC", "#E8C33D"},
+	rarity: map[string]string{
+		"common":    "#C8C8C8",
+		"rare":      "#A64FD9",
+		"dangerous": "#D93A3A",
+	},
+}
+
+// accessibleColors use the Okabe-Ito palette for areas and rarities and the
+// viridis ramp for tiers, which stay distinguishable with the common forms
+// of color blindness
+var accessibleColors = paletteColors{
+	areas: map[string]string{
+		"physics":     "#0072B2",
+		"society":     "#009E73",
+		"engineering": "#E69F00",
+	},
+	tiers: []string{"#440154", "#414487", "#2A788E", "#22A884", "#7AD151", "#FDE725"},
+	rarity: map[string]string{
+		"common":    "#999999",
+		"rare":      "#CC79A7",
+		"dangerous": "#D55E00",
+	},
+}
+
+// palette builds a Palette for the given tiers, in ascending order
+func (c paletteColors) palette(tiers []int) Palette {
+	p := Palette{
+		Areas:  c.areas,
+		Tiers:  make(map[string]string, len(tiers)),
+		Rarity: c.rarity,
+	}
+	for i, tier := range tiers {
+		p.Tiers[strconv.Itoa(tier)] = c.tiers[min(i, len(c.tiers)-1)]
+	}
+	return p
+}
+
+// ColorPalettes returns the game palette and a colorblind-safe alternative,
+// keyed "game" and "accessible", for the given tiers in ascending order
+func ColorPalettes(tiers []int) map[string]Palette {
+	return map[string]Palette{
+		"game":       gameColors.palette(tiers),
+		"accessible": accessibleColors.palette(tiers),
+	}
+}
@@ -0,0 +1,35 @@
+package generator
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestColorPalettes(t *testing.T) {
+	tiers := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	palettes := ColorPalettes(tiers)
+
+	hex := regexp.MustCompile(`^#[0-9A-F]{6}$`)
+	for name, palette := range palettes {
+		if len(palette.Tiers) != len(tiers) {
+			t.Errorf("%s: expected a color for each of %d tiers, got %v", name, len(tiers), palette.Tiers)
+		}
+		if palette.Tiers["7"] != palette.Tiers["5"] {
+			t.Errorf("%s: expected tiers beyond the ramp to reuse the last color, got %v", name, palette.Tiers)
+		}
+		for _, area := range []string{"physics", "society", "engineering"} {
+			if !hex.MatchString(palette.Areas[area]) {
+				t.Errorf("%s: expected a hex color for %s, got %q", name, area, palette.Areas[area])
+			}
+		}
+		for _, rarity := range []string{"common", "rare", "dangerous"} {
+			if !hex.MatchString(palette.Rarity[rarity]) {
+				t.Errorf("%s: expected a hex color for %s, got %q", name, rarity, palette.Rarity[rarity])
+			}
+		}
+	}
+
+	if palettes["game"].Areas["physics"] == palettes["accessible"].Areas["physics"] {
+		t.Error("Expected the accessible palette to differ from the game palette")
+	}
+}
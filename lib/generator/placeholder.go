@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"unicode"
+
+	"github.com/danaketh/StellarisDataParser/lib/manifest"
+)
+
+// PlaceholderIconSize is the width and height in pixels of placeholder
+// icons, the size of the game's technology icons
+const PlaceholderIconSize = 52
+
+// letterGlyphs complete the badge glyphs with the capital letters placeholder
+// initials are drawn with
+var letterGlyphs = map[rune][]string{
+	'A': {".#.", "#.#", "###", "#.#", "#.#"},
+	'B': {"##.", "#.#", "##.", "#.#", "##."},
+	'C': {".##", "#..", "#..", "#..", ".##"},
+	'D': {"##.", "#.#", "#.#", "#.#", "##."},
+	'E': {"###", "#..", "##.", "#..", "###"},
+	'F': {"###", "#..", "##.", "#..", "#.."},
+	'G': {".##", "#..", "#.#", "#.#", ".##"},
+	'H': {"#.#", "#.#", "###", "#.#", "#.#"},
+	'I': {"###", ".#.", ".#.", ".#.", "###"},
+	'J': {"..#", "..#", "..#", "#.#", ".#."},
+	'K': {"#.#", "#.#", "##.", "#.#", "#.#"},
+	'L': {"#..", "#..", "#..", "#..", "###"},
+	'M': {"#...#", "##.##", "#.#.#", "#...#", "#...#"},
+	'N': {"#..#", "##.#", "#.##", "#..#", "#..#"},
+	'O': {".#.", "#.#", "#.#", "#.#", ".#."},
+	'P': {"##.", "#.#", "##.", "#..", "#.."},
+	'Q': {".#.", "#.#", "#.#", "##.", ".##"},
+	'R': {"##.", "#.#", "##.", "#.#", "#.#"},
+	'S': {".##", "#..", ".#.", "..#", "##."},
+	'T': {"###", ".#.", ".#.", ".#.", ".#."},
+	'U': {"#.#", "#.#", "#.#", "#.#", "###"},
+	'V': {"#.#", "#.#", "#.#", "#.#", ".#."},
+	'W': {"#...#", "#...#", "#.#.#", "##.##", "#...#"},
+	'X': {"#.#", "#.#", ".#.", "#.#", "#.#"},
+	'Y': {"#.#", "#.#", ".#.", ".#.", ".#."},
+	'Z': {"###", "..#", ".#.", "#..", "###"},
+}
+
+var placeholderForeground = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+// iconInitials returns the first character of up to two words of a name
+// that can be drawn, in upper case
+func iconInitials(name string) string {
+	initials := ""
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		r := unicode.ToUpper([]rune(word)[0])
+		if _, ok := glyph(r); ok {
+			initials += string(r)
+		}
+		if len(initials) == 2 {
+			break
+		}
+	}
+	return initials
+}
+
+// drawPlaceholder returns a placeholder icon: initials centered on a
+// background color
+func drawPlaceholder(initials string, background color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, PlaceholderIconSize, PlaceholderIconSize))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: background}, image.Point{}, draw.Src)
+
+	// Two of the widest glyphs and their spacing fill most of the width
+	scale := max(1, PlaceholderIconSize/13)
+	width, height := labelSize(initials, scale)
+	drawLabel(img, initials, (PlaceholderIconSize-width)/2, (PlaceholderIconSize-height)/2, scale, placeholderForeground)
+	return img
+}
+
+// WritePlaceholder writes a placeholder in place of an icon and its size
+// variants: the initials on the background color
+func (ic *IconConverter) WritePlaceholder(iconName, initials string, background color.RGBA) error {
+	outputPath := ic.iconOutputPath(iconName)
+	if ic.manifest != nil {
+		fingerprint := manifest.Fingerprint([]byte("placeholder"), []byte(initials), []byte{background.R, background.G, background.B, background.A}, []byte(ic.encoding.fingerprint()))
+		if ic.unchangedVariants(outputPath, fingerprint) {
+			ic.skipped++
+			return nil
+		}
+	}
+
+	img := drawPlaceholder(initials, background)
+	if err := ic.encodeFile(img, outputPath); err != nil {
+		return err
+	}
+	for i, path := range ic.variantPaths(outputPath) {
+		if err := ic.encodeFile(downscale(img, ic.encoding.Sizes[i]), path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
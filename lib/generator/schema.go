@@ -0,0 +1,111 @@
+package generator
+
+import (
+	"github.com/danaketh/StellarisDataParser/lib/layout"
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+// SchemaVersion is the version of the format of the generated JSON files,
+// written as schemaVersion in each of them. It only changes when a field is
+// removed or renamed or its type or meaning changes; new fields and files
+// keep the version. Files written before versioning are version 0; Upgrade
+// converts older files to the current version.
+const SchemaVersion = 1
+
+// TechnologyJSON is a technology as written to the research files. Fields are
+// in the order of the Technology interface of the type declarations.
+type TechnologyJSON struct {
+	Key                string              `json:"key"`
+	Name               string              `json:"name"`
+	Description        string              `json:"description"`
+	Cost               int                 `json:"cost"`
+	Area               string              `json:"area"`
+	Tier               int                 `json:"tier"`
+	Level              int                 `json:"level"` // Depth in the prerequisite tree
+	EstimatedYear      int                 `json:"estimatedYear"`
+	Category           string              `json:"category"` // Comma-separated list of categories
+	Prerequisites      []string            `json:"prerequisites"`
+	Order              int                 `json:"order"`     // Index in the research order of the whole tree
+	AreaOrder          int                 `json:"areaOrder"` // Index in the research order of the area
+	CumulativeCost     tree.CumulativeCost `json:"cumulativeCost"`
+	Position           layout.Position     `json:"position"`
+	PrerequisiteGroups [][]string          `json:"prerequisiteGroups"` // Any one group unlocks the technology
+	Weight             int                 `json:"weight"`
+	SourceFile         string              `json:"sourceFile"`
+	Icon               string              `json:"icon"`
+	IconFile           string              `json:"iconFile"` // File name in the icon directory, .png or .svg
+	IsStartTech        bool                `json:"isStartTech"`
+	IsDangerous        bool                `json:"isDangerous"`
+	IsRare             bool                `json:"isRare"`
+	IsEvent            bool                `json:"isEvent"`
+	IsInsight          bool                `json:"isInsight"`
+	Acquisition        string              `json:"acquisition"`
+	IsReverse          bool                `json:"isReverse"`
+	IsRepeatable       bool                `json:"isRepeatable"`
+	Levels             int                 `json:"levels"` // -1 means unlimited
+	CostPerLevel       int                 `json:"costPerLevel"`
+	IsInfinite         bool                `json:"isInfinite"`
+	IsGestalt          bool                `json:"isGestalt"`
+	IsMegacorp         bool                `json:"isMegacorp"`
+	Mod                string              `json:"mod,omitempty"`       // Set for technologies defined by a mod
+	BadgeIcon          string              `json:"badgeIcon,omitempty"` // Set for repeatable technologies with repeatable badges
+	IconData           string              `json:"iconData,omitempty"`  // Data URI of the icon, set when icons are embedded
+	CostTable          []models.LevelCost  `json:"costTable,omitempty"` // Set for repeatable technologies
+	Raw                *models.Source      `json:"raw,omitempty"`       // Script text of the definition, set with raw sources
+
+	*FullTechnologyJSON // Set in full mode
+}
+
+// FullTechnologyJSON holds the technology fields only written in full mode
+type FullTechnologyJSON struct {
+	BaseWeight         float64                `json:"baseWeight"`
+	OfferChance        float64                `json:"offerChance"` // Chance to be among the research alternatives of the area
+	FeatureUnlocks     []string               `json:"featureUnlocks"`
+	AIUpdateType       string                 `json:"aiUpdateType"`
+	Gateway            string                 `json:"gateway"`
+	IsMachineEmpire    bool                   `json:"isMachineEmpire"`
+	IsHiveEmpire       bool                   `json:"isHiveEmpire"`
+	IsDriveAssimilator bool                   `json:"isDriveAssimilator"`
+	IsRogueServitor    bool                   `json:"isRogueServitor"`
+	ExtraFlags         map[string]interface{} `json:"extraFlags"`
+	// Weight groups of the technology, and the factor applied to its weight
+	// once a technology of a group is offered, by group
+	WeightGroups           []string           `json:"weightGroups"`
+	ModWeightIfGroupPicked map[string]float64 `json:"modWeightIfGroupPicked"`
+}
+
+// ResearchFileJSON is the contents of a research-<area>.json file
+type ResearchFileJSON struct {
+	SchemaVersion int              `json:"schemaVersion"`
+	Area          string           `json:"area"`
+	Technologies  []TechnologyJSON `json:"technologies"` // By level, then key
+}
+
+// MetadataJSON is the contents of metadata.json
+type MetadataJSON struct {
+	SchemaVersion   int                      `json:"schemaVersion"`
+	Areas           []string                 `json:"areas"`
+	Tiers           []int                    `json:"tiers"`
+	Categories      []string                 `json:"categories"`
+	MaxLevel        int                      `json:"maxLevel"`
+	AreaDetails     map[string]MetadataEntry `json:"areaDetails"`
+	CategoryDetails map[string]MetadataEntry `json:"categoryDetails"`
+	Colors          map[string]Palette       `json:"colors"` // Game colors and a colorblind-safe alternative
+	Issues          []tree.Issue             `json:"issues"`
+	IconSizes       []int                    `json:"iconSizes,omitempty"`   // Sizes of the icon variants, set when they are written
+	GameVersion     *models.GameVersion      `json:"gameVersion,omitempty"` // Set when the game version is known
+}
+
+// GraphNode is a node of the graph file: a technology with its key as id
+type GraphNode struct {
+	ID string `json:"id"`
+	TechnologyJSON
+}
+
+// GraphJSON is the contents of the graph file
+type GraphJSON struct {
+	SchemaVersion int         `json:"schemaVersion"`
+	Nodes         []GraphNode `json:"nodes"` // In research order
+	Links         []GraphLink `json:"links"`
+}
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"sort"
+
+	"github.com/danaketh/StellarisDataParser/lib/localization"
+)
+
+// SearchRecord is a technology in the search index. Records have an objectID
+// for Algolia and a key to use as the lunr ref; names and descriptions are
+// plain text whatever the text modes.
+type SearchRecord struct {
+	ObjectID    string `json:"objectID"`
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Area        string `json:"area"`
+	AreaName    string `json:"areaName"`
+	Tier        int    `json:"tier"`
+	Category    string `json:"category"` // Comma-separated list of categories
+}
+
+// SetSearchIndex enables writing the search index
+func (g *JSONGenerator) SetSearchIndex(enabled bool) {
+	g.searchIndex = enabled
+}
+
+// SearchIndexFileName returns the file name of the search index
+func (g *JSONGenerator) SearchIndexFileName() string {
+	return g.dataFileName(g.output.SearchIndexFile)
+}
+
+// searchRecords returns a search record for each exported technology, in
+// research order
+func (g *JSONGenerator) searchRecords(techsByArea map[string][]TechnologyJSON) []SearchRecord {
+	metadata := g.Metadata()
+	var techs []TechnologyJSON
+	for _, areaTechs := range techsByArea {
+		techs = append(techs, areaTechs...)
+	}
+	sort.Slice(techs, func(i, j int) bool { return techs[i].Order < techs[j].Order })
+
+	records := []SearchRecord{}
+	for _, tech := range techs {
+		node, exists := g.tree.GetNode(tech.Key)
+		if !exists {
+			continue
+		}
+		name := node.Tech.Name
+		if name == "" {
+			name = formatTechName(tech.Key)
+		}
+		records = append(records, SearchRecord{
+			ObjectID:    tech.Key,
+			Key:         tech.Key,
+			Name:        plainText(name),
+			Description: plainText(node.Tech.Description),
+			Area:        tech.Area,
+			AreaName:    metadata.AreaDetails[tech.Area].Name,
+			Tier:        tech.Tier,
+			Category:    tech.Category,
+		})
+	}
+	return records
+}
+
+// plainText strips color markup, scripting commands and icon references from
+// a localized string
+func plainText(text string) string {
+	text = localization.FormatColors(text, localization.ColorStrip)
+	text = localization.FormatCommands(text, localization.CommandStrip, nil)
+	return localization.FormatIconTokens(text, localization.IconTokenStrip, "")
+}
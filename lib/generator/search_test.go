@@ -0,0 +1,41 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateSearchIndex(t *testing.T) {
+	techTree := createTestTree()
+	node, _ := techTree.GetNode("tech_test_2")
+	node.Tech.Name = "§YBlue§! Lasers"
+	node.Tech.Description = "Fires £energy£ beams [Root.GetName]."
+
+	generator := NewJSONGenerator(techTree)
+	generator.SetSearchIndex(true)
+	generator.SetColorMode("html")
+	outputDir := t.TempDir()
+	if err := generator.GenerateJSONFiles(outputDir); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "search-index.json"))
+	if err != nil {
+		t.Fatalf("Failed to read search index: %v", err)
+	}
+	var records []SearchRecord
+	if err := json.Unmarshal(content, &records); err != nil {
+		t.Fatalf("Expected an array of records: %v", err)
+	}
+	if len(records) != 3 || records[1].ObjectID != "tech_test_2" || records[1].Key != "tech_test_2" {
+		t.Fatalf("Expected the 3 technologies in research order, got %+v", records)
+	}
+	if records[1].Name != "Blue Lasers" || records[1].Description != "Fires  beams ." {
+		t.Errorf("Expected plain text, got %q, %q", records[1].Name, records[1].Description)
+	}
+	if records[0].Name != "Test 1" || records[0].AreaName == "" || records[2].Area != "engineering" {
+		t.Errorf("Expected formatted names and area details, got %+v", records)
+	}
+}
@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/danaketh/StellarisDataParser/lib/config"
+)
+
+// SidebarsName is the name of the sidebar in the sidebars file
+const SidebarsName = "technologies"
+
+// SidebarItem is an entry of a Docusaurus sidebar: a category of items, or a
+// doc page of a technology
+type SidebarItem struct {
+	Type  string        `json:"type"`
+	Label string        `json:"label"`
+	ID    string        `json:"id,omitempty"`    // Doc id, for doc items
+	Items []SidebarItem `json:"items,omitempty"` // Entries of a category
+}
+
+// SetSidebars enables writing the sidebars file
+func (g *JSONGenerator) SetSidebars(enabled bool) {
+	g.sidebars = enabled
+}
+
+// SidebarsFileName returns the file name of the sidebars file
+func (g *JSONGenerator) SidebarsFileName() string {
+	return g.output.SidebarsFile
+}
+
+// sidebarItems returns a category for each research area of the exported
+// technologies, holding a category for each tier with the doc pages of its
+// technologies in research order
+func (g *JSONGenerator) sidebarItems(techsByArea map[string][]TechnologyJSON) []SidebarItem {
+	metadata := g.Metadata()
+	items := []SidebarItem{}
+	for _, area := range metadata.Areas {
+		techs := append([]TechnologyJSON(nil), techsByArea[area]...)
+		if len(techs) == 0 {
+			continue
+		}
+		sort.Slice(techs, func(i, j int) bool {
+			if techs[i].Tier != techs[j].Tier {
+				return techs[i].Tier < techs[j].Tier
+			}
+			return techs[i].Order < techs[j].Order
+		})
+
+		areaItem := SidebarItem{Type: "category", Label: metadata.AreaDetails[area].Name}
+		for _, tech := range techs {
+			if n := len(areaItem.Items); n == 0 || areaItem.Items[n-1].Label != tierLabel(tech.Tier) {
+				areaItem.Items = append(areaItem.Items, SidebarItem{Type: "category", Label: tierLabel(tech.Tier)})
+			}
+			tier := &areaItem.Items[len(areaItem.Items)-1]
+			tier.Items = append(tier.Items, SidebarItem{
+				Type:  "doc",
+				Label: g.plainName(tech.Key),
+				ID:    strings.ReplaceAll(g.output.SidebarDocID, config.KeyPlaceholder, tech.Key),
+			})
+		}
+		items = append(items, areaItem)
+	}
+	return items
+}
+
+// tierLabel returns the sidebar label of a tier
+func tierLabel(tier int) string {
+	return fmt.Sprintf("Tier %d", tier)
+}
+
+// plainName returns the name of a technology as plain text, or its formatted
+// key when it has no name
+func (g *JSONGenerator) plainName(key string) string {
+	if node, exists := g.tree.GetNode(key); exists && node.Tech.Name != "" {
+		return plainText(node.Tech.Name)
+	}
+	return formatTechName(key)
+}
+
+// sidebarsFile returns the sidebars file: a CommonJS module exporting the
+// sidebar items as a Docusaurus sidebars config
+func (g *JSONGenerator) sidebarsFile(techsByArea map[string][]TechnologyJSON) ([]byte, error) {
+	data, err := json.MarshalIndent(map[string][]SidebarItem{SidebarsName: g.sidebarItems(techsByArea)}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	var buf strings.Builder
+	buf.WriteString("// Generated by stellaris-data-parser, changes are overwritten by the next run\n")
+	buf.WriteString("/** @type {import('@docusaurus/plugin-content-docs').SidebarsConfig} */\n")
+	buf.WriteString("module.exports = ")
+	buf.Write(data)
+	buf.WriteString(";\n")
+	return []byte(buf.String()), nil
+}
@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/config"
+)
+
+func TestGenerateSidebars(t *testing.T) {
+	techTree := createTestTree()
+	node, _ := techTree.GetNode("tech_test_2")
+	node.Tech.Name = "§YBlue§! Lasers"
+
+	generator := NewJSONGenerator(techTree)
+	generator.SetSidebars(true)
+	outputDir := t.TempDir()
+	if err := generator.GenerateJSONFiles(outputDir); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "sidebars.js"))
+	if err != nil {
+		t.Fatalf("Failed to read sidebars: %v", err)
+	}
+	text := string(content)
+	start := strings.Index(text, "module.exports = ")
+	if start < 0 || !strings.HasSuffix(text, ";\n") {
+		t.Fatalf("Expected a CommonJS module, got %s", text)
+	}
+	var sidebars map[string][]SidebarItem
+	if err := json.Unmarshal([]byte(strings.TrimSuffix(text[start+len("module.exports = "):], ";\n")), &sidebars); err != nil {
+		t.Fatalf("Expected the sidebars as an object literal: %v", err)
+	}
+
+	areas := sidebars[SidebarsName]
+	if len(areas) != 2 || areas[0].Type != "category" || areas[0].Label == "" {
+		t.Fatalf("Expected a category for each area, got %+v", areas)
+	}
+	var docs []SidebarItem
+	for _, area := range areas {
+		for _, tier := range area.Items {
+			if tier.Type != "category" || !strings.HasPrefix(tier.Label, "Tier ") {
+				t.Errorf("Expected tier categories, got %+v", tier)
+			}
+			docs = append(docs, tier.Items...)
+		}
+	}
+	if len(docs) != 3 {
+		t.Fatalf("Expected a doc for each technology, got %+v", docs)
+	}
+	for _, doc := range docs {
+		if doc.Type != "doc" || !strings.HasPrefix(doc.ID, "technologies/tech_test_") {
+			t.Errorf("Expected doc ids from the default template, got %+v", doc)
+		}
+		if doc.ID == "technologies/tech_test_2" && doc.Label != "Blue Lasers" {
+			t.Errorf("Expected a plain-text label, got %q", doc.Label)
+		}
+	}
+}
+
+func TestSidebarItemsByTier(t *testing.T) {
+	generator := NewJSONGenerator(createTestTree())
+	output := config.Default().Output
+	output.SidebarDocID = "docs/" + config.KeyPlaceholder
+	generator.SetOutputConfig(output)
+
+	items := generator.sidebarItems(map[string][]TechnologyJSON{
+		"physics": {
+			{Key: "tech_test_2", Area: "physics", Tier: 1, Order: 1},
+			{Key: "tech_test_1", Area: "physics", Tier: 0, Order: 0},
+		},
+	})
+	if len(items) != 1 || len(items[0].Items) != 2 {
+		t.Fatalf("Expected one area with two tiers, got %+v", items)
+	}
+	tiers := items[0].Items
+	if tiers[0].Label != "Tier 0" || tiers[1].Label != "Tier 1" {
+		t.Errorf("Expected tiers in ascending order, got %q, %q", tiers[0].Label, tiers[1].Label)
+	}
+	if tiers[0].Items[0].ID != "docs/tech_test_1" || tiers[1].Items[0].ID != "docs/tech_test_2" {
+		t.Errorf("Expected doc ids from the configured template, got %+v", tiers)
+	}
+}
@@ -0,0 +1,89 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/danaketh/StellarisDataParser/lib/config"
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+// SetSubgraphs enables writing a subgraph file for each exported technology
+func (g *JSONGenerator) SetSubgraphs(enabled bool) {
+	g.subgraphs = enabled
+}
+
+// SubgraphFileName returns the file name of a technology's subgraph file
+func (g *JSONGenerator) SubgraphFileName(key string) string {
+	return g.dataFileName(strings.ReplaceAll(g.output.SubgraphFile, config.KeyPlaceholder, key))
+}
+
+// SubgraphData returns the dependency context of a technology: every
+// technology it depends on and every technology depending on it, in research
+// order, and the prerequisite edges between them. It returns false if the
+// technology is unknown.
+func (g *JSONGenerator) SubgraphData(key string) (map[string]interface{}, bool) {
+	ancestors, exists := g.tree.Ancestors(key)
+	if !exists {
+		return nil, false
+	}
+	descendants, _ := g.tree.Descendants(key)
+	node, _ := g.tree.GetNode(key)
+
+	nodes := map[*tree.TechNode]bool{node: true}
+	for _, n := range append(append([]*tree.TechNode(nil), ancestors...), descendants...) {
+		nodes[n] = true
+	}
+
+	edges := [][2]string{}
+	for n := range nodes {
+		for _, dependency := range n.Dependencies {
+			if nodes[dependency] {
+				edges = append(edges, [2]string{dependency.Tech.Key, n.Tech.Key})
+			}
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i][0] == edges[j][0] {
+			return edges[i][1] < edges[j][1]
+		}
+		return edges[i][0] < edges[j][0]
+	})
+
+	return map[string]interface{}{
+		"key":         key,
+		"ancestors":   nodeKeys(ancestors),
+		"descendants": nodeKeys(descendants),
+		"edges":       edges,
+	}, true
+}
+
+// writeSubgraphs writes the subgraph file of each exported technology
+func (g *JSONGenerator) writeSubgraphs(ctx context.Context, outputDir string, exported []*models.Technology) error {
+	for _, tech := range exported {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		data, _ := g.SubgraphData(tech.Key)
+		path, err := prepareOutputPath(outputDir, g.SubgraphFileName(tech.Key))
+		if err != nil {
+			return fmt.Errorf("failed to create subgraph directory: %w", err)
+		}
+		if err := g.writeJSONFile(path, data); err != nil {
+			return fmt.Errorf("failed to write subgraph of %s: %w", tech.Key, err)
+		}
+	}
+	return nil
+}
+
+// nodeKeys returns the technology keys of nodes
+func nodeKeys(nodes []*tree.TechNode) []string {
+	keys := make([]string, len(nodes))
+	for i, node := range nodes {
+		keys[i] = node.Tech.Key
+	}
+	return keys
+}
@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+// TemplateExtension is stripped from the name of a template to get the name
+// of the file it renders, e.g. wiki.txt.tmpl renders wiki.txt
+const TemplateExtension = ".tmpl"
+
+// TemplateData is the data a template is executed with
+type TemplateData struct {
+	SchemaVersion int
+	Technologies  []TechnologyJSON            // Exported technologies in research order
+	ByArea        map[string][]TechnologyJSON // Exported technologies per area, by level, then key, as in the research files
+	Metadata      MetadataJSON
+	Tree          *tree.TechTree // The whole tree, including technologies left out by filters
+}
+
+// TemplateFuncs returns the functions available to templates besides the
+// text/template builtins
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"join":    strings.Join,
+		"lower":   strings.ToLower,
+		"upper":   strings.ToUpper,
+		"replace": strings.ReplaceAll,
+		"repeat":  strings.Repeat,
+		"json": func(v interface{}) (string, error) {
+			content, err := json.Marshal(v)
+			return string(content), err
+		},
+		"add": func(a, b int) int { return a + b },
+	}
+}
+
+// LoadTemplate reads and parses a text/template file. The template is named
+// after the file.
+func LoadTemplate(path string) (*template.Template, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(TemplateFuncs()).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// TemplateFileName returns the name of the file a template renders: its name
+// without the .tmpl extension
+func TemplateFileName(tmpl *template.Template) string {
+	return strings.TrimSuffix(tmpl.Name(), TemplateExtension)
+}
+
+// SetTemplates sets templates rendered into the output directory along with
+// the JSON files, each to the file named by TemplateFileName
+func (g *JSONGenerator) SetTemplates(templates []*template.Template) {
+	g.templates = templates
+}
+
+// templateData returns the data templates are executed with, from the
+// exported technologies of each area
+func (g *JSONGenerator) templateData(techsByArea map[string][]TechnologyJSON) TemplateData {
+	technologies := []TechnologyJSON{}
+	for _, techs := range techsByArea {
+		technologies = append(technologies, techs...)
+	}
+	sort.Slice(technologies, func(i, j int) bool { return technologies[i].Order < technologies[j].Order })
+
+	return TemplateData{
+		SchemaVersion: SchemaVersion,
+		Technologies:  technologies,
+		ByArea:        techsByArea,
+		Metadata:      g.Metadata(),
+		Tree:          g.tree,
+	}
+}
+
+// writeTemplates renders each template set with SetTemplates
+func (g *JSONGenerator) writeTemplates(ctx context.Context, outputDir string, techsByArea map[string][]TechnologyJSON) error {
+	if len(g.templates) == 0 {
+		return nil
+	}
+	data := g.templateData(techsByArea)
+	for _, tmpl := range g.templates {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var content bytes.Buffer
+		if err := tmpl.Execute(&content, data); err != nil {
+			return fmt.Errorf("failed to render template %s: %w", tmpl.Name(), err)
+		}
+		path, err := prepareOutputPath(outputDir, TemplateFileName(tmpl))
+		if err != nil {
+			return fmt.Errorf("failed to create directory for template %s: %w", tmpl.Name(), err)
+		}
+		if err := g.writeFile(path, content.Bytes()); err != nil {
+			return fmt.Errorf("failed to write template %s: %w", tmpl.Name(), err)
+		}
+	}
+	return nil
+}
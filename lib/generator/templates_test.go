@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestLoadTemplate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "wiki.txt.tmpl")
+	if err := os.WriteFile(path, []byte(`{{upper "x"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := LoadTemplate(path)
+	if err != nil {
+		t.Fatalf("Failed to load template: %v", err)
+	}
+	if name := TemplateFileName(tmpl); name != "wiki.txt" {
+		t.Errorf("Expected wiki.txt, got %q", name)
+	}
+
+	if err := os.WriteFile(path, []byte(`{{.Unclosed`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTemplate(path); err == nil {
+		t.Error("Expected an error for an invalid template")
+	}
+	if _, err := LoadTemplate(filepath.Join(dir, "missing.tmpl")); err == nil {
+		t.Error("Expected an error for a missing template")
+	}
+}
+
+func TestGenerateTemplates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "techs.md.tmpl")
+	content := `{{range .Technologies}}{{.Key}} {{.Area}} {{join .Prerequisites ","}}
+{{end}}{{len (index .ByArea "physics")}} {{len .Metadata.Areas}} {{len .Tree.GetAllNodes}} {{json .SchemaVersion}}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := LoadTemplate(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generator := NewJSONGenerator(createTestTree())
+	generator.SetTemplates([]*template.Template{tmpl})
+	generator.SetSubtrees([]string{"tech_test_2"})
+
+	outputDir := t.TempDir()
+	if err := generator.GenerateJSONFiles(outputDir); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	rendered, err := os.ReadFile(filepath.Join(outputDir, "techs.md"))
+	if err != nil {
+		t.Fatalf("Expected the template to be rendered: %v", err)
+	}
+	expected := "tech_test_2 physics tech_test_1\ntech_test_3 engineering tech_test_2\n1 2 3 1"
+	if string(rendered) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, rendered)
+	}
+
+	found := false
+	for _, file := range generator.GeneratedFiles() {
+		found = found || strings.HasSuffix(file, "techs.md")
+	}
+	if !found {
+		t.Error("Expected the rendered file among the generated files")
+	}
+}
@@ -0,0 +1,907 @@
+package generator
+
+// typeDefinitions describes the JSON files written by the generator for
+// TypeScript consumers. Keep it in sync with the structs of schema.go;
+// TestTypeDefinitionsMatchTechnologyData and TestTypeDefinitionsMetadata
+// check the technology and metadata fields.
+const typeDefinitions = `// Generated by stellaris-data-parser. Do not edit.
+// Types of the JSON files written by the parse command.
+
+/** Version of the format of the JSON files, the schemaVersion of each file */
+export type SchemaVersion = 1;
+
+/** Research cost of one level of a repeatable technology */
+export interface LevelCost {
+  level: number;
+  cost: number;
+}
+
+/** How a technology is acquired */
+export type Acquisition = "start" | "research" | "event" | "insight";
+
+/** A technology, as listed in the research-<area>.json files */
+export interface Technology {
+  key: string;
+  name: string;
+  description: string;
+  cost: number;
+  area: string;
+  tier: number;
+  /** Depth in the prerequisite tree, 0 for technologies without prerequisites */
+  level: number;
+  /** Earliest in-game year the technology is typically reachable */
+  estimatedYear: number;
+  /** Comma-separated list of categories */
+  category: string;
+  prerequisites: string[];
+  /** Index in a research order of all technologies where each comes after its prerequisites */
+  order: number;
+  /** Index in the same order, counting only the technologies of the area */
+  areaOrder: number;
+  cumulativeCost: CumulativeCost;
+  /** Layered layout position within the area, in grid units */
+  position: Position;
+  /** Keys of each prerequisites block; completing any one group unlocks the technology */
+  prerequisiteGroups: string[][];
+  weight: number;
+  sourceFile: string;
+  /** Icon name; the technology key when an override named after it replaces the game icon */
+  icon: string;
+  /** File name of the icon in the icon directory, .png or .svg */
+  iconFile: string;
+  isStartTech: boolean;
+  isDangerous: boolean;
+  isRare: boolean;
+  isEvent: boolean;
+  /** Granted through insight (First Contact) instead of being drawn as a research option */
+  isInsight: boolean;
+  acquisition: Acquisition;
+  isReverse: boolean;
+  isRepeatable: boolean;
+  /** Number of levels of a repeatable technology, -1 means unlimited */
+  levels: number;
+  costPerLevel: number;
+  isInfinite: boolean;
+  isGestalt: boolean;
+  isMegacorp: boolean;
+  /** Mod directory name, present for technologies defined by a mod */
+  mod?: string;
+  /** Badged icon name, present for repeatable technologies with -repeatable-badges */
+  badgeIcon?: string;
+  /** PNG or SVG data URI of the icon, present with -embed-icons */
+  iconData?: string;
+  /** Present for repeatable technologies */
+  costTable?: LevelCost[];
+  /** Original script text of the technology, present with -raw */
+  raw?: RawSource;
+  /** The fields below are present with -full */
+  baseWeight?: number;
+  /** Chance to be among the research alternatives of its area, 0 for technologies that are not drawn */
+  offerChance?: number;
+  featureUnlocks?: string[];
+  aiUpdateType?: string;
+  gateway?: string;
+  isMachineEmpire?: boolean;
+  isHiveEmpire?: boolean;
+  isDriveAssimilator?: boolean;
+  isRogueServitor?: boolean;
+  /** Technology block keys the parser does not model */
+  extraFlags?: Record<string, ExtraFlagValue>;
+  /** Groups the technology belongs to when research options are drawn */
+  weightGroups?: string[];
+  /** Factor applied to the weight once a technology of the group is among the research options, by group */
+  modWeightIfGroupPicked?: Record<string, number>;
+}
+
+/** Script text of a definition and the lines of its file it spans */
+export interface RawSource {
+  file: string;
+  /** 1-based */
+  startLine: number;
+  /** 1-based, inclusive */
+  endLine: number;
+  /** Comments included */
+  text: string;
+}
+
+/** Research cost of a technology including the prerequisites needed first */
+export interface CumulativeCost {
+  total: number;
+  /** Part of the total spent in each research area */
+  byArea: Record<string, number>;
+}
+
+/** Grid position of a technology in the layout of its area */
+export interface Position {
+  /** Column within the layer, from 0 */
+  x: number;
+  /** Layer, equal to the level */
+  y: number;
+}
+
+/** Nested blocks keep their structure, and comparisons other than = are Comparison objects */
+export type ExtraFlagValue = boolean | number | string | Array<boolean | number | string> | ScriptBlock | Comparison;
+
+/** Contents of a research-<area>.json file */
+export interface ResearchFile {
+  schemaVersion: SchemaVersion;
+  area: string;
+  technologies: Technology[];
+}
+
+/** Contents of metadata.json */
+export interface Metadata {
+  schemaVersion: SchemaVersion;
+  areas: string[];
+  tiers: number[];
+  categories: string[];
+  maxLevel: number;
+  /** Display name and icon of each area */
+  areaDetails: Record<string, MetadataEntry>;
+  /** Display name and icon of each category */
+  categoryDetails: Record<string, MetadataEntry>;
+  /** Game colors and a colorblind-safe alternative */
+  colors: {
+    game: Palette;
+    accessible: Palette;
+  };
+  /** Technologies that can never be researched or are not connected to the tree */
+  issues: TreeIssue[];
+  /** Sizes of the icon variants in icons/<size>/, present with -icon-sizes */
+  iconSizes?: number[];
+  /** Stellaris version the data was read from, present when the game directory has launcher settings */
+  gameVersion?: GameVersion;
+}
+
+/** A Stellaris version, from the launcher settings of the game directory */
+export interface GameVersion {
+  /** Version number, e.g. 3.12.4 */
+  version: string;
+  /** Release name, e.g. Andromeda */
+  name?: string;
+}
+
+/** A structural problem of a technology */
+export interface TreeIssue {
+  tech: string;
+  kind: "unreachable" | "orphan";
+  reason: string;
+}
+
+/** A research area or category */
+export interface MetadataEntry {
+  /** Localized, or formatted from the key */
+  name: string;
+  /** Path relative to the icon directory */
+  icon?: string;
+}
+
+/** CSS hex colors of research areas, tiers and rarities */
+export interface Palette {
+  areas: Record<string, string>;
+  /** Keyed by tier number */
+  tiers: Record<string, string>;
+  rarity: {
+    common: string;
+    rare: string;
+    dangerous: string;
+  };
+}
+
+/** An icon used by more than one technology */
+export interface IconUsage {
+  /** Icon name; the technology key when an override named after it replaces the game icon */
+  icon: string;
+  /** File name of the icon in the icon directory, .png or .svg */
+  iconFile: string;
+  technologies: string[];
+  /** Used by at least heavyReuseThreshold technologies */
+  heavyReuse: boolean;
+}
+
+/** Contents of icon-usage.json */
+export interface IconUsageFile {
+  schemaVersion: SchemaVersion;
+  heavyReuseThreshold: number;
+  /** Most used icons first */
+  sharedIcons: IconUsage[];
+}
+
+/** A technology icon that couldn't be converted */
+export interface MissingIcon {
+  icon: string;
+  /** notFound: no file in the game, the mods or the overrides; unreadable: found but not converted */
+  reason: "notFound" | "unreadable";
+  /** Why an unreadable icon failed */
+  error?: string;
+  technologies: string[];
+  /** A placeholder with the technology's initials was written in its place, with -icon-placeholders */
+  placeholder: boolean;
+}
+
+/** Contents of missing-icons.json */
+export interface MissingIconsFile {
+  schemaVersion: SchemaVersion;
+  /** Sorted by icon */
+  missingIcons: MissingIcon[];
+}
+
+/** Where a technology definition came from */
+export interface Definition {
+  sourceFile: string;
+  mod?: string;
+}
+
+/** A technology definition replaced by a later one */
+export interface Override {
+  key: string;
+  definition: Definition;
+  overriddenBy: Definition;
+}
+
+/** Contents of overrides.json */
+export interface OverridesFile {
+  schemaVersion: SchemaVersion;
+  overrides: Override[];
+}
+
+/** Name and description keys missing from one language */
+export interface LanguageCoverage {
+  language: string;
+  /** Name and description keys of all technologies */
+  expected: number;
+  missing: number;
+  /** Percentage of expected keys present, rounded to one decimal */
+  coverage: number;
+  missingNames: string[];
+  /** Description keys, ending in _desc */
+  missingDescriptions: string[];
+}
+
+/** Contents of localization-coverage.json */
+export interface LocalizationCoverageFile {
+  schemaVersion: SchemaVersion;
+  languages: LanguageCoverage[];
+}
+
+/** A numeric game define that affects research */
+export interface Define {
+  /** Namespace.KEY, e.g. NGameplay.TECH_COST_MULT */
+  key: string;
+  value: number;
+  explanation: string;
+}
+
+/** When technologies of a tier can be offered */
+export interface TierRule {
+  tier: number;
+  /** Technologies of the previous tier that must be researched first */
+  previouslyUnlocked: number;
+  explanation: string;
+}
+
+/** A research-related effect of a static modifier */
+export interface ModifierEffect {
+  key: string;
+  name: string;
+  value: number;
+}
+
+/** A static modifier with research-related effects */
+export interface StaticModifier {
+  key: string;
+  name: string;
+  effects: ModifierEffect[];
+  explanation: string;
+}
+
+/** Contents of mechanics.json, written with -mechanics */
+export interface MechanicsFile {
+  schemaVersion: SchemaVersion;
+  defines: Define[];
+  tiers: TierRule[];
+  staticModifiers: StaticModifier[];
+}
+
+/** A technology in graph.json, with its key as id */
+export interface GraphNode extends Technology {
+  id: string;
+}
+
+/** A prerequisite edge in graph.json, from the prerequisite to the technology it unlocks */
+export interface GraphLink {
+  source: string;
+  target: string;
+}
+
+/** Contents of graph.json, written with -graph */
+export interface GraphFile {
+  schemaVersion: SchemaVersion;
+  /** Exported technologies in research order */
+  nodes: GraphNode[];
+  links: GraphLink[];
+}
+
+/** Contents of subgraphs/<key>.json, written with -subgraphs */
+export interface SubgraphFile {
+  schemaVersion: SchemaVersion;
+  key: string;
+  /** Every technology the technology depends on, in research order */
+  ancestors: string[];
+  /** Every technology depending on the technology, in research order */
+  descendants: string[];
+  /** Prerequisite edges between the technologies, as [prerequisite, dependent] */
+  edges: [string, string][];
+}
+
+/** A technology in search-index.json, written with -search-index. objectID is for Algolia; use key as the lunr ref. */
+export interface SearchRecord {
+  objectID: string;
+  key: string;
+  /** Plain text, without markup */
+  name: string;
+  /** Plain text, without markup */
+  description: string;
+  area: string;
+  areaName: string;
+  tier: number;
+  /** Comma-separated list of categories */
+  category: string;
+}
+
+/** Contents of search-index.json: the exported technologies in research order. It has no schema version so it can be imported as it is. */
+export type SearchIndexFile = SearchRecord[];
+
+/** Raw script block, such as a condition tree or modifiers. Keys assigned more than once have an array of their values. */
+export type ScriptBlock = Record<string, unknown>;
+
+/** Value of a condition in a ScriptBlock compared with an operator other than =, such as num_owned_planets > 5 */
+export interface Comparison {
+  operator: ">" | ">=" | "<" | "<=" | "!=";
+  value: unknown;
+}
+
+/** An edict or campaign from common/edicts */
+export interface Edict {
+  key: string;
+  name: string;
+  description: string;
+  icon?: string;
+  /** Duration in days, 0 for edicts active until cancelled */
+  length: number;
+  /** One-time resource cost, by resource */
+  cost: Record<string, number>;
+  /** Monthly resource cost while active, by resource */
+  upkeep: Record<string, number>;
+  modifiers: ScriptBlock;
+  prerequisites: string[];
+  potential: ScriptBlock;
+  allow: ScriptBlock;
+  sourceFile: string;
+  mod?: string;
+}
+
+/** A choice of a policy */
+export interface PolicyOption {
+  key: string;
+  name: string;
+  description: string;
+  cost: Record<string, number>;
+  modifiers: ScriptBlock;
+  prerequisites: string[];
+  potential: ScriptBlock;
+  valid: ScriptBlock;
+}
+
+/** An empire policy from common/policies */
+export interface Policy {
+  key: string;
+  name: string;
+  description: string;
+  options: PolicyOption[];
+  potential: ScriptBlock;
+  allow: ScriptBlock;
+  sourceFile: string;
+  mod?: string;
+}
+
+/** A weapon slot of a ship section */
+export interface ComponentSlot {
+  name: string;
+  /** Slot size and type, such as large_turret */
+  template: string;
+  locator: string;
+}
+
+/** A ship section from common/section_templates */
+export interface SectionTemplate {
+  key: string;
+  name: string;
+  shipSize: string;
+  /** Section slot of the hull the section fits on */
+  slot: string;
+  icon?: string;
+  componentSlots: ComponentSlot[];
+  /** Number of utility slots by size: small, medium, large, aux */
+  utilitySlots: Record<string, number>;
+  cost: Record<string, number>;
+  prerequisites: string[];
+  sourceFile: string;
+  mod?: string;
+}
+
+/** A hull from common/ship_sizes with the sections fitting its slots */
+export interface ShipSize {
+  key: string;
+  name: string;
+  description: string;
+  class?: string;
+  icon?: string;
+  /** Base values such as max_hitpoints and max_speed */
+  stats: Record<string, number>;
+  modifiers: ScriptBlock;
+  cost: Record<string, number>;
+  upkeep: Record<string, number>;
+  /** Base build time in days */
+  buildTime: number;
+  sectionSlots: { name: string; locator: string }[];
+  /** Sections ordered by slot, then key */
+  sections: SectionTemplate[];
+  prerequisites: string[];
+  potential: ScriptBlock;
+  sourceFile: string;
+  mod?: string;
+}
+
+/** A planet district from common/districts */
+export interface District {
+  key: string;
+  name: string;
+  description: string;
+  icon?: string;
+  /** Base build time in days */
+  buildTime: number;
+  /** Limited by the district_<key>_max modifier of the planet */
+  capped: boolean;
+  cost: Record<string, number>;
+  upkeep: Record<string, number>;
+  /** Monthly resource output */
+  produces: Record<string, number>;
+  /** Planet modifiers of each district, such as jobs and housing */
+  modifiers: ScriptBlock;
+  /** Conditional planet modifiers, each with potential and modifier */
+  triggered: ScriptBlock[];
+  convertTo: string[];
+  prerequisites: string[];
+  potential: ScriptBlock;
+  allow: ScriptBlock;
+  sourceFile: string;
+  mod?: string;
+}
+
+/** A planet type from common/planet_classes */
+export interface PlanetClass {
+  key: string;
+  name: string;
+  description: string;
+  /** Species habitability depends on their preferred climate */
+  climate?: string;
+  colonizable: boolean;
+  initial: boolean;
+  extreme: boolean;
+  /** Maximum number of each capped district added by the class, by district */
+  districtCaps: Record<string, number>;
+  modifiers: ScriptBlock;
+  sourceFile: string;
+  mod?: string;
+}
+
+/** A relic from common/relics */
+export interface Relic {
+  key: string;
+  name: string;
+  description: string;
+  /** GFX_ sprite or texture path of the relic art */
+  portrait?: string;
+  /** Extracted art, relative to the icons directory */
+  iconFile?: string;
+  /** Victory score of owning the relic */
+  score: number;
+  /** Days before the relic can be activated again */
+  activationDuration: number;
+  /** Activation cost */
+  cost: Record<string, number>;
+  /** Country modifiers while owned, each with potential and modifier */
+  passiveEffects: ScriptBlock[];
+  activeEffect: ScriptBlock;
+  possible: ScriptBlock;
+  sourceFile: string;
+  mod?: string;
+}
+
+/** A chapter of an archaeological site */
+export interface ArchaeologyStage {
+  difficulty: number;
+  icon?: string;
+  /** Event fired when the chapter is completed */
+  event: string;
+}
+
+/** A dig site type from common/archaeological_site_types */
+export interface ArchaeologySite {
+  key: string;
+  name: string;
+  description: string;
+  picture?: string;
+  /** 0 when unlimited */
+  maxInstances: number;
+  /** Spawn weight, a number or a raw weight block */
+  weight: number | ScriptBlock | null;
+  stages: ArchaeologyStage[];
+  potential: ScriptBlock;
+  allow: ScriptBlock;
+  onRollFailed: ScriptBlock;
+  sourceFile: string;
+  mod?: string;
+}
+
+/** A choice of an event */
+export interface EventOption {
+  nameKey: string;
+  name: string;
+  /** Raw condition tree for showing the option */
+  trigger: ScriptBlock;
+  /** Remaining contents of the option, as written in the script */
+  effects: ScriptBlock;
+}
+
+/** An event from the events directory */
+export interface StellarisEvent {
+  id: string;
+  /** Block name, such as country_event or planet_event */
+  type: string;
+  namespace: string;
+  titleKey?: string;
+  /** Every description, conditional ones included */
+  descriptionKeys: string[];
+  title: string;
+  /** Text of the first description */
+  description: string;
+  picture?: string;
+  /** No window is shown */
+  hidden: boolean;
+  /** Only fired by effects or on_actions */
+  triggeredOnly: boolean;
+  trigger: ScriptBlock;
+  immediate: ScriptBlock;
+  options: EventOption[];
+  sourceFile: string;
+  mod?: string;
+}
+
+/** An anomaly from common/anomalies */
+export interface Anomaly {
+  key: string;
+  name: string;
+  descriptionKey?: string;
+  description: string;
+  picture?: string;
+  /** Research difficulty */
+  level: number;
+  /** Chance that nothing spawns, 0 to 1 */
+  nullSpawnChance: number;
+  /** Spawns at most once per empire */
+  maxOnce: boolean;
+  /** A number or a raw weight block with modifiers */
+  spawnChance: number | ScriptBlock | null;
+  /** Raw outcomes, keyed by outcome weight or index */
+  onSuccess: ScriptBlock;
+  onFail: ScriptBlock;
+  /** Events fired by the anomaly, in order of appearance */
+  linkedEvents: string[];
+  sourceFile: string;
+  mod?: string;
+}
+
+/** A special project from common/special_projects */
+export interface SpecialProject {
+  key: string;
+  name: string;
+  descriptionKey?: string;
+  description: string;
+  picture?: string;
+  /** Research points, 0 for projects completed by time */
+  cost: number;
+  /** 0 for projects completed with research points */
+  daysToResearch: number;
+  department?: string;
+  /** Scope of the completion event, such as ship_event */
+  eventScope?: string;
+  /** Ships and leaders required to work on the project */
+  requirements: ScriptBlock;
+  onSuccess: ScriptBlock;
+  onFail: ScriptBlock;
+  linkedEvents: string[];
+  sourceFile: string;
+  mod?: string;
+}
+
+/** A define from common/defines */
+export interface GameDefine {
+  /** Namespace.KEY, e.g. NGameplay.TECH_COST_MULT */
+  key: string;
+  namespace: string;
+  name: string;
+  type: 'number' | 'string' | 'bool' | 'list' | 'block';
+  value: number | string | boolean | Array<number | string> | ScriptBlock;
+  sourceFile: string;
+  mod?: string;
+}
+
+/** A leader trait from common/traits */
+export interface LeaderTrait {
+  key: string;
+  name: string;
+  description: string;
+  icon?: string;
+  /** Leader classes that can have the trait, empty for every class */
+  classes: string[];
+  /** Trait points */
+  cost: number;
+  opposites: string[];
+  /** Technologies that make the trait available */
+  prerequisites: string[];
+  modifiers: ScriptBlock;
+  selfModifiers: ScriptBlock;
+  councilorModifiers: ScriptBlock;
+  potential: ScriptBlock;
+  sourceFile: string;
+  mod?: string;
+}
+
+/** A leader class from common/leader_classes */
+export interface LeaderClass {
+  key: string;
+  name: string;
+  description: string;
+  cost: Record<string, number>;
+  upkeep: Record<string, number>;
+  settings: ScriptBlock;
+  sourceFile: string;
+  mod?: string;
+}
+
+/** A council position from common/governments/councils */
+export interface CouncilPosition {
+  key: string;
+  name: string;
+  description: string;
+  leaderClasses: string[];
+  potential: ScriptBlock;
+  modifiers: ScriptBlock;
+  sourceFile: string;
+  mod?: string;
+}
+
+/** An espionage operation from common/espionage_operation_types */
+export interface EspionageOperation {
+  key: string;
+  name: string;
+  description: string;
+  icon?: string;
+  category?: string;
+  /** A number or a raw block with modifiers */
+  difficulty: number | ScriptBlock | null;
+  /** Length of each phase of the operation */
+  daysPerPhase: number;
+  cost: Record<string, number>;
+  potential: ScriptBlock;
+  allow: ScriptBlock;
+  /** Events fired by the operation, in order of appearance */
+  linkedEvents: string[];
+  sourceFile: string;
+  mod?: string;
+}
+
+/** A stage of a situation */
+export interface SituationStage {
+  key: string;
+  name: string;
+  /** Progress at which the stage ends */
+  end: number;
+  icon?: string;
+  modifiers: ScriptBlock;
+}
+
+/** A way an empire can handle a situation */
+export interface SituationApproach {
+  key: string;
+  nameKey?: string;
+  name: string;
+  description: string;
+  icon?: string;
+  potential: ScriptBlock;
+  allow: ScriptBlock;
+  modifiers: ScriptBlock;
+}
+
+/** A situation from common/situations */
+export interface Situation {
+  key: string;
+  name: string;
+  description: string;
+  category?: string;
+  /** A number or a raw block with modifiers */
+  monthlyProgress: number | ScriptBlock | null;
+  stages: SituationStage[];
+  approaches: SituationApproach[];
+  linkedEvents: string[];
+  sourceFile: string;
+  mod?: string;
+}
+
+/** A diplomatic action from common/diplomatic_actions */
+export interface DiplomaticAction {
+  key: string;
+  name: string;
+  description: string;
+  icon?: string;
+  potential: ScriptBlock;
+  possible: ScriptBlock;
+  /** Conditions for proposing the action to the AI */
+  proposable: ScriptBlock;
+  linkedEvents: string[];
+  sourceFile: string;
+  mod?: string;
+}
+
+/** A subject agreement preset from common/agreement_presets */
+export interface AgreementPreset {
+  key: string;
+  name: string;
+  description: string;
+  icon?: string;
+  potential: ScriptBlock;
+  /** Value of each subject term, by term */
+  terms: ScriptBlock;
+  sourceFile: string;
+  mod?: string;
+}
+
+/** A subject term value from common/agreement_term_values */
+export interface SubjectTerm {
+  key: string;
+  name: string;
+  description: string;
+  term: string;
+  value: unknown;
+  potential: ScriptBlock;
+  modifiers: ScriptBlock;
+  sourceFile: string;
+  mod?: string;
+}
+
+/** Contents of edicts.json, written with -content edicts */
+export interface EdictsFile {
+  schemaVersion: SchemaVersion;
+  edicts: Edict[];
+}
+
+/** Contents of policies.json, written with -content policies */
+export interface PoliciesFile {
+  schemaVersion: SchemaVersion;
+  policies: Policy[];
+}
+
+/** Contents of ships.json, written with -content ships */
+export interface ShipsFile {
+  schemaVersion: SchemaVersion;
+  ships: ShipSize[];
+}
+
+/** Contents of districts.json, written with -content districts */
+export interface DistrictsFile {
+  schemaVersion: SchemaVersion;
+  districts: District[];
+}
+
+/** Contents of planets.json, written with -content planets */
+export interface PlanetsFile {
+  schemaVersion: SchemaVersion;
+  planets: PlanetClass[];
+}
+
+/** Contents of relics.json, written with -content relics */
+export interface RelicsFile {
+  schemaVersion: SchemaVersion;
+  relics: Relic[];
+}
+
+/** Contents of archaeology.json, written with -content archaeology */
+export interface ArchaeologyFile {
+  schemaVersion: SchemaVersion;
+  archaeology: ArchaeologySite[];
+}
+
+/** Contents of events.json, written with -content events */
+export interface EventsFile {
+  schemaVersion: SchemaVersion;
+  events: StellarisEvent[];
+}
+
+/** Contents of anomalies.json, written with -content anomalies */
+export interface AnomaliesFile {
+  schemaVersion: SchemaVersion;
+  anomalies: Anomaly[];
+  specialProjects: SpecialProject[];
+}
+
+/** Contents of defines.json, written with -content defines */
+export interface DefinesFile {
+  schemaVersion: SchemaVersion;
+  defines: GameDefine[];
+}
+
+/** Contents of leaders.json, written with -content leaders */
+export interface LeadersFile {
+  schemaVersion: SchemaVersion;
+  traits: LeaderTrait[];
+  classes: LeaderClass[];
+  councilPositions: CouncilPosition[];
+  /** Technology key -> leader traits it makes available */
+  traitsByTechnology: Record<string, string[]>;
+}
+
+/** Contents of espionage.json, written with -content espionage */
+export interface EspionageFile {
+  schemaVersion: SchemaVersion;
+  espionage: EspionageOperation[];
+}
+
+/** Contents of situations.json, written with -content situations */
+export interface SituationsFile {
+  schemaVersion: SchemaVersion;
+  situations: Situation[];
+}
+
+/** Contents of diplomacy.json, written with -content diplomacy */
+export interface DiplomacyFile {
+  schemaVersion: SchemaVersion;
+  actions: DiplomaticAction[];
+  agreementPresets: AgreementPreset[];
+  subjectTerms: SubjectTerm[];
+}
+
+/** Contents of manifest.json */
+export interface Manifest {
+  version: number;
+  /** Output path relative to the output directory -> fingerprint */
+  files: Record<string, string>;
+  /** Output path relative to the output directory -> file as written */
+  outputs?: Record<string, ManifestOutput>;
+  /** What the run read, when known */
+  inputs?: ManifestInputs;
+}
+
+export interface ManifestInputs {
+  /** Fingerprint of the options the output depends on */
+  settings: string;
+  /** Input file -> hash of its content */
+  files: Record<string, string>;
+}
+
+export interface ManifestOutput {
+  /** Hex-encoded SHA-256 of the content */
+  sha256: string;
+  /** Size in bytes */
+  size: number;
+  /** RFC 3339 time the content was first generated */
+  generatedAt: string;
+}
+`
+
+// TypeDefinitions returns the TypeScript declarations of the generated JSON
+// files
+func TypeDefinitions() string {
+	return typeDefinitions
+}
@@ -0,0 +1,147 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+// interfaceFields returns the field names declared by a TypeScript interface
+// in the type definitions
+func interfaceFields(t *testing.T, name string) map[string]bool {
+	t.Helper()
+
+	block := regexp.MustCompile(`(?s)export interface ` + name + ` \{\n(.*?)\n\}`).FindStringSubmatch(TypeDefinitions())
+	if block == nil {
+		t.Fatalf("Expected interface %s in the type definitions", name)
+	}
+
+	fields := make(map[string]bool)
+	for _, match := range regexp.MustCompile(`(?m)^  (\w+)\??:`).FindAllStringSubmatch(block[1], -1) {
+		fields[match[1]] = true
+	}
+	return fields
+}
+
+// jsonFields returns the field names v is encoded with
+func jsonFields(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+
+	content, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(content, &fields); err != nil {
+		t.Fatal(err)
+	}
+	return fields
+}
+
+func TestTypeDefinitionsMatchTechnologyData(t *testing.T) {
+	// A technology that sets every optional field
+	techTree := tree.NewTechTree(map[string]*models.Technology{
+		"tech_modded_repeatable": {
+			Key:          "tech_modded_repeatable",
+			Cost:         1000,
+			Area:         "physics",
+			Mod:          "my_mod",
+			IsRepeatable: true,
+			Levels:       -1,
+			CostPerLevel: 100,
+			Source:       &models.Source{File: "mod_techs.txt", StartLine: 1, EndLine: 1, Text: "tech_modded_repeatable = {}"},
+			Extra:        map[string]interface{}{"my_mod_hidden": true},
+		},
+	})
+	node, _ := techTree.GetNode("tech_modded_repeatable")
+
+	overridesDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(overridesDir, "tech_modded_repeatable.svg"), []byte("<svg/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	generator := NewJSONGenerator(techTree)
+	generator.SetRepeatableBadges(true)
+	generator.SetFull(true)
+	generator.SetRawSource(true)
+	generator.SetGameDir(t.TempDir())
+	generator.SetIconOverrides(overridesDir)
+	generator.SetEmbedIcons(&IconEmbedding{})
+	techData := jsonFields(t, generator.Technology(node))
+
+	declared := interfaceFields(t, "Technology")
+	for field := range techData {
+		if !declared[field] {
+			t.Errorf("Expected field '%s' to be declared in the Technology interface", field)
+		}
+	}
+	for field := range declared {
+		if _, exists := techData[field]; !exists {
+			t.Errorf("Declared field '%s' is not written by the generator", field)
+		}
+	}
+}
+
+func TestTypeDefinitionsMetadata(t *testing.T) {
+	declared := interfaceFields(t, "Metadata")
+	written := jsonFields(t, MetadataJSON{IconSizes: []int{32}, GameVersion: &models.GameVersion{Version: "3.12.4"}})
+	for field := range written {
+		if !declared[field] {
+			t.Errorf("Expected field '%s' to be declared in the Metadata interface", field)
+		}
+	}
+	for field := range declared {
+		if _, exists := written[field]; !exists {
+			t.Errorf("Declared field '%s' is not written by the generator", field)
+		}
+	}
+}
+
+func TestGenerateTypeDefinitions(t *testing.T) {
+	generator := NewJSONGenerator(createTestTree())
+
+	tmpDir := t.TempDir()
+	if err := generator.GenerateJSONFiles(tmpDir); err != nil {
+		t.Fatalf("Failed to generate JSON files: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "technologies.d.ts"))
+	if err != nil {
+		t.Fatalf("Expected technologies.d.ts to be created: %v", err)
+	}
+	if !strings.Contains(string(content), "export interface ResearchFile") {
+		t.Error("Expected the ResearchFile interface to be declared")
+	}
+}
+
+func TestTypeDefinitionsSearchRecord(t *testing.T) {
+	declared := interfaceFields(t, "SearchRecord")
+	written := jsonFields(t, SearchRecord{})
+	if len(declared) != len(written) {
+		t.Errorf("Expected %d declared fields, got %d", len(written), len(declared))
+	}
+	for field := range written {
+		if !declared[field] {
+			t.Errorf("Expected field '%s' to be declared in the SearchRecord interface", field)
+		}
+	}
+}
+
+func TestTypeDefinitionsMissingIcon(t *testing.T) {
+	declared := interfaceFields(t, "MissingIcon")
+	written := jsonFields(t, MissingIcon{Error: "failed"})
+	if len(declared) != len(written) {
+		t.Errorf("Expected %d declared fields, got %d", len(written), len(declared))
+	}
+	for field := range written {
+		if !declared[field] {
+			t.Errorf("Expected field '%s' to be declared in the MissingIcon interface", field)
+		}
+	}
+}
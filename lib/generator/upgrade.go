@@ -0,0 +1,210 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+)
+
+// UpgradeResult describes the upgrade of a generated file
+type UpgradeResult struct {
+	From int // Schema version of the file before the upgrade
+	// Fields of the current schema the file lacks that can only be written
+	// by generating it again, sorted
+	Missing []string
+}
+
+// Upgraded reports whether the file had an older schema version
+func (r UpgradeResult) Upgraded() bool {
+	return r.From < SchemaVersion
+}
+
+// migration upgrades the decoded contents of a file from version from to
+// from+1. It returns the fields it could not derive.
+type migration struct {
+	from    int
+	upgrade func(data map[string]interface{}) []string
+}
+
+// migrations are applied in order to files older than SchemaVersion
+var migrations = []migration{
+	{from: 0, upgrade: upgradeUnversioned},
+}
+
+// Upgrade converts the contents of a JSON file written by the generator to
+// SchemaVersion. Fields that can be derived from the file are filled in;
+// fields that need the game data are reported as missing. Files already at
+// SchemaVersion are returned unchanged, and files of a newer version are an
+// error.
+func Upgrade(content []byte) ([]byte, UpgradeResult, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var data map[string]interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return nil, UpgradeResult{}, fmt.Errorf("failed to parse file: %w", err)
+	}
+	if data == nil {
+		return nil, UpgradeResult{}, fmt.Errorf("expected a JSON object")
+	}
+
+	result := UpgradeResult{}
+	if version, exists := data["schemaVersion"]; exists {
+		number, ok := version.(json.Number)
+		from, err := number.Int64()
+		if !ok || err != nil || from < 0 {
+			return nil, result, fmt.Errorf("invalid schemaVersion %v", version)
+		}
+		result.From = int(from)
+	}
+	if result.From > SchemaVersion {
+		return nil, result, fmt.Errorf("schema version %d is newer than %d, upgrade stellaris-data-parser", result.From, SchemaVersion)
+	}
+	if result.From == SchemaVersion {
+		return content, result, nil
+	}
+
+	missing := make(map[string]bool)
+	for _, m := range migrations {
+		if m.from < result.From {
+			continue
+		}
+		for _, field := range m.upgrade(data) {
+			missing[field] = true
+		}
+	}
+	for field := range missing {
+		result.Missing = append(result.Missing, field)
+	}
+	sort.Strings(result.Missing)
+
+	// Minified files stay minified
+	delete(data, "schemaVersion")
+	upgraded, err := encodeJSONFile(data, !minified(content))
+	if err != nil {
+		return nil, result, fmt.Errorf("failed to encode file: %w", err)
+	}
+	return upgraded, result, nil
+}
+
+// minified reports whether encoded JSON has no line breaks before its end
+func minified(content []byte) bool {
+	return !bytes.Contains(bytes.TrimSpace(content), []byte("\n"))
+}
+
+// upgradeUnversioned upgrades a file written before schema versioning. Older
+// releases wrote fewer technology and metadata fields; the ones that follow
+// from other fields are derived.
+func upgradeUnversioned(data map[string]interface{}) []string {
+	var missing []string
+
+	// Research files list technologies, the graph file lists them as nodes
+	for _, list := range []string{"technologies", "nodes"} {
+		techs, _ := data[list].([]interface{})
+		for _, item := range techs {
+			if tech, ok := item.(map[string]interface{}); ok {
+				missing = append(missing, upgradeTechnology(tech)...)
+			}
+		}
+	}
+
+	if _, isMetadata := data["maxLevel"]; isMetadata {
+		missing = append(missing, upgradeMetadata(data)...)
+	}
+	return missing
+}
+
+// technologyFieldsFromGameData are technology fields computed from the whole
+// tree, which can't be derived from a single technology
+var technologyFieldsFromGameData = []string{"level", "estimatedYear", "order", "areaOrder", "cumulativeCost", "position"}
+
+// upgradeTechnology derives the fields added to technologies since the first
+// releases
+func upgradeTechnology(tech map[string]interface{}) []string {
+	setDefault := func(field string, value func() interface{}) {
+		if _, exists := tech[field]; !exists {
+			tech[field] = value()
+		}
+	}
+	flag := func(field string) bool {
+		value, _ := tech[field].(bool)
+		return value
+	}
+
+	setDefault("isInsight", func() interface{} { return false })
+	setDefault("acquisition", func() interface{} {
+		switch {
+		case flag("isStartTech"):
+			return "start"
+		case flag("isInsight"):
+			return "insight"
+		case flag("isEvent"):
+			return "event"
+		}
+		return "research"
+	})
+	setDefault("isInfinite", func() interface{} {
+		levels, _ := tech["levels"].(json.Number)
+		count, _ := levels.Int64()
+		return flag("isRepeatable") && count < 0
+	})
+	setDefault("prerequisiteGroups", func() interface{} {
+		if prerequisites, _ := tech["prerequisites"].([]interface{}); len(prerequisites) > 0 {
+			return []interface{}{prerequisites}
+		}
+		return []interface{}{}
+	})
+	setDefault("iconFile", func() interface{} {
+		icon, _ := tech["icon"].(string)
+		return iconFileName(icon, "")
+	})
+
+	var missing []string
+	for _, field := range technologyFieldsFromGameData {
+		if _, exists := tech[field]; !exists {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+// upgradeMetadata derives the metadata fields added since the first releases
+func upgradeMetadata(data map[string]interface{}) []string {
+	if _, exists := data["colors"]; !exists {
+		var tiers []int
+		list, _ := data["tiers"].([]interface{})
+		for _, tier := range list {
+			if number, ok := tier.(json.Number); ok {
+				if value, err := number.Int64(); err == nil {
+					tiers = append(tiers, int(value))
+				}
+			}
+		}
+		data["colors"] = ColorPalettes(tiers)
+	}
+
+	// Names are formatted from the keys, as when no localization is loaded
+	details := func(field, list string, icon func(key string) string) {
+		if _, exists := data[field]; exists {
+			return
+		}
+		entries := make(map[string]MetadataEntry)
+		keys, _ := data[list].([]interface{})
+		for _, item := range keys {
+			if key, ok := item.(string); ok {
+				entries[key] = MetadataEntry{Name: formatTechName(key), Icon: icon(key)}
+			}
+		}
+		data[field] = entries
+	}
+	details("areaDetails", "areas", func(area string) string {
+		return path.Join(AreaIconsOutputDir, area+".png")
+	})
+	details("categoryDetails", "categories", func(string) string { return "" })
+
+	if _, exists := data["issues"]; !exists {
+		return []string{"issues"}
+	}
+	return nil
+}
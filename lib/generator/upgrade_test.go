@@ -0,0 +1,133 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUpgradeUnversioned(t *testing.T) {
+	content := []byte(`{
+  "area": "physics",
+  "technologies": [
+    {"key": "tech_lasers_1", "icon": "tech_lasers_1", "isStartTech": false, "isEvent": true, "isRepeatable": false, "levels": 0, "prerequisites": ["tech_a", "tech_b"], "cost": 12345678901},
+    {"key": "tech_repeatable", "icon": "tech_repeatable", "isRepeatable": true, "levels": -1, "prerequisites": [], "level": 3, "estimatedYear": 2300, "order": 1, "areaOrder": 1, "cumulativeCost": {}, "position": {}}
+  ]
+}`)
+
+	upgraded, result, err := Upgrade(content)
+	if err != nil {
+		t.Fatalf("Failed to upgrade: %v", err)
+	}
+	if result.From != 0 || !result.Upgraded() {
+		t.Errorf("Expected an upgrade from version 0, got %+v", result)
+	}
+	expectedMissing := []string{"areaOrder", "cumulativeCost", "estimatedYear", "level", "order", "position"}
+	if !reflect.DeepEqual(result.Missing, expectedMissing) {
+		t.Errorf("Expected missing fields %v, got %v", expectedMissing, result.Missing)
+	}
+	if !strings.HasPrefix(string(upgraded), "{\n  \"schemaVersion\": 1,\n") {
+		t.Errorf("Expected schemaVersion first, got:\n%s", upgraded)
+	}
+
+	var file ResearchFileJSON
+	if err := json.Unmarshal(upgraded, &file); err != nil {
+		t.Fatalf("Expected the upgraded file to decode as a research file: %v", err)
+	}
+	lasers, repeatable := file.Technologies[0], file.Technologies[1]
+	if lasers.Acquisition != "event" || lasers.IconFile != "tech_lasers_1.png" || lasers.Cost != 12345678901 {
+		t.Errorf("Expected derived fields and exact numbers, got %+v", lasers)
+	}
+	if !reflect.DeepEqual(lasers.PrerequisiteGroups, [][]string{{"tech_a", "tech_b"}}) {
+		t.Errorf("Expected the prerequisites as the only group, got %v", lasers.PrerequisiteGroups)
+	}
+	if !repeatable.IsInfinite || len(repeatable.PrerequisiteGroups) != 0 || repeatable.Acquisition != "research" {
+		t.Errorf("Expected an infinite research technology without groups, got %+v", repeatable)
+	}
+}
+
+func TestUpgradeMetadata(t *testing.T) {
+	upgraded, result, err := Upgrade([]byte(`{"areas": ["physics"], "tiers": [0, 1], "categories": ["computing"], "maxLevel": 2}`))
+	if err != nil {
+		t.Fatalf("Failed to upgrade: %v", err)
+	}
+	if !reflect.DeepEqual(result.Missing, []string{"issues"}) {
+		t.Errorf("Expected issues to be missing, got %v", result.Missing)
+	}
+
+	var metadata MetadataJSON
+	if err := json.Unmarshal(upgraded, &metadata); err != nil {
+		t.Fatal(err)
+	}
+	if metadata.SchemaVersion != SchemaVersion || metadata.AreaDetails["physics"].Name != "Physics" || metadata.CategoryDetails["computing"].Name != "Computing" {
+		t.Errorf("Expected derived area and category details, got %+v", metadata)
+	}
+	if _, exists := metadata.Colors["game"].Tiers["1"]; !exists {
+		t.Errorf("Expected colors for the tiers, got %v", metadata.Colors)
+	}
+}
+
+func TestUpgradeVersions(t *testing.T) {
+	current := []byte(fmt.Sprintf(`{"schemaVersion": %d, "area": "physics"}`, SchemaVersion))
+	upgraded, result, err := Upgrade(current)
+	if err != nil || result.Upgraded() || string(upgraded) != string(current) {
+		t.Errorf("Expected a current file to be kept, got %s, %+v, %v", upgraded, result, err)
+	}
+
+	if _, _, err := Upgrade([]byte(fmt.Sprintf(`{"schemaVersion": %d}`, SchemaVersion+1))); err == nil {
+		t.Error("Expected an error for a newer schema version")
+	}
+	if _, _, err := Upgrade([]byte(`{"schemaVersion": "one"}`)); err == nil {
+		t.Error("Expected an error for an invalid schema version")
+	}
+	if _, _, err := Upgrade([]byte(`[1, 2]`)); err == nil {
+		t.Error("Expected an error for a file that isn't an object")
+	}
+}
+
+func TestGeneratedFilesHaveSchemaVersion(t *testing.T) {
+	generator := NewJSONGenerator(createTestTree())
+	generator.SetGraph(true)
+	generator.SetSubgraphs(true)
+	generator.SetDomain(DomainEdicts, []string{})
+
+	outputDir := t.TempDir()
+	if err := generator.GenerateJSONFiles(outputDir); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	prefix := fmt.Sprintf("{\n  \"schemaVersion\": %d", SchemaVersion)
+	for _, path := range generator.GeneratedFiles() {
+		if filepath.Ext(path) != ".json" {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(content), prefix) {
+			t.Errorf("Expected %s to start with schemaVersion, got:\n%.80s", filepath.Base(path), content)
+		}
+		if _, result, err := Upgrade(content); err != nil || result.Upgraded() {
+			t.Errorf("Expected %s to be current, got %+v, %v", filepath.Base(path), result, err)
+		}
+	}
+
+	if !strings.Contains(TypeDefinitions(), fmt.Sprintf("export type SchemaVersion = %d;", SchemaVersion)) {
+		t.Error("Expected the SchemaVersion type to match SchemaVersion")
+	}
+}
+
+func TestUpgradeKeepsMinified(t *testing.T) {
+	upgraded, _, err := Upgrade([]byte(`{"area":"physics","technologies":[]}` + "\n"))
+	if err != nil {
+		t.Fatalf("Failed to upgrade: %v", err)
+	}
+	if expected := `{"schemaVersion":1,"area":"physics","technologies":[]}` + "\n"; string(upgraded) != expected {
+		t.Errorf("Expected %q, got %q", expected, upgraded)
+	}
+}
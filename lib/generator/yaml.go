@@ -0,0 +1,160 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Output formats of the data files, see SetFormat
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// Formats are the output formats accepted by SetFormat
+var Formats = []string{FormatJSON, FormatYAML}
+
+// yamlPlain matches the strings written as plain YAML scalars. Anything else,
+// including strings that start like a number, is written double-quoted.
+var yamlPlain = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_ ./-]*$`)
+
+// yamlReserved are the plain scalars YAML 1.1 tools read as booleans or null
+var yamlReserved = map[string]bool{
+	"y": true, "yes": true, "n": true, "no": true, "on": true, "off": true,
+	"true": true, "false": true, "null": true,
+}
+
+// yamlNode is a decoded JSON value, keeping the order of object keys so the
+// YAML file lists fields in the same order as the JSON file
+type yamlNode struct {
+	kind   json.Delim  // '{' for objects, '[' for arrays, 0 for scalars
+	scalar string      // Encoded scalar
+	keys   []string    // Object keys in order
+	items  []*yamlNode // Object values or array items
+}
+
+// encodeYAML converts an encoded JSON file to YAML in block style, with
+// objects and arrays indented by two spaces
+func encodeYAML(content []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	node, err := decodeYAMLNode(decoder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to YAML: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if node.block() {
+		node.write(&buf, 0, false)
+	} else {
+		buf.WriteString(node.inline())
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeYAMLNode decodes the next JSON value
+func decodeYAMLNode(decoder *json.Decoder) (*yamlNode, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch value := token.(type) {
+	case json.Delim:
+		node := &yamlNode{kind: value}
+		for decoder.More() {
+			if value == '{' {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.keys = append(node.keys, key.(string))
+			}
+			item, err := decodeYAMLNode(decoder)
+			if err != nil {
+				return nil, err
+			}
+			node.items = append(node.items, item)
+		}
+		if _, err := decoder.Token(); err != nil { // Closing delimiter
+			return nil, err
+		}
+		return node, nil
+	case string:
+		return &yamlNode{scalar: yamlString(value)}, nil
+	case json.Number:
+		return &yamlNode{scalar: value.String()}, nil
+	case bool:
+		return &yamlNode{scalar: fmt.Sprint(value)}, nil
+	default:
+		return &yamlNode{scalar: "null"}, nil
+	}
+}
+
+// block reports whether the node is written on lines of its own: objects
+// and arrays that aren't empty
+func (n *yamlNode) block() bool {
+	return n.kind != 0 && len(n.items) > 0
+}
+
+// inline returns the node written on the line of its key or dash
+func (n *yamlNode) inline() string {
+	switch n.kind {
+	case '{':
+		return "{}"
+	case '[':
+		return "[]"
+	}
+	return n.scalar
+}
+
+// write writes a block node at indent. With first, the first line continues
+// the line of a dash and isn't indented.
+func (n *yamlNode) write(buf *bytes.Buffer, indent int, first bool) {
+	padding := strings.Repeat(" ", indent)
+	for i, item := range n.items {
+		if i > 0 || !first {
+			buf.WriteString(padding)
+		}
+
+		if n.kind == '[' {
+			buf.WriteString("- ")
+			if item.block() {
+				item.write(buf, indent+2, true)
+				continue
+			}
+			buf.WriteString(item.inline())
+			buf.WriteByte('\n')
+			continue
+		}
+
+		buf.WriteString(yamlString(n.keys[i]))
+		buf.WriteByte(':')
+		if item.block() {
+			buf.WriteByte('\n')
+			item.write(buf, indent+2, false)
+			continue
+		}
+		buf.WriteByte(' ')
+		buf.WriteString(item.inline())
+		buf.WriteByte('\n')
+	}
+}
+
+// yamlString returns s as a plain scalar when that reads back as the same
+// string, or else double-quoted. JSON string escapes are valid in YAML
+// double-quoted scalars.
+func yamlString(s string) string {
+	if yamlPlain.MatchString(s) && !strings.HasSuffix(s, " ") && !yamlReserved[strings.ToLower(s)] {
+		return s
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncodeYAML(t *testing.T) {
+	content := []byte(`{"schemaVersion":1,"key":"tech_lasers_1","name":"Red Lasers","cost":1.5,` +
+		`"isRare":false,"icon":null,"tier":"1","description":"Line one\nline \"two\"",` +
+		`"answer":"yes","empty":{},"none":[],"categories":["particles","computing"],` +
+		`"prerequisites":[{"key":"tech_a","groups":[["x","y"]]}],"nested":{"weight":{"base":10}}}`)
+
+	encoded, err := encodeYAML(content)
+	if err != nil {
+		t.Fatalf("encodeYAML failed: %v", err)
+	}
+
+	expected := `schemaVersion: 1
+key: tech_lasers_1
+name: Red Lasers
+cost: 1.5
+isRare: false
+icon: null
+tier: "1"
+description: "Line one\nline \"two\""
+answer: "yes"
+empty: {}
+none: []
+categories:
+  - particles
+  - computing
+prerequisites:
+  - key: tech_a
+    groups:
+      - - x
+        - "y"
+nested:
+  weight:
+    base: 10
+`
+	if string(encoded) != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, encoded)
+	}
+
+	if encoded, _ := encodeYAML([]byte(`[]`)); string(encoded) != "[]\n" {
+		t.Errorf("Expected an empty array on one line, got %q", encoded)
+	}
+	if _, err := encodeYAML([]byte(`{"key":`)); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
+
+func TestGenerateYAML(t *testing.T) {
+	generator := NewJSONGenerator(createTestTree())
+	generator.SetFormat(FormatYAML)
+	generator.SetGzip(true)
+
+	outputDir := t.TempDir()
+	if err := generator.GenerateJSONFiles(outputDir); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	if generator.MetadataFileName() != "metadata.yaml" || generator.ResearchFileName("physics") != "research-physics.yaml" {
+		t.Errorf("Expected .yaml file names, got %s and %s", generator.MetadataFileName(), generator.ResearchFileName("physics"))
+	}
+	content, err := os.ReadFile(filepath.Join(outputDir, "research-physics.yaml"))
+	if err != nil {
+		t.Fatalf("Expected research-physics.yaml to be written: %v", err)
+	}
+	if !strings.HasPrefix(string(content), "schemaVersion: 1\narea: physics\ntechnologies:\n  - key: ") {
+		t.Errorf("Expected the structure of the JSON file, got\n%s", content)
+	}
+	for _, name := range []string{"metadata.yaml", "metadata.yaml.gz", generator.TypesFileName()} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); err != nil {
+			t.Errorf("Expected %s to be written: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "metadata.json")); err == nil {
+		t.Error("Expected no JSON data files")
+	}
+}
@@ -0,0 +1,98 @@
+// Package install finds Stellaris installations in the standard Steam and
+// GOG locations and reads the version of an installed game.
+package install
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ErrNotFound is returned by Detect when no installation was found
+var ErrNotFound = errors.New("no Stellaris installation found in the standard Steam and GOG locations")
+
+// steamAppDir is the directory of the game inside a Steam library
+var steamAppDir = filepath.Join("steamapps", "common", "Stellaris")
+
+// Detect returns the game directory of a Stellaris installation in a
+// standard Steam or GOG location
+func Detect() (string, error) {
+	for _, dir := range Candidates() {
+		if IsGameDir(dir) {
+			return dir, nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+// Candidates returns the directories checked by Detect, in order. Steam
+// libraries listed in libraryfolders.vdf are included.
+func Candidates() []string {
+	return candidates(steamRoots(), standaloneDirs())
+}
+
+// candidates expands Steam roots to the game directory in each of their
+// libraries, followed by the standalone (GOG, Paradox launcher) directories.
+// Duplicates are removed.
+func candidates(roots, otherDirs []string) []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	add := func(dir string) {
+		key := filepath.Clean(dir)
+		if !seen[key] {
+			seen[key] = true
+			dirs = append(dirs, dir)
+		}
+	}
+
+	for _, root := range roots {
+		add(filepath.Join(root, steamAppDir))
+		for _, library := range steamLibraries(root) {
+			add(filepath.Join(library, steamAppDir))
+		}
+	}
+	for _, dir := range otherDirs {
+		add(dir)
+	}
+	return dirs
+}
+
+// IsGameDir reports whether dir looks like a Stellaris game directory
+func IsGameDir(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, "common", "technology"))
+	return err == nil && info.IsDir()
+}
+
+// steamLibraries returns the additional library folders configured in a
+// Steam installation
+func steamLibraries(steamRoot string) []string {
+	content, err := os.ReadFile(filepath.Join(steamRoot, "steamapps", "libraryfolders.vdf"))
+	if err != nil {
+		return nil
+	}
+	return parseLibraryFolders(string(content))
+}
+
+// libraryPathPattern matches library paths in both the current format
+// ("path" "D:\\SteamLibrary") and the legacy format ("1" "D:\\SteamLibrary")
+var libraryPathPattern = regexp.MustCompile(`(?m)^\s*"(?:path|\d+)"[ \t]+"([^"]+)"`)
+
+// parseLibraryFolders extracts the library paths from libraryfolders.vdf
+func parseLibraryFolders(content string) []string {
+	var libraries []string
+	for _, match := range libraryPathPattern.FindAllStringSubmatch(content, -1) {
+		libraries = append(libraries, strings.ReplaceAll(match[1], `\\`, `\`))
+	}
+	return libraries
+}
+
+// homeDir returns the user's home directory, or an empty string
+func homeDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return home
+}
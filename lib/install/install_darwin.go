@@ -0,0 +1,20 @@
+package install
+
+import "path/filepath"
+
+// steamRoots returns the default Steam installation directory
+func steamRoots() []string {
+	home := homeDir()
+	if home == "" {
+		return nil
+	}
+	return []string{filepath.Join(home, "Library", "Application Support", "Steam")}
+}
+
+// standaloneDirs returns the default GOG install locations outside of Steam
+func standaloneDirs() []string {
+	return []string{
+		"/Applications/Stellaris.app/Contents/Resources/game",
+		"/Applications/Stellaris",
+	}
+}
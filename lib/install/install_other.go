@@ -0,0 +1,29 @@
+//go:build !windows && !darwin
+
+package install
+
+import "path/filepath"
+
+// steamRoots returns the default Steam installation directories, including
+// the Flatpak and Snap packages
+func steamRoots() []string {
+	home := homeDir()
+	if home == "" {
+		return nil
+	}
+	return []string{
+		filepath.Join(home, ".steam", "steam"),
+		filepath.Join(home, ".local", "share", "Steam"),
+		filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam"),
+		filepath.Join(home, "snap", "steam", "common", ".local", "share", "Steam"),
+	}
+}
+
+// standaloneDirs returns the default GOG install locations outside of Steam
+func standaloneDirs() []string {
+	home := homeDir()
+	if home == "" {
+		return nil
+	}
+	return []string{filepath.Join(home, "GOG Games", "Stellaris", "game")}
+}
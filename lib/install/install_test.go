@@ -0,0 +1,75 @@
+package install
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseLibraryFolders(t *testing.T) {
+	content := `"libraryfolders"
+{
+	"0"
+	{
+		"path"		"C:\\Program Files (x86)\\Steam"
+		"label"		""
+	}
+	"1"
+	{
+		"path"		"D:\\SteamLibrary"
+	}
+}`
+
+	expected := []string{`C:\Program Files (x86)\Steam`, `D:\SteamLibrary`}
+	if got := parseLibraryFolders(content); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestParseLibraryFoldersLegacy(t *testing.T) {
+	content := `"LibraryFolders"
+{
+	"TimeNextStatsReport"		"1600000000"
+	"1"		"/mnt/games/SteamLibrary"
+}`
+
+	expected := []string{"/mnt/games/SteamLibrary"}
+	if got := parseLibraryFolders(content); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestCandidatesWithLibraries(t *testing.T) {
+	root := t.TempDir()
+	steamRoot := filepath.Join(root, "Steam")
+	library := filepath.Join(root, "Library")
+
+	if err := os.MkdirAll(filepath.Join(steamRoot, "steamapps"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	vdf := "\"libraryfolders\"\n{\n\t\"path\"\t\t\"" + steamRoot + "\"\n\t\"path\"\t\t\"" + library + "\"\n}\n"
+	if err := os.WriteFile(filepath.Join(steamRoot, "steamapps", "libraryfolders.vdf"), []byte(vdf), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	gog := filepath.Join(root, "GOG")
+	got := candidates([]string{steamRoot}, []string{gog})
+	expected := []string{
+		filepath.Join(steamRoot, steamAppDir),
+		filepath.Join(library, steamAppDir),
+		gog,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestIsGameDir(t *testing.T) {
+	if !IsGameDir("../../testdata") {
+		t.Error("Expected testdata to look like a game directory")
+	}
+	if IsGameDir(t.TempDir()) {
+		t.Error("Expected empty directory not to look like a game directory")
+	}
+}
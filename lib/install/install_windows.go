@@ -0,0 +1,64 @@
+package install
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// steamRoots returns Steam installation directories from the registry,
+// followed by the default install location
+func steamRoots() []string {
+	var roots []string
+	for _, query := range [][]string{
+		{`HKLM\SOFTWARE\WOW6432Node\Valve\Steam`, "InstallPath"},
+		{`HKLM\SOFTWARE\Valve\Steam`, "InstallPath"},
+		{`HKCU\Software\Valve\Steam`, "SteamPath"},
+	} {
+		if value := registryValue(query[0], query[1]); value != "" {
+			roots = append(roots, filepath.FromSlash(value))
+		}
+	}
+
+	for _, env := range []string{"ProgramFiles(x86)", "ProgramFiles"} {
+		if dir := os.Getenv(env); dir != "" {
+			roots = append(roots, filepath.Join(dir, "Steam"))
+		}
+	}
+	return roots
+}
+
+// standaloneDirs returns the default GOG and Paradox launcher install locations
+func standaloneDirs() []string {
+	dirs := []string{`C:\GOG Games\Stellaris`}
+	for _, env := range []string{"ProgramFiles(x86)", "ProgramFiles"} {
+		if dir := os.Getenv(env); dir != "" {
+			dirs = append(dirs,
+				filepath.Join(dir, "GOG Galaxy", "Games", "Stellaris"),
+				filepath.Join(dir, "GOG Games", "Stellaris"),
+				filepath.Join(dir, "Paradox Interactive", "Stellaris"),
+			)
+		}
+	}
+	return dirs
+}
+
+// registryValue reads a string value from the registry using reg.exe, so no
+// extra dependencies are needed. Returns an empty string if it is not set.
+func registryValue(key, name string) string {
+	out, err := exec.Command("reg", "query", key, "/v", name).Output()
+	if err != nil {
+		return ""
+	}
+
+	// Output line: "    InstallPath    REG_SZ    C:\Program Files (x86)\Steam"
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && strings.EqualFold(fields[0], name) && strings.HasPrefix(fields[1], "REG_") {
+			_, value, _ := strings.Cut(line, fields[1])
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
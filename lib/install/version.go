@@ -0,0 +1,77 @@
+package install
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// ErrNoVersion is returned by ReadVersion when the game directory has no
+// launcher settings
+var ErrNoVersion = errors.New("no launcher-settings.json found in the game directory")
+
+// launcherSettingsFiles are the locations of the launcher settings relative
+// to the game directory, in the order they are tried
+var launcherSettingsFiles = []string{
+	"launcher-settings.json",
+	filepath.Join("launcher", "launcher-settings.json"),
+}
+
+// launcherSettings holds the fields of launcher-settings.json that identify
+// the game version
+type launcherSettings struct {
+	Version    string `json:"version"`    // e.g. "Andromeda v3.12.4"
+	RawVersion string `json:"rawVersion"` // e.g. "3.12.4" or "v3.12.4"
+}
+
+// ReadVersion returns the version of the game in gameDir from the
+// launcher-settings.json the launcher reads it from
+func ReadVersion(gameDir string) (models.GameVersion, error) {
+	for _, name := range launcherSettingsFiles {
+		path := filepath.Join(gameDir, name)
+		content, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return models.GameVersion{}, err
+		}
+		version, err := parseLauncherSettings(content)
+		if err != nil {
+			return models.GameVersion{}, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		return version, nil
+	}
+	return models.GameVersion{}, ErrNoVersion
+}
+
+// parseLauncherSettings returns the game version of launcher-settings.json.
+// The version number is taken from rawVersion, or else from the end of
+// version, which starts with the release name.
+func parseLauncherSettings(content []byte) (models.GameVersion, error) {
+	var settings launcherSettings
+	if err := json.Unmarshal(content, &settings); err != nil {
+		return models.GameVersion{}, err
+	}
+
+	var version models.GameVersion
+	name, number, found := strings.Cut(settings.Version, " v")
+	if found {
+		version.Name = strings.TrimSpace(name)
+	} else {
+		number = settings.Version
+	}
+	if settings.RawVersion != "" {
+		number = settings.RawVersion
+	}
+	version.Version = strings.TrimPrefix(strings.TrimSpace(number), "v")
+	if version.Version == "" {
+		return models.GameVersion{}, errors.New("no version in launcher settings")
+	}
+	return version, nil
+}
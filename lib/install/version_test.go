@@ -0,0 +1,60 @@
+package install
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+func TestParseLauncherSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected models.GameVersion
+	}{
+		{"raw version", `{"gameId": "stellaris", "version": "Andromeda v3.12.4", "rawVersion": "3.12.4"}`, models.GameVersion{Version: "3.12.4", Name: "Andromeda"}},
+		{"raw version with v", `{"version": "Phoenix v4.0.22", "rawVersion": "v4.0.22"}`, models.GameVersion{Version: "4.0.22", Name: "Phoenix"}},
+		{"name only in version", `{"version": "Canis Minor v3.8.4.1"}`, models.GameVersion{Version: "3.8.4.1", Name: "Canis Minor"}},
+		{"number only", `{"version": "3.4.5"}`, models.GameVersion{Version: "3.4.5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := parseLauncherSettings([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("parseLauncherSettings failed: %v", err)
+			}
+			if version != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, version)
+			}
+		})
+	}
+
+	if _, err := parseLauncherSettings([]byte(`{"gameId": "stellaris"}`)); err == nil {
+		t.Error("Expected an error for settings without a version")
+	}
+}
+
+func TestReadVersion(t *testing.T) {
+	gameDir := t.TempDir()
+	if _, err := ReadVersion(gameDir); !errors.Is(err, ErrNoVersion) {
+		t.Errorf("Expected ErrNoVersion without launcher settings, got %v", err)
+	}
+
+	launcherDir := filepath.Join(gameDir, "launcher")
+	if err := os.MkdirAll(launcherDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := []byte(`{"version": "Andromeda v3.12.4", "rawVersion": "3.12.4"}`)
+	if err := os.WriteFile(filepath.Join(launcherDir, "launcher-settings.json"), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	version, err := ReadVersion(gameDir)
+	if err != nil || version.Version != "3.12.4" || version.Name != "Andromeda" {
+		t.Errorf("Expected 3.12.4 (Andromeda), got %+v (%v)", version, err)
+	}
+}
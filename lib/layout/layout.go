@@ -0,0 +1,181 @@
+// Package layout places the technologies of each research area on a grid,
+// as a layered graph with few edge crossings, so front-ends can draw the
+// tree without a layout library.
+package layout
+
+import (
+	"sort"
+
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+// sweeps is the number of down and up passes of crossing reduction
+const sweeps = 8
+
+// Position is the place of a technology in the layout of its area. Both
+// coordinates are grid units; front-ends scale them to their node size.
+type Position struct {
+	X int `json:"x"` // Column within the layer, from 0
+	Y int `json:"y"` // Layer, equal to the technology's level
+}
+
+// vertex is a technology or a dummy on a prerequisite edge that spans
+// several layers
+type vertex struct {
+	key   string // Technology key, empty for dummies
+	layer int
+	pos   int
+	up    []*vertex // Neighbours in the layer above
+	down  []*vertex // Neighbours in the layer below
+}
+
+// Compute lays out each research area as a layered graph. Technologies are
+// placed in the layer of their level, and prerequisite edges within the area
+// that span several layers are routed through dummy positions that stay free.
+// The order within each layer is chosen by barycenter sweeps to reduce edge
+// crossings. Edges to other areas and edges of prerequisite cycles are
+// ignored. The result is the same on every run.
+func Compute(t *tree.TechTree) map[string]Position {
+	positions := make(map[string]Position)
+	for _, area := range t.GetAreas() {
+		layers := buildLayers(t.TopologicalOrderByArea(area))
+		reduceCrossings(layers)
+		for _, layer := range layers {
+			for _, v := range layer {
+				if v.key != "" {
+					positions[v.key] = Position{X: v.pos, Y: v.layer}
+				}
+			}
+		}
+	}
+	return positions
+}
+
+// buildLayers creates the vertices of an area's technologies, given in
+// topological order, grouped by layer in their initial order
+func buildLayers(nodes []*tree.TechNode) [][]*vertex {
+	maxLevel := 0
+	for _, node := range nodes {
+		if node.Level > maxLevel {
+			maxLevel = node.Level
+		}
+	}
+	layers := make([][]*vertex, maxLevel+1)
+	add := func(v *vertex) {
+		v.pos = len(layers[v.layer])
+		layers[v.layer] = append(layers[v.layer], v)
+	}
+
+	vertices := make(map[*tree.TechNode]*vertex, len(nodes))
+	for _, node := range nodes {
+		v := &vertex{key: node.Tech.Key, layer: node.Level}
+		vertices[node] = v
+		add(v)
+	}
+
+	for _, node := range nodes {
+		for _, dependency := range node.Dependencies {
+			from, sameArea := vertices[dependency]
+			if !sameArea || dependency.Level >= node.Level {
+				continue
+			}
+			for layer := dependency.Level + 1; layer < node.Level; layer++ {
+				dummy := &vertex{layer: layer}
+				add(dummy)
+				connect(from, dummy)
+				from = dummy
+			}
+			connect(from, vertices[node])
+		}
+	}
+	return layers
+}
+
+func connect(upper, lower *vertex) {
+	upper.down = append(upper.down, lower)
+	lower.up = append(lower.up, upper)
+}
+
+// reduceCrossings reorders the layers with alternating downward and upward
+// barycenter sweeps and keeps the order with the fewest crossings
+func reduceCrossings(layers [][]*vertex) {
+	best := snapshot(layers)
+	bestCrossings := crossings(layers)
+
+	for i := 0; i < sweeps && bestCrossings > 0; i++ {
+		if i%2 == 0 {
+			for l := 1; l < len(layers); l++ {
+				orderByBarycenter(layers[l], func(v *vertex) []*vertex { return v.up })
+			}
+		} else {
+			for l := len(layers) - 2; l >= 0; l-- {
+				orderByBarycenter(layers[l], func(v *vertex) []*vertex { return v.down })
+			}
+		}
+		if count := crossings(layers); count < bestCrossings {
+			best, bestCrossings = snapshot(layers), count
+		}
+	}
+
+	for l, layer := range best {
+		copy(layers[l], layer)
+		for pos, v := range layers[l] {
+			v.pos = pos
+		}
+	}
+}
+
+// orderByBarycenter sorts a layer by the average position of each vertex's
+// neighbours. Vertices without neighbours keep their position.
+func orderByBarycenter(layer []*vertex, neighbours func(*vertex) []*vertex) {
+	barycenters := make(map[*vertex]float64, len(layer))
+	for _, v := range layer {
+		adjacent := neighbours(v)
+		if len(adjacent) == 0 {
+			barycenters[v] = float64(v.pos)
+			continue
+		}
+		sum := 0
+		for _, n := range adjacent {
+			sum += n.pos
+		}
+		barycenters[v] = float64(sum) / float64(len(adjacent))
+	}
+
+	sort.SliceStable(layer, func(i, j int) bool { return barycenters[layer[i]] < barycenters[layer[j]] })
+	for pos, v := range layer {
+		v.pos = pos
+	}
+}
+
+// crossings counts the pairs of edges that cross between adjacent layers
+func crossings(layers [][]*vertex) int {
+	count := 0
+	for _, layer := range layers {
+		type edge struct{ from, to int }
+		var edges []edge
+		for _, v := range layer {
+			for _, n := range v.down {
+				edges = append(edges, edge{v.pos, n.pos})
+			}
+		}
+		for i := range edges {
+			for j := i + 1; j < len(edges); j++ {
+				a, b := edges[i], edges[j]
+				if (a.from < b.from && a.to > b.to) || (a.from > b.from && a.to < b.to) {
+					count++
+				}
+			}
+		}
+	}
+	return count
+}
+
+// snapshot copies the order of every layer
+func snapshot(layers [][]*vertex) [][]*vertex {
+	copied := make([][]*vertex, len(layers))
+	for l, layer := range layers {
+		copied[l] = append([]*vertex(nil), layer...)
+	}
+	return copied
+}
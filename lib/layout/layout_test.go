@@ -0,0 +1,62 @@
+package layout
+
+import (
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+func TestCompute(t *testing.T) {
+	techs := map[string]*models.Technology{
+		"tech_a":       {Key: "tech_a", Area: "physics"},
+		"tech_b":       {Key: "tech_b", Area: "physics"},
+		"tech_c":       {Key: "tech_c", Area: "physics", Prerequisites: []string{"tech_b"}},
+		"tech_d":       {Key: "tech_d", Area: "physics", Prerequisites: []string{"tech_a"}},
+		"tech_e":       {Key: "tech_e", Area: "physics", Prerequisites: []string{"tech_c"}},
+		"tech_long":    {Key: "tech_long", Area: "physics", Prerequisites: []string{"tech_e", "tech_a"}},
+		"tech_society": {Key: "tech_society", Area: "society", Prerequisites: []string{"tech_d"}},
+	}
+	positions := Compute(tree.NewTechTree(techs))
+
+	if len(positions) != len(techs) {
+		t.Fatalf("Expected a position for each of %d technologies, got %v", len(techs), positions)
+	}
+
+	// Sorting by key would cross tech_c -> tech_b and tech_d -> tech_a
+	before := func(a, b string) bool { return positions[a].X < positions[b].X }
+	if before("tech_a", "tech_b") != before("tech_d", "tech_c") {
+		t.Errorf("Expected no crossing between levels 0 and 1, got %v", positions)
+	}
+
+	if positions["tech_long"].Y != 3 {
+		t.Errorf("Expected tech_long in layer 3, got %d", positions["tech_long"].Y)
+	}
+	// The edge from tech_a to tech_long passes layers 1 and 2 as dummies
+	techTree := tree.NewTechTree(techs)
+	layers := buildLayers(techTree.TopologicalOrderByArea("physics"))
+	if len(layers[1]) != 3 || len(layers[2]) != 2 {
+		t.Errorf("Expected a dummy in layers 1 and 2, got %d and %d vertices", len(layers[1]), len(layers[2]))
+	}
+
+	// Areas are laid out separately
+	if positions["tech_society"] != (Position{X: 0, Y: 2}) {
+		t.Errorf("Expected tech_society at 0,2, got %v", positions["tech_society"])
+	}
+}
+
+func TestCrossings(t *testing.T) {
+	a, b := &vertex{pos: 0}, &vertex{pos: 1}
+	c, d := &vertex{pos: 0, layer: 1}, &vertex{pos: 1, layer: 1}
+	connect(a, d)
+	connect(b, c)
+	layers := [][]*vertex{{a, b}, {c, d}}
+
+	if count := crossings(layers); count != 1 {
+		t.Fatalf("Expected 1 crossing, got %d", count)
+	}
+	reduceCrossings(layers)
+	if count := crossings(layers); count != 0 {
+		t.Errorf("Expected no crossings after reduction, got %d", count)
+	}
+}
@@ -0,0 +1,75 @@
+package localization
+
+import (
+	"html"
+	"strings"
+)
+
+// Color modes select how §X...§! color markup in localized strings is written
+const (
+	ColorStrip = "strip" // Remove the markup and keep the text
+	ColorHTML  = "html"  // Convert to <span class="stellaris-color-X"> elements
+	ColorRaw   = "raw"   // Keep the markup as it is in the game files
+)
+
+// ColorModes lists the supported color modes
+var ColorModes = []string{ColorStrip, ColorHTML, ColorRaw}
+
+// ColorClassPrefix is prepended to the color code to form the CSS class of
+// the spans written in ColorHTML mode
+const ColorClassPrefix = "stellaris-color-"
+
+// FormatColors applies a color mode to a localized string. §X starts a color
+// and §! ends the most recently started one; colors still open at the end of
+// the string are closed. In ColorHTML mode the text is HTML-escaped as well.
+// Unknown modes behave like ColorRaw.
+func FormatColors(text string, mode string) string {
+	if mode != ColorStrip && mode != ColorHTML {
+		return text
+	}
+
+	var result strings.Builder
+	var segment strings.Builder
+	open := 0
+
+	flush := func() {
+		if mode == ColorHTML {
+			result.WriteString(html.EscapeString(segment.String()))
+		} else {
+			result.WriteString(segment.String())
+		}
+		segment.Reset()
+	}
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '§' || i+1 >= len(runes) {
+			segment.WriteRune(runes[i])
+			continue
+		}
+
+		flush()
+		code := runes[i+1]
+		i++
+		if code == '!' {
+			if open > 0 {
+				open--
+				if mode == ColorHTML {
+					result.WriteString("</span>")
+				}
+			}
+			continue
+		}
+
+		open++
+		if mode == ColorHTML {
+			result.WriteString(`<span class="` + ColorClassPrefix + html.EscapeString(string(code)) + `">`)
+		}
+	}
+
+	flush()
+	if mode == ColorHTML {
+		result.WriteString(strings.Repeat("</span>", open))
+	}
+	return result.String()
+}
@@ -0,0 +1,32 @@
+package localization
+
+import "testing"
+
+func TestFormatColors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		mode     string
+		expected string
+	}{
+		{"strip", "Grants §Y+10%§! research speed", ColorStrip, "Grants +10% research speed"},
+		{"strip nested", "§GGreen §Yyellow§! green§!", ColorStrip, "Green yellow green"},
+		{"strip unclosed", "§RDanger", ColorStrip, "Danger"},
+		{"html", "Grants §Y+10%§! speed", ColorHTML, `Grants <span class="stellaris-color-Y">+10%</span> speed`},
+		{"html nested", "§Ga §Yb§!§!", ColorHTML, `<span class="stellaris-color-G">a <span class="stellaris-color-Y">b</span></span>`},
+		{"html closes open colors", "§Ra", ColorHTML, `<span class="stellaris-color-R">a</span>`},
+		{"html ignores stray end", "a§!b", ColorHTML, "ab"},
+		{"html escapes text", "§Y<b> & c§!", ColorHTML, `<span class="stellaris-color-Y">&lt;b&gt; &amp; c</span>`},
+		{"raw", "Grants §Y+10%§!", ColorRaw, "Grants §Y+10%§!"},
+		{"trailing marker", "Text§", ColorStrip, "Text§"},
+		{"no markup", "Plain text", ColorStrip, "Plain text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatColors(tt.input, tt.mode); got != tt.expected {
+				t.Errorf("FormatColors(%q, %q) = %q, want %q", tt.input, tt.mode, got, tt.expected)
+			}
+		})
+	}
+}
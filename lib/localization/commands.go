@@ -0,0 +1,64 @@
+package localization
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Command modes select how bracketed scripting commands such as
+// [Root.GetName] in localized strings are written
+const (
+	CommandStrip       = "strip"       // Remove the command
+	CommandPlaceholder = "placeholder" // Replace the command with its configured placeholder
+	CommandRaw         = "raw"         // Keep the command as it is in the game files
+)
+
+// CommandModes lists the supported command modes
+var CommandModes = []string{CommandStrip, CommandPlaceholder, CommandRaw}
+
+// DefaultCommandPlaceholders are the placeholders of common vanilla commands
+func DefaultCommandPlaceholders() map[string]string {
+	return map[string]string{
+		"Root.GetName":              "your empire",
+		"Root.GetAdj":               "your",
+		"Root.GetSpeciesName":       "your species",
+		"Root.GetSpeciesNamePlural": "your species",
+		"Root.GetRulerName":         "your ruler",
+		"This.GetName":              "this empire",
+		"This.GetSpeciesName":       "this species",
+		"This.GetSpeciesNamePlural": "this species",
+		"Owner.GetName":             "the owner",
+	}
+}
+
+// commandPattern matches [Scope.Command] with an optional |format suffix
+var commandPattern = regexp.MustCompile(`\[([A-Za-z_][\w.]*)(?:\|[^\]]*)?\]`)
+
+// FormatCommands applies a command mode to a localized string. In
+// CommandPlaceholder mode commands without a placeholder are removed. Removing
+// a command collapses the double space it leaves behind. Unknown modes behave
+// like CommandRaw.
+func FormatCommands(text, mode string, placeholders map[string]string) string {
+	if mode != CommandStrip && mode != CommandPlaceholder {
+		return text
+	}
+
+	removed := false
+	result := commandPattern.ReplaceAllStringFunc(text, func(command string) string {
+		if mode == CommandPlaceholder {
+			if placeholder, ok := placeholders[commandPattern.FindStringSubmatch(command)[1]]; ok {
+				return placeholder
+			}
+		}
+		removed = true
+		return ""
+	})
+
+	if removed {
+		for strings.Contains(result, "  ") {
+			result = strings.ReplaceAll(result, "  ", " ")
+		}
+		result = strings.TrimSpace(result)
+	}
+	return result
+}
@@ -0,0 +1,77 @@
+package localization
+
+import "testing"
+
+func TestFormatCommands(t *testing.T) {
+	placeholders := DefaultCommandPlaceholders()
+
+	tests := []struct {
+		name     string
+		input    string
+		mode     string
+		expected string
+	}{
+		{
+			"strip",
+			"The [Root.GetName] has mastered the secrets of the atom.",
+			CommandStrip,
+			"The has mastered the secrets of the atom.",
+		},
+		{
+			"strip at start",
+			"[This.GetSpeciesNamePlural] scientists study the stars.",
+			CommandStrip,
+			"scientists study the stars.",
+		},
+		{
+			"placeholder",
+			"The [Root.GetName] has mastered the secrets of the atom.",
+			CommandPlaceholder,
+			"The your empire has mastered the secrets of the atom.",
+		},
+		{
+			"placeholder with format suffix",
+			"Grants [Root.GetSpeciesName|Y] new insights.",
+			CommandPlaceholder,
+			"Grants your species new insights.",
+		},
+		{
+			"placeholder missing",
+			"Reported by [Root.Capital.GetName] observers.",
+			CommandPlaceholder,
+			"Reported by observers.",
+		},
+		{
+			"nested scope",
+			"[This.Owner.GetName] researchers",
+			CommandStrip,
+			"researchers",
+		},
+		{
+			"raw",
+			"The [Root.GetName] rises.",
+			CommandRaw,
+			"The [Root.GetName] rises.",
+		},
+		{
+			"no commands",
+			"Basic  laser technology.",
+			CommandStrip,
+			"Basic  laser technology.",
+		},
+		{
+			"not a command",
+			"Damage [+10%]",
+			CommandStrip,
+			"Damage [+10%]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatCommands(tt.input, tt.mode, placeholders); got != tt.expected {
+				t.Errorf("FormatCommands(%q, %q) = %q, want %q", tt.input, tt.mode, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package localization
+
+import (
+	"math"
+	"sort"
+)
+
+// DescriptionSuffix is appended to a technology key to form the key of its
+// description
+const DescriptionSuffix = "_desc"
+
+// LanguageCoverage lists the technology name and description keys missing
+// from one language
+type LanguageCoverage struct {
+	Language            string   `json:"language"`
+	Expected            int      `json:"expected"` // Name and description keys of all technologies
+	Missing             int      `json:"missing"`
+	Coverage            float64  `json:"coverage"` // Percentage of expected keys present, rounded to one decimal
+	MissingNames        []string `json:"missingNames"`
+	MissingDescriptions []string `json:"missingDescriptions"` // Description keys, ending in _desc
+}
+
+// Coverage reports which name and description keys of the given technology
+// keys are missing from each language. Fallback languages are not consulted.
+func (p *LocalizationParser) Coverage(techKeys []string, languages []string) []LanguageCoverage {
+	keys := append([]string(nil), techKeys...)
+	sort.Strings(keys)
+
+	report := make([]LanguageCoverage, 0, len(languages))
+	for _, language := range languages {
+		var translations map[string]string
+		if langData, ok := p.data.Languages[language]; ok {
+			translations = langData.Translations
+		}
+
+		coverage := LanguageCoverage{
+			Language:            language,
+			Expected:            2 * len(keys),
+			MissingNames:        []string{},
+			MissingDescriptions: []string{},
+		}
+		for _, key := range keys {
+			if _, ok := translations[key]; !ok {
+				coverage.MissingNames = append(coverage.MissingNames, key)
+			}
+			if _, ok := translations[key+DescriptionSuffix]; !ok {
+				coverage.MissingDescriptions = append(coverage.MissingDescriptions, key+DescriptionSuffix)
+			}
+		}
+		coverage.Missing = len(coverage.MissingNames) + len(coverage.MissingDescriptions)
+
+		coverage.Coverage = 100
+		if coverage.Expected > 0 {
+			present := float64(coverage.Expected - coverage.Missing)
+			coverage.Coverage = math.Round(present/float64(coverage.Expected)*1000) / 10
+		}
+		report = append(report, coverage)
+	}
+	return report
+}
@@ -0,0 +1,38 @@
+package localization
+
+import "testing"
+
+func TestCoverage(t *testing.T) {
+	dir := t.TempDir()
+	writeLocalizationFile(t, dir, "english/tech_l_english.yml", "l_english:\n tech_a:0 \"Alpha\"\n tech_a_desc:0 \"Alpha tech\"\n tech_b:0 \"Beta\"\n tech_b_desc:0 \"Beta tech\"\n")
+	writeLocalizationFile(t, dir, "german/tech_l_german.yml", "l_german:\n tech_a:0 \"Alpha\"\n tech_b_desc:0 \"Beta Technik\"\n")
+
+	parser := NewLocalizationParser()
+	if err := parser.ParseDirectory(dir); err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	report := parser.Coverage([]string{"tech_b", "tech_a"}, []string{"english", "german", "french"})
+	if len(report) != 3 {
+		t.Fatalf("Expected a report per language, got %+v", report)
+	}
+
+	if english := report[0]; english.Missing != 0 || english.Coverage != 100 {
+		t.Errorf("Expected full english coverage, got %+v", english)
+	}
+
+	german := report[1]
+	if german.Expected != 4 || german.Missing != 2 || german.Coverage != 50 {
+		t.Errorf("Expected 2 of 4 german keys missing, got %+v", german)
+	}
+	if len(german.MissingNames) != 1 || german.MissingNames[0] != "tech_b" {
+		t.Errorf("Expected tech_b to be missing, got %v", german.MissingNames)
+	}
+	if len(german.MissingDescriptions) != 1 || german.MissingDescriptions[0] != "tech_a_desc" {
+		t.Errorf("Expected tech_a_desc to be missing, got %v", german.MissingDescriptions)
+	}
+
+	if french := report[2]; french.Coverage != 0 || french.MissingNames[0] != "tech_a" {
+		t.Errorf("Expected no french coverage with sorted keys, got %+v", french)
+	}
+}
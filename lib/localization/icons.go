@@ -0,0 +1,59 @@
+package localization
+
+import (
+	"html"
+	"path"
+	"regexp"
+	"sort"
+)
+
+// Icon token modes select how £name£ icon references in localized strings
+// are written
+const (
+	IconTokenRaw   = "raw"   // Keep the reference as it is in the game files
+	IconTokenStrip = "strip" // Remove the reference
+	IconTokenToken = "token" // Replace with a stable {icon:name} token
+	IconTokenHTML  = "html"  // Replace with an <img> element pointing at the extracted icon
+)
+
+// IconTokenModes lists the supported icon token modes
+var IconTokenModes = []string{IconTokenRaw, IconTokenStrip, IconTokenToken, IconTokenHTML}
+
+// iconTokenPattern matches £name£ and framed references such as £energy|1£
+var iconTokenPattern = regexp.MustCompile(`£(\w+)(?:\|\d+)?£`)
+
+// FormatIconTokens applies an icon token mode to a localized string. In
+// IconTokenHTML mode each reference becomes an <img> whose source is
+// iconDir/<name>.png. Unknown modes behave like IconTokenRaw.
+func FormatIconTokens(text, mode, iconDir string) string {
+	switch mode {
+	case IconTokenStrip:
+		return iconTokenPattern.ReplaceAllString(text, "")
+	case IconTokenToken:
+		return iconTokenPattern.ReplaceAllString(text, "{icon:$1}")
+	case IconTokenHTML:
+		return iconTokenPattern.ReplaceAllStringFunc(text, func(reference string) string {
+			name := html.EscapeString(iconTokenPattern.FindStringSubmatch(reference)[1])
+			src := html.EscapeString(path.Join(iconDir, name+".png"))
+			return `<img class="stellaris-icon" src="` + src + `" alt="` + name + `">`
+		})
+	default:
+		return text
+	}
+}
+
+// IconTokenNames returns the sorted, unique icon names referenced in texts
+func IconTokenNames(texts ...string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, text := range texts {
+		for _, match := range iconTokenPattern.FindAllStringSubmatch(text, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				names = append(names, match[1])
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
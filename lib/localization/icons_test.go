@@ -0,0 +1,38 @@
+package localization
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormatIconTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		mode     string
+		expected string
+	}{
+		{"raw", "Costs £energy£ 10", IconTokenRaw, "Costs £energy£ 10"},
+		{"strip", "Costs £energy£10", IconTokenStrip, "Costs 10"},
+		{"token", "£minerals£ and £energy£", IconTokenToken, "{icon:minerals} and {icon:energy}"},
+		{"token with frame", "£trigger_yes|1£ done", IconTokenToken, "{icon:trigger_yes} done"},
+		{"html", "Costs £energy£", IconTokenHTML, `Costs <img class="stellaris-icon" src="icons/resources/energy.png" alt="energy">`},
+		{"unknown mode", "£energy£", "other", "£energy£"},
+		{"unclosed", "£energy 10", IconTokenToken, "£energy 10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatIconTokens(tt.input, tt.mode, "icons/resources"); got != tt.expected {
+				t.Errorf("FormatIconTokens(%q, %q) = %q, want %q", tt.input, tt.mode, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIconTokenNames(t *testing.T) {
+	names := IconTokenNames("£minerals£ and £energy£", "£energy|2£ again", "none")
+	if !reflect.DeepEqual(names, []string{"energy", "minerals"}) {
+		t.Errorf("Expected [energy minerals], got %v", names)
+	}
+}
@@ -1,14 +1,38 @@
+// Package localization reads the localization .yml files of the game and of
+// mods and looks up localized text by key, with fallback languages and
+// resolution of $variable$ references.
 package localization
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+
+	"github.com/danaketh/StellarisDataParser/lib/charset"
 )
 
+// Languages lists the localization languages shipped with Stellaris
+var Languages = []string{
+	"braz_por",
+	"english",
+	"french",
+	"german",
+	"japanese",
+	"korean",
+	"polish",
+	"russian",
+	"simp_chinese",
+	"spanish",
+}
+
 // LocalizationData stores translations for all languages
 type LocalizationData struct {
 	Languages map[string]*LanguageData // key: language code (e.g., "english", "german")
@@ -17,11 +41,13 @@ type LocalizationData struct {
 // LanguageData stores translations for a specific language
 type LanguageData struct {
 	Translations map[string]string // key: translation key, value: localized text
+	replaced     map[string]bool   // Keys set by replace files
 }
 
 // LocalizationParser parses Stellaris localization files
 type LocalizationParser struct {
-	data *LocalizationData
+	data   *LocalizationData
+	logger *slog.Logger
 }
 
 // NewLocalizationParser creates a new localization parser
@@ -30,46 +56,74 @@ func NewLocalizationParser() *LocalizationParser {
 		data: &LocalizationData{
 			Languages: make(map[string]*LanguageData),
 		},
+		logger: slog.New(slog.DiscardHandler),
+	}
+}
+
+// SetLogger sets the logger that receives a warning for every localization
+// file that can't be read. Nothing is logged by default; nil restores the
+// default.
+func (p *LocalizationParser) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
 	}
+	p.logger = logger
 }
 
-// ParseDirectory parses all localization files in the given directory and subdirectories
+// ReplaceDir is the name of the localization subdirectory whose entries take
+// priority over all other localization files
+const ReplaceDir = "replace"
+
+// languagePattern extracts the language from a file name (*_l_<language>.yml)
+var languagePattern = regexp.MustCompile(`_l_(\w+)\.yml$`)
+
+// ParseDirectory parses all localization files in the given directory and
+// subdirectories. Files in a replace/ subdirectory are parsed last and their
+// entries are not overwritten by regular files parsed later, so they take
+// priority the same way they do in the game.
 func (p *LocalizationParser) ParseDirectory(localizationDir string) error {
 	// Check if directory exists
 	if _, err := os.Stat(localizationDir); os.IsNotExist(err) {
 		return fmt.Errorf("localization directory does not exist: %s", localizationDir)
 	}
 
+	return p.parseFS(os.DirFS(localizationDir), ".", func(name string) string {
+		return filepath.Join(localizationDir, filepath.FromSlash(name))
+	})
+}
+
+// ParseFS is ParseDirectory for the directory dir of a file system, such as
+// the localisation directory of a mod .zip archive. dir is a slash-separated
+// path as accepted by fs.ValidPath, "." for the root.
+func (p *LocalizationParser) ParseFS(fsys fs.FS, dir string) error {
+	if _, err := fs.Stat(fsys, dir); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("localization directory does not exist: %s", dir)
+	}
+
+	return p.parseFS(fsys, dir, func(name string) string { return name })
+}
+
+// parseFS parses the localization files below dir of fsys. location turns
+// the paths of fsys into the paths shown in warnings.
+func (p *LocalizationParser) parseFS(fsys fs.FS, dir string, location func(string) string) error {
+	var regular, replace []string
+
 	// Walk through all subdirectories
-	err := filepath.Walk(localizationDir, func(path string, info os.FileInfo, err error) error {
+	err := fs.WalkDir(fsys, dir, func(path string, entry fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
 		// Skip if not a file or not a YAML file
-		if info.IsDir() || !strings.HasSuffix(strings.ToLower(path), ".yml") {
+		if entry.IsDir() || !strings.HasSuffix(strings.ToLower(path), ".yml") {
 			return nil
 		}
 
-		// Extract language code from file name
-		// Format: *_l_<language>.yml
-		fileName := filepath.Base(path)
-		languagePattern := regexp.MustCompile(`_l_(\w+)\.yml$`)
-		matches := languagePattern.FindStringSubmatch(fileName)
-
-		if len(matches) < 2 {
-			// Skip files that don't match the pattern
-			return nil
+		if isReplacePath(dir, path) {
+			replace = append(replace, path)
+		} else {
+			regular = append(regular, path)
 		}
-
-		language := matches[1]
-
-		// Parse the file
-		if err := p.parseFile(path, language); err != nil {
-			// Log error but continue with other files
-			fmt.Printf("Warning: failed to parse localization file %s: %v\n", path, err)
-		}
-
 		return nil
 	})
 
@@ -77,26 +131,76 @@ func (p *LocalizationParser) ParseDirectory(localizationDir string) error {
 		return fmt.Errorf("failed to walk localization directory: %w", err)
 	}
 
+	for _, path := range regular {
+		p.parseLanguageFile(fsys, path, location(path), false)
+	}
+	for _, path := range replace {
+		p.parseLanguageFile(fsys, path, location(path), true)
+	}
+
 	return nil
 }
 
-// parseFile parses a single localization YAML file
-func (p *LocalizationParser) parseFile(filePath string, language string) error {
-	file, err := os.Open(filePath)
+// isReplacePath reports whether a file lies in a replace/ directory below
+// localizationDir. Both are slash-separated paths of the same file system.
+func isReplacePath(localizationDir, name string) bool {
+	rel := name
+	if localizationDir != "." {
+		var found bool
+		if rel, found = strings.CutPrefix(name, localizationDir+"/"); !found {
+			return false
+		}
+	}
+	for _, part := range strings.Split(path.Dir(rel), "/") {
+		if strings.EqualFold(part, ReplaceDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseLanguageFile parses a file named *_l_<language>.yml. Other files are
+// skipped.
+func (p *LocalizationParser) parseLanguageFile(fsys fs.FS, name, location string, replace bool) {
+	matches := languagePattern.FindStringSubmatch(path.Base(name))
+	if len(matches) < 2 {
+		return
+	}
+
+	if err := p.parseFile(fsys, name, matches[1], replace); err != nil {
+		// Log error but continue with other files
+		p.logger.Warn("Failed to parse localization file", "file", location, "error", err)
+	}
+}
+
+// parseFile parses a single localization YAML file of fsys. Entries of
+// replace files can only be overwritten by other replace files.
+func (p *LocalizationParser) parseFile(fsys fs.FS, name string, language string, replace bool) error {
+	file, err := fsys.Open(name)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
+	// Localization files start with a byte order mark
+	content, err := charset.NewReader(file)
+	if err != nil {
+		return err
+	}
+
 	// Ensure language data exists
 	if p.data.Languages[language] == nil {
 		p.data.Languages[language] = &LanguageData{
 			Translations: make(map[string]string),
+			replaced:     make(map[string]bool),
 		}
 	}
 
 	langData := p.data.Languages[language]
-	scanner := bufio.NewScanner(file)
+	if langData.replaced == nil {
+		langData.replaced = make(map[string]bool)
+	}
+	scanner := bufio.NewScanner(content)
 
 	// Pattern to match localization entries with optional version number:
 	// Format 1: key:version "value" (e.g., tech_basic_science_lab_1:0 "Scientific Method")
@@ -105,7 +209,7 @@ func (p *LocalizationParser) parseFile(filePath string, language string) error {
 	entryPattern2 := regexp.MustCompile(`^\s*([a-zA-Z0-9_]+):\s*"(.+)"`)
 
 	for scanner.Scan() {
-		line := scanner.Text()
+		line := charset.StripComment(scanner.Text())
 
 		// Skip empty lines, comments, and language header
 		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") || strings.HasPrefix(strings.TrimSpace(line), "l_") {
@@ -127,6 +231,11 @@ func (p *LocalizationParser) parseFile(filePath string, language string) error {
 			value = strings.ReplaceAll(value, `\"`, `"`)
 			value = strings.ReplaceAll(value, `\n`, "\n")
 
+			if replace {
+				langData.replaced[key] = true
+			} else if langData.replaced[key] {
+				continue
+			}
 			langData.Translations[key] = value
 		}
 	}
@@ -140,31 +249,61 @@ func (p *LocalizationParser) parseFile(filePath string, language string) error {
 
 // GetLocalizedName returns the localized name for a technology key
 func (p *LocalizationParser) GetLocalizedName(techKey string, language string) string {
-	if langData, ok := p.data.Languages[language]; ok {
-		if name, ok := langData.Translations[techKey]; ok {
-			return p.resolveVariables(name, language)
-		}
-	}
-	return ""
+	name, _ := p.LookupName(techKey, []string{language})
+	return name
 }
 
 // GetLocalizedDescription returns the localized description for a technology key
 func (p *LocalizationParser) GetLocalizedDescription(techKey string, language string) string {
-	descKey := techKey + "_desc"
-	if langData, ok := p.data.Languages[language]; ok {
-		if desc, ok := langData.Translations[descKey]; ok {
-			return p.resolveVariables(desc, language)
+	desc, _ := p.LookupDescription(techKey, []string{language})
+	return desc
+}
+
+// LanguageChain returns language followed by its fallback languages, without
+// duplicates
+func LanguageChain(language string, fallbacks []string) []string {
+	chain := []string{language}
+	for _, fallback := range fallbacks {
+		if !slices.Contains(chain, fallback) {
+			chain = append(chain, fallback)
+		}
+	}
+	return chain
+}
+
+// LookupName returns the localized name for a technology key in the first
+// language of chain that has one, and that language. Both are empty if no
+// language has the key.
+func (p *LocalizationParser) LookupName(techKey string, chain []string) (string, string) {
+	return p.lookup(techKey, chain)
+}
+
+// LookupDescription returns the localized description for a technology key
+// in the first language of chain that has one, and that language
+func (p *LocalizationParser) LookupDescription(techKey string, chain []string) (string, string) {
+	return p.lookup(techKey+DescriptionSuffix, chain)
+}
+
+// lookup finds a key along a language chain. Variables in the text are
+// resolved in the language it was found in and then its fallbacks.
+func (p *LocalizationParser) lookup(key string, chain []string) (string, string) {
+	for i, language := range chain {
+		if langData, ok := p.data.Languages[language]; ok {
+			if text, ok := langData.Translations[key]; ok {
+				return p.resolveVariables(text, chain[i:]), language
+			}
 		}
 	}
-	return ""
+	return "", ""
 }
 
-// GetAvailableLanguages returns a list of all parsed languages
+// GetAvailableLanguages returns a sorted list of all parsed languages
 func (p *LocalizationParser) GetAvailableLanguages() []string {
 	languages := make([]string, 0, len(p.data.Languages))
 	for lang := range p.data.Languages {
 		languages = append(languages, lang)
 	}
+	slices.Sort(languages)
 	return languages
 }
 
@@ -204,16 +343,17 @@ func (p *LocalizationParser) GetData() *LocalizationData {
 }
 
 // resolveVariables recursively resolves variable references in localized strings
-// Variables are in the format $variable_name$ and reference other localization keys
-func (p *LocalizationParser) resolveVariables(text string, language string) string {
+// Variables are in the format $variable_name$ and reference other localization keys,
+// looked up in the first of the given languages that has them
+func (p *LocalizationParser) resolveVariables(text string, languages []string) string {
 	// Keep track of visited keys to prevent infinite loops
 	visited := make(map[string]bool)
 
-	return p.resolveVariablesRecursive(text, language, visited, 0)
+	return p.resolveVariablesRecursive(text, languages, visited, 0)
 }
 
 // resolveVariablesRecursive is the recursive helper function
-func (p *LocalizationParser) resolveVariablesRecursive(text string, language string, visited map[string]bool, depth int) string {
+func (p *LocalizationParser) resolveVariablesRecursive(text string, languages []string, visited map[string]bool, depth int) string {
 	// Prevent infinite recursion
 	if depth > 10 {
 		return text
@@ -236,10 +376,12 @@ func (p *LocalizationParser) resolveVariablesRecursive(text string, language str
 		visited[varName] = true
 
 		// Look up the variable value
-		if langData, ok := p.data.Languages[language]; ok {
-			if value, ok := langData.Translations[varName]; ok {
-				// Recursively resolve any variables in the value
-				return p.resolveVariablesRecursive(value, language, visited, depth+1)
+		for _, language := range languages {
+			if langData, ok := p.data.Languages[language]; ok {
+				if value, ok := langData.Translations[varName]; ok {
+					// Recursively resolve any variables in the value
+					return p.resolveVariablesRecursive(value, languages, visited, depth+1)
+				}
 			}
 		}
 
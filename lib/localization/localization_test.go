@@ -1,7 +1,10 @@
 package localization
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"testing/fstest"
 )
 
 func TestResolveVariables(t *testing.T) {
@@ -67,7 +70,7 @@ func TestResolveVariables(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := parser.resolveVariables(tt.input, "english")
+			result := parser.resolveVariables(tt.input, []string{"english"})
 			if result != tt.expected {
 				t.Errorf("resolveVariables() = %q, want %q", result, tt.expected)
 			}
@@ -118,3 +121,151 @@ func TestGetLocalizedNameWithVariables(t *testing.T) {
 		})
 	}
 }
+
+// writeLocalizationFile writes a localization file below dir
+func writeLocalizationFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write localization file: %v", err)
+	}
+}
+
+func TestReplaceDirectoryPriority(t *testing.T) {
+	baseDir := t.TempDir()
+	modDir := t.TempDir()
+
+	// zz_ sorts after replace/, so file order alone would let it win
+	writeLocalizationFile(t, baseDir, "english/replace/fixes_l_english.yml", "l_english:\n tech_lasers_1:0 \"Replaced Lasers\"\n")
+	writeLocalizationFile(t, baseDir, "english/zz_tech_l_english.yml", "l_english:\n tech_lasers_1:0 \"Red Lasers\"\n tech_lasers_2:0 \"Blue Lasers\"\n")
+	writeLocalizationFile(t, modDir, "english/mod_l_english.yml", "l_english:\n tech_lasers_1:0 \"Mod Lasers\"\n tech_lasers_2:0 \"Mod Blue Lasers\"\n")
+
+	parser := NewLocalizationParser()
+	for _, dir := range []string{baseDir, modDir} {
+		if err := parser.ParseDirectory(dir); err != nil {
+			t.Fatalf("Failed to parse %s: %v", dir, err)
+		}
+	}
+
+	if name := parser.GetLocalizedName("tech_lasers_1", "english"); name != "Replaced Lasers" {
+		t.Errorf("Expected the replace entry to win, got %q", name)
+	}
+	if name := parser.GetLocalizedName("tech_lasers_2", "english"); name != "Mod Blue Lasers" {
+		t.Errorf("Expected the later regular entry to win, got %q", name)
+	}
+}
+
+func TestIsReplacePath(t *testing.T) {
+	root := "game/localisation"
+	tests := map[string]bool{
+		root + "/english/replace/a_l_english.yml": true,
+		root + "/replace/a_l_english.yml":         true,
+		root + "/english/a_l_english.yml":         false,
+		root + "/english/replace_l_english.yml":   false,
+		"english/replace/a_l_english.yml":         false,
+	}
+
+	for path, expected := range tests {
+		if got := isReplacePath(root, path); got != expected {
+			t.Errorf("isReplacePath(%q) = %v, want %v", path, got, expected)
+		}
+	}
+}
+
+func TestLookupFallbackChain(t *testing.T) {
+	dir := t.TempDir()
+	writeLocalizationFile(t, dir, "english/tech_l_english.yml", "l_english:\n tech_a:0 \"Alpha\"\n tech_a_desc:0 \"Uses $SHARED$\"\n tech_b:0 \"Beta\"\n SHARED:0 \"shared text\"\n")
+	writeLocalizationFile(t, dir, "braz_por/tech_l_braz_por.yml", "l_braz_por:\n tech_a:0 \"Alfa\"\n tech_a_desc:0 \"Usa $SHARED$\"\n")
+
+	parser := NewLocalizationParser()
+	if err := parser.ParseDirectory(dir); err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	chain := LanguageChain("braz_por", []string{"english", "braz_por"})
+	if len(chain) != 2 || chain[0] != "braz_por" || chain[1] != "english" {
+		t.Fatalf("Expected [braz_por english], got %v", chain)
+	}
+
+	if name, language := parser.LookupName("tech_a", chain); name != "Alfa" || language != "braz_por" {
+		t.Errorf("Expected the braz_por name, got %q from %q", name, language)
+	}
+	if name, language := parser.LookupName("tech_b", chain); name != "Beta" || language != "english" {
+		t.Errorf("Expected the english fallback, got %q from %q", name, language)
+	}
+	if desc, _ := parser.LookupDescription("tech_a", chain); desc != "Usa shared text" {
+		t.Errorf("Expected variables to resolve through the fallback, got %q", desc)
+	}
+	if name, language := parser.LookupName("tech_missing", chain); name != "" || language != "" {
+		t.Errorf("Expected no name for a missing key, got %q from %q", name, language)
+	}
+	if name := parser.GetLocalizedName("tech_b", "braz_por"); name != "" {
+		t.Errorf("Expected no fallback without a chain, got %q", name)
+	}
+}
+
+func TestParseFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"mod/localisation/english/replace/fix_l_english.yml": {Data: []byte("l_english:\n tech_a:0 \"Replaced\"\n")},
+		"mod/localisation/english/tech_l_english.yml":        {Data: []byte("l_english:\n tech_a:0 \"Alpha\"\n tech_b:0 \"Beta\"\n")},
+		"mod/localisation/german/tech_l_german.yml":          {Data: []byte("l_german:\n tech_b:0 \"Beta (de)\"\n")},
+		"mod/localisation/english/notes.txt":                 {Data: []byte("tech_c: \"Ignored\"\n")},
+	}
+
+	parser := NewLocalizationParser()
+	if err := parser.ParseFS(fsys, "mod/localisation"); err != nil {
+		t.Fatalf("Failed to parse file system: %v", err)
+	}
+
+	if name := parser.GetLocalizedName("tech_a", "english"); name != "Replaced" {
+		t.Errorf("Expected the replace entry to win, got %q", name)
+	}
+	if name := parser.GetLocalizedName("tech_b", "german"); name != "Beta (de)" {
+		t.Errorf("Expected the German entry, got %q", name)
+	}
+	if name := parser.GetLocalizedName("tech_c", "english"); name != "" {
+		t.Errorf("Expected files other than .yml to be skipped, got %q", name)
+	}
+
+	if err := parser.ParseFS(fsys, "missing"); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestParseFSEncodings(t *testing.T) {
+	fsys := fstest.MapFS{
+		"loc/english/bom_l_english.yml": {Data: []byte("\xEF\xBB\xBFtech_a:0 \"Alpha\"\n")},
+		"loc/english/cp_l_english.yml":  {Data: []byte("l_english:\n tech_b:0 \"Caf\xE9 \x96 Beta\"\n")},
+	}
+
+	parser := NewLocalizationParser()
+	if err := parser.ParseFS(fsys, "loc"); err != nil {
+		t.Fatalf("Failed to parse file system: %v", err)
+	}
+	if name := parser.GetLocalizedName("tech_a", "english"); name != "Alpha" {
+		t.Errorf("Expected the first key after the BOM, got %q", name)
+	}
+	if name := parser.GetLocalizedName("tech_b", "english"); name != "Café – Beta" {
+		t.Errorf("Expected Windows-1252 to be converted, got %q", name)
+	}
+}
+
+func TestParseFSComments(t *testing.T) {
+	fsys := fstest.MapFS{
+		"loc/english/tech_l_english.yml": {Data: []byte("l_english:\n # tech_a:0 \"Commented\"\n tech_b:0 \"#weak Beta#!\" # \"note\"\n")},
+	}
+
+	parser := NewLocalizationParser()
+	if err := parser.ParseFS(fsys, "loc"); err != nil {
+		t.Fatalf("Failed to parse file system: %v", err)
+	}
+	if name := parser.GetLocalizedName("tech_a", "english"); name != "" {
+		t.Errorf("Expected the commented entry to be skipped, got %q", name)
+	}
+	if name := parser.GetLocalizedName("tech_b", "english"); name != "#weak Beta#!" {
+		t.Errorf("Expected the color tags to be kept and the comment removed, got %q", name)
+	}
+}
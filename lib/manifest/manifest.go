@@ -0,0 +1,313 @@
+// Package manifest records fingerprints of the inputs and checksums of the
+// files a run generated, so a later run can skip unchanged files and deploy
+// pipelines can tell which files changed.
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// FormatVersion is the version of the manifest file format
+const FormatVersion = 1
+
+// Manifest records a fingerprint of the inputs of every generated file, so
+// a later run can skip files whose inputs have not changed, and the checksum
+// of every generated file, so deploy pipelines can tell which files changed
+type Manifest struct {
+	Version int               `json:"version"`
+	Files   map[string]string `json:"files"`             // Output path relative to the output directory -> fingerprint
+	Outputs map[string]Output `json:"outputs,omitempty"` // Output path relative to the output directory -> file as written
+	Inputs  *Inputs           `json:"inputs,omitempty"`  // What the run read, set when known
+}
+
+// Inputs records everything a run read, so a later run with the same inputs
+// can skip regenerating entirely
+type Inputs struct {
+	Settings string            `json:"settings"` // Fingerprint of the options the output depends on
+	Files    map[string]string `json:"files"`    // Input file -> hash of its content
+}
+
+// Changed returns the sorted names of the files added, removed or changed
+// since previous. A nil previous treats every file as added.
+func (i *Inputs) Changed(previous *Inputs) []string {
+	var changed []string
+	for name, fingerprint := range i.Files {
+		if previous == nil || previous.Files[name] != fingerprint {
+			changed = append(changed, name)
+		}
+	}
+	if previous != nil {
+		for name := range previous.Files {
+			if _, exists := i.Files[name]; !exists {
+				changed = append(changed, name)
+			}
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Output describes a generated file as it was written
+type Output struct {
+	SHA256      string    `json:"sha256"` // Hex-encoded SHA-256 of the content
+	Size        int64     `json:"size"`   // In bytes
+	GeneratedAt time.Time `json:"generatedAt"`
+}
+
+// New creates an empty manifest
+func New() *Manifest {
+	return &Manifest{
+		Version: FormatVersion,
+		Files:   make(map[string]string),
+	}
+}
+
+// Load reads a manifest written by a previous run
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	m := New()
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	if m.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d in %s (expected %d)", m.Version, path, FormatVersion)
+	}
+	if m.Files == nil {
+		m.Files = make(map[string]string)
+	}
+
+	return m, nil
+}
+
+// Save writes the manifest as JSON
+func (m *Manifest) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Set records the fingerprint of a generated file
+func (m *Manifest) Set(name, fingerprint string) {
+	m.Files[name] = fingerprint
+}
+
+// RecordOutputs records the checksum and size of every file of the manifest
+// found in dir, generated at now. A file with the same checksum in since
+// keeps the time it was generated at then. Files not in dir, such as those
+// skipped by an incremental run into another directory, keep their entry of
+// since, if any.
+func (m *Manifest) RecordOutputs(dir string, since *Manifest, now time.Time) error {
+	m.Outputs = make(map[string]Output, len(m.Files))
+	for _, name := range m.Names() {
+		var previous Output
+		var hasPrevious bool
+		if since != nil {
+			previous, hasPrevious = since.Outputs[name]
+		}
+
+		output, err := checksumFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if errors.Is(err, fs.ErrNotExist) {
+			if hasPrevious {
+				m.Outputs[name] = previous
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to checksum %s: %w", name, err)
+		}
+
+		output.GeneratedAt = now
+		if hasPrevious && previous.SHA256 == output.SHA256 {
+			output.GeneratedAt = previous.GeneratedAt
+		}
+		m.Outputs[name] = output
+	}
+	return nil
+}
+
+// checksumFile returns the SHA-256 and size of a file
+func checksumFile(path string) (Output, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Output{}, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return Output{}, err
+	}
+	return Output{SHA256: hex.EncodeToString(hash.Sum(nil)), Size: size}, nil
+}
+
+// Unchanged reports whether name was recorded with the same fingerprint. A
+// nil manifest treats every file as changed.
+func (m *Manifest) Unchanged(name, fingerprint string) bool {
+	if m == nil {
+		return false
+	}
+	previous, exists := m.Files[name]
+	return exists && previous == fingerprint
+}
+
+// UnchangedInputs reports whether the manifest was written by a run with the
+// same settings and input files. A nil manifest, or one without inputs,
+// treats the inputs as changed.
+func (m *Manifest) UnchangedInputs(inputs *Inputs) bool {
+	if m == nil || m.Inputs == nil || inputs == nil {
+		return false
+	}
+	return m.Inputs.Settings == inputs.Settings && len(inputs.Changed(m.Inputs)) == 0
+}
+
+// Names returns the sorted names of all recorded files
+func (m *Manifest) Names() []string {
+	names := make([]string, 0, len(m.Files))
+	for name := range m.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Fingerprint returns a hash of the given parts
+func Fingerprint(parts ...[]byte) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write(part)
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// FingerprintFile returns a hash of a file's content
+func FingerprintFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	return FingerprintReader(file)
+}
+
+// FingerprintReader returns a hash of the content read from r, the same as
+// FingerprintFile for a file with that content
+func FingerprintReader(r io.Reader) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// FingerprintFS returns a hash of the names and contents of the files below
+// the directories dirs of fsys, in lexical order. Missing directories are
+// skipped, so the fingerprint only changes when a file is added, removed or
+// changed.
+func FingerprintFS(fsys fs.FS, dirs ...string) (string, error) {
+	var parts [][]byte
+	err := walkFingerprints(fsys, dirs, func(name, fingerprint string) {
+		parts = append(parts, []byte(name), []byte(fingerprint))
+	})
+	if err != nil {
+		return "", err
+	}
+	return Fingerprint(parts...), nil
+}
+
+// FingerprintFiles returns a hash of the content of each file below the
+// directories dirs of fsys, by its name prefixed with prefix. Missing
+// directories are skipped like in FingerprintFS.
+func FingerprintFiles(fsys fs.FS, prefix string, dirs ...string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := walkFingerprints(fsys, dirs, func(name, fingerprint string) {
+		files[path.Join(prefix, name)] = fingerprint
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// StatFiles is FingerprintFiles hashing the size and modification time of
+// each file instead of its content, for files too large to read on every
+// run
+func StatFiles(fsys fs.FS, prefix string, dirs ...string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := walkFiles(fsys, dirs, func(name string, entry fs.DirEntry) error {
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		files[path.Join(prefix, name)] = Fingerprint(
+			[]byte(strconv.FormatInt(info.Size(), 10)),
+			[]byte(info.ModTime().UTC().Format(time.RFC3339Nano)),
+		)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// walkFingerprints calls fn with the hash of each file below the directories
+// dirs of fsys, in lexical order. A dir may also name a single file.
+func walkFingerprints(fsys fs.FS, dirs []string, fn func(name, fingerprint string)) error {
+	return walkFiles(fsys, dirs, func(name string, entry fs.DirEntry) error {
+		file, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		fingerprint, err := FingerprintReader(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		fn(name, fingerprint)
+		return nil
+	})
+}
+
+// walkFiles calls fn for each file below the directories dirs of fsys, in
+// lexical order, skipping missing directories. A dir may also name a single
+// file.
+func walkFiles(fsys fs.FS, dirs []string, fn func(name string, entry fs.DirEntry) error) error {
+	for _, dir := range dirs {
+		err := fs.WalkDir(fsys, dir, func(name string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if name == dir && errors.Is(err, fs.ErrNotExist) {
+					return fs.SkipDir
+				}
+				return err
+			}
+			if entry.IsDir() {
+				return nil
+			}
+			return fn(name, entry)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+
+	m := New()
+	m.Set("research-physics.json", Fingerprint([]byte("physics")))
+	m.Set("icons/tech_lasers.png", Fingerprint([]byte("lasers")))
+	if err := m.Save(path); err != nil {
+		t.Fatalf("Failed to save manifest: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load manifest: %v", err)
+	}
+
+	if !loaded.Unchanged("research-physics.json", Fingerprint([]byte("physics"))) {
+		t.Error("Expected research-physics.json to be unchanged")
+	}
+	if loaded.Unchanged("research-physics.json", Fingerprint([]byte("changed"))) {
+		t.Error("Expected changed fingerprint to be reported as changed")
+	}
+	if loaded.Unchanged("research-society.json", Fingerprint([]byte("society"))) {
+		t.Error("Expected unknown file to be reported as changed")
+	}
+
+	names := loaded.Names()
+	if len(names) != 2 || names[0] != "icons/tech_lasers.png" {
+		t.Errorf("Expected sorted names, got %v", names)
+	}
+}
+
+func TestNilManifest(t *testing.T) {
+	var m *Manifest
+	if m.Unchanged("metadata.json", "abc") {
+		t.Error("Expected nil manifest to report every file as changed")
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := map[string]string{
+		"malformed json": `{"files": `,
+		"wrong version":  `{"version": 99, "files": {}}`,
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "manifest.json")
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write manifest: %v", err)
+			}
+			if _, err := Load(path); err == nil {
+				t.Error("Expected error for invalid manifest")
+			}
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	// Parts are separated so different splits give different fingerprints
+	if Fingerprint([]byte("ab"), []byte("c")) == Fingerprint([]byte("a"), []byte("bc")) {
+		t.Error("Expected fingerprints of different parts to differ")
+	}
+
+	path := filepath.Join(t.TempDir(), "icon.dds")
+	if err := os.WriteFile(path, []byte("icon"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fingerprint, err := FingerprintFile(path)
+	if err != nil {
+		t.Fatalf("Failed to fingerprint file: %v", err)
+	}
+	if fingerprint == "" || fingerprint == Fingerprint([]byte("other")) {
+		t.Errorf("Unexpected file fingerprint %q", fingerprint)
+	}
+}
+
+func TestFingerprintFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"common/technology/a.txt": {Data: []byte("a")},
+		"common/technology/b.txt": {Data: []byte("b")},
+		"localisation/x.yml":      {Data: []byte("x")},
+	}
+
+	first, err := FingerprintFS(fsys, "common/technology", "localisation", "localisation_synced")
+	if err != nil {
+		t.Fatalf("Failed to fingerprint: %v", err)
+	}
+	if second, _ := FingerprintFS(fsys, "common/technology", "localisation"); second != first {
+		t.Error("Expected a missing directory not to change the fingerprint")
+	}
+
+	fsys["common/technology/b.txt"] = &fstest.MapFile{Data: []byte("changed")}
+	if changed, _ := FingerprintFS(fsys, "common/technology", "localisation"); changed == first {
+		t.Error("Expected a changed file to change the fingerprint")
+	}
+
+	delete(fsys, "common/technology/b.txt")
+	fsys["common/technology/c.txt"] = &fstest.MapFile{Data: []byte("b")}
+	if renamed, _ := FingerprintFS(fsys, "common/technology", "localisation"); renamed == first {
+		t.Error("Expected a renamed file to change the fingerprint")
+	}
+}
+
+func TestRecordOutputs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "research-physics.json"), []byte("physics"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	generated := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	first := New()
+	first.Set("research-physics.json", Fingerprint([]byte("physics")))
+	if err := first.RecordOutputs(dir, nil, generated); err != nil {
+		t.Fatalf("Failed to record outputs: %v", err)
+	}
+	output := first.Outputs["research-physics.json"]
+	// SHA-256 of "physics"
+	if output.SHA256 != "742b27454919b5e1b2e834033f639088fc207c45a05b00635ec148a25939b6e9" {
+		t.Errorf("Expected a SHA-256 checksum, got %q", output.SHA256)
+	}
+	if output.Size != 7 || !output.GeneratedAt.Equal(generated) {
+		t.Errorf("Expected 7 bytes generated at %v, got %+v", generated, output)
+	}
+
+	// An unchanged file keeps its time, a missing one its previous entry
+	later := generated.Add(time.Hour)
+	if err := os.WriteFile(filepath.Join(dir, "research-society.json"), []byte("society"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	second := New()
+	second.Set("research-physics.json", Fingerprint([]byte("physics")))
+	second.Set("research-society.json", Fingerprint([]byte("society")))
+	if err := second.RecordOutputs(dir, first, later); err != nil {
+		t.Fatalf("Failed to record outputs: %v", err)
+	}
+	if second.Outputs["research-physics.json"] != output {
+		t.Errorf("Expected the unchanged file to keep its output, got %+v", second.Outputs["research-physics.json"])
+	}
+	if !second.Outputs["research-society.json"].GeneratedAt.Equal(later) {
+		t.Errorf("Expected the new file to be generated at %v, got %+v", later, second.Outputs["research-society.json"])
+	}
+
+	third := New()
+	third.Set("research-physics.json", Fingerprint([]byte("physics")))
+	if err := third.RecordOutputs(t.TempDir(), first, later); err != nil {
+		t.Fatalf("Failed to record outputs: %v", err)
+	}
+	if third.Outputs["research-physics.json"] != output {
+		t.Errorf("Expected the output of a file not written to be carried over, got %+v", third.Outputs)
+	}
+}
+
+func TestFingerprintFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"common/technology/a.txt": {Data: []byte("a")},
+		"launcher-settings.json":  {Data: []byte("{}")},
+	}
+
+	files, err := FingerprintFiles(fsys, "game", "common", "launcher-settings.json", "events")
+	if err != nil {
+		t.Fatalf("Failed to fingerprint: %v", err)
+	}
+	expected, _ := FingerprintReader(strings.NewReader("a"))
+	if len(files) != 2 || files["game/common/technology/a.txt"] != expected {
+		t.Errorf("Expected a hash of each file by its prefixed name, got %v", files)
+	}
+	if _, exists := files["game/launcher-settings.json"]; !exists {
+		t.Errorf("Expected a single file to be hashed, got %v", files)
+	}
+}
+
+func TestStatFiles(t *testing.T) {
+	modTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"dlc/dlc001/dlc001.zip": {Data: []byte("archive"), ModTime: modTime},
+	}
+
+	files, err := StatFiles(fsys, "game", "dlc", "missing")
+	if err != nil {
+		t.Fatalf("Failed to fingerprint: %v", err)
+	}
+	fingerprint := files["game/dlc/dlc001/dlc001.zip"]
+	if len(files) != 1 || fingerprint == "" {
+		t.Fatalf("Expected a fingerprint of the archive by its prefixed name, got %v", files)
+	}
+
+	// The content isn't read, only the size and time count
+	fsys["dlc/dlc001/dlc001.zip"] = &fstest.MapFile{Data: []byte("ARCHIVE"), ModTime: modTime}
+	if files, _ := StatFiles(fsys, "game", "dlc"); files["game/dlc/dlc001/dlc001.zip"] != fingerprint {
+		t.Error("Expected the same size and time to give the same fingerprint")
+	}
+	fsys["dlc/dlc001/dlc001.zip"] = &fstest.MapFile{Data: []byte("ARCHIVE"), ModTime: modTime.Add(time.Second)}
+	if files, _ := StatFiles(fsys, "game", "dlc"); files["game/dlc/dlc001/dlc001.zip"] == fingerprint {
+		t.Error("Expected a new modification time to change the fingerprint")
+	}
+}
+
+func TestUnchangedInputs(t *testing.T) {
+	inputs := &Inputs{Settings: "flags", Files: map[string]string{"game/a.txt": "1", "game/b.txt": "2"}}
+
+	m := New()
+	if m.UnchangedInputs(inputs) {
+		t.Error("Expected a manifest without inputs to treat them as changed")
+	}
+	m.Inputs = &Inputs{Settings: "flags", Files: map[string]string{"game/a.txt": "1", "game/b.txt": "2"}}
+	if !m.UnchangedInputs(inputs) {
+		t.Error("Expected the same inputs to be unchanged")
+	}
+
+	changed := &Inputs{Settings: "flags", Files: map[string]string{"game/a.txt": "changed", "game/c.txt": "3"}}
+	if m.UnchangedInputs(changed) {
+		t.Error("Expected changed files to change the inputs")
+	}
+	if got := strings.Join(changed.Changed(m.Inputs), ","); got != "game/a.txt,game/b.txt,game/c.txt" {
+		t.Errorf("Expected the changed, removed and added files, got %s", got)
+	}
+	if m.UnchangedInputs(&Inputs{Settings: "other", Files: inputs.Files}) {
+		t.Error("Expected changed settings to change the inputs")
+	}
+}
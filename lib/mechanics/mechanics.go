@@ -0,0 +1,314 @@
+// Package mechanics reads the research defines, tier rules and static
+// modifiers that decide how research works in a game directory and its
+// mods.
+package mechanics
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/danaketh/StellarisDataParser/lib/charset"
+	"github.com/danaketh/StellarisDataParser/lib/gamefs"
+)
+
+// Locations of the research mechanics, relative to the game or mod directory
+const (
+	DefinesDir         = "common/defines"
+	TierDir            = "common/technology/tier"
+	StaticModifiersDir = "common/static_modifiers"
+)
+
+// Define is a numeric game define that affects research
+type Define struct {
+	Key         string  `json:"key"` // Namespace.KEY, e.g. NGameplay.TECH_COST_MULT
+	Value       float64 `json:"value"`
+	Explanation string  `json:"explanation"`
+}
+
+// TierRule describes when technologies of a tier can be offered
+type TierRule struct {
+	Tier               int    `json:"tier"`
+	PreviouslyUnlocked int    `json:"previouslyUnlocked"` // Technologies of the previous tier that must be researched first
+	Explanation        string `json:"explanation"`
+}
+
+// Effect is a single research-related modifier of a static modifier
+type Effect struct {
+	Key   string  `json:"key"`
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+// StaticModifier is a static modifier with research-related effects
+type StaticModifier struct {
+	Key         string   `json:"key"`
+	Name        string   `json:"name"`
+	Effects     []Effect `json:"effects"` // Only the research-related effects
+	Explanation string   `json:"explanation"`
+}
+
+// Mechanics combines the defines, tier rules and static modifiers that
+// explain how research works
+type Mechanics struct {
+	Defines         []Define         `json:"defines"`
+	Tiers           []TierRule       `json:"tiers"`
+	StaticModifiers []StaticModifier `json:"staticModifiers"`
+}
+
+// Localizer looks up localized text; it returns an empty string for missing
+// keys
+type Localizer func(key string) string
+
+// researchPattern matches the keys of research-related defines and modifiers
+var researchPattern = regexp.MustCompile(`(?i)tech|research`)
+
+// Load reads the research mechanics from a game directory followed by mods,
+// see gamefs.Open. Definitions with the same key in a later directory replace
+// earlier ones. Missing directories are skipped.
+func Load(dirs ...string) (*Mechanics, error) {
+	defines := make(map[string]float64)
+	tiers := make(map[int]int)
+	modifiers := make(map[string][]Effect)
+
+	for _, dir := range dirs {
+		fsys, err := gamefs.Open(dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		defer fsys.Close()
+
+		blocks, err := readDir(fsys, DefinesDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range blocks {
+			for _, entry := range b.entries {
+				if researchPattern.MatchString(entry.key) {
+					defines[b.name+"."+entry.key] = entry.value
+				}
+			}
+		}
+
+		if blocks, err = readDir(fsys, TierDir); err != nil {
+			return nil, err
+		}
+		for _, b := range blocks {
+			tier, err := strconv.Atoi(strings.TrimPrefix(b.name, "tier_"))
+			if err != nil {
+				continue
+			}
+			tiers[tier] = 0
+			for _, entry := range b.entries {
+				if entry.key == "previously_unlocked" {
+					tiers[tier] = int(entry.value)
+				}
+			}
+		}
+
+		if blocks, err = readDir(fsys, StaticModifiersDir); err != nil {
+			return nil, err
+		}
+		for _, b := range blocks {
+			var effects []Effect
+			for _, entry := range b.entries {
+				if researchPattern.MatchString(entry.key) {
+					effects = append(effects, Effect{Key: entry.key, Value: entry.value})
+				}
+			}
+			if len(effects) > 0 {
+				modifiers[b.name] = effects
+			} else {
+				delete(modifiers, b.name)
+			}
+		}
+	}
+
+	m := &Mechanics{
+		Defines:         []Define{},
+		Tiers:           []TierRule{},
+		StaticModifiers: []StaticModifier{},
+	}
+	for key, value := range defines {
+		m.Defines = append(m.Defines, Define{Key: key, Value: value})
+	}
+	sort.Slice(m.Defines, func(i, j int) bool { return m.Defines[i].Key < m.Defines[j].Key })
+
+	for tier, previous := range tiers {
+		m.Tiers = append(m.Tiers, TierRule{Tier: tier, PreviouslyUnlocked: previous})
+	}
+	sort.Slice(m.Tiers, func(i, j int) bool { return m.Tiers[i].Tier < m.Tiers[j].Tier })
+
+	for key, effects := range modifiers {
+		m.StaticModifiers = append(m.StaticModifiers, StaticModifier{Key: key, Effects: effects})
+	}
+	sort.Slice(m.StaticModifiers, func(i, j int) bool { return m.StaticModifiers[i].Key < m.StaticModifiers[j].Key })
+
+	m.Localize(nil)
+	return m, nil
+}
+
+// Localize fills in names and explanations. Modifier names use the
+// localization key of the modifier and effect names the game's MOD_<KEY>
+// keys; names not found are formatted from the key. A nil localizer formats
+// every name from its key.
+func (m *Mechanics) Localize(localize Localizer) {
+	lookup := func(key, fallback string) string {
+		if localize != nil {
+			if text := localize(key); text != "" {
+				return text
+			}
+		}
+		return humanize(fallback)
+	}
+
+	for i := range m.Defines {
+		define := &m.Defines[i]
+		name := define.Key[strings.Index(define.Key, ".")+1:]
+		define.Explanation = fmt.Sprintf("%s: %s", humanize(name), formatNumber(define.Value))
+	}
+
+	for i := range m.Tiers {
+		rule := &m.Tiers[i]
+		if rule.PreviouslyUnlocked == 0 {
+			rule.Explanation = fmt.Sprintf("Tier %d technologies can be offered from the start", rule.Tier)
+		} else {
+			rule.Explanation = fmt.Sprintf("Tier %d technologies can be offered after researching %d technologies of tier %d",
+				rule.Tier, rule.PreviouslyUnlocked, rule.Tier-1)
+		}
+	}
+
+	for i := range m.StaticModifiers {
+		modifier := &m.StaticModifiers[i]
+		modifier.Name = lookup(modifier.Key, modifier.Key)
+		parts := make([]string, len(modifier.Effects))
+		for j := range modifier.Effects {
+			effect := &modifier.Effects[j]
+			effect.Name = lookup("MOD_"+strings.ToUpper(effect.Key), effect.Key)
+			parts[j] = fmt.Sprintf("%s %s", formatEffectValue(effect.Key, effect.Value), effect.Name)
+		}
+		modifier.Explanation = fmt.Sprintf("%s: %s", modifier.Name, strings.Join(parts, ", "))
+	}
+}
+
+// humanize turns a key such as TECH_COST_MULT into "Tech cost mult"
+func humanize(key string) string {
+	words := strings.Fields(strings.ToLower(strings.ReplaceAll(key, "_", " ")))
+	if len(words) == 0 {
+		return key
+	}
+	words[0] = strings.ToUpper(words[0][:1]) + words[0][1:]
+	return strings.Join(words, " ")
+}
+
+// formatNumber writes a value without trailing zeros
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// formatEffectValue writes multipliers and speeds as signed percentages and
+// other values as signed numbers
+func formatEffectValue(key string, value float64) string {
+	sign := "+"
+	if value < 0 {
+		sign = "-"
+	}
+	if strings.HasSuffix(key, "_mult") || strings.HasSuffix(key, "_speed") {
+		return sign + formatNumber(math.Round(math.Abs(value)*10000)/100) + "%"
+	}
+	return sign + formatNumber(math.Abs(value))
+}
+
+// block is a top-level block with its numeric key = value entries
+type block struct {
+	name    string
+	entries []entry
+}
+
+type entry struct {
+	key   string
+	value float64
+}
+
+// tokenPattern splits script files into quoted strings, braces, equals signs
+// and words
+var tokenPattern = regexp.MustCompile(`"[^"]*"|[{}=]|[^\s{}="]+`)
+
+// readDir reads the top-level blocks of every .txt file below dir of fsys,
+// in lexical path order. A missing directory yields no blocks.
+func readDir(fsys fs.FS, dir string) ([]block, error) {
+	if _, err := fs.Stat(fsys, dir); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	var blocks []block
+	err := fs.WalkDir(fsys, dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".txt") {
+			return nil
+		}
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, parseBlocks(string(charset.Decode(content)))...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	return blocks, nil
+}
+
+// parseBlocks returns the top-level name = { ... } blocks of a script with
+// the numeric entries directly inside them. Nested blocks and other values
+// are skipped.
+func parseBlocks(content string) []block {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		if idx := strings.Index(line, "#"); idx != -1 {
+			line = line[:idx]
+		}
+		lines = append(lines, line)
+	}
+	tokens := tokenPattern.FindAllString(strings.Join(lines, "\n"), -1)
+
+	var blocks []block
+	var current *block
+	depth := 0
+	for i := 0; i < len(tokens); i++ {
+		switch token := tokens[i]; {
+		case token == "{":
+			depth++
+		case token == "}":
+			if depth > 0 {
+				depth--
+			}
+			if depth == 0 && current != nil {
+				blocks = append(blocks, *current)
+				current = nil
+			}
+		case i+2 < len(tokens) && tokens[i+1] == "=":
+			value := tokens[i+2]
+			if depth == 0 && value == "{" {
+				current = &block{name: strings.Trim(token, "\"")}
+				i++
+			} else if depth == 1 && current != nil && value != "{" {
+				if number, err := strconv.ParseFloat(value, 64); err == nil {
+					current.entries = append(current.entries, entry{key: strings.Trim(token, "\""), value: number})
+				}
+				i += 2
+			}
+		}
+	}
+	return blocks
+}
@@ -0,0 +1,89 @@
+package mechanics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	gameDir := t.TempDir()
+	modDir := t.TempDir()
+
+	writeFile(t, gameDir, "common/defines/00_defines.txt", `
+NGameplay = {
+	TECH_COST_MULT = 0.5 # per colony
+	RESEARCH_ALTERNATIVES = 3
+	START_YEAR = 2200
+	TECH_TIERS = { 1 2 3 }
+	TECH_NAME = "some text"
+}
+`)
+	writeFile(t, gameDir, "common/technology/tier/00_tier.txt", "0 = { }\n1 = { previously_unlocked = 0 }\n2 = {\n\tpreviously_unlocked = 6\n}\n")
+	writeFile(t, gameDir, "common/static_modifiers/00_static.txt", `
+research_station = {
+	physics_research_speed = 0.1
+	all_technology_research_speed = -0.05
+	ship_speed_mult = 0.2
+}
+unrelated = {
+	ship_speed_mult = 0.2
+}
+`)
+	writeFile(t, modDir, "common/defines/mod_defines.txt", "NGameplay = {\n\tRESEARCH_ALTERNATIVES = 4\n}\n")
+
+	m, err := Load(gameDir, modDir, filepath.Join(modDir, "missing"))
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+
+	if len(m.Defines) != 2 {
+		t.Fatalf("Expected 2 research defines, got %+v", m.Defines)
+	}
+	if define := m.Defines[0]; define.Key != "NGameplay.RESEARCH_ALTERNATIVES" || define.Value != 4 {
+		t.Errorf("Expected the mod define to win, got %+v", define)
+	}
+	if explanation := m.Defines[1].Explanation; explanation != "Tech cost mult: 0.5" {
+		t.Errorf("Unexpected define explanation %q", explanation)
+	}
+
+	if len(m.Tiers) != 3 || m.Tiers[2].PreviouslyUnlocked != 6 {
+		t.Fatalf("Expected 3 tier rules, got %+v", m.Tiers)
+	}
+	if explanation := m.Tiers[2].Explanation; explanation != "Tier 2 technologies can be offered after researching 6 technologies of tier 1" {
+		t.Errorf("Unexpected tier explanation %q", explanation)
+	}
+
+	if len(m.StaticModifiers) != 1 || len(m.StaticModifiers[0].Effects) != 2 {
+		t.Fatalf("Expected 1 static modifier with 2 research effects, got %+v", m.StaticModifiers)
+	}
+
+	m.Localize(func(key string) string {
+		return map[string]string{"research_station": "Research Station", "MOD_PHYSICS_RESEARCH_SPEED": "Physics Research Speed"}[key]
+	})
+	expected := "Research Station: +10% Physics Research Speed, -5% All technology research speed"
+	if explanation := m.StaticModifiers[0].Explanation; explanation != expected {
+		t.Errorf("Expected %q, got %q", expected, explanation)
+	}
+}
+
+func TestLoadEmpty(t *testing.T) {
+	m, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if m.Defines == nil || m.Tiers == nil || m.StaticModifiers == nil {
+		t.Errorf("Expected empty lists rather than nil, got %+v", m)
+	}
+}
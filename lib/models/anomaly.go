@@ -0,0 +1,38 @@
+package models
+
+// Anomaly is an anomaly defined in common/anomalies
+type Anomaly struct {
+	Key             string                 `json:"key"`
+	Name            string                 `json:"name"`
+	DescriptionKey  string                 `json:"descriptionKey,omitempty"`
+	Description     string                 `json:"description"`
+	Picture         string                 `json:"picture,omitempty"`
+	Level           int                    `json:"level"`           // Research difficulty
+	NullSpawnChance float64                `json:"nullSpawnChance"` // Chance that nothing spawns, 0 to 1
+	MaxOnce         bool                   `json:"maxOnce"`         // Spawns at most once per empire
+	SpawnChance     interface{}            `json:"spawnChance"`     // A number or a raw weight block with modifiers
+	OnSuccess       map[string]interface{} `json:"onSuccess"`       // Raw outcomes, keyed by outcome weight or index
+	OnFail          map[string]interface{} `json:"onFail"`
+	LinkedEvents    []string               `json:"linkedEvents"` // Events fired by the anomaly, in order of appearance
+	SourceFile      string                 `json:"sourceFile"`
+	Mod             string                 `json:"mod,omitempty"`
+}
+
+// SpecialProject is a special project defined in common/special_projects
+type SpecialProject struct {
+	Key            string                 `json:"key"`
+	Name           string                 `json:"name"`
+	DescriptionKey string                 `json:"descriptionKey,omitempty"`
+	Description    string                 `json:"description"`
+	Picture        string                 `json:"picture,omitempty"`
+	Cost           int                    `json:"cost"`           // Research points, 0 for projects completed by time
+	DaysToResearch int                    `json:"daysToResearch"` // 0 for projects completed with research points
+	Department     string                 `json:"department,omitempty"`
+	EventScope     string                 `json:"eventScope,omitempty"` // Scope of the completion event, such as ship_event
+	Requirements   map[string]interface{} `json:"requirements"`         // Ships and leaders required to work on the project
+	OnSuccess      map[string]interface{} `json:"onSuccess"`
+	OnFail         map[string]interface{} `json:"onFail"`
+	LinkedEvents   []string               `json:"linkedEvents"`
+	SourceFile     string                 `json:"sourceFile"`
+	Mod            string                 `json:"mod,omitempty"`
+}
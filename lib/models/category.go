@@ -0,0 +1,9 @@
+package models
+
+// Category is a research category defined in common/technology/category
+type Category struct {
+	Key  string
+	Name string // Localized name, empty when no localization was loaded
+	Icon string // Texture path relative to the game directory, or a GFX_ sprite name
+	Mod  string // Name of the mod defining this category, empty for the base game
+}
@@ -0,0 +1,22 @@
+package models
+
+// Types of define values
+const (
+	DefineNumber = "number"
+	DefineString = "string"
+	DefineBool   = "bool"
+	DefineList   = "list"  // A list of values such as { 1 2 3 }
+	DefineBlock  = "block" // A nested block of key = value pairs
+)
+
+// Define is an entry of a namespace block in common/defines, such as
+// TECH_COST_MULT in NGameplay = { ... }
+type Define struct {
+	Key        string      `json:"key"` // Namespace.KEY, e.g. NGameplay.TECH_COST_MULT
+	Namespace  string      `json:"namespace"`
+	Name       string      `json:"name"`
+	Type       string      `json:"type"`
+	Value      interface{} `json:"value"`
+	SourceFile string      `json:"sourceFile"`
+	Mod        string      `json:"mod,omitempty"`
+}
@@ -0,0 +1,43 @@
+package models
+
+// DiplomaticAction is a diplomatic action defined in
+// common/diplomatic_actions
+type DiplomaticAction struct {
+	Key          string                 `json:"key"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	Icon         string                 `json:"icon,omitempty"`
+	Potential    map[string]interface{} `json:"potential"`    // Raw condition tree for showing the action
+	Possible     map[string]interface{} `json:"possible"`     // Raw condition tree for taking it
+	Proposable   map[string]interface{} `json:"proposable"`   // Raw condition tree for proposing it to the AI
+	LinkedEvents []string               `json:"linkedEvents"` // Events fired by the action, in order of appearance
+	SourceFile   string                 `json:"sourceFile"`
+	Mod          string                 `json:"mod,omitempty"`
+}
+
+// AgreementPreset is a subject agreement preset, such as vassal or
+// tributary, defined in common/agreement_presets
+type AgreementPreset struct {
+	Key         string                 `json:"key"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Icon        string                 `json:"icon,omitempty"`
+	Potential   map[string]interface{} `json:"potential"` // Raw condition tree for offering the preset
+	Terms       map[string]interface{} `json:"terms"`     // Value of each subject term, by term
+	SourceFile  string                 `json:"sourceFile"`
+	Mod         string                 `json:"mod,omitempty"`
+}
+
+// SubjectTerm is a value of a subject agreement term defined in
+// common/agreement_term_values, such as allowing the subject to do diplomacy
+type SubjectTerm struct {
+	Key         string                 `json:"key"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Term        string                 `json:"term"`      // Term the value is for
+	Value       interface{}            `json:"value"`     // As written in the script
+	Potential   map[string]interface{} `json:"potential"` // Raw condition tree for the agreements the value is available in
+	Modifiers   map[string]interface{} `json:"modifiers"` // Effects on the subject, as written in the script
+	SourceFile  string                 `json:"sourceFile"`
+	Mod         string                 `json:"mod,omitempty"`
+}
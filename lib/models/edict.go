@@ -0,0 +1,43 @@
+package models
+
+// Edict is an edict or campaign defined in common/edicts
+type Edict struct {
+	Key           string                 `json:"key"`
+	Name          string                 `json:"name"` // Localized name, empty when no localization was loaded
+	Description   string                 `json:"description"`
+	Icon          string                 `json:"icon,omitempty"`
+	Length        int                    `json:"length"`    // Duration in days, 0 for edicts active until cancelled
+	Cost          map[string]float64     `json:"cost"`      // One-time resource cost
+	Upkeep        map[string]float64     `json:"upkeep"`    // Monthly resource cost while active
+	Modifiers     map[string]interface{} `json:"modifiers"` // Effects while active, as written in the script
+	Prerequisites []string               `json:"prerequisites"`
+	Potential     map[string]interface{} `json:"potential"` // Raw condition tree
+	Allow         map[string]interface{} `json:"allow"`     // Raw condition tree
+	SourceFile    string                 `json:"sourceFile"`
+	Mod           string                 `json:"mod,omitempty"`
+}
+
+// Policy is an empire policy defined in common/policies, such as the
+// diplomatic stance
+type Policy struct {
+	Key         string                 `json:"key"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Options     []PolicyOption         `json:"options"`
+	Potential   map[string]interface{} `json:"potential"`
+	Allow       map[string]interface{} `json:"allow"`
+	SourceFile  string                 `json:"sourceFile"`
+	Mod         string                 `json:"mod,omitempty"`
+}
+
+// PolicyOption is one of the choices of a policy
+type PolicyOption struct {
+	Key           string                 `json:"key"`
+	Name          string                 `json:"name"`
+	Description   string                 `json:"description"`
+	Cost          map[string]float64     `json:"cost"` // Resource cost of choosing the option, if any
+	Modifiers     map[string]interface{} `json:"modifiers"`
+	Prerequisites []string               `json:"prerequisites"`
+	Potential     map[string]interface{} `json:"potential"`
+	Valid         map[string]interface{} `json:"valid"` // Raw condition tree for keeping the option
+}
@@ -0,0 +1,19 @@
+package models
+
+// EspionageOperation is an espionage operation defined in
+// common/espionage_operation_types
+type EspionageOperation struct {
+	Key          string                 `json:"key"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	Icon         string                 `json:"icon,omitempty"`
+	Category     string                 `json:"category,omitempty"`
+	Difficulty   interface{}            `json:"difficulty"`   // A number or a raw block with modifiers
+	DaysPerPhase int                    `json:"daysPerPhase"` // Length of each phase of the operation
+	Cost         map[string]float64     `json:"cost"`
+	Potential    map[string]interface{} `json:"potential"`    // Raw condition tree for showing the operation
+	Allow        map[string]interface{} `json:"allow"`        // Raw condition tree for starting it
+	LinkedEvents []string               `json:"linkedEvents"` // Events fired by the operation, in order of appearance
+	SourceFile   string                 `json:"sourceFile"`
+	Mod          string                 `json:"mod,omitempty"`
+}
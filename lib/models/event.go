@@ -0,0 +1,29 @@
+package models
+
+// Event is an event defined in the events directory. Titles and descriptions
+// are localization keys; Title and Description hold their localized text.
+type Event struct {
+	ID              string                 `json:"id"`
+	Type            string                 `json:"type"` // Block name, such as country_event or planet_event
+	Namespace       string                 `json:"namespace"`
+	TitleKey        string                 `json:"titleKey,omitempty"`
+	DescriptionKeys []string               `json:"descriptionKeys"` // Every description, conditional ones included
+	Title           string                 `json:"title"`
+	Description     string                 `json:"description"` // Text of the first description
+	Picture         string                 `json:"picture,omitempty"`
+	Hidden          bool                   `json:"hidden"`        // hide_window, no window is shown
+	TriggeredOnly   bool                   `json:"triggeredOnly"` // Only fired by effects or on_actions
+	Trigger         map[string]interface{} `json:"trigger"`       // Raw condition tree
+	Immediate       map[string]interface{} `json:"immediate"`     // Raw effects run when the event fires
+	Options         []EventOption          `json:"options"`
+	SourceFile      string                 `json:"sourceFile"`
+	Mod             string                 `json:"mod,omitempty"`
+}
+
+// EventOption is a choice of an event
+type EventOption struct {
+	NameKey string                 `json:"nameKey"`
+	Name    string                 `json:"name"`
+	Trigger map[string]interface{} `json:"trigger"` // Raw condition tree for showing the option
+	Effects map[string]interface{} `json:"effects"` // Remaining contents of the option, as written in the script
+}
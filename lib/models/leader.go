@@ -0,0 +1,45 @@
+package models
+
+// LeaderTrait is a leader trait defined in common/traits
+type LeaderTrait struct {
+	Key                string                 `json:"key"`
+	Name               string                 `json:"name"`
+	Description        string                 `json:"description"`
+	Icon               string                 `json:"icon,omitempty"`
+	Classes            []string               `json:"classes"`            // Leader classes that can have the trait, empty for every class
+	Cost               int                    `json:"cost"`               // Trait points
+	Opposites          []string               `json:"opposites"`          // Traits the leader can't have together with this one
+	Prerequisites      []string               `json:"prerequisites"`      // Technologies that make the trait available
+	Modifiers          map[string]interface{} `json:"modifiers"`          // Effects on what the leader leads, as written in the script
+	SelfModifiers      map[string]interface{} `json:"selfModifiers"`      // Effects on the leader
+	CouncilorModifiers map[string]interface{} `json:"councilorModifiers"` // Effects while the leader sits on the council
+	Potential          map[string]interface{} `json:"potential"`          // Raw condition tree for the leaders that can get the trait
+	SourceFile         string                 `json:"sourceFile"`
+	Mod                string                 `json:"mod,omitempty"`
+}
+
+// LeaderClass is a leader class defined in common/leader_classes, such as
+// scientist or official
+type LeaderClass struct {
+	Key         string                 `json:"key"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Cost        map[string]float64     `json:"cost"`     // Recruitment cost
+	Upkeep      map[string]float64     `json:"upkeep"`   // Monthly cost
+	Settings    map[string]interface{} `json:"settings"` // The other fields of the class, as written in the script
+	SourceFile  string                 `json:"sourceFile"`
+	Mod         string                 `json:"mod,omitempty"`
+}
+
+// CouncilPosition is a council position defined in
+// common/governments/councils
+type CouncilPosition struct {
+	Key           string                 `json:"key"`
+	Name          string                 `json:"name"`
+	Description   string                 `json:"description"`
+	LeaderClasses []string               `json:"leaderClasses"` // Classes of the leaders that can fill the position
+	Potential     map[string]interface{} `json:"potential"`     // Raw condition tree for empires with the position
+	Modifiers     map[string]interface{} `json:"modifiers"`     // Effects of the councilor, as written in the script
+	SourceFile    string                 `json:"sourceFile"`
+	Mod           string                 `json:"mod,omitempty"`
+}
@@ -0,0 +1,37 @@
+package models
+
+// District is a planet district defined in common/districts
+type District struct {
+	Key           string                   `json:"key"`
+	Name          string                   `json:"name"`
+	Description   string                   `json:"description"`
+	Icon          string                   `json:"icon,omitempty"`
+	BuildTime     int                      `json:"buildTime"` // Base build time in days
+	Capped        bool                     `json:"capped"`    // Limited by the district_<key>_max modifier of the planet
+	Cost          map[string]float64       `json:"cost"`
+	Upkeep        map[string]float64       `json:"upkeep"`
+	Produces      map[string]float64       `json:"produces"`  // Monthly resource output
+	Modifiers     map[string]interface{}   `json:"modifiers"` // Planet modifiers of each district, such as jobs and housing
+	Triggered     []map[string]interface{} `json:"triggered"` // Conditional planet modifiers, each with potential and modifier
+	ConvertTo     []string                 `json:"convertTo"` // Districts this district is converted to on some planets
+	Prerequisites []string                 `json:"prerequisites"`
+	Potential     map[string]interface{}   `json:"potential"`
+	Allow         map[string]interface{}   `json:"allow"`
+	SourceFile    string                   `json:"sourceFile"`
+	Mod           string                   `json:"mod,omitempty"`
+}
+
+// PlanetClass is a planet type defined in common/planet_classes
+type PlanetClass struct {
+	Key          string                 `json:"key"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	Climate      string                 `json:"climate,omitempty"` // Species habitability depends on their preferred climate, such as dry
+	Colonizable  bool                   `json:"colonizable"`
+	Initial      bool                   `json:"initial"` // Can be generated at galaxy creation
+	Extreme      bool                   `json:"extreme"`
+	DistrictCaps map[string]float64     `json:"districtCaps"` // Maximum number of each capped district added by the planet class, by district
+	Modifiers    map[string]interface{} `json:"modifiers"`
+	SourceFile   string                 `json:"sourceFile"`
+	Mod          string                 `json:"mod,omitempty"`
+}
@@ -0,0 +1,42 @@
+package models
+
+// Relic is a relic defined in common/relics
+type Relic struct {
+	Key                string                   `json:"key"`
+	Name               string                   `json:"name"`
+	Description        string                   `json:"description"`
+	Portrait           string                   `json:"portrait,omitempty"` // GFX_ sprite or texture path of the relic art
+	IconFile           string                   `json:"iconFile,omitempty"` // Extracted art, relative to the icons directory
+	Score              int                      `json:"score"`              // Victory score of owning the relic
+	ActivationDuration int                      `json:"activationDuration"` // Days before the relic can be activated again
+	Cost               map[string]float64       `json:"cost"`               // Activation cost
+	PassiveEffects     []map[string]interface{} `json:"passiveEffects"`     // Country modifiers while owned, each with potential and modifier
+	ActiveEffect       map[string]interface{}   `json:"activeEffect"`       // Effect of activating the relic, as written in the script
+	Possible           map[string]interface{}   `json:"possible"`           // Raw condition tree for activating the relic
+	SourceFile         string                   `json:"sourceFile"`
+	Mod                string                   `json:"mod,omitempty"`
+}
+
+// ArchaeologySite is a dig site type defined in
+// common/archaeological_site_types
+type ArchaeologySite struct {
+	Key          string                 `json:"key"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	Picture      string                 `json:"picture,omitempty"`
+	MaxInstances int                    `json:"maxInstances"` // 0 when unlimited
+	Weight       interface{}            `json:"weight"`       // Spawn weight, a number or a raw weight block
+	Stages       []ArchaeologyStage     `json:"stages"`       // Chapters of the site, in order
+	Potential    map[string]interface{} `json:"potential"`
+	Allow        map[string]interface{} `json:"allow"`
+	OnRollFailed map[string]interface{} `json:"onRollFailed"` // Effect of a failed excavation roll
+	SourceFile   string                 `json:"sourceFile"`
+	Mod          string                 `json:"mod,omitempty"`
+}
+
+// ArchaeologyStage is a chapter of an archaeological site
+type ArchaeologyStage struct {
+	Difficulty int    `json:"difficulty"`
+	Icon       string `json:"icon,omitempty"`
+	Event      string `json:"event"` // Event fired when the chapter is completed
+}
@@ -0,0 +1,50 @@
+package models
+
+// ShipSize is a hull defined in common/ship_sizes, with the sections that fit
+// its slots
+type ShipSize struct {
+	Key           string                 `json:"key"`
+	Name          string                 `json:"name"`
+	Description   string                 `json:"description"`
+	Class         string                 `json:"class,omitempty"` // Such as shipclass_military or shipclass_starbase
+	Icon          string                 `json:"icon,omitempty"`
+	Stats         map[string]float64     `json:"stats"`     // Base values such as max_hitpoints and max_speed
+	Modifiers     map[string]interface{} `json:"modifiers"` // Modifiers applied to every ship of the size
+	Cost          map[string]float64     `json:"cost"`
+	Upkeep        map[string]float64     `json:"upkeep"`
+	BuildTime     int                    `json:"buildTime"`    // Base build time in days
+	SectionSlots  []SectionSlot          `json:"sectionSlots"` // In definition order
+	Sections      []*SectionTemplate     `json:"sections"`     // Sections fitting the slots, by slot and key
+	Prerequisites []string               `json:"prerequisites"`
+	Potential     map[string]interface{} `json:"potential"`
+	SourceFile    string                 `json:"sourceFile"`
+	Mod           string                 `json:"mod,omitempty"`
+}
+
+// SectionSlot is a slot of a hull that takes one section
+type SectionSlot struct {
+	Name    string `json:"name"` // Such as bow, mid or stern
+	Locator string `json:"locator"`
+}
+
+// SectionTemplate is a ship section defined in common/section_templates
+type SectionTemplate struct {
+	Key            string             `json:"key"`
+	Name           string             `json:"name"`
+	ShipSize       string             `json:"shipSize"`
+	Slot           string             `json:"slot"` // Section slot the section fits on
+	Icon           string             `json:"icon,omitempty"`
+	ComponentSlots []ComponentSlot    `json:"componentSlots"`
+	UtilitySlots   map[string]int     `json:"utilitySlots"` // Number of small, medium, large and aux utility slots
+	Cost           map[string]float64 `json:"cost"`
+	Prerequisites  []string           `json:"prerequisites"`
+	SourceFile     string             `json:"sourceFile"`
+	Mod            string             `json:"mod,omitempty"`
+}
+
+// ComponentSlot is a weapon slot of a section
+type ComponentSlot struct {
+	Name     string `json:"name"`
+	Template string `json:"template"` // Slot size and type, such as large_turret or point_defence_turret
+	Locator  string `json:"locator"`
+}
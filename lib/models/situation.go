@@ -0,0 +1,36 @@
+package models
+
+// Situation is a situation defined in common/situations
+type Situation struct {
+	Key             string              `json:"key"`
+	Name            string              `json:"name"`
+	Description     string              `json:"description"`
+	Category        string              `json:"category,omitempty"`
+	MonthlyProgress interface{}         `json:"monthlyProgress"` // A number or a raw block with modifiers
+	Stages          []SituationStage    `json:"stages"`          // In file order
+	Approaches      []SituationApproach `json:"approaches"`      // In file order
+	LinkedEvents    []string            `json:"linkedEvents"`    // Events fired by the situation, in order of appearance
+	SourceFile      string              `json:"sourceFile"`
+	Mod             string              `json:"mod,omitempty"`
+}
+
+// SituationStage is a stage of a situation, reached as its progress grows
+type SituationStage struct {
+	Key       string                 `json:"key"`
+	Name      string                 `json:"name"`
+	End       int                    `json:"end"` // Progress at which the stage ends
+	Icon      string                 `json:"icon,omitempty"`
+	Modifiers map[string]interface{} `json:"modifiers"` // Effects while in the stage, as written in the script
+}
+
+// SituationApproach is a way an empire can handle a situation
+type SituationApproach struct {
+	Key         string                 `json:"key"`
+	NameKey     string                 `json:"nameKey,omitempty"` // Localization key of the name, when it isn't the key
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Icon        string                 `json:"icon,omitempty"`
+	Potential   map[string]interface{} `json:"potential"` // Raw condition tree for showing the approach
+	Allow       map[string]interface{} `json:"allow"`     // Raw condition tree for picking it
+	Modifiers   map[string]interface{} `json:"modifiers"` // Effects while picked, as written in the script
+}
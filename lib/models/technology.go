@@ -1,3 +1,5 @@
+// Package models holds the data structures shared by the parser, the tree
+// and the generator.
 package models
 
 // Technology represents a single research technology in Stellaris
@@ -9,17 +11,21 @@ type Technology struct {
 	Area          string
 	Tier          int
 	Category      []string
-	Prerequisites []string
+	Prerequisites []string // Every prerequisite key, across all groups
 	Weight        int
 	BaseWeight    float64
-	SourceFile    string // The filename this technology was parsed from
-	Icon          string // Icon filename (without extension), defaults to tech key if not specified
+	SourceFile    string  // The filename this technology was parsed from
+	Source        *Source // Original script text, set when the parser keeps sources
+	Mod           string  // Name of the mod defining this technology, empty for the base game
+	Icon          string  // Icon filename (without extension), defaults to tech key if not specified
 	IsStartTech   bool
 	IsDangerous   bool
 	IsRare        bool
 	IsEvent       bool
+	IsInsight     bool // Granted through insight (First Contact) instead of being drawn as a research option
 	IsRepeatable  bool
-	Levels        int // For repeatable technologies
+	Levels        int // For repeatable technologies, -1 means unlimited
+	CostPerLevel  int // Cost increase per level for repeatable technologies
 	// Empire type restrictions
 	IsGestalt          bool
 	IsMegacorp         bool
@@ -28,17 +34,123 @@ type Technology struct {
 	IsDriveAssimilator bool
 	IsRogueServitor    bool
 	// Additional fields
-	FeatureUnlocks   []string
-	WeightModifiers  []WeightModifier
-	Potential        *Condition
-	AIUpdateType string
-	Gateway      string
-	IsReverse    bool
+	PrerequisiteGroups [][]string // Keys of each prerequisites block; any one group unlocks the technology
+	FeatureUnlocks     []string
+	WeightModifiers    []WeightModifier // From weight_modifier, changing the chance of being drawn as a research option
+	AIWeightModifiers  []WeightModifier // From ai_weight, changing how likely the AI is to pick it
+	Potential          *Condition
+	AIUpdateType       string
+	Gateway            string
+	IsReverse          bool
+	// Groups the technology belongs to when research options are drawn, and
+	// the factor applied to its weight once a technology of a group is among
+	// the options, by group
+	WeightGroups           []string
+	ModWeightIfGroupPicked map[string]float64
+	// Extra as exported in extraFlags: repeated scalar keys are lists of
+	// their values and set_technology_flag holds the flags set anywhere in
+	// the block, sorted. Derived from Extra by the parser.
+	ExtraFlags map[string]interface{}
+	// Every key of the block the parser does not model, with its value as
+	// parsed: scalars, lists, nested blocks as map[string]interface{},
+	// Repeated for keys assigned more than once and Comparison for other
+	// operators than =
+	Extra map[string]interface{}
 }
 
-// WeightModifier represents a modifier that affects technology weight
+// Ways a technology is acquired, as returned by Technology.Acquisition
+const (
+	AcquisitionStart    = "start"    // Known from the start of the game
+	AcquisitionResearch = "research" // Drawn as a research option
+	AcquisitionEvent    = "event"    // Granted by events
+	AcquisitionInsight  = "insight"  // Granted through insight, e.g. from studying pre-FTL civilizations
+)
+
+// Acquisition returns how the technology is acquired. Start technologies take
+// precedence over insight, and insight over event technologies.
+func (t *Technology) Acquisition() string {
+	switch {
+	case t.IsStartTech:
+		return AcquisitionStart
+	case t.IsInsight:
+		return AcquisitionInsight
+	case t.IsEvent:
+		return AcquisitionEvent
+	default:
+		return AcquisitionResearch
+	}
+}
+
+// LevelCost is the research cost of a single level of a repeatable technology
+type LevelCost struct {
+	Level int `json:"level"`
+	Cost  int `json:"cost"`
+}
+
+// IsInfinite reports whether a repeatable technology has no level limit
+func (t *Technology) IsInfinite() bool {
+	return t.IsRepeatable && t.Levels < 0
+}
+
+// CostAtLevel returns the research cost of the given level (1-based).
+// The first level costs Cost and each following level adds CostPerLevel.
+func (t *Technology) CostAtLevel(level int) int {
+	if level < 1 {
+		level = 1
+	}
+	return t.Cost + t.CostPerLevel*(level-1)
+}
+
+// CostTable returns the cost of each level of a repeatable technology.
+// Infinite repeatables are cut off at maxLevels; finite ones stop at their
+// own level count or maxLevels, whichever is lower. Non-repeatable
+// technologies return nil.
+func (t *Technology) CostTable(maxLevels int) []LevelCost {
+	if !t.IsRepeatable || maxLevels <= 0 {
+		return nil
+	}
+
+	count := maxLevels
+	if t.Levels > 0 && t.Levels < count {
+		count = t.Levels
+	}
+
+	table := make([]LevelCost, 0, count)
+	for level := 1; level <= count; level++ {
+		table = append(table, LevelCost{Level: level, Cost: t.CostAtLevel(level)})
+	}
+	return table
+}
+
+// Definition identifies where a technology was defined
+type Definition struct {
+	SourceFile string `json:"sourceFile"`
+	Mod        string `json:"mod,omitempty"`
+}
+
+// Source is the original script text of a definition and the lines of its
+// file it spans
+type Source struct {
+	File      string `json:"file"`
+	StartLine int    `json:"startLine"` // 1-based
+	EndLine   int    `json:"endLine"`   // 1-based, inclusive
+	Text      string `json:"text"`
+}
+
+// Override records a technology key that was defined more than once.
+// Definition is the one that was replaced, OverriddenBy is the one that
+// replaced it according to load order.
+type Override struct {
+	Key          string     `json:"key"`
+	Definition   Definition `json:"definition"`
+	OverriddenBy Definition `json:"overriddenBy"`
+}
+
+// WeightModifier represents a modifier that affects technology weight. The
+// weight is multiplied by Factor and increased by Add when all Conditions
+// hold; a modifier without conditions always applies.
 type WeightModifier struct {
-	Factor     float64
+	Factor     float64 // 1 when the block has no factor
 	Add        float64
 	Conditions []Condition
 }
@@ -53,6 +165,26 @@ type Condition struct {
 	Raw      map[string]interface{} // Raw data for complex structures
 }
 
+// Comparison is the value of a condition compared with an operator other
+// than =, such as num_owned_planets > 5, in raw condition blocks
+type Comparison struct {
+	Operator string      `json:"operator"` // >, >=, <, <= or !=
+	Value    interface{} `json:"value"`
+}
+
+// Repeated holds the values of a key assigned more than once in a raw block,
+// such as several modifier blocks, in file order
+type Repeated []interface{}
+
+// Values returns the values of a raw block entry: each value of a repeated
+// key, or else the value itself
+func Values(value interface{}) []interface{} {
+	if repeated, ok := value.(Repeated); ok {
+		return repeated
+	}
+	return []interface{}{value}
+}
+
 // Modifier represents a game effect or modifier
 type Modifier struct {
 	Type  string
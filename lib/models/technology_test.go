@@ -188,3 +188,81 @@ func TestModifier(t *testing.T) {
 		t.Errorf("Expected Value to be 0.15, got %v", mod.Value)
 	}
 }
+
+func TestCostTable(t *testing.T) {
+	tests := []struct {
+		name      string
+		tech      Technology
+		maxLevels int
+		expected  []int
+	}{
+		{
+			name:      "infinite repeatable capped",
+			tech:      Technology{Cost: 50000, CostPerLevel: 10000, IsRepeatable: true, Levels: -1},
+			maxLevels: 3,
+			expected:  []int{50000, 60000, 70000},
+		},
+		{
+			name:      "finite repeatable below cap",
+			tech:      Technology{Cost: 20000, CostPerLevel: 5000, IsRepeatable: true, Levels: 2},
+			maxLevels: 10,
+			expected:  []int{20000, 25000},
+		},
+		{
+			name:      "non-repeatable",
+			tech:      Technology{Cost: 1000},
+			maxLevels: 10,
+			expected:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := tt.tech.CostTable(tt.maxLevels)
+			if len(table) != len(tt.expected) {
+				t.Fatalf("Expected %d levels, got %d", len(tt.expected), len(table))
+			}
+			for i, entry := range table {
+				if entry.Level != i+1 {
+					t.Errorf("Expected level %d, got %d", i+1, entry.Level)
+				}
+				if entry.Cost != tt.expected[i] {
+					t.Errorf("Expected level %d to cost %d, got %d", entry.Level, tt.expected[i], entry.Cost)
+				}
+			}
+		})
+	}
+}
+
+func TestIsInfinite(t *testing.T) {
+	infinite := &Technology{IsRepeatable: true, Levels: -1}
+	if !infinite.IsInfinite() {
+		t.Error("Expected repeatable with levels -1 to be infinite")
+	}
+
+	finite := &Technology{IsRepeatable: true, Levels: 5}
+	if finite.IsInfinite() {
+		t.Error("Expected repeatable with levels 5 not to be infinite")
+	}
+}
+
+func TestAcquisition(t *testing.T) {
+	tests := []struct {
+		name     string
+		tech     Technology
+		expected string
+	}{
+		{"research", Technology{}, AcquisitionResearch},
+		{"start", Technology{IsStartTech: true, IsInsight: true}, AcquisitionStart},
+		{"insight", Technology{IsInsight: true, IsEvent: true}, AcquisitionInsight},
+		{"event", Technology{IsEvent: true}, AcquisitionEvent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tech.Acquisition(); got != tt.expected {
+				t.Errorf("Expected acquisition %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
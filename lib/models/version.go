@@ -0,0 +1,8 @@
+package models
+
+// GameVersion is the Stellaris version a game directory holds, as written
+// by the launcher
+type GameVersion struct {
+	Version string `json:"version"`        // Version number, e.g. 3.12.4
+	Name    string `json:"name,omitempty"` // Release name, e.g. Andromeda
+}
@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// Locations of exploration definitions, relative to the game or mod
+// directory
+const (
+	AnomaliesDir       = "common/anomalies"
+	SpecialProjectsDir = "common/special_projects"
+)
+
+// eventReferencePattern matches events fired from a script, such as
+// anomaly_event = anomaly.2, id = anomaly.3001 or an outcome 2 = anomaly.56.
+// Event ids have the form namespace.number.
+var eventReferencePattern = regexp.MustCompile(`\b(?:anomaly_event|id|\d+)\s*=\s*"?([A-Za-z_]\w*\.\d+)"?`)
+
+// ParseAnomalies reads the anomalies of the game directory and mods
+func ParseAnomalies(gameDir string, modDirs []string) ([]*models.Anomaly, error) {
+	definitions, err := LoadDefinitions(AnomaliesDir, gameDir, modDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read anomalies: %w", err)
+	}
+
+	anomalies := make([]*models.Anomaly, 0, len(definitions))
+	for _, definition := range definitions {
+		data := definition.Data
+		anomaly := &models.Anomaly{
+			Key:          definition.Key,
+			SpawnChance:  data["spawn_chance"],
+			OnSuccess:    rawBlock(data["on_success"]),
+			OnFail:       rawBlock(data["on_fail"]),
+			LinkedEvents: linkedEvents(definition),
+			SourceFile:   definition.SourceFile,
+			Mod:          definition.Mod,
+		}
+		anomaly.DescriptionKey, _ = data["desc"].(string)
+		anomaly.Picture, _ = data["picture"].(string)
+		anomaly.Level, _ = data["level"].(int)
+		anomaly.NullSpawnChance, _ = number(data["null_spawn_chance"])
+		anomaly.MaxOnce, _ = data["max_once"].(bool)
+		anomalies = append(anomalies, anomaly)
+	}
+	return anomalies, nil
+}
+
+// ParseSpecialProjects reads the special projects of the game directory and
+// mods. Projects are identified by their key.
+func ParseSpecialProjects(gameDir string, modDirs []string) ([]*models.SpecialProject, error) {
+	definitions, err := LoadKeyedDefinitions(SpecialProjectsDir, "key", gameDir, modDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read special projects: %w", err)
+	}
+
+	projects := make([]*models.SpecialProject, 0, len(definitions))
+	for _, definition := range definitions {
+		data := definition.Data
+		project := &models.SpecialProject{
+			Key:          definition.Key,
+			Requirements: rawBlock(data["requirements"]),
+			OnSuccess:    rawBlock(data["on_success"]),
+			OnFail:       rawBlock(data["on_fail"]),
+			LinkedEvents: linkedEvents(definition),
+			SourceFile:   definition.SourceFile,
+			Mod:          definition.Mod,
+		}
+		project.DescriptionKey, _ = data["desc"].(string)
+		project.Picture, _ = data["picture"].(string)
+		project.Cost, _ = data["cost"].(int)
+		project.DaysToResearch, _ = data["days_to_research"].(int)
+		project.Department, _ = data["tech_department"].(string)
+		project.EventScope, _ = data["event_scope"].(string)
+		projects = append(projects, project)
+	}
+	return projects, nil
+}
+
+// linkedEvents returns the events referenced from a definition, without
+// duplicates, in order of appearance
+func linkedEvents(definition Definition) []string {
+	events := []string{}
+	seen := make(map[string]bool)
+	for _, match := range eventReferencePattern.FindAllStringSubmatch(definition.content, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			events = append(events, match[1])
+		}
+	}
+	return events
+}
@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseAnomalies(t *testing.T) {
+	gameDir := t.TempDir()
+	writeFiles(t, map[string]string{
+		filepath.Join(gameDir, AnomaliesDir, "00_anomalies.txt"): `ORB_CAT = {
+	desc = ORB_CAT_DESC
+	picture = GFX_evt_alien_planet
+	level = 2
+	null_spawn_chance = 0.5
+	max_once = yes
+	spawn_chance = {
+		modifier = {
+			add = 5
+			is_planet_class = pc_gas_giant
+		}
+	}
+	on_success = {
+		1 = {
+			anomaly_event = anomaly.55
+			max_once = yes
+		}
+		2 = anomaly.56
+		3 = {
+			anomaly_event = anomaly.55
+		}
+	}
+}
+`,
+	})
+
+	anomalies, err := ParseAnomalies(gameDir, nil)
+	if err != nil {
+		t.Fatalf("Failed to parse anomalies: %v", err)
+	}
+	if len(anomalies) != 1 {
+		t.Fatalf("Expected 1 anomaly, got %d", len(anomalies))
+	}
+
+	orb := anomalies[0]
+	if orb.DescriptionKey != "ORB_CAT_DESC" || orb.Level != 2 || orb.NullSpawnChance != 0.5 || !orb.MaxOnce {
+		t.Errorf("Expected description key, level, null spawn chance and max once, got %+v", orb)
+	}
+	if _, ok := orb.SpawnChance.(map[string]interface{}); !ok {
+		t.Errorf("Expected the raw spawn chance block, got %v", orb.SpawnChance)
+	}
+	if len(orb.LinkedEvents) != 2 || orb.LinkedEvents[0] != "anomaly.55" || orb.LinkedEvents[1] != "anomaly.56" {
+		t.Errorf("Expected each linked event once, got %v", orb.LinkedEvents)
+	}
+	if len(orb.OnSuccess) != 3 {
+		t.Errorf("Expected 3 outcomes, got %v", orb.OnSuccess)
+	}
+}
+
+func TestParseSpecialProjects(t *testing.T) {
+	gameDir := t.TempDir()
+	writeFiles(t, map[string]string{
+		filepath.Join(gameDir, SpecialProjectsDir, "00_special_projects.txt"): `special_project = {
+	key = "ABANDONED_TERRAFORMING_PROJECT"
+	cost = 1200
+	tech_department = engineering_technology
+	picture = GFX_evt_terraforming
+	event_scope = planet_event
+	requirements = {
+		shipclass_science_ship = 1
+		leader = scientist
+	}
+	on_success = {
+		planet_event = {
+			id = anomaly.3001
+		}
+	}
+}
+
+special_project = {
+	key = "SURVEY_DERELICT"
+	days_to_research = 90
+	event_scope = ship_event
+}
+`,
+	})
+
+	projects, err := ParseSpecialProjects(gameDir, nil)
+	if err != nil {
+		t.Fatalf("Failed to parse special projects: %v", err)
+	}
+	if len(projects) != 2 {
+		t.Fatalf("Expected 2 special projects, got %d", len(projects))
+	}
+
+	terraforming, derelict := projects[0], projects[1]
+	if terraforming.Key != "ABANDONED_TERRAFORMING_PROJECT" || terraforming.Cost != 1200 || terraforming.Department != "engineering_technology" {
+		t.Errorf("Expected cost and department, got %+v", terraforming)
+	}
+	if terraforming.Requirements["leader"] != "scientist" {
+		t.Errorf("Expected requirements, got %v", terraforming.Requirements)
+	}
+	if len(terraforming.LinkedEvents) != 1 || terraforming.LinkedEvents[0] != "anomaly.3001" {
+		t.Errorf("Expected the completion event, got %v", terraforming.LinkedEvents)
+	}
+	if derelict.DaysToResearch != 90 || derelict.EventScope != "ship_event" {
+		t.Errorf("Expected a timed project, got %+v", derelict)
+	}
+}
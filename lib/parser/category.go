@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"errors"
+	"io/fs"
+	"maps"
+	"strings"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// Subdirectories of common/technology holding definitions other than
+// technologies
+const (
+	CategoryDir = "category" // Research categories, see GetCategories
+	TierDir     = "tier"     // Tier unlock rules
+)
+
+// parseCategoryFS reads the category definitions below dir of fsys. A
+// missing directory is not an error; later definitions replace earlier ones.
+func (p *TechParser) parseCategoryFS(fsys fs.FS, dir string) error {
+	if _, err := fs.Stat(fsys, dir); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return fs.WalkDir(fsys, dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".txt") {
+			return nil
+		}
+
+		file, err := fsys.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		content, err := readFileContent(file)
+		if err != nil {
+			return err
+		}
+
+		for key, block := range p.topLevelBlocks(content) {
+			category := &models.Category{Key: key, Mod: p.mod}
+			if icon, ok := p.parseStatements(block.body)["icon"].(string); ok {
+				category.Icon = icon
+			}
+			p.mu.Lock()
+			p.categories[key] = category
+			p.mu.Unlock()
+		}
+		return nil
+	})
+}
+
+// GetCategories returns a copy of the map of parsed research categories
+func (p *TechParser) GetCategories() map[string]*models.Category {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return maps.Clone(p.categories)
+}
@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseCategories(t *testing.T) {
+	baseDir := t.TempDir()
+	modDir := t.TempDir()
+	files := map[string]string{
+		filepath.Join(baseDir, "00_phys_tech.txt"):             "tech_lasers_1 = {\n\tarea = physics\n\tcategory = { particles }\n}\n",
+		filepath.Join(baseDir, CategoryDir, "00_category.txt"): "particles = {\n\ticon = \"gfx/interface/icons/research/particles.dds\"\n}\ncomputing = {\n\ticon = GFX_research_computing\n}\n",
+		filepath.Join(baseDir, TierDir, "00_tiers.txt"):        "1 = {\n\tpreviously_unlocked = 0\n}\n",
+		filepath.Join(modDir, CategoryDir, "mod_category.txt"): "computing = {\n\ticon = GFX_mod_computing\n}\nmod_psionics = { }\n",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := NewTechParser()
+	if err := p.ParseDirectory(baseDir); err != nil {
+		t.Fatalf("Failed to parse base: %v", err)
+	}
+	if err := p.ParseModDirectory(modDir, "my_mod"); err != nil {
+		t.Fatalf("Failed to parse mod: %v", err)
+	}
+
+	if techs := p.GetTechnologies(); len(techs) != 1 {
+		t.Errorf("Expected category and tier definitions not to be parsed as technologies, got %d technologies", len(techs))
+	}
+
+	categories := p.GetCategories()
+	if len(categories) != 3 {
+		t.Fatalf("Expected 3 categories, got %v", categories)
+	}
+	if icon := categories["particles"].Icon; icon != "gfx/interface/icons/research/particles.dds" {
+		t.Errorf("Expected the particles texture path, got %q", icon)
+	}
+	if computing := categories["computing"]; computing.Icon != "GFX_mod_computing" || computing.Mod != "my_mod" {
+		t.Errorf("Expected the mod to replace computing, got %+v", computing)
+	}
+	if icon := categories["mod_psionics"].Icon; icon != "" {
+		t.Errorf("Expected no icon, got %q", icon)
+	}
+}
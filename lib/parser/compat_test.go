@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+// versionExpectation describes what the corpus of one vanilla game version
+// under testdata/versions is expected to parse into
+type versionExpectation struct {
+	version      string
+	technologies int
+	repeatable   int
+	insight      int
+	potentials   int
+	// Technologies whose cost comes from a scripted variable
+	costs map[string]int
+}
+
+var supportedVersions = []versionExpectation{
+	{
+		version:      "3.4",
+		technologies: 6,
+		costs:        map[string]int{"tech_lasers_1": 360, "tech_lasers_2": 1280, "tech_xeno_diplomacy": 360},
+	},
+	{
+		version:      "3.8",
+		technologies: 8,
+		insight:      2,
+		costs:        map[string]int{"tech_lasers_1": 360, "tech_cloaking_1": 1280},
+	},
+	{
+		version:      "3.12",
+		technologies: 9,
+		repeatable:   1,
+		insight:      2,
+		potentials:   2,
+		costs:        map[string]int{"tech_lasers_1": 400, "tech_repeatable_lasers_damage": 50000},
+	},
+}
+
+func TestParseVanillaVersions(t *testing.T) {
+	for _, expected := range supportedVersions {
+		t.Run(expected.version, func(t *testing.T) {
+			dir, err := filepath.Abs(filepath.Join("../../testdata/versions", expected.version, "common", "technology"))
+			if err != nil {
+				t.Fatalf("Failed to get testdata path: %v", err)
+			}
+
+			parser := NewTechParser()
+			parser.SetStrict(true)
+			if err := parser.ParseDirectory(dir); err != nil {
+				t.Fatalf("Failed to parse %s: %v", expected.version, err)
+			}
+
+			technologies := parser.GetTechnologies()
+			if len(technologies) != expected.technologies {
+				t.Errorf("Expected %d technologies, got %d", expected.technologies, len(technologies))
+			}
+
+			var repeatable, insight, potentials int
+			for _, tech := range technologies {
+				if tech.Area == "" {
+					t.Errorf("Expected %s to have an area", tech.Key)
+				}
+				if tech.IsRepeatable {
+					repeatable++
+				}
+				if tech.IsInsight {
+					insight++
+				}
+				if tech.Potential != nil {
+					potentials++
+				}
+			}
+			if repeatable != expected.repeatable {
+				t.Errorf("Expected %d repeatable technologies, got %d", expected.repeatable, repeatable)
+			}
+			if insight != expected.insight {
+				t.Errorf("Expected %d insight technologies, got %d", expected.insight, insight)
+			}
+			if potentials != expected.potentials {
+				t.Errorf("Expected %d technologies with potential, got %d", expected.potentials, potentials)
+			}
+
+			for key, cost := range expected.costs {
+				tech, exists := technologies[key]
+				if !exists {
+					t.Errorf("Expected to find %s", key)
+					continue
+				}
+				if tech.Cost != cost {
+					t.Errorf("Expected %s to cost %d, got %d", key, cost, tech.Cost)
+				}
+			}
+
+			if missing := tree.NewTechTree(technologies).GetMissingPrerequisites(); len(missing) != 0 {
+				t.Errorf("Expected every prerequisite to resolve, got %v", missing)
+			}
+		})
+	}
+}
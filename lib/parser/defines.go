@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/danaketh/StellarisDataParser/lib/gamefs"
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// DefinesDir is the location of the defines, relative to the game or mod
+// directory
+const DefinesDir = "common/defines"
+
+// Defines used by the research estimates
+const (
+	DefineTechCostMult         = "NGameplay.TECH_COST_MULT"        // Multiplier of technology costs
+	DefineResearchAlternatives = "NGameplay.RESEARCH_ALTERNATIVES" // Research options offered per area
+)
+
+// Defines holds the game defines by Namespace.KEY
+type Defines map[string]models.Define
+
+// ParseDefines reads the defines of the game directory followed by each mod
+// directory. Mods change single defines, so a define replaces an earlier one
+// with the same key while the rest of its namespace is kept.
+func ParseDefines(gameDir string, modDirs []string) (Defines, error) {
+	defines := make(Defines)
+
+	sources := append([]string{gameDir}, modDirs...)
+	for i, source := range sources {
+		mod := ""
+		if i > 0 {
+			mod = gamefs.Name(source)
+		}
+		namespaces, err := readSourceDefinitions(source, DefinesDir, mod, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read defines: %w", err)
+		}
+		for _, namespace := range namespaces {
+			for name, value := range namespace.Data {
+				key := namespace.Key + "." + name
+				defines[key] = models.Define{
+					Key:        key,
+					Namespace:  namespace.Key,
+					Name:       name,
+					Type:       defineType(value),
+					Value:      value,
+					SourceFile: namespace.SourceFile,
+					Mod:        mod,
+				}
+			}
+		}
+	}
+	return defines, nil
+}
+
+// defineType returns the type of a parsed define value
+func defineType(value interface{}) string {
+	switch value.(type) {
+	case int, float64:
+		return models.DefineNumber
+	case bool:
+		return models.DefineBool
+	case []interface{}, models.Repeated:
+		return models.DefineList
+	case map[string]interface{}:
+		return models.DefineBlock
+	}
+	return models.DefineString
+}
+
+// Number returns the value of a numeric define
+func (d Defines) Number(key string) (float64, bool) {
+	return number(d[key].Value)
+}
+
+// List returns the defines sorted by key
+func (d Defines) List() []models.Define {
+	list := make([]models.Define, 0, len(d))
+	for _, define := range d {
+		list = append(list, define)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list
+}
@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+func TestParseDefines(t *testing.T) {
+	gameDir := t.TempDir()
+	modDir := filepath.Join(t.TempDir(), "my_mod")
+	writeFiles(t, map[string]string{
+		filepath.Join(gameDir, DefinesDir, "00_defines.txt"): `NGameplay = {
+	TECH_COST_MULT = 0.5			# Comment
+	RESEARCH_ALTERNATIVES = 3
+	START_YEAR = "2200.01.01"
+	FTL_JUMP_DISTANCES = { 1 2 3 }
+}
+NAI = {
+	ALLOW_WAR = yes
+}
+`,
+		filepath.Join(modDir, DefinesDir, "mod_defines.txt"): "NGameplay = {\n\tRESEARCH_ALTERNATIVES = 4\n}\n",
+	})
+
+	defines, err := ParseDefines(gameDir, []string{modDir})
+	if err != nil {
+		t.Fatalf("Failed to parse defines: %v", err)
+	}
+	if len(defines) != 5 {
+		t.Fatalf("Expected 5 defines, got %v", defines)
+	}
+
+	if cost, ok := defines.Number(DefineTechCostMult); !ok || cost != 0.5 {
+		t.Errorf("Expected a tech cost multiplier of 0.5, got %v", cost)
+	}
+	alternatives := defines[DefineResearchAlternatives]
+	if alternatives.Value != 4 || alternatives.Mod != "my_mod" || alternatives.Namespace != "NGameplay" || alternatives.Name != "RESEARCH_ALTERNATIVES" {
+		t.Errorf("Expected the mod to replace RESEARCH_ALTERNATIVES only, got %+v", alternatives)
+	}
+
+	types := map[string]string{
+		"NGameplay.START_YEAR":         models.DefineString,
+		"NGameplay.FTL_JUMP_DISTANCES": models.DefineList,
+		"NAI.ALLOW_WAR":                models.DefineBool,
+		DefineTechCostMult:             models.DefineNumber,
+	}
+	for key, expected := range types {
+		if defines[key].Type != expected {
+			t.Errorf("Expected %s to be a %s, got %+v", key, expected, defines[key])
+		}
+	}
+
+	var keys []string
+	for _, define := range defines.List() {
+		keys = append(keys, define.Key)
+	}
+	expected := []string{"NAI.ALLOW_WAR", "NGameplay.FTL_JUMP_DISTANCES", "NGameplay.RESEARCH_ALTERNATIVES", "NGameplay.START_YEAR", "NGameplay.TECH_COST_MULT"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected defines sorted by key %v, got %v", expected, keys)
+	}
+}
+
+func TestParseDefinesWithoutDirectory(t *testing.T) {
+	defines, err := ParseDefines(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("Failed to parse defines: %v", err)
+	}
+	if _, ok := defines.Number(DefineTechCostMult); ok || len(defines.List()) != 0 {
+		t.Errorf("Expected no defines, got %v", defines)
+	}
+}
@@ -0,0 +1,248 @@
+package parser
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/danaketh/StellarisDataParser/lib/gamefs"
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// Definition is a named top-level block of a game script file other than a
+// technology, such as an edict or a ship size
+type Definition struct {
+	Key        string
+	Block      string // Name of the top-level block, the same as Key unless keyed by a field
+	SourceFile string
+	Mod        string // Mod directory name, empty for the base game
+	Data       map[string]interface{}
+	content    string // Block content with scripted variables resolved, for Blocks
+}
+
+// Blocks returns every block assigned to key directly inside the definition.
+// Data keeps only the last block of a repeated key such as option; Blocks
+// returns all of them in file order.
+func (d Definition) Blocks(key string) []map[string]interface{} {
+	var p TechParser
+	var blocks []map[string]interface{}
+	for _, block := range namedBlocks(d.content) {
+		if block.name == key {
+			blocks = append(blocks, p.parseStatements(block.body))
+		}
+	}
+	return blocks
+}
+
+// namedBlock is a block assigned to a key, with its content without the
+// outer braces and its statements
+type namedBlock struct {
+	name    string
+	content string
+	body    []statement
+}
+
+// namedBlocks returns the blocks assigned directly in content, in order and
+// including repeated keys. Keys may be quoted.
+func namedBlocks(content string) []namedBlock {
+	return blocksIn(statements(content))
+}
+
+// blocksIn returns the blocks assigned directly in the statements of a
+// block, see namedBlocks
+func blocksIn(body []statement) []namedBlock {
+	var blocks []namedBlock
+	for _, st := range body {
+		if st.isBlock && st.key != "" && st.operator == "=" {
+			blocks = append(blocks, namedBlock{name: st.key, content: st.block, body: st.children})
+		}
+	}
+	return blocks
+}
+
+// LoadDefinitions reads the definitions in a directory such as
+// common/edicts of the game directory followed by each mod, in load order.
+// Mods are directories, .zip archives or .mod descriptors, see gamefs.Open.
+// A definition replaces an earlier one with the same key. Missing
+// directories are skipped. The result is sorted by key.
+func LoadDefinitions(dir, gameDir string, modDirs []string) ([]Definition, error) {
+	return LoadKeyedDefinitions(dir, "", gameDir, modDirs)
+}
+
+// LoadKeyedDefinitions is LoadDefinitions for files that repeat the same
+// block name and identify each block by a field, such as the key of a
+// ship_section_template. Blocks without the field are skipped. An empty
+// field uses the block names as keys.
+func LoadKeyedDefinitions(dir, field, gameDir string, modDirs []string) ([]Definition, error) {
+	byKey := make(map[string]Definition)
+
+	sources := append([]string{gameDir}, modDirs...)
+	for i, source := range sources {
+		mod := ""
+		if i > 0 {
+			mod = gamefs.Name(source)
+		}
+		definitions, err := readSourceDefinitions(source, dir, mod, field)
+		if err != nil {
+			return nil, err
+		}
+		for _, definition := range definitions {
+			byKey[definition.Key] = definition
+		}
+	}
+
+	definitions := make([]Definition, 0, len(byKey))
+	for _, definition := range byKey {
+		definitions = append(definitions, definition)
+	}
+	sort.Slice(definitions, func(i, j int) bool { return definitions[i].Key < definitions[j].Key })
+	return definitions, nil
+}
+
+// readSourceDefinitions is readDefinitions for the directory dir of the game
+// directory or a mod opened with gamefs.Open. A missing source is skipped.
+func readSourceDefinitions(source, dir, mod, field string) ([]Definition, error) {
+	fsys, err := gamefs.Open(source)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer fsys.Close()
+	return readDefinitions(fsys, dir, mod, field)
+}
+
+// readDefinitions reads the top-level blocks of the .txt files below dir of
+// fsys, in lexical path order, keyed by field or by block name. Scripted
+// variables defined in the same file are resolved.
+func readDefinitions(fsys fs.FS, dir, mod, field string) ([]Definition, error) {
+	if _, err := fs.Stat(fsys, dir); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	var p TechParser
+	var definitions []Definition
+	err := fs.WalkDir(fsys, dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".txt") {
+			return nil
+		}
+
+		file, err := fsys.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		content, err := readFileContent(file)
+		if err != nil {
+			return err
+		}
+
+		content = resolveScriptedVariables(content, extractScriptedVariables(content))
+		for _, block := range namedBlocks(content) {
+			data := p.parseStatements(block.body)
+			key := block.name
+			if field != "" {
+				if key, _ = data[field].(string); key == "" {
+					continue
+				}
+			}
+			definitions = append(definitions, Definition{
+				Key:        key,
+				Block:      block.name,
+				SourceFile: entry.Name(),
+				Mod:        mod,
+				Data:       data,
+				content:    block.content,
+			})
+		}
+		return nil
+	})
+	return definitions, err
+}
+
+// simpleFields returns the key = value pairs written directly in a block,
+// on any number of lines, such as difficulty = 1 icon = GFX_x event = a.1.
+// Quotes are removed and nested blocks are skipped.
+func simpleFields(body []statement) map[string]string {
+	fields := make(map[string]string)
+	for _, st := range body {
+		if st.key != "" && st.operator == "=" && !st.isBlock {
+			fields[st.key] = strings.Trim(st.value, "\"")
+		}
+	}
+	return fields
+}
+
+// numberMap converts a block of numeric values, such as a resource cost, to
+// a map. Values that are not numbers, e.g. unresolved scripted variables,
+// are left out.
+func numberMap(value interface{}) map[string]float64 {
+	result := make(map[string]float64)
+	for key, v := range rawBlock(value) {
+		if n, ok := number(lastValue(v)); ok {
+			result[key] = n
+		}
+	}
+	return result
+}
+
+// number converts an int or float64 value to float64
+func number(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// stringList converts a list value such as prerequisites to strings. The
+// lists of a repeated key are joined.
+func stringList(value interface{}) []string {
+	result := []string{}
+	for _, entry := range models.Values(value) {
+		list, ok := entry.([]interface{})
+		if !ok {
+			continue
+		}
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
+
+// rawBlock returns a nested block as is, or an empty block. The blocks of a
+// repeated key are merged, keeping the values of keys they share as
+// models.Repeated.
+func rawBlock(value interface{}) map[string]interface{} {
+	repeated, ok := value.(models.Repeated)
+	if !ok {
+		if block, ok := value.(map[string]interface{}); ok {
+			return block
+		}
+		return map[string]interface{}{}
+	}
+
+	merged := make(map[string]interface{})
+	for _, entry := range repeated {
+		block, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key, v := range block {
+			for _, single := range models.Values(v) {
+				addValue(merged, key, single)
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+func TestLoadDefinitionsZipMod(t *testing.T) {
+	gameDir := t.TempDir()
+	writeFiles(t, map[string]string{
+		filepath.Join(gameDir, EdictsDir, "00_edicts.txt"): "edict_a = {\n\tlength = 10\n}\nedict_b = {\n\tlength = 20\n}\n",
+	})
+
+	// Zipped mod directories keep their top-level directory
+	archivePath := filepath.Join(t.TempDir(), "zipped_mod.zip")
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := zip.NewWriter(file)
+	for name, content := range map[string]string{
+		"zipped_mod/descriptor.mod":              "name=\"Zipped\"\n",
+		"zipped_mod/" + EdictsDir + "/mod_a.txt": "edict_a = {\n\tlength = 99\n}\n",
+	} {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	definitions, err := LoadDefinitions(EdictsDir, gameDir, []string{archivePath, filepath.Join(gameDir, "missing_mod")})
+	if err != nil {
+		t.Fatalf("Failed to load definitions: %v", err)
+	}
+	if len(definitions) != 2 {
+		t.Fatalf("Expected 2 definitions, got %d", len(definitions))
+	}
+
+	a := definitions[0]
+	if a.Key != "edict_a" || a.Data["length"] != 99 || a.Mod != "zipped_mod" || a.SourceFile != "mod_a.txt" {
+		t.Errorf("Expected edict_a from the zipped mod, got %+v", a)
+	}
+	if b := definitions[1]; b.Mod != "" || b.Data["length"] != 20 {
+		t.Errorf("Expected edict_b from the game, got %+v", b)
+	}
+}
+
+func TestRawBlockRepeated(t *testing.T) {
+	merged := rawBlock(models.Repeated{
+		map[string]interface{}{"is_gestalt": false, "has_technology": "tech_a"},
+		map[string]interface{}{"has_technology": "tech_b"},
+	})
+	if merged["is_gestalt"] != false {
+		t.Errorf("Expected the keys of every block, got %v", merged)
+	}
+	values, ok := merged["has_technology"].(models.Repeated)
+	if !ok || len(values) != 2 || values[0] != "tech_a" || values[1] != "tech_b" {
+		t.Errorf("Expected shared keys to keep both values, got %#v", merged["has_technology"])
+	}
+
+	if list := stringList(models.Repeated{[]interface{}{"a"}, []interface{}{"b", "c"}}); len(list) != 3 {
+		t.Errorf("Expected the lists of a repeated key to be joined, got %v", list)
+	}
+}
@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Diagnostic describes a problem found in a source file, with the position
+// where it was detected and the offending line for context
+type Diagnostic struct {
+	File    string
+	Line    int // 1-based line number
+	Column  int // 1-based column number
+	Message string
+	Context string // The trimmed source line the problem was found on
+}
+
+// Error implements the error interface so a diagnostic can be returned as-is
+func (d Diagnostic) Error() string {
+	if d.Context == "" {
+		return fmt.Sprintf("%s:%d:%d: %s", d.File, d.Line, d.Column, d.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s\n    %s", d.File, d.Line, d.Column, d.Message, d.Context)
+}
+
+// ParseError is returned by ParseFile when a file contains one or more
+// problems. It carries every diagnostic found in the file.
+type ParseError struct {
+	File        string
+	Diagnostics []Diagnostic
+}
+
+// Error returns the first diagnostic and a count of any further ones
+func (e *ParseError) Error() string {
+	if len(e.Diagnostics) == 0 {
+		return fmt.Sprintf("%s: parse error", e.File)
+	}
+	msg := e.Diagnostics[0].Error()
+	if len(e.Diagnostics) > 1 {
+		msg += fmt.Sprintf("\n    (and %d more)", len(e.Diagnostics)-1)
+	}
+	return msg
+}
+
+// Unwrap returns an *ErrMalformedBlock for every diagnostic, so callers can
+// find the position of a problem with errors.As
+func (e *ParseError) Unwrap() []error {
+	errs := make([]error, len(e.Diagnostics))
+	for i, d := range e.Diagnostics {
+		errs[i] = &ErrMalformedBlock{File: d.File, Line: d.Line, Message: d.Message}
+	}
+	return errs
+}
+
+// ErrMalformedBlock is a block of a script file whose braces don't balance,
+// found in a *ParseError
+type ErrMalformedBlock struct {
+	File    string
+	Line    int    // 1-based line of the unexpected or unclosed brace
+	Message string // What is wrong with the brace
+}
+
+func (e *ErrMalformedBlock) Error() string {
+	return fmt.Sprintf("%s:%d: malformed block: %s", e.File, e.Line, e.Message)
+}
+
+// checkBraces verifies that braces in the given lines are balanced and
+// returns a diagnostic for every unexpected closing brace and every opening
+// brace that is never closed. Lines are expected to have comments removed
+// but to keep their original indentation so columns stay accurate.
+func checkBraces(file string, lines []string) []Diagnostic {
+	var diagnostics []Diagnostic
+
+	type position struct {
+		line   int
+		column int
+	}
+	var open []position
+
+	for i, line := range lines {
+		inQuotes := false
+		col := -1
+		for b := 0; b < len(line); b++ {
+			// Columns count runes, so continuation bytes are skipped
+			if line[b]&0xC0 == 0x80 {
+				continue
+			}
+			col++
+			switch line[b] {
+			case '"':
+				inQuotes = !inQuotes
+			case '{':
+				if !inQuotes {
+					open = append(open, position{line: i, column: col})
+				}
+			case '}':
+				if inQuotes {
+					continue
+				}
+				if len(open) == 0 {
+					diagnostics = append(diagnostics, Diagnostic{
+						File:    file,
+						Line:    i + 1,
+						Column:  col + 1,
+						Message: "unexpected closing brace",
+						Context: strings.TrimSpace(line),
+					})
+					continue
+				}
+				open = open[:len(open)-1]
+			}
+		}
+	}
+
+	for _, pos := range open {
+		diagnostics = append(diagnostics, Diagnostic{
+			File:    file,
+			Line:    pos.line + 1,
+			Column:  pos.column + 1,
+			Message: "opening brace is never closed",
+			Context: strings.TrimSpace(lines[pos.line]),
+		})
+	}
+
+	return diagnostics
+}
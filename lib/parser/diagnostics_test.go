@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckBraces(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		expected []Diagnostic
+	}{
+		{
+			name:     "balanced",
+			lines:    []string{"tech_a = {", "\tcost = 100", "}"},
+			expected: nil,
+		},
+		{
+			name:  "unexpected closing brace",
+			lines: []string{"tech_a = {", "}", "\t}"},
+			expected: []Diagnostic{
+				{Line: 3, Column: 2, Message: "unexpected closing brace", Context: "}"},
+			},
+		},
+		{
+			name:  "unclosed block",
+			lines: []string{"tech_a = {", "\tpotential = {", "\t\tis_gestalt = yes", "}"},
+			expected: []Diagnostic{
+				{Line: 1, Column: 10, Message: "opening brace is never closed", Context: "tech_a = {"},
+			},
+		},
+		{
+			name:     "braces inside quotes are ignored",
+			lines:    []string{`tech_a = {`, `	gateway = "}"`, `}`},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := checkBraces("test.txt", tt.lines)
+			if len(diagnostics) != len(tt.expected) {
+				t.Fatalf("Expected %d diagnostics, got %d: %v", len(tt.expected), len(diagnostics), diagnostics)
+			}
+			for i, d := range diagnostics {
+				want := tt.expected[i]
+				if d.File != "test.txt" || d.Line != want.Line || d.Column != want.Column ||
+					d.Message != want.Message || d.Context != want.Context {
+					t.Errorf("Expected %+v, got %+v", want, d)
+				}
+			}
+		})
+	}
+}
+
+func TestParseFileMalformed(t *testing.T) {
+	parser := NewTechParser()
+
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "broken.txt")
+	content := `tech_good = {
+	cost = 100
+	area = physics
+}
+
+tech_broken = {
+	cost = 200
+	potential = {
+		is_gestalt = yes
+}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	err := parser.ParseFile(path)
+	if err == nil {
+		t.Fatal("Expected error for malformed file")
+	}
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected *ParseError, got %T", err)
+	}
+	if len(parseErr.Diagnostics) != 1 {
+		t.Fatalf("Expected 1 diagnostic, got %d", len(parseErr.Diagnostics))
+	}
+
+	d := parseErr.Diagnostics[0]
+	if d.File != "broken.txt" || d.Line != 6 || d.Column != 15 {
+		t.Errorf("Unexpected diagnostic position: %s:%d:%d", d.File, d.Line, d.Column)
+	}
+	if !strings.Contains(err.Error(), "broken.txt:6:15") {
+		t.Errorf("Expected error message to contain position, got %q", err.Error())
+	}
+
+	if len(parser.GetDiagnostics()) != 1 {
+		t.Errorf("Expected diagnostics to be accumulated on the parser, got %d", len(parser.GetDiagnostics()))
+	}
+
+	if _, exists := parser.GetTechnology("tech_good"); !exists {
+		t.Error("Expected well-formed technology to still be parsed")
+	}
+}
+
+func TestStrictMode(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := "tech_good = {\n\tcost = 100\n}\ntech_broken = {\n\tcost = 200\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "broken.txt"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	lenient := NewTechParser()
+	if err := lenient.ParseDirectory(tmpDir); err != nil {
+		t.Errorf("Expected lenient mode to continue, got %v", err)
+	}
+	if _, exists := lenient.GetTechnology("tech_good"); !exists {
+		t.Error("Expected lenient mode to keep well-formed technologies")
+	}
+
+	strict := NewTechParser()
+	strict.SetStrict(true)
+	err := strict.ParseDirectory(tmpDir)
+	if err == nil {
+		t.Fatal("Expected strict mode to fail on malformed file")
+	}
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Errorf("Expected error to wrap *ParseError, got %T", err)
+	}
+	var malformed *ErrMalformedBlock
+	if !errors.As(err, &malformed) || malformed.File != "broken.txt" || malformed.Line != 4 {
+		t.Errorf("Expected the error to wrap *ErrMalformedBlock at broken.txt:4, got %v", malformed)
+	}
+	if len(strict.GetTechnologies()) != 0 {
+		t.Errorf("Expected strict mode to discard technologies, got %d", len(strict.GetTechnologies()))
+	}
+}
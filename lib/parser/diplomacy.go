@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// Locations of diplomacy definitions, relative to the game or mod directory
+const (
+	DiplomaticActionsDir = "common/diplomatic_actions"
+	AgreementPresetsDir  = "common/agreement_presets"
+	SubjectTermsDir      = "common/agreement_term_values"
+)
+
+// ParseDiplomaticActions reads the diplomatic actions of the game directory
+// and mods
+func ParseDiplomaticActions(gameDir string, modDirs []string) ([]*models.DiplomaticAction, error) {
+	definitions, err := LoadDefinitions(DiplomaticActionsDir, gameDir, modDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read diplomatic actions: %w", err)
+	}
+
+	actions := make([]*models.DiplomaticAction, 0, len(definitions))
+	for _, definition := range definitions {
+		data := definition.Data
+		action := &models.DiplomaticAction{
+			Key:          definition.Key,
+			Potential:    rawBlock(data["potential"]),
+			Possible:     rawBlock(data["possible"]),
+			Proposable:   rawBlock(data["proposable"]),
+			LinkedEvents: linkedEvents(definition),
+			SourceFile:   definition.SourceFile,
+			Mod:          definition.Mod,
+		}
+		action.Icon, _ = data["icon"].(string)
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// ParseAgreementPresets reads the subject agreement presets of the game
+// directory and mods
+func ParseAgreementPresets(gameDir string, modDirs []string) ([]*models.AgreementPreset, error) {
+	definitions, err := LoadDefinitions(AgreementPresetsDir, gameDir, modDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agreement presets: %w", err)
+	}
+
+	presets := make([]*models.AgreementPreset, 0, len(definitions))
+	for _, definition := range definitions {
+		data := definition.Data
+		preset := &models.AgreementPreset{
+			Key:        definition.Key,
+			Potential:  rawBlock(data["potential"]),
+			Terms:      rawBlock(data["terms"]),
+			SourceFile: definition.SourceFile,
+			Mod:        definition.Mod,
+		}
+		preset.Icon, _ = data["icon"].(string)
+		presets = append(presets, preset)
+	}
+	return presets, nil
+}
+
+// ParseSubjectTerms reads the subject term values of the game directory and
+// mods
+func ParseSubjectTerms(gameDir string, modDirs []string) ([]*models.SubjectTerm, error) {
+	definitions, err := LoadDefinitions(SubjectTermsDir, gameDir, modDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subject terms: %w", err)
+	}
+
+	terms := make([]*models.SubjectTerm, 0, len(definitions))
+	for _, definition := range definitions {
+		data := definition.Data
+		term := &models.SubjectTerm{
+			Key:        definition.Key,
+			Value:      data["value"],
+			Potential:  rawBlock(data["potential"]),
+			Modifiers:  rawBlock(data["modifier"]),
+			SourceFile: definition.SourceFile,
+			Mod:        definition.Mod,
+		}
+		term.Term, _ = data["term"].(string)
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseDiplomaticActions(t *testing.T) {
+	gameDir := t.TempDir()
+	writeFiles(t, map[string]string{
+		filepath.Join(gameDir, DiplomaticActionsDir, "00_actions.txt"): `action_offer_vassalization = {
+	icon = GFX_diplomacy_offer_vassalization
+	potential = {
+		is_subject = no
+	}
+	possible = {
+		is_at_war = no
+	}
+	proposable = {
+		is_ai = yes
+	}
+	on_accept = {
+		country_event = { id = diplomacy.5 }
+	}
+}
+`,
+	})
+
+	actions, err := ParseDiplomaticActions(gameDir, nil)
+	if err != nil {
+		t.Fatalf("Failed to parse diplomatic actions: %v", err)
+	}
+	if len(actions) != 1 {
+		t.Fatalf("Expected 1 action, got %d", len(actions))
+	}
+	action := actions[0]
+	if action.Key != "action_offer_vassalization" || action.Icon != "GFX_diplomacy_offer_vassalization" {
+		t.Errorf("Unexpected action %+v", action)
+	}
+	if action.Potential["is_subject"] != false || action.Possible["is_at_war"] != false || action.Proposable["is_ai"] != true {
+		t.Errorf("Unexpected conditions %v, %v and %v", action.Potential, action.Possible, action.Proposable)
+	}
+	if !reflect.DeepEqual(action.LinkedEvents, []string{"diplomacy.5"}) {
+		t.Errorf("Expected the linked event diplomacy.5, got %v", action.LinkedEvents)
+	}
+}
+
+func TestParseAgreementPresetsAndSubjectTerms(t *testing.T) {
+	gameDir := t.TempDir()
+	modDir := filepath.Join(t.TempDir(), "my_mod")
+	writeFiles(t, map[string]string{
+		filepath.Join(gameDir, AgreementPresetsDir, "00_presets.txt"): `preset_vassal = {
+	icon = GFX_preset_vassal
+	terms = {
+		subject_can_do_diplomacy = no
+		subject_loyalty_effects = loyalty_vassal
+	}
+}
+`,
+		filepath.Join(gameDir, SubjectTermsDir, "00_terms.txt"): `subject_can_do_diplomacy_no = {
+	term = subject_can_do_diplomacy
+	value = no
+	modifier = {
+		subject_integration_influence_cost_mult = -0.25
+	}
+}
+`,
+		filepath.Join(modDir, AgreementPresetsDir, "mod_presets.txt"): "preset_vassal = {\n\tpotential = {\n\t\tis_gestalt = no\n\t}\n}\n",
+	})
+
+	presets, err := ParseAgreementPresets(gameDir, []string{modDir})
+	if err != nil {
+		t.Fatalf("Failed to parse agreement presets: %v", err)
+	}
+	if len(presets) != 1 {
+		t.Fatalf("Expected 1 preset, got %d", len(presets))
+	}
+	if preset := presets[0]; preset.Mod != "my_mod" || preset.Potential["is_gestalt"] != false || len(preset.Terms) != 0 {
+		t.Errorf("Expected the mod to replace preset_vassal, got %+v", preset)
+	}
+
+	presets, err = ParseAgreementPresets(gameDir, nil)
+	if err != nil {
+		t.Fatalf("Failed to parse agreement presets: %v", err)
+	}
+	if terms := presets[0].Terms; terms["subject_can_do_diplomacy"] != false || terms["subject_loyalty_effects"] != "loyalty_vassal" {
+		t.Errorf("Unexpected terms %v", terms)
+	}
+
+	terms, err := ParseSubjectTerms(gameDir, []string{modDir})
+	if err != nil {
+		t.Fatalf("Failed to parse subject terms: %v", err)
+	}
+	if len(terms) != 1 {
+		t.Fatalf("Expected 1 subject term, got %d", len(terms))
+	}
+	term := terms[0]
+	if term.Term != "subject_can_do_diplomacy" || term.Value != false || term.Modifiers["subject_integration_influence_cost_mult"] != -0.25 {
+		t.Errorf("Unexpected subject term %+v", term)
+	}
+}
@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// Locations of empire management definitions, relative to the game or mod
+// directory
+const (
+	EdictsDir   = "common/edicts"
+	PoliciesDir = "common/policies"
+)
+
+// ParseEdicts reads the edicts of the game directory and mods
+func ParseEdicts(gameDir string, modDirs []string) ([]*models.Edict, error) {
+	definitions, err := LoadDefinitions(EdictsDir, gameDir, modDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read edicts: %w", err)
+	}
+
+	edicts := make([]*models.Edict, 0, len(definitions))
+	for _, definition := range definitions {
+		data := definition.Data
+		cost, upkeep := resourceCosts(data)
+		edict := &models.Edict{
+			Key:           definition.Key,
+			Cost:          cost,
+			Upkeep:        upkeep,
+			Modifiers:     rawBlock(data["modifier"]),
+			Prerequisites: stringList(data["prerequisites"]),
+			Potential:     rawBlock(data["potential"]),
+			Allow:         rawBlock(data["allow"]),
+			SourceFile:    definition.SourceFile,
+			Mod:           definition.Mod,
+		}
+		if icon, ok := data["icon"].(string); ok {
+			edict.Icon = icon
+		}
+		if length, ok := data["length"].(int); ok && length > 0 {
+			edict.Length = length
+		}
+		edicts = append(edicts, edict)
+	}
+	return edicts, nil
+}
+
+// ParsePolicies reads the policies of the game directory and mods
+func ParsePolicies(gameDir string, modDirs []string) ([]*models.Policy, error) {
+	definitions, err := LoadDefinitions(PoliciesDir, gameDir, modDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policies: %w", err)
+	}
+
+	policies := make([]*models.Policy, 0, len(definitions))
+	for _, definition := range definitions {
+		policy := &models.Policy{
+			Key:        definition.Key,
+			Options:    []models.PolicyOption{},
+			Potential:  rawBlock(definition.Data["potential"]),
+			Allow:      rawBlock(definition.Data["allow"]),
+			SourceFile: definition.SourceFile,
+			Mod:        definition.Mod,
+		}
+		for _, data := range definition.Blocks("option") {
+			name, _ := data["name"].(string)
+			cost, _ := resourceCosts(data)
+			policy.Options = append(policy.Options, models.PolicyOption{
+				Key:           name,
+				Cost:          cost,
+				Modifiers:     rawBlock(data["modifier"]),
+				Prerequisites: stringList(data["prerequisites"]),
+				Potential:     rawBlock(data["potential"]),
+				Valid:         rawBlock(data["valid"]),
+			})
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// resourceCosts returns the one-time cost and the upkeep of a definition,
+// written either in a resources block or directly in the definition
+func resourceCosts(data map[string]interface{}) (cost, upkeep map[string]float64) {
+	if resources, ok := data["resources"].(map[string]interface{}); ok {
+		data = resources
+	}
+	return numberMap(data["cost"]), numberMap(data["upkeep"])
+}
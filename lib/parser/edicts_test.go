@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestParseEdicts(t *testing.T) {
+	gameDir := t.TempDir()
+	modDir := filepath.Join(t.TempDir(), "my_mod")
+	writeFiles(t, map[string]string{
+		filepath.Join(gameDir, EdictsDir, "00_edicts.txt"): `@edict_cost = 50
+
+research_subsidies = {
+	length = 0
+	icon = "GFX_edict_type_policy"
+	resources = {
+		category = edicts
+		cost = { influence = @edict_cost }
+		upkeep = { energy = 2.5 }
+	}
+	modifier = {
+		all_technology_research_speed = 0.1
+	}
+	potential = {
+		is_gestalt = no
+	}
+	prerequisites = { "tech_planetary_unification" }
+}
+
+capacity_subsidies = {
+	length = 3600
+	cost = { influence = 30 }
+}
+`,
+		filepath.Join(modDir, EdictsDir, "mod_edicts.txt"): "capacity_subsidies = {\n\tlength = 1800\n}\n",
+	})
+
+	edicts, err := ParseEdicts(gameDir, []string{modDir})
+	if err != nil {
+		t.Fatalf("Failed to parse edicts: %v", err)
+	}
+	if len(edicts) != 2 {
+		t.Fatalf("Expected 2 edicts, got %d", len(edicts))
+	}
+
+	capacity, research := edicts[0], edicts[1]
+	if capacity.Key != "capacity_subsidies" || capacity.Length != 1800 || capacity.Mod != "my_mod" {
+		t.Errorf("Expected the mod to replace capacity_subsidies, got %+v", capacity)
+	}
+	if research.Cost["influence"] != 50 || research.Upkeep["energy"] != 2.5 {
+		t.Errorf("Expected cost and upkeep from the resources block, got %v and %v", research.Cost, research.Upkeep)
+	}
+	if research.Modifiers["all_technology_research_speed"] != 0.1 {
+		t.Errorf("Expected modifiers, got %v", research.Modifiers)
+	}
+	if research.Potential["is_gestalt"] != false {
+		t.Errorf("Expected potential conditions, got %v", research.Potential)
+	}
+	if len(research.Prerequisites) != 1 || research.Prerequisites[0] != "tech_planetary_unification" {
+		t.Errorf("Expected prerequisites, got %v", research.Prerequisites)
+	}
+	if research.Icon != "GFX_edict_type_policy" || research.SourceFile != "00_edicts.txt" {
+		t.Errorf("Expected icon and source file, got %+v", research)
+	}
+}
+
+func TestParsePolicies(t *testing.T) {
+	gameDir := t.TempDir()
+	writeFiles(t, map[string]string{
+		filepath.Join(gameDir, PoliciesDir, "00_policies.txt"): `diplomatic_stance = {
+	potential = {
+		is_country_type = default
+	}
+	option = {
+		name = "diplo_stance_cooperative"
+		modifier = {
+			envoys_add = 1
+		}
+	}
+	option = {
+		name = "diplo_stance_belligerent"
+		prerequisites = { "tech_interstellar_fleet_traditions" }
+		valid = {
+			is_pacifist = no
+		}
+	}
+}
+`,
+	})
+
+	policies, err := ParsePolicies(gameDir, nil)
+	if err != nil {
+		t.Fatalf("Failed to parse policies: %v", err)
+	}
+	if len(policies) != 1 {
+		t.Fatalf("Expected 1 policy, got %d", len(policies))
+	}
+
+	options := policies[0].Options
+	if len(options) != 2 {
+		t.Fatalf("Expected every option, got %+v", options)
+	}
+	if options[0].Key != "diplo_stance_cooperative" || options[0].Modifiers["envoys_add"] != 1 {
+		t.Errorf("Expected the cooperative option with its modifier, got %+v", options[0])
+	}
+	if options[1].Key != "diplo_stance_belligerent" || len(options[1].Prerequisites) != 1 || options[1].Valid["is_pacifist"] != false {
+		t.Errorf("Expected the belligerent option with prerequisites and valid, got %+v", options[1])
+	}
+	if policies[0].Potential["is_country_type"] != "default" {
+		t.Errorf("Expected potential conditions, got %v", policies[0].Potential)
+	}
+}
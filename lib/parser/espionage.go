@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// EspionageOperationTypesDir is the location of espionage operations,
+// relative to the game or mod directory
+const EspionageOperationTypesDir = "common/espionage_operation_types"
+
+// ParseEspionageOperations reads the espionage operations of the game
+// directory and mods
+func ParseEspionageOperations(gameDir string, modDirs []string) ([]*models.EspionageOperation, error) {
+	definitions, err := LoadDefinitions(EspionageOperationTypesDir, gameDir, modDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read espionage operations: %w", err)
+	}
+
+	operations := make([]*models.EspionageOperation, 0, len(definitions))
+	for _, definition := range definitions {
+		data := definition.Data
+		cost, _ := resourceCosts(data)
+		operation := &models.EspionageOperation{
+			Key:          definition.Key,
+			Difficulty:   data["difficulty"],
+			Cost:         cost,
+			Potential:    rawBlock(data["potential"]),
+			Allow:        rawBlock(data["allow"]),
+			LinkedEvents: linkedEvents(definition),
+			SourceFile:   definition.SourceFile,
+			Mod:          definition.Mod,
+		}
+		operation.Icon, _ = data["icon"].(string)
+		operation.Category, _ = data["category"].(string)
+		operation.DaysPerPhase, _ = data["days_per_phase"].(int)
+		operations = append(operations, operation)
+	}
+	return operations, nil
+}
@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseEspionageOperations(t *testing.T) {
+	gameDir := t.TempDir()
+	writeFiles(t, map[string]string{
+		filepath.Join(gameDir, EspionageOperationTypesDir, "00_operations.txt"): `operation_gather_information = {
+	icon = GFX_operation_gather_information
+	category = operation_category_information
+	difficulty = 2
+	days_per_phase = 30
+	resources = {
+		category = espionage
+		cost = {
+			energy = 50
+		}
+	}
+	potential = {
+		is_gestalt = no
+	}
+	on_roll_finished = {
+		country_event = { id = operation.1 }
+	}
+}
+operation_steal_technology = {
+	difficulty = {
+		base = 4
+		modifier = {
+			add = 1
+			has_technology = tech_encryption_1
+		}
+	}
+	on_phase_finished = {
+		country_event = { id = operation.20 }
+	}
+}
+`,
+	})
+
+	operations, err := ParseEspionageOperations(gameDir, nil)
+	if err != nil {
+		t.Fatalf("Failed to parse espionage operations: %v", err)
+	}
+	if len(operations) != 2 {
+		t.Fatalf("Expected 2 operations, got %d", len(operations))
+	}
+
+	gather := operations[0]
+	if gather.Icon != "GFX_operation_gather_information" || gather.Category != "operation_category_information" || gather.DaysPerPhase != 30 {
+		t.Errorf("Unexpected operation %+v", gather)
+	}
+	if gather.Difficulty != 2 || gather.Cost["energy"] != 50 || gather.Potential["is_gestalt"] != false {
+		t.Errorf("Unexpected difficulty %v, cost %v or potential %v", gather.Difficulty, gather.Cost, gather.Potential)
+	}
+	if !reflect.DeepEqual(gather.LinkedEvents, []string{"operation.1"}) {
+		t.Errorf("Expected the linked event operation.1, got %v", gather.LinkedEvents)
+	}
+
+	steal := operations[1]
+	if difficulty, ok := steal.Difficulty.(map[string]interface{}); !ok || difficulty["base"] != 4 {
+		t.Errorf("Expected the raw difficulty block, got %v", steal.Difficulty)
+	}
+	if len(steal.Cost) != 0 || len(steal.Allow) != 0 {
+		t.Errorf("Expected no cost or conditions, got %v and %v", steal.Cost, steal.Allow)
+	}
+}
@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// EventsDir is the location of event files, relative to the game or mod
+// directory
+const EventsDir = "events"
+
+// ParseEvents reads the events of the game directory and mods. Events are
+// identified by their id, so a mod replaces an event by defining the same id.
+// Blocks and effects are kept as raw script blocks, which hold only the last
+// of repeated keys.
+func ParseEvents(gameDir string, modDirs []string) ([]*models.Event, error) {
+	definitions, err := LoadKeyedDefinitions(EventsDir, "id", gameDir, modDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events: %w", err)
+	}
+
+	events := make([]*models.Event, 0, len(definitions))
+	for _, definition := range definitions {
+		data := definition.Data
+		namespace, _, _ := strings.Cut(definition.Key, ".")
+		event := &models.Event{
+			ID:              definition.Key,
+			Type:            definition.Block,
+			Namespace:       namespace,
+			DescriptionKeys: textKeys(data["desc"], definition.Blocks("desc")),
+			Trigger:         rawBlock(data["trigger"]),
+			Immediate:       rawBlock(data["immediate"]),
+			Options:         []models.EventOption{},
+			SourceFile:      definition.SourceFile,
+			Mod:             definition.Mod,
+		}
+		if titles := textKeys(data["title"], definition.Blocks("title")); len(titles) > 0 {
+			event.TitleKey = titles[0]
+		}
+		event.Picture, _ = data["picture"].(string)
+		event.Hidden, _ = data["hide_window"].(bool)
+		event.TriggeredOnly, _ = data["is_triggered_only"].(bool)
+
+		for _, option := range definition.Blocks("option") {
+			name, _ := option["name"].(string)
+			effects := make(map[string]interface{}, len(option))
+			for key, value := range option {
+				if key != "name" && key != "trigger" {
+					effects[key] = value
+				}
+			}
+			event.Options = append(event.Options, models.EventOption{
+				NameKey: name,
+				Trigger: rawBlock(option["trigger"]),
+				Effects: effects,
+			})
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// textKeys returns the localization keys of a title or description: a
+// single key, each key of a repeated one, or the text of each conditional
+// block such as desc = { trigger = { ... } text = key }
+func textKeys(value interface{}, blocks []map[string]interface{}) []string {
+	keys := []string{}
+	for _, entry := range models.Values(value) {
+		if key, ok := entry.(string); ok {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) > 0 {
+		return keys
+	}
+	for _, block := range blocks {
+		if key, ok := block["text"].(string); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
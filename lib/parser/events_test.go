@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseEvents(t *testing.T) {
+	gameDir := t.TempDir()
+	modDir := filepath.Join(t.TempDir(), "my_mod")
+	writeFiles(t, map[string]string{
+		filepath.Join(gameDir, EventsDir, "ancient_relics_events.txt"): `namespace = ancrel
+
+country_event = {
+	id = ancrel.1
+	title = ancrel.1.name
+	desc = {
+		trigger = {
+			is_machine_empire = yes
+		}
+		text = ancrel.1.desc.machine
+	}
+	desc = {
+		text = ancrel.1.desc
+	}
+	picture = GFX_evt_ancient_battlefield
+	is_triggered_only = yes
+	immediate = {
+		set_country_flag = ancrel_1_seen
+	}
+	option = {
+		name = ancrel.1.a
+		add_resource = {
+			influence = 50
+		}
+	}
+	option = {
+		name = ancrel.1.b
+		trigger = {
+			is_pacifist = no
+		}
+	}
+}
+
+planet_event = {
+	id = ancrel.2
+	hide_window = yes
+	trigger = {
+		is_colony = yes
+	}
+}
+`,
+		filepath.Join(modDir, EventsDir, "mod_events.txt"): "planet_event = {\n\tid = ancrel.2\n\ttitle = mod.2.name\n}\n",
+	})
+
+	events, err := ParseEvents(gameDir, []string{modDir})
+	if err != nil {
+		t.Fatalf("Failed to parse events: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+
+	first := events[0]
+	if first.ID != "ancrel.1" || first.Type != "country_event" || first.Namespace != "ancrel" {
+		t.Errorf("Expected id, type and namespace, got %+v", first)
+	}
+	if first.TitleKey != "ancrel.1.name" || len(first.DescriptionKeys) != 2 || first.DescriptionKeys[1] != "ancrel.1.desc" {
+		t.Errorf("Expected the title key and both description keys, got %q and %v", first.TitleKey, first.DescriptionKeys)
+	}
+	if !first.TriggeredOnly || first.Hidden || first.Immediate["set_country_flag"] != "ancrel_1_seen" {
+		t.Errorf("Expected a triggered event with immediate effects, got %+v", first)
+	}
+	if len(first.Options) != 2 {
+		t.Fatalf("Expected 2 options, got %+v", first.Options)
+	}
+	if option := first.Options[0]; option.NameKey != "ancrel.1.a" || rawBlock(option.Effects["add_resource"])["influence"] != 50 {
+		t.Errorf("Expected the first option with its effects, got %+v", option)
+	}
+	if option := first.Options[1]; option.Trigger["is_pacifist"] != false || len(option.Effects) != 0 {
+		t.Errorf("Expected the second option with a trigger only, got %+v", option)
+	}
+
+	if second := events[1]; second.TitleKey != "mod.2.name" || second.Mod != "my_mod" {
+		t.Errorf("Expected the mod to replace ancrel.2, got %+v", second)
+	}
+}
@@ -0,0 +1,20 @@
+package parser_test
+
+import (
+	"fmt"
+
+	"github.com/danaketh/StellarisDataParser/lib/parser"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+func Example() {
+	techParser := parser.NewTechParser()
+	if err := techParser.ParseDirectory("../../testdata/common/technology"); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	techTree := tree.NewTechTree(techParser.GetTechnologies())
+	fmt.Println(techTree.GetAreas())
+	// Output: [engineering physics society]
+}
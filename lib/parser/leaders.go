@@ -0,0 +1,144 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// Locations of leader definitions, relative to the game or mod directory
+const (
+	TraitsDir           = "common/traits"
+	LeaderClassesDir    = "common/leader_classes"
+	CouncilPositionsDir = "common/governments/councils"
+)
+
+// ParseLeaderTraits reads the leader traits of the game directory and mods.
+// common/traits also holds species traits; a trait is a leader trait when it
+// sets leader_trait or leader_class.
+func ParseLeaderTraits(gameDir string, modDirs []string) ([]*models.LeaderTrait, error) {
+	definitions, err := LoadDefinitions(TraitsDir, gameDir, modDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read traits: %w", err)
+	}
+
+	traits := []*models.LeaderTrait{}
+	for _, definition := range definitions {
+		data := definition.Data
+		classes, isLeaderTrait := leaderClasses(data)
+		if !isLeaderTrait {
+			continue
+		}
+		trait := &models.LeaderTrait{
+			Key:                definition.Key,
+			Classes:            classes,
+			Opposites:          stringList(data["opposites"]),
+			Prerequisites:      stringList(data["prerequisites"]),
+			Modifiers:          rawBlock(data["modifier"]),
+			SelfModifiers:      rawBlock(data["self_modifier"]),
+			CouncilorModifiers: rawBlock(data["councilor_modifier"]),
+			Potential:          rawBlock(data["leader_potential_add"]),
+			SourceFile:         definition.SourceFile,
+			Mod:                definition.Mod,
+		}
+		trait.Icon, _ = data["icon"].(string)
+		trait.Cost, _ = data["cost"].(int)
+		traits = append(traits, trait)
+	}
+	return traits, nil
+}
+
+// leaderClasses returns the classes a trait is limited to and whether it is
+// a leader trait at all. leader_class lists the classes; older files use
+// leader_trait = { scientist } or leader_trait = all.
+func leaderClasses(data map[string]interface{}) ([]string, bool) {
+	value, exists := data["leader_class"]
+	if !exists {
+		if value, exists = data["leader_trait"]; !exists || value == false {
+			return nil, false
+		}
+	}
+
+	classes := []string{}
+	for _, class := range stringOrList(value) {
+		if class != "all" {
+			classes = append(classes, class)
+		}
+	}
+	return classes, true
+}
+
+// stringOrList converts a single string or a list of strings to a list
+func stringOrList(value interface{}) []string {
+	if s, ok := value.(string); ok {
+		return []string{s}
+	}
+	return stringList(value)
+}
+
+// TraitsByTechnology returns the leader traits each technology makes
+// available, sorted by trait key
+func TraitsByTechnology(traits []*models.LeaderTrait) map[string][]string {
+	byTechnology := make(map[string][]string)
+	for _, trait := range traits {
+		for _, tech := range trait.Prerequisites {
+			byTechnology[tech] = append(byTechnology[tech], trait.Key)
+		}
+	}
+	for _, keys := range byTechnology {
+		sort.Strings(keys)
+	}
+	return byTechnology
+}
+
+// ParseLeaderClasses reads the leader classes of the game directory and mods
+func ParseLeaderClasses(gameDir string, modDirs []string) ([]*models.LeaderClass, error) {
+	definitions, err := LoadDefinitions(LeaderClassesDir, gameDir, modDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read leader classes: %w", err)
+	}
+
+	classes := make([]*models.LeaderClass, 0, len(definitions))
+	for _, definition := range definitions {
+		cost, upkeep := resourceCosts(definition.Data)
+		settings := make(map[string]interface{}, len(definition.Data))
+		for key, value := range definition.Data {
+			if key != "resources" && key != "cost" && key != "upkeep" {
+				settings[key] = value
+			}
+		}
+		classes = append(classes, &models.LeaderClass{
+			Key:        definition.Key,
+			Cost:       cost,
+			Upkeep:     upkeep,
+			Settings:   settings,
+			SourceFile: definition.SourceFile,
+			Mod:        definition.Mod,
+		})
+	}
+	return classes, nil
+}
+
+// ParseCouncilPositions reads the council positions of the game directory
+// and mods
+func ParseCouncilPositions(gameDir string, modDirs []string) ([]*models.CouncilPosition, error) {
+	definitions, err := LoadDefinitions(CouncilPositionsDir, gameDir, modDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read council positions: %w", err)
+	}
+
+	positions := make([]*models.CouncilPosition, 0, len(definitions))
+	for _, definition := range definitions {
+		data := definition.Data
+		positions = append(positions, &models.CouncilPosition{
+			Key:           definition.Key,
+			LeaderClasses: stringOrList(data["leader_class"]),
+			Potential:     rawBlock(data["potential"]),
+			Modifiers:     rawBlock(data["modifier"]),
+			SourceFile:    definition.SourceFile,
+			Mod:           definition.Mod,
+		})
+	}
+	return positions, nil
+}
@@ -0,0 +1,145 @@
+package parser
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseLeaderTraits(t *testing.T) {
+	gameDir := t.TempDir()
+	writeFiles(t, map[string]string{
+		filepath.Join(gameDir, TraitsDir, "00_species_traits.txt"): `trait_strong = {
+	cost = 1
+	modifier = {
+		pop_housing_usage_mult = 0.1
+	}
+}
+`,
+		filepath.Join(gameDir, TraitsDir, "00_leader_traits.txt"): `leader_trait_spark_of_genius = {
+	cost = 2
+	icon = "gfx/interface/icons/traits/leader_traits/leader_trait_spark_of_genius.dds"
+	leader_class = { scientist official }
+	opposites = { "leader_trait_lethargic" }
+	prerequisites = { tech_psionic_theory }
+	self_modifier = {
+		species_leader_exp_gain = 0.1
+	}
+	councilor_modifier = {
+		all_technology_research_speed = 0.05
+	}
+	leader_potential_add = {
+		is_lithoid = no
+	}
+}
+leader_trait_old = {
+	leader_trait = all
+	prerequisites = { tech_psionic_theory }
+}
+leader_trait_careful = {
+	leader_trait = { scientist }
+	modifier = {
+		ship_anomaly_fail_risk = -0.25
+	}
+}
+`,
+	})
+
+	traits, err := ParseLeaderTraits(gameDir, nil)
+	if err != nil {
+		t.Fatalf("Failed to parse leader traits: %v", err)
+	}
+	if len(traits) != 3 {
+		t.Fatalf("Expected the 3 leader traits only, got %d", len(traits))
+	}
+
+	careful, old, genius := traits[0], traits[1], traits[2]
+	if genius.Key != "leader_trait_spark_of_genius" || genius.Cost != 2 || genius.Icon == "" {
+		t.Errorf("Unexpected trait %+v", genius)
+	}
+	if !reflect.DeepEqual(genius.Classes, []string{"scientist", "official"}) {
+		t.Errorf("Expected the classes of leader_class, got %v", genius.Classes)
+	}
+	if !reflect.DeepEqual(genius.Opposites, []string{"leader_trait_lethargic"}) || !reflect.DeepEqual(genius.Prerequisites, []string{"tech_psionic_theory"}) {
+		t.Errorf("Unexpected opposites %v or prerequisites %v", genius.Opposites, genius.Prerequisites)
+	}
+	if genius.SelfModifiers["species_leader_exp_gain"] != 0.1 || genius.CouncilorModifiers["all_technology_research_speed"] != 0.05 {
+		t.Errorf("Unexpected modifiers %v and %v", genius.SelfModifiers, genius.CouncilorModifiers)
+	}
+	if genius.Potential["is_lithoid"] != false {
+		t.Errorf("Expected the leader_potential_add conditions, got %v", genius.Potential)
+	}
+	if len(old.Classes) != 0 {
+		t.Errorf("Expected leader_trait = all to allow every class, got %v", old.Classes)
+	}
+	if !reflect.DeepEqual(careful.Classes, []string{"scientist"}) || careful.Modifiers["ship_anomaly_fail_risk"] != -0.25 {
+		t.Errorf("Unexpected trait %+v", careful)
+	}
+
+	byTechnology := TraitsByTechnology(traits)
+	expected := map[string][]string{"tech_psionic_theory": {"leader_trait_old", "leader_trait_spark_of_genius"}}
+	if !reflect.DeepEqual(byTechnology, expected) {
+		t.Errorf("Expected %v, got %v", expected, byTechnology)
+	}
+}
+
+func TestParseLeaderClassesAndCouncilPositions(t *testing.T) {
+	gameDir := t.TempDir()
+	modDir := filepath.Join(t.TempDir(), "my_mod")
+	writeFiles(t, map[string]string{
+		filepath.Join(gameDir, LeaderClassesDir, "00_leader_classes.txt"): `scientist = {
+	max_trait_points = 3
+	resources = {
+		category = leaders
+		cost = {
+			unity = 50
+		}
+		upkeep = {
+			energy = 2
+		}
+	}
+}
+`,
+		filepath.Join(gameDir, CouncilPositionsDir, "00_councils.txt"): `councilor_research = {
+	leader_class = scientist
+	potential = {
+		is_gestalt = no
+	}
+	modifier = {
+		all_technology_research_speed = 0.05
+	}
+}
+`,
+		filepath.Join(modDir, CouncilPositionsDir, "mod_councils.txt"): "councilor_military = {\n\tleader_class = { commander official }\n}\n",
+	})
+
+	classes, err := ParseLeaderClasses(gameDir, []string{modDir})
+	if err != nil {
+		t.Fatalf("Failed to parse leader classes: %v", err)
+	}
+	if len(classes) != 1 {
+		t.Fatalf("Expected 1 leader class, got %d", len(classes))
+	}
+	scientist := classes[0]
+	if scientist.Cost["unity"] != 50 || scientist.Upkeep["energy"] != 2 {
+		t.Errorf("Unexpected cost %v or upkeep %v", scientist.Cost, scientist.Upkeep)
+	}
+	if _, exists := scientist.Settings["resources"]; exists || scientist.Settings["max_trait_points"] != 3 {
+		t.Errorf("Expected the settings without resources, got %v", scientist.Settings)
+	}
+
+	positions, err := ParseCouncilPositions(gameDir, []string{modDir})
+	if err != nil {
+		t.Fatalf("Failed to parse council positions: %v", err)
+	}
+	if len(positions) != 2 {
+		t.Fatalf("Expected 2 council positions, got %d", len(positions))
+	}
+	military, research := positions[0], positions[1]
+	if !reflect.DeepEqual(military.LeaderClasses, []string{"commander", "official"}) || military.Mod != "my_mod" {
+		t.Errorf("Unexpected position %+v", military)
+	}
+	if !reflect.DeepEqual(research.LeaderClasses, []string{"scientist"}) || research.Potential["is_gestalt"] != false || research.Modifiers["all_technology_research_speed"] != 0.05 {
+		t.Errorf("Unexpected position %+v", research)
+	}
+}
@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"log/slog"
+
+	"github.com/danaketh/StellarisDataParser/lib/progress"
+)
+
+// Option configures a TechParser created by NewTechParser. Each option has a
+// Set method of the same effect, so a parser can also be configured after
+// it is created.
+type Option func(*TechParser)
+
+// WithStrictMode enables or disables strict mode, see SetStrict
+func WithStrictMode(strict bool) Option {
+	return func(p *TechParser) { p.SetStrict(strict) }
+}
+
+// WithKeepSource keeps the script text of each technology, see SetKeepSource
+func WithKeepSource(keep bool) Option {
+	return func(p *TechParser) { p.SetKeepSource(keep) }
+}
+
+// WithWorkers sets how many files are parsed concurrently, see SetWorkers
+func WithWorkers(workers int) Option {
+	return func(p *TechParser) { p.SetWorkers(workers) }
+}
+
+// WithVariables sets scripted variables every parsed file can reference, see
+// SetVariables
+func WithVariables(variables map[string]string) Option {
+	return func(p *TechParser) { p.SetVariables(variables) }
+}
+
+// WithFileFilter selects the technology files to parse, see SetFileFilter
+func WithFileFilter(filter func(path string) bool) Option {
+	return func(p *TechParser) { p.SetFileFilter(filter) }
+}
+
+// WithProgress sets the reporter of parse progress, see SetProgress
+func WithProgress(reporter *progress.Reporter) Option {
+	return func(p *TechParser) { p.SetProgress(reporter) }
+}
+
+// WithLogger sets the logger for warnings and debug records, see SetLogger
+func WithLogger(logger *slog.Logger) Option {
+	return func(p *TechParser) { p.SetLogger(logger) }
+}
@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+func TestNewTechParserOptions(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+
+	parser := NewTechParser(
+		WithStrictMode(true),
+		WithKeepSource(true),
+		WithWorkers(0),
+		WithLogger(logger),
+	)
+	if !parser.strict || !parser.keepSource || parser.workers != 1 || parser.logger != logger {
+		t.Errorf("Expected the options to configure the parser, got strict %v, keep source %v, %d workers",
+			parser.strict, parser.keepSource, parser.workers)
+	}
+
+	defaults := NewTechParser()
+	if defaults.strict || defaults.keepSource || defaults.workers < 1 {
+		t.Error("Expected NewTechParser without options to keep the defaults")
+	}
+}
+
+func TestWithVariables(t *testing.T) {
+	fsys := fstest.MapFS{
+		"technology/00_a.txt": {Data: []byte("tech_a = { cost = @tier1cost }\n")},
+		"technology/01_b.txt": {Data: []byte("@tier1cost = 50\ntech_b = { cost = @tier1cost tier = @tier }\n")},
+	}
+
+	variables := map[string]string{"tier1cost": "100", "tier": "1"}
+	parser := NewTechParser(WithVariables(variables))
+	variables["tier"] = "2"
+	if err := parser.ParseFS(fsys, "technology"); err != nil {
+		t.Fatalf("ParseFS failed: %v", err)
+	}
+
+	techA, _ := parser.GetTechnology("tech_a")
+	if techA.Cost != 100 {
+		t.Errorf("Expected the parser variable to be used, got cost %v", techA.Cost)
+	}
+	techB, _ := parser.GetTechnology("tech_b")
+	if techB.Cost != 50 {
+		t.Errorf("Expected the file's own variable to take precedence, got cost %v", techB.Cost)
+	}
+	if techB.Tier != 1 {
+		t.Errorf("Expected the variables to be copied, got tier %d", techB.Tier)
+	}
+}
+
+func TestWithFileFilter(t *testing.T) {
+	fsys := fstest.MapFS{
+		"technology/00_phys_tech.txt":       {Data: []byte("tech_a = { area = physics }\n")},
+		"technology/00_soc_tech.txt":        {Data: []byte("tech_b = { area = society }\n")},
+		"technology/extra/01_phys_tech.txt": {Data: []byte("tech_c = { area = physics }\n")},
+	}
+
+	var seen []string
+	parser := NewTechParser(WithFileFilter(func(path string) bool {
+		seen = append(seen, path)
+		return strings.Contains(path, "phys")
+	}))
+	if err := parser.ParseFS(fsys, "technology"); err != nil {
+		t.Fatalf("ParseFS failed: %v", err)
+	}
+
+	if got := len(parser.GetTechnologies()); got != 2 {
+		t.Errorf("Expected the two physics technologies, got %d", got)
+	}
+	if _, exists := parser.GetTechnology("tech_b"); exists {
+		t.Error("Expected the filtered file to be skipped")
+	}
+	want := []string{"00_phys_tech.txt", "00_soc_tech.txt", "extra/01_phys_tech.txt"}
+	if strings.Join(seen, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected the filter to get paths relative to the directory %v, got %v", want, seen)
+	}
+
+	var streamed []string
+	err := parser.ParseFSStream(fsys, "technology", func(tech *models.Technology) error {
+		streamed = append(streamed, tech.Key)
+		return nil
+	})
+	if err != nil || strings.Join(streamed, ",") != "tech_a,tech_c" {
+		t.Errorf("Expected streaming to apply the filter, got %v (%v)", streamed, err)
+	}
+}
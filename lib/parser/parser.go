@@ -1,105 +1,511 @@
+// Package parser reads Stellaris game script files. TechParser parses the
+// technologies of common/technology of the game and of mods; the Parse and
+// Load functions read the other game content, such as edicts, ship sizes or
+// defines, from a game directory followed by mod directories in load order.
 package parser
 
 import (
-	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 
-	"stellaris-data-parser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/charset"
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/progress"
 )
 
-// TechParser handles parsing of Stellaris technology files
+// TechnologyDir is the location of the technology files, relative to the game
+// or mod directory
+const TechnologyDir = "common/technology"
+
+// ErrNoTechDirectory is returned when the technology directory to parse
+// doesn't exist
+var ErrNoTechDirectory = errors.New("technology directory not found")
+
+// TechParser handles parsing of Stellaris technology files.
+//
+// A TechParser is safe for concurrent use. Parse calls run one at a time,
+// and the getters may be called from other goroutines while a directory is
+// being parsed; they return copies, so the results can't change under the
+// caller. The technologies themselves are shared and must not be modified
+// while the parser is in use. The Set methods configure the parser and are
+// meant to be called before parsing.
 type TechParser struct {
+	mu           sync.RWMutex // Guards the parsed data below
+	parsing      sync.Mutex   // Held for the whole of a parse call
 	technologies map[string]*models.Technology
+	categories   map[string]*models.Category
+	diagnostics  []Diagnostic
+	strict       bool // Fail on the first malformed file instead of warning
+	keepSource   bool // Keep the script text of each technology
+	workers      int  // Number of files parsed concurrently by ParseDirectory
+	overrides    []models.Override
+	mod          string            // Mod currently being parsed, empty for the base game
+	variables    map[string]string // Scripted variables available to every file
+	fileFilter   func(string) bool // Selects the technology files to parse, nil for all
+	progress     *progress.Reporter
+	logger       *slog.Logger
 }
 
-// NewTechParser creates a new technology parser
-func NewTechParser() *TechParser {
-	return &TechParser{
+// fileResult holds everything parsed from a single file, so files can be
+// read concurrently and merged into the parser afterwards
+type fileResult struct {
+	path         string
+	technologies map[string]*models.Technology
+	categories   map[string]*models.Category
+	diagnostics  []Diagnostic
+	err          error
+}
+
+// NewTechParser creates a new technology parser configured by the given
+// options, see Option
+func NewTechParser(opts ...Option) *TechParser {
+	p := &TechParser{
 		technologies: make(map[string]*models.Technology),
+		categories:   make(map[string]*models.Category),
+		workers:      runtime.NumCPU(),
+		logger:       slog.New(slog.DiscardHandler),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// SetStrict enables or disables strict mode.
+// In strict mode parsing stops at the first malformed file and technologies
+// from that file are discarded. In lenient mode (the default) problems are
+// reported as warnings and parsing continues.
+func (p *TechParser) SetStrict(strict bool) {
+	p.strict = strict
+}
+
+// SetKeepSource sets whether the original script text of each technology,
+// comments included, is kept in its Source along with the lines it spans.
+// Sources are not kept by default.
+func (p *TechParser) SetKeepSource(keep bool) {
+	p.keepSource = keep
+}
+
+// SetWorkers sets how many files ParseDirectory parses concurrently.
+// Values below 1 are treated as 1 (sequential parsing).
+func (p *TechParser) SetWorkers(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	p.workers = workers
+}
+
+// SetProgress sets the reporter that receives an event for every parsed file
+func (p *TechParser) SetProgress(reporter *progress.Reporter) {
+	p.progress = reporter
+}
+
+// SetLogger sets the logger that receives a debug record for every parsed
+// file and a warning for every file that can't be read. Nothing is logged by
+// default; nil restores the default.
+func (p *TechParser) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
 	}
+	p.logger = logger
 }
 
-// ParseDirectory parses all technology files in a directory
+// SetVariables sets scripted variables (name without the @ mapped to the
+// value) that every parsed file can reference, such as those of
+// common/scripted_variables. A variable defined in a file takes precedence
+// over one of the same name set here.
+func (p *TechParser) SetVariables(variables map[string]string) {
+	p.variables = maps.Clone(variables)
+}
+
+// SetFileFilter sets a function selecting the technology files parsed by
+// ParseDirectory, ParseFS and their variants. It is called with the
+// slash-separated path of each .txt file relative to the technology
+// directory, such as "00_phys_tech.txt", and files for which it returns
+// false are skipped. Categories and files passed to ParseFile are not
+// filtered; nil parses every file.
+func (p *TechParser) SetFileFilter(filter func(path string) bool) {
+	p.fileFilter = filter
+}
+
+// ParseModDirectory parses the technology files of a mod.
+// Mods must be parsed after the base game and in load order: technologies
+// they define replace earlier definitions with the same key, and every
+// replacement is recorded as an override.
+func (p *TechParser) ParseModDirectory(path string, mod string) error {
+	return p.ParseModDirectoryContext(context.Background(), path, mod)
+}
+
+// ParseModDirectoryContext is ParseModDirectory with a context that stops
+// parsing when it is cancelled
+func (p *TechParser) ParseModDirectoryContext(ctx context.Context, path string, mod string) error {
+	p.parsing.Lock()
+	defer p.parsing.Unlock()
+	p.mod = mod
+	defer func() { p.mod = "" }()
+	return p.parseFS(ctx, os.DirFS(path), ".", osPath(path))
+}
+
+// ParseModFS is ParseModDirectory for the technology directory dir of a file
+// system, such as a mod .zip archive opened with gamefs.Open
+func (p *TechParser) ParseModFS(fsys fs.FS, dir string, mod string) error {
+	return p.ParseModFSContext(context.Background(), fsys, dir, mod)
+}
+
+// ParseModFSContext is ParseModFS with a context that stops parsing when it
+// is cancelled
+func (p *TechParser) ParseModFSContext(ctx context.Context, fsys fs.FS, dir string, mod string) error {
+	p.parsing.Lock()
+	defer p.parsing.Unlock()
+	p.mod = mod
+	defer func() { p.mod = "" }()
+	return p.parseFS(ctx, fsys, dir, fsPath)
+}
+
+// ParseDirectory parses all technology files in a directory.
+// Files are parsed concurrently but merged in lexical path order, so a
+// technology defined in several files always resolves to the last one.
+// Research categories in the category subdirectory are read separately, see
+// GetCategories. A directory that doesn't exist is reported as
+// ErrNoTechDirectory.
 func (p *TechParser) ParseDirectory(path string) error {
-	return filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
+	return p.ParseDirectoryContext(context.Background(), path)
+}
+
+// ParseDirectoryContext is ParseDirectory with a context. When the context
+// is cancelled, files not read yet are skipped and its error is returned;
+// no technologies of the directory are added then.
+func (p *TechParser) ParseDirectoryContext(ctx context.Context, path string) error {
+	p.parsing.Lock()
+	defer p.parsing.Unlock()
+	return p.parseFS(ctx, os.DirFS(path), ".", osPath(path))
+}
+
+// ParseFS is ParseDirectory for the directory dir of a file system. dir is a
+// slash-separated path as accepted by fs.ValidPath, "." for the root.
+func (p *TechParser) ParseFS(fsys fs.FS, dir string) error {
+	return p.ParseFSContext(context.Background(), fsys, dir)
+}
+
+// ParseFSContext is ParseFS with a context, like ParseDirectoryContext
+func (p *TechParser) ParseFSContext(ctx context.Context, fsys fs.FS, dir string) error {
+	p.parsing.Lock()
+	defer p.parsing.Unlock()
+	return p.parseFS(ctx, fsys, dir, fsPath)
+}
+
+// parseFS parses the technology files below dir. location turns the paths
+// of fsys into the paths shown in warnings and progress events.
+func (p *TechParser) parseFS(ctx context.Context, fsys fs.FS, dir string, location func(string) string) error {
+	paths, err := technologyFiles(ctx, fsys, dir, p.fileFilter)
+	if err != nil {
+		return directoryError(err, location(dir))
+	}
+
+	if err := p.parseCategoryFS(fsys, path.Join(dir, CategoryDir)); err != nil {
+		return fmt.Errorf("failed to parse categories: %w", err)
+	}
+
+	results := p.parseFiles(ctx, fsys, paths, location)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		if err := p.mergeResult(result); err != nil {
+			if err := p.fileError(result, err); err != nil {
+				return err
+			}
+			continue
+		}
+		p.logger.Debug("Parsed technology file", "file", result.path, "technologies", len(result.technologies))
+	}
+
+	return nil
+}
+
+// fileError returns the error to stop parsing a directory with when a file
+// fails, which is none in lenient mode. Malformed files are reported
+// through GetDiagnostics and other failures are logged.
+func (p *TechParser) fileError(result fileResult, err error) error {
+	if p.strict {
+		return fmt.Errorf("failed to parse %s: %w", result.path, err)
+	}
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		p.logger.Warn("Failed to parse technology file", "file", result.path, "error", err)
+	}
+	return nil
+}
+
+// technologyFiles returns the technology files below dir in lexical order,
+// without the category and tier definitions and the files rejected by
+// filter, if any. A missing dir is ErrNoTechDirectory.
+func technologyFiles(ctx context.Context, fsys fs.FS, dir string, filter func(string) bool) ([]string, error) {
+	if _, err := fs.Stat(fsys, dir); errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNoTechDirectory
+	}
+
+	var paths []string
+	err := fs.WalkDir(fsys, dir, func(filePath string, entry fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Category and tier definitions are not technologies
+		if entry.IsDir() && filePath != dir && (entry.Name() == CategoryDir || entry.Name() == TierDir) {
+			return fs.SkipDir
+		}
 
 		// Only process .txt files
-		if !info.IsDir() && strings.HasSuffix(info.Name(), ".txt") {
-			if err := p.ParseFile(filePath); err != nil {
-				fmt.Printf("Warning: failed to parse %s: %v\n", filePath, err)
-			}
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".txt") {
+			return nil
 		}
+		if filter != nil && !filter(relativePath(dir, filePath)) {
+			return nil
+		}
+		paths = append(paths, filePath)
 		return nil
 	})
+	return paths, err
+}
+
+// directoryError adds the location of the technology directory to
+// ErrNoTechDirectory returned by technologyFiles
+func directoryError(err error, location string) error {
+	if errors.Is(err, ErrNoTechDirectory) {
+		return fmt.Errorf("%w: %s", err, location)
+	}
+	return err
+}
+
+// relativePath returns the path of name, a path below dir of a file system,
+// relative to dir
+func relativePath(dir, name string) string {
+	if dir == "." {
+		return name
+	}
+	return strings.TrimPrefix(name, dir+"/")
+}
+
+// osPath returns a location function turning the paths of os.DirFS(dir) back
+// into operating system paths
+func osPath(dir string) func(string) string {
+	return func(name string) string {
+		return filepath.Join(dir, filepath.FromSlash(name))
+	}
+}
+
+// fsPath is the location function of file systems passed in by callers,
+// which shows their paths as is
+func fsPath(name string) string {
+	return name
+}
+
+// parseFiles parses the given files of fsys using a bounded worker pool and
+// returns the results in the same order as paths. Once ctx is cancelled the
+// remaining files are skipped.
+func (p *TechParser) parseFiles(ctx context.Context, fsys fs.FS, paths []string, location func(string) string) []fileResult {
+	results := make([]fileResult, len(paths))
+	jobs := make(chan int)
+
+	workers := p.workers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(paths) {
+		workers = len(paths)
+	}
+
+	var wg sync.WaitGroup
+	var done atomic.Int64
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = p.readFile(fsys, paths[i], location(paths[i]))
+				p.progress.Report(progress.StageParse, int(done.Add(1)), len(paths), results[i].path)
+			}
+		}()
+	}
+
+	for i := range paths {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
 }
 
-// ParseFile parses a single technology file
+// ParseFile parses a single technology file.
+// If the file is malformed (e.g. unbalanced braces) the problems are recorded
+// in the parser diagnostics and returned as a *ParseError. In lenient mode
+// technologies that could still be read from the file are kept; in strict
+// mode none of them are.
 func (p *TechParser) ParseFile(path string) error {
+	p.parsing.Lock()
+	defer p.parsing.Unlock()
+	return p.mergeResult(p.readFile(os.DirFS(filepath.Dir(path)), filepath.Base(path), path))
+}
+
+// ParseFileFS is ParseFile for the file name of a file system
+func (p *TechParser) ParseFileFS(fsys fs.FS, name string) error {
+	p.parsing.Lock()
+	defer p.parsing.Unlock()
+	return p.mergeResult(p.readFile(fsys, name, name))
+}
+
+// readFile parses the file name of fsys without touching parser state, so it
+// is safe to call from multiple goroutines. location is the path reported
+// for the file.
+func (p *TechParser) readFile(fsys fs.FS, name, location string) fileResult {
+	result := fileResult{path: location}
+
 	// Get just the filename (not the full path)
-	filename := filepath.Base(path)
+	filename := path.Base(name)
 
 	// Skip tier definition files
 	if filename == "00_tier.txt" {
-		return nil
+		return result
 	}
 
-	file, err := os.Open(path)
+	// ReadFile sizes the buffer from the file's size where fsys reports it
+	data, err := fs.ReadFile(fsys, name)
 	if err != nil {
-		return err
+		result.err = err
+		return result
+	}
+	// The tokenizer skips comments and indentation itself, so the content
+	// is only split into lines for the brace diagnostics
+	content := string(charset.Decode(data))
+	result.diagnostics = checkBraces(filename, sourceLines(content))
+	if len(result.diagnostics) > 0 {
+		result.err = &ParseError{File: filename, Diagnostics: result.diagnostics}
+		if p.strict {
+			return result
+		}
 	}
-	defer file.Close()
 
-	content, err := readFileContent(file)
-	if err != nil {
-		return err
+	result.technologies = p.parseContent(content, filename)
+	if p.keepSource {
+		source := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+		p.addSources(result.technologies, content, source, filename)
 	}
+	return result
+}
 
-	techs := p.parseContent(content, filename)
-	for key, tech := range techs {
+// mergeResult adds the technologies and diagnostics of a parsed file to the
+// parser and returns the file's error, if any
+func (p *TechParser) mergeResult(result fileResult) error {
+	// Merge in key order so override records are stable
+	keys := make([]string, 0, len(result.technologies))
+	for key := range result.technologies {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.diagnostics = append(p.diagnostics, result.diagnostics...)
+	for _, key := range keys {
+		tech := result.technologies[key]
+		tech.Mod = p.mod
+		if existing, exists := p.technologies[key]; exists {
+			p.overrides = append(p.overrides, models.Override{
+				Key:          key,
+				Definition:   models.Definition{SourceFile: existing.SourceFile, Mod: existing.Mod},
+				OverriddenBy: models.Definition{SourceFile: tech.SourceFile, Mod: tech.Mod},
+			})
+		}
 		p.technologies[key] = tech
 	}
+	return result.err
+}
 
-	return nil
+// readFileContent reads and preprocesses file content, converted to UTF-8
+func readFileContent(file io.Reader) (string, error) {
+	file, err := charset.NewReader(file)
+	if err != nil {
+		return "", err
+	}
+	lines, err := readSourceLines(file)
+	if err != nil {
+		return "", err
+	}
+	return joinTrimmedLines(lines), nil
 }
 
-// readFileContent reads and preprocesses file content
-func readFileContent(file *os.File) (string, error) {
-	scanner := bufio.NewScanner(file)
+// readSourceLines reads a file line by line and removes comments while
+// keeping indentation, so positions in the result match the original file
+func readSourceLines(file io.Reader) ([]string, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return sourceLines(string(data)), nil
+}
+
+// sourceLines splits content into lines without their comments, see
+// readSourceLines. The lines share the memory of content.
+func sourceLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = charset.StripComment(strings.TrimSuffix(line, "\r"))
+	}
+	return lines
+}
+
+// joinTrimmedLines trims every line and joins them back together.
+// Blank lines are kept so line numbers stay aligned with the source file.
+func joinTrimmedLines(lines []string) string {
 	var content strings.Builder
+	size := 0
+	for _, line := range lines {
+		size += len(line) + 1
+	}
+	content.Grow(size)
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		// Remove comments
-		if idx := strings.Index(line, "#"); idx != -1 {
-			line = line[:idx]
-		}
-		line = strings.TrimSpace(line)
-		if line != "" {
-			content.WriteString(line)
-			content.WriteString("\n")
-		}
+	for _, line := range lines {
+		content.WriteString(strings.TrimSpace(line))
+		content.WriteString("\n")
 	}
 
-	return content.String(), scanner.Err()
+	return content.String()
 }
 
 // parseContent parses the preprocessed content
 func (p *TechParser) parseContent(content string, filename string) map[string]*models.Technology {
 	techs := make(map[string]*models.Technology)
 
-	// Split into top-level blocks
-	blocks := p.extractTopLevelBlocks(content)
-
-	for key, blockContent := range blocks {
-		tech := p.parseTechnologyBlock(key, blockContent)
+	for key, block := range topLevelBlocksIn(resolvedStatements(content, p.variables)) {
+		tech := p.parseTechnologyBlock(key, block.body)
 		tech.SourceFile = filename
 		techs[key] = tech
 	}
@@ -107,66 +513,173 @@ func (p *TechParser) parseContent(content string, filename string) map[string]*m
 	return techs
 }
 
-// extractTopLevelBlocks extracts technology definition blocks
-func (p *TechParser) extractTopLevelBlocks(content string) map[string]string {
-	blocks := make(map[string]string)
+// extractPrerequisiteGroups returns the keys listed in each non-empty
+// top-level prerequisites block of a technology, in source order. Repeated blocks
+// can't be read from the parsed map because later keys replace earlier ones.
+func (p *TechParser) extractPrerequisiteGroups(body []statement) [][]string {
+	var groups [][]string
+
+	for _, st := range body {
+		if st.key != "prerequisites" || !st.isBlock {
+			continue
+		}
+		group := make([]string, 0, len(st.children))
+		for _, child := range st.children {
+			if child.key != "" || child.isBlock {
+				continue
+			}
+			if strings.HasPrefix(child.value, "\"") {
+				group = append(group, strings.Trim(child.value, "\""))
+			} else if str, ok := p.parseValue(child.value).(string); ok {
+				group = append(group, str)
+			}
+		}
+		if len(group) > 0 {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups
+}
 
-	// Pattern to match tech_name = { ... }
-	pattern := regexp.MustCompile(`(\w+)\s*=\s*\{`)
+var (
+	scriptedReferencePattern = regexp.MustCompile(`@(\w+)`)
+)
 
-	lines := strings.Split(content, "\n")
-	var currentKey string
-	var currentBlock strings.Builder
-	braceDepth := 0
-	inBlock := false
+// extractScriptedVariables returns the scripted variables (@name = value)
+// defined at the top level of a file
+func extractScriptedVariables(content string) map[string]string {
+	return scriptedVariables(tokenize(content))
+}
 
-	for _, line := range lines {
-		if matches := pattern.FindStringSubmatch(line); matches != nil && braceDepth == 0 {
-			// Save previous block if exists
-			if inBlock && currentKey != "" {
-				blocks[currentKey] = currentBlock.String()
+// scriptedVariables returns the scripted variables defined at the top level
+// of the tokens of a file
+func scriptedVariables(tokens []token) map[string]string {
+	variables := make(map[string]string)
+
+	depth := 0
+	for i, t := range tokens {
+		switch t.kind {
+		case tokenOpen:
+			depth++
+		case tokenClose:
+			depth = max(depth-1, 0)
+		case tokenWord:
+			name, ok := strings.CutPrefix(t.text, "@")
+			if !ok || name == "" || depth > 0 || i+2 >= len(tokens) || tokens[i+1].text != "=" {
+				continue
 			}
-
-			currentKey = matches[1]
-			currentBlock.Reset()
-			inBlock = true
-
-			// Count braces in this line
-			braceDepth += strings.Count(line, "{") - strings.Count(line, "}")
-		} else if inBlock {
-			currentBlock.WriteString(line)
-			currentBlock.WriteString("\n")
-			braceDepth += strings.Count(line, "{") - strings.Count(line, "}")
-
-			if braceDepth == 0 {
-				blocks[currentKey] = currentBlock.String()
-				inBlock = false
-				currentKey = ""
-				currentBlock.Reset()
+			if value := tokens[i+2]; value.kind == tokenWord || value.kind == tokenString {
+				variables[name] = value.text
 			}
 		}
 	}
 
-	// Save last block if exists
-	if inBlock && currentKey != "" {
-		blocks[currentKey] = currentBlock.String()
+	return variables
+}
+
+// resolveScriptedVariables replaces references to scripted variables defined
+// in the same file with their values. Unknown references are left as they are.
+func resolveScriptedVariables(content string, variables map[string]string) string {
+	if len(variables) == 0 {
+		return content
+	}
+	return scriptedReferencePattern.ReplaceAllStringFunc(content, func(reference string) string {
+		if value, ok := variables[reference[1:]]; ok {
+			return value
+		}
+		return reference
+	})
+}
+
+// resolvedStatements returns the statements of a file with the references
+// to its scripted variables, or else to the given ones, replaced by their
+// values. The file is tokenized once and the references are resolved token
+// by token, so the text of blocks keeps them.
+func resolvedStatements(content string, global map[string]string) []statement {
+	tokens, release := pooledTokens(content)
+	defer release()
+	variables := scriptedVariables(tokens)
+	if len(variables) == 0 && len(global) == 0 {
+		return readStatements(content, tokens)
+	}
+	for i, t := range tokens {
+		name, ok := strings.CutPrefix(t.text, "@")
+		if !ok || t.kind != tokenWord {
+			continue
+		}
+		if value, ok := variables[name]; ok {
+			tokens[i].text = value
+		} else if value, ok := global[name]; ok {
+			tokens[i].text = value
+		}
+	}
+	return readStatements(content, tokens)
+}
+
+// topLevelBlock is a top-level block of a script file
+type topLevelBlock struct {
+	content   string      // Text between the braces
+	body      []statement // Statements of the block
+	startLine int         // 1-based line opening the block
+	endLine   int         // 1-based line closing the block
+}
+
+// topLevelBlocks extracts the top-level blocks of a file with the lines they
+// span, however the blocks are spread over lines. A key defined twice keeps
+// its last block.
+func (p *TechParser) topLevelBlocks(content string) map[string]topLevelBlock {
+	return topLevelBlocksIn(statements(content))
+}
+
+// topLevelBlocksIn returns the blocks of the top-level statements of a file,
+// see topLevelBlocks
+func topLevelBlocksIn(body []statement) map[string]topLevelBlock {
+	blocks := make(map[string]topLevelBlock)
+
+	for _, st := range body {
+		if st.isBlock && st.hasIdentifierKey() {
+			blocks[st.key] = topLevelBlock{content: st.block, body: st.children, startLine: st.line, endLine: st.endLine}
+		}
 	}
 
 	return blocks
 }
 
-// parseTechnologyBlock parses a single technology block
-func (p *TechParser) parseTechnologyBlock(key, content string) *models.Technology {
+// addSources sets the original script text and line span of each
+// technology from the lines of the file, comments included
+func (p *TechParser) addSources(technologies map[string]*models.Technology, content string, source []string, filename string) {
+	for key, block := range p.topLevelBlocks(content) {
+		tech, ok := technologies[key]
+		if !ok {
+			continue
+		}
+		end := min(block.endLine, len(source))
+		tech.Source = &models.Source{
+			File:      filename,
+			StartLine: block.startLine,
+			EndLine:   end,
+			Text:      strings.Join(source[block.startLine-1:end], "\n"),
+		}
+	}
+}
+
+// parseTechnologyBlock parses the statements of a single technology block
+func (p *TechParser) parseTechnologyBlock(key string, body []statement) *models.Technology {
 	tech := &models.Technology{
-		Key:             key,
-		Prerequisites:   []string{},
-		Category:        []string{},
-		FeatureUnlocks:  []string{},
-		WeightModifiers: []models.WeightModifier{},
+		Key:               key,
+		Prerequisites:     []string{},
+		Category:          []string{},
+		FeatureUnlocks:    []string{},
+		WeightGroups:      []string{},
+		WeightModifiers:   []models.WeightModifier{},
+		AIWeightModifiers: []models.WeightModifier{},
 	}
 
-	// Parse the block as a map
-	data := p.parseBlock(content)
+	// Parse the block as a map. Weight blocks are the bulk of most
+	// technologies and are read into modifiers below, and prerequisites are
+	// read by group, so they are left out.
+	data := p.parseFields(body, statementBlocks)
 
 	// Extract simple fields
 	if cost, ok := data["cost"].(int); ok {
@@ -190,6 +703,7 @@ func (p *TechParser) parseTechnologyBlock(key, content string) *models.Technolog
 	tech.IsDangerous = p.getBool(data, "is_dangerous")
 	tech.IsRare = p.getBool(data, "is_rare")
 	tech.IsEvent = p.getBool(data, "is_event_tech")
+	tech.IsInsight = p.getBool(data, "is_insight")
 	tech.IsReverse = p.getBool(data, "is_reverse_engineerable")
 	tech.IsRepeatable = p.getBool(data, "is_repeatable")
 	tech.IsGestalt = p.getBool(data, "is_gestalt")
@@ -203,6 +717,9 @@ func (p *TechParser) parseTechnologyBlock(key, content string) *models.Technolog
 	if levels, ok := data["levels"].(int); ok {
 		tech.Levels = levels
 	}
+	if costPerLevel, ok := data["cost_per_level"].(int); ok {
+		tech.CostPerLevel = costPerLevel
+	}
 
 	// String fields
 	if aiUpdateType, ok := data["ai_update_type"].(string); ok {
@@ -218,16 +735,19 @@ func (p *TechParser) parseTechnologyBlock(key, content string) *models.Technolog
 		tech.Icon = key
 	}
 
-	// Array fields
-	if prereqs, ok := data["prerequisites"].([]interface{}); ok {
-		for _, p := range prereqs {
-			if str, ok := p.(string); ok {
-				tech.Prerequisites = append(tech.Prerequisites, str)
+	// Array fields. Each prerequisites block is a group of its own; with
+	// several blocks any one group satisfies the requirement.
+	tech.PrerequisiteGroups = p.extractPrerequisiteGroups(body)
+	for _, group := range tech.PrerequisiteGroups {
+		for _, prereq := range group {
+			if !slices.Contains(tech.Prerequisites, prereq) {
+				tech.Prerequisites = append(tech.Prerequisites, prereq)
 			}
 		}
 	}
 
 	if categories, ok := data["category"].([]interface{}); ok {
+		tech.Category = make([]string, 0, len(categories))
 		for _, c := range categories {
 			if str, ok := c.(string); ok {
 				tech.Category = append(tech.Category, str)
@@ -235,6 +755,18 @@ func (p *TechParser) parseTechnologyBlock(key, content string) *models.Technolog
 		}
 	}
 
+	if groups, ok := data["weight_groups"].([]interface{}); ok {
+		tech.WeightGroups = stringList(groups)
+	}
+	if factors, ok := data["mod_weight_if_group_picked"].(map[string]interface{}); ok {
+		tech.ModWeightIfGroupPicked = make(map[string]float64)
+		for group, value := range factors {
+			if factor, ok := number(value); ok {
+				tech.ModWeightIfGroupPicked[group] = factor
+			}
+		}
+	}
+
 	if features, ok := data["feature_unlocks"].([]interface{}); ok {
 		for _, f := range features {
 			if str, ok := f.(string); ok {
@@ -243,9 +775,18 @@ func (p *TechParser) parseTechnologyBlock(key, content string) *models.Technolog
 		}
 	}
 
-	// Parse weight_modifiers
-	if modifiers, ok := data["weight_modifiers"].(map[string]interface{}); ok {
-		tech.WeightModifiers = p.parseWeightModifiers(modifiers)
+	// Repeated modifier blocks can't be read from the parsed map; the
+	// modifiers of every block are kept, in file order
+	for _, st := range body {
+		if !st.isBlock {
+			continue
+		}
+		switch st.key {
+		case "weight_modifier", "weight_modifiers":
+			tech.WeightModifiers = append(tech.WeightModifiers, p.parseWeightModifiers(st.children)...)
+		case "ai_weight":
+			tech.AIWeightModifiers = append(tech.AIWeightModifiers, p.parseWeightModifiers(st.children)...)
+		}
 	}
 
 	// Parse potential
@@ -253,127 +794,210 @@ func (p *TechParser) parseTechnologyBlock(key, content string) *models.Technolog
 		tech.Potential = p.parseCondition(potential)
 	}
 
+	tech.Extra = extractExtra(data)
+	tech.ExtraFlags = extraFlags(tech.Extra)
+
 	return tech
 }
 
-// parseBlock parses a block of content into a map
-func (p *TechParser) parseBlock(content string) map[string]interface{} {
-	result := make(map[string]interface{})
+// statementBlocks are the technology blocks read from their statements by
+// parseWeightModifiers and extractPrerequisiteGroups rather than from the
+// parsed map
+var statementBlocks = map[string]bool{"weight_modifier": true, "weight_modifiers": true, "ai_weight": true, "prerequisites": true}
+
+// knownTechKeys are the technology keys handled by parseTechnologyBlock, or
+// vanilla blocks that are deliberately not exported
+var knownTechKeys = map[string]bool{
+	"cost": true, "area": true, "tier": true, "weight": true, "base_weight": true,
+	"start_tech": true, "is_dangerous": true, "is_rare": true, "is_event_tech": true,
+	"is_insight": true, "is_reverse_engineerable": true, "is_repeatable": true,
+	"is_gestalt": true, "is_megacorp": true, "is_machine_empire": true, "is_hive_empire": true,
+	"is_drive_assimilator": true, "is_rogue_servitor": true, "levels": true,
+	"cost_per_level": true, "ai_update_type": true, "gateway": true, "icon": true,
+	"prerequisites": true, "category": true, "feature_unlocks": true,
+	"weight_modifier": true, "weight_modifiers": true, "potential": true, "modifier": true, "ai_weight": true,
+	"weight_groups": true, "mod_weight_if_group_picked": true, "prereqfor_desc": true,
+}
 
-	lines := strings.Split(content, "\n")
-	i := 0
+// technologyFlagKey is collected from nested blocks as well as the top level
+const technologyFlagKey = "set_technology_flag"
 
-	for i < len(lines) {
-		line := strings.TrimSpace(lines[i])
-		if line == "" || line == "}" {
-			i++
+// extractExtra returns every top-level key of a technology block that is
+// not modelled, with its value as parsed, so mods and new game versions can
+// add fields without losing them. Returns nil when there are none.
+func extractExtra(data map[string]interface{}) map[string]interface{} {
+	var extra map[string]interface{}
+	for key, value := range data {
+		if knownTechKeys[key] {
 			continue
 		}
+		if extra == nil {
+			extra = make(map[string]interface{})
+		}
+		extra[key] = value
+	}
+	return extra
+}
+
+// extraFlags returns the keys of extra as exported in extraFlags: repeated
+// scalar keys become a list of their values, and set_technology_flag effects
+// are collected from any depth into a sorted list. Returns nil when extra is
+// empty.
+func extraFlags(extra map[string]interface{}) map[string]interface{} {
+	if len(extra) == 0 {
+		return nil
+	}
 
-		// Check for key = value or key = { block }
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			i++
+	flags := make(map[string]interface{}, len(extra))
+	for key, value := range extra {
+		if key == technologyFlagKey {
 			continue
 		}
+		if repeated, ok := value.(models.Repeated); ok && isScalarList(repeated) {
+			value = []interface{}(repeated)
+		}
+		flags[key] = value
+	}
 
-		key := strings.TrimSpace(parts[0])
-		valuePart := strings.TrimSpace(parts[1])
+	if technologyFlags := collectTechnologyFlags(extra, nil); len(technologyFlags) > 0 {
+		sort.Strings(technologyFlags)
+		flags[technologyFlagKey] = technologyFlags
+	}
+	if len(flags) == 0 {
+		return nil
+	}
+	return flags
+}
 
-		// Check if it's a block
-		if strings.HasPrefix(valuePart, "{") {
-			// Extract the block
-			blockContent, newIndex := p.extractBlock(lines, i)
-			i = newIndex
+// isScalarList reports whether every value is a bool, number or string
+func isScalarList(values []interface{}) bool {
+	for _, value := range values {
+		switch value.(type) {
+		case bool, int, float64, string:
+		default:
+			return false
+		}
+	}
+	return true
+}
 
-			// Parse the block
-			if p.isArray(blockContent) {
-				result[key] = p.parseArray(blockContent)
-			} else {
-				result[key] = p.parseBlock(blockContent)
+// collectTechnologyFlags appends the values of set_technology_flag keys
+// found anywhere in data
+func collectTechnologyFlags(data map[string]interface{}, flags []string) []string {
+	for key, entry := range data {
+		for _, value := range models.Values(entry) {
+			switch v := value.(type) {
+			case map[string]interface{}:
+				flags = collectTechnologyFlags(v, flags)
+			case string:
+				if key == technologyFlagKey {
+					flags = append(flags, v)
+				}
 			}
-		} else {
-			// Simple value
-			result[key] = p.parseValue(valuePart)
-			i++
 		}
 	}
+	return flags
+}
 
-	return result
+// parseBlock parses a block of content into a map. Keys are read
+// token by token, so blocks may be spread over lines or written on one.
+func (p *TechParser) parseBlock(content string) map[string]interface{} {
+	return p.parseStatements(statements(content))
 }
 
-// extractBlock extracts a { ... } block starting from the current line
-// Returns the content WITHOUT the outer braces
-func (p *TechParser) extractBlock(lines []string, startIndex int) (string, int) {
-	var block strings.Builder
-	braceDepth := 0
-	started := false
-	firstBrace := true
-
-	for i := startIndex; i < len(lines); i++ {
-		line := lines[i]
-
-		for _, char := range line {
-			if char == '{' {
-				braceDepth++
-				started = true
-				// Skip the first opening brace
-				if firstBrace {
-					firstBrace = false
-					continue
-				}
-			} else if char == '}' {
-				braceDepth--
-				// Skip the last closing brace
-				if braceDepth == 0 {
-					return block.String(), i + 1
-				}
-			}
+// parseStatements converts the statements of a block into a map
+func (p *TechParser) parseStatements(body []statement) map[string]interface{} {
+	return p.parseFields(body, nil)
+}
 
-			if started && braceDepth > 0 {
-				block.WriteRune(char)
-			}
-		}
+// parseFields converts the statements of a block into a map, leaving out
+// the keys in skip
+func (p *TechParser) parseFields(body []statement, skip map[string]bool) map[string]interface{} {
+	result := make(map[string]interface{}, len(body))
 
-		if started && braceDepth > 0 {
-			block.WriteRune('\n')
+	for _, st := range body {
+		// Bare values only belong in arrays
+		if st.key == "" || skip[st.key] {
+			continue
 		}
+
+		addValue(result, st.key, p.statementValue(st))
+	}
+
+	return result
+}
+
+// statementValue returns the value of a keyed statement in a parsed block: a
+// list or map for a block, a models.Comparison for a comparison, or else the
+// parsed scalar
+func (p *TechParser) statementValue(st statement) interface{} {
+	switch {
+	case st.isBlock && isList(st.children):
+		return p.listValues(st.children)
+	case st.isBlock:
+		return p.parseStatements(st.children)
+	case st.operator != "=":
+		return models.Comparison{Operator: st.operator, Value: p.parseValue(st.value)}
 	}
+	return p.parseValue(st.value)
+}
 
-	return block.String(), len(lines)
+// addValue sets key in a parsed block, collecting the values of a key that
+// is already set into a models.Repeated
+func addValue(block map[string]interface{}, key string, value interface{}) {
+	existing, exists := block[key]
+	if !exists {
+		block[key] = value
+		return
+	}
+	if repeated, ok := existing.(models.Repeated); ok {
+		block[key] = append(repeated, value)
+		return
+	}
+	block[key] = models.Repeated{existing, value}
 }
 
 // isArray checks if a block represents an array
 func (p *TechParser) isArray(content string) bool {
 	// Remove braces and whitespace
-	content = strings.Trim(content, "{} \n\t")
+	return isList(statements(strings.Trim(content, "{} \n\t")))
+}
 
-	// If it contains = it's likely a map, not an array
-	return !strings.Contains(content, "=")
+// isList reports whether the statements of a block are all bare values. A
+// key = value or a comparison makes the block a map.
+func isList(body []statement) bool {
+	for _, st := range body {
+		if st.key != "" {
+			return false
+		}
+	}
+	return true
 }
 
 // parseArray parses an array block
 func (p *TechParser) parseArray(content string) []interface{} {
-	var result []interface{}
-
 	// Remove outer braces
-	content = strings.Trim(content, "{} \n\t")
-
-	// Split by quotes and spaces
-	stringPattern := regexp.MustCompile(`"([^"]+)"`)
-	matches := stringPattern.FindAllStringSubmatch(content, -1)
+	return p.listValues(statements(strings.Trim(content, "{} \n\t")))
+}
 
-	for _, match := range matches {
-		if len(match) > 1 {
-			result = append(result, match[1])
-		}
+// listValues returns the bare values of a block. Quoted items stay strings
+// and nested blocks become nested lists.
+func (p *TechParser) listValues(body []statement) []interface{} {
+	if len(body) == 0 {
+		return nil
 	}
+	result := make([]interface{}, 0, len(body))
 
-	// If no quoted strings found, try splitting by whitespace
-	if len(result) == 0 {
-		parts := strings.Fields(content)
-		for _, part := range parts {
-			result = append(result, p.parseValue(part))
+	for _, st := range body {
+		switch {
+		case st.key != "":
+			continue
+		case st.isBlock:
+			result = append(result, p.listValues(st.children))
+		case strings.HasPrefix(st.value, "\""):
+			result = append(result, strings.Trim(st.value, "\""))
+		default:
+			result = append(result, p.parseValue(st.value))
 		}
 	}
 
@@ -400,9 +1024,18 @@ func (p *TechParser) parseValue(value string) interface{} {
 		return false
 	}
 
-	// Integer
-	if intVal, err := strconv.Atoi(value); err == nil {
-		return intVal
+	// Keys such as physics are far more common than numbers, and failed
+	// conversions allocate an error
+	if value == "" || !strings.ContainsRune("0123456789+-.", rune(value[0])) {
+		return value
+	}
+
+	// Integer. Values with a decimal point or an exponent go straight to
+	// the float conversion, as a failed Atoi allocates an error too.
+	if !strings.ContainsAny(value, ".eE") {
+		if intVal, err := strconv.Atoi(value); err == nil {
+			return intVal
+		}
 	}
 
 	// Float
@@ -427,35 +1060,9 @@ func (p *TechParser) getBool(data map[string]interface{}, key string) bool {
 	return false
 }
 
-// parseWeightModifiers parses weight_modifiers block
-func (p *TechParser) parseWeightModifiers(data map[string]interface{}) []models.WeightModifier {
-	var modifiers []models.WeightModifier
-
-	// Weight modifiers can have factor, add, and various conditions
-	if factor, ok := data["factor"]; ok {
-		mod := models.WeightModifier{}
-		if f, ok := factor.(float64); ok {
-			mod.Factor = f
-		} else if i, ok := factor.(int); ok {
-			mod.Factor = float64(i)
-		}
-		modifiers = append(modifiers, mod)
-	}
-
-	if add, ok := data["add"]; ok {
-		mod := models.WeightModifier{}
-		if a, ok := add.(float64); ok {
-			mod.Add = a
-		} else if i, ok := add.(int); ok {
-			mod.Add = float64(i)
-		}
-		modifiers = append(modifiers, mod)
-	}
-
-	return modifiers
-}
-
-// parseCondition parses a condition block
+// parseCondition parses a condition block. Children are sorted by key, and a
+// simple condition with several keys uses the first, so the result is the
+// same on every run.
 func (p *TechParser) parseCondition(data map[string]interface{}) *models.Condition {
 	condition := &models.Condition{
 		Children: []models.Condition{},
@@ -463,52 +1070,88 @@ func (p *TechParser) parseCondition(data map[string]interface{}) *models.Conditi
 	}
 
 	// Check for logical operators
-	if andBlock, ok := data["AND"].(map[string]interface{}); ok {
-		condition.Type = "AND"
-		for key, val := range andBlock {
-			child := &models.Condition{
-				Key:   key,
-				Value: val,
-			}
-			condition.Children = append(condition.Children, *child)
-		}
-	} else if orBlock, ok := data["OR"].(map[string]interface{}); ok {
-		condition.Type = "OR"
-		for key, val := range orBlock {
-			child := &models.Condition{
-				Key:   key,
-				Value: val,
-			}
-			condition.Children = append(condition.Children, *child)
-		}
-	} else if notBlock, ok := data["NOT"].(map[string]interface{}); ok {
-		condition.Type = "NOT"
-		for key, val := range notBlock {
-			child := &models.Condition{
-				Key:   key,
-				Value: val,
-			}
-			condition.Children = append(condition.Children, *child)
+	for _, operator := range []string{"AND", "OR", "NOT"} {
+		block, ok := lastValue(data[operator]).(map[string]interface{})
+		if !ok {
+			continue
 		}
-	} else {
-		// Simple condition
-		for key, val := range data {
-			condition.Key = key
-			condition.Value = val
-			break
+		condition.Type = operator
+		condition.Children = make([]models.Condition, 0, len(block))
+		// A repeated key such as has_technology is a condition per value
+		for _, key := range sortedKeys(block) {
+			for _, entry := range models.Values(block[key]) {
+				value, operator := conditionValue(entry)
+				condition.Children = append(condition.Children, models.Condition{
+					Key:      key,
+					Value:    value,
+					Operator: operator,
+				})
+			}
 		}
+		return condition
+	}
+
+	// Simple condition
+	if keys := sortedKeys(data); len(keys) > 0 {
+		condition.Key = keys[0]
+		condition.Value, condition.Operator = conditionValue(models.Values(data[keys[0]])[0])
 	}
 
 	return condition
 }
 
-// GetTechnologies returns all parsed technologies
+// lastValue returns the last value of a repeated key in a parsed block, or
+// else the value itself
+func lastValue(value interface{}) interface{} {
+	values := models.Values(value)
+	return values[len(values)-1]
+}
+
+// conditionValue returns the value and operator of a condition in a parsed
+// block, unwrapping comparisons
+func conditionValue(value interface{}) (interface{}, string) {
+	if comparison, ok := value.(models.Comparison); ok {
+		return comparison.Value, comparison.Operator
+	}
+	return value, "="
+}
+
+// sortedKeys returns the keys of a block in lexical order
+func sortedKeys(block map[string]interface{}) []string {
+	keys := make([]string, 0, len(block))
+	for key := range block {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GetTechnologies returns a copy of the map of all parsed technologies
 func (p *TechParser) GetTechnologies() map[string]*models.Technology {
-	return p.technologies
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return maps.Clone(p.technologies)
+}
+
+// GetOverrides returns every technology definition that was replaced by a
+// later one, in the order the replacements happened
+func (p *TechParser) GetOverrides() []models.Override {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return slices.Clone(p.overrides)
+}
+
+// GetDiagnostics returns all problems found in the files parsed so far
+func (p *TechParser) GetDiagnostics() []Diagnostic {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return slices.Clone(p.diagnostics)
 }
 
 // GetTechnology returns a specific technology by key
 func (p *TechParser) GetTechnology(key string) (*models.Technology, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	tech, exists := p.technologies[key]
 	return tech, exists
 }
@@ -1,11 +1,21 @@
 package parser
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
 
-	"stellaris-data-parser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/progress"
 )
 
 func TestNewTechParser(t *testing.T) {
@@ -373,8 +383,11 @@ func TestParseDirectoryNonExistent(t *testing.T) {
 	parser := NewTechParser()
 
 	err := parser.ParseDirectory("/nonexistent/path")
-	if err == nil {
-		t.Error("Expected error when parsing non-existent directory")
+	if !errors.Is(err, ErrNoTechDirectory) {
+		t.Errorf("Expected ErrNoTechDirectory when parsing non-existent directory, got %v", err)
+	}
+	if err := parser.ParseFSStream(fstest.MapFS{}, "technology", func(*models.Technology) error { return nil }); !errors.Is(err, ErrNoTechDirectory) {
+		t.Errorf("Expected ErrNoTechDirectory when streaming a non-existent directory, got %v", err)
 	}
 }
 
@@ -450,3 +463,709 @@ tier_1 = {
 		t.Errorf("Expected 0 technologies from tier file, got %d", len(techs))
 	}
 }
+
+func TestParseRepeatableTech(t *testing.T) {
+	parser := NewTechParser()
+
+	testdataPath, err := filepath.Abs("../../testdata/common/technology/00_repeatable_tech.txt")
+	if err != nil {
+		t.Fatalf("Failed to get testdata path: %v", err)
+	}
+
+	if err := parser.ParseFile(testdataPath); err != nil {
+		t.Fatalf("Failed to parse repeatable tech file: %v", err)
+	}
+
+	tech, exists := parser.GetTechnology("tech_repeatable_weapon_damage")
+	if !exists {
+		t.Fatal("Expected to find tech_repeatable_weapon_damage")
+	}
+	if !tech.IsRepeatable {
+		t.Error("Expected IsRepeatable to be true")
+	}
+	if tech.Levels != -1 {
+		t.Errorf("Expected levels -1, got %d", tech.Levels)
+	}
+	if tech.CostPerLevel != 10000 {
+		t.Errorf("Expected cost per level 10000, got %d", tech.CostPerLevel)
+	}
+	if !tech.IsInfinite() {
+		t.Error("Expected tech_repeatable_weapon_damage to be infinite")
+	}
+
+	limited, exists := parser.GetTechnology("tech_repeatable_limited")
+	if !exists {
+		t.Fatal("Expected to find tech_repeatable_limited")
+	}
+	if limited.Levels != 3 {
+		t.Errorf("Expected levels 3, got %d", limited.Levels)
+	}
+	if limited.IsInfinite() {
+		t.Error("Expected tech_repeatable_limited not to be infinite")
+	}
+}
+
+func TestParseDirectoryConcurrentMergeOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Both files define tech_shared; the lexically later file must win
+	files := map[string]string{
+		"00_base.txt": "tech_shared = {\n\tcost = 100\n}\ntech_base_only = {\n\tcost = 1\n}\n",
+		"99_mod.txt":  "tech_shared = {\n\tcost = 999\n}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	for _, workers := range []int{1, 2, 8} {
+		parser := NewTechParser()
+		parser.SetWorkers(workers)
+
+		if err := parser.ParseDirectory(tmpDir); err != nil {
+			t.Fatalf("Failed to parse directory with %d workers: %v", workers, err)
+		}
+
+		tech, exists := parser.GetTechnology("tech_shared")
+		if !exists {
+			t.Fatalf("Expected tech_shared with %d workers", workers)
+		}
+		if tech.Cost != 999 || tech.SourceFile != "99_mod.txt" {
+			t.Errorf("Expected 99_mod.txt definition to win with %d workers, got cost %d from %s",
+				workers, tech.Cost, tech.SourceFile)
+		}
+		if len(parser.GetTechnologies()) != 2 {
+			t.Errorf("Expected 2 technologies with %d workers, got %d", workers, len(parser.GetTechnologies()))
+		}
+	}
+}
+
+// writeBenchmarkCorpus writes a synthetic technology directory roughly the
+// size of the vanilla game (around 30 files, 1000+ technologies)
+func writeBenchmarkCorpus(b *testing.B) string {
+	dir := b.TempDir()
+	for f := 0; f < 30; f++ {
+		var content strings.Builder
+		for i := 0; i < 40; i++ {
+			fmt.Fprintf(&content, `tech_bench_%d_%d = {
+	cost = %d
+	area = physics
+	tier = %d
+	category = { particles }
+	prerequisites = { "tech_bench_%d_%d" }
+	weight = 50
+	potential = {
+		AND = {
+			is_gestalt = no
+			has_technology = "tech_bench_%d_0"
+		}
+	}
+	weight_modifiers = {
+		factor = 1.5
+		add = 10
+	}
+}
+`, f, i, i*100, i%5, f, i-1, f)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("%02d_bench.txt", f))
+		if err := os.WriteFile(path, []byte(content.String()), 0644); err != nil {
+			b.Fatalf("Failed to write benchmark file: %v", err)
+		}
+	}
+	return dir
+}
+
+func BenchmarkParseDirectory(b *testing.B) {
+	dir := writeBenchmarkCorpus(b)
+
+	for _, workers := range []int{1, runtime.NumCPU()} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				parser := NewTechParser()
+				parser.SetWorkers(workers)
+				if err := parser.ParseDirectory(dir); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestParseModDirectoryOverrides(t *testing.T) {
+	baseDir := t.TempDir()
+	modDir := t.TempDir()
+
+	base := "tech_shared = {\n\tcost = 100\n}\ntech_base_only = {\n\tcost = 1\n}\n"
+	mod := "tech_shared = {\n\tcost = 999\n}\ntech_mod_only = {\n\tcost = 2\n}\n"
+	if err := os.WriteFile(filepath.Join(baseDir, "00_base.txt"), []byte(base), 0644); err != nil {
+		t.Fatalf("Failed to write base file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(modDir, "mod_techs.txt"), []byte(mod), 0644); err != nil {
+		t.Fatalf("Failed to write mod file: %v", err)
+	}
+
+	parser := NewTechParser()
+	if err := parser.ParseDirectory(baseDir); err != nil {
+		t.Fatalf("Failed to parse base directory: %v", err)
+	}
+	if err := parser.ParseModDirectory(modDir, "my_mod"); err != nil {
+		t.Fatalf("Failed to parse mod directory: %v", err)
+	}
+
+	shared, _ := parser.GetTechnology("tech_shared")
+	if shared.Cost != 999 || shared.Mod != "my_mod" {
+		t.Errorf("Expected mod definition to win, got cost %d from mod %q", shared.Cost, shared.Mod)
+	}
+
+	baseOnly, _ := parser.GetTechnology("tech_base_only")
+	if baseOnly.Mod != "" {
+		t.Errorf("Expected base technology to have no mod, got %q", baseOnly.Mod)
+	}
+
+	overrides := parser.GetOverrides()
+	if len(overrides) != 1 {
+		t.Fatalf("Expected 1 override, got %d", len(overrides))
+	}
+
+	override := overrides[0]
+	if override.Key != "tech_shared" {
+		t.Errorf("Expected override of tech_shared, got %s", override.Key)
+	}
+	if override.Definition.SourceFile != "00_base.txt" || override.Definition.Mod != "" {
+		t.Errorf("Unexpected overridden definition: %+v", override.Definition)
+	}
+	if override.OverriddenBy.SourceFile != "mod_techs.txt" || override.OverriddenBy.Mod != "my_mod" {
+		t.Errorf("Unexpected overriding definition: %+v", override.OverriddenBy)
+	}
+}
+
+func TestParseDirectoryProgress(t *testing.T) {
+	var buf strings.Builder
+	parser := NewTechParser()
+	parser.SetWorkers(4)
+	parser.SetProgress(progress.NewReporter(&buf))
+
+	if err := parser.ParseDirectory("../../testdata/common/technology"); err != nil {
+		t.Fatalf("Failed to parse directory: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	files, _ := filepath.Glob("../../testdata/common/technology/*.txt")
+	if len(lines) != len(files) {
+		t.Fatalf("Expected one event per file (%d), got %d", len(files), len(lines))
+	}
+
+	// Events count up even though files finish in any order
+	last := fmt.Sprintf(`{"stage":"parse","current":%d,"total":%d,`, len(files), len(files))
+	if !strings.HasPrefix(lines[len(lines)-1], last) {
+		t.Errorf("Expected last event to start with %s, got %s", last, lines[len(lines)-1])
+	}
+}
+
+func TestParseExtraFlags(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	content := `tech_modded = {
+	cost = 100
+	area = physics
+	is_insight = yes
+	my_mod_hidden = yes
+	my_mod_priority = 3
+	custom_tags = { alpha beta }
+	modifier = { ship_speed_mult = 0.1 }
+	set_technology_flag = top_flag
+	on_research = {
+		set_technology_flag = nested_flag
+	}
+}
+tech_plain = {
+	cost = 100
+}
+`
+	path := filepath.Join(tmpDir, "00_modded.txt")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	parser := NewTechParser()
+	if err := parser.ParseFile(path); err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	tech, _ := parser.GetTechnology("tech_modded")
+	if tech.ExtraFlags["my_mod_hidden"] != true {
+		t.Errorf("Expected my_mod_hidden to be true, got %v", tech.ExtraFlags["my_mod_hidden"])
+	}
+	if !tech.IsInsight {
+		t.Error("Expected IsInsight to be true")
+	}
+	if _, exists := tech.ExtraFlags["is_insight"]; exists {
+		t.Error("Expected modelled key is_insight not to be an extra flag")
+	}
+	if tech.ExtraFlags["my_mod_priority"] != 3 {
+		t.Errorf("Expected my_mod_priority 3, got %v", tech.ExtraFlags["my_mod_priority"])
+	}
+	if tags, ok := tech.ExtraFlags["custom_tags"].([]interface{}); !ok || len(tags) != 2 {
+		t.Errorf("Expected custom_tags to be a list of 2, got %v", tech.ExtraFlags["custom_tags"])
+	}
+	if _, exists := tech.ExtraFlags["modifier"]; exists {
+		t.Error("Expected known block modifier not to be an extra flag")
+	}
+	if _, ok := tech.ExtraFlags["on_research"].(map[string]interface{}); !ok {
+		t.Errorf("Expected nested block on_research to be kept, got %v", tech.ExtraFlags["on_research"])
+	}
+	if _, exists := tech.ExtraFlags["cost"]; exists {
+		t.Error("Expected modelled key cost not to be an extra flag")
+	}
+
+	flags, ok := tech.ExtraFlags["set_technology_flag"].([]string)
+	if !ok || len(flags) != 2 || flags[0] != "nested_flag" || flags[1] != "top_flag" {
+		t.Errorf("Expected technology flags [nested_flag top_flag], got %v", tech.ExtraFlags["set_technology_flag"])
+	}
+
+	plain, _ := parser.GetTechnology("tech_plain")
+	if plain.ExtraFlags != nil {
+		t.Errorf("Expected no extra flags for tech_plain, got %v", plain.ExtraFlags)
+	}
+}
+
+func TestParseExtra(t *testing.T) {
+	content := `tech_modded = {
+	cost = 100
+	is_insight = yes
+	my_mod_hidden = yes
+	my_mod_unlocks = {
+		building = { building_my_lab }
+		rank >= 2
+	}
+	my_mod_note = "a"
+	my_mod_note = "b"
+	set_technology_flag = top_flag
+}
+tech_plain = {
+	cost = 100
+}
+`
+	parser := NewTechParser()
+	if err := parser.ParseFileFS(fstest.MapFS{"00_modded.txt": {Data: []byte(content)}}, "00_modded.txt"); err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	tech, _ := parser.GetTechnology("tech_modded")
+	if len(tech.Extra) != 4 {
+		t.Errorf("Expected the 4 unmodelled keys, got %v", tech.Extra)
+	}
+	if tech.Extra["my_mod_hidden"] != true || tech.Extra["set_technology_flag"] != "top_flag" {
+		t.Errorf("Expected the scalar keys as parsed, got %v", tech.Extra)
+	}
+	unlocks, ok := tech.Extra["my_mod_unlocks"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected my_mod_unlocks to be kept as a block, got %v", tech.Extra["my_mod_unlocks"])
+	}
+	if rank, ok := unlocks["rank"].(models.Comparison); !ok || rank.Operator != ">=" || rank.Value != 2 {
+		t.Errorf("Expected the comparison in the nested block, got %v", unlocks["rank"])
+	}
+	if notes, ok := tech.Extra["my_mod_note"].(models.Repeated); !ok || len(notes) != 2 {
+		t.Errorf("Expected the repeated key with both values, got %v", tech.Extra["my_mod_note"])
+	}
+	if _, exists := tech.Extra["is_insight"]; exists {
+		t.Error("Expected modelled key is_insight not to be in Extra")
+	}
+
+	plain, _ := parser.GetTechnology("tech_plain")
+	if plain.Extra != nil {
+		t.Errorf("Expected no extra keys for tech_plain, got %v", plain.Extra)
+	}
+}
+
+func TestResolveScriptedVariables(t *testing.T) {
+	content := "@tier1cost = 360\n@weight = 2.5\ntech_a = {\ncost = @tier1cost\nweight = @weight\ngateway = @unknown\n}\n"
+
+	variables := extractScriptedVariables(content)
+	if len(variables) != 2 || variables["tier1cost"] != "360" {
+		t.Fatalf("Expected 2 scripted variables, got %v", variables)
+	}
+
+	resolved := resolveScriptedVariables("cost = @tier1cost\ngateway = @unknown\n", variables)
+	if resolved != "cost = 360\ngateway = @unknown\n" {
+		t.Errorf("Unexpected resolved content: %q", resolved)
+	}
+
+	parser := NewTechParser()
+	techs := parser.parseContent(content, "test.txt")
+	if techs["tech_a"].Cost != 360 {
+		t.Errorf("Expected cost 360, got %d", techs["tech_a"].Cost)
+	}
+	if _, exists := techs["tier1cost"]; exists {
+		t.Error("Expected scripted variables not to be parsed as technologies")
+	}
+}
+
+func TestParsePrerequisiteGroups(t *testing.T) {
+	content := `tech_alternatives = {
+	cost = 100
+	prerequisites = { "tech_a" "tech_b" }
+	prerequisites = { "tech_c" }
+	potential = {
+		prerequisites = { "tech_nested" }
+	}
+}
+tech_single = {
+	cost = 100
+	prerequisites = { "tech_a" }
+}
+tech_none = {
+	cost = 0
+	prerequisites = { }
+}
+`
+
+	parser := NewTechParser()
+	techs := parser.parseContent(joinTrimmedLines(strings.Split(content, "\n")), "test.txt")
+
+	alternatives := techs["tech_alternatives"]
+	if len(alternatives.PrerequisiteGroups) != 2 {
+		t.Fatalf("Expected 2 prerequisite groups, got %v", alternatives.PrerequisiteGroups)
+	}
+	if len(alternatives.PrerequisiteGroups[0]) != 2 || alternatives.PrerequisiteGroups[1][0] != "tech_c" {
+		t.Errorf("Unexpected prerequisite groups: %v", alternatives.PrerequisiteGroups)
+	}
+	if len(alternatives.Prerequisites) != 3 {
+		t.Errorf("Expected prerequisites of all groups, got %v", alternatives.Prerequisites)
+	}
+
+	if groups := techs["tech_single"].PrerequisiteGroups; len(groups) != 1 || len(groups[0]) != 1 {
+		t.Errorf("Expected a single group, got %v", groups)
+	}
+	if groups := techs["tech_none"].PrerequisiteGroups; len(groups) != 0 {
+		t.Errorf("Expected no groups for an empty block, got %v", groups)
+	}
+}
+
+func TestParseFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"common/technology/00_base.txt":             {Data: []byte("tech_base = {\n\tcost = 100\n\tarea = physics\n}\n")},
+		"common/technology/category/00_cats.txt":    {Data: []byte("particles = {\n\ticon = GFX_particles\n}\n")},
+		"common/technology/tier/00_tier.txt":        {Data: []byte("1 = {\n}\n")},
+		"common/technology/nested/01_more.txt":      {Data: []byte("tech_nested = {\n\tcost = 5\n}\n")},
+		"common/technology/readme.md":               {Data: []byte("tech_ignored = {\n}\n")},
+		"mod/common/technology/00_mod_override.txt": {Data: []byte("tech_base = {\n\tcost = 200\n}\n")},
+	}
+
+	parser := NewTechParser()
+	if err := parser.ParseFS(fsys, "common/technology"); err != nil {
+		t.Fatalf("Failed to parse file system: %v", err)
+	}
+	if err := parser.ParseModFS(fsys, "mod/common/technology", "my_mod"); err != nil {
+		t.Fatalf("Failed to parse mod file system: %v", err)
+	}
+
+	technologies := parser.GetTechnologies()
+	if len(technologies) != 2 {
+		t.Fatalf("Expected 2 technologies, got %d", len(technologies))
+	}
+	base := technologies["tech_base"]
+	if base.Cost != 200 || base.Mod != "my_mod" || base.SourceFile != "00_mod_override.txt" {
+		t.Errorf("Expected the mod definition, got cost %d from %q of mod %q", base.Cost, base.SourceFile, base.Mod)
+	}
+	if len(parser.GetOverrides()) != 1 {
+		t.Errorf("Expected 1 override, got %d", len(parser.GetOverrides()))
+	}
+	if category := parser.GetCategories()["particles"]; category == nil || category.Icon != "GFX_particles" {
+		t.Errorf("Expected the particles category, got %+v", category)
+	}
+
+	if err := parser.ParseFS(fsys, "missing"); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestParseFileFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"broken.txt": {Data: []byte("tech_broken = {\n\tcost = 1\n")},
+	}
+
+	parser := NewTechParser()
+	err := parser.ParseFileFS(fsys, "broken.txt")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.File != "broken.txt" {
+		t.Fatalf("Expected a parse error for broken.txt, got %v", err)
+	}
+	if len(parser.GetDiagnostics()) == 0 {
+		t.Error("Expected diagnostics for the unclosed block")
+	}
+}
+
+func TestParseDirectoryContextCancelled(t *testing.T) {
+	parser := NewTechParser()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := parser.ParseDirectoryContext(ctx, "../../testdata/common/technology")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if count := len(parser.GetTechnologies()); count != 0 {
+		t.Errorf("Expected no technologies after cancellation, got %d", count)
+	}
+}
+
+func TestParseDirectoryLogger(t *testing.T) {
+	var buf bytes.Buffer
+	parser := NewTechParser()
+	parser.SetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	if err := parser.ParseDirectory("../../testdata/common/technology"); err != nil {
+		t.Fatalf("Failed to parse directory: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Parsed technology file") {
+		t.Errorf("Expected a debug record for every parsed file, got %q", buf.String())
+	}
+}
+
+func TestParseConditionOrder(t *testing.T) {
+	parser := NewTechParser()
+	data := map[string]interface{}{
+		"OR": map[string]interface{}{
+			"has_ethic":         "ethic_materialist",
+			"has_origin":        "origin_mechanists",
+			"is_gestalt":        true,
+			"has_authority":     "auth_machine_intelligence",
+			"has_country_flag":  "flag",
+			"has_civic":         "civic_technocracy",
+			"is_country_type":   "default",
+			"has_valid_civic":   "civic_x",
+			"has_global_flag":   "global",
+			"has_tradition":     "tr_discovery_adopt",
+			"num_owned_planets": 3,
+		},
+	}
+
+	for i := 0; i < 5; i++ {
+		condition := parser.parseCondition(data)
+		if condition.Type != "OR" {
+			t.Fatalf("Expected an OR condition, got %q", condition.Type)
+		}
+		for j := 1; j < len(condition.Children); j++ {
+			if condition.Children[j-1].Key > condition.Children[j].Key {
+				t.Fatalf("Expected children sorted by key, got %s before %s", condition.Children[j-1].Key, condition.Children[j].Key)
+			}
+		}
+	}
+
+	simple := parser.parseCondition(map[string]interface{}{"is_gestalt": true, "has_ethic": "ethic_materialist"})
+	if simple.Key != "has_ethic" {
+		t.Errorf("Expected the first key of a simple condition, got %q", simple.Key)
+	}
+}
+
+func TestParseComparisonConditions(t *testing.T) {
+	parser := NewTechParser()
+	content := `tech_compared = {
+	area = engineering
+	potential = {
+		AND = {
+			num_owned_planets > 5
+			count_starbase_sizes >= 3
+			has_ethic != ethic_pacifist
+			is_gestalt = no
+		}
+	}
+}
+`
+	tech := parser.parseContent(content, "compared.txt")["tech_compared"]
+	if tech == nil || tech.Potential == nil {
+		t.Fatal("Expected tech_compared with a potential")
+	}
+
+	expected := map[string]models.Condition{
+		"num_owned_planets":    {Key: "num_owned_planets", Operator: ">", Value: 5},
+		"count_starbase_sizes": {Key: "count_starbase_sizes", Operator: ">=", Value: 3},
+		"has_ethic":            {Key: "has_ethic", Operator: "!=", Value: "ethic_pacifist"},
+		"is_gestalt":           {Key: "is_gestalt", Operator: "=", Value: false},
+	}
+	if tech.Potential.Type != "AND" || len(tech.Potential.Children) != len(expected) {
+		t.Fatalf("Expected an AND with %d conditions, got %+v", len(expected), tech.Potential)
+	}
+	for _, child := range tech.Potential.Children {
+		want := expected[child.Key]
+		if child.Key != want.Key || child.Operator != want.Operator || child.Value != want.Value {
+			t.Errorf("Expected %+v, got %+v", want, child)
+		}
+	}
+
+	raw := tech.Potential.Raw["AND"].(map[string]interface{})
+	if comparison := raw["count_starbase_sizes"]; comparison != (models.Comparison{Operator: ">=", Value: 3}) {
+		t.Errorf("Expected the raw block to keep the comparison, got %#v", comparison)
+	}
+}
+
+func TestParseRepeatedKeys(t *testing.T) {
+	parser := NewTechParser()
+	data := parser.parseBlock(`
+	prereqfor_desc = {
+		title = "first"
+	}
+	prereqfor_desc = {
+		title = "second"
+	}
+	potential = {
+		has_technology = tech_a
+		has_technology = tech_b
+		has_technology = tech_c
+	}
+	cost = 100
+`)
+
+	descs, ok := data["prereqfor_desc"].(models.Repeated)
+	if !ok || len(descs) != 2 {
+		t.Fatalf("Expected both prereqfor_desc blocks, got %#v", data["prereqfor_desc"])
+	}
+	if title := descs[1].(map[string]interface{})["title"]; title != "second" {
+		t.Errorf("Expected the blocks in file order, got %v", title)
+	}
+	if cost := data["cost"]; cost != 100 {
+		t.Errorf("Expected a single key to keep its value, got %#v", cost)
+	}
+
+	potential := parser.parseCondition(data["potential"].(map[string]interface{}))
+	if len(potential.Raw["has_technology"].(models.Repeated)) != 3 {
+		t.Errorf("Expected three has_technology conditions, got %#v", potential.Raw["has_technology"])
+	}
+
+	// Logical blocks have a condition for each value of a repeated key
+	and := parser.parseCondition(map[string]interface{}{"AND": potential.Raw})
+	if len(and.Children) != 3 || and.Children[2].Value != "tech_c" {
+		t.Errorf("Expected a child per has_technology, got %+v", and.Children)
+	}
+}
+
+func TestParseKeepSource(t *testing.T) {
+	content := "# Lasers\r\ntech_lasers_1 = {\r\n\tcost = 100 # cheap\r\n\tarea = physics\r\n}\r\n\r\ntech_short = { cost = 5 }\r\n"
+	fsys := fstest.MapFS{"00_test.txt": {Data: []byte(content)}}
+
+	parser := NewTechParser()
+	if err := parser.ParseFileFS(fsys, "00_test.txt"); err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if tech, _ := parser.GetTechnology("tech_lasers_1"); tech.Source != nil {
+		t.Errorf("Expected no source by default, got %+v", tech.Source)
+	}
+
+	parser = NewTechParser()
+	parser.SetKeepSource(true)
+	if err := parser.ParseFileFS(fsys, "00_test.txt"); err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	lasers, _ := parser.GetTechnology("tech_lasers_1")
+	expected := models.Source{
+		File:      "00_test.txt",
+		StartLine: 2,
+		EndLine:   5,
+		Text:      "tech_lasers_1 = {\n\tcost = 100 # cheap\n\tarea = physics\n}",
+	}
+	if lasers.Source == nil || *lasers.Source != expected {
+		t.Errorf("Expected source %+v, got %+v", expected, lasers.Source)
+	}
+
+	short, _ := parser.GetTechnology("tech_short")
+	if short.Source == nil || short.Source.StartLine != 7 || short.Source.EndLine != 7 {
+		t.Errorf("Expected a single-line source on line 7, got %+v", short.Source)
+	}
+	if short.Cost != 5 {
+		t.Errorf("Expected the single-line block to be parsed, got cost %d", short.Cost)
+	}
+}
+
+func TestParseFileFSEncodings(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bom.txt": {Data: []byte("\xEF\xBB\xBFtech_bom = {\n\tarea = physics\n}\n")},
+		"cp.txt":  {Data: []byte("tech_cp = {\n\tarea = society\n\ticon = \"caf\xE9\"\n}\n")},
+	}
+
+	parser := NewTechParser()
+	for _, name := range []string{"bom.txt", "cp.txt"} {
+		if err := parser.ParseFileFS(fsys, name); err != nil {
+			t.Fatalf("Failed to parse %s: %v", name, err)
+		}
+	}
+	if tech, ok := parser.GetTechnology("tech_bom"); !ok || tech.Area != "physics" {
+		t.Errorf("Expected the key after the BOM to be parsed, got %+v", tech)
+	}
+	if tech, ok := parser.GetTechnology("tech_cp"); !ok || tech.Icon != "café" {
+		t.Errorf("Expected Windows-1252 to be converted, got %+v", tech)
+	}
+}
+
+func TestTechParserConcurrentAccess(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for i := 0; i < 20; i++ {
+		fsys[fmt.Sprintf("technology/%02d.txt", i)] = &fstest.MapFile{
+			Data: []byte(fmt.Sprintf("tech_%d = { area = physics }\ntech_shared = { tier = %d }\n}\n", i, i)),
+		}
+	}
+
+	parser := NewTechParser()
+	parser.SetWorkers(4)
+
+	// Read the results while the directory is being parsed; run with -race
+	// to check the locking
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				for key := range parser.GetTechnologies() {
+					parser.GetTechnology(key)
+				}
+				_ = len(parser.GetDiagnostics()) + len(parser.GetOverrides()) + len(parser.GetCategories())
+			}
+		}()
+	}
+
+	errs := make(chan error, 2)
+	for m := 0; m < 2; m++ {
+		go func() {
+			errs <- parser.ParseModFS(fsys, "technology", fmt.Sprintf("mod_%d", m))
+		}()
+	}
+	for m := 0; m < 2; m++ {
+		if err := <-errs; err != nil {
+			t.Errorf("ParseModFS failed: %v", err)
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	technologies := parser.GetTechnologies()
+	if len(technologies) != 21 {
+		t.Errorf("Expected 21 technologies, got %d", len(technologies))
+	}
+	// Parse calls run one at a time, so each mod's files are merged together
+	// and the last definition of tech_shared comes from the last file
+	if tech := technologies["tech_shared"]; tech.Tier != 19 {
+		t.Errorf("Expected tech_shared from the last file, got tier %d", tech.Tier)
+	}
+	if len(parser.GetOverrides()) != 59 {
+		t.Errorf("Expected 59 overrides, got %d", len(parser.GetOverrides()))
+	}
+
+	// The getters return copies
+	delete(technologies, "tech_shared")
+	parser.GetDiagnostics()[0].Message = "changed"
+	if _, exists := parser.GetTechnology("tech_shared"); !exists {
+		t.Error("Expected deleting from GetTechnologies not to change the parser")
+	}
+	if parser.GetDiagnostics()[0].Message == "changed" {
+		t.Error("Expected GetDiagnostics to return a copy")
+	}
+}
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// Locations of planet definitions, relative to the game or mod directory
+const (
+	DistrictsDir     = "common/districts"
+	PlanetClassesDir = "common/planet_classes"
+)
+
+// ParseDistricts reads the districts of the game directory and mods
+func ParseDistricts(gameDir string, modDirs []string) ([]*models.District, error) {
+	definitions, err := LoadDefinitions(DistrictsDir, gameDir, modDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read districts: %w", err)
+	}
+
+	districts := make([]*models.District, 0, len(definitions))
+	for _, definition := range definitions {
+		data := definition.Data
+		cost, upkeep := resourceCosts(data)
+		district := &models.District{
+			Key:           definition.Key,
+			Cost:          cost,
+			Upkeep:        upkeep,
+			Produces:      numberMap(rawBlock(data["resources"])["produces"]),
+			Modifiers:     rawBlock(data["planet_modifier"]),
+			Triggered:     definition.Blocks("triggered_planet_modifier"),
+			ConvertTo:     stringList(data["convert_to"]),
+			Prerequisites: stringList(data["prerequisites"]),
+			Potential:     rawBlock(data["potential"]),
+			Allow:         rawBlock(data["allow"]),
+			SourceFile:    definition.SourceFile,
+			Mod:           definition.Mod,
+		}
+		if district.Triggered == nil {
+			district.Triggered = []map[string]interface{}{}
+		}
+		district.Icon, _ = data["icon"].(string)
+		district.BuildTime, _ = data["base_buildtime"].(int)
+		district.Capped, _ = data["is_capped_by_modifier"].(bool)
+		districts = append(districts, district)
+	}
+	return districts, nil
+}
+
+// ParsePlanetClasses reads the planet classes of the game directory and mods
+func ParsePlanetClasses(gameDir string, modDirs []string) ([]*models.PlanetClass, error) {
+	definitions, err := LoadDefinitions(PlanetClassesDir, gameDir, modDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read planet classes: %w", err)
+	}
+
+	classes := make([]*models.PlanetClass, 0, len(definitions))
+	for _, definition := range definitions {
+		data := definition.Data
+		class := &models.PlanetClass{
+			Key:          definition.Key,
+			DistrictCaps: districtCaps(rawBlock(data["modifier"])),
+			Modifiers:    rawBlock(data["modifier"]),
+			SourceFile:   definition.SourceFile,
+			Mod:          definition.Mod,
+		}
+		class.Climate, _ = data["climate"].(string)
+		class.Colonizable, _ = data["colonizable"].(bool)
+		class.Initial, _ = data["initial"].(bool)
+		class.Extreme, _ = data["extreme"].(bool)
+		classes = append(classes, class)
+	}
+	return classes, nil
+}
+
+// districtCaps reads the district_<key>_max and district_<key>_max_add
+// modifiers, keyed by district
+func districtCaps(modifiers map[string]interface{}) map[string]float64 {
+	caps := make(map[string]float64)
+	for key, value := range numberMap(modifiers) {
+		district, found := strings.CutPrefix(key, "district_")
+		if !found {
+			continue
+		}
+		if district, found = strings.CutSuffix(strings.TrimSuffix(district, "_add"), "_max"); found {
+			caps["district_"+district] += value
+		}
+	}
+	return caps
+}
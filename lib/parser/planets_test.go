@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseDistricts(t *testing.T) {
+	gameDir := t.TempDir()
+	writeFiles(t, map[string]string{
+		filepath.Join(gameDir, DistrictsDir, "00_urban_districts.txt"): `@district_cost = 500
+
+district_generator = {
+	base_buildtime = 240
+	is_capped_by_modifier = yes
+	convert_to = {
+		district_generator_uncapped
+	}
+	resources = {
+		category = planet_districts
+		cost = {
+			minerals = @district_cost
+		}
+		upkeep = {
+			energy = 1
+		}
+		produces = {
+			energy = 4
+		}
+	}
+	planet_modifier = {
+		planet_housing_add = 2
+		job_technician_add = 2
+	}
+	triggered_planet_modifier = {
+		potential = {
+			has_technology = tech_power_hub_1
+		}
+		modifier = {
+			job_technician_add = 1
+		}
+	}
+	triggered_planet_modifier = {
+		potential = {
+			has_technology = tech_power_hub_2
+		}
+		modifier = {
+			job_technician_add = 1
+		}
+	}
+}
+`,
+	})
+
+	districts, err := ParseDistricts(gameDir, nil)
+	if err != nil {
+		t.Fatalf("Failed to parse districts: %v", err)
+	}
+	if len(districts) != 1 {
+		t.Fatalf("Expected 1 district, got %d", len(districts))
+	}
+
+	generator := districts[0]
+	if !generator.Capped || generator.BuildTime != 240 {
+		t.Errorf("Expected a capped district with a build time, got %+v", generator)
+	}
+	if generator.Cost["minerals"] != 500 || generator.Upkeep["energy"] != 1 || generator.Produces["energy"] != 4 {
+		t.Errorf("Expected cost, upkeep and production, got %v, %v and %v", generator.Cost, generator.Upkeep, generator.Produces)
+	}
+	if generator.Modifiers["job_technician_add"] != 2 {
+		t.Errorf("Expected planet modifiers, got %v", generator.Modifiers)
+	}
+	if len(generator.Triggered) != 2 || rawBlock(generator.Triggered[1]["potential"])["has_technology"] != "tech_power_hub_2" {
+		t.Errorf("Expected both triggered modifiers, got %v", generator.Triggered)
+	}
+	if len(generator.ConvertTo) != 1 || generator.ConvertTo[0] != "district_generator_uncapped" {
+		t.Errorf("Expected conversions, got %v", generator.ConvertTo)
+	}
+}
+
+func TestParsePlanetClasses(t *testing.T) {
+	gameDir := t.TempDir()
+	writeFiles(t, map[string]string{
+		filepath.Join(gameDir, PlanetClassesDir, "00_planet_classes.txt"): `pc_desert = {
+	climate = "dry"
+	initial = yes
+	colonizable = yes
+	extreme = no
+	atmosphere_color = hsv { 0.08 0.4 0.8 }
+	modifier = {
+		district_mining_max_add = 2
+		district_farming_max = 1
+		planet_stability_add = 1
+	}
+}
+`,
+	})
+
+	classes, err := ParsePlanetClasses(gameDir, nil)
+	if err != nil {
+		t.Fatalf("Failed to parse planet classes: %v", err)
+	}
+	if len(classes) != 1 {
+		t.Fatalf("Expected 1 planet class, got %d", len(classes))
+	}
+
+	desert := classes[0]
+	if desert.Climate != "dry" || !desert.Colonizable || !desert.Initial || desert.Extreme {
+		t.Errorf("Expected a colonizable dry planet, got %+v", desert)
+	}
+	if len(desert.DistrictCaps) != 2 || desert.DistrictCaps["district_mining"] != 2 || desert.DistrictCaps["district_farming"] != 1 {
+		t.Errorf("Expected district caps, got %v", desert.DistrictCaps)
+	}
+	if desert.Modifiers["planet_stability_add"] != 1 {
+		t.Errorf("Expected modifiers, got %v", desert.Modifiers)
+	}
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// Locations of relic and archaeology definitions, relative to the game or mod
+// directory
+const (
+	RelicsDir                  = "common/relics"
+	ArchaeologicalSiteTypesDir = "common/archaeological_site_types"
+)
+
+// ParseRelics reads the relics of the game directory and mods
+func ParseRelics(gameDir string, modDirs []string) ([]*models.Relic, error) {
+	definitions, err := LoadDefinitions(RelicsDir, gameDir, modDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read relics: %w", err)
+	}
+
+	relics := make([]*models.Relic, 0, len(definitions))
+	for _, definition := range definitions {
+		data := definition.Data
+		cost, _ := resourceCosts(data)
+		relic := &models.Relic{
+			Key:            definition.Key,
+			Cost:           cost,
+			PassiveEffects: definition.Blocks("triggered_country_modifier"),
+			ActiveEffect:   rawBlock(data["active_effect"]),
+			Possible:       rawBlock(data["possible"]),
+			SourceFile:     definition.SourceFile,
+			Mod:            definition.Mod,
+		}
+		if relic.PassiveEffects == nil {
+			relic.PassiveEffects = []map[string]interface{}{}
+		}
+		relic.Portrait, _ = data["portrait"].(string)
+		relic.Score, _ = data["score"].(int)
+		relic.ActivationDuration, _ = data["activation_duration"].(int)
+		relics = append(relics, relic)
+	}
+	return relics, nil
+}
+
+// ParseArchaeologySites reads the archaeological site types of the game
+// directory and mods
+func ParseArchaeologySites(gameDir string, modDirs []string) ([]*models.ArchaeologySite, error) {
+	definitions, err := LoadDefinitions(ArchaeologicalSiteTypesDir, gameDir, modDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archaeological site types: %w", err)
+	}
+
+	sites := make([]*models.ArchaeologySite, 0, len(definitions))
+	for _, definition := range definitions {
+		data := definition.Data
+		site := &models.ArchaeologySite{
+			Key:          definition.Key,
+			Weight:       data["weight"],
+			Stages:       []models.ArchaeologyStage{},
+			Potential:    rawBlock(data["potential"]),
+			Allow:        rawBlock(data["allow"]),
+			OnRollFailed: rawBlock(data["on_roll_failed"]),
+			SourceFile:   definition.SourceFile,
+			Mod:          definition.Mod,
+		}
+		site.Picture, _ = data["picture"].(string)
+		site.MaxInstances, _ = data["max_instances"].(int)
+
+		// Repeated stage blocks can't be read from Data
+		for _, block := range namedBlocks(definition.content) {
+			if block.name != "stage" {
+				continue
+			}
+			fields := simpleFields(block.body)
+			difficulty, _ := strconv.Atoi(fields["difficulty"])
+			site.Stages = append(site.Stages, models.ArchaeologyStage{
+				Difficulty: difficulty,
+				Icon:       fields["icon"],
+				Event:      fields["event"],
+			})
+		}
+		sites = append(sites, site)
+	}
+	return sites, nil
+}
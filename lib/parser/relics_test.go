@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseRelics(t *testing.T) {
+	gameDir := t.TempDir()
+	writeFiles(t, map[string]string{
+		filepath.Join(gameDir, RelicsDir, "00_relics.txt"): `r_galaxy = {
+	activation_duration = 3600
+	portrait = "GFX_relic_galaxy"
+	score = 1000
+	resources = {
+		category = relics
+		cost = {
+			influence = 100
+		}
+	}
+	triggered_country_modifier = {
+		potential = {
+			always = yes
+		}
+		modifier = {
+			all_technology_research_speed = 0.05
+		}
+	}
+	active_effect = {
+		add_modifier = {
+			modifier = relic_activation_galaxy
+			days = 3600
+		}
+	}
+	possible = {
+		custom_tooltip = {
+			fail_text = "requires_surveyed"
+		}
+	}
+}
+`,
+	})
+
+	relics, err := ParseRelics(gameDir, nil)
+	if err != nil {
+		t.Fatalf("Failed to parse relics: %v", err)
+	}
+	if len(relics) != 1 {
+		t.Fatalf("Expected 1 relic, got %d", len(relics))
+	}
+
+	galaxy := relics[0]
+	if galaxy.Portrait != "GFX_relic_galaxy" || galaxy.Score != 1000 || galaxy.ActivationDuration != 3600 {
+		t.Errorf("Expected portrait, score and activation duration, got %+v", galaxy)
+	}
+	if galaxy.Cost["influence"] != 100 {
+		t.Errorf("Expected the activation cost, got %v", galaxy.Cost)
+	}
+	if len(galaxy.PassiveEffects) != 1 || rawBlock(galaxy.PassiveEffects[0]["modifier"])["all_technology_research_speed"] != 0.05 {
+		t.Errorf("Expected the passive effect, got %v", galaxy.PassiveEffects)
+	}
+	if _, exists := galaxy.ActiveEffect["add_modifier"]; !exists {
+		t.Errorf("Expected the active effect, got %v", galaxy.ActiveEffect)
+	}
+}
+
+func TestParseArchaeologySites(t *testing.T) {
+	gameDir := t.TempDir()
+	writeFiles(t, map[string]string{
+		filepath.Join(gameDir, ArchaeologicalSiteTypesDir, "00_sites.txt"): `site_ancient_battlefield = {
+	desc = site_ancient_battlefield_desc
+	picture = GFX_evt_ancient_battlefield
+	stages = 2
+	max_instances = 1
+	weight = 5
+	stage = { difficulty = 1 icon = "GFX_archaeology_runes_A1" event = ancrel.1 }
+	stage = {
+		difficulty = 3
+		icon = GFX_archaeology_runes_A2
+		event = ancrel.2
+	}
+	on_roll_failed = {
+		standard_archaeological_site_on_roll_failed = { RANDOM_EVENTS = ancrel_fail }
+	}
+}
+`,
+	})
+
+	sites, err := ParseArchaeologySites(gameDir, nil)
+	if err != nil {
+		t.Fatalf("Failed to parse archaeological sites: %v", err)
+	}
+	if len(sites) != 1 {
+		t.Fatalf("Expected 1 site, got %d", len(sites))
+	}
+
+	site := sites[0]
+	if site.Picture != "GFX_evt_ancient_battlefield" || site.MaxInstances != 1 || site.Weight != 5 {
+		t.Errorf("Expected picture, instances and weight, got %+v", site)
+	}
+	expected := []struct {
+		difficulty  int
+		icon, event string
+	}{{1, "GFX_archaeology_runes_A1", "ancrel.1"}, {3, "GFX_archaeology_runes_A2", "ancrel.2"}}
+	if len(site.Stages) != len(expected) {
+		t.Fatalf("Expected %d stages, got %+v", len(expected), site.Stages)
+	}
+	for i, stage := range site.Stages {
+		if stage.Difficulty != expected[i].difficulty || stage.Icon != expected[i].icon || stage.Event != expected[i].event {
+			t.Errorf("Expected stage %d to be %+v, got %+v", i, expected[i], stage)
+		}
+	}
+	if _, exists := site.OnRollFailed["standard_archaeological_site_on_roll_failed"]; !exists {
+		t.Errorf("Expected the failed roll effect, got %v", site.OnRollFailed)
+	}
+}
@@ -0,0 +1,262 @@
+package parser
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// tokenKind classifies the tokens of script text
+type tokenKind int
+
+const (
+	tokenWord     tokenKind = iota // A key or unquoted value, such as tech_lasers_1 or 1.5
+	tokenString                    // A quoted value, quotes included
+	tokenOperator                  // =, >, >=, <, <= or !=
+	tokenOpen                      // {
+	tokenClose                     // }
+)
+
+// token is a token of script text with its position
+type token struct {
+	kind  tokenKind
+	text  string
+	start int // Byte offset of the first character
+	end   int // Byte offset after the last character
+	line  int // 1-based line
+}
+
+// wordEnds are the bytes ending an unquoted word
+var wordEnds = func() (ends [256]bool) {
+	for _, c := range []byte(" \t\r\n#{}=<>!\"") {
+		ends[c] = true
+	}
+	return ends
+}()
+
+// tokenize splits script text into tokens in a single pass, regardless of
+// how it is spread over lines. Comments are skipped and quoted strings may
+// contain spaces, braces and operators.
+func tokenize(content string) []token {
+	// Vanilla files average a token every 6 to 8 bytes
+	return appendTokens(make([]token, 0, len(content)/6), content)
+}
+
+// appendTokens appends the tokens of script text to tokens, see tokenize
+func appendTokens(tokens []token, content string) []token {
+	line := 1
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#':
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+		case c == '{' || c == '}':
+			kind := tokenOpen
+			if c == '}' {
+				kind = tokenClose
+			}
+			tokens = append(tokens, token{kind: kind, text: content[i : i+1], start: i, end: i + 1, line: line})
+			i++
+		case c == '=' || c == '>' || c == '<' || c == '!':
+			end := i + 1
+			if end < len(content) && content[end] == '=' && c != '=' {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: content[i:end], start: i, end: end, line: line})
+			i = end
+		case c == '"':
+			start, startLine := i, line
+			for i++; i < len(content) && content[i] != '"'; i++ {
+				if content[i] == '\\' && i+1 < len(content) {
+					i++
+				}
+				if content[i] == '\n' {
+					line++
+				}
+			}
+			i = min(i+1, len(content))
+			tokens = append(tokens, token{kind: tokenString, text: content[start:i], start: start, end: i, line: startLine})
+		default:
+			start := i
+			for i < len(content) && !wordEnds[content[i]] {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: content[start:i], start: start, end: i, line: line})
+		}
+	}
+	return tokens
+}
+
+// tokenBuffers holds token slices for reuse across files. Statements don't
+// refer to the tokens they were read from, so the slice of a file can be
+// reused once its statements are read.
+var tokenBuffers = sync.Pool{New: func() any { return new([]token) }}
+
+// pooledTokens tokenizes content into a slice from tokenBuffers, which
+// release returns to the pool
+func pooledTokens(content string) (tokens []token, release func()) {
+	buf := tokenBuffers.Get().(*[]token)
+	tokens = appendTokens((*buf)[:0], content)
+	return tokens, func() {
+		// Cleared so the pool doesn't keep the text of the file alive
+		clear(tokens)
+		*buf = tokens[:0]
+		tokenBuffers.Put(buf)
+	}
+}
+
+// statement is an assignment such as key = value or key = { ... }, a
+// comparison such as key > 5, or a bare value such as an item of a list
+type statement struct {
+	key      string      // Without quotes; empty for a bare value
+	operator string      // Empty for a bare value
+	value    string      // Scalar value as written, quotes included
+	block    string      // Content of a block value, without the braces
+	children []statement // Statements of a block value
+	end      int         // Byte offset after a block value
+	line     int         // 1-based line the statement starts on
+	endLine  int         // 1-based line the statement ends on
+	quoted   bool        // Whether the key was quoted
+	isBlock  bool
+}
+
+// statements returns the statements written directly in script text, in
+// order and including repeated keys. The text is tokenized once and nested
+// blocks are read into the children of their statement. An unclosed block
+// extends to the end of the text and stray closing braces are skipped.
+func statements(content string) []statement {
+	return readStatements(content, tokenize(content))
+}
+
+// readStatements reads the top-level statements of the tokens of content
+func readStatements(content string, tokens []token) []statement {
+	r := statementReader{content: content, tokens: tokens}
+	result, _ := r.read(0, false)
+	return result
+}
+
+// statementReader reads statements from tokens. The statements of every
+// block are stored together, so a file needs a handful of allocations
+// rather than one per block.
+type statementReader struct {
+	content string
+	tokens  []token
+	stack   []statement // Statements of the blocks being read
+	store   []statement // Statements of the blocks read so far
+}
+
+// read reads statements from tokens[i] up to the brace closing a nested
+// block, or up to the end of the tokens otherwise. It returns the index of
+// the closing brace, or len(tokens) if there is none.
+func (r *statementReader) read(i int, nested bool) ([]statement, int) {
+	tokens := r.tokens
+	mark := len(r.stack)
+
+	for i < len(tokens) {
+		t := tokens[i]
+		switch t.kind {
+		case tokenClose:
+			if nested {
+				return r.keep(mark), i
+			}
+			i++
+			continue
+		case tokenOperator:
+			i++
+			continue
+		case tokenOpen:
+			// An anonymous block, such as an item of a list of lists
+			st := statement{line: t.line}
+			i = r.readBlock(i, &st)
+			r.stack = append(r.stack, st)
+			continue
+		}
+
+		if i+1 >= len(tokens) || tokens[i+1].kind != tokenOperator {
+			r.stack = append(r.stack, statement{value: t.text, line: t.line, endLine: t.line})
+			i++
+			continue
+		}
+
+		st := statement{key: strings.Trim(t.text, "\""), quoted: t.kind == tokenString, operator: tokens[i+1].text, line: t.line, endLine: tokens[i+1].line}
+		i += 2
+		if i >= len(tokens) {
+			r.stack = append(r.stack, st)
+			break
+		}
+		switch value := tokens[i]; value.kind {
+		case tokenOpen:
+			i = r.readBlock(i, &st)
+		case tokenClose, tokenOperator:
+			// A key without a value
+		default:
+			st.value, st.endLine = value.text, value.line
+			i++
+			// Typed values such as color = rgb { 255 0 0 } keep their block
+			if value.kind == tokenWord && i < len(tokens) && tokens[i].kind == tokenOpen {
+				var typed statement
+				i = r.readBlock(i, &typed)
+				st.value = r.content[value.start:typed.end]
+				st.endLine = typed.endLine
+			}
+		}
+		r.stack = append(r.stack, st)
+	}
+
+	return r.keep(mark), len(tokens)
+}
+
+// readBlock reads the block opened at tokens[open] into a statement and
+// returns the index of the token after it
+func (r *statementReader) readBlock(open int, st *statement) int {
+	children, close := r.read(open+1, true)
+	st.isBlock, st.children = true, children
+	if close < len(r.tokens) {
+		st.block = r.content[r.tokens[open].end:r.tokens[close].start]
+		st.end, st.endLine = r.tokens[close].end, r.tokens[close].line
+	} else {
+		st.block = r.content[r.tokens[open].end:]
+		st.end, st.endLine = len(r.content), r.tokens[len(r.tokens)-1].line
+	}
+	return close + 1
+}
+
+// keep moves the statements read since mark from the stack to the store
+// and returns them
+func (r *statementReader) keep(mark int) []statement {
+	n := len(r.stack) - mark
+	if n == 0 {
+		return nil
+	}
+	if len(r.store)+n > cap(r.store) {
+		// Statements take about three tokens on average
+		r.store = make([]statement, 0, max(n, len(r.tokens)/3))
+	}
+	start := len(r.store)
+	r.store = append(r.store, r.stack[mark:]...)
+	r.stack = r.stack[:mark]
+	return r.store[start:len(r.store):len(r.store)]
+}
+
+// hasIdentifierKey reports whether a statement is keyed by a Clausewitz
+// identifier: letters and digits in any order with _ . : ' and -, such as
+// tech_lasers_1, 09_tech, event_target:my_target or owner.capital. Quoted
+// keys may contain any character.
+func (st statement) hasIdentifierKey() bool {
+	if st.key == "" || st.quoted {
+		return st.key != ""
+	}
+	for _, r := range st.key {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("_.:'-", r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestStatements(t *testing.T) {
+	content := `a = 1 b = { c = "x y" } # b = ignored
+d >= 2 "e" f = rgb { 1 2 3 }
+g = {
+	h = { }
+}`
+	got := statements(content)
+	want := []statement{
+		{key: "a", operator: "=", value: "1", line: 1, endLine: 1},
+		{key: "b", operator: "=", block: ` c = "x y" `, isBlock: true, end: 23, line: 1, endLine: 1, children: []statement{
+			{key: "c", operator: "=", value: `"x y"`, line: 1, endLine: 1},
+		}},
+		{key: "d", operator: ">=", value: "2", line: 2, endLine: 2},
+		{value: `"e"`, line: 2, endLine: 2},
+		{key: "f", operator: "=", value: "rgb { 1 2 3 }", line: 2, endLine: 2},
+		{key: "g", operator: "=", block: "\n\th = { }\n", isBlock: true, end: 83, line: 3, endLine: 5, children: []statement{
+			{key: "h", operator: "=", block: " ", isBlock: true, end: 81, line: 4, endLine: 4},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected statements\ngot  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestStatementsUnclosedBlock(t *testing.T) {
+	got := statements("a = { b = 1 } }\nc = { d = 2")
+	if len(got) != 2 || got[1].key != "c" || got[1].block != " d = 2" || got[1].endLine != 2 {
+		t.Errorf("Expected the stray brace skipped and the unclosed block to run to the end, got %+v", got)
+	}
+}
+
+func TestParseSingleLineBlocks(t *testing.T) {
+	parser := NewTechParser()
+	content := `@cost = 500
+tech_a = { area = physics tier = 1 cost = @cost }
+tech_b = { area = society prerequisites = { "tech_a" "tech_c" } category = { biology } } tech_c = { area = engineering }
+tech_d = {
+	area = physics prerequisites = { "tech_a" }
+	weight_modifier = { modifier = { factor = 2 has_technology = tech_b } }
+}
+`
+	techs := parser.parseContent(content, "one_line.txt")
+	if len(techs) != 4 {
+		t.Fatalf("Expected 4 technologies, got %d", len(techs))
+	}
+
+	if a := techs["tech_a"]; a.Area != "physics" || a.Tier != 1 || a.Cost != 500 {
+		t.Errorf("Unexpected tech_a %+v", a)
+	}
+	b := techs["tech_b"]
+	if !reflect.DeepEqual(b.Prerequisites, []string{"tech_a", "tech_c"}) {
+		t.Errorf("Expected both prerequisites of tech_b, got %v", b.Prerequisites)
+	}
+	if !reflect.DeepEqual(b.Category, []string{"biology"}) {
+		t.Errorf("Expected the biology category, got %v", b.Category)
+	}
+	if techs["tech_c"].Area != "engineering" {
+		t.Errorf("Expected tech_c after tech_b on the same line, got %+v", techs["tech_c"])
+	}
+
+	d := techs["tech_d"]
+	if !reflect.DeepEqual(d.Prerequisites, []string{"tech_a"}) {
+		t.Errorf("Expected the prerequisite of tech_d, got %v", d.Prerequisites)
+	}
+	if len(d.WeightModifiers) != 1 || d.WeightModifiers[0].Factor != 2 || len(d.WeightModifiers[0].Conditions) != 1 {
+		t.Errorf("Expected a one-line weight modifier with its condition, got %+v", d.WeightModifiers)
+	}
+}
+
+func TestParseIdentifierKeys(t *testing.T) {
+	parser := NewTechParser()
+	content := `"09_tech" = { area = physics }
+tech.sub:variant = { area = society }
+"tech with space" = { area = engineering }
+@[ 1 + 2 ] = { area = physics }
+`
+	techs := parser.parseContent(content, "keys.txt")
+	for key, area := range map[string]string{"09_tech": "physics", "tech.sub:variant": "society", "tech with space": "engineering"} {
+		if tech := techs[key]; tech == nil || tech.Area != area {
+			t.Errorf("Expected %s in %s, got %+v", key, area, tech)
+		}
+	}
+	if len(techs) != 3 {
+		t.Errorf("Expected 3 technologies, got %d", len(techs))
+	}
+
+	block := parser.parseBlock(`event_target:my_target = { owner.capital = yes }`)
+	target, _ := block["event_target:my_target"].(map[string]interface{})
+	if target["owner.capital"] != true {
+		t.Errorf("Expected the event target block to be parsed, got %v", block)
+	}
+}
+
+// benchmarkContent returns a technology file of n technologies written like
+// the vanilla files, with nested weight modifiers and comments
+func benchmarkContent(n int) string {
+	var content strings.Builder
+	content.WriteString("@tier1cost1 = 500\n@tier1weight1 = 100\n\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&content, `# Technology %[1]d
+tech_bench_%[1]d = {
+	cost = @tier1cost1
+	area = physics
+	tier = 1
+	category = { particles }
+	prerequisites = { "tech_bench_%[2]d" "tech_lasers_1" }
+	weight = @tier1weight1
+	potential = {
+		NOT = { has_country_flag = bench_flag_%[1]d }
+	}
+	weight_modifier = {
+		factor = 0.5
+		modifier = {
+			factor = 1.25
+			research_leader = { area = physics has_trait = "leader_trait_expertise_particles" }
+		}
+		modifier = {
+			factor = 0.1
+			num_owned_planets < 5
+			OR = { has_technology = tech_a has_technology = tech_b }
+		}
+	}
+	ai_weight = {
+		factor = 2
+		modifier = { factor = 0 is_ai = no }
+	}
+}
+
+`, i, max(i-1, 0))
+	}
+	return content.String()
+}
+
+func BenchmarkParseFile(b *testing.B) {
+	content := []byte(benchmarkContent(1000))
+	fsys := fstest.MapFS{"bench.txt": {Data: content}}
+	b.SetBytes(int64(len(content)))
+	b.ReportAllocs()
+	for b.Loop() {
+		parser := NewTechParser()
+		if err := parser.ParseFileFS(fsys, "bench.txt"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStatements(b *testing.B) {
+	content := benchmarkContent(1000)
+	b.SetBytes(int64(len(content)))
+	b.ReportAllocs()
+	for b.Loop() {
+		statements(content)
+	}
+}
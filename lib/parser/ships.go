@@ -0,0 +1,136 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// Locations of ship designer definitions, relative to the game or mod
+// directory
+const (
+	ShipSizesDir        = "common/ship_sizes"
+	SectionTemplatesDir = "common/section_templates"
+)
+
+// utilitySlotSizes are the utility slot sizes of a section, read from
+// <size>_utility_slots
+var utilitySlotSizes = []string{"small", "medium", "large", "aux"}
+
+// ParseShips reads the ship sizes and section templates of the game directory
+// and mods. Each section is added to the sections of its ship size, ordered
+// by slot; sections of unknown ship sizes are left out.
+func ParseShips(gameDir string, modDirs []string) ([]*models.ShipSize, error) {
+	definitions, err := LoadDefinitions(ShipSizesDir, gameDir, modDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ship sizes: %w", err)
+	}
+
+	sizes := make([]*models.ShipSize, 0, len(definitions))
+	byKey := make(map[string]*models.ShipSize, len(definitions))
+	for _, definition := range definitions {
+		size := parseShipSize(definition)
+		sizes = append(sizes, size)
+		byKey[size.Key] = size
+	}
+
+	definitions, err = LoadKeyedDefinitions(SectionTemplatesDir, "key", gameDir, modDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read section templates: %w", err)
+	}
+	for _, definition := range definitions {
+		section := parseSectionTemplate(definition)
+		if size, exists := byKey[section.ShipSize]; exists {
+			size.Sections = append(size.Sections, section)
+		}
+	}
+
+	for _, size := range sizes {
+		position := make(map[string]int, len(size.SectionSlots))
+		for i, slot := range size.SectionSlots {
+			position[slot.Name] = i
+		}
+		slotIndex := func(slot string) int {
+			if i, exists := position[slot]; exists {
+				return i
+			}
+			return len(position)
+		}
+		sort.SliceStable(size.Sections, func(i, j int) bool {
+			return slotIndex(size.Sections[i].Slot) < slotIndex(size.Sections[j].Slot)
+		})
+	}
+	return sizes, nil
+}
+
+// parseShipSize converts a ship size definition. Every top-level number other
+// than the build time is a base stat.
+func parseShipSize(definition Definition) *models.ShipSize {
+	data := definition.Data
+	cost, upkeep := resourceCosts(data)
+	size := &models.ShipSize{
+		Key:           definition.Key,
+		Stats:         make(map[string]float64),
+		Modifiers:     rawBlock(data["modifier"]),
+		Cost:          cost,
+		Upkeep:        upkeep,
+		SectionSlots:  []models.SectionSlot{},
+		Sections:      []*models.SectionTemplate{},
+		Prerequisites: stringList(data["prerequisites"]),
+		Potential:     rawBlock(data["potential"]),
+		SourceFile:    definition.SourceFile,
+		Mod:           definition.Mod,
+	}
+	size.Class, _ = data["class"].(string)
+	size.Icon, _ = data["icon"].(string)
+	size.BuildTime, _ = data["base_buildtime"].(int)
+
+	for key, value := range data {
+		if n, ok := number(value); ok && key != "base_buildtime" {
+			size.Stats[key] = n
+		}
+	}
+
+	// Slots keep their file order, which Data loses
+	for _, block := range namedBlocks(definition.content) {
+		if block.name != "section_slots" {
+			continue
+		}
+		for _, slot := range blocksIn(block.body) {
+			size.SectionSlots = append(size.SectionSlots, models.SectionSlot{Name: slot.name, Locator: simpleFields(slot.body)["locator"]})
+		}
+	}
+	return size
+}
+
+// parseSectionTemplate converts a section template definition
+func parseSectionTemplate(definition Definition) *models.SectionTemplate {
+	data := definition.Data
+	cost, _ := resourceCosts(data)
+	section := &models.SectionTemplate{
+		Key:            definition.Key,
+		ComponentSlots: []models.ComponentSlot{},
+		UtilitySlots:   make(map[string]int),
+		Cost:           cost,
+		Prerequisites:  stringList(data["prerequisites"]),
+		SourceFile:     definition.SourceFile,
+		Mod:            definition.Mod,
+	}
+	section.ShipSize, _ = data["ship_size"].(string)
+	section.Slot, _ = data["fits_on_slot"].(string)
+	section.Icon, _ = data["icon"].(string)
+
+	for _, slot := range definition.Blocks("component_slot") {
+		name, _ := slot["name"].(string)
+		template, _ := slot["template"].(string)
+		locator, _ := slot["locatorname"].(string)
+		section.ComponentSlots = append(section.ComponentSlots, models.ComponentSlot{Name: name, Template: template, Locator: locator})
+	}
+	for _, size := range utilitySlotSizes {
+		if count, ok := data[size+"_utility_slots"].(int); ok && count > 0 {
+			section.UtilitySlots[size] = count
+		}
+	}
+	return section
+}
@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseShips(t *testing.T) {
+	gameDir := t.TempDir()
+	writeFiles(t, map[string]string{
+		filepath.Join(gameDir, ShipSizesDir, "00_ship_sizes.txt"): `destroyer = {
+	max_speed = 140
+	max_hitpoints = 600
+	base_buildtime = 90
+	class = shipclass_military
+	icon = ship_size_military_2
+	modifier = {
+		ship_evasion_add = 35
+	}
+	section_slots = { "bow" = { locator = "part1" } "stern" = { locator = "part2" } }
+	resources = {
+		category = ship_sizes
+		upkeep = { energy = 1 }
+	}
+	prerequisites = { "tech_destroyers" }
+}
+`,
+		filepath.Join(gameDir, SectionTemplatesDir, "destroyer.txt"): `ship_section_template = {
+	key = "DESTROYER_STERN_S"
+	ship_size = destroyer
+	fits_on_slot = stern
+	small_utility_slots = 1
+}
+
+ship_section_template = {
+	key = "DESTROYER_BOW_M1S2"
+	ship_size = destroyer
+	fits_on_slot = bow
+	component_slot = {
+		name = "MEDIUM_GUN_01"
+		template = "medium_turret"
+		locatorname = "medium_gun_01"
+	}
+	component_slot = {
+		name = "SMALL_GUN_01"
+		template = "small_turret"
+		locatorname = "small_gun_01"
+	}
+	small_utility_slots = 2
+	aux_utility_slots = 1
+	resources = {
+		category = ship_sections
+		cost = { alloys = 10 }
+	}
+}
+
+ship_section_template = {
+	key = "CRUISER_MID_L"
+	ship_size = cruiser
+	fits_on_slot = mid
+}
+`,
+	})
+
+	sizes, err := ParseShips(gameDir, nil)
+	if err != nil {
+		t.Fatalf("Failed to parse ships: %v", err)
+	}
+	if len(sizes) != 1 {
+		t.Fatalf("Expected 1 ship size, got %d", len(sizes))
+	}
+
+	destroyer := sizes[0]
+	if destroyer.Stats["max_speed"] != 140 || destroyer.Stats["max_hitpoints"] != 600 || destroyer.BuildTime != 90 {
+		t.Errorf("Expected base stats and build time, got %v and %d", destroyer.Stats, destroyer.BuildTime)
+	}
+	if _, exists := destroyer.Stats["base_buildtime"]; exists {
+		t.Errorf("Expected the build time not to be a stat")
+	}
+	if destroyer.Class != "shipclass_military" || destroyer.Upkeep["energy"] != 1 || destroyer.Modifiers["ship_evasion_add"] != 35 {
+		t.Errorf("Expected class, upkeep and modifiers, got %+v", destroyer)
+	}
+	if len(destroyer.SectionSlots) != 2 || destroyer.SectionSlots[0].Name != "bow" || destroyer.SectionSlots[1].Locator != "part2" {
+		t.Errorf("Expected the bow and stern slots, got %+v", destroyer.SectionSlots)
+	}
+
+	if len(destroyer.Sections) != 2 {
+		t.Fatalf("Expected the destroyer sections only, got %+v", destroyer.Sections)
+	}
+	bow, stern := destroyer.Sections[0], destroyer.Sections[1]
+	if bow.Key != "DESTROYER_BOW_M1S2" || stern.Key != "DESTROYER_STERN_S" {
+		t.Errorf("Expected sections in slot order, got %s and %s", bow.Key, stern.Key)
+	}
+	if len(bow.ComponentSlots) != 2 || bow.ComponentSlots[0].Template != "medium_turret" || bow.ComponentSlots[1].Locator != "small_gun_01" {
+		t.Errorf("Expected both component slots, got %+v", bow.ComponentSlots)
+	}
+	if bow.UtilitySlots["small"] != 2 || bow.UtilitySlots["aux"] != 1 || bow.Cost["alloys"] != 10 {
+		t.Errorf("Expected utility slots and cost, got %v and %v", bow.UtilitySlots, bow.Cost)
+	}
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// SituationsDir is the location of situations, relative to the game or mod
+// directory
+const SituationsDir = "common/situations"
+
+// ParseSituations reads the situations of the game directory and mods
+func ParseSituations(gameDir string, modDirs []string) ([]*models.Situation, error) {
+	definitions, err := LoadDefinitions(SituationsDir, gameDir, modDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read situations: %w", err)
+	}
+
+	var p TechParser
+	situations := make([]*models.Situation, 0, len(definitions))
+	for _, definition := range definitions {
+		data := definition.Data
+		situation := &models.Situation{
+			Key:             definition.Key,
+			MonthlyProgress: data["monthly_progress"],
+			Stages:          []models.SituationStage{},
+			Approaches:      []models.SituationApproach{},
+			LinkedEvents:    linkedEvents(definition),
+			SourceFile:      definition.SourceFile,
+			Mod:             definition.Mod,
+		}
+		situation.Category, _ = data["category"].(string)
+
+		// Stages and approaches keep their file order, which Data loses
+		for _, block := range namedBlocks(definition.content) {
+			for _, entry := range blocksIn(block.body) {
+				fields := simpleFields(entry.body)
+				nested := p.parseStatements(entry.body)
+				switch block.name {
+				case "stages":
+					end, _ := strconv.Atoi(fields["end"])
+					situation.Stages = append(situation.Stages, models.SituationStage{
+						Key:       entry.name,
+						End:       end,
+						Icon:      fields["icon"],
+						Modifiers: rawBlock(nested["modifier"]),
+					})
+				case "approaches":
+					situation.Approaches = append(situation.Approaches, models.SituationApproach{
+						Key:       entry.name,
+						NameKey:   fields["name"],
+						Icon:      fields["icon"],
+						Potential: rawBlock(nested["potential"]),
+						Allow:     rawBlock(nested["allow"]),
+						Modifiers: rawBlock(nested["modifier"]),
+					})
+				}
+			}
+		}
+		situations = append(situations, situation)
+	}
+	return situations, nil
+}
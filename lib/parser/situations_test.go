@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseSituations(t *testing.T) {
+	gameDir := t.TempDir()
+	modDir := filepath.Join(t.TempDir(), "my_mod")
+	writeFiles(t, map[string]string{
+		filepath.Join(gameDir, SituationsDir, "00_situations.txt"): `situation_food_shortage = {
+	category = negative
+	monthly_progress = 1
+	stages = {
+		stage_3 = { end = 100 icon = "GFX_situation_stage_3" }
+		stage_1 = {
+			end = 25
+			modifier = {
+				pop_happiness = -0.05
+			}
+		}
+	}
+	approaches = {
+		approach_rationing = {
+			name = "approach_rationing_name"
+			icon = "GFX_approach_rationing"
+			potential = {
+				is_gestalt = no
+			}
+			modifier = {
+				pop_food_req_mult = -0.1
+			}
+		}
+		approach_ignore = {
+			allow = { has_policy_flag = ignore_shortages }
+		}
+	}
+	on_fail = {
+		country_event = { id = situation.10 }
+	}
+}
+`,
+		filepath.Join(modDir, SituationsDir, "mod_situations.txt"): "situation_mod = {\n\tmonthly_progress = {\n\t\tbase = 2\n\t}\n}\n",
+	})
+
+	situations, err := ParseSituations(gameDir, []string{modDir})
+	if err != nil {
+		t.Fatalf("Failed to parse situations: %v", err)
+	}
+	if len(situations) != 2 {
+		t.Fatalf("Expected 2 situations, got %d", len(situations))
+	}
+
+	shortage := situations[0]
+	if shortage.Category != "negative" || shortage.MonthlyProgress != 1 {
+		t.Errorf("Unexpected situation %+v", shortage)
+	}
+	if len(shortage.Stages) != 2 {
+		t.Fatalf("Expected 2 stages, got %v", shortage.Stages)
+	}
+	if stage := shortage.Stages[0]; stage.Key != "stage_3" || stage.End != 100 || stage.Icon != "GFX_situation_stage_3" {
+		t.Errorf("Expected the one-line stage first, got %+v", stage)
+	}
+	if stage := shortage.Stages[1]; stage.End != 25 || stage.Modifiers["pop_happiness"] != -0.05 {
+		t.Errorf("Unexpected stage %+v", stage)
+	}
+
+	if len(shortage.Approaches) != 2 {
+		t.Fatalf("Expected 2 approaches, got %v", shortage.Approaches)
+	}
+	rationing, ignore := shortage.Approaches[0], shortage.Approaches[1]
+	if rationing.Key != "approach_rationing" || rationing.NameKey != "approach_rationing_name" || rationing.Icon != "GFX_approach_rationing" {
+		t.Errorf("Unexpected approach %+v", rationing)
+	}
+	if rationing.Potential["is_gestalt"] != false || rationing.Modifiers["pop_food_req_mult"] != -0.1 {
+		t.Errorf("Unexpected conditions %v or modifiers %v", rationing.Potential, rationing.Modifiers)
+	}
+	if ignore.Allow["has_policy_flag"] != "ignore_shortages" {
+		t.Errorf("Expected the allow conditions of the one-line block, got %v", ignore.Allow)
+	}
+	if !reflect.DeepEqual(shortage.LinkedEvents, []string{"situation.10"}) {
+		t.Errorf("Expected the linked event situation.10, got %v", shortage.LinkedEvents)
+	}
+
+	if progress, ok := situations[1].MonthlyProgress.(map[string]interface{}); !ok || progress["base"] != 2 || situations[1].Mod != "my_mod" {
+		t.Errorf("Unexpected situation %+v", situations[1])
+	}
+}
@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/progress"
+)
+
+// ParseDirectoryStream parses the technology files in a directory one at a
+// time and calls fn with each technology as soon as its file is parsed,
+// instead of keeping them in the parser. Only one file is held in memory,
+// which suits tools that filter or count technologies.
+//
+// Files are read in lexical path order and the technologies of a file in
+// key order. Definitions are not merged: a technology defined in several
+// files is passed to fn once per definition, and GetTechnologies and
+// GetOverrides are left untouched. Categories and diagnostics are recorded
+// as by ParseDirectory. Parsing stops at the first error returned by fn,
+// which is returned. fn must not start another parse call on the parser,
+// which would wait for this one to finish.
+func (p *TechParser) ParseDirectoryStream(path string, fn func(*models.Technology) error) error {
+	return p.ParseDirectoryStreamContext(context.Background(), path, fn)
+}
+
+// ParseDirectoryStreamContext is ParseDirectoryStream with a context that
+// stops parsing when it is cancelled
+func (p *TechParser) ParseDirectoryStreamContext(ctx context.Context, path string, fn func(*models.Technology) error) error {
+	p.parsing.Lock()
+	defer p.parsing.Unlock()
+	return p.streamFS(ctx, os.DirFS(path), ".", osPath(path), fn)
+}
+
+// ParseFSStream is ParseDirectoryStream for the directory dir of a file
+// system
+func (p *TechParser) ParseFSStream(fsys fs.FS, dir string, fn func(*models.Technology) error) error {
+	p.parsing.Lock()
+	defer p.parsing.Unlock()
+	return p.streamFS(context.Background(), fsys, dir, fsPath, fn)
+}
+
+// streamFS parses the technology files below dir sequentially and passes
+// their technologies to fn, see ParseDirectoryStream
+func (p *TechParser) streamFS(ctx context.Context, fsys fs.FS, dir string, location func(string) string, fn func(*models.Technology) error) error {
+	paths, err := technologyFiles(ctx, fsys, dir, p.fileFilter)
+	if err != nil {
+		return directoryError(err, location(dir))
+	}
+
+	if err := p.parseCategoryFS(fsys, path.Join(dir, CategoryDir)); err != nil {
+		return fmt.Errorf("failed to parse categories: %w", err)
+	}
+
+	for i, name := range paths {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		result := p.readFile(fsys, name, location(name))
+		p.progress.Report(progress.StageParse, i+1, len(paths), result.path)
+		p.mu.Lock()
+		p.diagnostics = append(p.diagnostics, result.diagnostics...)
+		p.mu.Unlock()
+		if result.err != nil {
+			// Technologies that could still be read are passed on in
+			// lenient mode
+			if err := p.fileError(result, result.err); err != nil {
+				return err
+			}
+		} else {
+			p.logger.Debug("Parsed technology file", "file", result.path, "technologies", len(result.technologies))
+		}
+
+		keys := make([]string, 0, len(result.technologies))
+		for key := range result.technologies {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			tech := result.technologies[key]
+			tech.Mod = p.mod
+			if err := fn(tech); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+func TestParseFSStream(t *testing.T) {
+	fsys := fstest.MapFS{
+		"technology/00_b.txt":             {Data: []byte("tech_b = { area = society }\ntech_a = { area = physics }\n")},
+		"technology/01_broken.txt":        {Data: []byte("tech_c = {\n\tarea = engineering\n")},
+		"technology/02_override.txt":      {Data: []byte("tech_a = { area = engineering }\n")},
+		"technology/category/00_cat.txt":  {Data: []byte("particles = { icon = \"gfx/particles.dds\" }\n")},
+		"technology/tier/00_tier.txt":     {Data: []byte("1 = { }\n")},
+		"technology/not_a_technology.yml": {Data: []byte("tech_d = { }\n")},
+	}
+
+	parser := NewTechParser()
+	var got []string
+	err := parser.ParseFSStream(fsys, "technology", func(tech *models.Technology) error {
+		got = append(got, tech.Key+":"+tech.Area)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ParseFSStream failed: %v", err)
+	}
+
+	want := []string{"tech_a:physics", "tech_b:society", "tech_c:engineering", "tech_a:engineering"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %v in file and key order, got %v", want, got)
+			break
+		}
+	}
+
+	if len(parser.GetTechnologies()) != 0 {
+		t.Error("Expected streamed technologies not to be kept")
+	}
+	if len(parser.GetDiagnostics()) == 0 {
+		t.Error("Expected a diagnostic for the unclosed block")
+	}
+	if len(parser.GetCategories()) != 1 {
+		t.Errorf("Expected the category to be read, got %v", parser.GetCategories())
+	}
+}
+
+func TestParseFSStreamStop(t *testing.T) {
+	fsys := fstest.MapFS{
+		"technology/00_a.txt": {Data: []byte("tech_a = { }\ntech_b = { }\n")},
+		"technology/01_c.txt": {Data: []byte("tech_c = { }\n")},
+	}
+
+	stop := errors.New("stop")
+	count := 0
+	err := NewTechParser().ParseFSStream(fsys, "technology", func(tech *models.Technology) error {
+		count++
+		return stop
+	})
+	if !errors.Is(err, stop) || count != 1 {
+		t.Errorf("Expected the callback error after one technology, got %v after %d", err, count)
+	}
+
+	parser := NewTechParser()
+	parser.SetStrict(true)
+	broken := fstest.MapFS{"technology/00_broken.txt": {Data: []byte("tech_a = {\n")}}
+	var parseErr *ParseError
+	if err := parser.ParseFSStream(broken, "technology", func(*models.Technology) error { return nil }); !errors.As(err, &parseErr) {
+		t.Errorf("Expected a parse error in strict mode, got %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// ScriptedTriggersDir is the location of scripted triggers, relative to the
+// game or mod directory
+const ScriptedTriggersDir = "common/scripted_triggers"
+
+// ScriptedTriggers holds the condition block of each scripted trigger, by
+// name
+type ScriptedTriggers map[string]map[string]interface{}
+
+// ParseScriptedTriggers reads the scripted triggers of the game directory and
+// mods
+func ParseScriptedTriggers(gameDir string, modDirs []string) (ScriptedTriggers, error) {
+	definitions, err := LoadDefinitions(ScriptedTriggersDir, gameDir, modDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scripted triggers: %w", err)
+	}
+
+	triggers := make(ScriptedTriggers, len(definitions))
+	for _, definition := range definitions {
+		triggers[definition.Key] = definition.Data
+	}
+	return triggers, nil
+}
+
+// Expand returns a copy of a condition block in which every scripted trigger
+// used as name = yes is replaced by its conditions in an AND block, and every
+// name = no by its conditions in a NOT block, at any depth. Triggers used in
+// their own definition are kept as they are, as are triggers whose AND or NOT
+// block can't be added without changing the meaning of the block. Triggers
+// taking parameters are never expanded.
+func (t ScriptedTriggers) Expand(block map[string]interface{}) map[string]interface{} {
+	return t.expand(block, nil, false)
+}
+
+// expand expands block; stack holds the triggers being expanded and
+// disjunctive is set for the contents of OR and NOR blocks, where each
+// condition is an alternative
+func (t ScriptedTriggers) expand(block map[string]interface{}, stack []string, disjunctive bool) map[string]interface{} {
+	result := make(map[string]interface{}, len(block))
+	var required, forbidden []string
+	for key, value := range block {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			result[key] = t.expand(v, stack, key == "OR" || key == "NOR")
+			continue
+		case models.Repeated:
+			// Each of several blocks such as OR is expanded on its own
+			expanded := make(models.Repeated, len(v))
+			for i, entry := range v {
+				if nested, ok := entry.(map[string]interface{}); ok {
+					entry = t.expand(nested, stack, key == "OR" || key == "NOR")
+				}
+				expanded[i] = entry
+			}
+			result[key] = expanded
+			continue
+		case bool:
+			if _, exists := t[key]; exists && !slices.Contains(stack, key) {
+				if v {
+					required = append(required, key)
+				} else {
+					forbidden = append(forbidden, key)
+				}
+				continue
+			}
+		}
+		result[key] = value
+	}
+
+	sort.Strings(required)
+	for _, name := range required {
+		if !inlineConditions(result, "AND", t.expand(t[name], append(stack, name), false), !disjunctive) {
+			result[name] = true
+		}
+	}
+	// A NOT block forbids its conditions together, so each trigger needs a
+	// block of its own; NAND has the same meaning
+	sort.Strings(forbidden)
+	for _, name := range forbidden {
+		conditions := t.expand(t[name], append(stack, name), false)
+		if !inlineConditions(result, "NOT", conditions, false) && !inlineConditions(result, "NAND", conditions, false) {
+			result[name] = false
+		}
+	}
+	return result
+}
+
+// inlineConditions adds conditions to block as a new block under key. With
+// extend, an existing block is extended instead when none of the conditions
+// is already in it, which only keeps the meaning of AND blocks outside OR
+// blocks. It reports whether the conditions were added.
+func inlineConditions(block map[string]interface{}, key string, conditions map[string]interface{}, extend bool) bool {
+	existing, exists := block[key]
+	if !exists {
+		block[key] = conditions
+		return true
+	}
+
+	target, isBlock := existing.(map[string]interface{})
+	if !extend || !isBlock {
+		return false
+	}
+	for condition := range conditions {
+		if _, clash := target[condition]; clash {
+			return false
+		}
+	}
+	for condition, value := range conditions {
+		target[condition] = value
+	}
+	return true
+}
+
+// ExpandPotentials expands the scripted triggers in the potential of every
+// parsed technology
+func (p *TechParser) ExpandPotentials(triggers ScriptedTriggers) {
+	p.parsing.Lock()
+	defer p.parsing.Unlock()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, tech := range p.technologies {
+		if tech.Potential != nil {
+			tech.Potential = p.parseCondition(triggers.Expand(tech.Potential.Raw))
+		}
+	}
+}
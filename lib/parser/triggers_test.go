@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseScriptedTriggers(t *testing.T) {
+	gameDir := t.TempDir()
+	modDir := filepath.Join(t.TempDir(), "my_mod")
+	writeFiles(t, map[string]string{
+		filepath.Join(gameDir, ScriptedTriggersDir, "00_scripted_triggers.txt"): "is_machine_empire = {\n\thas_authority = auth_machine_intelligence\n}\nis_gestalt = {\n\tOR = {\n\t\tis_machine_empire = yes\n\t\tis_hive_empire = yes\n\t}\n}\n",
+		filepath.Join(modDir, ScriptedTriggersDir, "mod_triggers.txt"):          "is_machine_empire = {\n\thas_authority = auth_mod_machine\n}\n",
+	})
+
+	triggers, err := ParseScriptedTriggers(gameDir, []string{modDir})
+	if err != nil {
+		t.Fatalf("Failed to parse scripted triggers: %v", err)
+	}
+	if len(triggers) != 2 {
+		t.Fatalf("Expected 2 triggers, got %v", triggers)
+	}
+	if triggers["is_machine_empire"]["has_authority"] != "auth_mod_machine" {
+		t.Errorf("Expected the mod to replace is_machine_empire, got %v", triggers["is_machine_empire"])
+	}
+}
+
+func TestExpandScriptedTriggers(t *testing.T) {
+	triggers := ScriptedTriggers{
+		"is_machine_empire": {"has_authority": "auth_machine_intelligence"},
+		"is_hive_empire":    {"has_authority": "auth_hive_mind"},
+		"is_gestalt":        {"OR": map[string]interface{}{"is_machine_empire": true, "is_hive_empire": true}},
+		"is_recursive":      {"is_recursive": true},
+	}
+
+	tests := map[string]struct {
+		block    map[string]interface{}
+		expected map[string]interface{}
+	}{
+		"yes becomes an AND block": {
+			block:    map[string]interface{}{"is_machine_empire": true, "is_ai": false},
+			expected: map[string]interface{}{"AND": map[string]interface{}{"has_authority": "auth_machine_intelligence"}, "is_ai": false},
+		},
+		"no becomes a NOT block": {
+			block:    map[string]interface{}{"is_machine_empire": false},
+			expected: map[string]interface{}{"NOT": map[string]interface{}{"has_authority": "auth_machine_intelligence"}},
+		},
+		"nested triggers": {
+			block: map[string]interface{}{"is_gestalt": true},
+			expected: map[string]interface{}{"AND": map[string]interface{}{"OR": map[string]interface{}{
+				"AND":               map[string]interface{}{"has_authority": "auth_hive_mind"},
+				"is_machine_empire": true,
+			}}},
+		},
+		"alternatives are not merged": {
+			block: map[string]interface{}{"OR": map[string]interface{}{"is_machine_empire": true, "is_ai": true, "AND": map[string]interface{}{"is_pacifist": true}}},
+			expected: map[string]interface{}{"OR": map[string]interface{}{
+				"is_machine_empire": true, "is_ai": true, "AND": map[string]interface{}{"is_pacifist": true},
+			}},
+		},
+		"existing AND block is extended": {
+			block:    map[string]interface{}{"AND": map[string]interface{}{"is_ai": false}, "is_machine_empire": true},
+			expected: map[string]interface{}{"AND": map[string]interface{}{"is_ai": false, "has_authority": "auth_machine_intelligence"}},
+		},
+		"second NOT uses NAND": {
+			block: map[string]interface{}{"is_machine_empire": false, "is_hive_empire": false},
+			expected: map[string]interface{}{
+				"NAND": map[string]interface{}{"has_authority": "auth_machine_intelligence"},
+				"NOT":  map[string]interface{}{"has_authority": "auth_hive_mind"},
+			},
+		},
+		"recursive trigger is kept": {
+			block:    map[string]interface{}{"is_recursive": true},
+			expected: map[string]interface{}{"AND": map[string]interface{}{"is_recursive": true}},
+		},
+		"unknown condition is kept": {
+			block:    map[string]interface{}{"has_ethic": "ethic_pacifist"},
+			expected: map[string]interface{}{"has_ethic": "ethic_pacifist"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := triggers.Expand(tt.block); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestExpandPotentials(t *testing.T) {
+	p := NewTechParser()
+	tech := p.parseTechnologyBlock("tech_robotic_workers", statements("\tarea = engineering\n\tpotential = {\n\t\tis_machine_empire = no\n\t}\n"))
+	p.technologies[tech.Key] = tech
+
+	p.ExpandPotentials(ScriptedTriggers{"is_machine_empire": {"has_authority": "auth_machine_intelligence"}})
+
+	expected := map[string]interface{}{"has_authority": "auth_machine_intelligence"}
+	if tech.Potential.Type != "NOT" || !reflect.DeepEqual(tech.Potential.Raw["NOT"], expected) {
+		t.Errorf("Expected the potential to forbid the trigger's conditions, got %+v", tech.Potential)
+	}
+}
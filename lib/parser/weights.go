@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// logicalOperators are the condition blocks combining their children, as
+// opposed to scope and comparison blocks
+var logicalOperators = map[string]bool{"AND": true, "OR": true, "NOT": true, "NOR": true, "NAND": true}
+
+// parseWeightModifiers parses a weight_modifier or ai_weight block. Its own
+// factor and add form an unconditional modifier, and each modifier block a
+// modifier applied when all of its conditions hold, in file order.
+func (p *TechParser) parseWeightModifiers(body []statement) []models.WeightModifier {
+	modifiers := []models.WeightModifier{}
+
+	if modifier, ok := p.weightFactors(body); ok {
+		modifiers = append(modifiers, modifier)
+	}
+	for _, st := range body {
+		if st.key != "modifier" || !st.isBlock {
+			continue
+		}
+		modifier, _ := p.weightFactors(st.children)
+		for _, condition := range p.parseConditionList(st.children) {
+			if condition.Key != "factor" && condition.Key != "add" {
+				modifier.Conditions = append(modifier.Conditions, condition)
+			}
+		}
+		modifiers = append(modifiers, modifier)
+	}
+
+	return modifiers
+}
+
+// weightFactors returns a modifier with the factor and add of a block, and
+// whether the block has either. The factor is 1 when the block has none.
+func (p *TechParser) weightFactors(body []statement) (models.WeightModifier, bool) {
+	modifier := models.WeightModifier{Factor: 1, Conditions: []models.Condition{}}
+	hasFactor, hasAdd := false, false
+	for _, st := range body {
+		if st.isBlock || st.operator != "=" {
+			continue
+		}
+		// A repeated key keeps its last value
+		switch st.key {
+		case "factor":
+			if factor, ok := number(p.parseValue(st.value)); ok {
+				modifier.Factor, hasFactor = factor, true
+			}
+		case "add":
+			if add, ok := number(p.parseValue(st.value)); ok {
+				modifier.Add, hasAdd = add, true
+			}
+		}
+	}
+	return modifier, hasFactor || hasAdd
+}
+
+// parseConditionList parses the conditions of a block in file order,
+// keeping the repeated keys that parseBlock drops.
+// Blocks become conditions with their conditions as children; logical
+// blocks such as OR also have their operator as Type.
+func (p *TechParser) parseConditionList(body []statement) []models.Condition {
+	return p.conditionList(body, nil)
+}
+
+// conditionList parses the conditions of a block, see parseConditionList,
+// and adds them to raw as parseStatements would when raw isn't nil. The Raw
+// map of each block is filled while its children are parsed, so nested
+// blocks are read once.
+func (p *TechParser) conditionList(body []statement, raw map[string]interface{}) []models.Condition {
+	conditions := make([]models.Condition, 0, len(body))
+
+	for _, st := range body {
+		if st.key == "" {
+			continue
+		}
+
+		condition := models.Condition{Key: st.key, Operator: st.operator, Children: []models.Condition{}}
+		if st.isBlock && logicalOperators[st.key] {
+			condition.Type = st.key
+		}
+		var value interface{}
+		switch {
+		case st.isBlock && isList(st.children):
+			condition.Raw = map[string]interface{}{}
+			value = p.listValues(st.children)
+		case st.isBlock:
+			condition.Raw = make(map[string]interface{}, len(st.children))
+			condition.Children = p.conditionList(st.children, condition.Raw)
+			value = condition.Raw
+		default:
+			condition.Value = p.parseValue(st.value)
+			value = condition.Value
+			if st.operator != "=" {
+				value = models.Comparison{Operator: st.operator, Value: condition.Value}
+			}
+		}
+		if raw != nil {
+			addValue(raw, st.key, value)
+		}
+		conditions = append(conditions, condition)
+	}
+
+	return conditions
+}
@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestParseWeightModifiers(t *testing.T) {
+	parser := NewTechParser()
+	content := `tech_weighted = {
+	area = physics
+	weight_modifier = {
+		factor = 0.5
+		modifier = {
+			factor = 1.25
+			has_tradition = tr_discovery_adopt
+		}
+		modifier = {
+			add = 10
+			num_owned_planets > 5
+			OR = {
+				has_technology = tech_a
+				has_technology = tech_b
+			}
+		}
+	}
+	ai_weight = {
+		modifier = {
+			factor = 0
+			is_ai = yes
+		}
+	}
+}
+`
+	tech := parser.parseContent(content, "weights.txt")["tech_weighted"]
+	if tech == nil {
+		t.Fatal("Expected tech_weighted to be parsed")
+	}
+
+	modifiers := tech.WeightModifiers
+	if len(modifiers) != 3 {
+		t.Fatalf("Expected 3 weight modifiers, got %+v", modifiers)
+	}
+	if modifiers[0].Factor != 0.5 || len(modifiers[0].Conditions) != 0 {
+		t.Errorf("Expected an unconditional factor of 0.5, got %+v", modifiers[0])
+	}
+
+	tradition := modifiers[1]
+	if tradition.Factor != 1.25 || len(tradition.Conditions) != 1 {
+		t.Fatalf("Expected a factor of 1.25 with one condition, got %+v", tradition)
+	}
+	if c := tradition.Conditions[0]; c.Key != "has_tradition" || c.Operator != "=" || c.Value != "tr_discovery_adopt" {
+		t.Errorf("Unexpected tradition condition %+v", c)
+	}
+
+	planets := modifiers[2]
+	if planets.Factor != 1 || planets.Add != 10 || len(planets.Conditions) != 2 {
+		t.Fatalf("Expected an add of 10 with two conditions, got %+v", planets)
+	}
+	if c := planets.Conditions[0]; c.Key != "num_owned_planets" || c.Operator != ">" || c.Value != 5 {
+		t.Errorf("Expected the comparison to keep its operator, got %+v", c)
+	}
+	or := planets.Conditions[1]
+	if or.Type != "OR" || len(or.Children) != 2 {
+		t.Fatalf("Expected an OR with both repeated conditions, got %+v", or)
+	}
+	if or.Children[0].Value != "tech_a" || or.Children[1].Value != "tech_b" {
+		t.Errorf("Expected the OR children in file order, got %+v", or.Children)
+	}
+
+	if len(tech.AIWeightModifiers) != 1 || tech.AIWeightModifiers[0].Factor != 0 {
+		t.Errorf("Expected an AI weight modifier with a factor of 0, got %+v", tech.AIWeightModifiers)
+	}
+	if _, exists := tech.ExtraFlags["weight_modifier"]; exists {
+		t.Error("Expected weight_modifier not to be an extra flag")
+	}
+}
+
+func TestParseRepeatedWeightModifiers(t *testing.T) {
+	parser := NewTechParser()
+	content := `tech_repeated = {
+	area = physics
+	weight_modifier = {
+		factor = 0.5
+	}
+	weight_modifier = {
+		modifier = {
+			factor = 2
+			has_tradition = tr_discovery_adopt
+		}
+	}
+	ai_weight = {
+		factor = 3
+	}
+	ai_weight = {
+		modifier = {
+			factor = 0
+			is_ai = yes
+		}
+	}
+}
+`
+	tech := parser.parseContent(content, "weights.txt")["tech_repeated"]
+	if tech == nil {
+		t.Fatal("Expected tech_repeated to be parsed")
+	}
+
+	modifiers := tech.WeightModifiers
+	if len(modifiers) != 2 || modifiers[0].Factor != 0.5 || modifiers[1].Factor != 2 {
+		t.Errorf("Expected the modifiers of both weight_modifier blocks in file order, got %+v", modifiers)
+	}
+	ai := tech.AIWeightModifiers
+	if len(ai) != 2 || ai[0].Factor != 3 || ai[1].Factor != 0 {
+		t.Errorf("Expected the modifiers of both ai_weight blocks in file order, got %+v", ai)
+	}
+}
+
+func TestParseWeightGroups(t *testing.T) {
+	parser := NewTechParser()
+	content := `tech_grouped = {
+	area = physics
+	weight_groups = {
+		repeatable
+	}
+	mod_weight_if_group_picked = {
+		repeatable = 0.01
+	}
+}
+`
+	tech := parser.parseContent(content, "groups.txt")["tech_grouped"]
+	if tech == nil {
+		t.Fatal("Expected tech_grouped to be parsed")
+	}
+	if len(tech.WeightGroups) != 1 || tech.WeightGroups[0] != "repeatable" {
+		t.Errorf("Expected the repeatable weight group, got %v", tech.WeightGroups)
+	}
+	if factor, ok := tech.ModWeightIfGroupPicked["repeatable"]; !ok || factor != 0.01 {
+		t.Errorf("Expected a factor of 0.01 for the repeatable group, got %v", tech.ModWeightIfGroupPicked)
+	}
+	if _, exists := tech.ExtraFlags["weight_groups"]; exists {
+		t.Error("Expected weight_groups not to be an extra flag")
+	}
+}
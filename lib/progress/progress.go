@@ -0,0 +1,58 @@
+// Package progress reports the progress of long-running stages as
+// line-delimited JSON events.
+package progress
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Stages reported while processing game data
+const (
+	StageParse        = "parse"        // Technology files, one event per file
+	StageLocalization = "localization" // Localization directories, one event per directory
+	StageTree         = "tree"         // Building the technology tree
+	StageGenerate     = "generate"     // JSON files, one event per file
+	StageIcons        = "icons"        // Icons, one event per icon
+	StageDone         = "done"         // Emitted once when the command finished
+)
+
+// Event is a single progress update
+type Event struct {
+	Stage   string `json:"stage"`
+	Current int    `json:"current"`
+	Total   int    `json:"total"` // 0 if the total is not known
+	Message string `json:"message,omitempty"`
+}
+
+// Reporter writes progress events as line-delimited JSON. It is safe for
+// concurrent use. A nil *Reporter discards all events, so callers don't need
+// to check whether progress reporting is enabled.
+type Reporter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewReporter creates a reporter writing to w
+func NewReporter(w io.Writer) *Reporter {
+	return &Reporter{encoder: json.NewEncoder(w)}
+}
+
+// Report writes a progress event
+func (r *Reporter) Report(stage string, current, total int, message string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Progress is best effort; a closed stderr must not stop the work
+	_ = r.encoder.Encode(Event{
+		Stage:   stage,
+		Current: current,
+		Total:   total,
+		Message: message,
+	})
+}
@@ -0,0 +1,57 @@
+package progress
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	var buf bytes.Buffer
+	reporter := NewReporter(&buf)
+
+	reporter.Report(StageParse, 1, 2, "00_phys.txt")
+	reporter.Report(StageDone, 0, 0, "")
+
+	expected := "{\"stage\":\"parse\",\"current\":1,\"total\":2,\"message\":\"00_phys.txt\"}\n" +
+		"{\"stage\":\"done\",\"current\":0,\"total\":0}\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestReportConcurrent(t *testing.T) {
+	var buf bytes.Buffer
+	reporter := NewReporter(&buf)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			reporter.Report(StageIcons, i, 50, "icon")
+		}(i)
+	}
+	wg.Wait()
+
+	// Every line must be a complete event
+	lines := 0
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("Invalid event line %q: %v", scanner.Text(), err)
+		}
+		lines++
+	}
+	if lines != 50 {
+		t.Errorf("Expected 50 events, got %d", lines)
+	}
+}
+
+func TestNilReporter(t *testing.T) {
+	var reporter *Reporter
+	reporter.Report(StageParse, 1, 1, "ignored") // Must not panic
+}
@@ -0,0 +1,256 @@
+// Package savegame reads the empires and their researched technologies from
+// Stellaris save files. Binary (ironman) saves are not supported.
+package savegame
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GamestateFile is the name of the game state inside a .sav archive
+const GamestateFile = "gamestate"
+
+// binaryMarker starts the game state of ironman and other binary saves
+const binaryMarker = "SAV0"
+
+// ErrBinarySave is returned for saves in the binary format, which can't be
+// read
+var ErrBinarySave = errors.New("binary (ironman) saves are not supported")
+
+// Empire is a country of a save with its researched technologies
+type Empire struct {
+	ID           int            `json:"id"`
+	Name         string         `json:"name"`             // Name, or its localization key for generated names
+	Player       string         `json:"player,omitempty"` // Name of the player controlling the empire, empty for AI empires
+	Technologies map[string]int `json:"technologies"`     // Level of each researched technology, by key
+}
+
+// Save holds the empires of a save
+type Save struct {
+	Empires []*Empire // By ID
+}
+
+// Open reads a .sav archive. A plain game state file, as extracted from an
+// archive, is read as well.
+func Open(path string) (*Save, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read save: %w", err)
+	}
+
+	if bytes.HasPrefix(content, []byte("PK")) {
+		archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open save archive %s: %w", path, err)
+		}
+		file, err := archive.Open(GamestateFile)
+		if err != nil {
+			return nil, fmt.Errorf("save archive %s has no %s: %w", path, GamestateFile, err)
+		}
+		defer file.Close()
+		if content, err = io.ReadAll(file); err != nil {
+			return nil, fmt.Errorf("failed to read %s of %s: %w", GamestateFile, path, err)
+		}
+	}
+
+	save, err := Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse save %s: %w", path, err)
+	}
+	return save, nil
+}
+
+// Parse reads the empires from a game state. Only the country names, the
+// technologies of each country's tech_status and the player list are read;
+// everything else is skipped.
+func Parse(gamestate []byte) (*Save, error) {
+	if bytes.HasPrefix(gamestate, []byte(binaryMarker)) {
+		return nil, ErrBinarySave
+	}
+
+	empires := make(map[int]*Empire)
+	players := make(map[int]string)
+	var (
+		path       []string // Keys of the open blocks; "" for list elements
+		pending    string   // Key whose = was just read
+		technology string   // Last technology read in a tech_status block
+		playerName string
+	)
+
+	s := scanner{data: gamestate}
+	for {
+		token, quoted, ok := s.next()
+		if !ok {
+			break
+		}
+
+		switch {
+		case token == "{" && !quoted:
+			path = append(path, pending)
+			pending = ""
+			if len(path) == 2 && path[0] == "country" {
+				if id, err := strconv.Atoi(path[1]); err == nil {
+					empires[id] = &Empire{ID: id, Technologies: make(map[string]int)}
+				}
+			}
+			continue
+		case token == "}" && !quoted:
+			if len(path) == 0 {
+				return nil, fmt.Errorf("unexpected } at offset %d", s.pos)
+			}
+			if len(path) == 2 && path[0] == "player" {
+				playerName = ""
+			}
+			path = path[:len(path)-1]
+			pending = ""
+			continue
+		case token == "=" && !quoted:
+			continue
+		}
+
+		// A key is followed by =, a value is not
+		if s.peekEquals() {
+			pending = token
+			continue
+		}
+		key := pending
+		pending = ""
+
+		switch {
+		case len(path) == 2 && path[0] == "country" && key == "name":
+			if empire := empires[atoi(path[1])]; empire != nil {
+				empire.Name = token
+			}
+		case len(path) == 3 && path[0] == "country" && path[2] == "name" && key == "key":
+			if empire := empires[atoi(path[1])]; empire != nil && empire.Name == "" {
+				empire.Name = token
+			}
+		case len(path) == 3 && path[0] == "country" && path[2] == "tech_status":
+			empire := empires[atoi(path[1])]
+			if empire == nil {
+				continue
+			}
+			if key == "technology" {
+				technology = token
+				empire.Technologies[technology] = 1
+			} else if key == "level" && technology != "" {
+				empire.Technologies[technology] = atoi(token)
+			}
+		case len(path) == 2 && path[0] == "player" && key == "name":
+			playerName = token
+		case len(path) == 2 && path[0] == "player" && key == "country" && playerName != "":
+			players[atoi(token)] = playerName
+		}
+	}
+	if len(path) > 0 {
+		return nil, fmt.Errorf("unexpected end of game state in %s block", path[len(path)-1])
+	}
+
+	save := &Save{}
+	for id, empire := range empires {
+		empire.Player = players[id]
+		save.Empires = append(save.Empires, empire)
+	}
+	sort.Slice(save.Empires, func(i, j int) bool { return save.Empires[i].ID < save.Empires[j].ID })
+	return save, nil
+}
+
+// Find selects an empire by ID or case-insensitive name. An empty selector
+// selects the empire of the first player.
+func (s *Save) Find(selector string) (*Empire, error) {
+	if selector == "" {
+		for _, empire := range s.Empires {
+			if empire.Player != "" {
+				return empire, nil
+			}
+		}
+		return nil, fmt.Errorf("save has no player empire, select one by ID or name")
+	}
+
+	id, err := strconv.Atoi(selector)
+	for _, empire := range s.Empires {
+		if (err == nil && empire.ID == id) || strings.EqualFold(empire.Name, selector) {
+			return empire, nil
+		}
+	}
+	return nil, fmt.Errorf("no empire %q in save", selector)
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// scanner splits a game state into braces, equals signs, quoted strings and
+// words. Comments start with # and run to the end of the line.
+type scanner struct {
+	data []byte
+	pos  int
+}
+
+// next returns the next token and whether it was quoted; quotes are removed
+func (s *scanner) next() (string, bool, bool) {
+	s.skipSpace()
+	if s.pos >= len(s.data) {
+		return "", false, false
+	}
+
+	switch c := s.data[s.pos]; c {
+	case '{', '}', '=':
+		s.pos++
+		return string(c), false, true
+	case '"':
+		// Names may contain escaped quotes
+		var token []byte
+		for s.pos++; s.pos < len(s.data) && s.data[s.pos] != '"'; s.pos++ {
+			if s.data[s.pos] == '\\' && s.pos+1 < len(s.data) {
+				s.pos++
+			}
+			token = append(token, s.data[s.pos])
+		}
+		s.pos++
+		return string(token), true, true
+	}
+
+	start := s.pos
+	for s.pos < len(s.data) && !isDelimiter(s.data[s.pos]) {
+		s.pos++
+	}
+	return string(s.data[start:s.pos]), false, true
+}
+
+// peekEquals reports whether the next token is =, without consuming it
+func (s *scanner) peekEquals() bool {
+	s.skipSpace()
+	return s.pos < len(s.data) && s.data[s.pos] == '='
+}
+
+func (s *scanner) skipSpace() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\r', '\n':
+			s.pos++
+		case '#':
+			for s.pos < len(s.data) && s.data[s.pos] != '\n' {
+				s.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '{', '}', '=', '"', '#':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package savegame
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testGamestate = `version="Cepheus v3.12.4"
+player={
+	{
+		name="Commander \"Zed\""
+		country=1
+	}
+}
+country={
+	0={
+		name={
+			key="EMPIRE_DESIGN_orbis"
+		}
+		tech_status={
+			technology="tech_lasers_1"
+			level=1
+			potential={
+				technology="tech_lasers_2"
+			}
+		}
+	}
+	1={
+		name="United Nations of Earth"
+		flags={
+			name="not the empire name"
+		}
+		tech_status={
+			technology="tech_lasers_1"
+			level=1
+			technology="tech_repeatable_weapon_cost"
+			level=4
+		}
+	}
+	2=none
+}
+`
+
+func TestParse(t *testing.T) {
+	save, err := Parse([]byte(testGamestate))
+	if err != nil {
+		t.Fatalf("Failed to parse game state: %v", err)
+	}
+	if len(save.Empires) != 2 {
+		t.Fatalf("Expected 2 empires, got %d", len(save.Empires))
+	}
+
+	ai, player := save.Empires[0], save.Empires[1]
+	if ai.Name != "EMPIRE_DESIGN_orbis" || ai.Player != "" {
+		t.Errorf("Unexpected AI empire: %+v", ai)
+	}
+	if len(ai.Technologies) != 1 {
+		t.Errorf("Expected only the researched technology, got %v", ai.Technologies)
+	}
+	if player.Name != "United Nations of Earth" || player.Player != `Commander "Zed"` {
+		t.Errorf("Unexpected player empire: %+v", player)
+	}
+	if player.Technologies["tech_repeatable_weapon_cost"] != 4 {
+		t.Errorf("Expected level 4 of the repeatable, got %v", player.Technologies)
+	}
+
+	for selector, id := range map[string]int{"": 1, "0": 0, "united nations of earth": 1} {
+		empire, err := save.Find(selector)
+		if err != nil || empire.ID != id {
+			t.Errorf("Find(%q): expected empire %d, got %v (%v)", selector, id, empire, err)
+		}
+	}
+	if _, err := save.Find("Nobody"); err == nil {
+		t.Error("Expected an error for an unknown empire")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse([]byte("SAV0102xyz")); !errors.Is(err, ErrBinarySave) {
+		t.Errorf("Expected ErrBinarySave, got %v", err)
+	}
+	if _, err := Parse([]byte("country={ 0={ ")); err == nil {
+		t.Error("Expected an error for an unterminated block")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "autosave.sav")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create save: %v", err)
+	}
+	archive := zip.NewWriter(file)
+	for name, content := range map[string]string{"meta": `version="v3.12.4"`, GamestateFile: testGamestate} {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Failed to close archive: %v", err)
+	}
+	file.Close()
+
+	save, err := Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open save: %v", err)
+	}
+	if len(save.Empires) != 2 {
+		t.Errorf("Expected 2 empires, got %d", len(save.Empires))
+	}
+}
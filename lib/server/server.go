@@ -0,0 +1,309 @@
+// Package server serves a technology tree over a read-only REST API, with
+// technologies represented like in the generated files.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/danaketh/StellarisDataParser/lib/filter"
+	"github.com/danaketh/StellarisDataParser/lib/generator"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+const (
+	// DefaultLimit is the page size used when no limit is given
+	DefaultLimit = 100
+	// MaxLimit is the largest page size a client may request
+	MaxLimit = 1000
+)
+
+// Server exposes parsed technology data over a REST API. The data is read
+// once at startup and never modified, so requests are served concurrently
+// without locking.
+type Server struct {
+	tree      *tree.TechTree
+	generator *generator.JSONGenerator
+	nodes     []*tree.TechNode // All nodes sorted by level, then key
+}
+
+// NewServer creates a server for a technology tree. The generator defines
+// how technologies are represented, so the API matches the generated files.
+func NewServer(techTree *tree.TechTree, gen *generator.JSONGenerator) *Server {
+	// GetSortedNodes returns a copy, which can be sorted in place
+	nodes := techTree.GetSortedNodes()
+	sortByLevel(nodes)
+
+	// Compute the lazily built data up front so concurrent requests only
+	// read the generator
+	gen.Precompute()
+
+	return &Server{
+		tree:      techTree,
+		generator: gen,
+		nodes:     nodes,
+	}
+}
+
+// Handler returns the HTTP handler serving the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/technologies", s.handleTechnologies)
+	mux.HandleFunc("GET /api/technologies/{key}", s.handleTechnology)
+	mux.HandleFunc("GET /api/areas", s.handleAreas)
+	mux.HandleFunc("GET /api/tree", s.handleTree)
+	return mux
+}
+
+// sortByLevel sorts nodes in place by level, then key
+func sortByLevel(nodes []*tree.TechNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Level == nodes[j].Level {
+			return nodes[i].Tech.Key < nodes[j].Tech.Key
+		}
+		return nodes[i].Level < nodes[j].Level
+	})
+}
+
+// handleTechnologies lists technologies, optionally filtered by area, tier,
+// category, mod, icon, a key or name prefix (search) or a -where style
+// expression, with offset/limit pagination
+func (s *Server) handleTechnologies(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	offset, err := intParam(query.Get("offset"), 0)
+	if err != nil || offset < 0 {
+		writeError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+		return
+	}
+	limit, err := intParam(query.Get("limit"), DefaultLimit)
+	if err != nil || limit < 1 || limit > MaxLimit {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be an integer between 1 and %d", MaxLimit))
+		return
+	}
+
+	candidates, err := s.candidates(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	expr, err := compileWhere(query.Get("where"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// The expression is evaluated for every candidate; stop when the client
+	// is gone
+	var matched []*tree.TechNode
+	for _, node := range candidates {
+		if r.Context().Err() != nil {
+			return
+		}
+		if expr != nil {
+			ok, err := expr.Match(s.generator.FilterFields(node))
+			if err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("failed to evaluate filter for %s: %v", node.Tech.Key, err))
+				return
+			}
+			if !ok {
+				continue
+			}
+		}
+		matched = append(matched, node)
+	}
+
+	technologies := []generator.TechnologyJSON{}
+	for i := offset; i < len(matched) && i < offset+limit; i++ {
+		technologies = append(technologies, s.generator.Technology(matched[i]))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"total":        len(matched),
+		"offset":       offset,
+		"limit":        limit,
+		"technologies": technologies,
+	})
+}
+
+// technologyResponse is a technology as returned by /api/technologies/{key}
+type technologyResponse struct {
+	generator.TechnologyJSON
+	Unlocks []string `json:"unlocks"` // Keys of the technologies it unlocks, sorted
+}
+
+// handleTechnology returns a single technology with the keys of the
+// technologies it unlocks
+func (s *Server) handleTechnology(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	node, exists := s.tree.GetNode(key)
+	if !exists {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("unknown technology %q", key))
+		return
+	}
+
+	unlocks := make([]string, len(node.Dependents))
+	for i, dependent := range node.Dependents {
+		unlocks[i] = dependent.Tech.Key
+	}
+	sort.Strings(unlocks)
+
+	writeJSON(w, http.StatusOK, technologyResponse{
+		TechnologyJSON: s.generator.Technology(node),
+		Unlocks:        unlocks,
+	})
+}
+
+// handleAreas lists the research areas with the number of technologies in each
+func (s *Server) handleAreas(w http.ResponseWriter, r *http.Request) {
+	areas := []map[string]interface{}{}
+	for _, area := range s.tree.GetAreas() {
+		areas = append(areas, map[string]interface{}{
+			"area":  area,
+			"count": len(s.tree.GetNodesByArea(area)),
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"areas": areas})
+}
+
+// handleTree returns the technology graph as nodes and prerequisite edges,
+// optionally limited to one area. Edges are only included when both ends
+// are part of the result.
+func (s *Server) handleTree(w http.ResponseWriter, r *http.Request) {
+	selected := s.nodes
+	if area := r.URL.Query().Get("area"); area != "" {
+		selected = s.tree.GetNodesByArea(matchFold(s.tree.GetAreas(), area))
+		sortByLevel(selected)
+	}
+
+	included := make(map[string]bool, len(selected))
+	nodes := []map[string]interface{}{}
+	for _, node := range selected {
+		included[node.Tech.Key] = true
+		nodes = append(nodes, map[string]interface{}{
+			"key":   node.Tech.Key,
+			"name":  node.Tech.Name,
+			"area":  node.Tech.Area,
+			"tier":  node.Tech.Tier,
+			"level": node.Level,
+		})
+	}
+
+	edges := []map[string]string{}
+	for _, node := range selected {
+		for _, dep := range node.Dependencies {
+			if included[dep.Tech.Key] {
+				edges = append(edges, map[string]string{"from": dep.Tech.Key, "to": node.Tech.Key})
+			}
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i]["from"] == edges[j]["from"] {
+			return edges[i]["to"] < edges[j]["to"]
+		}
+		return edges[i]["from"] < edges[j]["from"]
+	})
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"nodes": nodes,
+		"edges": edges,
+	})
+}
+
+// candidates returns the nodes matching the area, tier, category, mod, icon
+// and search query parameters, read from the tree's indexes and sorted by
+// level, then key. Returns every node when none is given. Areas, categories
+// and mods match case-insensitively, as in filter expressions.
+func (s *Server) candidates(query url.Values) ([]*tree.TechNode, error) {
+	var lists [][]*tree.TechNode
+	if area := query.Get("area"); area != "" {
+		lists = append(lists, s.tree.GetNodesByArea(matchFold(s.tree.GetAreas(), area)))
+	}
+	if value := query.Get("tier"); value != "" {
+		tier, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("tier must be an integer")
+		}
+		lists = append(lists, s.tree.GetNodesByTier(tier))
+	}
+	if category := query.Get("category"); category != "" {
+		lists = append(lists, s.tree.GetNodesByCategory(matchFold(s.tree.GetCategories(), category)))
+	}
+	if mod := query.Get("mod"); mod != "" {
+		lists = append(lists, s.tree.GetNodesByMod(matchFold(s.tree.GetMods(), mod)))
+	}
+	if icon := query.Get("icon"); icon != "" {
+		lists = append(lists, s.tree.GetNodesByIcon(icon))
+	}
+	if search := query.Get("search"); search != "" {
+		lists = append(lists, s.tree.FindByNamePrefix(search))
+	}
+	if len(lists) == 0 {
+		return s.nodes, nil
+	}
+
+	// Keep the nodes of the shortest list that are in every other list
+	sort.Slice(lists, func(i, j int) bool { return len(lists[i]) < len(lists[j]) })
+	nodes := lists[0]
+	for _, list := range lists[1:] {
+		in := make(map[*tree.TechNode]bool, len(list))
+		for _, node := range list {
+			in[node] = true
+		}
+		nodes = slices.DeleteFunc(nodes, func(node *tree.TechNode) bool { return !in[node] })
+	}
+	sortByLevel(nodes)
+	return nodes, nil
+}
+
+// matchFold returns the value of values equal to value under case folding,
+// or value itself when there is none
+func matchFold(values []string, value string) string {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return v
+		}
+	}
+	return value
+}
+
+// compileWhere compiles the where query parameter. Returns nil when it is
+// empty.
+func compileWhere(where string) (*filter.Expression, error) {
+	if where == "" {
+		return nil, nil
+	}
+	expr, err := filter.Compile(where, filter.TechnologyFieldNames())
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	return expr, nil
+}
+
+// intParam parses an optional integer query parameter
+func intParam(value string, defaultValue int) (int, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// writeJSON writes data as a JSON response
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(data)
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
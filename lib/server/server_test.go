@@ -0,0 +1,269 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/generator"
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+func createTestServer() *Server {
+	technologies := map[string]*models.Technology{
+		"tech_root": {
+			Key:         "tech_root",
+			Name:        "Root",
+			Area:        "physics",
+			Category:    []string{"computing"},
+			IsStartTech: true,
+		},
+		"tech_lasers": {
+			Key:           "tech_lasers",
+			Area:          "physics",
+			Tier:          1,
+			Cost:          1000,
+			Category:      []string{"particles"},
+			Prerequisites: []string{"tech_root"},
+			Icon:          "tech_lasers",
+		},
+		"tech_rare": {
+			Key:           "tech_rare",
+			Area:          "physics",
+			Tier:          2,
+			Cost:          2000,
+			Category:      []string{"particles"},
+			Prerequisites: []string{"tech_lasers"},
+			IsRare:        true,
+			Icon:          "tech_lasers",
+		},
+		"tech_society": {
+			Key:      "tech_society",
+			Area:     "society",
+			Tier:     1,
+			Cost:     1000,
+			Category: []string{"biology"},
+			Mod:      "Society Mod",
+		},
+	}
+
+	techTree := tree.NewTechTree(technologies)
+	return NewServer(techTree, generator.NewJSONGenerator(techTree))
+}
+
+func get(t *testing.T, s *Server, url string, expectedStatus int) map[string]interface{} {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	s.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+
+	if recorder.Code != expectedStatus {
+		t.Fatalf("GET %s: expected status %d, got %d: %s", url, expectedStatus, recorder.Code, recorder.Body.String())
+	}
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("GET %s: expected JSON content type, got %q", url, contentType)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &data); err != nil {
+		t.Fatalf("GET %s: failed to parse JSON: %v", url, err)
+	}
+	return data
+}
+
+func keys(data map[string]interface{}) []string {
+	var result []string
+	for _, tech := range data["technologies"].([]interface{}) {
+		result = append(result, tech.(map[string]interface{})["key"].(string))
+	}
+	return result
+}
+
+func TestListTechnologies(t *testing.T) {
+	s := createTestServer()
+
+	data := get(t, s, "/api/technologies", http.StatusOK)
+	if data["total"].(float64) != 4 {
+		t.Errorf("Expected 4 technologies, got %v", data["total"])
+	}
+
+	// Sorted by level, then key
+	if got := keys(data); len(got) != 4 || got[0] != "tech_root" || got[3] != "tech_rare" {
+		t.Errorf("Unexpected order: %v", got)
+	}
+
+	// Results read from the indexes are in the same order
+	data = get(t, s, "/api/technologies?search=tech_&category=particles", http.StatusOK)
+	if got := keys(data); len(got) != 2 || got[0] != "tech_lasers" || got[1] != "tech_rare" {
+		t.Errorf("Unexpected order of indexed results: %v", got)
+	}
+}
+
+func TestListTechnologiesFiltering(t *testing.T) {
+	s := createTestServer()
+
+	tests := []struct {
+		url      string
+		expected int
+	}{
+		{"/api/technologies?area=physics", 3},
+		{"/api/technologies?area=PHYSICS&tier=1", 1},
+		{"/api/technologies?category=particles", 2},
+		{"/api/technologies?where=isRare", 1},
+		{"/api/technologies?area=physics&where=cost+%3E%3D+1000", 2},
+		{"/api/technologies?category=PARTICLES&tier=2", 1},
+		{"/api/technologies?area=unknown", 0},
+		{"/api/technologies?mod=society+mod", 1},
+		{"/api/technologies?icon=tech_lasers", 2},
+		{"/api/technologies?icon=tech_lasers&where=isRare", 1},
+		{"/api/technologies?search=tech_r", 2},
+		{"/api/technologies?search=ROOT", 1},
+		{"/api/technologies?search=tech_&area=physics&tier=1", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			data := get(t, s, tt.url, http.StatusOK)
+			if data["total"].(float64) != float64(tt.expected) {
+				t.Errorf("Expected %d technologies, got %v (%v)", tt.expected, data["total"], keys(data))
+			}
+		})
+	}
+}
+
+func TestListTechnologiesPagination(t *testing.T) {
+	s := createTestServer()
+
+	data := get(t, s, "/api/technologies?offset=1&limit=2", http.StatusOK)
+	if got := keys(data); len(got) != 2 || got[0] != "tech_society" || got[1] != "tech_lasers" {
+		t.Errorf("Unexpected page: %v", got)
+	}
+	if data["total"].(float64) != 4 {
+		t.Errorf("Expected total to count all matches, got %v", data["total"])
+	}
+
+	data = get(t, s, "/api/technologies?offset=10", http.StatusOK)
+	if len(keys(data)) != 0 {
+		t.Errorf("Expected empty page past the end, got %v", keys(data))
+	}
+}
+
+func TestListTechnologiesInvalid(t *testing.T) {
+	s := createTestServer()
+
+	for _, url := range []string{
+		"/api/technologies?limit=0",
+		"/api/technologies?limit=abc",
+		"/api/technologies?offset=-1",
+		"/api/technologies?tier=high",
+		"/api/technologies?where=tier+%3E%3D",
+		"/api/technologies?where=unknownField",
+	} {
+		t.Run(url, func(t *testing.T) {
+			data := get(t, s, url, http.StatusBadRequest)
+			if data["error"] == "" {
+				t.Error("Expected error message")
+			}
+		})
+	}
+}
+
+func TestGetTechnology(t *testing.T) {
+	s := createTestServer()
+
+	data := get(t, s, "/api/technologies/tech_lasers", http.StatusOK)
+	if data["key"] != "tech_lasers" {
+		t.Errorf("Expected tech_lasers, got %v", data["key"])
+	}
+	if unlocks := data["unlocks"].([]interface{}); len(unlocks) != 1 || unlocks[0] != "tech_rare" {
+		t.Errorf("Expected tech_lasers to unlock tech_rare, got %v", unlocks)
+	}
+
+	get(t, s, "/api/technologies/tech_missing", http.StatusNotFound)
+}
+
+func TestAreas(t *testing.T) {
+	s := createTestServer()
+
+	data := get(t, s, "/api/areas", http.StatusOK)
+	areas := data["areas"].([]interface{})
+	if len(areas) != 2 {
+		t.Fatalf("Expected 2 areas, got %v", areas)
+	}
+	physics := areas[0].(map[string]interface{})
+	if physics["area"] != "physics" || physics["count"].(float64) != 3 {
+		t.Errorf("Unexpected physics entry: %v", physics)
+	}
+}
+
+func TestTree(t *testing.T) {
+	s := createTestServer()
+
+	data := get(t, s, "/api/tree?area=physics", http.StatusOK)
+	if nodes := data["nodes"].([]interface{}); len(nodes) != 3 {
+		t.Errorf("Expected 3 physics nodes, got %d", len(nodes))
+	}
+	edges := data["edges"].([]interface{})
+	if len(edges) != 2 {
+		t.Fatalf("Expected 2 edges, got %v", edges)
+	}
+	first := edges[0].(map[string]interface{})
+	if first["from"] != "tech_lasers" || first["to"] != "tech_rare" {
+		t.Errorf("Unexpected first edge: %v", first)
+	}
+
+	data = get(t, s, "/api/tree?area=Society", http.StatusOK)
+	if nodes := data["nodes"].([]interface{}); len(nodes) != 1 {
+		t.Errorf("Expected the area to match case-insensitively, got %v", nodes)
+	}
+
+	data = get(t, s, "/api/tree", http.StatusOK)
+	if nodes := data["nodes"].([]interface{}); len(nodes) != 4 {
+		t.Errorf("Expected all 4 nodes, got %d", len(nodes))
+	}
+}
+
+func TestListTechnologiesCancelled(t *testing.T) {
+	s := createTestServer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/api/technologies", nil).WithContext(ctx)
+	s.Handler().ServeHTTP(recorder, request)
+
+	if recorder.Body.Len() != 0 {
+		t.Errorf("Expected no response for a cancelled request, got %s", recorder.Body.String())
+	}
+}
+
+// Run with -race to check that requests only read the shared generator
+func TestConcurrentRequests(t *testing.T) {
+	s := createTestServer()
+	handler := s.Handler()
+
+	// Start the requests together, so the first doesn't finish before the
+	// others begin
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			for _, url := range []string{"/api/technologies", "/api/technologies/tech_lasers", "/api/tree?area=physics"} {
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+				if recorder.Code != http.StatusOK {
+					t.Errorf("Expected status 200 for %s, got %d", url, recorder.Code)
+				}
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+}
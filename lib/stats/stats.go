@@ -0,0 +1,99 @@
+// Package stats summarizes a technology tree: counts by area, tier and
+// category, average costs, the longest prerequisite chain and the
+// technologies unlocking the most others.
+package stats
+
+import (
+	"math"
+	"sort"
+
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+// TopFanOut is the number of technologies listed in Stats.LargestFanOut
+const TopFanOut = 5
+
+// FanOut is a technology with the number of technologies it directly unlocks
+type FanOut struct {
+	Tech       string `json:"tech"`
+	Dependents int    `json:"dependents"`
+}
+
+// Stats summarizes the technology tree
+type Stats struct {
+	Technologies      int             `json:"technologies"`
+	ByArea            map[string]int  `json:"byArea"`
+	ByTier            map[int]int     `json:"byTier"`
+	ByCategory        map[string]int  `json:"byCategory"` // Technologies of several categories count in each
+	AverageCostByTier map[int]float64 `json:"averageCostByTier"`
+	Rare              int             `json:"rare"`
+	Dangerous         int             `json:"dangerous"`
+	LongestChain      []string        `json:"longestChain"`  // Keys from a root technology to the deepest one
+	LargestFanOut     []FanOut        `json:"largestFanOut"` // Technologies with the most dependents, most first
+}
+
+// Compute collects the statistics of a technology tree. Ties in the longest
+// chain and the fan-out list are broken by key, so the result is the same on
+// every run.
+func Compute(t *tree.TechTree) Stats {
+	s := Stats{
+		ByArea:            make(map[string]int),
+		ByTier:            make(map[int]int),
+		ByCategory:        make(map[string]int),
+		AverageCostByTier: make(map[int]float64),
+		LongestChain:      []string{},
+		LargestFanOut:     []FanOut{},
+	}
+
+	costByTier := make(map[int]int)
+	var deepest *tree.TechNode
+	for _, node := range t.GetSortedNodes() {
+		key := node.Tech.Key
+		tech := node.Tech
+		s.Technologies++
+		s.ByArea[tech.Area]++
+		s.ByTier[tech.Tier]++
+		costByTier[tech.Tier] += tech.Cost
+		for _, category := range tech.Category {
+			s.ByCategory[category]++
+		}
+		if tech.IsRare {
+			s.Rare++
+		}
+		if tech.IsDangerous {
+			s.Dangerous++
+		}
+		if deepest == nil || node.Level > deepest.Level {
+			deepest = node
+		}
+		if len(node.Dependents) > 0 {
+			s.LargestFanOut = append(s.LargestFanOut, FanOut{Tech: key, Dependents: len(node.Dependents)})
+		}
+	}
+
+	for tier, total := range costByTier {
+		s.AverageCostByTier[tier] = math.Round(float64(total)/float64(s.ByTier[tier])*10) / 10
+	}
+
+	// Walk back from the deepest technology through prerequisites one level
+	// above it
+	for node := deepest; node != nil; {
+		s.LongestChain = append([]string{node.Tech.Key}, s.LongestChain...)
+		var previous *tree.TechNode
+		for _, dependency := range node.Dependencies {
+			if dependency.Level == node.Level-1 && (previous == nil || dependency.Tech.Key < previous.Tech.Key) {
+				previous = dependency
+			}
+		}
+		node = previous
+	}
+
+	sort.SliceStable(s.LargestFanOut, func(i, j int) bool {
+		return s.LargestFanOut[i].Dependents > s.LargestFanOut[j].Dependents
+	})
+	if len(s.LargestFanOut) > TopFanOut {
+		s.LargestFanOut = s.LargestFanOut[:TopFanOut]
+	}
+
+	return s
+}
@@ -0,0 +1,47 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+func TestCompute(t *testing.T) {
+	techs := map[string]*models.Technology{
+		"tech_root":   {Key: "tech_root", Area: "physics", Tier: 0, Cost: 0, Category: []string{"computing"}},
+		"tech_a":      {Key: "tech_a", Area: "physics", Tier: 1, Cost: 100, Category: []string{"computing", "particles"}, Prerequisites: []string{"tech_root"}},
+		"tech_b":      {Key: "tech_b", Area: "society", Tier: 1, Cost: 300, IsRare: true, Prerequisites: []string{"tech_root"}},
+		"tech_c":      {Key: "tech_c", Area: "physics", Tier: 2, Cost: 1000, IsDangerous: true, Prerequisites: []string{"tech_a", "tech_b"}},
+		"tech_single": {Key: "tech_single", Area: "engineering", Tier: 1, Cost: 200},
+	}
+	s := Compute(tree.NewTechTree(techs))
+
+	if s.Technologies != 5 || s.ByArea["physics"] != 3 || s.ByTier[1] != 3 {
+		t.Errorf("Unexpected counts: %d technologies, %v by area, %v by tier", s.Technologies, s.ByArea, s.ByTier)
+	}
+	if s.ByCategory["computing"] != 2 || s.ByCategory["particles"] != 1 {
+		t.Errorf("Expected technologies to count in each of their categories, got %v", s.ByCategory)
+	}
+	if s.AverageCostByTier[1] != 200 || s.AverageCostByTier[2] != 1000 {
+		t.Errorf("Unexpected average costs: %v", s.AverageCostByTier)
+	}
+	if s.Rare != 1 || s.Dangerous != 1 {
+		t.Errorf("Expected 1 rare and 1 dangerous technology, got %d and %d", s.Rare, s.Dangerous)
+	}
+
+	expectedChain := []string{"tech_root", "tech_a", "tech_c"}
+	if len(s.LongestChain) != len(expectedChain) {
+		t.Fatalf("Expected chain %v, got %v", expectedChain, s.LongestChain)
+	}
+	for i, key := range expectedChain {
+		if s.LongestChain[i] != key {
+			t.Errorf("Expected chain %v, got %v", expectedChain, s.LongestChain)
+			break
+		}
+	}
+
+	if len(s.LargestFanOut) != 3 || s.LargestFanOut[0] != (FanOut{Tech: "tech_root", Dependents: 2}) || s.LargestFanOut[1].Tech != "tech_a" {
+		t.Errorf("Unexpected fan-out: %v", s.LargestFanOut)
+	}
+}
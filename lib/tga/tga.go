@@ -0,0 +1,216 @@
+// Package tga decodes Truevision TGA images, which mods often ship icons as:
+// true-color, grayscale and color-mapped images, uncompressed or run-length
+// encoded, in either row order.
+//
+// Importing the package registers the format with the image package:
+//
+//	import _ "github.com/danaketh/StellarisDataParser/lib/tga"
+package tga
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// TGA files have no magic number, so the format is recognized by the color
+// map and image types in the second and third bytes
+func init() {
+	for _, magic := range []string{"?\x01\x01", "?\x00\x02", "?\x00\x03", "?\x01\x09", "?\x00\x0a", "?\x00\x0b"} {
+		image.RegisterFormat("tga", magic, Decode, DecodeConfig)
+	}
+}
+
+const headerSize = 18
+
+// Image types
+const (
+	typeColorMapped = 1
+	typeTrueColor   = 2
+	typeGrayscale   = 3
+	typeRLE         = 8 // Added to the other types for run-length encoding
+)
+
+// Image descriptor bits
+const (
+	descAlphaBits   = 0x0f
+	descRightToLeft = 0x10
+	descTopToBottom = 0x20
+)
+
+// Header describes a TGA image
+type Header struct {
+	Width, Height int
+	imageType     int
+	pixelDepth    int
+	descriptor    byte
+	idLength      int
+	mapFirst      int // First entry index of the color map
+	mapLength     int
+	mapDepth      int
+}
+
+// ReadHeader reads the header of a TGA file
+func ReadHeader(r io.Reader) (Header, error) {
+	var buf [headerSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return Header{}, fmt.Errorf("failed to read header: %w", err)
+	}
+	h := Header{
+		idLength:   int(buf[0]),
+		imageType:  int(buf[2]),
+		mapFirst:   int(binary.LittleEndian.Uint16(buf[3:])),
+		mapLength:  int(binary.LittleEndian.Uint16(buf[5:])),
+		mapDepth:   int(buf[7]),
+		Width:      int(binary.LittleEndian.Uint16(buf[12:])),
+		Height:     int(binary.LittleEndian.Uint16(buf[14:])),
+		pixelDepth: int(buf[16]),
+		descriptor: buf[17],
+	}
+	if h.Width == 0 || h.Height == 0 {
+		return Header{}, fmt.Errorf("invalid size %dx%d", h.Width, h.Height)
+	}
+
+	valid := false
+	switch h.imageType &^ typeRLE {
+	case typeColorMapped:
+		valid = buf[1] == 1 && (h.pixelDepth == 8 || h.pixelDepth == 16) && validDepth(h.mapDepth)
+	case typeTrueColor:
+		valid = buf[1] == 0 && validDepth(h.pixelDepth)
+	case typeGrayscale:
+		valid = buf[1] == 0 && (h.pixelDepth == 8 || h.pixelDepth == 16)
+	}
+	if !valid {
+		return Header{}, fmt.Errorf("unsupported image type %d with color map type %d and %d bits per pixel", h.imageType, buf[1], h.pixelDepth)
+	}
+	return h, nil
+}
+
+// validDepth reports whether colors of a depth can be decoded
+func validDepth(depth int) bool {
+	return depth == 15 || depth == 16 || depth == 24 || depth == 32
+}
+
+// DecodeConfig returns the size and color model of a TGA image
+func DecodeConfig(r io.Reader) (image.Config, error) {
+	h, err := ReadHeader(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{ColorModel: color.NRGBAModel, Width: h.Width, Height: h.Height}, nil
+}
+
+// Decode reads a TGA image
+func Decode(r io.Reader) (image.Image, error) {
+	br := bufio.NewReader(r)
+	h, err := ReadHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := br.Discard(h.idLength); err != nil {
+		return nil, fmt.Errorf("failed to read image ID: %w", err)
+	}
+	alpha := h.descriptor&descAlphaBits != 0
+
+	// Pixels are read as bytes and turned into colors by pixel
+	var pixel func([]byte) color.NRGBA
+	switch h.imageType &^ typeRLE {
+	case typeColorMapped:
+		entry := make([]byte, (h.mapDepth+7)/8)
+		palette := make([]color.NRGBA, h.mapLength)
+		for i := range palette {
+			if _, err := io.ReadFull(br, entry); err != nil {
+				return nil, fmt.Errorf("failed to read color map: %w", err)
+			}
+			palette[i] = trueColor(entry, alpha)
+		}
+		pixel = func(b []byte) color.NRGBA {
+			index := int(b[0])
+			if len(b) == 2 {
+				index |= int(b[1]) << 8
+			}
+			if index -= h.mapFirst; index < 0 || index >= len(palette) {
+				return color.NRGBA{}
+			}
+			return palette[index]
+		}
+	case typeTrueColor:
+		pixel = func(b []byte) color.NRGBA {
+			return trueColor(b, alpha)
+		}
+	case typeGrayscale:
+		pixel = func(b []byte) color.NRGBA {
+			c := color.NRGBA{R: b[0], G: b[0], B: b[0], A: 255}
+			if len(b) == 2 {
+				c.A = b[1]
+			}
+			return c
+		}
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, h.Width, h.Height))
+	buf := make([]byte, (h.pixelDepth+7)/8)
+	run, raw := 0, false // Pixels left in the current run-length packet
+	for i := 0; i < h.Width*h.Height; i++ {
+		if h.imageType&typeRLE != 0 && run == 0 {
+			packet, err := br.ReadByte()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read pixels: %w", err)
+			}
+			run, raw = int(packet&0x7f)+1, packet&0x80 == 0
+			if !raw {
+				if _, err := io.ReadFull(br, buf); err != nil {
+					return nil, fmt.Errorf("failed to read pixels: %w", err)
+				}
+			}
+		}
+		if h.imageType&typeRLE == 0 || raw {
+			if _, err := io.ReadFull(br, buf); err != nil {
+				return nil, fmt.Errorf("failed to read pixels: %w", err)
+			}
+		}
+		run--
+
+		x, y := i%h.Width, i/h.Width
+		if h.descriptor&descRightToLeft != 0 {
+			x = h.Width - 1 - x
+		}
+		if h.descriptor&descTopToBottom == 0 {
+			y = h.Height - 1 - y
+		}
+		img.SetNRGBA(x, y, pixel(buf))
+	}
+	return img, nil
+}
+
+// trueColor returns the color of a little-endian BGR(A) value of 2, 3 or 4
+// bytes. 16-bit values have 5 bits per channel and one alpha bit. Alpha is
+// only used if the image has alpha bits, otherwise colors are opaque.
+func trueColor(b []byte, alpha bool) color.NRGBA {
+	switch len(b) {
+	case 2:
+		v := uint16(b[0]) | uint16(b[1])<<8
+		c := color.NRGBA{R: expand5(v >> 10), G: expand5(v >> 5), B: expand5(v), A: 255}
+		if alpha && v&0x8000 == 0 {
+			c.A = 0
+		}
+		return c
+	case 3:
+		return color.NRGBA{R: b[2], G: b[1], B: b[0], A: 255}
+	default:
+		c := color.NRGBA{R: b[2], G: b[1], B: b[0], A: 255}
+		if alpha {
+			c.A = b[3]
+		}
+		return c
+	}
+}
+
+// expand5 scales the low 5 bits of a value to 8 bits
+func expand5(v uint16) uint8 {
+	v &= 0x1f
+	return uint8(v<<3 | v>>2)
+}
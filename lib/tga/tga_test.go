@@ -0,0 +1,117 @@
+package tga
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// tgaFile builds a TGA file from its header fields and data
+func tgaFile(mapType, imageType byte, mapLength, mapDepth, width, height, depth int, descriptor byte, data ...byte) []byte {
+	header := []byte{
+		3, mapType, imageType,
+		0, 0, byte(mapLength), byte(mapLength >> 8), byte(mapDepth),
+		0, 0, 0, 0,
+		byte(width), byte(width >> 8), byte(height), byte(height >> 8),
+		byte(depth), descriptor,
+	}
+	// An image ID of three bytes precedes the color map
+	return append(append(header, 'i', 'd', '!'), data...)
+}
+
+func decode(t *testing.T, file []byte) image.Image {
+	t.Helper()
+	img, format, err := image.Decode(bytes.NewReader(file))
+	if err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	if format != "tga" {
+		t.Errorf("Expected the tga format to be registered, got %q", format)
+	}
+	return img
+}
+
+func expectPixels(t *testing.T, img image.Image, expected [][]color.NRGBA) {
+	t.Helper()
+	for y, row := range expected {
+		for x, want := range row {
+			if got := color.NRGBAModel.Convert(img.At(x, y)); got != want {
+				t.Errorf("Expected %v at %d,%d, got %v", want, x, y, got)
+			}
+		}
+	}
+}
+
+var (
+	red   = color.NRGBA{255, 0, 0, 255}
+	green = color.NRGBA{0, 255, 0, 255}
+	blue  = color.NRGBA{0, 0, 255, 255}
+	white = color.NRGBA{255, 255, 255, 255}
+)
+
+func TestDecodeTrueColor(t *testing.T) {
+	// Rows from the bottom
+	file := tgaFile(0, 2, 0, 0, 2, 2, 24, 0,
+		0, 0, 255, 0, 255, 0,
+		255, 0, 0, 255, 255, 255,
+	)
+	expectPixels(t, decode(t, file), [][]color.NRGBA{{blue, white}, {red, green}})
+
+	// Rows from the top and right to left, with alpha
+	file = tgaFile(0, 2, 0, 0, 2, 1, 32, 8|descTopToBottom|descRightToLeft,
+		0, 0, 255, 128, 255, 0, 0, 255,
+	)
+	expectPixels(t, decode(t, file), [][]color.NRGBA{{blue, {255, 0, 0, 128}}})
+
+	// Without alpha bits, the fourth byte is ignored
+	file = tgaFile(0, 2, 0, 0, 1, 1, 32, 0, 0, 0, 255, 0)
+	expectPixels(t, decode(t, file), [][]color.NRGBA{{red}})
+
+	// 16 bits with one alpha bit
+	file = tgaFile(0, 2, 0, 0, 2, 1, 16, 1|descTopToBottom, 0x00, 0xfc, 0x1f, 0x00)
+	expectPixels(t, decode(t, file), [][]color.NRGBA{{red, {0, 0, 255, 0}}})
+}
+
+func TestDecodeRLE(t *testing.T) {
+	// A run of three and a raw packet of one, crossing rows
+	file := tgaFile(0, 10, 0, 0, 2, 2, 24, descTopToBottom,
+		0x82, 0, 0, 255,
+		0x00, 255, 0, 0,
+	)
+	expectPixels(t, decode(t, file), [][]color.NRGBA{{red, red}, {red, blue}})
+}
+
+func TestDecodeColorMapped(t *testing.T) {
+	palette := []byte{0, 0, 255, 0, 255, 0, 255, 0, 0}
+	file := tgaFile(1, 9, 3, 24, 3, 1, 8, descTopToBottom,
+		append(palette, 0x01, 2, 1, 0x00, 0)...,
+	)
+	expectPixels(t, decode(t, file), [][]color.NRGBA{{blue, green, red}})
+}
+
+func TestDecodeGrayscale(t *testing.T) {
+	file := tgaFile(0, 3, 0, 0, 2, 1, 16, 8|descTopToBottom, 200, 255, 100, 50)
+	expectPixels(t, decode(t, file), [][]color.NRGBA{{{200, 200, 200, 255}, {100, 100, 100, 50}}})
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := map[string][]byte{
+		"unsupported depth": tgaFile(0, 2, 0, 0, 1, 1, 8, 0, 0),
+		"empty":             tgaFile(0, 2, 0, 0, 0, 1, 24, 0),
+		"missing color map": tgaFile(0, 1, 0, 0, 1, 1, 8, 0, 0),
+		"truncated":         tgaFile(0, 2, 0, 0, 2, 2, 24, 0, 1, 2, 3),
+		"truncated RLE":     tgaFile(0, 10, 0, 0, 2, 2, 24, 0, 0x81, 1, 2, 3),
+		"short header":      {0, 0, 2},
+	}
+	for name, file := range tests {
+		if _, err := Decode(bytes.NewReader(file)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	config, err := DecodeConfig(bytes.NewReader(tgaFile(0, 2, 0, 0, 300, 2, 24, 0)))
+	if err != nil || config.Width != 300 || config.Height != 2 {
+		t.Errorf("Expected the config of a 300x2 image, got %+v, %v", config, err)
+	}
+}
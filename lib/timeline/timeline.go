@@ -0,0 +1,176 @@
+// Package timeline estimates the in-game year each technology becomes
+// reachable from assumptions about research speed and tier pacing.
+package timeline
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+// Assumptions describe the research speed and tier pacing used to estimate
+// when technologies become reachable
+type Assumptions struct {
+	StartYear      int     `json:"startYear"`      // In-game year the game starts
+	BaseResearch   float64 `json:"baseResearch"`   // Research points per month in each area at game start
+	ResearchGrowth float64 `json:"researchGrowth"` // Increase of monthly research points per year
+	CostMultiplier float64 `json:"costMultiplier"` // Multiplier of technology costs, e.g. the galaxy's tech cost setting; 0 is read as 1
+	// TierYears is the number of years after the start before technologies
+	// of a tier are typically offered, approximating the requirement to
+	// research technologies of the previous tier first
+	TierYears map[int]int `json:"tierYears"`
+}
+
+// DefaultAssumptions returns assumptions modelled on a typical single player
+// game at normal difficulty and technology cost
+func DefaultAssumptions() Assumptions {
+	return Assumptions{
+		StartYear:      2200,
+		BaseResearch:   20,
+		ResearchGrowth: 12,
+		CostMultiplier: 1,
+		TierYears: map[int]int{
+			1: 0,
+			2: 10,
+			3: 25,
+			4: 45,
+			5: 70,
+		},
+	}
+}
+
+// Validate checks the assumptions for values that would make estimates meaningless
+func (a Assumptions) Validate() error {
+	if a.BaseResearch <= 0 {
+		return fmt.Errorf("baseResearch must be positive, got %g", a.BaseResearch)
+	}
+	if a.ResearchGrowth < 0 {
+		return fmt.Errorf("researchGrowth must not be negative, got %g", a.ResearchGrowth)
+	}
+	if a.CostMultiplier < 0 {
+		return fmt.Errorf("costMultiplier must not be negative, got %g", a.CostMultiplier)
+	}
+	for tier, years := range a.TierYears {
+		if years < 0 {
+			return fmt.Errorf("tierYears for tier %d must not be negative, got %d", tier, years)
+		}
+	}
+	return nil
+}
+
+// Estimate returns the earliest in-game year each technology is typically
+// reachable. A technology is started once all of its prerequisites are done
+// and its tier is available, and takes as long as its cost requires at the
+// research speed of that moment. Areas are researched in parallel and
+// technologies in the same area are not assumed to compete for research, so
+// the result is a lower bound. Repeatable technologies are estimated for
+// their first level.
+func Estimate(techTree *tree.TechTree, assumptions Assumptions) map[string]int {
+	e := &estimator{
+		assumptions: assumptions,
+		finished:    make(map[string]float64),
+		inProgress:  make(map[string]bool),
+	}
+
+	years := make(map[string]int)
+	// Nodes are estimated in key order, so prerequisite cycles are broken at
+	// the same technology on every run
+	for _, node := range techTree.GetSortedNodes() {
+		years[node.Tech.Key] = assumptions.StartYear + int(e.finishMonth(node)/12)
+	}
+	return years
+}
+
+// estimator memoizes the month in which each technology is finished
+type estimator struct {
+	assumptions Assumptions
+	finished    map[string]float64
+	inProgress  map[string]bool // Guards against prerequisite cycles
+}
+
+// finishMonth returns the number of months after the start in which the
+// technology of node is finished
+func (e *estimator) finishMonth(node *tree.TechNode) float64 {
+	key := node.Tech.Key
+	if month, ok := e.finished[key]; ok {
+		return month
+	}
+	if node.Tech.IsStartTech || e.inProgress[key] {
+		return 0
+	}
+
+	e.inProgress[key] = true
+	start := float64(e.assumptions.TierYears[node.Tech.Tier] * 12)
+	if prerequisites := e.prerequisitesDone(node); prerequisites > start {
+		start = prerequisites
+	}
+	delete(e.inProgress, key)
+
+	cost := float64(node.Tech.Cost)
+	if e.assumptions.CostMultiplier > 0 {
+		cost *= e.assumptions.CostMultiplier
+	}
+	month := e.researchUntil(start, cost)
+	e.finished[key] = month
+	return month
+}
+
+// prerequisitesDone returns the month in which the prerequisites of node are
+// done. With alternative prerequisite groups the earliest finished group
+// counts; otherwise every prerequisite is required.
+func (e *estimator) prerequisitesDone(node *tree.TechNode) float64 {
+	if len(node.Tech.PrerequisiteGroups) < 2 {
+		return e.latestFinish(node.Dependencies)
+	}
+
+	byKey := make(map[string]*tree.TechNode, len(node.Dependencies))
+	for _, dep := range node.Dependencies {
+		byKey[dep.Tech.Key] = dep
+	}
+
+	earliest := -1.0
+	for _, group := range node.Tech.PrerequisiteGroups {
+		deps := make([]*tree.TechNode, 0, len(group))
+		for _, prereq := range group {
+			if dep, exists := byKey[prereq]; exists {
+				deps = append(deps, dep)
+			}
+		}
+		if month := e.latestFinish(deps); earliest < 0 || month < earliest {
+			earliest = month
+		}
+	}
+	return earliest
+}
+
+// latestFinish returns the month in which the last of nodes is finished
+func (e *estimator) latestFinish(nodes []*tree.TechNode) float64 {
+	latest := 0.0
+	for _, node := range nodes {
+		if month := e.finishMonth(node); month > latest {
+			latest = month
+		}
+	}
+	return latest
+}
+
+// researchUntil returns the month at which cost research points have been
+// accumulated when starting at month start. Monthly research grows linearly,
+// so the accumulated research is the integral of
+// BaseResearch + ResearchGrowth*m/12, which is solved for the end month.
+func (e *estimator) researchUntil(start, cost float64) float64 {
+	if cost <= 0 {
+		return start
+	}
+
+	base := e.assumptions.BaseResearch
+	a := e.assumptions.ResearchGrowth / 24
+	if a == 0 {
+		return start + cost/base
+	}
+
+	// a*end² + base*end = a*start² + base*start + cost
+	k := a*start*start + base*start + cost
+	return (-base + math.Sqrt(base*base+4*a*k)) / (2 * a)
+}
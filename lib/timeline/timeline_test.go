@@ -0,0 +1,149 @@
+package timeline
+
+import (
+	"math"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+func TestEstimate(t *testing.T) {
+	technologies := map[string]*models.Technology{
+		"tech_start": {
+			Key:         "tech_start",
+			Cost:        0,
+			Tier:        0,
+			IsStartTech: true,
+		},
+		"tech_cheap": {
+			Key:           "tech_cheap",
+			Cost:          240, // 1 year at 20 per month
+			Tier:          1,
+			Prerequisites: []string{"tech_start"},
+		},
+		"tech_chain": {
+			Key:           "tech_chain",
+			Cost:          480,
+			Tier:          1,
+			Prerequisites: []string{"tech_cheap"},
+		},
+		"tech_late_tier": {
+			Key:           "tech_late_tier",
+			Cost:          0,
+			Tier:          3,
+			Prerequisites: []string{"tech_cheap"},
+		},
+	}
+
+	assumptions := Assumptions{
+		StartYear:      2200,
+		BaseResearch:   20,
+		ResearchGrowth: 0,
+		TierYears:      map[int]int{3: 25},
+	}
+
+	years := Estimate(tree.NewTechTree(technologies), assumptions)
+
+	tests := []struct {
+		key      string
+		expected int
+	}{
+		{"tech_start", 2200},
+		{"tech_cheap", 2201},
+		{"tech_chain", 2203}, // 1 year for tech_cheap + 2 years
+		{"tech_late_tier", 2225},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if years[tt.key] != tt.expected {
+				t.Errorf("Expected %s to be reachable in %d, got %d", tt.key, tt.expected, years[tt.key])
+			}
+		})
+	}
+}
+
+func TestEstimateAlternativePrerequisites(t *testing.T) {
+	technologies := map[string]*models.Technology{
+		"tech_fast":  {Key: "tech_fast", Cost: 240},
+		"tech_slow":  {Key: "tech_slow", Cost: 960},
+		"tech_other": {Key: "tech_other", Cost: 480},
+		"tech_either": {
+			Key:                "tech_either",
+			Cost:               240,
+			Prerequisites:      []string{"tech_slow", "tech_fast", "tech_other"},
+			PrerequisiteGroups: [][]string{{"tech_slow"}, {"tech_fast", "tech_other"}},
+		},
+	}
+
+	assumptions := Assumptions{StartYear: 2200, BaseResearch: 20, TierYears: map[int]int{}}
+	years := Estimate(tree.NewTechTree(technologies), assumptions)
+
+	// The second group is done after 2 years (tech_other), before tech_slow's 4
+	if years["tech_either"] != 2203 {
+		t.Errorf("Expected tech_either to be reachable in 2203, got %d", years["tech_either"])
+	}
+}
+
+func TestEstimateCostMultiplier(t *testing.T) {
+	technologies := map[string]*models.Technology{
+		"tech_a": {Key: "tech_a", Cost: 240},
+	}
+
+	assumptions := Assumptions{StartYear: 2200, BaseResearch: 20, CostMultiplier: 2, TierYears: map[int]int{}}
+	if years := Estimate(tree.NewTechTree(technologies), assumptions); years["tech_a"] != 2202 {
+		t.Errorf("Expected doubled costs to take 2 years, got %d", years["tech_a"])
+	}
+
+	// Without a multiplier costs are used as they are
+	assumptions.CostMultiplier = 0
+	if years := Estimate(tree.NewTechTree(technologies), assumptions); years["tech_a"] != 2201 {
+		t.Errorf("Expected 1 year without a multiplier, got %d", years["tech_a"])
+	}
+}
+
+func TestEstimateCycle(t *testing.T) {
+	technologies := map[string]*models.Technology{
+		"tech_a": {Key: "tech_a", Cost: 240, Prerequisites: []string{"tech_b"}},
+		"tech_b": {Key: "tech_b", Cost: 240, Prerequisites: []string{"tech_a"}},
+	}
+
+	// Must terminate and still produce an estimate for every technology
+	years := Estimate(tree.NewTechTree(technologies), DefaultAssumptions())
+	if len(years) != 2 {
+		t.Errorf("Expected 2 estimates, got %d", len(years))
+	}
+}
+
+func TestResearchUntilWithGrowth(t *testing.T) {
+	e := &estimator{assumptions: Assumptions{BaseResearch: 20, ResearchGrowth: 12}}
+
+	// Monthly research is 20 + m, so 12 months accumulate 20*12 + 12²/2 = 312
+	if got := e.researchUntil(0, 312); math.Abs(got-12) > 1e-9 {
+		t.Errorf("Expected 12 months, got %g", got)
+	}
+
+	// Starting later is faster because research has grown
+	if later := e.researchUntil(120, 312) - 120; later >= 12 {
+		t.Errorf("Expected research to be faster later in the game, took %g months", later)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := DefaultAssumptions().Validate(); err != nil {
+		t.Errorf("Expected default assumptions to be valid, got %v", err)
+	}
+
+	invalid := DefaultAssumptions()
+	invalid.BaseResearch = 0
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected error for zero base research")
+	}
+
+	invalid = DefaultAssumptions()
+	invalid.CostMultiplier = -1
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected error for a negative cost multiplier")
+	}
+}
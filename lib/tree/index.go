@@ -0,0 +1,122 @@
+package tree
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// nameEntry is a single searchable term in the name index
+type nameEntry struct {
+	term string
+	node *TechNode
+}
+
+// treeIndex holds secondary lookup structures built once when the tree is
+// constructed, so repeated queries don't have to scan every node
+type treeIndex struct {
+	byName []nameEntry // sorted by term for binary prefix search
+	byIcon map[string][]*TechNode
+	byMod  map[string][]*TechNode // The base game's technologies under ""
+}
+
+// buildIndexes populates the secondary indexes from the tree nodes
+func (t *TechTree) buildIndexes() {
+	t.index = &treeIndex{
+		byName: make([]nameEntry, 0, len(t.nodes)*2),
+		byIcon: make(map[string][]*TechNode),
+		byMod:  make(map[string][]*TechNode),
+	}
+
+	for _, node := range t.sorted {
+		// Index both the technology key and its display name
+		t.index.byName = append(t.index.byName, nameEntry{term: strings.ToLower(node.Tech.Key), node: node})
+		if node.Tech.Name != "" {
+			t.index.byName = append(t.index.byName, nameEntry{term: strings.ToLower(node.Tech.Name), node: node})
+		}
+
+		if node.Tech.Icon != "" {
+			t.index.byIcon[node.Tech.Icon] = append(t.index.byIcon[node.Tech.Icon], node)
+		}
+
+		t.index.byMod[node.Tech.Mod] = append(t.index.byMod[node.Tech.Mod], node)
+	}
+
+	sort.Slice(t.index.byName, func(i, j int) bool {
+		if t.index.byName[i].term == t.index.byName[j].term {
+			return t.index.byName[i].node.Tech.Key < t.index.byName[j].node.Tech.Key
+		}
+		return t.index.byName[i].term < t.index.byName[j].term
+	})
+
+	for _, nodes := range t.index.byIcon {
+		sortNodesByKey(nodes)
+	}
+	for _, nodes := range t.index.byMod {
+		sortNodesByKey(nodes)
+	}
+	for _, nodes := range t.byCategory {
+		sortNodesByKey(nodes)
+	}
+}
+
+// sortNodesByKey sorts nodes in place by technology key
+func sortNodesByKey(nodes []*TechNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Tech.Key < nodes[j].Tech.Key
+	})
+}
+
+// FindByNamePrefix returns all nodes whose key or display name starts with
+// the given prefix (case-insensitive), sorted by technology key
+func (t *TechTree) FindByNamePrefix(prefix string) []*TechNode {
+	prefix = strings.ToLower(prefix)
+	entries := t.index.byName
+
+	start := sort.Search(len(entries), func(i int) bool {
+		return entries[i].term >= prefix
+	})
+
+	seen := make(map[string]bool)
+	result := []*TechNode{}
+	for i := start; i < len(entries) && strings.HasPrefix(entries[i].term, prefix); i++ {
+		key := entries[i].node.Tech.Key
+		if !seen[key] {
+			seen[key] = true
+			result = append(result, entries[i].node)
+		}
+	}
+
+	sortNodesByKey(result)
+	return result
+}
+
+// GetNodesByCategory returns nodes filtered by research category, sorted by
+// key
+func (t *TechTree) GetNodesByCategory(category string) []*TechNode {
+	return slices.Clone(t.byCategory[category])
+}
+
+// GetNodesByIcon returns all nodes that use the given icon, sorted by key
+func (t *TechTree) GetNodesByIcon(icon string) []*TechNode {
+	return slices.Clone(t.index.byIcon[icon])
+}
+
+// GetNodesByMod returns all nodes whose last definition comes from the given
+// mod, sorted by key. The base game's technologies have no mod, so "" returns
+// them.
+func (t *TechTree) GetNodesByMod(mod string) []*TechNode {
+	return slices.Clone(t.index.byMod[mod])
+}
+
+// GetMods returns the mods defining technologies of the tree, sorted
+func (t *TechTree) GetMods() []string {
+	mods := make([]string, 0, len(t.index.byMod))
+	for mod := range t.index.byMod {
+		if mod != "" {
+			mods = append(mods, mod)
+		}
+	}
+	sort.Strings(mods)
+	return mods
+}
@@ -0,0 +1,114 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+func createIndexedTechnologies() map[string]*models.Technology {
+	return map[string]*models.Technology{
+		"tech_lasers_1": {
+			Key:        "tech_lasers_1",
+			Name:       "Red Lasers",
+			Area:       "physics",
+			Category:   []string{"particles"},
+			Icon:       "tech_lasers_1",
+			SourceFile: "00_phys_weapon_tech.txt",
+		},
+		"tech_lasers_2": {
+			Key:           "tech_lasers_2",
+			Name:          "Blue Lasers",
+			Area:          "physics",
+			Category:      []string{"particles"},
+			Prerequisites: []string{"tech_lasers_1"},
+			Icon:          "tech_lasers_2",
+			SourceFile:    "00_phys_weapon_tech.txt",
+		},
+		"tech_mass_driver_1": {
+			Key:        "tech_mass_driver_1",
+			Name:       "Mass Drivers",
+			Area:       "engineering",
+			Category:   []string{"materials"},
+			Icon:       "tech_lasers_1",
+			SourceFile: "00_eng_weapon_tech.txt",
+			Mod:        "Better Weapons",
+		},
+	}
+}
+
+func TestFindByNamePrefix(t *testing.T) {
+	tree := NewTechTree(createIndexedTechnologies())
+
+	tests := []struct {
+		prefix   string
+		expected []string
+	}{
+		{"tech_lasers", []string{"tech_lasers_1", "tech_lasers_2"}},
+		{"red", []string{"tech_lasers_1"}},
+		{"MASS", []string{"tech_mass_driver_1"}},
+		{"tech_", []string{"tech_lasers_1", "tech_lasers_2", "tech_mass_driver_1"}},
+		{"nothing", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			nodes := tree.FindByNamePrefix(tt.prefix)
+			if len(nodes) != len(tt.expected) {
+				t.Fatalf("Expected %d results, got %d", len(tt.expected), len(nodes))
+			}
+			for i, node := range nodes {
+				if node.Tech.Key != tt.expected[i] {
+					t.Errorf("Expected result %d to be '%s', got '%s'", i, tt.expected[i], node.Tech.Key)
+				}
+			}
+		})
+	}
+}
+
+func TestGetNodesByIcon(t *testing.T) {
+	tree := NewTechTree(createIndexedTechnologies())
+
+	nodes := tree.GetNodesByIcon("tech_lasers_1")
+	if len(nodes) != 2 {
+		t.Fatalf("Expected 2 nodes sharing icon, got %d", len(nodes))
+	}
+	if nodes[0].Tech.Key != "tech_lasers_1" || nodes[1].Tech.Key != "tech_mass_driver_1" {
+		t.Errorf("Unexpected nodes for icon: %s, %s", nodes[0].Tech.Key, nodes[1].Tech.Key)
+	}
+
+	if len(tree.GetNodesByIcon("missing")) != 0 {
+		t.Error("Expected no nodes for unknown icon")
+	}
+}
+
+func TestGetNodesByMod(t *testing.T) {
+	tree := NewTechTree(createIndexedTechnologies())
+
+	nodes := tree.GetNodesByMod("Better Weapons")
+	if len(nodes) != 1 || nodes[0].Tech.Key != "tech_mass_driver_1" {
+		t.Errorf("Expected the mod's technology, got %v", nodes)
+	}
+	if base := tree.GetNodesByMod(""); len(base) != 2 || base[0].Tech.Key != "tech_lasers_1" {
+		t.Errorf("Expected the 2 base game technologies sorted by key, got %v", base)
+	}
+	if len(tree.GetNodesByMod("missing")) != 0 {
+		t.Error("Expected no nodes for an unknown mod")
+	}
+
+	if mods := tree.GetMods(); len(mods) != 1 || mods[0] != "Better Weapons" {
+		t.Errorf("Unexpected mods: %v", mods)
+	}
+}
+
+func TestGetNodesByCategory(t *testing.T) {
+	tree := NewTechTree(createIndexedTechnologies())
+
+	nodes := tree.GetNodesByCategory("particles")
+	if len(nodes) != 2 {
+		t.Fatalf("Expected 2 particles nodes, got %d", len(nodes))
+	}
+	if nodes[0].Tech.Key != "tech_lasers_1" {
+		t.Errorf("Expected category nodes sorted by key, got '%s' first", nodes[0].Tech.Key)
+	}
+}
@@ -0,0 +1,275 @@
+package tree
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// Kinds of structural issues found by Issues and TierIssues
+const (
+	IssueUnreachable      = "unreachable"       // Prerequisites or potential can never be satisfied
+	IssueOrphan           = "orphan"            // Not connected to the tree and unlocks nothing
+	IssuePrerequisiteTier = "prerequisite-tier" // Prerequisite of a higher tier than the technology
+	IssueTierRequirement  = "tier-requirement"  // Tier that can't be offered under the tier rules
+)
+
+// Issue is a structural problem of a technology in the tree
+type Issue struct {
+	Tech   string `json:"tech"`
+	Kind   string `json:"kind"`
+	Reason string `json:"reason"`
+}
+
+// Issues finds technologies that can never be researched and orphans, sorted
+// by technology key.
+//
+// A technology is unreachable when every prerequisite group contains an
+// unknown or unreachable technology, when it is part of a prerequisite cycle,
+// or when its potential contradicts itself. Start technologies are always
+// reachable. An orphan is a technology drawn as a research option that has no
+// prerequisites, no dependents and no feature unlocks.
+func (t *TechTree) Issues() []Issue {
+	issues := []Issue{}
+	for key, reason := range t.unreachable() {
+		issues = append(issues, Issue{Tech: key, Kind: IssueUnreachable, Reason: reason})
+	}
+
+	for key, node := range t.nodes {
+		tech := node.Tech
+		if len(tech.Prerequisites) == 0 && len(node.Dependents) == 0 && len(tech.FeatureUnlocks) == 0 &&
+			tech.Acquisition() == models.AcquisitionResearch {
+			issues = append(issues, Issue{Tech: key, Kind: IssueOrphan, Reason: "no prerequisites, no dependents and no feature unlocks"})
+		}
+	}
+
+	sortIssues(issues)
+	return issues
+}
+
+// TierIssues checks every technology against the tier rules, given as the
+// number of technologies of the previous tier that must be researched before
+// each tier is offered. A technology violates them when its tier has no rule
+// or when fewer technologies of the previous tier can be researched than the
+// rule requires; start technologies count as researched. Without rules only
+// prerequisites are checked: they must be of the same or a lower tier.
+// Issues are sorted by technology key.
+func (t *TechTree) TierIssues(previouslyUnlocked map[int]int) []Issue {
+	researchable := make(map[int]int)
+	for _, node := range t.nodes {
+		if acquisition := node.Tech.Acquisition(); acquisition == models.AcquisitionResearch || acquisition == models.AcquisitionStart {
+			researchable[node.Tech.Tier]++
+		}
+	}
+
+	issues := []Issue{}
+	for key, node := range t.nodes {
+		tech := node.Tech
+		for _, dependency := range node.Dependencies {
+			if dependency.Tech.Tier > tech.Tier {
+				issues = append(issues, Issue{
+					Tech:   key,
+					Kind:   IssuePrerequisiteTier,
+					Reason: fmt.Sprintf("tier %d but prerequisite %s is tier %d", tech.Tier, dependency.Tech.Key, dependency.Tech.Tier),
+				})
+			}
+		}
+
+		if len(previouslyUnlocked) == 0 || tech.IsStartTech {
+			continue
+		}
+		if required, exists := previouslyUnlocked[tech.Tier]; !exists {
+			issues = append(issues, Issue{
+				Tech:   key,
+				Kind:   IssueTierRequirement,
+				Reason: fmt.Sprintf("tier %d has no tier rule", tech.Tier),
+			})
+		} else if available := researchable[tech.Tier-1]; required > available {
+			issues = append(issues, Issue{
+				Tech:   key,
+				Kind:   IssueTierRequirement,
+				Reason: fmt.Sprintf("tier %d requires %d technologies of tier %d but only %d can be researched", tech.Tier, required, tech.Tier-1, available),
+			})
+		}
+	}
+
+	sortIssues(issues)
+	return issues
+}
+
+// sortIssues sorts issues by technology, kind and reason
+func sortIssues(issues []Issue) {
+	sort.Slice(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.Tech != b.Tech {
+			return a.Tech < b.Tech
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Reason < b.Reason
+	})
+}
+
+// unreachable returns the reason each unreachable technology can't be
+// researched, by key
+func (t *TechTree) unreachable() map[string]string {
+	reasons := make(map[string]string)
+	reachable := make(map[string]bool)
+
+	// Each pass settles the technologies whose prerequisites are settled;
+	// whatever is left when nothing changes depends on a cycle
+	order := t.TopologicalOrder()
+	for changed := true; changed; {
+		changed = false
+		for _, node := range order {
+			key := node.Tech.Key
+			if reachable[key] || reasons[key] != "" {
+				continue
+			}
+			if node.Tech.IsStartTech {
+				reachable[key] = true
+				changed = true
+				continue
+			}
+			if reason, impossible := impossiblePotential(node.Tech); impossible {
+				reasons[key] = reason
+				changed = true
+				continue
+			}
+
+			settled, reason := true, ""
+			for _, group := range prerequisiteKeyGroups(node.Tech) {
+				groupReachable := true
+				for _, prerequisite := range group {
+					if _, exists := t.nodes[prerequisite]; !exists {
+						groupReachable, reason = false, fmt.Sprintf("unknown prerequisite %s", prerequisite)
+						break
+					}
+					if reasons[prerequisite] != "" {
+						groupReachable, reason = false, fmt.Sprintf("prerequisite %s is unreachable", prerequisite)
+						break
+					}
+					if !reachable[prerequisite] {
+						groupReachable, settled = false, false
+					}
+				}
+				if groupReachable {
+					reachable[key] = true
+					changed = true
+					break
+				}
+			}
+			if !reachable[key] && settled {
+				reasons[key] = reason
+				changed = true
+			}
+		}
+	}
+
+	for _, node := range order {
+		if !reachable[node.Tech.Key] && reasons[node.Tech.Key] == "" {
+			reasons[node.Tech.Key] = "part of or depends on a prerequisite cycle"
+		}
+	}
+	return reasons
+}
+
+// prerequisiteKeyGroups returns the prerequisite keys of each alternative
+// group, including keys missing from the tree
+func prerequisiteKeyGroups(tech *models.Technology) [][]string {
+	if len(tech.PrerequisiteGroups) < 2 {
+		return [][]string{tech.Prerequisites}
+	}
+	return tech.PrerequisiteGroups
+}
+
+// impossiblePotential reports whether a technology's potential can never be
+// true: it contains always = no, requires the technology itself, or both
+// requires and forbids the same condition
+func impossiblePotential(tech *models.Technology) (string, bool) {
+	if tech.Potential == nil {
+		return "", false
+	}
+
+	required := make(map[string][]interface{})
+	forbidden := make(map[string][]interface{})
+	collectConditions(tech.Potential.Raw, required, forbidden)
+
+	for _, value := range required["always"] {
+		if value == false {
+			return "potential contains always = no", true
+		}
+	}
+	for _, value := range forbidden["always"] {
+		if value == true {
+			return "potential forbids always = yes", true
+		}
+	}
+	for _, value := range required["has_technology"] {
+		if value == tech.Key {
+			return "potential requires the technology itself", true
+		}
+	}
+
+	keys := make([]string, 0, len(required))
+	for key := range required {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range required[key] {
+			for _, other := range forbidden[key] {
+				if reflect.DeepEqual(value, other) {
+					if comparison, ok := value.(models.Comparison); ok {
+						return fmt.Sprintf("potential both requires and forbids %s %s %v", key, comparison.Operator, comparison.Value), true
+					}
+					return fmt.Sprintf("potential both requires and forbids %s = %v", key, value), true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// collectConditions gathers the conditions that must hold and the ones that
+// must not hold from a condition block. AND blocks are required like the
+// block itself and the condition of a single-condition NOT block is
+// forbidden; OR blocks and other nested blocks are skipped since they don't
+// require any single condition.
+func collectConditions(block map[string]interface{}, required, forbidden map[string][]interface{}) {
+	for key, entry := range block {
+		for _, value := range models.Values(entry) {
+			collectCondition(key, value, required, forbidden)
+		}
+	}
+}
+
+// collectCondition adds a single condition of a block to the required or
+// forbidden conditions, see collectConditions
+func collectCondition(key string, value interface{}, required, forbidden map[string][]interface{}) {
+	switch key {
+	case "AND":
+		if nested, ok := value.(map[string]interface{}); ok {
+			collectConditions(nested, required, forbidden)
+		}
+	case "NOT":
+		// Several conditions in a NOT block only forbid them together
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) == 1 {
+			for notKey, notValue := range nested {
+				switch notValue.(type) {
+				case map[string]interface{}, models.Repeated:
+				default:
+					forbidden[notKey] = append(forbidden[notKey], notValue)
+				}
+			}
+		}
+	case "OR", "NOR", "NAND":
+	default:
+		if _, isBlock := value.(map[string]interface{}); !isBlock {
+			required[key] = append(required[key], value)
+		}
+	}
+}
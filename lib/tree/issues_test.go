@@ -0,0 +1,106 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+func TestIssues(t *testing.T) {
+	techs := map[string]*models.Technology{
+		"tech_start":   {Key: "tech_start", IsStartTech: true, FeatureUnlocks: []string{"feature"}},
+		"tech_fine":    {Key: "tech_fine", Prerequisites: []string{"tech_start"}},
+		"tech_missing": {Key: "tech_missing", Prerequisites: []string{"tech_gone"}},
+		"tech_after":   {Key: "tech_after", Prerequisites: []string{"tech_missing"}},
+		"tech_either": {
+			Key:                "tech_either",
+			Prerequisites:      []string{"tech_gone", "tech_fine"},
+			PrerequisiteGroups: [][]string{{"tech_gone"}, {"tech_fine"}},
+		},
+		"tech_never": {
+			Key:           "tech_never",
+			Prerequisites: []string{"tech_start"},
+			Potential: &models.Condition{Raw: map[string]interface{}{
+				"AND": map[string]interface{}{
+					"is_gestalt": true,
+					"NOT":        map[string]interface{}{"is_gestalt": true},
+				},
+			}},
+		},
+		"tech_crowded": {
+			Key:           "tech_crowded",
+			Prerequisites: []string{"tech_start"},
+			Potential: &models.Condition{Raw: map[string]interface{}{
+				"num_owned_planets": models.Comparison{Operator: ">", Value: 5},
+				"NOT": map[string]interface{}{
+					"num_owned_planets": models.Comparison{Operator: ">", Value: 5},
+				},
+			}},
+		},
+		"tech_cycle_a": {Key: "tech_cycle_a", Prerequisites: []string{"tech_cycle_b"}},
+		"tech_cycle_b": {Key: "tech_cycle_b", Prerequisites: []string{"tech_cycle_a"}},
+		"tech_self": {
+			Key:           "tech_self",
+			Prerequisites: []string{"tech_start"},
+			Potential: &models.Condition{Raw: map[string]interface{}{
+				"has_technology": models.Repeated{"tech_start", "tech_self"},
+			}},
+		},
+		"tech_orphan": {Key: "tech_orphan"},
+		"tech_event":  {Key: "tech_event", IsEvent: true},
+	}
+	issues := NewTechTree(techs).Issues()
+
+	expected := []Issue{
+		{Tech: "tech_after", Kind: IssueUnreachable, Reason: "prerequisite tech_missing is unreachable"},
+		{Tech: "tech_crowded", Kind: IssueUnreachable, Reason: "potential both requires and forbids num_owned_planets > 5"},
+		{Tech: "tech_cycle_a", Kind: IssueUnreachable, Reason: "part of or depends on a prerequisite cycle"},
+		{Tech: "tech_cycle_b", Kind: IssueUnreachable, Reason: "part of or depends on a prerequisite cycle"},
+		{Tech: "tech_missing", Kind: IssueUnreachable, Reason: "unknown prerequisite tech_gone"},
+		{Tech: "tech_never", Kind: IssueUnreachable, Reason: "potential both requires and forbids is_gestalt = true"},
+		{Tech: "tech_orphan", Kind: IssueOrphan, Reason: "no prerequisites, no dependents and no feature unlocks"},
+		{Tech: "tech_self", Kind: IssueUnreachable, Reason: "potential requires the technology itself"},
+	}
+	if len(issues) != len(expected) {
+		t.Fatalf("Expected %d issues, got %+v", len(expected), issues)
+	}
+	for i := range expected {
+		if issues[i] != expected[i] {
+			t.Errorf("Issue %d: expected %+v, got %+v", i, expected[i], issues[i])
+		}
+	}
+}
+
+func TestTierIssues(t *testing.T) {
+	techs := map[string]*models.Technology{
+		"tech_start":    {Key: "tech_start", Tier: 0, IsStartTech: true},
+		"tech_basic":    {Key: "tech_basic", Tier: 0},
+		"tech_event":    {Key: "tech_event", Tier: 1, IsEvent: true},
+		"tech_advanced": {Key: "tech_advanced", Tier: 1, Prerequisites: []string{"tech_basic"}},
+		"tech_early":    {Key: "tech_early", Tier: 1, Prerequisites: []string{"tech_late"}},
+		"tech_late":     {Key: "tech_late", Tier: 2},
+		"tech_unknown":  {Key: "tech_unknown", Tier: 9},
+	}
+	techTree := NewTechTree(techs)
+
+	// Tier 2 needs 3 technologies of tier 1, but the event technology can't
+	// be drawn
+	issues := techTree.TierIssues(map[int]int{0: 0, 1: 2, 2: 3})
+	expected := []Issue{
+		{Tech: "tech_early", Kind: IssuePrerequisiteTier, Reason: "tier 1 but prerequisite tech_late is tier 2"},
+		{Tech: "tech_late", Kind: IssueTierRequirement, Reason: "tier 2 requires 3 technologies of tier 1 but only 2 can be researched"},
+		{Tech: "tech_unknown", Kind: IssueTierRequirement, Reason: "tier 9 has no tier rule"},
+	}
+	if len(issues) != len(expected) {
+		t.Fatalf("Expected %d issues, got %+v", len(expected), issues)
+	}
+	for i := range expected {
+		if issues[i] != expected[i] {
+			t.Errorf("Issue %d: expected %+v, got %+v", i, expected[i], issues[i])
+		}
+	}
+
+	if issues := techTree.TierIssues(nil); len(issues) != 1 || issues[0].Kind != IssuePrerequisiteTier {
+		t.Errorf("Expected only the prerequisite tier issue without rules, got %+v", issues)
+	}
+}
@@ -0,0 +1,50 @@
+package tree
+
+import (
+	"math"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+// OfferChances estimates for every technology drawn as a research option the
+// chance that it is among the alternatives offered in its area. The pool is
+// approximated by the technologies of the same area and tier; each
+// alternative is a draw weighted by base weight, so a technology of weight w
+// in a pool of total weight W is offered with 1-(1-w/W)^alternatives, and
+// always when the pool has no more technologies than alternatives. Weight
+// modifiers are not evaluated. Technologies that are not drawn, such
+// as start and event technologies, or have no weight are left out.
+func (t *TechTree) OfferChances(alternatives int) map[string]float64 {
+	type pool struct {
+		area string
+		tier int
+	}
+	weights := make(map[pool]float64)
+	sizes := make(map[pool]int)
+	for _, node := range t.nodes {
+		if drawn(node.Tech) {
+			weights[pool{node.Tech.Area, node.Tech.Tier}] += float64(node.Tech.Weight)
+			sizes[pool{node.Tech.Area, node.Tech.Tier}]++
+		}
+	}
+
+	chances := make(map[string]float64)
+	for key, node := range t.nodes {
+		if !drawn(node.Tech) || alternatives <= 0 {
+			continue
+		}
+		p := pool{node.Tech.Area, node.Tech.Tier}
+		if sizes[p] <= alternatives {
+			chances[key] = 1
+			continue
+		}
+		share := float64(node.Tech.Weight) / weights[p]
+		chances[key] = 1 - math.Pow(1-share, float64(alternatives))
+	}
+	return chances
+}
+
+// drawn reports whether a technology can be drawn as a research option
+func drawn(tech *models.Technology) bool {
+	return tech.Acquisition() == models.AcquisitionResearch && tech.Weight > 0
+}
@@ -0,0 +1,45 @@
+package tree
+
+import (
+	"math"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+func TestOfferChances(t *testing.T) {
+	techs := map[string]*models.Technology{
+		"tech_common":  {Key: "tech_common", Area: "physics", Tier: 1, Weight: 75},
+		"tech_rare":    {Key: "tech_rare", Area: "physics", Tier: 1, Weight: 25},
+		"tech_alone":   {Key: "tech_alone", Area: "physics", Tier: 2, Weight: 10},
+		"tech_other":   {Key: "tech_other", Area: "society", Tier: 1, Weight: 100},
+		"tech_start":   {Key: "tech_start", Area: "physics", Tier: 1, Weight: 100, IsStartTech: true},
+		"tech_event":   {Key: "tech_event", Area: "physics", Tier: 1, Weight: 100, IsEvent: true},
+		"tech_nothing": {Key: "tech_nothing", Area: "physics", Tier: 1},
+	}
+	chances := NewTechTree(techs).OfferChances(1)
+
+	expected := map[string]float64{
+		"tech_common": 0.75,
+		"tech_rare":   0.25,
+		"tech_alone":  1,
+		"tech_other":  1,
+	}
+	if len(chances) != len(expected) {
+		t.Errorf("Expected chances for %d technologies, got %v", len(expected), chances)
+	}
+	for key, want := range expected {
+		if got, exists := chances[key]; !exists || math.Abs(got-want) > 1e-9 {
+			t.Errorf("Expected %s to be offered with %g, got %g", key, want, got)
+		}
+	}
+
+	// A pool no larger than the alternatives is always offered
+	if chance := NewTechTree(techs).OfferChances(2)["tech_rare"]; chance != 1 {
+		t.Errorf("Expected tech_rare to always be offered with 2 alternatives, got %g", chance)
+	}
+
+	if chances := NewTechTree(techs).OfferChances(0); len(chances) != 0 {
+		t.Errorf("Expected no chances without alternatives, got %v", chances)
+	}
+}
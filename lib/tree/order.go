@@ -0,0 +1,81 @@
+package tree
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// TopologicalOrder returns every technology after all of its prerequisites.
+// Among technologies whose prerequisites are done, lower levels come first,
+// then keys in lexical order, so the order is the same on every run.
+// Technologies in a prerequisite cycle can't be ordered and come last, by key.
+func (t *TechTree) TopologicalOrder() []*TechNode {
+	remaining := make(map[*TechNode]int, len(t.nodes))
+	ready := &readyQueue{}
+	for _, node := range t.nodes {
+		remaining[node] = len(node.Dependencies)
+		if len(node.Dependencies) == 0 {
+			heap.Push(ready, node)
+		}
+	}
+
+	order := make([]*TechNode, 0, len(t.nodes))
+	for ready.Len() > 0 {
+		node := heap.Pop(ready).(*TechNode)
+		order = append(order, node)
+		for _, dependent := range node.Dependents {
+			remaining[dependent]--
+			if remaining[dependent] == 0 {
+				heap.Push(ready, dependent)
+			}
+		}
+	}
+
+	if len(order) < len(t.nodes) {
+		var cyclic []*TechNode
+		for node, count := range remaining {
+			if count > 0 {
+				cyclic = append(cyclic, node)
+			}
+		}
+		sort.Slice(cyclic, func(i, j int) bool { return cyclic[i].Tech.Key < cyclic[j].Tech.Key })
+		order = append(order, cyclic...)
+	}
+
+	return order
+}
+
+// TopologicalOrderByArea returns the technologies of an area in
+// TopologicalOrder
+func (t *TechTree) TopologicalOrderByArea(area string) []*TechNode {
+	var order []*TechNode
+	for _, node := range t.TopologicalOrder() {
+		if node.Tech.Area == area {
+			order = append(order, node)
+		}
+	}
+	return order
+}
+
+// readyQueue is a min-heap of nodes ordered by level, then key
+type readyQueue []*TechNode
+
+func (q readyQueue) Len() int { return len(q) }
+
+func (q readyQueue) Less(i, j int) bool {
+	if q[i].Level != q[j].Level {
+		return q[i].Level < q[j].Level
+	}
+	return q[i].Tech.Key < q[j].Tech.Key
+}
+
+func (q readyQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *readyQueue) Push(x interface{}) { *q = append(*q, x.(*TechNode)) }
+
+func (q *readyQueue) Pop() interface{} {
+	old := *q
+	node := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return node
+}
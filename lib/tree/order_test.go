@@ -0,0 +1,46 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+func orderKeys(nodes []*TechNode) []string {
+	keys := make([]string, len(nodes))
+	for i, node := range nodes {
+		keys[i] = node.Tech.Key
+	}
+	return keys
+}
+
+func TestTopologicalOrder(t *testing.T) {
+	techs := map[string]*models.Technology{
+		"tech_c":       {Key: "tech_c", Area: "physics", Prerequisites: []string{"tech_a", "tech_b"}},
+		"tech_b":       {Key: "tech_b", Area: "society"},
+		"tech_a":       {Key: "tech_a", Area: "physics"},
+		"tech_d":       {Key: "tech_d", Area: "physics", Prerequisites: []string{"tech_a"}},
+		"tech_cycle_1": {Key: "tech_cycle_1", Area: "physics", Prerequisites: []string{"tech_cycle_2"}},
+		"tech_cycle_2": {Key: "tech_cycle_2", Area: "physics", Prerequisites: []string{"tech_cycle_1"}},
+	}
+
+	expected := []string{"tech_a", "tech_b", "tech_c", "tech_d", "tech_cycle_1", "tech_cycle_2"}
+	for run := 0; run < 5; run++ {
+		tree := NewTechTree(techs)
+		order := orderKeys(tree.TopologicalOrder())
+		if len(order) != len(expected) {
+			t.Fatalf("Expected %v, got %v", expected, order)
+		}
+		for i := range expected {
+			if order[i] != expected[i] {
+				t.Fatalf("Expected %v, got %v", expected, order)
+			}
+		}
+	}
+
+	tree := NewTechTree(techs)
+	physics := orderKeys(tree.TopologicalOrderByArea("physics"))
+	if len(physics) != 5 || physics[0] != "tech_a" || physics[1] != "tech_c" {
+		t.Errorf("Expected the physics technologies in research order, got %v", physics)
+	}
+}
@@ -0,0 +1,143 @@
+package tree
+
+// PathStep is a technology on a research path
+type PathStep struct {
+	Node           *TechNode
+	CumulativeCost int // Cost of this and every earlier step
+}
+
+// ResearchPath lists the technologies to research to reach a target
+type ResearchPath struct {
+	Steps     []PathStep // In research order, ending with the target
+	TotalCost int
+}
+
+// PathTo returns the smallest set of technologies needed to research the
+// target, in TopologicalOrder. Start technologies are already researched and
+// left out. When a technology has alternative prerequisite groups, the group
+// whose technologies cost the least in total is followed. It returns false if
+// the target is unknown.
+func (t *TechTree) PathTo(targetKey string) (ResearchPath, bool) {
+	target, exists := t.nodes[targetKey]
+	if !exists {
+		return ResearchPath{}, false
+	}
+
+	required := t.requiredFor(target, make(map[*TechNode]map[*TechNode]bool), make(map[*TechNode]bool))
+
+	var path ResearchPath
+	for _, node := range t.TopologicalOrder() {
+		if !required[node] || (node.Tech.IsStartTech && node != target) {
+			continue
+		}
+		path.TotalCost += node.Tech.Cost
+		path.Steps = append(path.Steps, PathStep{Node: node, CumulativeCost: path.TotalCost})
+	}
+	return path, true
+}
+
+// requiredFor returns node and the technologies it needs, following the
+// cheapest prerequisite group. Results are memoized; prerequisites that lead
+// back to a node being resolved are ignored.
+func (t *TechTree) requiredFor(node *TechNode, memo map[*TechNode]map[*TechNode]bool, resolving map[*TechNode]bool) map[*TechNode]bool {
+	if required, done := memo[node]; done {
+		return required
+	}
+	resolving[node] = true
+	defer delete(resolving, node)
+
+	var best map[*TechNode]bool
+	bestCost := 0
+	for _, group := range t.prerequisiteGroups(node) {
+		required := map[*TechNode]bool{}
+		for _, dependency := range group {
+			if resolving[dependency] {
+				continue
+			}
+			for n := range t.requiredFor(dependency, memo, resolving) {
+				required[n] = true
+			}
+		}
+
+		cost := 0
+		for n := range required {
+			if !n.Tech.IsStartTech {
+				cost += n.Tech.Cost
+			}
+		}
+		if best == nil || cost < bestCost {
+			best, bestCost = required, cost
+		}
+	}
+
+	if best == nil {
+		best = map[*TechNode]bool{}
+	}
+	best[node] = true
+	memo[node] = best
+	return best
+}
+
+// prerequisiteGroups returns the prerequisite nodes of each group of a
+// technology. Without several groups, all dependencies form one group.
+// Prerequisites missing from the tree are left out.
+func (t *TechTree) prerequisiteGroups(node *TechNode) [][]*TechNode {
+	if len(node.Tech.PrerequisiteGroups) < 2 {
+		return [][]*TechNode{node.Dependencies}
+	}
+
+	groups := make([][]*TechNode, 0, len(node.Tech.PrerequisiteGroups))
+	for _, keys := range node.Tech.PrerequisiteGroups {
+		var group []*TechNode
+		for _, key := range keys {
+			if dependency, exists := t.nodes[key]; exists {
+				group = append(group, dependency)
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// CumulativeCost is the research cost of a technology together with the
+// technologies PathTo would research first
+type CumulativeCost struct {
+	Total  int            `json:"total"`
+	ByArea map[string]int `json:"byArea"` // Part of Total spent in each research area
+}
+
+// CumulativeCosts returns the CumulativeCost of every technology, by key.
+// Each prerequisite is counted once, however many paths lead through it.
+func (t *TechTree) CumulativeCosts() map[string]CumulativeCost {
+	memo := make(map[*TechNode]map[*TechNode]bool)
+	costs := make(map[string]CumulativeCost, len(t.nodes))
+	for key, target := range t.nodes {
+		cost := CumulativeCost{ByArea: make(map[string]int)}
+		for node := range t.requiredFor(target, memo, make(map[*TechNode]bool)) {
+			if node.Tech.IsStartTech && node != target {
+				continue
+			}
+			cost.Total += node.Tech.Cost
+			cost.ByArea[node.Tech.Area] += node.Tech.Cost
+		}
+		costs[key] = cost
+	}
+	return costs
+}
+
+// Remaining returns, for each area, the technologies not yet researched in
+// TopologicalOrder with their cumulative cost within the area. Areas without
+// remaining technologies are left out.
+func (t *TechTree) Remaining(researched map[string]bool) map[string]ResearchPath {
+	remaining := make(map[string]ResearchPath)
+	for _, node := range t.TopologicalOrder() {
+		if researched[node.Tech.Key] {
+			continue
+		}
+		path := remaining[node.Tech.Area]
+		path.TotalCost += node.Tech.Cost
+		path.Steps = append(path.Steps, PathStep{Node: node, CumulativeCost: path.TotalCost})
+		remaining[node.Tech.Area] = path
+	}
+	return remaining
+}
@@ -0,0 +1,109 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+func TestPathTo(t *testing.T) {
+	techs := map[string]*models.Technology{
+		"tech_start":     {Key: "tech_start", Cost: 0, IsStartTech: true},
+		"tech_cheap":     {Key: "tech_cheap", Cost: 100, Prerequisites: []string{"tech_start"}},
+		"tech_expensive": {Key: "tech_expensive", Cost: 5000, Prerequisites: []string{"tech_start"}},
+		"tech_middle":    {Key: "tech_middle", Cost: 500, Prerequisites: []string{"tech_cheap"}},
+		"tech_unrelated": {Key: "tech_unrelated", Cost: 50},
+		"tech_target": {
+			Key:                "tech_target",
+			Cost:               1000,
+			Prerequisites:      []string{"tech_middle", "tech_expensive"},
+			PrerequisiteGroups: [][]string{{"tech_expensive"}, {"tech_middle"}},
+		},
+	}
+	tree := NewTechTree(techs)
+
+	path, exists := tree.PathTo("tech_target")
+	if !exists {
+		t.Fatal("Expected a path to tech_target")
+	}
+
+	expected := []struct {
+		key        string
+		cumulative int
+	}{
+		{"tech_cheap", 100},
+		{"tech_middle", 600},
+		{"tech_target", 1600},
+	}
+	if len(path.Steps) != len(expected) {
+		t.Fatalf("Expected %d steps, got %v", len(expected), orderKeys(pathNodes(path)))
+	}
+	for i, step := range path.Steps {
+		if step.Node.Tech.Key != expected[i].key || step.CumulativeCost != expected[i].cumulative {
+			t.Errorf("Step %d: expected %s at %d, got %s at %d", i, expected[i].key, expected[i].cumulative, step.Node.Tech.Key, step.CumulativeCost)
+		}
+	}
+	if path.TotalCost != 1600 {
+		t.Errorf("Expected total cost 1600, got %d", path.TotalCost)
+	}
+
+	if _, exists := tree.PathTo("tech_missing"); exists {
+		t.Error("Expected no path to an unknown technology")
+	}
+}
+
+func pathNodes(path ResearchPath) []*TechNode {
+	nodes := make([]*TechNode, len(path.Steps))
+	for i, step := range path.Steps {
+		nodes[i] = step.Node
+	}
+	return nodes
+}
+
+func TestCumulativeCosts(t *testing.T) {
+	techs := map[string]*models.Technology{
+		"tech_start":  {Key: "tech_start", Area: "physics", Cost: 0, IsStartTech: true},
+		"tech_left":   {Key: "tech_left", Area: "physics", Cost: 100, Prerequisites: []string{"tech_start"}},
+		"tech_right":  {Key: "tech_right", Area: "society", Cost: 200, Prerequisites: []string{"tech_start"}},
+		"tech_top":    {Key: "tech_top", Area: "physics", Cost: 300, Prerequisites: []string{"tech_left", "tech_right"}},
+		"tech_beyond": {Key: "tech_beyond", Area: "engineering", Cost: 400, Prerequisites: []string{"tech_top", "tech_left"}},
+	}
+	costs := NewTechTree(techs).CumulativeCosts()
+
+	// tech_left is reached through two paths but only counted once
+	beyond := costs["tech_beyond"]
+	if beyond.Total != 1000 {
+		t.Errorf("Expected a cumulative cost of 1000 for tech_beyond, got %d", beyond.Total)
+	}
+	expected := map[string]int{"physics": 400, "society": 200, "engineering": 400}
+	for area, cost := range expected {
+		if beyond.ByArea[area] != cost {
+			t.Errorf("Expected %d in %s, got %d", cost, area, beyond.ByArea[area])
+		}
+	}
+
+	if costs["tech_start"].Total != 0 || costs["tech_left"].Total != 100 {
+		t.Errorf("Unexpected costs: start %d, left %d", costs["tech_start"].Total, costs["tech_left"].Total)
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	techs := map[string]*models.Technology{
+		"tech_start":  {Key: "tech_start", Area: "physics", IsStartTech: true},
+		"tech_first":  {Key: "tech_first", Area: "physics", Cost: 100, Prerequisites: []string{"tech_start"}},
+		"tech_second": {Key: "tech_second", Area: "physics", Cost: 200, Prerequisites: []string{"tech_first"}},
+		"tech_done":   {Key: "tech_done", Area: "society", Cost: 300},
+	}
+	remaining := NewTechTree(techs).Remaining(map[string]bool{"tech_start": true, "tech_done": true})
+
+	if _, exists := remaining["society"]; exists {
+		t.Error("Expected no entry for an area without remaining technologies")
+	}
+	physics := remaining["physics"]
+	if len(physics.Steps) != 2 || physics.TotalCost != 300 {
+		t.Fatalf("Expected 2 remaining physics technologies costing 300, got %v costing %d", orderKeys(pathNodes(physics)), physics.TotalCost)
+	}
+	if physics.Steps[0].Node.Tech.Key != "tech_first" || physics.Steps[1].CumulativeCost != 300 {
+		t.Errorf("Unexpected steps: %v", orderKeys(pathNodes(physics)))
+	}
+}
@@ -0,0 +1,46 @@
+package tree
+
+// Research states of a technology for an empire, as returned by
+// ResearchStatus
+const (
+	StatusResearched = "researched" // Already researched
+	StatusAvailable  = "available"  // A prerequisite group is researched, so it can be drawn
+	StatusLocked     = "locked"     // Prerequisites are missing
+)
+
+// ResearchStatus returns the state of every technology given the keys an
+// empire has researched. A technology is available when every technology of
+// one of its prerequisite groups is researched; other conditions such as the
+// potential and tier rules are not checked.
+func (t *TechTree) ResearchStatus(researched map[string]bool) map[string]string {
+	status := make(map[string]string, len(t.nodes))
+	for key, node := range t.nodes {
+		switch {
+		case researched[key]:
+			status[key] = StatusResearched
+		case groupResearched(prerequisiteKeyGroups(node.Tech), researched):
+			status[key] = StatusAvailable
+		default:
+			status[key] = StatusLocked
+		}
+	}
+	return status
+}
+
+// groupResearched reports whether every prerequisite of one group is
+// researched
+func groupResearched(groups [][]string, researched map[string]bool) bool {
+	for _, group := range groups {
+		complete := true
+		for _, key := range group {
+			if !researched[key] {
+				complete = false
+				break
+			}
+		}
+		if complete {
+			return true
+		}
+	}
+	return false
+}
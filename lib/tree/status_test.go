@@ -0,0 +1,35 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+func TestResearchStatus(t *testing.T) {
+	techs := map[string]*models.Technology{
+		"tech_start": {Key: "tech_start", IsStartTech: true},
+		"tech_next":  {Key: "tech_next", Prerequisites: []string{"tech_start"}},
+		"tech_both":  {Key: "tech_both", Prerequisites: []string{"tech_start", "tech_next"}},
+		"tech_either": {
+			Key:                "tech_either",
+			Prerequisites:      []string{"tech_next", "tech_start"},
+			PrerequisiteGroups: [][]string{{"tech_next"}, {"tech_start"}},
+		},
+		"tech_root": {Key: "tech_root"},
+	}
+	status := NewTechTree(techs).ResearchStatus(map[string]bool{"tech_start": true})
+
+	expected := map[string]string{
+		"tech_start":  StatusResearched,
+		"tech_next":   StatusAvailable,
+		"tech_both":   StatusLocked,
+		"tech_either": StatusAvailable,
+		"tech_root":   StatusAvailable,
+	}
+	for key, want := range expected {
+		if status[key] != want {
+			t.Errorf("%s: expected %s, got %s", key, want, status[key])
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package tree
+
+// Ancestors returns every technology the given one depends on, directly or
+// through other prerequisites, in TopologicalOrder. It returns false if the
+// technology is unknown.
+func (t *TechTree) Ancestors(key string) ([]*TechNode, bool) {
+	return t.reachable(key, func(node *TechNode) []*TechNode { return node.Dependencies })
+}
+
+// Descendants returns every technology that depends on the given one,
+// directly or through other technologies, in TopologicalOrder. It returns
+// false if the technology is unknown.
+func (t *TechTree) Descendants(key string) ([]*TechNode, bool) {
+	return t.reachable(key, func(node *TechNode) []*TechNode { return node.Dependents })
+}
+
+// reachable returns the nodes reachable from key through next, excluding the
+// starting node, in TopologicalOrder
+func (t *TechTree) reachable(key string, next func(*TechNode) []*TechNode) ([]*TechNode, bool) {
+	start, exists := t.nodes[key]
+	if !exists {
+		return nil, false
+	}
+
+	seen := map[*TechNode]bool{start: true}
+	stack := []*TechNode{start}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, n := range next(node) {
+			if !seen[n] {
+				seen[n] = true
+				stack = append(stack, n)
+			}
+		}
+	}
+
+	nodes := []*TechNode{}
+	for _, node := range t.TopologicalOrder() {
+		if seen[node] && node != start {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, true
+}
@@ -0,0 +1,40 @@
+package tree
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/lib/models"
+)
+
+func TestAncestorsAndDescendants(t *testing.T) {
+	techs := map[string]*models.Technology{
+		"tech_a": {Key: "tech_a"},
+		"tech_b": {Key: "tech_b", Prerequisites: []string{"tech_a"}},
+		"tech_c": {Key: "tech_c", Prerequisites: []string{"tech_b"}},
+		"tech_d": {Key: "tech_d", Prerequisites: []string{"tech_b", "tech_e"}},
+		"tech_e": {Key: "tech_e"},
+		"tech_f": {Key: "tech_f"},
+	}
+	tree := NewTechTree(techs)
+
+	ancestors, exists := tree.Ancestors("tech_d")
+	if !exists {
+		t.Fatal("Expected tech_d to exist")
+	}
+	if keys := orderKeys(ancestors); !reflect.DeepEqual(keys, []string{"tech_a", "tech_e", "tech_b"}) {
+		t.Errorf("Unexpected ancestors %v", keys)
+	}
+
+	descendants, _ := tree.Descendants("tech_a")
+	if keys := orderKeys(descendants); !reflect.DeepEqual(keys, []string{"tech_b", "tech_c", "tech_d"}) {
+		t.Errorf("Unexpected descendants %v", keys)
+	}
+
+	if none, _ := tree.Descendants("tech_f"); len(none) != 0 {
+		t.Errorf("Expected no descendants, got %v", orderKeys(none))
+	}
+	if _, exists := tree.Ancestors("tech_missing"); exists {
+		t.Error("Expected an unknown technology to be reported")
+	}
+}
@@ -1,32 +1,65 @@
+// Package tree builds the technology dependency tree from parsed
+// technologies and answers questions about it, such as research paths,
+// research status for a set of researched technologies and offer chances.
 package tree
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"sort"
 
-	"stellaris-data-parser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/models"
 )
 
 // TechNode represents a node in the technology tree
 type TechNode struct {
 	Tech         *models.Technology
-	Dependencies []*TechNode
-	Dependents   []*TechNode
+	Dependencies []*TechNode // In the order of the prerequisites
+	Dependents   []*TechNode // Sorted by key
 	Level        int
 	Visited      bool
 }
 
-// TechTree represents the complete technology dependency tree
+// ErrMissingPrerequisite matches every MissingPrerequisite with errors.Is
+var ErrMissingPrerequisite = errors.New("missing prerequisite")
+
+// MissingPrerequisite records a prerequisite key that does not match any
+// known technology. It is an error returned by Validate.
+type MissingPrerequisite struct {
+	Tech         string `json:"tech"`
+	Prerequisite string `json:"prerequisite"`
+}
+
+func (m MissingPrerequisite) Error() string {
+	return fmt.Sprintf("%s: unknown prerequisite %q", m.Tech, m.Prerequisite)
+}
+
+// Is reports whether target is ErrMissingPrerequisite
+func (m MissingPrerequisite) Is(target error) bool {
+	return target == ErrMissingPrerequisite
+}
+
+// TechTree represents the complete technology dependency tree. A tree is
+// not modified after NewTechTree returns, so it is safe for concurrent reads.
+// The getters return copies of the node lists; the nodes are shared and must
+// not be modified.
 type TechTree struct {
 	nodes      map[string]*TechNode
+	sorted     []*TechNode // All nodes sorted by key
 	rootNodes  []*TechNode
 	maxLevel   int
 	byArea     map[string][]*TechNode
 	byTier     map[int][]*TechNode
 	byCategory map[string][]*TechNode
+	index      *treeIndex
+	missing    []MissingPrerequisite
 }
 
-// NewTechTree creates a new technology tree from parsed technologies
+// NewTechTree creates a new technology tree from parsed technologies. The
+// tree is built in key order, so every list it returns has the same order on
+// every run.
 func NewTechTree(technologies map[string]*models.Technology) *TechTree {
 	tree := &TechTree{
 		nodes:      make(map[string]*TechNode),
@@ -37,29 +70,44 @@ func NewTechTree(technologies map[string]*models.Technology) *TechTree {
 	}
 
 	// Create nodes for all technologies
-	for key, tech := range technologies {
+	keys := make([]string, 0, len(technologies))
+	for key := range technologies {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	tree.sorted = make([]*TechNode, len(keys))
+	for i, key := range keys {
 		node := &TechNode{
-			Tech:         tech,
+			Tech:         technologies[key],
 			Dependencies: []*TechNode{},
 			Dependents:   []*TechNode{},
 		}
 		tree.nodes[key] = node
+		tree.sorted[i] = node
 	}
 
 	// Build dependencies
-	for key, node := range tree.nodes {
+	for i, node := range tree.sorted {
+		key := keys[i]
 		for _, prereqKey := range node.Tech.Prerequisites {
 			if prereqNode, exists := tree.nodes[prereqKey]; exists {
 				node.Dependencies = append(node.Dependencies, prereqNode)
 				prereqNode.Dependents = append(prereqNode.Dependents, node)
 			} else {
-				fmt.Printf("Warning: technology '%s' has unknown prerequisite '%s'\n", key, prereqKey)
+				tree.missing = append(tree.missing, MissingPrerequisite{Tech: key, Prerequisite: prereqKey})
 			}
 		}
 	}
 
+	sort.Slice(tree.missing, func(i, j int) bool {
+		if tree.missing[i].Tech == tree.missing[j].Tech {
+			return tree.missing[i].Prerequisite < tree.missing[j].Prerequisite
+		}
+		return tree.missing[i].Tech < tree.missing[j].Tech
+	})
+
 	// Find root nodes (technologies with no prerequisites)
-	for _, node := range tree.nodes {
+	for _, node := range tree.sorted {
 		if len(node.Dependencies) == 0 {
 			tree.rootNodes = append(tree.rootNodes, node)
 		}
@@ -71,6 +119,9 @@ func NewTechTree(technologies map[string]*models.Technology) *TechTree {
 	// Organize by area, tier, and category
 	tree.organizeByAttributes()
 
+	// Build secondary lookup indexes
+	tree.buildIndexes()
+
 	return tree
 }
 
@@ -127,7 +178,7 @@ func (t *TechTree) calculateLevels() {
 
 // organizeByAttributes organizes nodes by area, tier, and category
 func (t *TechTree) organizeByAttributes() {
-	for _, node := range t.nodes {
+	for _, node := range t.sorted {
 		// By area
 		if node.Tech.Area != "" {
 			t.byArea[node.Tech.Area] = append(t.byArea[node.Tech.Area], node)
@@ -143,9 +194,27 @@ func (t *TechTree) organizeByAttributes() {
 	}
 }
 
-// GetRootNodes returns all root nodes (no prerequisites)
+// GetMissingPrerequisites returns all prerequisites that reference unknown
+// technologies, sorted by technology key
+func (t *TechTree) GetMissingPrerequisites() []MissingPrerequisite {
+	return slices.Clone(t.missing)
+}
+
+// Validate returns the prerequisites that reference unknown technologies as
+// an error joining a MissingPrerequisite for each, or nil if there are none.
+// Use errors.Is with ErrMissingPrerequisite to check for them and errors.As
+// to get the first one.
+func (t *TechTree) Validate() error {
+	errs := make([]error, len(t.missing))
+	for i, missing := range t.missing {
+		errs[i] = missing
+	}
+	return errors.Join(errs...)
+}
+
+// GetRootNodes returns all root nodes (no prerequisites), sorted by key
 func (t *TechTree) GetRootNodes() []*TechNode {
-	return t.rootNodes
+	return slices.Clone(t.rootNodes)
 }
 
 // GetNode returns a specific node by technology key
@@ -154,19 +223,25 @@ func (t *TechTree) GetNode(key string) (*TechNode, bool) {
 	return node, exists
 }
 
-// GetAllNodes returns all nodes in the tree
+// GetAllNodes returns all nodes in the tree, by key. Use GetSortedNodes to
+// iterate them in a stable order.
 func (t *TechTree) GetAllNodes() map[string]*TechNode {
-	return t.nodes
+	return maps.Clone(t.nodes)
+}
+
+// GetSortedNodes returns all nodes in the tree sorted by key
+func (t *TechTree) GetSortedNodes() []*TechNode {
+	return slices.Clone(t.sorted)
 }
 
-// GetNodesByArea returns nodes filtered by research area
+// GetNodesByArea returns nodes filtered by research area, sorted by key
 func (t *TechTree) GetNodesByArea(area string) []*TechNode {
-	return t.byArea[area]
+	return slices.Clone(t.byArea[area])
 }
 
-// GetNodesByTier returns nodes filtered by tier
+// GetNodesByTier returns nodes filtered by tier, sorted by key
 func (t *TechTree) GetNodesByTier(tier int) []*TechNode {
-	return t.byTier[tier]
+	return slices.Clone(t.byTier[tier])
 }
 
 // GetMaxLevel returns the maximum depth of the tree
@@ -1,9 +1,13 @@
 package tree
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
 	"testing"
 
-	"stellaris-data-parser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/models"
 )
 
 func createTestTechnologies() map[string]*models.Technology {
@@ -315,13 +319,30 @@ func TestUnknownPrerequisite(t *testing.T) {
 		},
 	}
 
-	// This should not panic, but print a warning
+	// This should not panic, but record the missing prerequisite
 	tree := NewTechTree(technologies)
 
 	node, _ := tree.GetNode("tech_with_missing_prereq")
 	if len(node.Dependencies) != 0 {
 		t.Errorf("Expected 0 dependencies (missing prereq), got %d", len(node.Dependencies))
 	}
+
+	missing := tree.GetMissingPrerequisites()
+	if len(missing) != 1 || missing[0].Tech != "tech_with_missing_prereq" || missing[0].Prerequisite != "tech_nonexistent" {
+		t.Errorf("Expected missing prerequisite to be recorded, got %v", missing)
+	}
+
+	err := tree.Validate()
+	if !errors.Is(err, ErrMissingPrerequisite) {
+		t.Errorf("Expected Validate to return ErrMissingPrerequisite, got %v", err)
+	}
+	var first MissingPrerequisite
+	if !errors.As(err, &first) || first != missing[0] {
+		t.Errorf("Expected Validate to return the missing prerequisite, got %v", err)
+	}
+	if err := NewTechTree(createTestTechnologies()).Validate(); err != nil {
+		t.Errorf("Expected no error for a complete tree, got %v", err)
+	}
 }
 
 func TestEmptyTechTree(t *testing.T) {
@@ -383,3 +404,88 @@ func TestComplexDependencyChain(t *testing.T) {
 		t.Errorf("Expected 2 dependencies for tech_d, got %d", len(nodeD.Dependencies))
 	}
 }
+
+// manyTechnologies returns technologies that all depend on tech_base, spread
+// over two areas, so map iteration order would show in the tree's lists
+func manyTechnologies() map[string]*models.Technology {
+	technologies := map[string]*models.Technology{
+		"tech_base": {Key: "tech_base", Area: "physics", Category: []string{"computing"}},
+	}
+	for i := 0; i < 30; i++ {
+		key := fmt.Sprintf("tech_%02d", i)
+		area := "physics"
+		if i%2 == 1 {
+			area = "society"
+		}
+		technologies[key] = &models.Technology{Key: key, Area: area, Category: []string{"computing"}, Prerequisites: []string{"tech_base"}}
+		root := fmt.Sprintf("tech_root_%02d", i)
+		technologies[root] = &models.Technology{Key: root, Area: area}
+	}
+	return technologies
+}
+
+func TestStableOrder(t *testing.T) {
+	keysOf := func(nodes []*TechNode) []string {
+		keys := make([]string, len(nodes))
+		for i, node := range nodes {
+			keys[i] = node.Tech.Key
+		}
+		return keys
+	}
+	sorted := func(name string, nodes []*TechNode) {
+		t.Helper()
+		if keys := keysOf(nodes); !sort.StringsAreSorted(keys) {
+			t.Errorf("Expected %s sorted by key, got %v", name, keys)
+		}
+	}
+
+	// Map iteration order changes between loops, so a few trees catch lists
+	// built in map order
+	for i := 0; i < 5; i++ {
+		tree := NewTechTree(manyTechnologies())
+		base, _ := tree.GetNode("tech_base")
+
+		sorted("GetSortedNodes", tree.GetSortedNodes())
+		sorted("GetRootNodes", tree.GetRootNodes())
+		sorted("GetNodesByArea", tree.GetNodesByArea("society"))
+		sorted("GetNodesByTier", tree.GetNodesByTier(0))
+		sorted("GetNodesByCategory", tree.GetNodesByCategory("computing"))
+		sorted("Dependents", base.Dependents)
+
+		if len(tree.GetSortedNodes()) != len(tree.GetAllNodes()) {
+			t.Fatalf("Expected every node in GetSortedNodes, got %d of %d", len(tree.GetSortedNodes()), len(tree.GetAllNodes()))
+		}
+	}
+}
+
+func TestTechTreeConcurrentReads(t *testing.T) {
+	tree := NewTechTree(createTestTechnologies())
+
+	// Run with -race to check that reads don't modify the tree
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, node := range tree.GetSortedNodes() {
+				tree.GetNode(node.Tech.Key)
+				tree.GetNodesByArea(node.Tech.Area)
+				tree.FindByNamePrefix(node.Tech.Key)
+			}
+			nodes := tree.GetRootNodes()
+			sort.Slice(nodes, func(i, j int) bool { return nodes[i].Tech.Key > nodes[j].Tech.Key })
+		}()
+	}
+	wg.Wait()
+
+	// The getters return copies
+	roots := tree.GetRootNodes()
+	roots[0] = nil
+	delete(tree.GetAllNodes(), "tech_root_1")
+	if tree.GetRootNodes()[0] == nil {
+		t.Error("Expected GetRootNodes to return a copy")
+	}
+	if _, exists := tree.GetNode("tech_root_1"); !exists {
+		t.Error("Expected deleting from GetAllNodes not to change the tree")
+	}
+}
@@ -0,0 +1,589 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/danaketh/StellarisDataParser/internal/cli"
+	"github.com/danaketh/StellarisDataParser/internal/logging"
+	"github.com/danaketh/StellarisDataParser/internal/suppress"
+	"github.com/danaketh/StellarisDataParser/lib/config"
+	"github.com/danaketh/StellarisDataParser/lib/filter"
+	"github.com/danaketh/StellarisDataParser/lib/gamefs"
+	"github.com/danaketh/StellarisDataParser/lib/generator"
+	"github.com/danaketh/StellarisDataParser/lib/install"
+	"github.com/danaketh/StellarisDataParser/lib/localization"
+	"github.com/danaketh/StellarisDataParser/lib/mechanics"
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/parser"
+	"github.com/danaketh/StellarisDataParser/lib/progress"
+	"github.com/danaketh/StellarisDataParser/lib/timeline"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+// gameOptions holds the flags shared by every command that reads game data
+type gameOptions struct {
+	inputFlag    string // Name of the flag holding the game directory
+	noDetect     bool   // Require the game directory instead of detecting it
+	gameDir      string
+	modDirs      string
+	language     string
+	fallbacks    string // Comma-separated languages used for keys missing in language
+	strict       bool
+	configFile   string
+	progress     string // Progress event format: none or json
+	suppressFile string
+	verbose      bool   // Log debug records
+	quiet        bool   // Log warnings and errors only
+	logFormat    string // Log record format: text or json
+
+	// Set by commands that don't need names and descriptions, or on request
+	skipLocalization bool
+	// Set by parse with -raw to keep the script text of each technology
+	keepSource bool
+
+	// Set by validate
+	mods     []string
+	chain    []string // language followed by its fallbacks
+	config   *config.Config
+	detected bool // The game directory was detected automatically
+	reporter *progress.Reporter
+	rules    *suppress.Rules
+	logger   *slog.Logger
+	phases   *phaseTimer // Times the phases of load for the run summary, if set
+}
+
+// progressFormats are the values accepted by -progress
+var progressFormats = []string{"none", "json"}
+
+// gameData is the parsed game content used by the commands
+type gameData struct {
+	parser       *parser.TechParser
+	localization *localization.LocalizationParser // Empty with -skip-localization
+	areaNames    map[string]string                // Localized area names, by area key
+	technologies map[string]*models.Technology
+	tree         *tree.TechTree
+	mechanics    *mechanics.Mechanics    // Research defines, tier rules and static modifiers
+	triggers     parser.ScriptedTriggers // Scripted triggers, by name
+	defines      parser.Defines          // Game defines, by Namespace.KEY
+	gameVersion  models.GameVersion      // Empty when the game directory has no launcher settings
+	warnings     []gameWarning           // Warnings not suppressed by the suppression file
+
+	// Technologies without a name or description in any language of the chain
+	missingNames, missingDescriptions int
+}
+
+// register adds the shared flags to a command's flag set
+func (o *gameOptions) register(fs *flag.FlagSet) {
+	if o.inputFlag == "" {
+		o.inputFlag = "input"
+	}
+	usage := "Path to Stellaris game directory (detected automatically if omitted)"
+	if o.noDetect {
+		usage = "Path to Stellaris game directory (required)"
+	}
+	fs.StringVar(&o.gameDir, o.inputFlag, "", usage)
+	fs.StringVar(&o.modDirs, "mods", "", "Comma-separated list of mod directories, .zip archives or .mod descriptors, in load order")
+	fs.StringVar(&o.language, "language", "english", "Localization language used for names and descriptions")
+	fs.StringVar(&o.fallbacks, "fallback-languages", "english", "Comma-separated languages used, in order, for names and descriptions missing in -language")
+	fs.BoolVar(&o.strict, "strict", false, "Fail on the first malformed technology file instead of warning")
+	fs.StringVar(&o.configFile, "config", "", "Path to a JSON config file")
+	fs.StringVar(&o.suppressFile, "suppress", "", "Path to a JSON file listing warnings to suppress")
+	fs.StringVar(&o.progress, "progress", "none", "Progress event format written to stderr: none or json")
+	o.registerLogging(fs)
+}
+
+// registerLogging adds the flags selecting what is logged and how
+func (o *gameOptions) registerLogging(fs *flag.FlagSet) {
+	fs.BoolVar(&o.verbose, "verbose", false, "Log every file read and written")
+	fs.BoolVar(&o.quiet, "quiet", false, "Log warnings and errors only")
+	fs.StringVar(&o.logFormat, "log-format", "text", "Log format: text, written to stdout, or json, written to stderr")
+}
+
+// validate checks the shared flags, loads the config file and records every
+// problem found
+func (o *gameOptions) validate(problems *cli.Problems) {
+	if o.gameDir == "" && !o.noDetect {
+		if dir, err := install.Detect(); err == nil {
+			o.gameDir = dir
+			o.detected = true
+		}
+	}
+
+	if o.gameDir == "" {
+		message := "game directory is required"
+		if !o.noDetect {
+			message += " (" + install.ErrNotFound.Error() + ")"
+		}
+		problems.AddWithSuggestion(o.inputFlag, message,
+			"example: -"+o.inputFlag+" \"C:\\Steam\\steamapps\\common\\Stellaris\"")
+	} else if _, err := os.Stat(o.gameDir); os.IsNotExist(err) {
+		problems.AddWithSuggestion(o.inputFlag, fmt.Sprintf("game directory does not exist: %s", o.gameDir),
+			"check the path and make sure the game is installed")
+	} else if _, err := os.Stat(o.techDir()); os.IsNotExist(err) {
+		problems.AddWithSuggestion(o.inputFlag, fmt.Sprintf("technology directory not found: %s", o.techDir()),
+			"point -"+o.inputFlag+" to the Stellaris game root directory (expected <game_dir>/common/technology/)")
+	}
+
+	o.mods = splitList(o.modDirs)
+	for _, modDir := range o.mods {
+		if _, err := os.Stat(modDir); os.IsNotExist(err) {
+			problems.Add("mods", fmt.Sprintf("mod does not exist: %s", modDir))
+		} else if source, err := gamefs.Open(modDir); err != nil {
+			problems.Add("mods", err.Error())
+		} else {
+			source.Close()
+		}
+	}
+
+	cli.CheckChoice("language", o.language, localization.Languages, problems)
+	fallbacks := splitList(o.fallbacks)
+	for _, fallback := range fallbacks {
+		cli.CheckChoice("fallback-languages", fallback, localization.Languages, problems)
+	}
+	o.chain = localization.LanguageChain(o.language, fallbacks)
+
+	if o.progress != "" {
+		cli.CheckChoice("progress", o.progress, progressFormats, problems)
+	}
+	if o.progress == "json" {
+		o.reporter = progress.NewReporter(os.Stderr)
+	}
+
+	if o.verbose && o.quiet {
+		problems.Add("quiet", "cannot be combined with -verbose")
+	}
+	if o.logFormat == "" {
+		o.logFormat = "text"
+	}
+	cli.CheckChoice("log-format", o.logFormat, logging.Formats, problems)
+	o.logger = o.newLogger()
+
+	if o.suppressFile != "" {
+		rules, err := suppress.Load(o.suppressFile)
+		if err != nil {
+			problems.Add("suppress", err.Error())
+		}
+		o.rules = rules
+	}
+
+	// Load configuration
+	o.config = config.Default()
+	if o.configFile != "" {
+		loaded, err := config.Load(o.configFile)
+		if err != nil {
+			problems.Add("config", err.Error())
+		} else {
+			o.config = loaded
+		}
+	}
+}
+
+// newLogger returns the logger selected by -verbose, -quiet and -log-format.
+// JSON records go to stderr so they can be captured apart from the output of
+// the command.
+func (o *gameOptions) newLogger() *slog.Logger {
+	if o.logFormat == "json" {
+		return logging.New(os.Stderr, o.logFormat, logging.Level(o.verbose, o.quiet))
+	}
+	return logging.New(os.Stdout, o.logFormat, logging.Level(o.verbose, o.quiet))
+}
+
+// printBanner prints the banner of long-running commands unless the log is
+// quiet or structured
+func (o *gameOptions) printBanner() {
+	if !o.quiet && o.logFormat != "json" {
+		printBanner()
+	}
+}
+
+// techDir returns the technology directory of the game
+func (o *gameOptions) techDir() string {
+	return filepath.Join(o.gameDir, filepath.FromSlash(parser.TechnologyDir))
+}
+
+// localizationDir returns the localization directory of the game
+func (o *gameOptions) localizationDir() string {
+	return filepath.Join(o.gameDir, "localisation")
+}
+
+// mod is a mod given with -mods, opened with gamefs.Open
+type mod struct {
+	path   string
+	name   string
+	source gamefs.Source
+}
+
+// openMods opens the mods in load order. The caller closes them with
+// closeMods.
+func openMods(paths []string) ([]mod, error) {
+	mods := make([]mod, 0, len(paths))
+	for _, path := range paths {
+		source, err := gamefs.Open(path)
+		if err != nil {
+			closeMods(mods)
+			return nil, fmt.Errorf("failed to open mod: %w", err)
+		}
+		mods = append(mods, mod{path: path, name: gamefs.Name(path), source: source})
+	}
+	return mods, nil
+}
+
+// closeMods closes the sources of mods
+func closeMods(mods []mod) {
+	for _, m := range mods {
+		m.source.Close()
+	}
+}
+
+// localizationDir is a localization directory of the game or a mod
+type localizationDir struct {
+	fsys fs.FS
+	dir  string // Slash-separated path in fsys
+	path string // Path shown in progress events
+}
+
+// localizationDirs returns the existing localization directories of the game
+// or a mod at path: localisation_synced first, so localisation wins on
+// conflicting keys
+func localizationDirs(fsys fs.FS, path string) []localizationDir {
+	var dirs []localizationDir
+	for _, name := range []string{"localisation_synced", "localisation"} {
+		if _, err := fs.Stat(fsys, name); err == nil {
+			dirs = append(dirs, localizationDir{fsys: fsys, dir: name, path: filepath.Join(path, name)})
+		}
+	}
+	return dirs
+}
+
+// load parses technologies (base game, then mods), applies localization
+// and builds the technology tree. Progress is logged at level: info for
+// commands that report it, debug for the others. Warnings about the game data
+// are logged as warnings at the info level and as debug records otherwise.
+// Loading stops with ctx's error when ctx is cancelled.
+func (o *gameOptions) load(ctx context.Context, level slog.Level) (*gameData, error) {
+	if o.logger == nil {
+		o.logger = o.newLogger()
+	}
+	logf := func(msg string, args ...any) {
+		o.logger.Log(ctx, level, msg, args...)
+	}
+	warnLevel := slog.LevelWarn
+	if level < slog.LevelInfo {
+		warnLevel = level
+	}
+
+	if o.detected {
+		logf("Detected Stellaris installation", "dir", o.gameDir)
+	}
+
+	gameVersion, err := install.ReadVersion(o.gameDir)
+	if err != nil {
+		logf("Game version unknown", "error", err)
+	} else {
+		logf("Game version", "version", gameVersion.Version, "name", gameVersion.Name)
+	}
+
+	// Parse technology files
+	o.phases.begin("technologies")
+	logf("Reading technology files", "dir", o.techDir())
+	techParser := parser.NewTechParser(
+		parser.WithStrictMode(o.strict),
+		parser.WithKeepSource(o.keepSource),
+		parser.WithProgress(o.reporter),
+		parser.WithLogger(o.logger),
+	)
+
+	if err := techParser.ParseDirectoryContext(ctx, o.techDir()); err != nil {
+		return nil, fmt.Errorf("failed to parse technology files: %w", err)
+	}
+
+	// Parse mods in load order; later definitions override earlier ones
+	mods, err := openMods(o.mods)
+	if err != nil {
+		return nil, err
+	}
+	defer closeMods(mods)
+	for _, m := range mods {
+		if _, err := fs.Stat(m.source, parser.TechnologyDir); err != nil {
+			continue
+		}
+		logf("Reading mod technology files", "dir", filepath.Join(m.path, filepath.FromSlash(parser.TechnologyDir)), "mod", m.name)
+		if err := techParser.ParseModFSContext(ctx, m.source, parser.TechnologyDir, m.name); err != nil {
+			return nil, fmt.Errorf("failed to parse mod technology files: %w", err)
+		}
+	}
+
+	technologies := techParser.GetTechnologies()
+	logf("Parsed technologies", "count", len(technologies))
+
+	if len(technologies) == 0 {
+		return nil, fmt.Errorf("no technologies found in %s (make sure the directory contains Stellaris technology .txt files)", o.techDir())
+	}
+
+	// Parse localization files
+	o.phases.begin("localization")
+	locParser := localization.NewLocalizationParser()
+	locParser.SetLogger(o.logger)
+	areaNames := make(map[string]string)
+
+	if o.skipLocalization {
+		logf("Skipping localization, names are formatted from technology keys")
+	} else if _, err := os.Stat(o.localizationDir()); err == nil {
+		logf("Reading localization files", "language", o.language, "dir", o.localizationDir())
+
+		// Mod localization is read after the base game so mods can override it
+		locDirs := localizationDirs(os.DirFS(o.gameDir), o.gameDir)
+		for _, m := range mods {
+			locDirs = append(locDirs, localizationDirs(m.source, m.path)...)
+		}
+
+		for i, locDir := range locDirs {
+			if err = locParser.ParseFS(locDir.fsys, locDir.dir); err != nil {
+				break
+			}
+			o.reporter.Report(progress.StageLocalization, i+1, len(locDirs), locDir.path)
+		}
+		if err != nil {
+			o.logger.Log(ctx, warnLevel, "Failed to parse localization files, continuing without localization data", "error", err)
+		} else {
+			// Add localization data directly to technologies, counting the
+			// entries taken from a fallback language
+			fallbacks := make(map[string]int)
+			for key, tech := range technologies {
+				name, nameLanguage := locParser.LookupName(key, o.chain)
+				desc, descLanguage := locParser.LookupDescription(key, o.chain)
+				if name != "" {
+					tech.Name = name
+				}
+				if desc != "" {
+					tech.Description = desc
+				}
+				for _, language := range []string{nameLanguage, descLanguage} {
+					if language != "" && language != o.language {
+						fallbacks[language]++
+					}
+				}
+			}
+			for key, category := range techParser.GetCategories() {
+				category.Name, _ = locParser.LookupName(key, o.chain)
+			}
+			for _, tech := range technologies {
+				if _, done := areaNames[tech.Area]; !done {
+					areaNames[tech.Area] = localizedAreaName(locParser, tech.Area, o.chain)
+				}
+			}
+			logf("Added localization to technologies", "language", o.language)
+			for _, language := range o.chain[1:] {
+				if fallbacks[language] > 0 {
+					logf("Used fallback language for missing names and descriptions", "language", language, "count", fallbacks[language])
+				}
+			}
+		}
+	} else {
+		o.logger.Log(ctx, warnLevel, "Localization directory not found, continuing without localization data", "dir", o.localizationDir())
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	o.phases.begin("tree")
+
+	// Scripted triggers in potentials are expanded so the tree's checks see
+	// the actual conditions
+	triggers, err := parser.ParseScriptedTriggers(o.gameDir, o.mods)
+	if err != nil {
+		return nil, err
+	}
+	techParser.ExpandPotentials(triggers)
+
+	// Build technology tree
+	logf("Building technology tree")
+	techTree := tree.NewTechTree(technologies)
+	o.reporter.Report(progress.StageTree, 1, 1, fmt.Sprintf("%d technologies", len(technologies)))
+
+	// The tier rules are needed to validate tiers
+	researchMechanics, err := mechanics.Load(append([]string{o.gameDir}, o.mods...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read research mechanics: %w", err)
+	}
+
+	defines, err := parser.ParseDefines(o.gameDir, o.mods)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &gameData{
+		parser:       techParser,
+		localization: locParser,
+		areaNames:    areaNames,
+		technologies: technologies,
+		tree:         techTree,
+		mechanics:    researchMechanics,
+		triggers:     triggers,
+		defines:      defines,
+		gameVersion:  gameVersion,
+	}
+	for _, tech := range technologies {
+		if tech.Name == "" {
+			data.missingNames++
+		}
+		if tech.Description == "" {
+			data.missingDescriptions++
+		}
+	}
+	data.warnings = collectWarnings(data, o.rules)
+	logWarnings(ctx, o.logger, warnLevel, data.warnings, o.rules)
+	o.phases.end()
+
+	return data, nil
+}
+
+// timeline returns the research speed assumptions with the game's technology
+// cost multiplier applied on top of the configured one
+func (d *gameData) timeline(assumptions timeline.Assumptions) timeline.Assumptions {
+	if multiplier, ok := d.defines.Number(parser.DefineTechCostMult); ok {
+		if assumptions.CostMultiplier <= 0 {
+			assumptions.CostMultiplier = 1
+		}
+		assumptions.CostMultiplier *= multiplier
+	}
+	return assumptions
+}
+
+// finish reports that the command has finished
+func (o *gameOptions) finish(message string) {
+	o.reporter.Report(progress.StageDone, 0, 0, message)
+}
+
+// compileWhere compiles a -where expression, recording problems with a
+// suggestion for misspelled field names. Returns nil for an empty expression.
+func compileWhere(value string, problems *cli.Problems) *filter.Expression {
+	if value == "" {
+		return nil
+	}
+
+	expr, err := filter.Compile(value, filter.TechnologyFieldNames())
+	var fieldErr *filter.UnknownFieldError
+	switch {
+	case errors.As(err, &fieldErr):
+		if suggestion := cli.Suggest(fieldErr.Field, filter.TechnologyFieldNames()); suggestion != "" {
+			problems.AddWithSuggestion("where", err.Error(), fmt.Sprintf("did you mean %q?", suggestion))
+		} else {
+			problems.AddWithSuggestion("where", err.Error(), "available fields: "+strings.Join(filter.TechnologyFieldNames(), ", "))
+		}
+	case err != nil:
+		problems.Add("where", err.Error())
+	}
+	return expr
+}
+
+// whereOption is the -where flag of the commands listing technologies
+type whereOption struct {
+	value string
+	expr  *filter.Expression // Set by validate; nil without -where
+}
+
+// register adds -where to a command's flag set, described by usage
+func (w *whereOption) register(fs *flag.FlagSet, usage string) {
+	fs.StringVar(&w.value, "where", "", usage)
+}
+
+// validate compiles the expression, see compileWhere
+func (w *whereOption) validate(problems *cli.Problems) {
+	w.expr = compileWhere(w.value, problems)
+}
+
+// matching returns the technologies of data matching the expression, which
+// sees the same fields as the -where of parse, or every technology without
+// -where. assumptions are the research speed assumptions of estimatedYear.
+func (w *whereOption) matching(data *gameData, assumptions timeline.Assumptions) (map[string]*models.Technology, error) {
+	if w.expr == nil {
+		return data.technologies, nil
+	}
+
+	fields := generator.NewJSONGenerator(data.tree)
+	fields.SetTimeline(data.timeline(assumptions))
+	matched := make(map[string]*models.Technology)
+	for _, node := range data.tree.GetSortedNodes() {
+		ok, err := w.expr.Match(fields.FilterFields(node))
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate -where for %s: %w", node.Tech.Key, err)
+		}
+		if ok {
+			matched[node.Tech.Key] = node.Tech
+		}
+	}
+	return matched, nil
+}
+
+// localizedAreaName returns the localized name of a research area, which the
+// game stores under the area key or its upper-case form
+func localizedAreaName(locParser *localization.LocalizationParser, area string, chain []string) string {
+	for _, key := range []string{area, strings.ToUpper(area)} {
+		if name, _ := locParser.LookupName(key, chain); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// validateOutputDir records a problem if the output path exists but is a file
+func validateOutputDir(outputDir string, problems *cli.Problems) {
+	if info, err := os.Stat(outputDir); err == nil && !info.IsDir() {
+		problems.AddWithSuggestion("output", fmt.Sprintf("output path is a file: %s", outputDir),
+			"choose a directory for the generated files")
+	}
+}
+
+// validateIconOverrides records a problem if the icon override directory is
+// set but does not exist
+func validateIconOverrides(dir string, problems *cli.Problems) {
+	if dir == "" {
+		return
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		problems.AddWithSuggestion("icon-overrides", fmt.Sprintf("icon override directory not found: %s", dir),
+			"point it to a directory of <tech_key>.png or <icon_name>.svg files")
+	}
+}
+
+// prepareOutputDir resolves the output directory and creates it if needed
+func prepareOutputDir(outputDir string) (string, error) {
+	absOutputPath, err := filepath.Abs(outputDir)
+	if err != nil {
+		absOutputPath = outputDir
+	}
+
+	if err := os.MkdirAll(absOutputPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	return absOutputPath, nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// describeDefinition formats where a technology was defined for console output
+func describeDefinition(def models.Definition) string {
+	if def.Mod == "" {
+		return def.SourceFile
+	}
+	return fmt.Sprintf("%s (%s)", def.SourceFile, def.Mod)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/danaketh/StellarisDataParser/internal/cli"
+	"github.com/danaketh/StellarisDataParser/lib/filter"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+// subsetOptions holds the flags restricting parse to a subset of the tree,
+// shorthands for common -where expressions and technology subtrees
+type subsetOptions struct {
+	areas      string
+	tiers      string
+	categories string
+	techs      string
+
+	expr        *filter.Expression // The areas, tiers and categories, set by validate
+	subtreeKeys []string           // The -tech technologies as keyed in the tree, set by check
+}
+
+// register adds the subset flags to a command's flag set
+func (o *subsetOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.areas, "areas", "", "Only export technologies of these comma-separated research areas")
+	fs.StringVar(&o.tiers, "tiers", "", "Only export technologies of these tiers, a comma-separated list of tiers and ranges, e.g. 0-2,5")
+	fs.StringVar(&o.categories, "categories", "", "Only export technologies in any of these comma-separated categories")
+	fs.StringVar(&o.techs, "tech", "", "Only export these comma-separated technologies and every technology depending on them")
+}
+
+// validate compiles the areas, tiers and categories into a filter expression,
+// recording problems for tiers that aren't numbers or ranges
+func (o *subsetOptions) validate(problems *cli.Problems) {
+	var parts []string
+	if areas := splitList(o.areas); len(areas) > 0 {
+		parts = append(parts, anyOf("area ==", areas))
+	}
+	if o.tiers != "" {
+		conditions, err := tierConditions(o.tiers)
+		if err != nil {
+			problems.AddWithSuggestion("tiers", err.Error(), "use tiers and ranges such as 0-2,5")
+		} else {
+			parts = append(parts, strings.Join(conditions, " || "))
+		}
+	}
+	if categories := splitList(o.categories); len(categories) > 0 {
+		parts = append(parts, anyOf("category ==", categories))
+	}
+	if len(parts) == 0 {
+		o.expr = nil
+		return
+	}
+
+	expr, err := filter.Compile("("+strings.Join(parts, ") && (")+")", filter.TechnologyFieldNames())
+	if err != nil {
+		problems.Add("areas", err.Error())
+		return
+	}
+	o.expr = expr
+}
+
+// check returns an error for areas, categories and technologies that aren't
+// in the tree, which would silently export nothing. Like the filter, it
+// ignores case.
+func (o *subsetOptions) check(techTree *tree.TechTree) error {
+	if _, err := checkKnown("area", "areas", splitList(o.areas), techTree.GetAreas()); err != nil {
+		return err
+	}
+	if _, err := checkKnown("category", "categories", splitList(o.categories), techTree.GetCategories()); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(techTree.GetAllNodes()))
+	for _, node := range techTree.GetSortedNodes() {
+		keys = append(keys, node.Tech.Key)
+	}
+	subtreeKeys, err := checkKnown("technology", "tech", splitList(o.techs), keys)
+	if err != nil {
+		return err
+	}
+	o.subtreeKeys = subtreeKeys
+	return nil
+}
+
+// subtrees returns the technologies whose subtrees are exported, as keyed in
+// the tree, none to export the whole tree. Set by check.
+func (o *subsetOptions) subtrees() []string {
+	return o.subtreeKeys
+}
+
+// anyOf returns an expression comparing with each value, joined with ||
+func anyOf(comparison string, values []string) string {
+	conditions := make([]string, len(values))
+	for i, value := range values {
+		conditions[i] = comparison + " " + strconv.Quote(value)
+	}
+	return strings.Join(conditions, " || ")
+}
+
+// tierConditions returns a condition for each tier or range of tiers in a
+// comma-separated list such as 0-2,5
+func tierConditions(value string) ([]string, error) {
+	var conditions []string
+	for _, part := range splitList(value) {
+		first, last, isRange := strings.Cut(part, "-")
+		from, err := strconv.Atoi(strings.TrimSpace(first))
+		if err != nil {
+			return nil, fmt.Errorf("invalid tier %q", part)
+		}
+		if !isRange {
+			conditions = append(conditions, fmt.Sprintf("tier == %d", from))
+			continue
+		}
+		to, err := strconv.Atoi(strings.TrimSpace(last))
+		if err != nil || to < from {
+			return nil, fmt.Errorf("invalid tier range %q", part)
+		}
+		conditions = append(conditions, fmt.Sprintf("tier >= %d && tier <= %d", from, to))
+	}
+	if len(conditions) == 0 {
+		return nil, fmt.Errorf("no tiers in %q", value)
+	}
+	return conditions, nil
+}
+
+// checkKnown returns each value as spelled in known, comparing without
+// regard to case, or an error for the first value that isn't known, with a
+// suggestion when it looks like a typo
+func checkKnown(kind, flagName string, values, known []string) ([]string, error) {
+	spelled := make([]string, 0, len(values))
+	for _, value := range values {
+		i := slices.IndexFunc(known, func(k string) bool { return strings.EqualFold(k, value) })
+		if i >= 0 {
+			spelled = append(spelled, known[i])
+			continue
+		}
+		if suggestion := cli.Suggest(value, known); suggestion != "" {
+			return nil, fmt.Errorf("unknown %s %q in -%s, did you mean %q?", kind, value, flagName, suggestion)
+		}
+		return nil, fmt.Errorf("unknown %s %q in -%s", kind, value, flagName)
+	}
+	return spelled, nil
+}
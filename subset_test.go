@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/danaketh/StellarisDataParser/internal/cli"
+	"github.com/danaketh/StellarisDataParser/lib/filter"
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/tree"
+)
+
+func TestTierConditions(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected []string
+	}{
+		{"3", []string{"tier == 3"}},
+		{"0-2,5", []string{"tier >= 0 && tier <= 2", "tier == 5"}},
+		{" 1 - 1 , 4 ", []string{"tier >= 1 && tier <= 1", "tier == 4"}},
+	}
+	for _, tt := range tests {
+		conditions, err := tierConditions(tt.value)
+		if err != nil {
+			t.Errorf("tierConditions(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if !slices.Equal(conditions, tt.expected) {
+			t.Errorf("tierConditions(%q) = %q, expected %q", tt.value, conditions, tt.expected)
+		}
+	}
+
+	for _, value := range []string{"high", "2-", "3-1", "1-x", ","} {
+		if _, err := tierConditions(value); err == nil {
+			t.Errorf("Expected tierConditions(%q) to fail", value)
+		}
+	}
+}
+
+// parseSubset registers the subset flags, parses args and validates them
+func parseSubset(t *testing.T, args ...string) (*subsetOptions, *cli.Problems) {
+	t.Helper()
+	subset := &subsetOptions{}
+	fs := flag.NewFlagSet("parse", flag.ContinueOnError)
+	subset.register(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	problems := &cli.Problems{}
+	subset.validate(problems)
+	return subset, problems
+}
+
+func TestSubsetOptionsValidate(t *testing.T) {
+	subset, problems := parseSubset(t, "-areas", "Physics,society", "-tiers", "1-2", "-categories", "particles")
+	if !problems.Empty() {
+		t.Fatalf("Unexpected problems: %v", problems)
+	}
+
+	tests := []struct {
+		tech     models.Technology
+		expected bool
+	}{
+		{models.Technology{Area: "physics", Tier: 1, Category: []string{"particles"}}, true},
+		{models.Technology{Area: "society", Tier: 2, Category: []string{"biology", "particles"}}, true},
+		{models.Technology{Area: "engineering", Tier: 1, Category: []string{"particles"}}, false},
+		{models.Technology{Area: "physics", Tier: 3, Category: []string{"particles"}}, false},
+		{models.Technology{Area: "physics", Tier: 1, Category: []string{"computing"}}, false},
+	}
+	for _, tt := range tests {
+		matched, err := subset.expr.Match(filter.TechnologyFields(&tt.tech))
+		if err != nil {
+			t.Fatalf("Match failed: %v", err)
+		}
+		if matched != tt.expected {
+			t.Errorf("Expected %+v to match: %v", tt.tech, tt.expected)
+		}
+	}
+
+	if subset, _ := parseSubset(t); subset.expr != nil {
+		t.Error("Expected no expression without subset flags")
+	}
+	if _, problems := parseSubset(t, "-tiers", "3-1"); problems.Empty() {
+		t.Error("Expected an invalid tier range to be a problem")
+	}
+}
+
+func TestSubsetOptionsCheck(t *testing.T) {
+	techTree := tree.NewTechTree(map[string]*models.Technology{
+		"tech_lasers_1": {Key: "tech_lasers_1", Area: "physics", Category: []string{"particles"}},
+		"tech_lasers_2": {Key: "tech_lasers_2", Area: "physics", Category: []string{"particles"}, Prerequisites: []string{"tech_lasers_1"}},
+	})
+
+	subset, _ := parseSubset(t, "-areas", "Physics", "-categories", "PARTICLES", "-tech", "TECH_LASERS_1")
+	if err := subset.check(techTree); err != nil {
+		t.Fatalf("Expected names to be checked without regard to case, got %v", err)
+	}
+	if subtrees := subset.subtrees(); !slices.Equal(subtrees, []string{"tech_lasers_1"}) {
+		t.Errorf("Expected the technologies as keyed in the tree, got %v", subtrees)
+	}
+
+	subset, _ = parseSubset(t, "-areas", "physiks")
+	err := subset.check(techTree)
+	if err == nil || !strings.Contains(err.Error(), `did you mean "physics"?`) {
+		t.Errorf("Expected a suggestion for a misspelled area, got %v", err)
+	}
+	subset, _ = parseSubset(t, "-tech", "tech_unknown_xyz")
+	if err := subset.check(techTree); err == nil {
+		t.Error("Expected an unknown technology to be an error")
+	}
+}
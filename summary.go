@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/danaketh/StellarisDataParser/lib/gamefs"
+	"github.com/danaketh/StellarisDataParser/lib/generator"
+	"github.com/danaketh/StellarisDataParser/lib/manifest"
+	"github.com/danaketh/StellarisDataParser/lib/models"
+	"github.com/danaketh/StellarisDataParser/lib/parser"
+)
+
+// phaseTimer records how long each phase of a run takes. A nil timer records
+// nothing.
+type phaseTimer struct {
+	phases  []phaseTiming
+	current string // Phase being timed, empty between phases
+	start   time.Time
+}
+
+// phaseTiming is the duration of a phase in the run summary
+type phaseTiming struct {
+	Name       string `json:"name"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// begin ends the current phase and starts timing the next
+func (t *phaseTimer) begin(name string) {
+	if t == nil {
+		return
+	}
+	t.end()
+	t.current = name
+	t.start = time.Now()
+}
+
+// end ends the current phase
+func (t *phaseTimer) end() {
+	if t == nil || t.current == "" {
+		return
+	}
+	t.phases = append(t.phases, phaseTiming{Name: t.current, DurationMs: time.Since(t.start).Milliseconds()})
+	t.current = ""
+}
+
+// add records a phase timed elsewhere
+func (t *phaseTimer) add(name string, duration time.Duration) {
+	if t == nil {
+		return
+	}
+	t.phases = append(t.phases, phaseTiming{Name: name, DurationMs: duration.Milliseconds()})
+}
+
+// runSummary is run-summary.json, written at the end of a run so pipelines
+// can assert on its results
+type runSummary struct {
+	Version     string              `json:"version"`
+	GameVersion *models.GameVersion `json:"gameVersion,omitempty"` // Set when the game version is known
+	Command     string              `json:"command"`
+	StartedAt   time.Time           `json:"startedAt"`
+	DurationMs  int64               `json:"durationMs"`
+	Success     bool                `json:"success"`
+	Error       string              `json:"error,omitempty"`
+	Counts      summaryCounts       `json:"counts"`
+	Phases      []phaseTiming       `json:"phases"`
+	Inputs      []summaryInput      `json:"inputs"`
+
+	timer phaseTimer
+}
+
+// summaryCounts are the numbers of things read and written by a run
+type summaryCounts struct {
+	Technologies        int            `json:"technologies"`
+	Warnings            int            `json:"warnings"`           // Not suppressed, including errors
+	Errors              int            `json:"errors"`             // Warnings the validate command counts as errors
+	SuppressedWarnings  int            `json:"suppressedWarnings"` // Matched by the suppression file
+	MissingNames        int            `json:"missingNames"`       // Technologies without a name in any language of the chain
+	MissingDescriptions int            `json:"missingDescriptions"`
+	IconsConverted      int            `json:"iconsConverted"`
+	IconsSkipped        int            `json:"iconsSkipped"` // Unchanged since -since
+	IconsFailed         int            `json:"iconsFailed"`
+	FilesWritten        int            `json:"filesWritten"`
+	FilesSkipped        int            `json:"filesSkipped"` // Unchanged since -since
+	Content             map[string]int `json:"content"`      // Definitions parsed per content type
+}
+
+// summaryInput is the fingerprint of an input of a run
+type summaryInput struct {
+	Kind        string `json:"kind"` // game, mod, config or suppress
+	Name        string `json:"name,omitempty"`
+	Path        string `json:"path"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// inputDirs are the directories of the game and of each mod fingerprinted in
+// the run summary
+var inputDirs = []string{parser.TechnologyDir, "localisation_synced", "localisation"}
+
+// newRunSummary starts the summary of a run of command
+func newRunSummary(command string) *runSummary {
+	return &runSummary{
+		Version:   version,
+		Command:   command,
+		StartedAt: time.Now(),
+		Counts:    summaryCounts{Content: make(map[string]int)},
+	}
+}
+
+// recordData counts the technologies and warnings of the loaded game data
+// and records the game version
+func (s *runSummary) recordData(data *gameData, game *gameOptions) {
+	s.Counts.Technologies = len(data.technologies)
+	if data.gameVersion.Version != "" {
+		s.GameVersion = &data.gameVersion
+	}
+	s.Counts.Warnings = len(data.warnings)
+	for _, warning := range data.warnings {
+		if warning.isError {
+			s.Counts.Errors++
+		}
+	}
+	s.Counts.SuppressedWarnings = game.rules.SuppressedCount()
+	s.Counts.MissingNames = data.missingNames
+	s.Counts.MissingDescriptions = data.missingDescriptions
+}
+
+// recordGenerator counts the files and icons written by a generator and
+// splits the time spent generating into the JSON files and the icons
+func (s *runSummary) recordGenerator(g *generator.JSONGenerator, duration time.Duration) {
+	icons := g.IconStats()
+	s.Counts.IconsConverted = icons.Converted
+	s.Counts.IconsSkipped = icons.Skipped
+	s.Counts.IconsFailed = icons.Failed
+	s.Counts.FilesWritten = len(g.GeneratedFiles())
+	s.Counts.FilesSkipped = len(g.SkippedFiles())
+	s.timer.add("json", duration-icons.Duration)
+	if icons.Duration > 0 {
+		s.timer.add("icons", icons.Duration)
+	}
+}
+
+// recordInputs fingerprints the technology and localization files of the
+// game and of each mod, and the config and suppression files
+func (s *runSummary) recordInputs(game *gameOptions) error {
+	sources := append([]string{game.gameDir}, game.mods...)
+	for i, path := range sources {
+		input := summaryInput{Kind: "game", Path: path}
+		if i > 0 {
+			input.Kind, input.Name = "mod", gamefs.Name(path)
+		}
+		source, err := gamefs.Open(path)
+		if err != nil {
+			return err
+		}
+		input.Fingerprint, err = manifest.FingerprintFS(source, inputDirs...)
+		source.Close()
+		if err != nil {
+			return fmt.Errorf("failed to fingerprint %s: %w", path, err)
+		}
+		s.Inputs = append(s.Inputs, input)
+	}
+
+	for _, file := range []summaryInput{{Kind: "config", Path: game.configFile}, {Kind: "suppress", Path: game.suppressFile}} {
+		if file.Path == "" {
+			continue
+		}
+		fingerprint, err := manifest.FingerprintFile(file.Path)
+		if err != nil {
+			return fmt.Errorf("failed to fingerprint %s: %w", file.Path, err)
+		}
+		file.Fingerprint = fingerprint
+		s.Inputs = append(s.Inputs, file)
+	}
+	return nil
+}
+
+// write finishes the summary with the result of the run and writes it to
+// path. The inputs are fingerprinted even when the run failed.
+func (s *runSummary) write(path string, game *gameOptions, runErr error) error {
+	s.timer.end()
+	s.Phases = s.timer.phases
+	if s.Phases == nil {
+		s.Phases = []phaseTiming{}
+	}
+	s.Success = runErr == nil
+	if runErr != nil {
+		s.Error = runErr.Error()
+	}
+	if err := s.recordInputs(game); err != nil {
+		return err
+	}
+	s.DurationMs = time.Since(s.StartedAt).Milliseconds()
+
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run summary: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create run summary directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write run summary: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/danaketh/StellarisDataParser/internal/suppress"
+)
+
+// gameWarning is a problem found in the game data that does not stop processing
+type gameWarning struct {
+	message string
+	isError bool             // Counted as an error by the validate command
+	source  suppress.Warning // What the warning is about, logged as attributes
+}
+
+// collectWarnings returns the parse diagnostics, missing prerequisites,
+// overrides, tree issues and tier rule violations of the loaded data that are
+// not suppressed by rules
+func collectWarnings(data *gameData, rules *suppress.Rules) []gameWarning {
+	var warnings []gameWarning
+
+	for _, diagnostic := range data.parser.GetDiagnostics() {
+		warning := suppress.Warning{Kind: suppress.KindParse, File: diagnostic.File}
+		if rules.Suppressed(warning) {
+			continue
+		}
+		warnings = append(warnings, gameWarning{message: diagnostic.Error(), isError: true, source: warning})
+	}
+
+	for _, missing := range data.tree.GetMissingPrerequisites() {
+		warning := suppress.Warning{
+			Kind:         suppress.KindMissingPrerequisite,
+			Tech:         missing.Tech,
+			Prerequisite: missing.Prerequisite,
+		}
+		if tech, exists := data.technologies[missing.Tech]; exists {
+			warning.File = tech.SourceFile
+			warning.Mod = tech.Mod
+		}
+		if rules.Suppressed(warning) {
+			continue
+		}
+		warnings = append(warnings, gameWarning{
+			message: missing.Error(),
+			isError: true,
+			source:  warning,
+		})
+	}
+
+	for _, override := range data.parser.GetOverrides() {
+		warning := suppress.Warning{
+			Kind: suppress.KindOverride,
+			Tech: override.Key,
+			File: override.OverriddenBy.SourceFile,
+			Mod:  override.OverriddenBy.Mod,
+		}
+		if rules.Suppressed(warning) {
+			continue
+		}
+		warnings = append(warnings, gameWarning{
+			message: fmt.Sprintf("%s: %s overridden by %s", override.Key, describeDefinition(override.Definition), describeDefinition(override.OverriddenBy)),
+			source:  warning,
+		})
+	}
+
+	tierRules := make(map[int]int)
+	for _, rule := range data.mechanics.Tiers {
+		tierRules[rule.Tier] = rule.PreviouslyUnlocked
+	}
+	issues := append(data.tree.Issues(), data.tree.TierIssues(tierRules)...)
+
+	for _, issue := range issues {
+		warning := suppress.Warning{Kind: issue.Kind, Tech: issue.Tech}
+		if tech, exists := data.technologies[issue.Tech]; exists {
+			warning.File = tech.SourceFile
+			warning.Mod = tech.Mod
+		}
+		if rules.Suppressed(warning) {
+			continue
+		}
+		warnings = append(warnings, gameWarning{
+			message: fmt.Sprintf("%s: %s, %s", issue.Tech, issue.Kind, issue.Reason),
+			source:  warning,
+		})
+	}
+
+	return warnings
+}
+
+// logWarnings logs each warning at level, with its kind, technology, file
+// and mod as attributes, and how many were suppressed
+func logWarnings(ctx context.Context, logger *slog.Logger, level slog.Level, warnings []gameWarning, rules *suppress.Rules) {
+	for _, warning := range warnings {
+		var attrs []any
+		for _, attr := range []struct{ key, value string }{
+			{"kind", warning.source.Kind},
+			{"tech", warning.source.Tech},
+			{"prerequisite", warning.source.Prerequisite},
+			{"file", warning.source.File},
+			{"mod", warning.source.Mod},
+		} {
+			if attr.value != "" {
+				attrs = append(attrs, attr.key, attr.value)
+			}
+		}
+		logger.Log(ctx, level, warning.message, attrs...)
+	}
+	if suppressed := rules.SuppressedCount(); suppressed > 0 {
+		logger.Log(ctx, min(level, slog.LevelInfo), "Suppressed warnings", "count", suppressed)
+	}
+}